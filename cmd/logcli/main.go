@@ -16,6 +16,7 @@ import (
 	"gopkg.in/alecthomas/kingpin.v2"
 
 	"github.com/grafana/loki/pkg/logcli/client"
+	"github.com/grafana/loki/pkg/logcli/index"
 	"github.com/grafana/loki/pkg/logcli/labelquery"
 	"github.com/grafana/loki/pkg/logcli/output"
 	"github.com/grafana/loki/pkg/logcli/query"
@@ -180,6 +181,23 @@ This is helpful to find high cardinality labels.
 `)
 	seriesQuery = newSeriesQuery(seriesCmd)
 
+	statsCmd = app.Command("stats", `Run index stats query.
+
+The "stats" command will take the provided query and return the number of
+streams, chunks, entries and bytes it matches in the time window, as estimated
+from the index. It is helpful to know the size of a query before running it.
+`)
+	statsQuery = newStatsQuery(statsCmd)
+
+	volumeCmd = app.Command("volume", `Run series volume query.
+
+The "volume" command will take the provided label matchers and return the
+label sets with the most ingested bytes in the time window. The volumes are
+aggregated by the labels of the matchers, e.g. '{app=~".+"}' ranks the values
+of the app label.
+`)
+	volumeQuery = newVolumeQuery(volumeCmd)
+
 	fmtCmd = app.Command("fmt", "Formats a LogQL query.")
 )
 
@@ -288,6 +306,10 @@ func main() {
 		labelsQuery.DoLabels(queryClient)
 	case seriesCmd.FullCommand():
 		seriesQuery.DoSeries(queryClient)
+	case statsCmd.FullCommand():
+		statsQuery.DoStats(queryClient)
+	case volumeCmd.FullCommand():
+		volumeQuery.DoVolume(queryClient)
 	case fmtCmd.FullCommand():
 		if err := formatLogQL(os.Stdin, os.Stdout); err != nil {
 			log.Fatalf("unable to format logql: %s", err)
@@ -409,6 +431,61 @@ func newSeriesQuery(cmd *kingpin.CmdClause) *seriesquery.SeriesQuery {
 	return q
 }
 
+func newStatsQuery(cmd *kingpin.CmdClause) *index.StatsQuery {
+	// calculate stats range from cli params
+	var from, to string
+	var since time.Duration
+
+	q := &index.StatsQuery{}
+
+	// executed after all command flags are parsed
+	cmd.Action(func(c *kingpin.ParseContext) error {
+
+		defaultEnd := time.Now()
+		defaultStart := defaultEnd.Add(-since)
+
+		q.Start = mustParse(from, defaultStart)
+		q.End = mustParse(to, defaultEnd)
+		q.Quiet = *quiet
+		return nil
+	})
+
+	cmd.Arg("query", "eg '{foo=\"bar\",baz=~\".*blip\"} |~ \".*error.*\"'").Required().StringVar(&q.QueryString)
+	cmd.Flag("since", "Lookback window.").Default("1h").DurationVar(&since)
+	cmd.Flag("from", "Start looking for logs at this absolute time (inclusive)").StringVar(&from)
+	cmd.Flag("to", "Stop looking for logs at this absolute time (exclusive)").StringVar(&to)
+
+	return q
+}
+
+func newVolumeQuery(cmd *kingpin.CmdClause) *index.VolumeQuery {
+	// calculate volume range from cli params
+	var from, to string
+	var since time.Duration
+
+	q := &index.VolumeQuery{}
+
+	// executed after all command flags are parsed
+	cmd.Action(func(c *kingpin.ParseContext) error {
+
+		defaultEnd := time.Now()
+		defaultStart := defaultEnd.Add(-since)
+
+		q.Start = mustParse(from, defaultStart)
+		q.End = mustParse(to, defaultEnd)
+		q.Quiet = *quiet
+		return nil
+	})
+
+	cmd.Arg("matchers", "eg '{namespace=\"prod\",app=~\".+\"}'").Required().StringVar(&q.QueryString)
+	cmd.Flag("since", "Lookback window.").Default("1h").DurationVar(&since)
+	cmd.Flag("from", "Start looking for logs at this absolute time (inclusive)").StringVar(&from)
+	cmd.Flag("to", "Stop looking for logs at this absolute time (exclusive)").StringVar(&to)
+	cmd.Flag("limit", "Limit on number of label sets to return.").Default("100").IntVar(&q.Limit)
+
+	return q
+}
+
 func newQuery(instant bool, cmd *kingpin.CmdClause) *query.Query {
 	// calculate query range from cli params
 	var now, from, to string
@@ -31,6 +31,8 @@ const (
 	chunkFormatV1
 	chunkFormatV2
 	chunkFormatV3
+	// chunkFormatV4 adds the structured metadata of each entry to the blocks.
+	chunkFormatV4
 
 	DefaultChunkFormat = chunkFormatV3 // the currently used chunk format
 
@@ -43,7 +45,7 @@ const (
 	defaultBlockSize = 256 * 1024
 )
 
-var HeadBlockFmts = []HeadBlockFmt{OrderedHeadBlockFmt, UnorderedHeadBlockFmt}
+var HeadBlockFmts = []HeadBlockFmt{OrderedHeadBlockFmt, UnorderedHeadBlockFmt, UnorderedWithStructuredMetadataHeadBlockFmt}
 
 type HeadBlockFmt byte
 
@@ -55,6 +57,8 @@ func (f HeadBlockFmt) String() string {
 		return "ordered"
 	case f == UnorderedHeadBlockFmt:
 		return "unordered"
+	case f == UnorderedWithStructuredMetadataHeadBlockFmt:
+		return "unordered with structured metadata"
 	default:
 		return fmt.Sprintf("unknown: %v", byte(f))
 	}
@@ -65,10 +69,18 @@ func (f HeadBlockFmt) NewBlock() HeadBlock {
 	case f < UnorderedHeadBlockFmt:
 		return &headBlock{}
 	default:
-		return newUnorderedHeadBlock()
+		return newUnorderedHeadBlock(f)
 	}
 }
 
+// ChunkFormat returns the chunk format required to store blocks cut from this head block format.
+func (f HeadBlockFmt) ChunkFormat() byte {
+	if f < UnorderedWithStructuredMetadataHeadBlockFmt {
+		return DefaultChunkFormat
+	}
+	return chunkFormatV4
+}
+
 const (
 	_ HeadBlockFmt = iota
 	// placeholders to start splitting chunk formats vs head block
@@ -77,6 +89,7 @@ const (
 	_
 	OrderedHeadBlockFmt
 	UnorderedHeadBlockFmt
+	UnorderedWithStructuredMetadataHeadBlockFmt
 )
 
 var magicNumber = uint32(0x12EE56A)
@@ -159,7 +172,9 @@ func (hb *headBlock) Reset() {
 
 func (hb *headBlock) Bounds() (int64, int64) { return hb.mint, hb.maxt }
 
-func (hb *headBlock) Append(ts int64, line string) error {
+// Append adds an entry to the head block. The ordered head block does not
+// support structured metadata, which is therefore dropped.
+func (hb *headBlock) Append(ts int64, line string, _ labels.Labels) error {
 	if !hb.IsEmpty() && hb.maxt > ts {
 		return ErrOutOfOrder
 	}
@@ -315,10 +330,10 @@ func (hb *headBlock) Convert(version HeadBlockFmt) (HeadBlock, error) {
 	if version < UnorderedHeadBlockFmt {
 		return hb, nil
 	}
-	out := newUnorderedHeadBlock()
+	out := version.NewBlock()
 
 	for _, e := range hb.entries {
-		if err := out.Append(e.t, e.s); err != nil {
+		if err := out.Append(e.t, e.s, nil); err != nil {
 			return nil, err
 		}
 	}
@@ -337,7 +352,7 @@ func NewMemChunk(enc Encoding, head HeadBlockFmt, blockSize, targetSize int) *Me
 		targetSize: targetSize, // Desired chunk size in compressed bytes
 		blocks:     []block{},
 
-		format: DefaultChunkFormat,
+		format: head.ChunkFormat(),
 		head:   head.NewBlock(),
 
 		encoding: enc,
@@ -366,7 +381,7 @@ func NewByteChunk(b []byte, blockSize, targetSize int) (*MemChunk, error) {
 	switch version {
 	case chunkFormatV1:
 		bc.encoding = EncGZIP
-	case chunkFormatV2, chunkFormatV3:
+	case chunkFormatV2, chunkFormatV3, chunkFormatV4:
 		// format v2+ has a byte for block encoding.
 		enc := Encoding(db.byte())
 		if db.err() != nil {
@@ -401,7 +416,7 @@ func NewByteChunk(b []byte, blockSize, targetSize int) (*MemChunk, error) {
 
 		// Read offset and length.
 		blk.offset = db.uvarint()
-		if version == chunkFormatV3 {
+		if version >= chunkFormatV3 {
 			blk.uncompressedSize = db.uvarint()
 		}
 		l := db.uvarint()
@@ -460,7 +475,7 @@ func (c *MemChunk) BytesSize() int {
 		size += binary.MaxVarintLen64 // mint
 		size += binary.MaxVarintLen64 // maxt
 		size += binary.MaxVarintLen32 // offset
-		if c.format == chunkFormatV3 {
+		if c.format >= chunkFormatV3 {
 			size += binary.MaxVarintLen32 // uncompressed size
 		}
 		size += binary.MaxVarintLen32 // len(b)
@@ -534,7 +549,7 @@ func (c *MemChunk) WriteTo(w io.Writer) (int64, error) {
 		eb.putVarint64(b.mint)
 		eb.putVarint64(b.maxt)
 		eb.putUvarint(b.offset)
-		if c.format == chunkFormatV3 {
+		if c.format >= chunkFormatV3 {
 			eb.putUvarint(b.uncompressedSize)
 		}
 		eb.putUvarint(len(b.b))
@@ -589,6 +604,13 @@ func MemchunkFromCheckpoint(chk, head []byte, desired HeadBlockFmt, blockSize in
 	if err != nil {
 		return nil, err
 	}
+	// The head block must be able to cut blocks in the format of the chunk.
+	switch {
+	case mc.format >= chunkFormatV4 && desired == UnorderedHeadBlockFmt:
+		desired = UnorderedWithStructuredMetadataHeadBlockFmt
+	case mc.format < chunkFormatV4 && desired == UnorderedWithStructuredMetadataHeadBlockFmt:
+		desired = UnorderedHeadBlockFmt
+	}
 	h, err := HeadFromCheckpoint(head, desired)
 	if err != nil {
 		return nil, err
@@ -626,7 +648,7 @@ func (c *MemChunk) SpaceFor(e *logproto.Entry) bool {
 	if c.targetSize > 0 {
 		// This is looking to see if the uncompressed lines will fit which is not
 		// a great check, but it will guarantee we are always under the target size
-		newHBSize := c.head.UncompressedSize() + len(e.Line)
+		newHBSize := c.head.UncompressedSize() + len(e.Line) + structuredMetadataSize(logproto.FromStructuredMetadataToLabels(e.StructuredMetadata))
 		return (c.cutBlockSize + newHBSize) < c.targetSize
 	}
 	// if targetSize is not defined, default to the original behavior of fixed blocks per chunk
@@ -674,7 +696,7 @@ func (c *MemChunk) Append(entry *logproto.Entry) error {
 		return ErrOutOfOrder
 	}
 
-	if err := c.head.Append(entryTimestamp, entry.Line); err != nil {
+	if err := c.head.Append(entryTimestamp, entry.Line, logproto.FromStructuredMetadataToLabels(entry.StructuredMetadata)); err != nil {
 		return err
 	}
 
@@ -722,6 +744,9 @@ func (c *MemChunk) reorder() error {
 }
 
 func (c *MemChunk) ConvertHead(desired HeadBlockFmt) error {
+	if desired.ChunkFormat() != c.format && (desired.ChunkFormat() >= chunkFormatV4 || c.format >= chunkFormatV4) {
+		return fmt.Errorf("cannot convert head block of chunk format v%d to %s", c.format, desired)
+	}
 	if c.head != nil && c.head.Format() != desired {
 		newH, err := c.head.Convert(desired)
 		if err != nil {
@@ -797,7 +822,7 @@ func (c *MemChunk) Iterator(ctx context.Context, mintT, maxtT time.Time, directi
 		}
 		lastMax = b.maxt
 
-		blockItrs = append(blockItrs, encBlock{c.encoding, b}.Iterator(ctx, pipeline))
+		blockItrs = append(blockItrs, encBlock{c.encoding, c.format, b}.Iterator(ctx, pipeline))
 	}
 
 	if !c.head.IsEmpty() {
@@ -871,7 +896,7 @@ func (c *MemChunk) SampleIterator(ctx context.Context, from, through time.Time,
 			ordered = false
 		}
 		lastMax = b.maxt
-		its = append(its, encBlock{c.encoding, b}.SampleIterator(ctx, extractor))
+		its = append(its, encBlock{c.encoding, c.format, b}.SampleIterator(ctx, extractor))
 	}
 
 	if !c.head.IsEmpty() {
@@ -903,7 +928,7 @@ func (c *MemChunk) Blocks(mintT, maxtT time.Time) []Block {
 
 	for _, b := range c.blocks {
 		if maxt >= b.mint && b.maxt >= mint {
-			blocks = append(blocks, encBlock{c.encoding, b})
+			blocks = append(blocks, encBlock{c.encoding, c.format, b})
 		}
 	}
 	return blocks
@@ -955,7 +980,8 @@ func (c *MemChunk) Rebound(start, end time.Time, filter filter.Func) (Chunk, err
 // then allows us to bind a decoding context to a block when requested, but otherwise helps reduce the
 // chances of chunk<>block encoding drift in the codebase as the latter is parameterized by the former.
 type encBlock struct {
-	enc Encoding
+	enc    Encoding
+	format byte
 	block
 }
 
@@ -963,14 +989,14 @@ func (b encBlock) Iterator(ctx context.Context, pipeline log.StreamPipeline) ite
 	if len(b.b) == 0 {
 		return iter.NoopIterator
 	}
-	return newEntryIterator(ctx, getReaderPool(b.enc), b.b, pipeline)
+	return newEntryIterator(ctx, getReaderPool(b.enc), b.b, b.format, pipeline)
 }
 
 func (b encBlock) SampleIterator(ctx context.Context, extractor log.StreamSampleExtractor) iter.SampleIterator {
 	if len(b.b) == 0 {
 		return iter.NoopIterator
 	}
-	return newSampleIterator(ctx, getReaderPool(b.enc), b.b, extractor)
+	return newSampleIterator(ctx, getReaderPool(b.enc), b.b, b.format, extractor)
 }
 
 func (b block) Offset() int {
@@ -1112,28 +1138,32 @@ func unsafeGetBytes(s string) []byte {
 type bufferedIterator struct {
 	origBytes []byte
 	stats     *stats.Context
+	format    byte
 
 	reader io.Reader
 	pool   ReaderPool
 
 	err error
 
-	readBuf      [20]byte // Enough bytes to store two varints.
+	readBuf      [30]byte // Enough bytes to store three varints.
 	readBufValid int      // How many bytes are left in readBuf from previous read.
 
 	buf      []byte // The buffer for a single entry.
 	currLine []byte // the current line, this is the same as the buffer but sliced the the line size.
 	currTs   int64
 
+	currStructuredMetadata labels.Labels // the structured metadata of the current entry, only set for chunk format v4+.
+
 	closed bool
 }
 
-func newBufferedIterator(ctx context.Context, pool ReaderPool, b []byte) *bufferedIterator {
+func newBufferedIterator(ctx context.Context, pool ReaderPool, b []byte, format byte) *bufferedIterator {
 	stats := stats.FromContext(ctx)
 	stats.AddCompressedBytes(int64(len(b)))
 	return &bufferedIterator{
 		stats:     stats,
 		origBytes: b,
+		format:    format,
 		reader:    nil, // will be initialized later
 		pool:      pool,
 	}
@@ -1154,72 +1184,88 @@ func (si *bufferedIterator) Next() bool {
 		}
 	}
 
-	ts, line, ok := si.moveNext()
+	ts, line, metadata, ok := si.moveNext()
 	if !ok {
 		si.Close()
 		return false
 	}
 	// we decode always the line length and ts as varint
-	si.stats.AddDecompressedBytes(int64(len(line)) + 2*binary.MaxVarintLen64)
+	si.stats.AddDecompressedBytes(int64(len(line)+len(metadata)) + 2*binary.MaxVarintLen64)
 	si.stats.AddDecompressedLines(1)
 
 	si.currTs = ts
 	si.currLine = line
+	si.currStructuredMetadata = nil
+	if len(metadata) > 0 {
+		si.currStructuredMetadata, si.err = decodeStructuredMetadata(metadata)
+		if si.err != nil {
+			si.Close()
+			return false
+		}
+	}
 	return true
 }
 
-// moveNext moves the buffer to the next entry
-func (si *bufferedIterator) moveNext() (int64, []byte, bool) {
+// moveNext moves the buffer to the next entry.
+// The returned metadata is only non-empty for chunk format v4+.
+func (si *bufferedIterator) moveNext() (int64, []byte, []byte, bool) {
 	var ts int64
-	var tWidth, lWidth, lineSize, lastAttempt int
-	for lWidth == 0 { // Read until both varints have enough bytes.
+	var tWidth, lWidth, mWidth, lineSize, metaSize, lastAttempt int
+	for lWidth == 0 || (si.format >= chunkFormatV4 && mWidth == 0) { // Read until all varints have enough bytes.
 		n, err := si.reader.Read(si.readBuf[si.readBufValid:])
 		si.readBufValid += n
 		if err != nil {
 			if err != io.EOF {
 				si.err = err
-				return 0, nil, false
+				return 0, nil, nil, false
 			}
 			if si.readBufValid == 0 { // Got EOF and no data in the buffer.
-				return 0, nil, false
+				return 0, nil, nil, false
 			}
 			if si.readBufValid == lastAttempt { // Got EOF and could not parse same data last time.
 				si.err = fmt.Errorf("invalid data in chunk")
-				return 0, nil, false
+				return 0, nil, nil, false
 			}
 		}
-		var l uint64
+		var l, m uint64
 		ts, tWidth = binary.Varint(si.readBuf[:si.readBufValid])
 		l, lWidth = binary.Uvarint(si.readBuf[tWidth:si.readBufValid])
 		lineSize = int(l)
+		if si.format >= chunkFormatV4 && lWidth > 0 {
+			m, mWidth = binary.Uvarint(si.readBuf[tWidth+lWidth : si.readBufValid])
+			metaSize = int(m)
+		}
 		lastAttempt = si.readBufValid
 	}
 
 	if lineSize >= maxLineLength {
 		si.err = fmt.Errorf("line too long %d, maximum %d", lineSize, maxLineLength)
-		return 0, nil, false
+		return 0, nil, nil, false
 	}
+	// The line and its structured metadata are read into the same buffer.
+	entrySize := lineSize + metaSize
 	// If the buffer is not yet initialize or too small, we get a new one.
-	if si.buf == nil || lineSize > cap(si.buf) {
+	if si.buf == nil || entrySize > cap(si.buf) {
 		// in case of a replacement we replace back the buffer in the pool
 		if si.buf != nil {
 			BytesBufferPool.Put(si.buf)
 		}
-		si.buf = BytesBufferPool.Get(lineSize).([]byte)
-		if lineSize > cap(si.buf) {
-			si.err = fmt.Errorf("could not get a line buffer of size %d, actual %d", lineSize, cap(si.buf))
-			return 0, nil, false
+		si.buf = BytesBufferPool.Get(entrySize).([]byte)
+		if entrySize > cap(si.buf) {
+			si.err = fmt.Errorf("could not get a line buffer of size %d, actual %d", entrySize, cap(si.buf))
+			return 0, nil, nil, false
 		}
 	}
-	si.buf = si.buf[:lineSize]
+	si.buf = si.buf[:entrySize]
+	headerSize := tWidth + lWidth + mWidth
 	// Take however many bytes are left in the read buffer.
-	n := copy(si.buf, si.readBuf[tWidth+lWidth:si.readBufValid])
+	n := copy(si.buf, si.readBuf[headerSize:si.readBufValid])
 	// Shift down what is still left in the fixed-size read buffer, if any.
-	si.readBufValid = copy(si.readBuf[:], si.readBuf[tWidth+lWidth+n:si.readBufValid])
+	si.readBufValid = copy(si.readBuf[:], si.readBuf[headerSize+n:si.readBufValid])
 
 	// Then process reading the line.
-	for n < lineSize {
-		r, err := si.reader.Read(si.buf[n:lineSize])
+	for n < entrySize {
+		r, err := si.reader.Read(si.buf[n:entrySize])
 		n += r
 		if err != nil {
 			// We might get EOF after reading enough bytes to fill the buffer, which is OK.
@@ -1228,10 +1274,10 @@ func (si *bufferedIterator) moveNext() (int64, []byte, bool) {
 				continue
 			}
 			si.err = err
-			return 0, nil, false
+			return 0, nil, nil, false
 		}
 	}
-	return ts, si.buf[:lineSize], true
+	return ts, si.buf[:lineSize], si.buf[lineSize:entrySize], true
 }
 
 func (si *bufferedIterator) Error() error { return si.err }
@@ -1257,9 +1303,9 @@ func (si *bufferedIterator) close() {
 	si.origBytes = nil
 }
 
-func newEntryIterator(ctx context.Context, pool ReaderPool, b []byte, pipeline log.StreamPipeline) iter.EntryIterator {
+func newEntryIterator(ctx context.Context, pool ReaderPool, b []byte, format byte, pipeline log.StreamPipeline) iter.EntryIterator {
 	return &entryBufferedIterator{
-		bufferedIterator: newBufferedIterator(ctx, pool, b),
+		bufferedIterator: newBufferedIterator(ctx, pool, b, format),
 		pipeline:         pipeline,
 	}
 }
@@ -1282,21 +1328,22 @@ func (e *entryBufferedIterator) StreamHash() uint64 { return e.pipeline.BaseLabe
 
 func (e *entryBufferedIterator) Next() bool {
 	for e.bufferedIterator.Next() {
-		newLine, lbs, matches := e.pipeline.Process(e.currTs, e.currLine)
+		newLine, lbs, matches := e.pipeline.Process(e.currTs, e.currLine, e.currStructuredMetadata...)
 		if !matches {
 			continue
 		}
 		e.cur.Timestamp = time.Unix(0, e.currTs)
 		e.cur.Line = string(newLine)
+		e.cur.StructuredMetadata = logproto.FromLabelsToStructuredMetadata(e.currStructuredMetadata)
 		e.currLabels = lbs
 		return true
 	}
 	return false
 }
 
-func newSampleIterator(ctx context.Context, pool ReaderPool, b []byte, extractor log.StreamSampleExtractor) iter.SampleIterator {
+func newSampleIterator(ctx context.Context, pool ReaderPool, b []byte, format byte, extractor log.StreamSampleExtractor) iter.SampleIterator {
 	it := &sampleBufferedIterator{
-		bufferedIterator: newBufferedIterator(ctx, pool, b),
+		bufferedIterator: newBufferedIterator(ctx, pool, b, format),
 		extractor:        extractor,
 	}
 	return it
@@ -1313,7 +1360,7 @@ type sampleBufferedIterator struct {
 
 func (e *sampleBufferedIterator) Next() bool {
 	for e.bufferedIterator.Next() {
-		val, labels, ok := e.extractor.Process(e.currTs, e.currLine)
+		val, labels, ok := e.extractor.Process(e.currTs, e.currLine, e.currStructuredMetadata...)
 		if !ok {
 			continue
 		}
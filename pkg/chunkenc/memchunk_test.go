@@ -24,6 +24,7 @@ import (
 	"github.com/grafana/loki/pkg/logql/log"
 	"github.com/grafana/loki/pkg/logql/syntax"
 	"github.com/grafana/loki/pkg/logqlmodel/stats"
+	"github.com/grafana/loki/pkg/push"
 	"github.com/grafana/loki/pkg/storage/chunk"
 )
 
@@ -250,8 +251,13 @@ func TestReadFormatV1(t *testing.T) {
 // 2) []byte loaded chunks <-> []byte loaded chunks
 func TestRoundtripV2(t *testing.T) {
 	for _, f := range HeadBlockFmts {
+		versions := []byte{chunkFormatV2, chunkFormatV3}
+		if f == UnorderedWithStructuredMetadataHeadBlockFmt {
+			versions = []byte{chunkFormatV4}
+		}
 		for _, enc := range testEncoding {
-			for _, version := range []byte{chunkFormatV2, chunkFormatV3} {
+			for _, version := range versions {
+				f := f
 				enc := enc
 				version := version
 				t.Run(enc.String(), func(t *testing.T) {
@@ -714,10 +720,10 @@ func BenchmarkWrite(b *testing.B) {
 type nomatchPipeline struct{}
 
 func (nomatchPipeline) BaseLabels() log.LabelsResult { return log.EmptyLabelsResult }
-func (nomatchPipeline) Process(_ int64, line []byte) ([]byte, log.LabelsResult, bool) {
+func (nomatchPipeline) Process(_ int64, line []byte, _ ...labels.Label) ([]byte, log.LabelsResult, bool) {
 	return line, nil, false
 }
-func (nomatchPipeline) ProcessString(_ int64, line string) (string, log.LabelsResult, bool) {
+func (nomatchPipeline) ProcessString(_ int64, line string, _ ...labels.Label) (string, log.LabelsResult, bool) {
 	return line, nil, false
 }
 
@@ -830,7 +836,7 @@ func BenchmarkHeadBlockIterator(b *testing.B) {
 			h := headBlock{}
 
 			for i := 0; i < j; i++ {
-				if err := h.Append(int64(i), "this is the append string"); err != nil {
+				if err := h.Append(int64(i), "this is the append string", nil); err != nil {
 					b.Fatal(err)
 				}
 			}
@@ -854,7 +860,7 @@ func BenchmarkHeadBlockSampleIterator(b *testing.B) {
 			h := headBlock{}
 
 			for i := 0; i < j; i++ {
-				if err := h.Append(int64(i), "this is the append string"); err != nil {
+				if err := h.Append(int64(i), "this is the append string", nil); err != nil {
 					b.Fatal(err)
 				}
 			}
@@ -1012,6 +1018,106 @@ func TestCheckpointEncoding(t *testing.T) {
 	}
 }
 
+func TestMemChunk_StructuredMetadata(t *testing.T) {
+	blockSize, targetSize := 256*1024, 1500*1024
+	metadata := func(i int) push.LabelsAdapter {
+		if i%2 == 0 {
+			return nil
+		}
+		return push.LabelsAdapter{
+			{Name: "traceID", Value: strconv.Itoa(i)},
+			{Name: "user", Value: "a"},
+		}
+	}
+
+	c := NewMemChunk(EncSnappy, UnorderedWithStructuredMetadataHeadBlockFmt, blockSize, targetSize)
+	require.Equal(t, chunkFormatV4, c.format)
+
+	for i := 0; i < 10; i++ {
+		require.Nil(t, c.Append(&logproto.Entry{
+			Timestamp:          time.Unix(int64(i), 0),
+			Line:               fmt.Sprintf("hi there - %d", i),
+			StructuredMetadata: metadata(i),
+		}))
+		// cut a block in the middle, so both blocks and head block are read
+		if i == 4 {
+			require.Nil(t, c.cut())
+		}
+	}
+
+	assertEntries := func(t *testing.T, c *MemChunk) {
+		it, err := c.Iterator(context.Background(), time.Unix(0, 0), time.Unix(100, 0), logproto.FORWARD, noopStreamPipeline)
+		require.NoError(t, err)
+		var i int
+		for it.Next() {
+			require.Equal(t, fmt.Sprintf("hi there - %d", i), it.Entry().Line)
+			require.Equal(t, metadata(i), it.Entry().StructuredMetadata)
+			i++
+		}
+		require.NoError(t, it.Close())
+		require.Equal(t, 10, i)
+
+		// the structured metadata can be queried like the labels of the stream.
+		expr, err := syntax.ParseLogSelector(`{app="foo"} | user="a"`, true)
+		require.NoError(t, err)
+		p, err := expr.Pipeline()
+		require.NoError(t, err)
+		it, err = c.Iterator(context.Background(), time.Unix(0, 0), time.Unix(100, 0), logproto.FORWARD, p.ForStream(labels.FromStrings("app", "foo")))
+		require.NoError(t, err)
+		i = 1
+		for it.Next() {
+			require.Equal(t, fmt.Sprintf("hi there - %d", i), it.Entry().Line)
+			require.Equal(t, labels.FromStrings("app", "foo", "traceID", strconv.Itoa(i), "user", "a").String(), it.Labels())
+			i += 2
+		}
+		require.NoError(t, it.Close())
+		require.Equal(t, 11, i)
+
+		sampleExpr, err := syntax.ParseSampleExpr(`sum by (user) (count_over_time({app="foo"} | user="a" [1m]))`)
+		require.NoError(t, err)
+		extractor, err := sampleExpr.Extractor()
+		require.NoError(t, err)
+		sampleIt := c.SampleIterator(context.Background(), time.Unix(0, 0), time.Unix(100, 0), extractor.ForStream(labels.FromStrings("app", "foo")))
+		var samples int
+		for sampleIt.Next() {
+			require.Equal(t, labels.FromStrings("user", "a").String(), sampleIt.Labels())
+			samples++
+		}
+		require.NoError(t, sampleIt.Close())
+		require.Equal(t, 5, samples)
+	}
+
+	t.Run("in memory", func(t *testing.T) {
+		assertEntries(t, c)
+	})
+
+	t.Run("checkpoint", func(t *testing.T) {
+		var chk, head bytes.Buffer
+		require.Nil(t, c.SerializeForCheckpointTo(&chk, &head))
+
+		// replaying checkpoints always requests unordered head blocks
+		cpy, err := MemchunkFromCheckpoint(chk.Bytes(), head.Bytes(), UnorderedHeadBlockFmt, blockSize, targetSize)
+		require.Nil(t, err)
+		require.Equal(t, UnorderedWithStructuredMetadataHeadBlockFmt, cpy.head.Format())
+		assertEntries(t, cpy)
+	})
+
+	t.Run("bytes", func(t *testing.T) {
+		require.Nil(t, c.Close())
+		b, err := c.Bytes()
+		require.Nil(t, err)
+
+		r, err := NewByteChunk(b, blockSize, targetSize)
+		require.Nil(t, err)
+		assertEntries(t, r)
+	})
+
+	t.Run("convert head", func(t *testing.T) {
+		require.Error(t, c.ConvertHead(OrderedHeadBlockFmt))
+		require.Error(t, NewMemChunk(EncSnappy, UnorderedHeadBlockFmt, blockSize, targetSize).ConvertHead(UnorderedWithStructuredMetadataHeadBlockFmt))
+	})
+}
+
 var (
 	streams = []logproto.Stream{}
 	series  = []logproto.Series{}
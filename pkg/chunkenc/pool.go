@@ -216,10 +216,17 @@ func (pool *FlatePool) PutWriter(writer io.WriteCloser) {
 	pool.writers.Put(writer)
 }
 
-// GzipPool is a gun zip compression pool
+// ZstdPool is a zstd compression pool
 type ZstdPool struct {
 	readers sync.Pool
 	writers sync.Pool
+	level   zstd.EncoderLevel
+}
+
+// SetZstdLevel sets the zstd compression level, from 1 (fastest) to 22 (best compression),
+// of the chunks written afterwards. It must be called before any chunk is written.
+func SetZstdLevel(level int) {
+	Zstd.level = zstd.EncoderLevelFromZstd(level)
 }
 
 // GetReader gets or creates a new CompressionReader and reset it to read from src
@@ -253,7 +260,11 @@ func (pool *ZstdPool) GetWriter(dst io.Writer) io.WriteCloser {
 		return writer
 	}
 
-	w, err := zstd.NewWriter(dst)
+	level := pool.level
+	if level == 0 {
+		level = zstd.SpeedDefault
+	}
+	w, err := zstd.NewWriter(dst, zstd.WithEncoderLevel(level))
 	if err != nil {
 		panic(err) // never happens, error is only returned on wrong compression level.
 	}
@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -58,3 +59,28 @@ func TestPool(t *testing.T) {
 		_ = pprof.Lookup("goroutine").WriteTo(os.Stdout, 1)
 	}
 }
+
+func TestZstdLevel(t *testing.T) {
+	data := bytes.Repeat([]byte("level=info msg=\"hello world\" duration=42ms\n"), 1000)
+	compress := func(pool *ZstdPool) []byte {
+		var buf bytes.Buffer
+		w := pool.GetWriter(&buf)
+		_, err := w.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+
+	fastest := compress(&ZstdPool{level: zstd.EncoderLevelFromZstd(1)})
+	best := compress(&ZstdPool{level: zstd.EncoderLevelFromZstd(22)})
+	require.LessOrEqual(t, len(best), len(fastest))
+
+	for _, b := range [][]byte{fastest, best} {
+		r, err := Zstd.GetReader(bytes.NewReader(b))
+		require.NoError(t, err)
+		res, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, data, res)
+		Zstd.PutReader(r)
+	}
+}
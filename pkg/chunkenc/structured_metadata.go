@@ -0,0 +1,60 @@
+package chunkenc
+
+import (
+	"encoding/binary"
+
+	"github.com/prometheus/prometheus/model/labels"
+)
+
+// structuredMetadataSize returns the uncompressed size of the structured metadata of an entry.
+func structuredMetadataSize(lbs labels.Labels) int {
+	size := 0
+	for _, l := range lbs {
+		size += len(l.Name) + len(l.Value)
+	}
+	return size
+}
+
+// encodeStructuredMetadata appends the encoded structured metadata to b.
+// The encoding is the number of pairs followed by each length-prefixed name and value.
+// Empty structured metadata is encoded to zero bytes.
+func encodeStructuredMetadata(b []byte, lbs labels.Labels) []byte {
+	if len(lbs) == 0 {
+		return b
+	}
+	b = binary.AppendUvarint(b, uint64(len(lbs)))
+	for _, l := range lbs {
+		b = binary.AppendUvarint(b, uint64(len(l.Name)))
+		b = append(b, l.Name...)
+		b = binary.AppendUvarint(b, uint64(len(l.Value)))
+		b = append(b, l.Value...)
+	}
+	return b
+}
+
+// decodeStructuredMetadata decodes structured metadata encoded by encodeStructuredMetadata.
+// The returned labels never reference b.
+func decodeStructuredMetadata(b []byte) (labels.Labels, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+	db := decbuf{b: b}
+	n := db.uvarint()
+	if db.err() != nil {
+		return nil, db.err()
+	}
+	// Every pair needs at least two bytes for the length of its name and value.
+	if n > len(b)/2 {
+		return nil, ErrInvalidSize
+	}
+	lbs := make(labels.Labels, 0, n)
+	for i := 0; i < n && db.err() == nil; i++ {
+		name := string(db.bytes(db.uvarint()))
+		value := string(db.bytes(db.uvarint()))
+		lbs = append(lbs, labels.Label{Name: name, Value: value})
+	}
+	if db.err() != nil {
+		return nil, db.err()
+	}
+	return lbs, nil
+}
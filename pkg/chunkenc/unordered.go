@@ -34,7 +34,7 @@ type HeadBlock interface {
 	Entries() int
 	UncompressedSize() int
 	Convert(HeadBlockFmt) (HeadBlock, error)
-	Append(int64, string) error
+	Append(int64, string, labels.Labels) error
 	Iterator(
 		ctx context.Context,
 		direction logproto.Direction,
@@ -52,6 +52,7 @@ type HeadBlock interface {
 }
 
 type unorderedHeadBlock struct {
+	format HeadBlockFmt
 	// Opted for range tree over skiplist for space reduction.
 	// Inserts: O(log(n))
 	// Scans: (O(k+log(n))) where k=num_scanned_entries & n=total_entries
@@ -62,13 +63,14 @@ type unorderedHeadBlock struct {
 	mint, maxt int64 // upper and lower bounds
 }
 
-func newUnorderedHeadBlock() *unorderedHeadBlock {
+func newUnorderedHeadBlock(format HeadBlockFmt) *unorderedHeadBlock {
 	return &unorderedHeadBlock{
-		rt: rangetree.New(1),
+		format: format,
+		rt:     rangetree.New(1),
 	}
 }
 
-func (hb *unorderedHeadBlock) Format() HeadBlockFmt { return UnorderedHeadBlockFmt }
+func (hb *unorderedHeadBlock) Format() HeadBlockFmt { return hb.format }
 
 func (hb *unorderedHeadBlock) IsEmpty() bool {
 	return hb.size == 0
@@ -87,21 +89,31 @@ func (hb *unorderedHeadBlock) UncompressedSize() int {
 }
 
 func (hb *unorderedHeadBlock) Reset() {
-	x := newUnorderedHeadBlock()
+	x := newUnorderedHeadBlock(hb.format)
 	*hb = *x
 }
 
 // collection of entries belonging to the same nanosecond
 type nsEntries struct {
 	ts      int64
-	entries []string
+	entries []nsEntry
+}
+
+type nsEntry struct {
+	line               string
+	structuredMetadata labels.Labels
 }
 
 func (e *nsEntries) ValueAtDimension(_ uint64) int64 {
 	return e.ts
 }
 
-func (hb *unorderedHeadBlock) Append(ts int64, line string) error {
+func (hb *unorderedHeadBlock) Append(ts int64, line string, structuredMetadata labels.Labels) error {
+	if hb.format < UnorderedWithStructuredMetadataHeadBlockFmt {
+		// structured metadata is not supported by this head block format
+		structuredMetadata = nil
+	}
+
 	// This is an allocation hack. The rangetree lib does not
 	// support the ability to pass a "mutate" function during an insert
 	// and instead will displace any existing entry at the specified timestamp.
@@ -120,14 +132,14 @@ func (hb *unorderedHeadBlock) Append(ts int64, line string) error {
 		// entries at the same time with the same content, iterate through any existing
 		// entries and ignore the line if we already have an entry with the same content
 		for _, et := range displaced[0].(*nsEntries).entries {
-			if et == line {
+			if et.line == line && labels.Equal(et.structuredMetadata, structuredMetadata) {
 				e.entries = displaced[0].(*nsEntries).entries
 				return nil
 			}
 		}
-		e.entries = append(displaced[0].(*nsEntries).entries, line)
+		e.entries = append(displaced[0].(*nsEntries).entries, nsEntry{line, structuredMetadata})
 	} else {
-		e.entries = []string{line}
+		e.entries = []nsEntry{{line, structuredMetadata}}
 	}
 
 	// Update hb metdata
@@ -139,7 +151,7 @@ func (hb *unorderedHeadBlock) Append(ts int64, line string) error {
 		hb.maxt = ts
 	}
 
-	hb.size += len(line)
+	hb.size += len(line) + structuredMetadataSize(structuredMetadata)
 	hb.lines++
 
 	return nil
@@ -162,7 +174,7 @@ func (hb *unorderedHeadBlock) forEntries(
 	direction logproto.Direction,
 	mint,
 	maxt int64,
-	entryFn func(int64, string) error, // returning an error exits early
+	entryFn func(int64, string, labels.Labels) error, // returning an error exits early
 ) (err error) {
 	if hb.IsEmpty() || (maxt < hb.mint || hb.maxt < mint) {
 		return
@@ -191,9 +203,9 @@ func (hb *unorderedHeadBlock) forEntries(
 		}
 
 		for ; i < len(es.entries) && i >= 0; next() {
-			line := es.entries[i]
-			chunkStats.AddHeadChunkBytes(int64(len(line)))
-			err = entryFn(es.ts, line)
+			e := es.entries[i]
+			chunkStats.AddHeadChunkBytes(int64(len(e.line)))
+			err = entryFn(es.ts, e.line, e.structuredMetadata)
 
 		}
 	}
@@ -235,8 +247,8 @@ func (hb *unorderedHeadBlock) Iterator(
 		direction,
 		mint,
 		maxt,
-		func(ts int64, line string) error {
-			newLine, parsedLbs, matches := pipeline.ProcessString(ts, line)
+		func(ts int64, line string, structuredMetadata labels.Labels) error {
+			newLine, parsedLbs, matches := pipeline.ProcessString(ts, line, structuredMetadata...)
 			if !matches {
 				return nil
 			}
@@ -253,8 +265,9 @@ func (hb *unorderedHeadBlock) Iterator(
 			}
 
 			stream.Entries = append(stream.Entries, logproto.Entry{
-				Timestamp: time.Unix(0, ts),
-				Line:      newLine,
+				Timestamp:          time.Unix(0, ts),
+				Line:               newLine,
+				StructuredMetadata: logproto.FromLabelsToStructuredMetadata(structuredMetadata),
 			})
 			return nil
 		},
@@ -284,8 +297,8 @@ func (hb *unorderedHeadBlock) SampleIterator(
 		logproto.FORWARD,
 		mint,
 		maxt,
-		func(ts int64, line string) error {
-			value, parsedLabels, ok := extractor.ProcessString(ts, line)
+		func(ts int64, line string, structuredMetadata labels.Labels) error {
+			value, parsedLabels, ok := extractor.ProcessString(ts, line, structuredMetadata...)
 			if !ok {
 				return nil
 			}
@@ -338,6 +351,7 @@ func (hb *unorderedHeadBlock) Serialise(pool WriterPool) ([]byte, error) {
 	outBuf := &bytes.Buffer{}
 
 	encBuf := make([]byte, binary.MaxVarintLen64)
+	var metaBuf []byte
 	compressedWriter := pool.GetWriter(outBuf)
 	defer pool.PutWriter(compressedWriter)
 
@@ -346,14 +360,24 @@ func (hb *unorderedHeadBlock) Serialise(pool WriterPool) ([]byte, error) {
 		logproto.FORWARD,
 		0,
 		math.MaxInt64,
-		func(ts int64, line string) error {
+		func(ts int64, line string, structuredMetadata labels.Labels) error {
 			n := binary.PutVarint(encBuf, ts)
 			inBuf.Write(encBuf[:n])
 
 			n = binary.PutUvarint(encBuf, uint64(len(line)))
 			inBuf.Write(encBuf[:n])
 
+			if hb.format < UnorderedWithStructuredMetadataHeadBlockFmt {
+				inBuf.WriteString(line)
+				return nil
+			}
+
+			metaBuf = encodeStructuredMetadata(metaBuf[:0], structuredMetadata)
+			n = binary.PutUvarint(encBuf, uint64(len(metaBuf)))
+			inBuf.Write(encBuf[:n])
+
 			inBuf.WriteString(line)
+			inBuf.Write(metaBuf)
 			return nil
 		},
 	)
@@ -369,7 +393,7 @@ func (hb *unorderedHeadBlock) Serialise(pool WriterPool) ([]byte, error) {
 }
 
 func (hb *unorderedHeadBlock) Convert(version HeadBlockFmt) (HeadBlock, error) {
-	if version > OrderedHeadBlockFmt {
+	if hb.format == version {
 		return hb, nil
 	}
 	out := version.NewBlock()
@@ -379,8 +403,8 @@ func (hb *unorderedHeadBlock) Convert(version HeadBlockFmt) (HeadBlock, error) {
 		logproto.FORWARD,
 		0,
 		math.MaxInt64,
-		func(ts int64, line string) error {
-			return out.Append(ts, line)
+		func(ts int64, line string, structuredMetadata labels.Labels) error {
+			return out.Append(ts, line, structuredMetadata)
 		},
 	)
 	return out, err
@@ -392,7 +416,11 @@ func (hb *unorderedHeadBlock) CheckpointSize() int {
 	size += binary.MaxVarintLen32 * 2                                  // total entries + total size
 	size += binary.MaxVarintLen64 * 2                                  // mint,maxt
 	size += (binary.MaxVarintLen64 + binary.MaxVarintLen32) * hb.lines // ts + len of log line.
-	size += hb.size                                                    // uncompressed bytes of lines
+	size += hb.size                                                    // uncompressed bytes of lines and structured metadata
+	if hb.format >= UnorderedWithStructuredMetadataHeadBlockFmt {
+		size += binary.MaxVarintLen32 * hb.lines // len of structured metadata
+		size += binary.MaxVarintLen32 * hb.lines // number of structured metadata pairs
+	}
 	return size
 }
 
@@ -427,12 +455,13 @@ func (hb *unorderedHeadBlock) CheckpointTo(w io.Writer) error {
 	}
 	eb.reset()
 
+	var metaBuf []byte
 	err = hb.forEntries(
 		context.Background(),
 		logproto.FORWARD,
 		0,
 		math.MaxInt64,
-		func(ts int64, line string) error {
+		func(ts int64, line string, structuredMetadata labels.Labels) error {
 			eb.putVarint64(ts)
 			eb.putUvarint(len(line))
 			_, err = w.Write(eb.get())
@@ -445,6 +474,23 @@ func (hb *unorderedHeadBlock) CheckpointTo(w io.Writer) error {
 			if err != nil {
 				return errors.Wrap(err, "write headblock entry line")
 			}
+
+			if hb.format < UnorderedWithStructuredMetadataHeadBlockFmt {
+				return nil
+			}
+
+			metaBuf = encodeStructuredMetadata(metaBuf[:0], structuredMetadata)
+			eb.putUvarint(len(metaBuf))
+			_, err = w.Write(eb.get())
+			if err != nil {
+				return errors.Wrap(err, "write headBlock entry structured metadata length")
+			}
+			eb.reset()
+
+			_, err = w.Write(metaBuf)
+			if err != nil {
+				return errors.Wrap(err, "write headBlock entry structured metadata")
+			}
 			return nil
 		},
 	)
@@ -454,7 +500,7 @@ func (hb *unorderedHeadBlock) CheckpointTo(w io.Writer) error {
 
 func (hb *unorderedHeadBlock) LoadBytes(b []byte) error {
 	// ensure it's empty
-	*hb = *newUnorderedHeadBlock()
+	*hb = *newUnorderedHeadBlock(hb.format)
 
 	if len(b) < 1 {
 		return nil
@@ -467,9 +513,12 @@ func (hb *unorderedHeadBlock) LoadBytes(b []byte) error {
 		return errors.Wrap(db.err(), "verifying headblock header")
 	}
 
-	if version != UnorderedHeadBlockFmt.Byte() {
-		return errors.Errorf("incompatible headBlock version (%v), only V4 is currently supported", version)
+	switch version {
+	case UnorderedHeadBlockFmt.Byte(), UnorderedWithStructuredMetadataHeadBlockFmt.Byte():
+	default:
+		return errors.Errorf("incompatible headBlock version (%v), only V4,V5 are currently supported", version)
 	}
+	hb.format = HeadBlockFmt(version)
 
 	n := db.uvarint()
 
@@ -481,7 +530,19 @@ func (hb *unorderedHeadBlock) LoadBytes(b []byte) error {
 		ts := db.varint64()
 		lineLn := db.uvarint()
 		line := string(db.bytes(lineLn))
-		if err := hb.Append(ts, line); err != nil {
+
+		var structuredMetadata labels.Labels
+		if hb.format >= UnorderedWithStructuredMetadataHeadBlockFmt {
+			metaLn := db.uvarint()
+			if metaLn > 0 && db.err() == nil {
+				var err error
+				if structuredMetadata, err = decodeStructuredMetadata(db.bytes(metaLn)); err != nil {
+					return errors.Wrap(err, "decoding structured metadata")
+				}
+			}
+		}
+
+		if err := hb.Append(ts, line, structuredMetadata); err != nil {
 			return err
 		}
 	}
@@ -508,7 +569,7 @@ func HeadFromCheckpoint(b []byte, desired HeadBlockFmt) (HeadBlock, error) {
 		return nil, errors.Wrap(db.err(), "verifying headblock header")
 	}
 	format := HeadBlockFmt(version)
-	if format > UnorderedHeadBlockFmt {
+	if format > UnorderedWithStructuredMetadataHeadBlockFmt {
 		return nil, fmt.Errorf("unexpected head block version: %v", format)
 	}
 
@@ -0,0 +1,261 @@
+package distributor
+
+import (
+	"encoding/json"
+	"flag"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana/dskit/tenant"
+)
+
+const (
+	// otherStream is the stream of the discards of the streams exceeding the per-stream limit.
+	otherStream = "other"
+
+	discardBucketDuration = time.Minute
+)
+
+// DiscardsConfig configures the tracking of discarded log lines.
+type DiscardsConfig struct {
+	PerStreamLimit int           `yaml:"per_stream_limit"`
+	Window         time.Duration `yaml:"window"`
+}
+
+// RegisterFlagsWithPrefix registers flags for the tracking of discarded log lines.
+func (cfg *DiscardsConfig) RegisterFlagsWithPrefix(prefix string, fs *flag.FlagSet) {
+	fs.IntVar(&cfg.PerStreamLimit, prefix+".per-stream-limit", 0, "Maximum number of streams per tenant and minute whose discards are listed separately by the /distributor/discards endpoint. Discards of further streams are listed with stream=\"other\". 0 to not list the discards per stream.")
+	fs.DurationVar(&cfg.Window, prefix+".window", 15*time.Minute, "Time range of discarded log lines kept in memory to be listed by the /distributor/discards endpoint.")
+}
+
+type discardCount struct {
+	Lines int64 `json:"lines"`
+	Bytes int64 `json:"bytes"`
+}
+
+func (c *discardCount) add(lines, bytes int64) {
+	c.Lines += lines
+	c.Bytes += bytes
+}
+
+type reasonCount struct {
+	discardCount
+	// streams are the counts of the reason per stream, bounded by the per-stream limit.
+	streams map[string]*discardCount
+}
+
+type discardBucket struct {
+	start time.Time
+	// tenant -> reason -> count
+	counts map[string]map[string]*reasonCount
+	// tenant -> streams counted separately in the bucket
+	streams map[string]map[string]struct{}
+}
+
+// discardTracker keeps track of the lines discarded by the distributor, per tenant,
+// reason and, optionally, stream. The discards are also counted in
+// validation.DiscardedSamples and validation.DiscardedBytes by the validator.
+type discardTracker struct {
+	cfg DiscardsConfig
+	now func() time.Time
+
+	mtx     sync.Mutex
+	buckets []*discardBucket // oldest first
+}
+
+func newDiscardTracker(cfg DiscardsConfig) *discardTracker {
+	return &discardTracker{
+		cfg: cfg,
+		now: time.Now,
+	}
+}
+
+func (t *discardTracker) record(tenant, reason, stream string, lines, bytes int) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	b := t.currentBucket()
+	reasons, ok := b.counts[tenant]
+	if !ok {
+		reasons = map[string]*reasonCount{}
+		b.counts[tenant] = reasons
+	}
+	c, ok := reasons[reason]
+	if !ok {
+		c = &reasonCount{streams: map[string]*discardCount{}}
+		reasons[reason] = c
+	}
+	c.add(int64(lines), int64(bytes))
+
+	if t.cfg.PerStreamLimit > 0 {
+		stream = b.streamFor(tenant, stream, t.cfg.PerStreamLimit)
+		sc, ok := c.streams[stream]
+		if !ok {
+			sc = &discardCount{}
+			c.streams[stream] = sc
+		}
+		sc.add(int64(lines), int64(bytes))
+	}
+}
+
+// streamFor returns the stream the discards are counted for in the bucket, bounded by the per-stream limit.
+func (b *discardBucket) streamFor(tenant, stream string, limit int) string {
+	streams, ok := b.streams[tenant]
+	if !ok {
+		streams = map[string]struct{}{}
+		b.streams[tenant] = streams
+	}
+	if _, ok := streams[stream]; ok {
+		return stream
+	}
+	if stream == "" || len(streams) >= limit {
+		return otherStream
+	}
+	streams[stream] = struct{}{}
+	return stream
+}
+
+// currentBucket returns the bucket for the current minute and removes the buckets outside of the window.
+func (t *discardTracker) currentBucket() *discardBucket {
+	now := t.now().Truncate(discardBucketDuration)
+	t.expire(now)
+	if n := len(t.buckets); n > 0 && t.buckets[n-1].start.Equal(now) {
+		return t.buckets[n-1]
+	}
+	b := &discardBucket{
+		start:   now,
+		counts:  map[string]map[string]*reasonCount{},
+		streams: map[string]map[string]struct{}{},
+	}
+	t.buckets = append(t.buckets, b)
+	return b
+}
+
+func (t *discardTracker) expire(now time.Time) {
+	i := 0
+	for i < len(t.buckets) && now.Sub(t.buckets[i].start) >= t.cfg.Window {
+		i++
+	}
+	t.buckets = t.buckets[i:]
+}
+
+// DiscardReason is the number of lines and bytes discarded for a reason.
+type DiscardReason struct {
+	Reason string `json:"reason"`
+	discardCount
+	// Streams are the streams with the most discarded bytes for the reason.
+	Streams []DiscardStream `json:"streams,omitempty"`
+}
+
+// DiscardStream is the number of lines and bytes of a stream discarded for a reason.
+type DiscardStream struct {
+	Stream string `json:"stream"`
+	discardCount
+}
+
+// topReasons returns the reasons of the discards of the tenant in the given
+// time range, sorted by the number of discarded bytes.
+func (t *discardTracker) topReasons(tenant string, since time.Duration, limit int) []DiscardReason {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	now := t.now()
+	t.expire(now.Truncate(discardBucketDuration))
+
+	totals := map[string]*reasonCount{}
+	for _, b := range t.buckets {
+		if now.Sub(b.start) > since {
+			continue
+		}
+		for reason, c := range b.counts[tenant] {
+			total, ok := totals[reason]
+			if !ok {
+				total = &reasonCount{streams: map[string]*discardCount{}}
+				totals[reason] = total
+			}
+			total.add(c.Lines, c.Bytes)
+			for stream, sc := range c.streams {
+				st, ok := total.streams[stream]
+				if !ok {
+					st = &discardCount{}
+					total.streams[stream] = st
+				}
+				st.add(sc.Lines, sc.Bytes)
+			}
+		}
+	}
+
+	result := make([]DiscardReason, 0, len(totals))
+	for reason, c := range totals {
+		result = append(result, DiscardReason{Reason: reason, discardCount: c.discardCount, Streams: topStreams(c.streams, t.cfg.PerStreamLimit)})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Bytes != result[j].Bytes {
+			return result[i].Bytes > result[j].Bytes
+		}
+		return result[i].Reason < result[j].Reason
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// topStreams returns the streams sorted by the number of discarded bytes, at most limit of them.
+func topStreams(streams map[string]*discardCount, limit int) []DiscardStream {
+	if len(streams) == 0 {
+		return nil
+	}
+
+	result := make([]DiscardStream, 0, len(streams))
+	for stream, c := range streams {
+		result = append(result, DiscardStream{Stream: stream, discardCount: *c})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Bytes != result[j].Bytes {
+			return result[i].Bytes > result[j].Bytes
+		}
+		return result[i].Stream < result[j].Stream
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// DiscardsHandler lists the top reasons of discarded log lines of the tenant of the request.
+// It accepts the optional query parameters minutes (defaults to the
+// configured window) and limit (defaults to 10).
+func (d *Distributor) DiscardsHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenant.TenantID(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	since := d.cfg.Discards.Window
+	if v := r.FormValue("minutes"); v != "" {
+		minutes, err := strconv.Atoi(v)
+		if err != nil || minutes <= 0 {
+			http.Error(w, "invalid minutes parameter", http.StatusBadRequest)
+			return
+		}
+		since = time.Duration(minutes) * time.Minute
+	}
+	limit := 10
+	if v := r.FormValue("limit"); v != "" {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil || limit < 0 {
+			http.Error(w, "invalid limit parameter", http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(d.discards.topReasons(tenantID, since, limit)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
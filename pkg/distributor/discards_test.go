@@ -0,0 +1,128 @@
+package distributor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/flagext"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/loki/pkg/validation"
+)
+
+func newTestDiscardTracker(cfg DiscardsConfig, now *time.Time) *discardTracker {
+	t := newDiscardTracker(cfg)
+	t.now = func() time.Time { return *now }
+	return t
+}
+
+func TestDiscardTracker_TopReasons(t *testing.T) {
+	now := time.Unix(1000*60, 0)
+	tracker := newTestDiscardTracker(DiscardsConfig{Window: 10 * time.Minute}, &now)
+
+	tracker.record("a", validation.LineTooLong, `{app="foo"}`, 1, 100)
+	tracker.record("a", validation.RateLimited, `{app="foo"}`, 10, 50)
+	tracker.record("b", validation.RateLimited, `{app="foo"}`, 1, 10)
+	now = now.Add(5 * time.Minute)
+	tracker.record("a", validation.RateLimited, `{app="bar"}`, 10, 100)
+
+	require.Equal(t, []DiscardReason{
+		{Reason: validation.RateLimited, discardCount: discardCount{Lines: 20, Bytes: 150}},
+		{Reason: validation.LineTooLong, discardCount: discardCount{Lines: 1, Bytes: 100}},
+	}, tracker.topReasons("a", 10*time.Minute, 0))
+	require.Equal(t, []DiscardReason{
+		{Reason: validation.RateLimited, discardCount: discardCount{Lines: 1, Bytes: 10}},
+	}, tracker.topReasons("b", 10*time.Minute, 0))
+
+	// Filter by time range and number of reasons.
+	require.Equal(t, []DiscardReason{
+		{Reason: validation.RateLimited, discardCount: discardCount{Lines: 10, Bytes: 100}},
+	}, tracker.topReasons("a", time.Minute, 0))
+	require.Len(t, tracker.topReasons("a", 10*time.Minute, 1), 1)
+	require.Empty(t, tracker.topReasons("c", 10*time.Minute, 0))
+
+	// Discards older than the window are forgotten.
+	now = now.Add(6 * time.Minute)
+	require.Equal(t, []DiscardReason{
+		{Reason: validation.RateLimited, discardCount: discardCount{Lines: 10, Bytes: 100}},
+	}, tracker.topReasons("a", 10*time.Minute, 0))
+	require.Empty(t, tracker.topReasons("b", 10*time.Minute, 0))
+}
+
+func TestDiscardTracker_PerStreamLimit(t *testing.T) {
+	now := time.Unix(1000*60, 0)
+	tracker := newTestDiscardTracker(DiscardsConfig{Window: 10 * time.Minute, PerStreamLimit: 1}, &now)
+
+	tracker.record("a", validation.LineTooLong, `{app="foo"}`, 1, 100)
+	tracker.record("a", validation.LineTooLong, `{app="bar"}`, 2, 200)
+	tracker.record("a", validation.LineTooLong, `{app="foo"}`, 1, 100)
+	tracker.record("a", validation.RateLimited, "", 1, 10)
+
+	require.Equal(t, []DiscardReason{
+		{
+			Reason:       validation.LineTooLong,
+			discardCount: discardCount{Lines: 4, Bytes: 400},
+			Streams:      []DiscardStream{{Stream: otherStream, discardCount: discardCount{Lines: 2, Bytes: 200}}},
+		},
+		{
+			Reason:       validation.RateLimited,
+			discardCount: discardCount{Lines: 1, Bytes: 10},
+			Streams:      []DiscardStream{{Stream: otherStream, discardCount: discardCount{Lines: 1, Bytes: 10}}},
+		},
+	}, tracker.topReasons("a", 10*time.Minute, 0))
+
+	// The streams are bounded per minute.
+	now = now.Add(time.Minute)
+	tracker.record("a", validation.LineTooLong, `{app="bar"}`, 3, 300)
+	require.Equal(t, []DiscardStream{
+		{Stream: `{app="bar"}`, discardCount: discardCount{Lines: 3, Bytes: 300}},
+	}, tracker.topReasons("a", 10*time.Minute, 0)[0].Streams)
+}
+
+func TestDistributor_DiscardsHandler(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.EnforceMetricName = false
+	limits.MaxLineSize = 5
+	distributors, _ := prepare(t, 1, 3, limits, nil)
+
+	_, err := distributors[0].Push(ctx, makeWriteRequest(10, 10))
+	require.Error(t, err)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/distributor/discards?minutes=5", nil)
+	distributors[0].DiscardsHandler(rec, req.WithContext(user.InjectOrgID(req.Context(), "test")))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp []struct {
+		Reason string `json:"reason"`
+		Lines  int64  `json:"lines"`
+		Bytes  int64  `json:"bytes"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Len(t, resp, 1)
+	require.Equal(t, validation.LineTooLong, resp[0].Reason)
+	require.Equal(t, int64(10), resp[0].Lines)
+	require.Equal(t, int64(100), resp[0].Bytes)
+
+	// The discards of other tenants aren't listed.
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/distributor/discards", nil)
+	distributors[0].DiscardsHandler(rec, req.WithContext(user.InjectOrgID(req.Context(), "other")))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `[]`, rec.Body.String())
+
+	// The tenant of the request is required.
+	rec = httptest.NewRecorder()
+	distributors[0].DiscardsHandler(rec, httptest.NewRequest(http.MethodGet, "/distributor/discards", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/distributor/discards?minutes=x", nil)
+	distributors[0].DiscardsHandler(rec, req.WithContext(user.InjectOrgID(req.Context(), "test")))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -10,13 +10,16 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/grafana/loki/pkg/ingester"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
 
+	"github.com/cespare/xxhash/v2"
 	"github.com/grafana/dskit/kv"
 	"github.com/grafana/dskit/limiter"
 	"github.com/grafana/dskit/ring"
@@ -34,7 +37,10 @@ import (
 
 	"github.com/grafana/loki/pkg/analytics"
 	"github.com/grafana/loki/pkg/distributor/clientpool"
+	"github.com/grafana/loki/pkg/distributor/esbulk"
 	"github.com/grafana/loki/pkg/distributor/shardstreams"
+	"github.com/grafana/loki/pkg/distributor/syslog"
+	"github.com/grafana/loki/pkg/distributor/tee"
 	"github.com/grafana/loki/pkg/distributor/writefailures"
 	"github.com/grafana/loki/pkg/ingester/client"
 	"github.com/grafana/loki/pkg/logproto"
@@ -42,6 +48,7 @@ import (
 	"github.com/grafana/loki/pkg/runtime"
 	"github.com/grafana/loki/pkg/storage/stores/indexshipper/compactor/retention"
 	"github.com/grafana/loki/pkg/util"
+	"github.com/grafana/loki/pkg/util/flagext"
 	util_log "github.com/grafana/loki/pkg/util/log"
 	"github.com/grafana/loki/pkg/validation"
 )
@@ -55,6 +62,8 @@ const (
 var (
 	maxLabelCacheSize = 100000
 	rfStats           = analytics.NewInt("distributor_replication_factor")
+
+	errStreamDropped = errors.New("stream dropped by relabeling")
 )
 
 // Config for a Distributor.
@@ -70,6 +79,27 @@ type Config struct {
 
 	// WriteFailuresLoggingCfg customizes write failures logging behavior.
 	WriteFailuresLogging writefailures.Cfg `yaml:"write_failures_logging" doc:"description=Experimental. Customize the logging of write failures."`
+
+	// Tee customizes the forwarding of accepted pushes to additional Loki endpoints.
+	Tee tee.Config `yaml:"tee" doc:"description=Experimental. Asynchronously forward accepted pushes to additional Loki endpoints, e.g. during cluster migrations."`
+
+	// Syslog configures the listener of syslog messages.
+	Syslog syslog.Config `yaml:"syslog" doc:"description=Experimental. Receive RFC5424 syslog messages over TCP, optionally with TLS, and UDP and push them as log streams."`
+
+	// ElasticsearchBulk configures the Elasticsearch Bulk API compatible push endpoint.
+	ElasticsearchBulk esbulk.Config `yaml:"elasticsearch_bulk" doc:"description=Experimental. Accept pushes of Elasticsearch shippers like Filebeat and Logstash at /es/_bulk."`
+
+	// HATrackerConfig configures the deduplication of pushes of redundant pairs of agents.
+	HATrackerConfig HATrackerConfig `yaml:"ha_tracker"`
+
+	// Discards configures the tracking of discarded log lines.
+	Discards DiscardsConfig `yaml:"discards"`
+
+	MaxDecompressedPushSize flagext.ByteSize `yaml:"max_decompressed_push_size"`
+
+	// Status codes of pushes rejected by the tenant and the per-stream rate limits.
+	RateLimitedStatusCode          int `yaml:"rate_limited_status_code"`
+	PerStreamRateLimitedStatusCode int `yaml:"per_stream_rate_limited_status_code"`
 }
 
 // RegisterFlags registers distributor-related flags.
@@ -77,6 +107,28 @@ func (cfg *Config) RegisterFlags(fs *flag.FlagSet) {
 	cfg.DistributorRing.RegisterFlags(fs)
 	cfg.RateStore.RegisterFlagsWithPrefix("distributor.rate-store", fs)
 	cfg.WriteFailuresLogging.RegisterFlagsWithPrefix("distributor.write-failures-logging", fs)
+	cfg.Tee.RegisterFlagsWithPrefix("distributor.tee", fs)
+	cfg.Syslog.RegisterFlagsWithPrefix("distributor.syslog", fs)
+	cfg.ElasticsearchBulk.RegisterFlagsWithPrefix("distributor.elasticsearch-bulk", fs)
+	cfg.HATrackerConfig.RegisterFlags(fs)
+	cfg.Discards.RegisterFlagsWithPrefix("distributor.discards", fs)
+	_ = cfg.MaxDecompressedPushSize.Set("100MB")
+	fs.Var(&cfg.MaxDecompressedPushSize, "distributor.max-decompressed-push-size", "Maximum size of the decompressed body of a push request. 0 to disable.")
+	fs.IntVar(&cfg.RateLimitedStatusCode, "distributor.rate-limited-status-code", http.StatusTooManyRequests, "HTTP status code of pushes rejected by the tenant ingestion rate limit. Either 429, which clients retry, or 400, which they don't.")
+	fs.IntVar(&cfg.PerStreamRateLimitedStatusCode, "distributor.per-stream-rate-limited-status-code", http.StatusTooManyRequests, "HTTP status code of pushes rejected by the distributor per-stream rate limit. Either 429, which clients retry, or 400, which they don't.")
+}
+
+// Validate validates the distributor config.
+func (cfg *Config) Validate() error {
+	for _, code := range []int{cfg.RateLimitedStatusCode, cfg.PerStreamRateLimitedStatusCode} {
+		if code != http.StatusTooManyRequests && code != http.StatusBadRequest {
+			return fmt.Errorf("invalid rate limited status code %d, must be %d or %d", code, http.StatusTooManyRequests, http.StatusBadRequest)
+		}
+	}
+	if err := cfg.ElasticsearchBulk.Validate(); err != nil {
+		return err
+	}
+	return cfg.HATrackerConfig.Validate()
 }
 
 // RateStore manages the ingestion rate of streams, populated by data fetched from ingesters.
@@ -111,16 +163,31 @@ type Distributor struct {
 	subservicesWatcher *services.FailureWatcher
 	// Per-user rate limiter.
 	ingestionRateLimiter *limiter.RateLimiter
+	streamRateLimiter    *streamRateLimiter
 	labelCache           *lru.Cache
 
 	// Push failures rate limiter.
 	writeFailuresManager *writefailures.Manager
 
+	// Forwards accepted pushes to additional endpoints, nil if disabled.
+	tee *tee.Tee
+
+	// Receives syslog messages, nil if disabled.
+	syslog *syslog.Syslog
+
+	// Deduplicates pushes of redundant pairs of agents, nil if disabled.
+	haTracker *haTracker
+
+	// Tracks the discarded lines per tenant and reason.
+	discards *discardTracker
+
 	// metrics
 	ingesterAppends        *prometheus.CounterVec
 	ingesterAppendFailures *prometheus.CounterVec
 	replicationFactor      prometheus.Gauge
 	streamShardCount       prometheus.Counter
+	droppedLines           *prometheus.CounterVec
+	droppedBytes           *prometheus.CounterVec
 }
 
 // New a distributor creates.
@@ -195,9 +262,22 @@ func New(
 			Name:      "stream_sharding_count",
 			Help:      "Total number of times the distributor has sharded streams",
 		}),
+		droppedLines: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki",
+			Name:      "distributor_drop_rule_discarded_lines_total",
+			Help:      "The total number of log lines discarded by a drop rule.",
+		}, []string{"tenant", "rule"}),
+		droppedBytes: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki",
+			Name:      "distributor_drop_rule_discarded_bytes_total",
+			Help:      "The total number of bytes of log lines discarded by a drop rule.",
+		}, []string{"tenant", "rule"}),
 		writeFailuresManager: writefailures.NewManager(util_log.Logger, cfg.WriteFailuresLogging, configs),
 	}
 
+	d.discards = newDiscardTracker(cfg.Discards)
+	validator.discards = d.discards
+
 	if overrides.IngestionRateStrategy() == validation.GlobalIngestionRateStrategy {
 		d.rateLimitStrat = validation.GlobalIngestionRateStrategy
 
@@ -214,6 +294,11 @@ func New(
 	}
 
 	d.ingestionRateLimiter = limiter.NewRateLimiter(ingestionRateStrategy, 10*time.Second)
+	if d.rateLimitStrat == validation.GlobalIngestionRateStrategy {
+		d.streamRateLimiter = newStreamRateLimiter(overrides, d)
+	} else {
+		d.streamRateLimiter = newStreamRateLimiter(overrides, nil)
+	}
 	d.distributorsRing = distributorsRing
 	d.distributorsLifecycler = distributorsLifecycler
 
@@ -234,7 +319,31 @@ func New(
 	)
 	d.rateStore = rs
 
-	servs = append(servs, d.pool, rs)
+	servs = append(servs, d.pool, rs, d.streamRateLimiter)
+
+	if cfg.Tee.Enabled() {
+		d.tee, err = tee.New(cfg.Tee, registerer, util_log.Logger)
+		if err != nil {
+			return nil, errors.Wrap(err, "tee")
+		}
+		servs = append(servs, d.tee)
+	}
+
+	if cfg.Syslog.Enabled() {
+		d.syslog, err = syslog.New(cfg.Syslog, d, registerer, util_log.Logger)
+		if err != nil {
+			return nil, errors.Wrap(err, "syslog")
+		}
+		servs = append(servs, d.syslog)
+	}
+
+	if cfg.HATrackerConfig.Enabled {
+		d.haTracker, err = newHATracker(cfg.HATrackerConfig, util_log.Logger, registerer)
+		if err != nil {
+			return nil, errors.Wrap(err, "HA tracker")
+		}
+		servs = append(servs, d.haTracker)
+	}
 	d.subservices, err = services.NewManager(servs...)
 	if err != nil {
 		return nil, errors.Wrap(err, "services manager")
@@ -263,6 +372,19 @@ func (d *Distributor) stopping(_ error) error {
 	return services.StopManagerAndAwaitStopped(context.Background(), d.subservices)
 }
 
+// truncateLine shortens the line to at most maxSize bytes including the marker,
+// without splitting a multi-byte character.
+func truncateLine(line string, maxSize int, marker string) string {
+	if len(marker) >= maxSize {
+		marker = ""
+	}
+	cut := maxSize - len(marker)
+	for cut > 0 && !utf8.RuneStart(line[cut]) {
+		cut--
+	}
+	return line[:cut] + marker
+}
+
 // TODO taken from Cortex, see if we can refactor out an usable interface.
 type streamTracker struct {
 	stream      logproto.Stream
@@ -301,8 +423,28 @@ func (d *Distributor) Push(ctx context.Context, req *logproto.PushRequest) (*log
 	validatedLineSize := 0
 	validatedLineCount := 0
 
+	// Streams to forward to the tee, before sharding.
+	var teeStreams []logproto.Stream
+
 	var validationErr error
-	validationContext := d.validator.getValidationContextForTime(time.Now(), tenantID)
+	// Set if a stream exceeded its rate limit, the push is then rejected with the per-stream rate limited status code.
+	var streamRateLimited *rateLimitedPush
+	now := time.Now()
+	validationContext := d.validator.getValidationContextForTime(now, tenantID)
+
+	if d.haTracker != nil && d.validator.AcceptHASamples(tenantID) {
+		removeReplicaLabel, err := d.checkHAReplica(ctx, tenantID, req)
+		if err != nil {
+			return nil, err
+		}
+		if removeReplicaLabel {
+			validationContext.haReplicaLabel = d.validator.HAReplicaLabel(tenantID)
+		}
+	}
+
+	if pipelines := d.validator.IngestionPipelines(tenantID); len(pipelines) > 0 {
+		req.Streams = processPipelines(tenantID, pipelines, req.Streams)
+	}
 
 	func() {
 		sp := opentracing.SpanFromContext(ctx)
@@ -321,18 +463,30 @@ func (d *Distributor) Push(ctx context.Context, req *logproto.PushRequest) (*log
 			// Truncate first so subsequent steps have consistent line lengths
 			d.truncateLines(validationContext, &stream)
 
+			rawLabels := stream.Labels
 			stream.Labels, stream.Hash, err = d.parseStreamLabels(validationContext, stream.Labels, &stream)
 			if err != nil {
-				validationErr = err
-				validation.DiscardedSamples.WithLabelValues(validation.InvalidLabels, tenantID).Add(float64(len(stream.Entries)))
+				reason := validation.InvalidLabels
+				if errors.Is(err, errStreamDropped) {
+					reason = validation.RelabelDropped
+				} else {
+					validationErr = err
+				}
 				bytes := 0
 				for _, e := range stream.Entries {
 					bytes += len(e.Line)
 				}
-				validation.DiscardedBytes.WithLabelValues(validation.InvalidLabels, tenantID).Add(float64(bytes))
+				d.validator.reportDiscarded(tenantID, reason, rawLabels, len(stream.Entries), bytes)
 				continue
 			}
 
+			if len(validationContext.dropRules) > 0 || len(validationContext.samplingRules) > 0 {
+				d.filterEntries(validationContext, &stream)
+				if len(stream.Entries) == 0 {
+					continue
+				}
+			}
+
 			n := 0
 			pushSize := 0
 			for _, entry := range stream.Entries {
@@ -363,19 +517,45 @@ func (d *Distributor) Push(ctx context.Context, req *logproto.PushRequest) (*log
 			}
 			stream.Entries = stream.Entries[:n]
 
+			var derivedKeys []uint32
+			var derivedStreams []streamTracker
 			shardStreamsCfg := d.validator.Limits.ShardStreams(tenantID)
 			if shardStreamsCfg.Enabled {
-				derivedKeys, derivedStreams := d.shardStream(stream, pushSize, tenantID)
-				keys = append(keys, derivedKeys...)
-				streams = append(streams, derivedStreams...)
+				derivedKeys, derivedStreams = d.shardStream(stream, pushSize, tenantID)
 			} else {
-				keys = append(keys, util.TokenFor(tenantID, stream.Labels))
-				streams = append(streams, streamTracker{stream: stream})
+				derivedKeys, derivedStreams = []uint32{util.TokenFor(tenantID, stream.Labels)}, []streamTracker{{stream: stream}}
+			}
+
+			// The per-stream rate limit applies to the streams sent to the ingesters, so to each shard of a sharded stream.
+			var teeEntries []logproto.Entry
+			for i, derived := range derivedStreams {
+				size := streamSize(derived.stream)
+				if limited, err := d.checkStreamRateLimit(now, tenantID, derived.stream, size); err != nil {
+					validatedLineCount -= len(derived.stream.Entries)
+					validatedLineSize -= size
+					validationErr = err
+					if limited != nil && (streamRateLimited == nil || size > streamRateLimited.bytes) {
+						streamRateLimited = limited
+					}
+					continue
+				}
+
+				if d.tee != nil {
+					teeEntries = append(teeEntries, derived.stream.Entries...)
+				}
+				keys = append(keys, derivedKeys[i])
+				streams = append(streams, derived)
+			}
+
+			if len(teeEntries) > 0 {
+				teeStreams = append(teeStreams, logproto.Stream{Labels: stream.Labels, Hash: stream.Hash, Entries: teeEntries})
 			}
 		}
 	}()
 
-	if validationErr != nil {
+	if streamRateLimited != nil {
+		validationErr = streamRateLimited.error(d.cfg.PerStreamRateLimitedStatusCode, tenantID, validationErr.Error())
+	} else if validationErr != nil {
 		validationErr = httpgrpc.Errorf(http.StatusBadRequest, validationErr.Error())
 	}
 
@@ -384,15 +564,22 @@ func (d *Distributor) Push(ctx context.Context, req *logproto.PushRequest) (*log
 		return &logproto.PushResponse{}, validationErr
 	}
 
-	now := time.Now()
 	if !d.ingestionRateLimiter.AllowN(now, tenantID, validatedLineSize) {
 		// Return a 429 to indicate to the client they are being rate limited
-		validation.DiscardedSamples.WithLabelValues(validation.RateLimited, tenantID).Add(float64(validatedLineCount))
-		validation.DiscardedBytes.WithLabelValues(validation.RateLimited, tenantID).Add(float64(validatedLineSize))
+		// The tenant limit applies to the whole push, so the discards are not attributed to streams.
+		d.validator.reportDiscarded(tenantID, validation.RateLimited, "", validatedLineCount, validatedLineSize)
 
-		err = fmt.Errorf(validation.RateLimitedErrorMsg, tenantID, int(d.ingestionRateLimiter.Limit(now, tenantID)), validatedLineCount, validatedLineSize)
+		limit := d.ingestionRateLimiter.Limit(now, tenantID)
+		err = fmt.Errorf(validation.RateLimitedErrorMsg, tenantID, int(limit), validatedLineCount, validatedLineSize)
 		d.writeFailuresManager.Log(tenantID, err)
-		return nil, httpgrpc.Errorf(http.StatusTooManyRequests, err.Error())
+		rateLimited := rateLimitedPush{reason: validation.RateLimited, limit: limit, bytes: validatedLineSize}
+		return nil, rateLimited.error(d.cfg.RateLimitedStatusCode, tenantID, err.Error())
+	}
+
+	// The per-stream rate limits are only consumed once the push passed the tenant rate limit.
+	// The concurrent pushes of a stream checked against the same tokens can exceed its limit by the size of one push.
+	for _, s := range streams {
+		d.streamRateLimiter.AllowN(now, tenantID, s.stream.Hash, streamSize(s.stream))
 	}
 
 	const maxExpectedReplicationSet = 5 // typical replication factor 3 plus one for inactive plus one for luck
@@ -449,12 +636,46 @@ func (d *Distributor) Push(ctx context.Context, req *logproto.PushRequest) (*log
 	case err := <-tracker.err:
 		return nil, err
 	case <-tracker.done:
+		if d.tee != nil {
+			d.tee.Duplicate(tenantID, teeStreams)
+		}
 		return &logproto.PushResponse{}, validationErr
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	}
 }
 
+// checkStreamRateLimit checks the push of a stream against its per-stream rate limit, without consuming it.
+// The lines of a rejected stream are reported as discarded. The returned rateLimitedPush is set
+// for the streams that exceeded their limit, but not for the pushes larger than the burst,
+// which are rejected as invalid since they can never be accepted.
+func (d *Distributor) checkStreamRateLimit(now time.Time, tenantID string, stream logproto.Stream, size int) (*rateLimitedPush, error) {
+	res, limit, burst := d.streamRateLimiter.Check(now, tenantID, stream.Hash, size)
+	if res == streamRateLimitAllowed {
+		return nil, nil
+	}
+
+	d.validator.reportDiscarded(tenantID, validation.StreamRateLimit, stream.Labels, len(stream.Entries), size)
+	if res == streamRateLimitBurstExceeded {
+		err := fmt.Errorf(validation.StreamRateLimitBurstErrorMsg, stream.Labels, flagext.ByteSize(size).String(), flagext.ByteSize(burst).String())
+		d.writeFailuresManager.Log(tenantID, err)
+		return nil, err
+	}
+
+	err := &validation.ErrStreamRateLimit{RateLimit: flagext.ByteSize(limit), Labels: stream.Labels, Bytes: flagext.ByteSize(size)}
+	d.writeFailuresManager.Log(tenantID, err)
+	return &rateLimitedPush{reason: validation.StreamRateLimit, limit: float64(limit), bytes: size}, err
+}
+
+// streamSize returns the size of the lines of the stream.
+func streamSize(stream logproto.Stream) int {
+	size := 0
+	for _, e := range stream.Entries {
+		size += len(e.Line)
+	}
+	return size
+}
+
 // shardStream shards (divides) the given stream into N smaller streams, where
 // N is the sharding size for the given stream. shardSteam returns the smaller
 // streams and their associated keys for hashing to ingesters.
@@ -580,10 +801,10 @@ func (d *Distributor) truncateLines(vContext validationContext, stream *logproto
 	var truncatedSamples, truncatedBytes int
 	for i, e := range stream.Entries {
 		if maxSize := vContext.maxLineSize; maxSize != 0 && len(e.Line) > maxSize {
-			stream.Entries[i].Line = e.Line[:maxSize]
+			stream.Entries[i].Line = truncateLine(e.Line, maxSize, vContext.truncateMarker)
 
 			truncatedSamples++
-			truncatedBytes += len(e.Line) - maxSize
+			truncatedBytes += len(e.Line) - len(stream.Entries[i].Line)
 		}
 	}
 
@@ -650,10 +871,48 @@ type labelData struct {
 	hash   uint64
 }
 
+// checkHAReplica checks with the HA tracker whether the push comes from the elected replica.
+// The cluster and replica are taken from the labels of the first stream, as all streams of a push
+// are expected to come from the same agent. It returns whether the replica label must be removed
+// from the streams, or an error if the push must be dropped.
+func (d *Distributor) checkHAReplica(ctx context.Context, tenantID string, req *logproto.PushRequest) (bool, error) {
+	ls, err := syntax.ParseLabels(req.Streams[0].Labels)
+	if err != nil {
+		// Invalid labels are reported by the validation.
+		return false, nil
+	}
+	cluster, replica := ls.Get(d.validator.HAClusterLabel(tenantID)), ls.Get(d.validator.HAReplicaLabel(tenantID))
+	if cluster == "" || replica == "" {
+		return false, nil
+	}
+
+	err = d.haTracker.checkReplica(ctx, tenantID, cluster, replica, time.Now())
+	if errors.As(err, &replicasNotMatchError{}) {
+		lines := 0
+		for _, stream := range req.Streams {
+			lines += len(stream.Entries)
+		}
+		d.haTracker.dedupedLines.WithLabelValues(tenantID, cluster).Add(float64(lines))
+		// Return a 202 to indicate to the agent that the push was accepted, but not ingested.
+		return false, httpgrpc.Errorf(http.StatusAccepted, err.Error())
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// parseStreamLabels parses, relabels and validates the labels of the given stream.
+// It returns errStreamDropped if the stream was dropped by the tenant's relabel configs.
 func (d *Distributor) parseStreamLabels(vContext validationContext, key string, stream *logproto.Stream) (string, uint64, error) {
-	if val, ok := d.labelCache.Get(key); ok {
-		labelVal := val.(labelData)
-		return labelVal.labels, labelVal.hash, nil
+	// The label cache is shared by all tenants, so it must not be used for tenants that rewrite their labels.
+	relabeling := len(vContext.relabelConfigs) > 0
+	skipCache := relabeling || vContext.haReplicaLabel != ""
+	if !skipCache {
+		if val, ok := d.labelCache.Get(key); ok {
+			labelVal := val.(labelData)
+			return labelVal.labels, labelVal.hash, nil
+		}
 	}
 
 	ls, err := syntax.ParseLabels(key)
@@ -661,6 +920,17 @@ func (d *Distributor) parseStreamLabels(vContext validationContext, key string,
 		return "", 0, fmt.Errorf(validation.InvalidLabelsErrorMsg, key, err)
 	}
 
+	if relabeling {
+		var keep bool
+		if ls, keep = relabel.Process(ls, vContext.relabelConfigs...); !keep {
+			return "", 0, errStreamDropped
+		}
+	}
+
+	if vContext.haReplicaLabel != "" {
+		ls = labels.NewBuilder(ls).Del(vContext.haReplicaLabel).Labels()
+	}
+
 	if err := d.validator.ValidateLabels(vContext, ls, *stream); err != nil {
 		return "", 0, err
 	}
@@ -668,10 +938,71 @@ func (d *Distributor) parseStreamLabels(vContext validationContext, key string,
 	lsVal := ls.String()
 	lsHash := ls.Hash()
 
-	d.labelCache.Add(key, labelData{lsVal, lsHash})
+	if !skipCache {
+		d.labelCache.Add(key, labelData{lsVal, lsHash})
+	}
 	return lsVal, lsHash, nil
 }
 
+// filterEntries removes the entries of the stream matching any of the tenant's drop rules
+// and the entries not kept by the first sampling rule matching the stream.
+func (d *Distributor) filterEntries(vContext validationContext, stream *logproto.Stream) {
+	ls, err := syntax.ParseLabels(stream.Labels)
+	if err != nil {
+		// The labels have already been validated.
+		return
+	}
+
+	var sampling *validation.SamplingRule
+	for i := range vContext.samplingRules {
+		if vContext.samplingRules[i].Matches(ls) {
+			sampling = &vContext.samplingRules[i]
+			break
+		}
+	}
+
+	n := 0
+	sampledLines, sampledBytes := 0, 0
+	for _, entry := range stream.Entries {
+		if d.matchesDropRule(vContext, ls, entry) {
+			continue
+		}
+		if sampling != nil && !keepSample(entry.Line, sampling.Rate) {
+			sampledLines++
+			sampledBytes += len(entry.Line)
+			continue
+		}
+		stream.Entries[n] = entry
+		n++
+	}
+	stream.Entries = stream.Entries[:n]
+
+	if sampledLines > 0 {
+		d.validator.reportDiscarded(vContext.userID, validation.Sampled, stream.Labels, sampledLines, sampledBytes)
+	}
+}
+
+func (d *Distributor) matchesDropRule(vContext validationContext, ls labels.Labels, entry logproto.Entry) bool {
+	for i := range vContext.dropRules {
+		rule := &vContext.dropRules[i]
+		if rule.Matches(ls, entry.Line) {
+			d.droppedLines.WithLabelValues(vContext.userID, rule.Name).Inc()
+			d.droppedBytes.WithLabelValues(vContext.userID, rule.Name).Add(float64(len(entry.Line)))
+			return true
+		}
+	}
+	return false
+}
+
+// keepSample returns whether the line is kept at the given sampling rate.
+// It only depends on the line, so all distributors take the same decision.
+func keepSample(line string, rate float64) bool {
+	if rate >= 1 {
+		return true
+	}
+	return float64(xxhash.Sum64String(line)) < rate*math.MaxUint64
+}
+
 // shardCountFor returns the right number of shards to be used by the given stream.
 //
 // It first checks if the number of shards is present in the shard store. If it isn't it will calculate it
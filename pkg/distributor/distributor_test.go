@@ -20,6 +20,7 @@ import (
 	ring_client "github.com/grafana/dskit/ring/client"
 	"github.com/grafana/dskit/services"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -32,6 +33,7 @@ import (
 	"github.com/grafana/loki/pkg/ingester/client"
 	"github.com/grafana/loki/pkg/logproto"
 	"github.com/grafana/loki/pkg/logql/syntax"
+	"github.com/grafana/loki/pkg/ruler/util"
 	"github.com/grafana/loki/pkg/runtime"
 	fe "github.com/grafana/loki/pkg/util/flagext"
 	loki_flagext "github.com/grafana/loki/pkg/util/flagext"
@@ -95,7 +97,7 @@ func TestDistributor(t *testing.T) {
 
 			response, err := distributors[i%len(distributors)].Push(ctx, request)
 			assert.Equal(t, tc.expectedResponse, response)
-			assert.Equal(t, tc.expectedError, err)
+			assert.Equal(t, tc.expectedError, withoutHeaders(err))
 		})
 	}
 }
@@ -445,6 +447,241 @@ func Test_SortLabelsOnPush(t *testing.T) {
 	require.Equal(t, `{a="b", buzz="f"}`, ingester.pushed[0].Streams[0].Labels)
 }
 
+func Test_RelabelOnPush(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.EnforceMetricName = false
+	limits.IngestionRelabelConfigs = []*util.RelabelConfig{
+		{SourceLabels: []string{"app"}, TargetLabel: "service", Action: "replace"},
+		{Regex: "app", Action: "labeldrop"},
+		{SourceLabels: []string{"env"}, Regex: "dev", Action: "drop"},
+	}
+	require.NoError(t, limits.Validate())
+	ingester := &mockIngester{}
+	distributors, _ := prepare(t, 1, 5, limits, func(addr string) (ring_client.PoolClient, error) { return ingester, nil })
+
+	request := makeWriteRequestWithLabels(10, 10, []string{`{app="foo", env="prod"}`, `{app="bar", env="dev"}`})
+	_, err := distributors[0].Push(ctx, request)
+	require.NoError(t, err)
+	for _, req := range ingester.pushed {
+		require.Len(t, req.Streams, 1)
+		require.Equal(t, `{env="prod", service="foo"}`, req.Streams[0].Labels)
+	}
+	require.Equal(t, float64(10), testutil.ToFloat64(validation.DiscardedSamples.WithLabelValues(validation.RelabelDropped, "test")))
+	require.Equal(t, float64(100), testutil.ToFloat64(validation.DiscardedBytes.WithLabelValues(validation.RelabelDropped, "test")))
+}
+
+func Test_DropRulesOnPush(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.EnforceMetricName = false
+	limits.DropRules = []validation.DropRule{
+		{Name: "foo-low", Selector: `{app="foo"}`, LineRegex: "^[0-4]"},
+		{Name: "bar", Selector: `{app="bar"}`},
+	}
+	require.NoError(t, limits.Validate())
+	ingester := &mockIngester{}
+	distributors, _ := prepare(t, 1, 5, limits, func(addr string) (ring_client.PoolClient, error) { return ingester, nil })
+
+	request := makeWriteRequestWithLabels(10, 10, []string{`{app="foo"}`, `{app="bar"}`})
+	_, err := distributors[0].Push(ctx, request)
+	require.NoError(t, err)
+	require.NotEmpty(t, ingester.pushed)
+	for _, req := range ingester.pushed {
+		require.Len(t, req.Streams, 1)
+		require.Equal(t, `{app="foo"}`, req.Streams[0].Labels)
+		require.Len(t, req.Streams[0].Entries, 5)
+	}
+	require.Equal(t, float64(5), testutil.ToFloat64(distributors[0].droppedLines.WithLabelValues("test", "foo-low")))
+	require.Equal(t, float64(10), testutil.ToFloat64(distributors[0].droppedLines.WithLabelValues("test", "bar")))
+	require.Equal(t, float64(100), testutil.ToFloat64(distributors[0].droppedBytes.WithLabelValues("test", "bar")))
+}
+
+func Test_SamplingOnPush(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.EnforceMetricName = false
+	limits.SamplingRules = []validation.SamplingRule{
+		{Selector: `{app="debug"}`, Rate: 0.5},
+		{Selector: `{app="noise"}`, Rate: 0},
+	}
+	require.NoError(t, limits.Validate())
+	ingester := &mockIngester{}
+	distributors, _ := prepare(t, 1, 5, limits, func(addr string) (ring_client.PoolClient, error) { return ingester, nil })
+
+	request := makeWriteRequestWithLabels(1000, 10, []string{`{app="debug"}`, `{app="noise"}`, `{app="foo"}`})
+	_, err := distributors[0].Push(ctx, request)
+	require.NoError(t, err)
+	require.NotEmpty(t, ingester.pushed)
+
+	kept := map[string]int{}
+	for _, req := range ingester.pushed {
+		for _, stream := range req.Streams {
+			kept[stream.Labels] = len(stream.Entries)
+		}
+	}
+	require.NotContains(t, kept, `{app="noise"}`)
+	require.Equal(t, 1000, kept[`{app="foo"}`])
+	require.InDelta(t, 500, kept[`{app="debug"}`], 100)
+}
+
+func Test_KeepSampleIsConsistent(t *testing.T) {
+	require.True(t, keepSample("line", 1))
+	require.False(t, keepSample("line", 0))
+	for i := 0; i < 100; i++ {
+		line := strconv.Itoa(i)
+		require.Equal(t, keepSample(line, 0.3), keepSample(line, 0.3))
+		// A line kept at some rate is kept at any higher rate.
+		if keepSample(line, 0.3) {
+			require.True(t, keepSample(line, 0.6))
+		}
+	}
+}
+
+func Test_PerStreamRateLimitOnPush(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.EnforceMetricName = false
+	limits.DistributorPerStreamRateLimit = 100
+	limits.DistributorPerStreamRateLimitBurst = 150
+	ingester := &mockIngester{}
+	distributors, _ := prepare(t, 1, 5, limits, func(addr string) (ring_client.PoolClient, error) { return ingester, nil })
+
+	// Use up the burst of one of the streams.
+	lbs, err := syntax.ParseLabels(`{app="foo"}`)
+	require.NoError(t, err)
+	require.True(t, distributors[0].streamRateLimiter.AllowN(time.Now(), "test", lbs.Hash(), 150))
+
+	// Only the stream which already used its burst is rejected.
+	_, err = distributors[0].Push(ctx, makeWriteRequestWithLabels(10, 10, []string{`{app="foo"}`, `{app="bar"}`}))
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusTooManyRequests), resp.Code)
+	require.NotEmpty(t, ingester.pushed)
+	for _, req := range ingester.pushed {
+		require.Len(t, req.Streams, 1)
+		require.Equal(t, `{app="bar"}`, req.Streams[0].Labels)
+	}
+}
+
+func Test_PerStreamRateLimitBurstOnPush(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.EnforceMetricName = false
+	limits.DistributorPerStreamRateLimit = 10
+	limits.DistributorPerStreamRateLimitBurst = 60
+	ingester := &mockIngester{}
+	distributors, _ := prepare(t, 1, 5, limits, func(addr string) (ring_client.PoolClient, error) { return ingester, nil })
+
+	// A push larger than the burst is rejected as invalid.
+	_, err := distributors[0].Push(ctx, makeWriteRequestWithLabels(10, 10, []string{`{app="foo"}`}))
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusBadRequest), resp.Code)
+	require.Contains(t, string(resp.Body), "exceeds the per stream rate limit burst")
+	require.Empty(t, ingester.pushed)
+}
+
+func Test_PerStreamRateLimitAppliesToShards(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.EnforceMetricName = false
+	limits.DistributorPerStreamRateLimit = 10
+	limits.DistributorPerStreamRateLimitBurst = 60
+	limits.ShardStreams.Enabled = true
+	limits.ShardStreams.DesiredRate = 50
+	ingester := &mockIngester{}
+	distributors, _ := prepare(t, 1, 5, limits, func(addr string) (ring_client.PoolClient, error) { return ingester, nil })
+	distributors[0].rateStore = &fakeRateStore{pushRate: 1}
+
+	// The stream is larger than the burst, but each of its shards isn't.
+	_, err := distributors[0].Push(ctx, makeWriteRequestWithLabels(10, 10, []string{`{app="foo"}`}))
+	require.NoError(t, err)
+
+	shards := map[string]struct{}{}
+	for _, req := range ingester.pushed {
+		for _, stream := range req.Streams {
+			shards[stream.Labels] = struct{}{}
+		}
+	}
+	require.Len(t, shards, 2)
+}
+
+func Test_PerStreamRateLimitNotConsumedByRejectedPush(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.EnforceMetricName = false
+	limits.DistributorPerStreamRateLimit = 10
+	limits.DistributorPerStreamRateLimitBurst = 150
+	limits.IngestionRateMB = 50.0 / (1 << 20)
+	limits.IngestionBurstSizeMB = 50.0 / (1 << 20)
+	distributors, _ := prepare(t, 1, 5, limits, nil)
+
+	// The push is rejected by the tenant rate limit.
+	_, err := distributors[0].Push(ctx, makeWriteRequestWithLabels(10, 10, []string{`{app="foo"}`}))
+	require.Error(t, err)
+
+	lbs, err := syntax.ParseLabels(`{app="foo"}`)
+	require.NoError(t, err)
+	res, _, _ := distributors[0].streamRateLimiter.Check(time.Now(), "test", lbs.Hash(), 150)
+	require.Equal(t, streamRateLimitAllowed, res)
+}
+
+func Test_RateLimitedPushHeaders(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.EnforceMetricName = false
+	limits.IngestionRateMB = 100.0 / (1 << 20)
+	limits.IngestionBurstSizeMB = 100.0 / (1 << 20)
+	distributors, _ := prepare(t, 1, 5, limits, nil)
+
+	_, err := distributors[0].Push(ctx, makeWriteRequest(30, 10))
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusTooManyRequests), resp.Code)
+
+	headers := map[string]string{}
+	for _, h := range resp.Headers {
+		headers[h.Key] = h.Values[0]
+	}
+	require.Equal(t, map[string]string{
+		retryAfterHeader:        "3",
+		rateLimitReasonHeader:   validation.RateLimited,
+		rateLimitTenantHeader:   "test",
+		rateLimitLimitHeader:    "100",
+		rateLimitObservedHeader: "300",
+	}, headers)
+
+	// No Retry-After is returned if the rejection must not be retried.
+	distributors[0].cfg.RateLimitedStatusCode = http.StatusBadRequest
+	_, err = distributors[0].Push(ctx, makeWriteRequest(30, 10))
+	resp, ok = httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusBadRequest), resp.Code)
+	for _, h := range resp.Headers {
+		require.NotEqual(t, retryAfterHeader, h.Key)
+	}
+}
+
+func TestRateLimitedPush_RetryAfter(t *testing.T) {
+	require.Equal(t, 1, rateLimitedPush{limit: 100, bytes: 10}.retryAfter())
+	require.Equal(t, 3, rateLimitedPush{limit: 100, bytes: 250}.retryAfter())
+	require.Equal(t, maxRetryAfterSeconds, rateLimitedPush{limit: 1, bytes: 1 << 20}.retryAfter())
+	require.Equal(t, 1, rateLimitedPush{bytes: 10}.retryAfter())
+}
+
+func TestConfig_ValidateRateLimitedStatusCodes(t *testing.T) {
+	var cfg Config
+	flagext.DefaultValues(&cfg)
+	require.NoError(t, cfg.Validate())
+
+	cfg.PerStreamRateLimitedStatusCode = http.StatusBadRequest
+	require.NoError(t, cfg.Validate())
+
+	cfg.RateLimitedStatusCode = http.StatusInternalServerError
+	require.Error(t, cfg.Validate())
+}
+
 func Test_TruncateLogLines(t *testing.T) {
 	setup := func() (*validation.Limits, *mockIngester) {
 		limits := &validation.Limits{}
@@ -463,9 +700,36 @@ func Test_TruncateLogLines(t *testing.T) {
 		_, err := distributors[0].Push(ctx, makeWriteRequest(1, 10))
 		require.NoError(t, err)
 		require.Len(t, ingester.pushed[0].Streams[0].Entries[0].Line, 5)
+		require.Equal(t, "00...", ingester.pushed[0].Streams[0].Entries[0].Line)
+	})
+
+	t.Run("it appends the configured truncation marker", func(t *testing.T) {
+		limits, ingester := setup()
+		limits.MaxLineSizeTruncateMarker = "~"
+		distributors, _ := prepare(t, 1, 5, limits, func(addr string) (ring_client.PoolClient, error) { return ingester, nil })
+
+		_, err := distributors[0].Push(ctx, makeWriteRequest(1, 10))
+		require.NoError(t, err)
+		require.Equal(t, "0000~", ingester.pushed[0].Streams[0].Entries[0].Line)
 	})
 }
 
+func Test_TruncateLine(t *testing.T) {
+	for _, tc := range []struct {
+		line, marker, expected string
+		maxSize                int
+	}{
+		{line: "0123456789", marker: "...", maxSize: 5, expected: "01..."},
+		{line: "0123456789", marker: "", maxSize: 5, expected: "01234"},
+		// The marker is omitted if it does not fit.
+		{line: "0123456789", marker: "...", maxSize: 3, expected: "012"},
+		// Multi-byte characters are not split.
+		{line: "aäöü", marker: "", maxSize: 4, expected: "aä"},
+	} {
+		require.Equal(t, tc.expected, truncateLine(tc.line, tc.maxSize, tc.marker))
+	}
+}
+
 func TestStreamShard(t *testing.T) {
 	// setup base stream.
 	baseStream := logproto.Stream{}
@@ -1019,7 +1283,7 @@ func TestDistributor_PushIngestionRateLimiter(t *testing.T) {
 					assert.Nil(t, err)
 				} else {
 					assert.Nil(t, response)
-					assert.Equal(t, push.expectedError, err)
+					assert.Equal(t, push.expectedError, withoutHeaders(err))
 				}
 			}
 		})
@@ -1122,6 +1386,15 @@ func prepare(t *testing.T, numDistributors, numIngesters int, limits *validation
 	return distributors, ingesters
 }
 
+// withoutHeaders strips the response headers of an httpgrpc error, so only its code and body are compared.
+func withoutHeaders(err error) error {
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	if !ok {
+		return err
+	}
+	return httpgrpc.ErrorFromHTTPResponse(&httpgrpc.HTTPResponse{Code: resp.Code, Body: resp.Body})
+}
+
 func makeWriteRequestWithLabels(lines, size int, labels []string) *logproto.PushRequest {
 	streams := make([]logproto.Stream, len(labels))
 	for i := 0; i < len(labels); i++ {
@@ -0,0 +1,103 @@
+package distributor
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/gorilla/mux"
+	"github.com/grafana/dskit/tenant"
+	"github.com/weaveworks/common/httpgrpc"
+
+	"github.com/grafana/loki/pkg/distributor/esbulk"
+	util_log "github.com/grafana/loki/pkg/util/log"
+)
+
+// elasticProductHeader is checked by the Elastic shippers to make sure they are connected to Elasticsearch.
+const elasticProductHeader = "X-Elastic-Product"
+
+// ElasticsearchInfoHandler answers the version requests of Elastic shippers connecting to the bulk endpoint.
+func (d *Distributor) ElasticsearchInfoHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set(elasticProductHeader, "Elasticsearch")
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"name":         "loki",
+		"cluster_name": "loki",
+		"version": map[string]string{
+			"number":       d.cfg.ElasticsearchBulk.Version,
+			"build_flavor": "default",
+		},
+		"tagline": "You Know, for Search",
+	})
+}
+
+// ElasticsearchBulkHandler converts the index and create actions of an Elasticsearch Bulk API request to a push.
+// The response lists the status of every action, as the shippers retry the failed ones.
+func (d *Distributor) ElasticsearchBulkHandler(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	logger := util_log.WithContext(r.Context(), util_log.Logger)
+	tenantID, err := tenant.TenantID(r.Context())
+	if err != nil {
+		level.Error(logger).Log("msg", "error getting tenant id", "err", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var body io.Reader = r.Body
+	switch enc := r.Header.Get("Content-Encoding"); enc {
+	case "":
+	case "gzip":
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer gz.Close()
+		body = gz
+	default:
+		http.Error(w, "Content-Encoding "+enc+" not supported", http.StatusUnsupportedMediaType)
+		return
+	}
+	if limit := d.cfg.MaxDecompressedPushSize.Val(); limit > 0 {
+		body = http.MaxBytesReader(w, io.NopCloser(body), int64(limit))
+	}
+
+	req, items, pushed, err := esbulk.ParseRequest(d.cfg.ElasticsearchBulk, body, mux.Vars(r)["index"], start)
+	if err != nil {
+		d.writeFailuresManager.Log(tenantID, err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Streams) > 0 {
+		if _, err := d.Push(r.Context(), req); err != nil {
+			code, msg := http.StatusInternalServerError, err.Error()
+			if resp, ok := httpgrpc.HTTPResponseFromError(err); ok {
+				code, msg = int(resp.Code), string(resp.Body)
+			}
+			if d.tenantConfigs.LogPushRequest(tenantID) {
+				level.Debug(logger).Log("msg", "elasticsearch bulk push failed", "code", code, "err", msg)
+			}
+			// The pushed entries are not distinguished, so all of them are reported as failed.
+			for _, i := range pushed {
+				items[i].Status, items[i].Error = code, msg
+			}
+		}
+	}
+
+	resp := esbulk.Response{Took: time.Since(start).Milliseconds(), Items: items}
+	for _, item := range items {
+		if item.Error != "" {
+			resp.Errors = true
+			break
+		}
+	}
+	w.Header().Set(elasticProductHeader, "Elasticsearch")
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		level.Error(logger).Log("msg", "failed to write elasticsearch bulk response", "err", err)
+	}
+}
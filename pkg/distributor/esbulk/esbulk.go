@@ -0,0 +1,289 @@
+package esbulk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/dskit/flagext"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/push"
+)
+
+// Config configures the Elasticsearch Bulk API compatible push endpoint.
+type Config struct {
+	Enabled                  bool                   `yaml:"enabled" category:"experimental"`
+	Version                  string                 `yaml:"version" category:"experimental"`
+	IndexLabel               string                 `yaml:"index_label" category:"experimental"`
+	LabelFields              flagext.StringSliceCSV `yaml:"label_fields" category:"experimental"`
+	StructuredMetadataFields flagext.StringSliceCSV `yaml:"structured_metadata_fields" category:"experimental"`
+	LineField                string                 `yaml:"line_field" category:"experimental"`
+	TimestampField           string                 `yaml:"timestamp_field" category:"experimental"`
+
+	labelFields              []fieldMapping
+	structuredMetadataFields []fieldMapping
+}
+
+// RegisterFlagsWithPrefix registers flags of the Elasticsearch Bulk API compatible push endpoint.
+func (cfg *Config) RegisterFlagsWithPrefix(prefix string, fs *flag.FlagSet) {
+	fs.BoolVar(&cfg.Enabled, prefix+".enabled", false, "Experimental and subject to change. Enable the Elasticsearch Bulk API compatible push endpoint at /es/_bulk.")
+	fs.StringVar(&cfg.Version, prefix+".version", "8.10.0", "Experimental and subject to change. Elasticsearch version reported to the shippers, which refuse to connect to versions they do not support.")
+	fs.StringVar(&cfg.IndexLabel, prefix+".index-label", "index", "Experimental and subject to change. Label set to the index name of the documents.")
+	fs.Var(&cfg.LabelFields, prefix+".label-fields", "Experimental and subject to change. Comma-separated list of document fields mapped to labels, in the form <field>=<label>, e.g. host.name=host. Nested fields are separated by dots.")
+	fs.Var(&cfg.StructuredMetadataFields, prefix+".structured-metadata-fields", "Experimental and subject to change. Comma-separated list of document fields mapped to structured metadata, in the form <field>=<name>, e.g. trace.id=trace_id. Structured metadata must be allowed for the tenant.")
+	fs.StringVar(&cfg.LineField, prefix+".line-field", "", "Experimental and subject to change. Document field used as log line, e.g. message. The whole document is used if empty or if the field is missing.")
+	fs.StringVar(&cfg.TimestampField, prefix+".timestamp-field", "@timestamp", "Experimental and subject to change. Document field holding the timestamp of the log line, in RFC3339 or epoch milliseconds. The time of the push is used if the field is missing.")
+}
+
+// Validate validates the config and parses the field mappings.
+func (cfg *Config) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if !model.LabelName(cfg.IndexLabel).IsValid() {
+		return fmt.Errorf("invalid Elasticsearch index label %q", cfg.IndexLabel)
+	}
+	var err error
+	if cfg.labelFields, err = parseFieldMappings(cfg.LabelFields); err != nil {
+		return fmt.Errorf("invalid Elasticsearch label fields: %w", err)
+	}
+	if cfg.structuredMetadataFields, err = parseFieldMappings(cfg.StructuredMetadataFields); err != nil {
+		return fmt.Errorf("invalid Elasticsearch structured metadata fields: %w", err)
+	}
+	return nil
+}
+
+type fieldMapping struct {
+	path []string
+	name string
+}
+
+func parseFieldMappings(mappings []string) ([]fieldMapping, error) {
+	result := make([]fieldMapping, 0, len(mappings))
+	for _, m := range mappings {
+		field, name, ok := strings.Cut(m, "=")
+		if !ok || field == "" {
+			return nil, fmt.Errorf("mapping %q must be in the form <field>=<name>", m)
+		}
+		if !model.LabelName(name).IsValid() {
+			return nil, fmt.Errorf("invalid name %q of field %q", name, field)
+		}
+		result = append(result, fieldMapping{path: strings.Split(field, "."), name: name})
+	}
+	return result, nil
+}
+
+// Item is the result of a single action of a bulk request.
+type Item struct {
+	Action string
+	Index  string
+	Status int
+	Error  string
+}
+
+// MarshalJSON encodes the item in the format of the Elasticsearch Bulk API response.
+func (i Item) MarshalJSON() ([]byte, error) {
+	result := map[string]interface{}{
+		"_index": i.Index,
+		"status": i.Status,
+	}
+	if i.Error != "" {
+		result["error"] = map[string]string{"type": "loki_exception", "reason": i.Error}
+	}
+	return json.Marshal(map[string]interface{}{i.Action: result})
+}
+
+// Response is the response of a bulk request.
+type Response struct {
+	Took   int64  `json:"took"`
+	Errors bool   `json:"errors"`
+	Items  []Item `json:"items"`
+}
+
+type action struct {
+	Index string `json:"_index"`
+}
+
+// ParseRequest converts the actions of a bulk request to a push request.
+// The index and create actions are supported, the results of the other actions are failed items.
+// The returned items are in the order of the actions; the indexes of the items of the pushed
+// entries are returned as well to update their status with the result of the push.
+func ParseRequest(cfg Config, body io.Reader, defaultIndex string, now time.Time) (*logproto.PushRequest, []Item, []int, error) {
+	var (
+		r       = bufio.NewReader(body)
+		streams = map[string]int{}
+		req     = &logproto.PushRequest{}
+		items   []Item
+		pushed  []int
+	)
+	for {
+		line, err := readLine(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if len(line) == 0 {
+			continue
+		}
+
+		var actions map[string]action
+		if err := json.Unmarshal(line, &actions); err != nil || len(actions) != 1 {
+			return nil, nil, nil, fmt.Errorf("malformed action line %q", line)
+		}
+		var (
+			name string
+			a    action
+		)
+		for k, v := range actions {
+			name, a = k, v
+		}
+		if a.Index == "" {
+			a.Index = defaultIndex
+		}
+		item := Item{Action: name, Index: a.Index, Status: http.StatusCreated}
+
+		switch name {
+		case "index", "create":
+		case "delete":
+			item.Status, item.Error = http.StatusBadRequest, "delete actions are not supported"
+			items = append(items, item)
+			continue
+		default:
+			// Other actions, e.g. update, are followed by a document.
+			if _, err := readLine(r); err != nil && err != io.EOF {
+				return nil, nil, nil, err
+			}
+			item.Status, item.Error = http.StatusBadRequest, fmt.Sprintf("%s actions are not supported", name)
+			items = append(items, item)
+			continue
+		}
+
+		doc, err := readLine(r)
+		if err != nil && err != io.EOF {
+			return nil, nil, nil, err
+		}
+		if a.Index == "" {
+			item.Status, item.Error = http.StatusBadRequest, "missing index name"
+			items = append(items, item)
+			continue
+		}
+		ls, entry, err := cfg.toEntry(a.Index, doc, now)
+		if err != nil {
+			item.Status, item.Error = http.StatusBadRequest, err.Error()
+			items = append(items, item)
+			continue
+		}
+
+		i, ok := streams[ls]
+		if !ok {
+			i = len(req.Streams)
+			streams[ls] = i
+			req.Streams = append(req.Streams, logproto.Stream{Labels: ls})
+		}
+		req.Streams[i].Entries = append(req.Streams[i].Entries, entry)
+		pushed = append(pushed, len(items))
+		items = append(items, item)
+	}
+	return req, items, pushed, nil
+}
+
+func readLine(r *bufio.Reader) ([]byte, error) {
+	line, err := r.ReadBytes('\n')
+	if err == io.EOF && len(line) > 0 {
+		err = nil
+	}
+	return bytes.TrimSpace(line), err
+}
+
+func (cfg Config) toEntry(index string, doc []byte, now time.Time) (string, logproto.Entry, error) {
+	d := json.NewDecoder(bytes.NewReader(doc))
+	d.UseNumber()
+	var fields map[string]interface{}
+	if err := d.Decode(&fields); err != nil {
+		return "", logproto.Entry{}, errors.New("malformed document")
+	}
+
+	lb := labels.NewBuilder(labels.FromStrings(cfg.IndexLabel, index))
+	for _, m := range cfg.labelFields {
+		if v, ok := lookup(fields, m.path); ok {
+			lb.Set(m.name, v)
+		}
+	}
+
+	entry := logproto.Entry{Timestamp: now, Line: string(doc)}
+	if cfg.LineField != "" {
+		if v, ok := lookup(fields, strings.Split(cfg.LineField, ".")); ok {
+			entry.Line = v
+		}
+	}
+	if cfg.TimestampField != "" {
+		if v, ok := lookup(fields, strings.Split(cfg.TimestampField, ".")); ok {
+			ts, err := parseTimestamp(v)
+			if err != nil {
+				return "", logproto.Entry{}, err
+			}
+			entry.Timestamp = ts
+		}
+	}
+	for _, m := range cfg.structuredMetadataFields {
+		if v, ok := lookup(fields, m.path); ok {
+			entry.StructuredMetadata = append(entry.StructuredMetadata, push.LabelAdapter{Name: m.name, Value: v})
+		}
+	}
+	return lb.Labels().String(), entry, nil
+}
+
+// lookup returns the value of the field at the path as string. Documents can
+// contain both nested objects and dotted field names, so both are looked up.
+func lookup(fields map[string]interface{}, path []string) (string, bool) {
+	for i := len(path); i > 0; i-- {
+		v, ok := fields[strings.Join(path[:i], ".")]
+		if !ok {
+			continue
+		}
+		if i == len(path) {
+			return stringify(v)
+		}
+		if nested, ok := v.(map[string]interface{}); ok {
+			return lookup(nested, path[i:])
+		}
+	}
+	return "", false
+}
+
+func stringify(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, v != ""
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	default:
+		return "", false
+	}
+}
+
+func parseTimestamp(v string) (time.Time, error) {
+	if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+	ts, err := time.Parse(time.RFC3339Nano, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q", v)
+	}
+	return ts, nil
+}
@@ -0,0 +1,94 @@
+package esbulk
+
+import (
+	"flag"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/push"
+)
+
+func newConfig(t *testing.T, labelFields, structuredMetadataFields []string) Config {
+	var cfg Config
+	cfg.RegisterFlagsWithPrefix("es", flag.NewFlagSet("", flag.PanicOnError))
+	cfg.Enabled = true
+	cfg.LabelFields = labelFields
+	cfg.StructuredMetadataFields = structuredMetadataFields
+	require.NoError(t, cfg.Validate())
+	return cfg
+}
+
+func TestParseRequest(t *testing.T) {
+	cfg := newConfig(t, []string{"host.name=host", "log.level=level"}, []string{"trace.id=trace_id"})
+	now := time.Now()
+
+	body := strings.Join([]string{
+		`{"index":{"_index":"app"}}`,
+		`{"@timestamp":"2023-10-11T22:14:15.003Z","message":"a","host":{"name":"h1"},"log.level":"info","trace":{"id":"abc"}}`,
+		`{"create":{"_index":"app"}}`,
+		`{"message":"b","host":{"name":"h2"}}`,
+		`{"update":{"_index":"app","_id":"1"}}`,
+		`{"doc":{"message":"c"}}`,
+		`{"index":{}}`,
+		`{"@timestamp":1697062455003,"message":"d","host":{"name":"h1"},"log.level":"info"}`,
+		`{"index":{"_index":"app"}}`,
+		`not json`,
+	}, "\n")
+
+	req, items, pushed, err := ParseRequest(cfg, strings.NewReader(body), "default", now)
+	require.NoError(t, err)
+
+	require.Len(t, items, 5)
+	require.Equal(t, []int{0, 1, 3}, pushed)
+	for i, status := range []int{http.StatusCreated, http.StatusCreated, http.StatusBadRequest, http.StatusCreated, http.StatusBadRequest} {
+		require.Equal(t, status, items[i].Status, "item %d", i)
+	}
+	require.Equal(t, "update", items[2].Action)
+	require.Equal(t, "default", items[3].Index)
+
+	require.Len(t, req.Streams, 3)
+	require.Equal(t, `{host="h1", index="app", level="info"}`, req.Streams[0].Labels)
+	require.Equal(t, push.LabelsAdapter{{Name: "trace_id", Value: "abc"}}, req.Streams[0].Entries[0].StructuredMetadata)
+	require.Equal(t, time.Date(2023, 10, 11, 22, 14, 15, 3e6, time.UTC), req.Streams[0].Entries[0].Timestamp.UTC())
+
+	require.Equal(t, `{host="h2", index="app"}`, req.Streams[1].Labels)
+	require.Equal(t, now, req.Streams[1].Entries[0].Timestamp)
+	require.Equal(t, `{"message":"b","host":{"name":"h2"}}`, req.Streams[1].Entries[0].Line)
+
+	require.Equal(t, `{host="h1", index="default", level="info"}`, req.Streams[2].Labels)
+	require.Equal(t, time.UnixMilli(1697062455003), req.Streams[2].Entries[0].Timestamp)
+}
+
+func TestParseRequest_LineField(t *testing.T) {
+	cfg := newConfig(t, nil, nil)
+	cfg.LineField = "message"
+
+	body := `{"index":{"_index":"app"}}
+{"message":"hello"}
+{"index":{"_index":"app"}}
+{"msg":"world"}
+`
+	req, _, _, err := ParseRequest(cfg, strings.NewReader(body), "", time.Now())
+	require.NoError(t, err)
+	require.Len(t, req.Streams, 1)
+	require.Equal(t, "hello", req.Streams[0].Entries[0].Line)
+	require.Equal(t, `{"msg":"world"}`, req.Streams[0].Entries[1].Line)
+}
+
+func TestParseRequest_MalformedAction(t *testing.T) {
+	_, _, _, err := ParseRequest(newConfig(t, nil, nil), strings.NewReader("{\"index\":\n"), "", time.Now())
+	require.Error(t, err)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	for _, fields := range [][]string{{"host.name"}, {"=host"}, {"host.name=host.name"}} {
+		cfg := Config{Enabled: true, IndexLabel: "index", LabelFields: fields}
+		require.Error(t, cfg.Validate(), fields)
+	}
+	cfg := Config{Enabled: true, IndexLabel: "index-name"}
+	require.Error(t, cfg.Validate())
+}
@@ -0,0 +1,201 @@
+package distributor
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/kv"
+	"github.com/grafana/dskit/services"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// HATrackerConfig configures the tracker that deduplicates pushes of
+// redundant pairs of agents.
+type HATrackerConfig struct {
+	Enabled         bool          `yaml:"enable_ha_tracker"`
+	UpdateTimeout   time.Duration `yaml:"ha_tracker_update_timeout"`
+	FailoverTimeout time.Duration `yaml:"ha_tracker_failover_timeout"`
+	KVStore         kv.Config     `yaml:"kvstore" doc:"description=Backend storage to use for the HA tracker. Only consul, etcd and inmemory are supported."`
+}
+
+// RegisterFlags adds the flags required to config this to the given FlagSet.
+func (cfg *HATrackerConfig) RegisterFlags(f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, "distributor.ha-tracker.enable", false, "Enable the HA tracker, which elects one replica per tenant and cluster and drops the pushes of the other replicas. The deduplication must also be enabled per tenant with accept_ha_samples.")
+	f.DurationVar(&cfg.UpdateTimeout, "distributor.ha-tracker.update-timeout", 15*time.Second, "Update the timestamp of the elected replica in the KV store at most this often.")
+	f.DurationVar(&cfg.FailoverTimeout, "distributor.ha-tracker.failover-timeout", 30*time.Second, "Elect another replica if no push has been received from the elected replica for this long. It must be greater than the update timeout.")
+	cfg.KVStore.RegisterFlagsWithPrefix("distributor.ha-tracker.", "ha-tracker/", f)
+}
+
+// Validate validates the HA tracker config.
+func (cfg *HATrackerConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.FailoverTimeout <= cfg.UpdateTimeout {
+		return fmt.Errorf("HA tracker failover timeout (%s) must be greater than the update timeout (%s)", cfg.FailoverTimeout, cfg.UpdateTimeout)
+	}
+	if cfg.KVStore.Store == "memberlist" {
+		return errors.New("memberlist is not supported as KV store of the HA tracker")
+	}
+	return nil
+}
+
+// replicaDesc is the elected replica of a cluster stored in the KV store.
+type replicaDesc struct {
+	Replica    string `json:"replica"`
+	ReceivedAt int64  `json:"received_at"` // unix milliseconds
+}
+
+var replicaDescCodec = haTrackerCodec{}
+
+type haTrackerCodec struct{}
+
+func (haTrackerCodec) Decode(data []byte) (interface{}, error) {
+	var desc replicaDesc
+	if err := jsoniter.ConfigFastest.Unmarshal(data, &desc); err != nil {
+		return nil, err
+	}
+	return &desc, nil
+}
+
+func (haTrackerCodec) Encode(obj interface{}) ([]byte, error) {
+	return jsoniter.ConfigFastest.Marshal(obj)
+}
+
+func (haTrackerCodec) CodecID() string { return "distributor.haTrackerCodec" }
+
+// replicasNotMatchError is returned for pushes of a replica which is not the elected one.
+type replicasNotMatchError struct {
+	replica, elected string
+}
+
+func (e replicasNotMatchError) Error() string {
+	return fmt.Sprintf("replicas did not match, rejecting push: replica=%s, elected=%s", e.replica, e.elected)
+}
+
+// haTracker elects one replica per tenant and cluster. The elected replica is
+// kept as long as it keeps pushing; another replica is only elected once the
+// elected one did not push for longer than the failover timeout.
+type haTracker struct {
+	services.Service
+
+	cfg    HATrackerConfig
+	client kv.Client
+	logger log.Logger
+
+	electedLock sync.RWMutex
+	elected     map[string]replicaDesc // tenant/cluster -> elected replica
+
+	electedReplicaChanges *prometheus.CounterVec
+	dedupedLines          *prometheus.CounterVec
+	kvCASCalls            *prometheus.CounterVec
+}
+
+func newHATracker(cfg HATrackerConfig, logger log.Logger, registerer prometheus.Registerer) (*haTracker, error) {
+	client, err := kv.NewClient(cfg.KVStore, replicaDescCodec, kv.RegistererWithKVName(registerer, "distributor-hatracker"), logger)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &haTracker{
+		cfg:     cfg,
+		client:  client,
+		logger:  logger,
+		elected: map[string]replicaDesc{},
+		electedReplicaChanges: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki",
+			Name:      "distributor_ha_tracker_elected_replica_changes_total",
+			Help:      "The total number of times the elected replica has changed for a tenant and cluster.",
+		}, []string{"tenant", "cluster"}),
+		dedupedLines: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki",
+			Name:      "distributor_ha_tracker_deduped_lines_total",
+			Help:      "The total number of log lines dropped because they were pushed by a non-elected replica.",
+		}, []string{"tenant", "cluster"}),
+		kvCASCalls: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki",
+			Name:      "distributor_ha_tracker_kv_store_cas_total",
+			Help:      "The total number of compare-and-swap calls to the KV store made by the HA tracker.",
+		}, []string{"tenant", "cluster"}),
+	}
+	t.Service = services.NewBasicService(nil, t.running, nil)
+	return t, nil
+}
+
+func (t *haTracker) running(ctx context.Context) error {
+	// Keep the local cache in sync with the elections of other distributors.
+	t.client.WatchPrefix(ctx, "", func(key string, value interface{}) bool {
+		desc, ok := value.(*replicaDesc)
+		t.electedLock.Lock()
+		defer t.electedLock.Unlock()
+		if !ok || desc == nil {
+			delete(t.elected, key)
+			return true
+		}
+		t.elected[key] = *desc
+		return true
+	})
+	return nil
+}
+
+func haKey(tenant, cluster string) string {
+	return tenant + "/" + cluster
+}
+
+// checkReplica returns nil if the push of the replica should be accepted and
+// a replicasNotMatchError if it must be dropped.
+func (t *haTracker) checkReplica(ctx context.Context, tenant, cluster, replica string, now time.Time) error {
+	key := haKey(tenant, cluster)
+
+	t.electedLock.RLock()
+	entry, ok := t.elected[key]
+	t.electedLock.RUnlock()
+
+	// Fast path: the replica is elected and its timestamp does not need to be updated yet.
+	if ok && entry.Replica == replica && now.Sub(time.UnixMilli(entry.ReceivedAt)) < t.cfg.UpdateTimeout {
+		return nil
+	}
+
+	t.kvCASCalls.WithLabelValues(tenant, cluster).Inc()
+	var rejectErr error
+	err := t.client.CAS(ctx, key, func(in interface{}) (interface{}, bool, error) {
+		rejectErr = nil
+		desc, _ := in.(*replicaDesc)
+		if desc != nil {
+			receivedAt := time.UnixMilli(desc.ReceivedAt)
+			if desc.Replica == replica {
+				if now.Sub(receivedAt) < t.cfg.UpdateTimeout {
+					// Another distributor already updated the timestamp.
+					return nil, false, nil
+				}
+			} else if now.Sub(receivedAt) < t.cfg.FailoverTimeout {
+				rejectErr = replicasNotMatchError{replica: replica, elected: desc.Replica}
+				return nil, false, nil
+			}
+		}
+		return &replicaDesc{Replica: replica, ReceivedAt: now.UnixMilli()}, true, nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "HA tracker")
+	}
+	if rejectErr != nil {
+		return rejectErr
+	}
+
+	t.electedLock.Lock()
+	defer t.electedLock.Unlock()
+	if prev, ok := t.elected[key]; !ok || prev.Replica != replica {
+		t.electedReplicaChanges.WithLabelValues(tenant, cluster).Inc()
+		level.Info(t.logger).Log("msg", "elected new replica", "tenant", tenant, "cluster", cluster, "replica", replica)
+	}
+	t.elected[key] = replicaDesc{Replica: replica, ReceivedAt: now.UnixMilli()}
+	return nil
+}
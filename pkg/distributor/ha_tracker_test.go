@@ -0,0 +1,92 @@
+package distributor
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/dskit/kv"
+	"github.com/grafana/dskit/kv/consul"
+	ring_client "github.com/grafana/dskit/ring/client"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/httpgrpc"
+
+	"github.com/grafana/loki/pkg/validation"
+)
+
+func newTestHATracker(t *testing.T) *haTracker {
+	kvStore, closer := consul.NewInMemoryClient(replicaDescCodec, log.NewNopLogger(), nil)
+	t.Cleanup(func() { closer.Close() })
+
+	tracker, err := newHATracker(HATrackerConfig{
+		Enabled:         true,
+		UpdateTimeout:   time.Second,
+		FailoverTimeout: 5 * time.Second,
+		KVStore:         kv.Config{Mock: kvStore},
+	}, log.NewNopLogger(), prometheus.NewRegistry())
+	require.NoError(t, err)
+	return tracker
+}
+
+func TestHATracker_CheckReplica(t *testing.T) {
+	tracker := newTestHATracker(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	// The first replica is elected.
+	require.NoError(t, tracker.checkReplica(ctx, "tenant", "cluster", "a", now))
+	require.NoError(t, tracker.checkReplica(ctx, "tenant", "cluster", "a", now.Add(2*time.Second)))
+
+	// Other replicas are rejected as long as the elected replica keeps pushing.
+	err := tracker.checkReplica(ctx, "tenant", "cluster", "b", now.Add(3*time.Second))
+	require.ErrorAs(t, err, &replicasNotMatchError{})
+
+	// Clusters and tenants are tracked independently.
+	require.NoError(t, tracker.checkReplica(ctx, "tenant", "other", "b", now))
+	require.NoError(t, tracker.checkReplica(ctx, "other", "cluster", "b", now))
+
+	// Another replica is elected once the failover timeout passed.
+	require.NoError(t, tracker.checkReplica(ctx, "tenant", "cluster", "b", now.Add(8*time.Second)))
+	err = tracker.checkReplica(ctx, "tenant", "cluster", "a", now.Add(9*time.Second))
+	require.ErrorAs(t, err, &replicasNotMatchError{})
+}
+
+func TestHATrackerConfig_Validate(t *testing.T) {
+	cfg := HATrackerConfig{Enabled: true, UpdateTimeout: 10 * time.Second, FailoverTimeout: 5 * time.Second}
+	require.Error(t, cfg.Validate())
+
+	cfg.FailoverTimeout = 30 * time.Second
+	require.NoError(t, cfg.Validate())
+
+	cfg.KVStore.Store = "memberlist"
+	require.Error(t, cfg.Validate())
+}
+
+func TestDistributor_PushHADeduplication(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.EnforceMetricName = false
+	limits.AcceptHASamples = true
+
+	ingester := &mockIngester{}
+	distributors, _ := prepare(t, 1, 5, limits, func(addr string) (ring_client.PoolClient, error) { return ingester, nil })
+	distributors[0].haTracker = newTestHATracker(t)
+
+	_, err := distributors[0].Push(ctx, makeWriteRequestWithLabels(1, 10, []string{`{cluster="prod", __replica__="a", app="foo"}`}))
+	require.NoError(t, err)
+	require.NotEmpty(t, ingester.pushed)
+	for _, req := range ingester.pushed {
+		require.Equal(t, `{app="foo", cluster="prod"}`, req.Streams[0].Labels)
+	}
+
+	pushed := len(ingester.pushed)
+	_, err = distributors[0].Push(ctx, makeWriteRequestWithLabels(1, 10, []string{`{cluster="prod", __replica__="b", app="foo"}`}))
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusAccepted), resp.Code)
+	require.Len(t, ingester.pushed, pushed)
+}
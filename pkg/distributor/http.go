@@ -1,6 +1,7 @@
 package distributor
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
@@ -25,18 +26,23 @@ func (d *Distributor) PushHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	req, err := push.ParseRequest(logger, tenantID, r, d.tenantsRetention)
+	push.AdvertiseContentEncodings(w)
+	req, err := push.ParseRequest(logger, tenantID, r, d.tenantsRetention, d.cfg.MaxDecompressedPushSize.Val())
 	if err != nil {
+		code := http.StatusBadRequest
+		if errors.As(err, new(push.UnsupportedContentEncodingError)) {
+			code = http.StatusUnsupportedMediaType
+		}
 		if d.tenantConfigs.LogPushRequest(tenantID) {
 			level.Debug(logger).Log(
 				"msg", "push request failed",
-				"code", http.StatusBadRequest,
+				"code", code,
 				"err", err,
 			)
 		}
 		d.writeFailuresManager.Log(tenantID, err)
 
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), code)
 		return
 	}
 
@@ -72,6 +78,11 @@ func (d *Distributor) PushHandler(w http.ResponseWriter, r *http.Request) {
 				"err", body,
 			)
 		}
+		for _, h := range resp.Headers {
+			for _, v := range h.Values {
+				w.Header().Add(h.Key, v)
+			}
+		}
 		http.Error(w, body, int(resp.Code))
 	} else {
 		if d.tenantConfigs.LogPushRequest(tenantID) {
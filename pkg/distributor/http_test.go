@@ -1,13 +1,20 @@
 package distributor
 
 import (
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/grafana/dskit/flagext"
+	ring_client "github.com/grafana/dskit/ring/client"
 	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
 
 	"github.com/grafana/loki/pkg/validation"
 )
@@ -54,3 +61,73 @@ func TestDistributorRingHandler(t *testing.T) {
 		require.NotContains(t, string(body), "<th>Instance ID</th>")
 	})
 }
+
+func TestPushHandler_RateLimitedHeaders(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.IngestionRateMB = 100.0 / (1 << 20)
+	limits.IngestionBurstSizeMB = 100.0 / (1 << 20)
+	distributors, _ := prepare(t, 1, 3, limits, nil)
+
+	body := `{"streams": [{"stream": {"foo": "bar"}, "values": [["` + strconv.FormatInt(time.Now().UnixNano(), 10) + `", "` + strings.Repeat("a", 200) + `"]]}]}`
+	req := httptest.NewRequest(http.MethodPost, "/loki/api/v1/push", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(user.InjectOrgID(req.Context(), "test"))
+
+	rec := httptest.NewRecorder()
+	distributors[0].PushHandler(rec, req)
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "2", rec.Header().Get("Retry-After"))
+	require.Equal(t, validation.RateLimited, rec.Header().Get("X-Loki-RateLimit-Reason"))
+	require.Equal(t, "100", rec.Header().Get("X-Loki-RateLimit-Limit"))
+}
+
+func TestElasticsearchBulkHandler(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	ingester := &mockIngester{}
+	distributors, _ := prepare(t, 1, 3, limits, func(addr string) (ring_client.PoolClient, error) { return ingester, nil })
+
+	d := distributors[0]
+	d.cfg.ElasticsearchBulk.Enabled = true
+	d.cfg.ElasticsearchBulk.LabelFields = []string{"host.name=host"}
+	d.cfg.ElasticsearchBulk.LineField = "message"
+	require.NoError(t, d.cfg.ElasticsearchBulk.Validate())
+
+	ts := time.Now().UTC().Format(time.RFC3339Nano)
+	body := `{"index":{"_index":"filebeat"}}
+{"@timestamp":"` + ts + `","message":"hello","host":{"name":"host1"}}
+{"delete":{"_index":"filebeat","_id":"1"}}
+{"create":{}}
+{"@timestamp":"` + ts + `","message":"world","host":{"name":"host1"}}
+`
+	req := httptest.NewRequest(http.MethodPost, "/es/filebeat/_bulk", strings.NewReader(body))
+	req = mux.SetURLVars(req, map[string]string{"index": "filebeat"})
+	req = req.WithContext(user.InjectOrgID(req.Context(), "test"))
+
+	rec := httptest.NewRecorder()
+	d.ElasticsearchBulkHandler(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "Elasticsearch", rec.Header().Get("X-Elastic-Product"))
+
+	var resp struct {
+		Errors bool                                `json:"errors"`
+		Items  []map[string]map[string]interface{} `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.True(t, resp.Errors)
+	require.Len(t, resp.Items, 3)
+	require.Equal(t, float64(http.StatusCreated), resp.Items[0]["index"]["status"])
+	require.Equal(t, float64(http.StatusBadRequest), resp.Items[1]["delete"]["status"])
+	require.Equal(t, float64(http.StatusCreated), resp.Items[2]["create"]["status"])
+
+	ingester.mu.Lock()
+	defer ingester.mu.Unlock()
+	require.NotEmpty(t, ingester.pushed)
+	for _, req := range ingester.pushed {
+		require.Len(t, req.Streams, 1)
+		require.Equal(t, `{host="host1", index="filebeat"}`, req.Streams[0].Labels)
+		require.Len(t, req.Streams[0].Entries, 2)
+		require.Equal(t, "hello", req.Streams[0].Entries[0].Line)
+	}
+}
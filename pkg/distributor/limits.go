@@ -3,8 +3,11 @@ package distributor
 import (
 	"time"
 
+	"github.com/prometheus/prometheus/model/relabel"
+
 	"github.com/grafana/loki/pkg/distributor/shardstreams"
 	"github.com/grafana/loki/pkg/storage/stores/indexshipper/compactor/retention"
+	"github.com/grafana/loki/pkg/validation"
 )
 
 // Limits is an interface for distributor limits/related configs
@@ -12,6 +15,8 @@ type Limits interface {
 	retention.Limits
 	MaxLineSize(userID string) int
 	MaxLineSizeTruncate(userID string) bool
+	MaxLineSizeTruncateMarker(userID string) string
+	DistributorPerStreamRateLimit(userID string) validation.RateLimit
 	EnforceMetricName(userID string) bool
 	MaxLabelNamesPerSeries(userID string) int
 	MaxLabelNameLength(userID string) int
@@ -23,6 +28,19 @@ type Limits interface {
 
 	IncrementDuplicateTimestamps(userID string) bool
 
+	AllowStructuredMetadata(userID string) bool
+	MaxStructuredMetadataSize(userID string) int
+	MaxStructuredMetadataCount(userID string) int
+
+	IngestionRelabelConfigs(userID string) []*relabel.Config
+	DropRules(userID string) []validation.DropRule
+	SamplingRules(userID string) []validation.SamplingRule
+	IngestionPipelines(userID string) []validation.IngestionPipeline
+
+	AcceptHASamples(userID string) bool
+	HAClusterLabel(userID string) string
+	HAReplicaLabel(userID string) string
+
 	ShardStreams(userID string) *shardstreams.Config
 	IngestionRateStrategy() string
 	IngestionRateBytes(userID string) float64
@@ -0,0 +1,125 @@
+package distributor
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/logql/syntax"
+	util_log "github.com/grafana/loki/pkg/util/log"
+	"github.com/grafana/loki/pkg/validation"
+)
+
+// processPipelines runs the first matching ingestion pipeline of the tenant on every stream.
+// Since the pipelines can add labels extracted from the lines, a stream can be split into several streams.
+// Streams with invalid labels are returned as they are, to be rejected by the validation.
+func processPipelines(tenantID string, pipelines []validation.IngestionPipeline, streams []logproto.Stream) []logproto.Stream {
+	result := make([]logproto.Stream, 0, len(streams))
+	for _, stream := range streams {
+		ls, err := syntax.ParseLabels(stream.Labels)
+		if err != nil {
+			result = append(result, stream)
+			continue
+		}
+
+		var pipeline *validation.IngestionPipeline
+		for i := range pipelines {
+			if pipelines[i].Matches(ls) {
+				pipeline = &pipelines[i]
+				break
+			}
+		}
+		if pipeline == nil {
+			result = append(result, stream)
+			continue
+		}
+
+		processed, err := processStream(pipeline, ls, stream)
+		if err != nil {
+			level.Warn(util_log.Logger).Log("msg", "failed to run ingestion pipeline", "tenant", tenantID, "stream", stream.Labels, "err", err)
+			result = append(result, stream)
+			continue
+		}
+		result = append(result, processed...)
+	}
+	return result
+}
+
+func processStream(pipeline *validation.IngestionPipeline, ls labels.Labels, stream logproto.Stream) ([]logproto.Stream, error) {
+	sp, release, err := pipeline.ForStream(ls)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	var (
+		streams []logproto.Stream
+		byKey   = map[string]int{}
+		builder = labels.NewBuilder(ls)
+	)
+	for _, entry := range stream.Entries {
+		line, lbs, ok := sp.ProcessString(entry.Timestamp.UnixNano(), entry.Line)
+		if !ok {
+			continue
+		}
+		entry.Line = line
+		extracted := lbs.Labels()
+
+		key := stream.Labels
+		if len(pipeline.Labels) > 0 {
+			builder.Reset(ls)
+			for _, name := range pipeline.Labels {
+				if v := extracted.Get(name); v != "" {
+					builder.Set(name, v)
+				}
+			}
+			key = builder.Labels().String()
+		}
+
+		if pipeline.TimestampLabel != "" {
+			if v := extracted.Get(pipeline.TimestampLabel); v != "" {
+				if ts, err := parseTimestamp(pipeline.TimestampFormat, v); err == nil {
+					entry.Timestamp = ts
+				}
+			}
+		}
+
+		i, ok := byKey[key]
+		if !ok {
+			i = len(streams)
+			byKey[key] = i
+			streams = append(streams, logproto.Stream{Labels: key})
+		}
+		streams[i].Entries = append(streams[i].Entries, entry)
+	}
+	return streams, nil
+}
+
+// parseTimestamp parses the timestamp extracted by an ingestion pipeline.
+func parseTimestamp(format, value string) (time.Time, error) {
+	switch format {
+	case "", "RFC3339":
+		return time.Parse(time.RFC3339, value)
+	case "RFC3339Nano":
+		return time.Parse(time.RFC3339Nano, value)
+	case "Unix", "UnixMs", "UnixNs":
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid %s timestamp %q", format, value)
+		}
+		switch format {
+		case "Unix":
+			return time.Unix(i, 0), nil
+		case "UnixMs":
+			return time.UnixMilli(i), nil
+		default:
+			return time.Unix(0, i), nil
+		}
+	default:
+		return time.Parse(format, value)
+	}
+}
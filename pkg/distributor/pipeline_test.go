@@ -0,0 +1,126 @@
+package distributor
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/validation"
+)
+
+func TestProcessPipelines(t *testing.T) {
+	limits := validation.Limits{
+		DeletionMode: "disabled",
+		IngestionPipelines: []validation.IngestionPipeline{
+			{
+				Selector:        `{job="json"}`,
+				Stages:          `| json | line_format "{{.msg}}"`,
+				Labels:          []string{"level"},
+				TimestampLabel:  "ts",
+				TimestampFormat: "UnixMs",
+			},
+			{
+				Selector: `{job="logfmt"}`,
+				Stages:   `| logfmt | line_format "{{.level}}: {{.msg}}"`,
+			},
+		},
+	}
+	require.NoError(t, limits.Validate())
+
+	now := time.Unix(0, 0).UTC()
+	streams := processPipelines("tenant", limits.IngestionPipelines, []logproto.Stream{
+		{
+			Labels: `{job="json"}`,
+			Entries: []logproto.Entry{
+				{Timestamp: now, Line: `{"msg": "a", "level": "info", "ts": 1000}`},
+				{Timestamp: now, Line: `{"msg": "b", "level": "error", "ts": 2000}`},
+				{Timestamp: now, Line: `{"msg": "c", "level": "info"}`},
+			},
+		},
+		{
+			Labels:  `{job="logfmt"}`,
+			Entries: []logproto.Entry{{Timestamp: now, Line: `level=warn msg=d`}},
+		},
+		{
+			Labels:  `{job="other"}`,
+			Entries: []logproto.Entry{{Timestamp: now, Line: `level=warn msg=e`}},
+		},
+	})
+
+	require.Equal(t, []logproto.Stream{
+		{
+			Labels: `{job="json", level="info"}`,
+			Entries: []logproto.Entry{
+				{Timestamp: time.UnixMilli(1000), Line: "a"},
+				{Timestamp: now, Line: "c"},
+			},
+		},
+		{
+			Labels:  `{job="json", level="error"}`,
+			Entries: []logproto.Entry{{Timestamp: time.UnixMilli(2000), Line: "b"}},
+		},
+		{
+			Labels:  `{job="logfmt"}`,
+			Entries: []logproto.Entry{{Timestamp: now, Line: "warn: d"}},
+		},
+		{
+			Labels:  `{job="other"}`,
+			Entries: []logproto.Entry{{Timestamp: now, Line: `level=warn msg=e`}},
+		},
+	}, streams)
+}
+
+func TestProcessPipelinesConcurrently(t *testing.T) {
+	limits := validation.Limits{
+		DeletionMode: "disabled",
+		IngestionPipelines: []validation.IngestionPipeline{
+			{Selector: `{job="json"}`, Stages: `| json | line_format "{{.msg}}"`, Labels: []string{"level"}},
+		},
+	}
+	require.NoError(t, limits.Validate())
+
+	// The pipelines built during the validation are reused by the concurrent pushes.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				msg := fmt.Sprintf("%d-%d", i, j)
+				streams := processPipelines("tenant", limits.IngestionPipelines, []logproto.Stream{{
+					Labels:  `{job="json"}`,
+					Entries: []logproto.Entry{{Line: fmt.Sprintf(`{"msg": %q, "level": "info"}`, msg)}},
+				}})
+				require.Equal(t, []logproto.Stream{{
+					Labels:  `{job="json", level="info"}`,
+					Entries: []logproto.Entry{{Line: msg}},
+				}}, streams)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestParseTimestamp(t *testing.T) {
+	for _, tc := range []struct {
+		format, value string
+		expected      time.Time
+	}{
+		{format: "", value: "2023-01-02T03:04:05Z", expected: time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{format: "RFC3339Nano", value: "2023-01-02T03:04:05.5Z", expected: time.Date(2023, 1, 2, 3, 4, 5, 5e8, time.UTC)},
+		{format: "Unix", value: "10", expected: time.Unix(10, 0)},
+		{format: "UnixNs", value: "10", expected: time.Unix(0, 10)},
+		{format: "2006-01-02", value: "2023-01-02", expected: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)},
+	} {
+		ts, err := parseTimestamp(tc.format, tc.value)
+		require.NoError(t, err)
+		require.True(t, tc.expected.Equal(ts), "format %q", tc.format)
+	}
+
+	_, err := parseTimestamp("Unix", "abc")
+	require.Error(t, err)
+}
@@ -0,0 +1,64 @@
+package distributor
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/weaveworks/common/httpgrpc"
+)
+
+// Headers of responses to pushes rejected by a rate limit.
+const (
+	retryAfterHeader         = "Retry-After"
+	rateLimitReasonHeader    = "X-Loki-RateLimit-Reason"
+	rateLimitTenantHeader    = "X-Loki-RateLimit-Tenant"
+	rateLimitLimitHeader     = "X-Loki-RateLimit-Limit"
+	rateLimitObservedHeader  = "X-Loki-RateLimit-Observed-Bytes"
+	maxRetryAfterSeconds     = 60
+	defaultRetryAfterSeconds = 1
+)
+
+// rateLimitedPush describes a push rejected by a rate limit.
+type rateLimitedPush struct {
+	reason string
+	// limit is the enforced limit in bytes per second.
+	limit float64
+	// bytes is the size of the rejected push.
+	bytes int
+}
+
+// retryAfter returns the number of seconds after which the rejected push would be
+// accepted, assuming no other pushes consume the limit in the meantime.
+func (p rateLimitedPush) retryAfter() int {
+	if p.limit <= 0 {
+		return defaultRetryAfterSeconds
+	}
+	seconds := int(math.Ceil(float64(p.bytes) / p.limit))
+	if seconds < defaultRetryAfterSeconds {
+		return defaultRetryAfterSeconds
+	}
+	if seconds > maxRetryAfterSeconds {
+		return maxRetryAfterSeconds
+	}
+	return seconds
+}
+
+// error returns the httpgrpc error of the rejected push with the given status code.
+// Retry-After is only set for 429, as clients must not retry other codes.
+func (p rateLimitedPush) error(code int, tenantID, msg string) error {
+	headers := []*httpgrpc.Header{
+		{Key: rateLimitReasonHeader, Values: []string{p.reason}},
+		{Key: rateLimitTenantHeader, Values: []string{tenantID}},
+		{Key: rateLimitLimitHeader, Values: []string{strconv.FormatFloat(p.limit, 'f', 0, 64)}},
+		{Key: rateLimitObservedHeader, Values: []string{strconv.Itoa(p.bytes)}},
+	}
+	if code == http.StatusTooManyRequests {
+		headers = append(headers, &httpgrpc.Header{Key: retryAfterHeader, Values: []string{strconv.Itoa(p.retryAfter())}})
+	}
+	return httpgrpc.ErrorFromHTTPResponse(&httpgrpc.HTTPResponse{
+		Code:    int32(code),
+		Body:    []byte(msg),
+		Headers: headers,
+	})
+}
@@ -0,0 +1,140 @@
+package distributor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/dskit/services"
+	"golang.org/x/time/rate"
+)
+
+// streamLimiterIdleTimeout is the time after which the limiter of a stream
+// that did not receive any push is removed.
+const streamLimiterIdleTimeout = time.Minute
+
+type streamKey struct {
+	tenant string
+	hash   uint64
+}
+
+type streamLimiter struct {
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+// streamRateLimiter enforces the per-stream rate limits in the distributor.
+// With the global ingestion rate strategy the limit is evenly shared across
+// the healthy distributors, like the tenant ingestion rate limit.
+type streamRateLimiter struct {
+	services.Service
+
+	limits Limits
+	ring   ReadLifecycler // nil for the local strategy
+
+	mtx      sync.Mutex
+	limiters map[streamKey]*streamLimiter
+}
+
+func newStreamRateLimiter(limits Limits, ring ReadLifecycler) *streamRateLimiter {
+	l := &streamRateLimiter{
+		limits:   limits,
+		ring:     ring,
+		limiters: map[streamKey]*streamLimiter{},
+	}
+	l.Service = services.NewTimerService(streamLimiterIdleTimeout, nil, l.iteration, nil)
+	return l
+}
+
+func (l *streamRateLimiter) iteration(_ context.Context) error {
+	l.removeIdle(time.Now().Add(-streamLimiterIdleTimeout))
+	return nil
+}
+
+// limit returns the per-distributor rate limit and burst of streams of the tenant.
+func (l *streamRateLimiter) limit(tenant string) (rate.Limit, int) {
+	limit := l.limits.DistributorPerStreamRateLimit(tenant)
+	if limit.Burst <= 0 {
+		limit.Burst = int(limit.Limit)
+	}
+	if l.ring != nil {
+		if n := l.ring.HealthyInstancesCount(); n > 0 {
+			limit.Limit /= rate.Limit(n)
+		}
+	}
+	return limit.Limit, limit.Burst
+}
+
+// streamRateLimitResult is the result of checking a push of a stream against its rate limit.
+type streamRateLimitResult int
+
+const (
+	streamRateLimitAllowed streamRateLimitResult = iota
+	// streamRateLimitExceeded is returned when the stream used up its limit, the push can be retried later.
+	streamRateLimitExceeded
+	// streamRateLimitBurstExceeded is returned when the push is larger than the burst, so it can never be accepted.
+	streamRateLimitBurstExceeded
+)
+
+// Check reports whether n bytes may be pushed to the stream at time now, without consuming the limit.
+// The limit is consumed by AllowN once the whole push is accepted.
+// It returns the effective limit and burst, which are 0 if the tenant has no per-stream limit.
+func (l *streamRateLimiter) Check(now time.Time, tenant string, hash uint64, n int) (streamRateLimitResult, rate.Limit, int) {
+	lim, limit, burst := l.limiter(now, tenant, hash)
+	switch {
+	case lim == nil:
+		return streamRateLimitAllowed, 0, 0
+	case n > burst:
+		return streamRateLimitBurstExceeded, limit, burst
+	case float64(n) > lim.TokensAt(now):
+		return streamRateLimitExceeded, limit, burst
+	}
+	return streamRateLimitAllowed, limit, burst
+}
+
+// AllowN consumes n bytes of the limit of the stream at time now and reports whether they were available.
+func (l *streamRateLimiter) AllowN(now time.Time, tenant string, hash uint64, n int) bool {
+	lim, _, _ := l.limiter(now, tenant, hash)
+	return lim == nil || lim.AllowN(now, n)
+}
+
+// limiter returns the limiter of the stream, which is nil if the tenant has no per-stream limit.
+func (l *streamRateLimiter) limiter(now time.Time, tenant string, hash uint64) (*rate.Limiter, rate.Limit, int) {
+	limit, burst := l.limit(tenant)
+	if limit <= 0 {
+		return nil, 0, 0
+	}
+
+	key := streamKey{tenant: tenant, hash: hash}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	s, ok := l.limiters[key]
+	if !ok {
+		s = &streamLimiter{limiter: rate.NewLimiter(limit, burst)}
+		l.limiters[key] = s
+	} else {
+		// The limits can be changed at runtime.
+		if s.limiter.Limit() != limit {
+			s.limiter.SetLimitAt(now, limit)
+		}
+		if s.limiter.Burst() != burst {
+			s.limiter.SetBurstAt(now, burst)
+		}
+	}
+	s.lastUsed = now
+	return s.limiter, limit, burst
+}
+
+// removeIdle removes the limiters of streams that have not been used since the given time.
+func (l *streamRateLimiter) removeIdle(since time.Time) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	for key, s := range l.limiters {
+		if s.lastUsed.Before(since) {
+			delete(l.limiters, key)
+		}
+	}
+}
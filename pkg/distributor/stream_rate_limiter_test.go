@@ -0,0 +1,95 @@
+package distributor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/flagext"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	loki_flagext "github.com/grafana/loki/pkg/util/flagext"
+	"github.com/grafana/loki/pkg/validation"
+)
+
+type fakeReadLifecycler int
+
+func (f fakeReadLifecycler) HealthyInstancesCount() int { return int(f) }
+
+func newStreamRateLimiterOverrides(t *testing.T, limit, burst int) Limits {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.DistributorPerStreamRateLimit = loki_flagext.ByteSize(limit)
+	limits.DistributorPerStreamRateLimitBurst = loki_flagext.ByteSize(burst)
+	overrides, err := validation.NewOverrides(*limits, nil)
+	require.NoError(t, err)
+	return overrides
+}
+
+func TestStreamRateLimiter_AllowN(t *testing.T) {
+	l := newStreamRateLimiter(newStreamRateLimiterOverrides(t, 10, 100), nil)
+	now := time.Now()
+
+	require.True(t, l.AllowN(now, "tenant", 1, 100))
+	require.False(t, l.AllowN(now, "tenant", 1, 1))
+
+	// Other streams and tenants have their own limit.
+	require.True(t, l.AllowN(now, "tenant", 2, 100))
+	require.True(t, l.AllowN(now, "other", 1, 100))
+
+	// The bucket refills over time.
+	require.True(t, l.AllowN(now.Add(time.Second), "tenant", 1, 10))
+}
+
+func TestStreamRateLimiter_Check(t *testing.T) {
+	l := newStreamRateLimiter(newStreamRateLimiterOverrides(t, 10, 100), nil)
+	now := time.Now()
+
+	res, limit, burst := l.Check(now, "tenant", 1, 100)
+	require.Equal(t, streamRateLimitAllowed, res)
+	require.Equal(t, rate.Limit(10), limit)
+	require.Equal(t, 100, burst)
+
+	// Checking doesn't consume the limit.
+	res, _, _ = l.Check(now, "tenant", 1, 100)
+	require.Equal(t, streamRateLimitAllowed, res)
+
+	require.True(t, l.AllowN(now, "tenant", 1, 100))
+	res, _, _ = l.Check(now, "tenant", 1, 1)
+	require.Equal(t, streamRateLimitExceeded, res)
+
+	// A push larger than the burst can never be accepted.
+	res, _, _ = l.Check(now, "tenant", 2, 101)
+	require.Equal(t, streamRateLimitBurstExceeded, res)
+}
+
+func TestStreamRateLimiter_Disabled(t *testing.T) {
+	l := newStreamRateLimiter(newStreamRateLimiterOverrides(t, 0, 0), nil)
+	res, limit, _ := l.Check(time.Now(), "tenant", 1, 1<<30)
+	require.Equal(t, streamRateLimitAllowed, res)
+	require.Equal(t, rate.Limit(0), limit)
+	require.True(t, l.AllowN(time.Now(), "tenant", 1, 1<<30))
+	require.Empty(t, l.limiters)
+}
+
+func TestStreamRateLimiter_DefaultBurst(t *testing.T) {
+	l := newStreamRateLimiter(newStreamRateLimiterOverrides(t, 10, 0), nil)
+	require.True(t, l.AllowN(time.Now(), "tenant", 1, 10))
+}
+
+func TestStreamRateLimiter_GlobalStrategy(t *testing.T) {
+	l := newStreamRateLimiter(newStreamRateLimiterOverrides(t, 10, 100), fakeReadLifecycler(5))
+	_, limit, _ := l.Check(time.Now(), "tenant", 1, 1)
+	require.Equal(t, rate.Limit(2), limit)
+}
+
+func TestStreamRateLimiter_RemoveIdle(t *testing.T) {
+	l := newStreamRateLimiter(newStreamRateLimiterOverrides(t, 10, 100), nil)
+	now := time.Now()
+	l.AllowN(now.Add(-time.Hour), "tenant", 1, 1)
+	l.AllowN(now, "tenant", 2, 1)
+
+	l.removeIdle(now.Add(-time.Minute))
+	require.Len(t, l.limiters, 1)
+	require.Contains(t, l.limiters, streamKey{tenant: "tenant", hash: 2})
+}
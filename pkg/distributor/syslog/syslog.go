@@ -0,0 +1,491 @@
+package syslog
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/services"
+	"github.com/influxdata/go-syslog/v3"
+	"github.com/influxdata/go-syslog/v3/nontransparent"
+	"github.com/influxdata/go-syslog/v3/octetcounting"
+	"github.com/influxdata/go-syslog/v3/rfc5424"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/ruler/util"
+)
+
+const (
+	// TenantLabel is the label that relabel configs can set to select the tenant of a message.
+	TenantLabel = "__tenant_id__"
+
+	labelPrefix = "__syslog_"
+)
+
+// Config configures the syslog listener of the distributor.
+type Config struct {
+	TCPListenAddress string `yaml:"tcp_listen_address" category:"experimental"`
+	UDPListenAddress string `yaml:"udp_listen_address" category:"experimental"`
+
+	TLSCertPath     string `yaml:"tls_cert_path" category:"experimental"`
+	TLSKeyPath      string `yaml:"tls_key_path" category:"experimental"`
+	TLSClientCAPath string `yaml:"tls_client_ca_path" category:"experimental"`
+
+	Tenant               string                `yaml:"tenant" category:"experimental"`
+	RelabelConfigs       []*util.RelabelConfig `yaml:"relabel_configs,omitempty" category:"experimental" doc:"description=List of relabel configurations applied to the labels of the messages. The labels __syslog_message_severity, __syslog_message_facility, __syslog_message_hostname, __syslog_message_app_name, __syslog_message_proc_id, __syslog_message_msg_id and __syslog_message_sd_<id>_<name> are available. Setting __tenant_id__ overrides the tenant of the message. Labels starting with __ are removed afterwards. If empty, the hostname and app name are mapped to the host and app labels."`
+	UseIncomingTimestamp bool                  `yaml:"use_incoming_timestamp" category:"experimental"`
+	MaxMessageLength     int                   `yaml:"max_message_length" category:"experimental"`
+	IdleTimeout          time.Duration         `yaml:"idle_timeout" category:"experimental"`
+	BatchWait            time.Duration         `yaml:"batch_wait" category:"experimental"`
+	BatchSize            int                   `yaml:"batch_size" category:"experimental"`
+}
+
+// RegisterFlagsWithPrefix registers syslog-related flags.
+func (cfg *Config) RegisterFlagsWithPrefix(prefix string, fs *flag.FlagSet) {
+	fs.StringVar(&cfg.TCPListenAddress, prefix+".tcp-listen-address", "", "Experimental and subject to change. Address to listen on for RFC5424 syslog messages over TCP, e.g. :1514. The TCP listener is disabled if empty.")
+	fs.StringVar(&cfg.UDPListenAddress, prefix+".udp-listen-address", "", "Experimental and subject to change. Address to listen on for RFC5424 syslog messages over UDP, one message per datagram. The UDP listener is disabled if empty.")
+	fs.StringVar(&cfg.TLSCertPath, prefix+".tls-cert-path", "", "Experimental and subject to change. Path to the server certificate. Enables TLS on the TCP listener together with the key.")
+	fs.StringVar(&cfg.TLSKeyPath, prefix+".tls-key-path", "", "Experimental and subject to change. Path to the server key.")
+	fs.StringVar(&cfg.TLSClientCAPath, prefix+".tls-client-ca-path", "", "Experimental and subject to change. Path to the CA used to verify client certificates. Client certificates are not required if empty.")
+	fs.StringVar(&cfg.Tenant, prefix+".tenant", "fake", "Experimental and subject to change. Tenant of the messages whose labels do not set __tenant_id__.")
+	fs.BoolVar(&cfg.UseIncomingTimestamp, prefix+".use-incoming-timestamp", false, "Experimental and subject to change. Use the timestamp of the messages instead of the time they are received.")
+	fs.IntVar(&cfg.MaxMessageLength, prefix+".max-message-length", 8192, "Experimental and subject to change. Maximum length of a syslog message.")
+	fs.DurationVar(&cfg.IdleTimeout, prefix+".idle-timeout", 2*time.Minute, "Experimental and subject to change. Timeout after which idle TCP connections are closed.")
+	fs.DurationVar(&cfg.BatchWait, prefix+".batch-wait", time.Second, "Experimental and subject to change. Maximum time to wait before pushing the received messages.")
+	fs.IntVar(&cfg.BatchSize, prefix+".batch-size", 1<<20, "Experimental and subject to change. Maximum size in bytes of the messages pushed at once.")
+}
+
+// Enabled returns whether any listener is configured.
+func (cfg *Config) Enabled() bool {
+	return cfg.TCPListenAddress != "" || cfg.UDPListenAddress != ""
+}
+
+// defaultRelabelConfigs are used if no relabel configs are configured.
+var defaultRelabelConfigs = []*util.RelabelConfig{
+	{SourceLabels: []string{"__syslog_message_hostname"}, TargetLabel: "host"},
+	{SourceLabels: []string{"__syslog_message_app_name"}, TargetLabel: "app"},
+}
+
+type metrics struct {
+	entries        *prometheus.CounterVec
+	parseErrors    *prometheus.CounterVec
+	pushFailures   *prometheus.CounterVec
+	droppedEntries prometheus.Counter
+}
+
+func newMetrics(registerer prometheus.Registerer) *metrics {
+	return &metrics{
+		entries: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki",
+			Name:      "distributor_syslog_entries_total",
+			Help:      "The total number of syslog messages received, by protocol.",
+		}, []string{"protocol"}),
+		parseErrors: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki",
+			Name:      "distributor_syslog_parsing_errors_total",
+			Help:      "The total number of syslog messages that could not be parsed, by protocol.",
+		}, []string{"protocol"}),
+		pushFailures: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki",
+			Name:      "distributor_syslog_push_failures_total",
+			Help:      "The total number of failed pushes of syslog messages, by tenant.",
+		}, []string{"tenant"}),
+		droppedEntries: promauto.With(registerer).NewCounter(prometheus.CounterOpts{
+			Namespace: "loki",
+			Name:      "distributor_syslog_dropped_entries_total",
+			Help:      "The total number of syslog messages dropped by relabeling.",
+		}),
+	}
+}
+
+type entry struct {
+	tenant string
+	labels string
+	logproto.Entry
+}
+
+// Syslog receives RFC5424 syslog messages over TCP and UDP and pushes them as log streams.
+type Syslog struct {
+	services.Service
+
+	cfg            Config
+	pusher         logproto.PusherServer
+	relabelConfigs []*relabel.Config
+	tlsConfig      *tls.Config
+	metrics        *metrics
+	logger         log.Logger
+
+	tcp     net.Listener
+	udp     net.PacketConn
+	entries chan entry
+
+	connsMtx sync.Mutex
+	conns    map[net.Conn]struct{}
+	wg       sync.WaitGroup
+}
+
+// New creates a Syslog pushing the received messages to the pusher.
+func New(cfg Config, pusher logproto.PusherServer, registerer prometheus.Registerer, logger log.Logger) (*Syslog, error) {
+	if cfg.BatchWait <= 0 {
+		return nil, fmt.Errorf("syslog batch wait must be greater than zero")
+	}
+
+	configs := cfg.RelabelConfigs
+	if len(configs) == 0 {
+		configs = defaultRelabelConfigs
+	}
+	relabelConfigs, err := util.ParseRelabelConfigs(configs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid relabel configs: %w", err)
+	}
+
+	s := &Syslog{
+		cfg:            cfg,
+		pusher:         pusher,
+		relabelConfigs: relabelConfigs,
+		metrics:        newMetrics(registerer),
+		logger:         logger,
+		entries:        make(chan entry, 1000),
+		conns:          map[net.Conn]struct{}{},
+	}
+	if cfg.TLSCertPath != "" || cfg.TLSKeyPath != "" {
+		if s.tlsConfig, err = newTLSConfig(cfg); err != nil {
+			return nil, err
+		}
+	}
+	s.Service = services.NewBasicService(s.starting, s.running, nil)
+	return s, nil
+}
+
+func newTLSConfig(cfg Config) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(cfg.TLSCertPath, cfg.TLSKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load syslog TLS certificate: %w", err)
+	}
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}}
+	if cfg.TLSClientCAPath != "" {
+		ca, err := os.ReadFile(cfg.TLSClientCAPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read syslog TLS client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificate found in syslog TLS client CA %s", cfg.TLSClientCAPath)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+func (s *Syslog) starting(_ context.Context) error {
+	var err error
+	if s.cfg.TCPListenAddress != "" {
+		if s.tcp, err = net.Listen("tcp", s.cfg.TCPListenAddress); err != nil {
+			return fmt.Errorf("failed to listen on syslog TCP address: %w", err)
+		}
+		if s.tlsConfig != nil {
+			s.tcp = tls.NewListener(s.tcp, s.tlsConfig)
+		}
+		level.Info(s.logger).Log("msg", "syslog TCP listener started", "addr", s.tcp.Addr())
+	}
+	if s.cfg.UDPListenAddress != "" {
+		if s.udp, err = net.ListenPacket("udp", s.cfg.UDPListenAddress); err != nil {
+			if s.tcp != nil {
+				s.tcp.Close()
+			}
+			return fmt.Errorf("failed to listen on syslog UDP address: %w", err)
+		}
+		level.Info(s.logger).Log("msg", "syslog UDP listener started", "addr", s.udp.LocalAddr())
+	}
+	return nil
+}
+
+// TCPAddr returns the address of the TCP listener, nil if disabled.
+func (s *Syslog) TCPAddr() net.Addr {
+	if s.tcp == nil {
+		return nil
+	}
+	return s.tcp.Addr()
+}
+
+// UDPAddr returns the address of the UDP listener, nil if disabled.
+func (s *Syslog) UDPAddr() net.Addr {
+	if s.udp == nil {
+		return nil
+	}
+	return s.udp.LocalAddr()
+}
+
+func (s *Syslog) running(ctx context.Context) error {
+	if s.tcp != nil {
+		s.wg.Add(1)
+		go s.acceptConnections()
+	}
+	if s.udp != nil {
+		s.wg.Add(1)
+		go s.receivePackets()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.batch()
+	}()
+
+	<-ctx.Done()
+	if s.tcp != nil {
+		s.tcp.Close()
+	}
+	if s.udp != nil {
+		s.udp.Close()
+	}
+	s.connsMtx.Lock()
+	for c := range s.conns {
+		c.Close()
+	}
+	s.connsMtx.Unlock()
+	s.wg.Wait()
+
+	// Push the remaining messages once every receiver stopped.
+	close(s.entries)
+	<-done
+	return nil
+}
+
+func (s *Syslog) acceptConnections() {
+	defer s.wg.Done()
+	for {
+		c, err := s.tcp.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				level.Warn(s.logger).Log("msg", "failed to accept syslog connection", "err", err)
+				continue
+			}
+			return
+		}
+		s.connsMtx.Lock()
+		s.conns[c] = struct{}{}
+		s.connsMtx.Unlock()
+
+		s.wg.Add(1)
+		go s.handleConnection(c)
+	}
+}
+
+func (s *Syslog) handleConnection(c net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.connsMtx.Lock()
+		delete(s.conns, c)
+		s.connsMtx.Unlock()
+		c.Close()
+	}()
+
+	r := bufio.NewReader(&idleTimeoutConn{Conn: c, timeout: s.cfg.IdleTimeout})
+	opts := []syslog.ParserOption{
+		syslog.WithListener(func(res *syslog.Result) { s.handleResult("tcp", res) }),
+		syslog.WithMaxMessageLength(s.cfg.MaxMessageLength),
+		syslog.WithBestEffort(),
+	}
+
+	// Detect the framing of the stream from the first byte: octet counting
+	// starts with the message length, non-transparent framing with the priority.
+	b, err := r.Peek(1)
+	if err != nil {
+		return
+	}
+	if b[0] >= '0' && b[0] <= '9' {
+		octetcounting.NewParser(opts...).Parse(r)
+	} else {
+		nontransparent.NewParser(opts...).Parse(r)
+	}
+}
+
+func (s *Syslog) receivePackets() {
+	defer s.wg.Done()
+	parser := rfc5424.NewParser(rfc5424.WithBestEffort())
+	buf := make([]byte, s.cfg.MaxMessageLength)
+	for {
+		n, _, err := s.udp.ReadFrom(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				level.Warn(s.logger).Log("msg", "failed to read syslog packet", "err", err)
+				continue
+			}
+			return
+		}
+		msg, err := parser.Parse(buf[:n])
+		s.handleResult("udp", &syslog.Result{Message: msg, Error: err})
+	}
+}
+
+func (s *Syslog) handleResult(protocol string, res *syslog.Result) {
+	if res.Error != nil && res.Message == nil {
+		s.metrics.parseErrors.WithLabelValues(protocol).Inc()
+		level.Debug(s.logger).Log("msg", "failed to parse syslog message", "protocol", protocol, "err", res.Error)
+		return
+	}
+	msg, ok := res.Message.(*rfc5424.SyslogMessage)
+	if !ok || msg.Message == nil {
+		s.metrics.parseErrors.WithLabelValues(protocol).Inc()
+		return
+	}
+	s.metrics.entries.WithLabelValues(protocol).Inc()
+
+	e, ok := s.toEntry(msg, time.Now())
+	if !ok {
+		s.metrics.droppedEntries.Inc()
+		return
+	}
+	s.entries <- e
+}
+
+// toEntry converts the message to an entry of the stream selected by the relabel configs.
+// It returns false if the message is dropped by relabeling.
+func (s *Syslog) toEntry(msg *rfc5424.SyslogMessage, now time.Time) (entry, bool) {
+	lb := labels.NewBuilder(nil)
+	lb.Set("job", "syslog")
+	if v := msg.SeverityLevel(); v != nil {
+		lb.Set(labelPrefix+"message_severity", *v)
+	}
+	if v := msg.FacilityLevel(); v != nil {
+		lb.Set(labelPrefix+"message_facility", *v)
+	}
+	for name, v := range map[string]*string{
+		"hostname": msg.Hostname,
+		"app_name": msg.Appname,
+		"proc_id":  msg.ProcID,
+		"msg_id":   msg.MsgID,
+	} {
+		if v != nil && *v != "" {
+			lb.Set(labelPrefix+"message_"+name, *v)
+		}
+	}
+	if msg.StructuredData != nil {
+		for id, params := range *msg.StructuredData {
+			id = strings.ReplaceAll(id, "@", "_")
+			for name, v := range params {
+				lb.Set(labelPrefix+"message_sd_"+id+"_"+name, v)
+			}
+		}
+	}
+
+	ls, keep := relabel.Process(lb.Labels(), s.relabelConfigs...)
+	if !keep {
+		return entry{}, false
+	}
+
+	tenant := s.cfg.Tenant
+	lb = labels.NewBuilder(nil)
+	for _, l := range ls {
+		if l.Name == TenantLabel {
+			if l.Value != "" {
+				tenant = l.Value
+			}
+			continue
+		}
+		if strings.HasPrefix(l.Name, "__") {
+			continue
+		}
+		lb.Set(l.Name, l.Value)
+	}
+
+	ts := now
+	if s.cfg.UseIncomingTimestamp && msg.Timestamp != nil {
+		ts = *msg.Timestamp
+	}
+	return entry{
+		tenant: tenant,
+		labels: lb.Labels().String(),
+		Entry:  logproto.Entry{Timestamp: ts, Line: *msg.Message},
+	}, true
+}
+
+// batch pushes the received entries per tenant once the batch size or the batch wait is reached.
+func (s *Syslog) batch() {
+	ticker := time.NewTicker(s.cfg.BatchWait)
+	defer ticker.Stop()
+
+	var (
+		batches = map[string]map[string]*logproto.Stream{}
+		size    int
+	)
+	flush := func() {
+		for tenant, streams := range batches {
+			s.push(tenant, streams)
+		}
+		batches = map[string]map[string]*logproto.Stream{}
+		size = 0
+	}
+
+	for {
+		select {
+		case e, ok := <-s.entries:
+			if !ok {
+				flush()
+				return
+			}
+			streams, ok := batches[e.tenant]
+			if !ok {
+				streams = map[string]*logproto.Stream{}
+				batches[e.tenant] = streams
+			}
+			stream, ok := streams[e.labels]
+			if !ok {
+				stream = &logproto.Stream{Labels: e.labels}
+				streams[e.labels] = stream
+			}
+			stream.Entries = append(stream.Entries, e.Entry)
+			size += len(e.Line)
+			if size >= s.cfg.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func (s *Syslog) push(tenant string, streams map[string]*logproto.Stream) {
+	req := &logproto.PushRequest{Streams: make([]logproto.Stream, 0, len(streams))}
+	for _, stream := range streams {
+		req.Streams = append(req.Streams, *stream)
+	}
+	ctx := user.InjectOrgID(context.Background(), tenant)
+	if _, err := s.pusher.Push(ctx, req); err != nil {
+		s.metrics.pushFailures.WithLabelValues(tenant).Inc()
+		level.Warn(s.logger).Log("msg", "failed to push syslog messages", "tenant", tenant, "err", err)
+	}
+}
+
+// idleTimeoutConn extends the read deadline of the connection on every read.
+type idleTimeoutConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func (c *idleTimeoutConn) Read(p []byte) (int, error) {
+	if c.timeout > 0 {
+		if err := c.Conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+			return 0, err
+		}
+	}
+	return c.Conn.Read(p)
+}
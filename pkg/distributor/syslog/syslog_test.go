@@ -0,0 +1,177 @@
+package syslog
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/services"
+	"github.com/influxdata/go-syslog/v3/rfc5424"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/ruler/util"
+)
+
+type pushedStream struct {
+	tenant string
+	logproto.Stream
+}
+
+type fakePusher struct {
+	mtx     sync.Mutex
+	streams []pushedStream
+}
+
+func (p *fakePusher) Push(ctx context.Context, req *logproto.PushRequest) (*logproto.PushResponse, error) {
+	tenant, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	for _, s := range req.Streams {
+		p.streams = append(p.streams, pushedStream{tenant: tenant, Stream: s})
+	}
+	return &logproto.PushResponse{}, nil
+}
+
+func (p *fakePusher) pushed() []pushedStream {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return append([]pushedStream(nil), p.streams...)
+}
+
+func defaultConfig() Config {
+	var cfg Config
+	cfg.RegisterFlagsWithPrefix("syslog", flag.NewFlagSet("", flag.PanicOnError))
+	cfg.BatchWait = 10 * time.Millisecond
+	return cfg
+}
+
+func newSyslog(t *testing.T, cfg Config, pusher logproto.PusherServer) *Syslog {
+	s, err := New(cfg, pusher, prometheus.NewRegistry(), log.NewNopLogger())
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), s))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), s))
+	})
+	return s
+}
+
+const message = `<165>1 2023-10-11T22:14:15.003Z host1 app1 1234 ID47 [origin@32473 tenant="tenant-a"] hello world`
+
+func TestSyslog_TCP(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.TCPListenAddress = "127.0.0.1:0"
+	pusher := &fakePusher{}
+	s := newSyslog(t, cfg, pusher)
+
+	for _, framed := range []string{
+		message + "\n", // non-transparent framing
+		fmt.Sprintf("%d %s", len(message), message), // octet counting
+	} {
+		c, err := net.Dial("tcp", s.TCPAddr().String())
+		require.NoError(t, err)
+		_, err = c.Write([]byte(framed))
+		require.NoError(t, err)
+		require.NoError(t, c.Close())
+	}
+
+	require.Eventually(t, func() bool { return countEntries(pusher.pushed()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	for _, s := range pusher.pushed() {
+		require.Equal(t, "fake", s.tenant)
+		require.Equal(t, `{app="app1", host="host1", job="syslog"}`, s.Labels)
+		for _, e := range s.Entries {
+			require.Equal(t, "hello world", e.Line)
+		}
+	}
+}
+
+func TestSyslog_UDP(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.UDPListenAddress = "127.0.0.1:0"
+	cfg.UseIncomingTimestamp = true
+	pusher := &fakePusher{}
+	s := newSyslog(t, cfg, pusher)
+
+	c, err := net.Dial("udp", s.UDPAddr().String())
+	require.NoError(t, err)
+	defer c.Close()
+	_, err = c.Write([]byte(message))
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool { return countEntries(pusher.pushed()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	e := pusher.pushed()[0].Entries[0]
+	require.Equal(t, "hello world", e.Line)
+	require.Equal(t, time.Date(2023, 10, 11, 22, 14, 15, 3e6, time.UTC), e.Timestamp.UTC())
+}
+
+func TestSyslog_ToEntry(t *testing.T) {
+	msg, err := rfc5424.NewParser(rfc5424.WithBestEffort()).Parse([]byte(message))
+	require.NoError(t, err)
+	now := time.Now()
+
+	for _, tc := range []struct {
+		name           string
+		relabelConfigs []*util.RelabelConfig
+		tenant         string
+		labels         string
+		dropped        bool
+	}{
+		{
+			name:   "default mapping",
+			tenant: "fake",
+			labels: `{app="app1", host="host1", job="syslog"}`,
+		},
+		{
+			name: "custom mapping and tenant",
+			relabelConfigs: []*util.RelabelConfig{
+				{SourceLabels: []string{"__syslog_message_severity"}, TargetLabel: "level"},
+				{SourceLabels: []string{"__syslog_message_sd_origin_32473_tenant"}, TargetLabel: TenantLabel},
+			},
+			tenant: "tenant-a",
+			labels: `{job="syslog", level="notice"}`,
+		},
+		{
+			name: "dropped",
+			relabelConfigs: []*util.RelabelConfig{
+				{SourceLabels: []string{"__syslog_message_app_name"}, Regex: "app1", Action: "drop"},
+			},
+			dropped: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := defaultConfig()
+			cfg.RelabelConfigs = tc.relabelConfigs
+			s, err := New(cfg, &fakePusher{}, prometheus.NewRegistry(), log.NewNopLogger())
+			require.NoError(t, err)
+
+			e, ok := s.toEntry(msg.(*rfc5424.SyslogMessage), now)
+			if tc.dropped {
+				require.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			require.Equal(t, tc.tenant, e.tenant)
+			require.Equal(t, tc.labels, e.labels)
+			require.Equal(t, "hello world", e.Line)
+			require.Equal(t, now, e.Timestamp)
+		})
+	}
+}
+
+func countEntries(streams []pushedStream) int {
+	n := 0
+	for _, s := range streams {
+		n += len(s.Entries)
+	}
+	return n
+}
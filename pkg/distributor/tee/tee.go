@@ -0,0 +1,206 @@
+package tee
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/golang/snappy"
+	"github.com/grafana/dskit/backoff"
+	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/dskit/services"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/loki/pkg/logproto"
+)
+
+const pushPath = "/loki/api/v1/push"
+
+// Config configures the forwarding of accepted pushes to additional Loki endpoints.
+type Config struct {
+	Endpoints     flagext.StringSliceCSV `yaml:"endpoints" category:"experimental"`
+	QueueCapacity int                    `yaml:"queue_capacity" category:"experimental"`
+	Timeout       time.Duration          `yaml:"timeout" category:"experimental"`
+	Backoff       backoff.Config         `yaml:"backoff_config" category:"experimental"`
+}
+
+// RegisterFlagsWithPrefix registers tee-related flags.
+func (cfg *Config) RegisterFlagsWithPrefix(prefix string, fs *flag.FlagSet) {
+	fs.Var(&cfg.Endpoints, prefix+".endpoints", "Experimental and subject to change. Comma-separated list of Loki base URLs that accepted pushes are asynchronously forwarded to, e.g. http://loki-secondary:3100. Forwarding is disabled if empty.")
+	fs.IntVar(&cfg.QueueCapacity, prefix+".queue-capacity", 1000, "Experimental and subject to change. Number of push requests to buffer per endpoint. Requests are dropped when the queue is full.")
+	fs.DurationVar(&cfg.Timeout, prefix+".timeout", 10*time.Second, "Experimental and subject to change. Timeout for a single forwarded push request.")
+	cfg.Backoff.RegisterFlagsWithPrefix(prefix, fs)
+}
+
+// Enabled returns whether any endpoint is configured.
+func (cfg *Config) Enabled() bool {
+	return len(cfg.Endpoints) > 0
+}
+
+type metrics struct {
+	sent    *prometheus.CounterVec
+	failed  *prometheus.CounterVec
+	dropped *prometheus.CounterVec
+}
+
+func newMetrics(registerer prometheus.Registerer) *metrics {
+	return &metrics{
+		sent: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki",
+			Name:      "distributor_tee_requests_sent_total",
+			Help:      "The total number of push requests successfully forwarded to a tee endpoint.",
+		}, []string{"endpoint"}),
+		failed: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki",
+			Name:      "distributor_tee_requests_failed_total",
+			Help:      "The total number of push requests that could not be forwarded to a tee endpoint after all retries.",
+		}, []string{"endpoint"}),
+		dropped: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki",
+			Name:      "distributor_tee_requests_dropped_total",
+			Help:      "The total number of push requests dropped because the queue of a tee endpoint was full.",
+		}, []string{"endpoint"}),
+	}
+}
+
+type request struct {
+	tenant  string
+	streams []logproto.Stream
+}
+
+type endpoint struct {
+	url   string
+	queue chan request
+}
+
+// Tee forwards accepted push requests to additional Loki endpoints.
+// Every endpoint has its own queue and sender, so a slow endpoint does not affect the others.
+type Tee struct {
+	services.Service
+
+	cfg       Config
+	client    *http.Client
+	endpoints []*endpoint
+	metrics   *metrics
+	logger    log.Logger
+}
+
+// New creates a Tee for the configured endpoints.
+func New(cfg Config, registerer prometheus.Registerer, logger log.Logger) (*Tee, error) {
+	if cfg.QueueCapacity <= 0 {
+		return nil, fmt.Errorf("tee queue capacity must be greater than zero")
+	}
+
+	t := &Tee{
+		cfg:     cfg,
+		client:  &http.Client{Timeout: cfg.Timeout},
+		metrics: newMetrics(registerer),
+		logger:  logger,
+	}
+	for _, u := range cfg.Endpoints {
+		t.endpoints = append(t.endpoints, &endpoint{
+			url:   strings.TrimSuffix(u, "/") + pushPath,
+			queue: make(chan request, cfg.QueueCapacity),
+		})
+	}
+	t.Service = services.NewBasicService(nil, t.running, nil)
+	return t, nil
+}
+
+// Duplicate enqueues the streams of the tenant for every endpoint.
+// It never blocks; requests are dropped for endpoints whose queue is full.
+func (t *Tee) Duplicate(tenant string, streams []logproto.Stream) {
+	req := request{tenant: tenant, streams: streams}
+	for _, e := range t.endpoints {
+		select {
+		case e.queue <- req:
+		default:
+			t.metrics.dropped.WithLabelValues(e.url).Inc()
+		}
+	}
+}
+
+func (t *Tee) running(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, e := range t.endpoints {
+		wg.Add(1)
+		go func(e *endpoint) {
+			defer wg.Done()
+			t.run(ctx, e)
+		}(e)
+	}
+	wg.Wait()
+	return nil
+}
+
+func (t *Tee) run(ctx context.Context, e *endpoint) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case req := <-e.queue:
+			if err := t.send(ctx, e, req); err != nil {
+				t.metrics.failed.WithLabelValues(e.url).Inc()
+				level.Warn(t.logger).Log("msg", "failed to forward push request", "endpoint", e.url, "tenant", req.tenant, "err", err)
+				continue
+			}
+			t.metrics.sent.WithLabelValues(e.url).Inc()
+		}
+	}
+}
+
+func (t *Tee) send(ctx context.Context, e *endpoint, req request) error {
+	buf, err := (&logproto.PushRequest{Streams: req.streams}).Marshal()
+	if err != nil {
+		return err
+	}
+	buf = snappy.Encode(nil, buf)
+
+	var lastErr error
+	b := backoff.New(ctx, t.cfg.Backoff)
+	for b.Ongoing() {
+		var retry bool
+		retry, lastErr = t.post(ctx, e.url, req.tenant, buf)
+		if lastErr == nil || !retry {
+			return lastErr
+		}
+		b.Wait()
+	}
+	if lastErr == nil {
+		lastErr = b.Err()
+	}
+	return lastErr
+}
+
+// post sends the encoded request and returns whether a failed request should be retried.
+func (t *Tee) post(ctx context.Context, url, tenant string, body []byte) (bool, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+	httpReq.Header.Set("X-Scope-OrgID", tenant)
+
+	resp, err := t.client.Do(httpReq)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 == 2 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return false, nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	err = fmt.Errorf("server returned HTTP status %s: %s", resp.Status, bytes.TrimSpace(msg))
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode/100 == 5, err
+}
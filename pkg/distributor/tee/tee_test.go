@@ -0,0 +1,105 @@
+package tee
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/golang/snappy"
+	"github.com/grafana/dskit/backoff"
+	"github.com/grafana/dskit/services"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/logproto"
+)
+
+type received struct {
+	tenant string
+	req    logproto.PushRequest
+}
+
+func newServer(t *testing.T, statusCodes ...int) (*httptest.Server, chan received) {
+	ch := make(chan received, 10)
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, pushPath, r.URL.Path)
+		if calls < len(statusCodes) {
+			calls++
+			w.WriteHeader(statusCodes[calls-1])
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		buf, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+		var req logproto.PushRequest
+		require.NoError(t, req.Unmarshal(buf))
+		ch <- received{tenant: r.Header.Get("X-Scope-OrgID"), req: req}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, ch
+}
+
+func newTee(t *testing.T, endpoints ...string) *Tee {
+	cfg := Config{
+		Endpoints:     endpoints,
+		QueueCapacity: 10,
+		Timeout:       time.Second,
+		Backoff:       backoff.Config{MinBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond, MaxRetries: 3},
+	}
+	tee, err := New(cfg, prometheus.NewRegistry(), log.NewNopLogger())
+	require.NoError(t, err)
+	return tee
+}
+
+func TestTee_Duplicate(t *testing.T) {
+	srv1, ch1 := newServer(t)
+	srv2, ch2 := newServer(t, http.StatusInternalServerError, http.StatusTooManyRequests)
+
+	tee := newTee(t, srv1.URL, srv2.URL+"/")
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), tee))
+	defer func() {
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), tee))
+	}()
+
+	streams := []logproto.Stream{{
+		Labels:  `{foo="bar"}`,
+		Entries: []logproto.Entry{{Timestamp: time.Unix(1, 0).UTC(), Line: "line"}},
+	}}
+	tee.Duplicate("tenant", streams)
+
+	for _, ch := range []chan received{ch1, ch2} {
+		select {
+		case r := <-ch:
+			require.Equal(t, "tenant", r.tenant)
+			require.Equal(t, streams, r.req.Streams)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for forwarded request")
+		}
+	}
+}
+
+func TestTee_DropsWhenQueueIsFull(t *testing.T) {
+	tee := newTee(t, "http://localhost:0")
+
+	// The tee is not running, so nothing consumes the queue.
+	for i := 0; i < 15; i++ {
+		tee.Duplicate("tenant", nil)
+	}
+	require.Equal(t, float64(5), testutil.ToFloat64(tee.metrics.dropped.WithLabelValues("http://localhost:0"+pushPath)))
+}
+
+func TestTee_DoesNotRetryClientErrors(t *testing.T) {
+	srv, _ := newServer(t, http.StatusBadRequest)
+
+	tee := newTee(t, srv.URL)
+	retry, err := tee.post(context.Background(), srv.URL+pushPath, "tenant", nil)
+	require.Error(t, err)
+	require.False(t, retry)
+}
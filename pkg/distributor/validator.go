@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
 
 	"github.com/grafana/loki/pkg/logproto"
 	"github.com/grafana/loki/pkg/validation"
@@ -18,13 +19,16 @@ const (
 
 type Validator struct {
 	Limits
+
+	// discards tracks the discarded lines per reason, nil if not tracked.
+	discards *discardTracker
 }
 
 func NewValidator(l Limits) (*Validator, error) {
 	if l == nil {
 		return nil, errors.New("nil Limits")
 	}
-	return &Validator{l}, nil
+	return &Validator{Limits: l}, nil
 }
 
 type validationContext struct {
@@ -34,6 +38,7 @@ type validationContext struct {
 
 	maxLineSize         int
 	maxLineSizeTruncate bool
+	truncateMarker      string
 
 	maxLabelNamesPerSeries int
 	maxLabelNameLength     int
@@ -41,6 +46,16 @@ type validationContext struct {
 
 	incrementDuplicateTimestamps bool
 
+	allowStructuredMetadata    bool
+	maxStructuredMetadataSize  int
+	maxStructuredMetadataCount int
+
+	relabelConfigs []*relabel.Config
+	dropRules      []validation.DropRule
+	samplingRules  []validation.SamplingRule
+	// haReplicaLabel is removed from the stream labels if set.
+	haReplicaLabel string
+
 	userID string
 }
 
@@ -52,10 +67,17 @@ func (v Validator) getValidationContextForTime(now time.Time, userID string) val
 		creationGracePeriod:          now.Add(v.CreationGracePeriod(userID)).UnixNano(),
 		maxLineSize:                  v.MaxLineSize(userID),
 		maxLineSizeTruncate:          v.MaxLineSizeTruncate(userID),
+		truncateMarker:               v.MaxLineSizeTruncateMarker(userID),
 		maxLabelNamesPerSeries:       v.MaxLabelNamesPerSeries(userID),
 		maxLabelNameLength:           v.MaxLabelNameLength(userID),
 		maxLabelValueLength:          v.MaxLabelValueLength(userID),
 		incrementDuplicateTimestamps: v.IncrementDuplicateTimestamps(userID),
+		allowStructuredMetadata:      v.AllowStructuredMetadata(userID),
+		maxStructuredMetadataSize:    v.MaxStructuredMetadataSize(userID),
+		maxStructuredMetadataCount:   v.MaxStructuredMetadataCount(userID),
+		relabelConfigs:               v.IngestionRelabelConfigs(userID),
+		dropRules:                    v.DropRules(userID),
+		samplingRules:                v.SamplingRules(userID),
 	}
 }
 
@@ -68,15 +90,13 @@ func (v Validator) ValidateEntry(ctx validationContext, labels string, entry log
 		// Makes time string on the error message formatted consistently.
 		formatedEntryTime := entry.Timestamp.Format(timeFormat)
 		formatedRejectMaxAgeTime := time.Unix(0, ctx.rejectOldSampleMaxAge).Format(timeFormat)
-		validation.DiscardedSamples.WithLabelValues(validation.GreaterThanMaxSampleAge, ctx.userID).Inc()
-		validation.DiscardedBytes.WithLabelValues(validation.GreaterThanMaxSampleAge, ctx.userID).Add(float64(len(entry.Line)))
+		v.reportDiscarded(ctx.userID, validation.GreaterThanMaxSampleAge, labels, 1, len(entry.Line))
 		return fmt.Errorf(validation.GreaterThanMaxSampleAgeErrorMsg, labels, formatedEntryTime, formatedRejectMaxAgeTime)
 	}
 
 	if ts > ctx.creationGracePeriod {
 		formatedEntryTime := entry.Timestamp.Format(timeFormat)
-		validation.DiscardedSamples.WithLabelValues(validation.TooFarInFuture, ctx.userID).Inc()
-		validation.DiscardedBytes.WithLabelValues(validation.TooFarInFuture, ctx.userID).Add(float64(len(entry.Line)))
+		v.reportDiscarded(ctx.userID, validation.TooFarInFuture, labels, 1, len(entry.Line))
 		return fmt.Errorf(validation.TooFarInFutureErrorMsg, labels, formatedEntryTime)
 	}
 
@@ -85,36 +105,61 @@ func (v Validator) ValidateEntry(ctx validationContext, labels string, entry log
 		// an orthogonal concept (we need not use ValidateLabels in this context)
 		// but the upstream cortex_validation pkg uses it, so we keep this
 		// for parity.
-		validation.DiscardedSamples.WithLabelValues(validation.LineTooLong, ctx.userID).Inc()
-		validation.DiscardedBytes.WithLabelValues(validation.LineTooLong, ctx.userID).Add(float64(len(entry.Line)))
+		v.reportDiscarded(ctx.userID, validation.LineTooLong, labels, 1, len(entry.Line))
 		return fmt.Errorf(validation.LineTooLongErrorMsg, maxSize, labels, len(entry.Line))
 	}
 
+	if len(entry.StructuredMetadata) > 0 {
+		if !ctx.allowStructuredMetadata {
+			v.reportDiscarded(ctx.userID, validation.DisallowedStructuredMetadata, labels, 1, len(entry.Line))
+			return fmt.Errorf(validation.DisallowedStructuredMetadataErrorMsg, labels)
+		}
+
+		var structuredMetadataSize int
+		for _, l := range entry.StructuredMetadata {
+			structuredMetadataSize += len(l.Name) + len(l.Value)
+		}
+		maxSize := ctx.maxStructuredMetadataSize
+		if maxSize == 0 {
+			// the structured metadata is still bounded by the line size limit when its own limit is disabled.
+			maxSize = ctx.maxLineSize
+		}
+		if maxSize != 0 && structuredMetadataSize > maxSize {
+			v.reportDiscarded(ctx.userID, validation.StructuredMetadataTooLarge, labels, 1, len(entry.Line))
+			return fmt.Errorf(validation.StructuredMetadataTooLargeErrorMsg, labels, structuredMetadataSize, maxSize)
+		}
+
+		if maxCount := ctx.maxStructuredMetadataCount; maxCount != 0 && len(entry.StructuredMetadata) > maxCount {
+			v.reportDiscarded(ctx.userID, validation.StructuredMetadataTooMany, labels, 1, len(entry.Line))
+			return fmt.Errorf(validation.StructuredMetadataTooManyErrorMsg, labels, len(entry.StructuredMetadata), maxCount)
+		}
+	}
+
 	return nil
 }
 
 // Validate labels returns an error if the labels are invalid
 func (v Validator) ValidateLabels(ctx validationContext, ls labels.Labels, stream logproto.Stream) error {
 	if len(ls) == 0 {
-		validation.DiscardedSamples.WithLabelValues(validation.MissingLabels, ctx.userID).Inc()
+		v.reportDiscarded(ctx.userID, validation.MissingLabels, stream.Labels, 1, 0)
 		return fmt.Errorf(validation.MissingLabelsErrorMsg)
 	}
 	numLabelNames := len(ls)
 	if numLabelNames > ctx.maxLabelNamesPerSeries {
-		updateMetrics(validation.MaxLabelNamesPerSeries, ctx.userID, stream)
+		v.updateMetrics(validation.MaxLabelNamesPerSeries, ctx.userID, stream)
 		return fmt.Errorf(validation.MaxLabelNamesPerSeriesErrorMsg, stream.Labels, numLabelNames, ctx.maxLabelNamesPerSeries)
 	}
 
 	lastLabelName := ""
 	for _, l := range ls {
 		if len(l.Name) > ctx.maxLabelNameLength {
-			updateMetrics(validation.LabelNameTooLong, ctx.userID, stream)
+			v.updateMetrics(validation.LabelNameTooLong, ctx.userID, stream)
 			return fmt.Errorf(validation.LabelNameTooLongErrorMsg, stream.Labels, l.Name)
 		} else if len(l.Value) > ctx.maxLabelValueLength {
-			updateMetrics(validation.LabelValueTooLong, ctx.userID, stream)
+			v.updateMetrics(validation.LabelValueTooLong, ctx.userID, stream)
 			return fmt.Errorf(validation.LabelValueTooLongErrorMsg, stream.Labels, l.Value)
 		} else if cmp := strings.Compare(lastLabelName, l.Name); cmp == 0 {
-			updateMetrics(validation.DuplicateLabelNames, ctx.userID, stream)
+			v.updateMetrics(validation.DuplicateLabelNames, ctx.userID, stream)
 			return fmt.Errorf(validation.DuplicateLabelNamesErrorMsg, stream.Labels, l.Name)
 		}
 		lastLabelName = l.Name
@@ -122,11 +167,19 @@ func (v Validator) ValidateLabels(ctx validationContext, ls labels.Labels, strea
 	return nil
 }
 
-func updateMetrics(reason, userID string, stream logproto.Stream) {
-	validation.DiscardedSamples.WithLabelValues(reason, userID).Inc()
+func (v Validator) updateMetrics(reason, userID string, stream logproto.Stream) {
 	bytes := 0
 	for _, e := range stream.Entries {
 		bytes += len(e.Line)
 	}
+	v.reportDiscarded(userID, reason, stream.Labels, 1, bytes)
+}
+
+// reportDiscarded records lines of the stream discarded for the given reason.
+func (v Validator) reportDiscarded(userID, reason, stream string, lines, bytes int) {
+	validation.DiscardedSamples.WithLabelValues(reason, userID).Add(float64(lines))
 	validation.DiscardedBytes.WithLabelValues(reason, userID).Add(float64(bytes))
+	if v.discards != nil {
+		v.discards.record(userID, reason, stream, lines, bytes)
+	}
 }
@@ -13,6 +13,7 @@ import (
 
 	"github.com/grafana/loki/pkg/logproto"
 	"github.com/grafana/loki/pkg/logql/syntax"
+	"github.com/grafana/loki/pkg/push"
 	"github.com/grafana/loki/pkg/validation"
 )
 
@@ -83,6 +84,64 @@ func TestValidator_ValidateEntry(t *testing.T) {
 			logproto.Entry{Timestamp: testTime, Line: "12345678901"},
 			fmt.Errorf(validation.LineTooLongErrorMsg, 10, testStreamLabels, 11),
 		},
+		{
+			"disallowed structured metadata",
+			"test",
+			fakeLimits{
+				&validation.Limits{
+					AllowStructuredMetadata: false,
+				},
+			},
+			logproto.Entry{Timestamp: testTime, Line: "12345678901", StructuredMetadata: push.LabelsAdapter{{Name: "foo", Value: "bar"}}},
+			fmt.Errorf(validation.DisallowedStructuredMetadataErrorMsg, testStreamLabels),
+		},
+		{
+			"structured metadata too big",
+			"test",
+			fakeLimits{
+				&validation.Limits{
+					AllowStructuredMetadata:   true,
+					MaxStructuredMetadataSize: 4,
+				},
+			},
+			logproto.Entry{Timestamp: testTime, Line: "12345678901", StructuredMetadata: push.LabelsAdapter{{Name: "foo", Value: "bar"}}},
+			fmt.Errorf(validation.StructuredMetadataTooLargeErrorMsg, testStreamLabels, 6, 4),
+		},
+		{
+			"structured metadata larger than the max line size without max structured metadata size",
+			"test",
+			fakeLimits{
+				&validation.Limits{
+					AllowStructuredMetadata: true,
+					MaxLineSize:             5,
+				},
+			},
+			logproto.Entry{Timestamp: testTime, Line: "1234", StructuredMetadata: push.LabelsAdapter{{Name: "foo", Value: "bar"}}},
+			fmt.Errorf(validation.StructuredMetadataTooLargeErrorMsg, testStreamLabels, 6, 5),
+		},
+		{
+			"structured metadata too many",
+			"test",
+			fakeLimits{
+				&validation.Limits{
+					AllowStructuredMetadata:           true,
+					MaxStructuredMetadataEntriesCount: 1,
+				},
+			},
+			logproto.Entry{Timestamp: testTime, Line: "12345678901", StructuredMetadata: push.LabelsAdapter{{Name: "foo", Value: "bar"}, {Name: "too", Value: "many"}}},
+			fmt.Errorf(validation.StructuredMetadataTooManyErrorMsg, testStreamLabels, 2, 1),
+		},
+		{
+			"valid structured metadata",
+			"test",
+			fakeLimits{
+				&validation.Limits{
+					AllowStructuredMetadata: true,
+				},
+			},
+			logproto.Entry{Timestamp: testTime, Line: "12345678901", StructuredMetadata: push.LabelsAdapter{{Name: "foo", Value: "bar"}}},
+			nil,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
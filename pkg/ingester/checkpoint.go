@@ -525,21 +525,32 @@ type Checkpointer struct {
 	iter    SeriesIter
 	writer  CheckpointWriter
 	metrics *ingesterMetrics
+	// checkpointOnShutdown writes a last checkpoint when quitting,
+	// so that restarting does not need to replay the WAL segments.
+	checkpointOnShutdown bool
 
 	quit <-chan struct{}
 }
 
-func NewCheckpointer(dur time.Duration, iter SeriesIter, writer CheckpointWriter, metrics *ingesterMetrics, quit <-chan struct{}) *Checkpointer {
+func NewCheckpointer(dur time.Duration, iter SeriesIter, writer CheckpointWriter, metrics *ingesterMetrics, checkpointOnShutdown bool, quit <-chan struct{}) *Checkpointer {
 	return &Checkpointer{
-		dur:     dur,
-		iter:    iter,
-		writer:  writer,
-		metrics: metrics,
-		quit:    quit,
+		dur:                  dur,
+		iter:                 iter,
+		writer:               writer,
+		metrics:              metrics,
+		checkpointOnShutdown: checkpointOnShutdown,
+		quit:                 quit,
 	}
 }
 
-func (c *Checkpointer) PerformCheckpoint() (err error) {
+// PerformCheckpoint writes a checkpoint, amortized over the checkpoint duration.
+func (c *Checkpointer) PerformCheckpoint() error {
+	return c.performCheckpoint(false)
+}
+
+// performCheckpoint writes a checkpoint. On shutdown, it is written as fast as
+// possible and is not aborted by quitting.
+func (c *Checkpointer) performCheckpoint(shutdown bool) (err error) {
 	noop, err := c.writer.Advance()
 	if err != nil {
 		return err
@@ -580,6 +591,10 @@ func (c *Checkpointer) PerformCheckpoint() (err error) {
 			return err
 		}
 
+		if shutdown {
+			continue
+		}
+
 		if !immediate {
 			if time.Since(start) > c.dur {
 				// This indicates the checkpoint is taking too long; stop waiting
@@ -618,6 +633,12 @@ func (c *Checkpointer) Run() {
 				continue
 			}
 		case <-c.quit:
+			if c.checkpointOnShutdown {
+				level.Info(util_log.Logger).Log("msg", "starting shutdown checkpoint")
+				if err := c.performCheckpoint(true); err != nil {
+					level.Error(util_log.Logger).Log("msg", "error checkpointing series on shutdown", "err", err)
+				}
+			}
 			return
 		}
 	}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
+	"strconv"
 	"testing"
 	"time"
 
@@ -134,6 +136,109 @@ func TestIngesterWAL(t *testing.T) {
 	ensureIngesterData(ctx, t, start, end, i)
 }
 
+func TestIngesterWALCheckpointOnShutdown(t *testing.T) {
+	walDir := t.TempDir()
+
+	ingesterConfig := defaultIngesterTestConfigWithWAL(t, walDir)
+	ingesterConfig.WAL.CheckpointDuration = time.Hour
+	ingesterConfig.WAL.CheckpointOnShutdown = true
+
+	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
+	require.NoError(t, err)
+
+	i, err := New(ingesterConfig, client.Config{}, &mockStore{chunks: map[string][]chunk.Chunk{}}, limits, runtime.DefaultTenantConfigs(), nil, writefailures.Cfg{})
+	require.NoError(t, err)
+	require.Nil(t, services.StartAndAwaitRunning(context.Background(), i))
+
+	start := time.Now()
+	steps := 10
+	end := start.Add(time.Second * time.Duration(steps))
+	req := logproto.PushRequest{
+		Streams: []logproto.Stream{
+			{Labels: `{foo="bar",bar="baz1"}`},
+			{Labels: `{foo="bar",bar="baz2"}`},
+		},
+	}
+	for i := 0; i < steps; i++ {
+		for j := range req.Streams {
+			req.Streams[j].Entries = append(req.Streams[j].Entries, logproto.Entry{
+				Timestamp: start.Add(time.Duration(i) * time.Second),
+				Line:      fmt.Sprintf("line %d", i),
+			})
+		}
+	}
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	_, err = i.Push(ctx, &req)
+	require.NoError(t, err)
+	require.Nil(t, services.StopAndAwaitTerminated(context.Background(), i))
+
+	expectCheckpoint(t, walDir, true, time.Second)
+
+	// The checkpoint holds all the data, so the segments are not needed anymore.
+	fs, err := os.ReadDir(walDir)
+	require.NoError(t, err)
+	for _, f := range fs {
+		if _, err := strconv.Atoi(f.Name()); err == nil {
+			require.NoError(t, os.Remove(filepath.Join(walDir, f.Name())))
+		}
+	}
+
+	i, err = New(ingesterConfig, client.Config{}, &mockStore{chunks: map[string][]chunk.Chunk{}}, limits, runtime.DefaultTenantConfigs(), nil, writefailures.Cfg{})
+	require.NoError(t, err)
+	defer services.StopAndAwaitTerminated(context.Background(), i) //nolint:errcheck
+	require.Nil(t, services.StartAndAwaitRunning(context.Background(), i))
+
+	ensureIngesterData(ctx, t, start, end, i)
+}
+
+func TestIngesterWALMixedCompression(t *testing.T) {
+	walDir := t.TempDir()
+
+	ingesterConfig := defaultIngesterTestConfigWithWAL(t, walDir)
+	// Replay from the segments only.
+	ingesterConfig.WAL.CheckpointDuration = time.Hour
+
+	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
+	require.NoError(t, err)
+
+	start := time.Now()
+	end := start.Add(5 * time.Second)
+	compressions := []string{"snappy", "zstd", "none"}
+
+	// Every restart appends records of another compression to the segments
+	// and replays the records of the previous ones; every run pushes to its own tenant.
+	for run, compression := range compressions {
+		ingesterConfig.WAL.Compression = compression
+		i, err := New(ingesterConfig, client.Config{}, &mockStore{chunks: map[string][]chunk.Chunk{}}, limits, runtime.DefaultTenantConfigs(), nil, writefailures.Cfg{})
+		require.NoError(t, err)
+		require.Nil(t, services.StartAndAwaitRunning(context.Background(), i))
+
+		for _, previous := range compressions[:run] {
+			ensureIngesterData(user.InjectOrgID(context.Background(), previous), t, start, end, i)
+		}
+
+		req := logproto.PushRequest{
+			Streams: []logproto.Stream{
+				{Labels: `{foo="bar",bar="baz1"}`},
+				{Labels: `{foo="bar",bar="baz2"}`},
+			},
+		}
+		for j := 0; j < 5; j++ {
+			for k := range req.Streams {
+				req.Streams[k].Entries = append(req.Streams[k].Entries, logproto.Entry{
+					Timestamp: start.Add(time.Duration(j) * time.Second),
+					Line:      fmt.Sprintf("line %d", j),
+				})
+			}
+		}
+		_, err = i.Push(user.InjectOrgID(context.Background(), compression), &req)
+		require.NoError(t, err)
+
+		require.Nil(t, services.StopAndAwaitTerminated(context.Background(), i))
+	}
+}
+
 func TestIngesterWALIgnoresStreamLimits(t *testing.T) {
 	walDir := t.TempDir()
 
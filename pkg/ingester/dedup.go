@@ -0,0 +1,95 @@
+package ingester
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+
+	"github.com/grafana/loki/pkg/logproto"
+)
+
+// recentEntries remembers the entries pushed to a stream within a time window,
+// to suppress the exact duplicates sent again by retrying clients.
+// Not thread-safe; assume accesses are locked by the stream chunkMtx.
+type recentEntries struct {
+	window time.Duration
+	// pushed are the entries pushed within the window by hash. The entries with the same hash are
+	// compared as a whole, so the entries whose hashes collide are not taken for duplicates.
+	pushed    map[uint64][]recentEntry
+	lastPrune time.Time
+}
+
+type recentEntry struct {
+	entry    logproto.Entry
+	pushedAt time.Time
+}
+
+func newRecentEntries(window time.Duration) *recentEntries {
+	return &recentEntries{
+		window: window,
+		pushed: map[uint64][]recentEntry{},
+	}
+}
+
+// contains returns whether an entry with the same timestamp, line and structured metadata was pushed within the window.
+func (r *recentEntries) contains(e *logproto.Entry, now time.Time) bool {
+	for _, recent := range r.pushed[entryHash(e)] {
+		if recent.entry.Equal(e) {
+			return now.Sub(recent.pushedAt) <= r.window
+		}
+	}
+	return false
+}
+
+// add remembers the entries and forgets the ones pushed before the window.
+func (r *recentEntries) add(entries []logproto.Entry, now time.Time) {
+	if now.Sub(r.lastPrune) > r.window {
+		for h, recents := range r.pushed {
+			kept := recents[:0]
+			for _, recent := range recents {
+				if now.Sub(recent.pushedAt) <= r.window {
+					kept = append(kept, recent)
+				}
+			}
+			if len(kept) == 0 {
+				delete(r.pushed, h)
+				continue
+			}
+			r.pushed[h] = kept
+		}
+		r.lastPrune = now
+	}
+	for i := range entries {
+		r.addEntry(&entries[i], now)
+	}
+}
+
+func (r *recentEntries) addEntry(e *logproto.Entry, now time.Time) {
+	h := entryHash(e)
+	recents := r.pushed[h]
+	for i := range recents {
+		if recents[i].entry.Equal(e) {
+			recents[i].pushedAt = now
+			return
+		}
+	}
+	r.pushed[h] = append(recents, recentEntry{entry: *e, pushedAt: now})
+}
+
+var entryHashSep = []byte{'\xff'}
+
+func entryHash(e *logproto.Entry) uint64 {
+	var ts [8]byte
+	binary.LittleEndian.PutUint64(ts[:], uint64(e.Timestamp.UnixNano()))
+	h := xxhash.New()
+	_, _ = h.Write(ts[:])
+	_, _ = h.WriteString(e.Line)
+	for _, l := range e.StructuredMetadata {
+		_, _ = h.Write(entryHashSep)
+		_, _ = h.WriteString(l.Name)
+		_, _ = h.Write(entryHashSep)
+		_, _ = h.WriteString(l.Value)
+	}
+	return h.Sum64()
+}
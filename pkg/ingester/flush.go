@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/dskit/tenant"
 
 	"github.com/grafana/loki/pkg/chunkenc"
+	"github.com/grafana/loki/pkg/logql/syntax"
 	"github.com/grafana/loki/pkg/storage/chunk"
 	"github.com/grafana/loki/pkg/util"
 	util_log "github.com/grafana/loki/pkg/util/log"
@@ -30,11 +31,12 @@ const (
 	nameLabel = "__name__"
 	logsValue = "logs"
 
-	flushReasonIdle   = "idle"
-	flushReasonMaxAge = "max_age"
-	flushReasonForced = "forced"
-	flushReasonFull   = "full"
-	flushReasonSynced = "synced"
+	flushReasonIdle    = "idle"
+	flushReasonMaxAge  = "max_age"
+	flushReasonForced  = "forced"
+	flushReasonFull    = "full"
+	flushReasonSynced  = "synced"
+	flushReasonEvicted = "evicted"
 )
 
 // Note: this is called both during the WAL replay (zero or more times)
@@ -65,26 +67,67 @@ func (i *Ingester) flush(mayRemoveStreams bool) {
 	level.Debug(util_log.Logger).Log("msg", "flush queues have drained")
 }
 
-// FlushHandler triggers a flush of all in memory chunks.  Mainly used for
-// local testing.
-func (i *Ingester) FlushHandler(w http.ResponseWriter, _ *http.Request) {
-	i.sweepUsers(true, true)
+// FlushHandler triggers a flush of the in memory chunks of the tenants of the
+// request, which is authenticated by the HTTP auth middleware. The flush can be
+// limited to the streams of one of these tenants with the tenant parameter and
+// to the streams matching the stream selector of the match parameter, e.g. when
+// chasing incidents.
+func (i *Ingester) FlushHandler(w http.ResponseWriter, r *http.Request) {
+	tenantIDs, err := tenant.TenantIDs(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if requested := r.FormValue("tenant"); requested != "" {
+		if !util.StringsContain(tenantIDs, requested) {
+			http.Error(w, fmt.Sprintf("tenant %q is not a tenant of the request", requested), http.StatusForbidden)
+			return
+		}
+		tenantIDs = []string{requested}
+	}
+	var matchers []*labels.Matcher
+	if match := r.FormValue("match"); match != "" {
+		if matchers, err = syntax.ParseMatchers(match); err != nil {
+			http.Error(w, fmt.Sprintf("invalid match parameter: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	for _, tenantID := range tenantIDs {
+		if instance, ok := i.getInstanceByID(tenantID); ok {
+			i.sweepInstance(instance, true, true, matchers...)
+		}
+	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// flushReasonPriority orders the flushes by reason: the full chunks are
+// flushed before the chunks reaching their max age, and the idle ones last.
+var flushReasonPriority = map[string]int64{
+	flushReasonIdle:    1,
+	flushReasonMaxAge:  2,
+	flushReasonFull:    3,
+	flushReasonSynced:  3,
+	flushReasonEvicted: 3,
+	flushReasonForced:  4,
+}
+
 type flushOp struct {
 	from      model.Time
 	userID    string
 	fp        model.Fingerprint
 	immediate bool
+	reason    string
 }
 
 func (o *flushOp) Key() string {
 	return fmt.Sprintf("%s-%s-%v", o.userID, o.fp, o.immediate)
 }
 
+// Priority orders the flushes by reason first, then the oldest first.
+// The reason is in the high bits as timestamps in milliseconds need less than 48 bits.
 func (o *flushOp) Priority() int64 {
-	return -int64(o.from)
+	return flushReasonPriority[o.reason]<<48 - int64(o.from)
 }
 
 // sweepUsers periodically schedules series for flushing and garbage collects users with no series
@@ -96,8 +139,13 @@ func (i *Ingester) sweepUsers(immediate, mayRemoveStreams bool) {
 	}
 }
 
-func (i *Ingester) sweepInstance(instance *instance, immediate, mayRemoveStreams bool) {
+// sweepInstance schedules the streams of the instance for flushing, only the
+// streams matching the matchers if any.
+func (i *Ingester) sweepInstance(instance *instance, immediate, mayRemoveStreams bool, matchers ...*labels.Matcher) {
 	_ = instance.streams.ForEach(func(s *stream) (bool, error) {
+		if !isMatching(s.labels, matchers) {
+			return true, nil
+		}
 		i.sweepStream(instance, s, immediate)
 		i.removeFlushedChunks(instance, s, mayRemoveStreams)
 		return true, nil
@@ -112,17 +160,33 @@ func (i *Ingester) sweepStream(instance *instance, stream *stream, immediate boo
 	}
 
 	lastChunk := stream.chunks[len(stream.chunks)-1]
-	shouldFlush, _ := i.shouldFlushChunk(&lastChunk)
+	shouldFlush, reason := i.shouldFlushChunk(stream, &lastChunk)
 	if len(stream.chunks) == 1 && !immediate && !shouldFlush {
 		return
 	}
+	switch {
+	case immediate:
+		reason = flushReasonForced
+	case len(stream.chunks) > 1:
+		// All chunks but the last one were closed when they were full.
+		reason = flushReasonFull
+	}
 
 	flushQueueIndex := int(uint64(stream.fp) % uint64(i.cfg.ConcurrentFlushes))
+	flushQueue := i.flushQueues[flushQueueIndex]
+	// The periodic flushes are skipped when the queue is full, they are
+	// scheduled again by the next sweep.
+	if !immediate && i.cfg.FlushQueueLength > 0 && flushQueue.Length() >= i.cfg.FlushQueueLength {
+		i.metrics.flushQueueDropped.WithLabelValues(reason).Inc()
+		return
+	}
 	firstTime, _ := stream.chunks[0].chunk.Bounds()
-	i.flushQueues[flushQueueIndex].Enqueue(&flushOp{
+	if flushQueue.Enqueue(&flushOp{
 		model.TimeFromUnixNano(firstTime.UnixNano()), instance.instanceID,
-		stream.fp, immediate,
-	})
+		stream.fp, immediate, reason,
+	}) {
+		i.metrics.flushQueueEnqueued.WithLabelValues(reason).Inc()
+	}
 }
 
 func (i *Ingester) flushLoop(j int) {
@@ -191,7 +255,7 @@ func (i *Ingester) collectChunksToFlush(instance *instance, fp model.Fingerprint
 
 	var result []*chunkDesc
 	for j := range stream.chunks {
-		shouldFlush, reason := i.shouldFlushChunk(&stream.chunks[j])
+		shouldFlush, reason := i.shouldFlushChunk(stream, &stream.chunks[j])
 		if immediate || shouldFlush {
 			// Ensure no more writes happen to this chunk.
 			if !stream.chunks[j].closed {
@@ -211,7 +275,7 @@ func (i *Ingester) collectChunksToFlush(instance *instance, fp model.Fingerprint
 	return result, stream.labels, &stream.chunkMtx
 }
 
-func (i *Ingester) shouldFlushChunk(chunk *chunkDesc) (bool, string) {
+func (i *Ingester) shouldFlushChunk(stream *stream, chunk *chunkDesc) (bool, string) {
 	// Append should close the chunk when the a new one is added.
 	if chunk.closed {
 		if chunk.synced {
@@ -220,11 +284,15 @@ func (i *Ingester) shouldFlushChunk(chunk *chunkDesc) (bool, string) {
 		return true, flushReasonFull
 	}
 
-	if time.Since(chunk.lastUpdated) > i.cfg.MaxChunkIdle {
+	if stream.evicted {
+		return true, flushReasonEvicted
+	}
+
+	if time.Since(chunk.lastUpdated) > stream.chunkIdlePeriod {
 		return true, flushReasonIdle
 	}
 
-	if from, to := chunk.chunk.Bounds(); to.Sub(from) > i.cfg.MaxChunkAge {
+	if from, to := chunk.chunk.Bounds(); to.Sub(from) > stream.maxChunkAge {
 		return true, flushReasonMaxAge
 	}
 
@@ -286,12 +354,25 @@ func (i *Ingester) flushChunks(ctx context.Context, fp model.Fingerprint, labelP
 	sizePerTenant := i.metrics.chunkSizePerTenant.WithLabelValues(userID)
 	countPerTenant := i.metrics.chunksPerTenant.WithLabelValues(userID)
 
+	// Chunks beyond the enforced retention of the stream would be deleted by
+	// the compactor, so they are discarded instead of being flushed.
+	var expiry model.Time
+	if period := i.limiter.EnforcedRetentionPeriod(userID, labelPairs); period > 0 {
+		expiry = model.Now().Add(-period)
+	}
+
 	for j, c := range cs {
 		if err := i.closeChunk(c, chunkMtx); err != nil {
 			return fmt.Errorf("chunk close for flushing: %w", err)
 		}
 
 		firstTime, lastTime := util.RoundToMilliseconds(c.chunk.Bounds())
+		if lastTime.Before(expiry) {
+			i.metrics.chunksExpired.WithLabelValues(userID).Inc()
+			i.markChunkAsFlushed(cs[j], chunkMtx)
+			continue
+		}
+
 		ch := chunk.NewChunk(
 			userID, fp, metric,
 			chunkenc.NewFacade(c.chunk, i.cfg.BlockSize, i.cfg.TargetChunkSize),
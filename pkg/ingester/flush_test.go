@@ -2,6 +2,8 @@ package ingester
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"sort"
 	"sync"
@@ -189,6 +191,97 @@ func TestFlushingCollidingLabels(t *testing.T) {
 	}
 }
 
+func TestFlushExpiredChunks(t *testing.T) {
+	cfg := defaultIngesterTestConfig(t)
+	store := &testStore{
+		chunks: map[string][]chunk.Chunk{},
+	}
+	l := defaultLimitsTestConfig()
+	l.EnforceRetention = true
+	l.RetentionPeriod = model.Duration(time.Hour)
+	l.StreamRetention = []validation.StreamRetention{
+		{Period: model.Duration(24 * time.Hour), Matchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "app", "long")}},
+	}
+	limits, err := validation.NewOverrides(l, nil)
+	require.NoError(t, err)
+
+	ing, err := New(cfg, client.Config{}, store, limits, runtime.DefaultTenantConfigs(), nil, writefailures.Cfg{})
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+
+	const userID = "testUser"
+	ctx := user.InjectOrgID(context.Background(), userID)
+	_, err = ing.Push(ctx, &logproto.PushRequest{Streams: []logproto.Stream{
+		{Labels: `{app="short"}`, Entries: entries(5, time.Now().Add(-2*time.Hour))},
+		{Labels: `{app="long"}`, Entries: entries(5, time.Now().Add(-2*time.Hour))},
+	}})
+	require.NoError(t, err)
+
+	// force flush
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), ing))
+
+	chunks := store.getChunksForUser(userID)
+	require.Len(t, chunks, 1)
+	require.Equal(t, "long", chunks[0].Metric.Get("app"))
+}
+
+func TestFlushHandlerTargeted(t *testing.T) {
+	cfg := defaultIngesterTestConfig(t)
+	store, ing := newTestStore(t, cfg, nil)
+	defer services.StopAndAwaitTerminated(context.Background(), ing) //nolint:errcheck
+
+	for _, userID := range []string{"a", "b"} {
+		_, err := ing.Push(user.InjectOrgID(context.Background(), userID), &logproto.PushRequest{Streams: []logproto.Stream{
+			{Labels: `{app="x"}`, Entries: entries(5, time.Unix(0, 0))},
+			{Labels: `{app="y"}`, Entries: entries(5, time.Unix(0, 0))},
+		}})
+		require.NoError(t, err)
+	}
+
+	flush := func(target, orgID string) int {
+		req := httptest.NewRequest(http.MethodPost, target, nil)
+		if orgID != "" {
+			req = req.WithContext(user.InjectOrgID(req.Context(), orgID))
+		}
+		w := httptest.NewRecorder()
+		ing.FlushHandler(w, req)
+		return w.Code
+	}
+
+	// the flush is limited to the tenant of the tenant parameter and the streams of the match parameter
+	require.Equal(t, http.StatusNoContent, flush(`/flush?tenant=a&match={app="x"}`, "a"))
+	require.Eventually(t, func() bool { return len(store.getChunksForUser("a")) == 1 }, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, "x", store.getChunksForUser("a")[0].Metric.Get("app"))
+	require.Empty(t, store.getChunksForUser("b"))
+
+	// the tenant parameter must be one of the tenants of the request
+	require.Equal(t, http.StatusForbidden, flush(`/flush?tenant=b`, "a"))
+	require.Equal(t, http.StatusBadRequest, flush(`/flush`, ""))
+	require.Equal(t, http.StatusBadRequest, flush(`/flush`, ".."))
+	require.Equal(t, http.StatusBadRequest, flush(`/flush?match={app=`, "a"))
+
+	// without the tenant parameter all the tenants of the request are flushed
+	require.Equal(t, http.StatusNoContent, flush(`/flush`, "b"))
+	require.Eventually(t, func() bool { return len(store.getChunksForUser("b")) == 2 }, 5*time.Second, 10*time.Millisecond)
+	require.Len(t, store.getChunksForUser("a"), 1)
+}
+
+func TestFlushOpPriority(t *testing.T) {
+	ops := []*flushOp{
+		{from: 1, reason: flushReasonIdle},
+		{from: 3, reason: flushReasonFull},
+		{from: 0, reason: flushReasonMaxAge},
+		{from: 2, reason: flushReasonFull},
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i].Priority() > ops[j].Priority() })
+	require.Equal(t, []*flushOp{
+		{from: 2, reason: flushReasonFull},
+		{from: 3, reason: flushReasonFull},
+		{from: 0, reason: flushReasonMaxAge},
+		{from: 1, reason: flushReasonIdle},
+	}, ops)
+}
+
 func TestFlushMaxAge(t *testing.T) {
 	cfg := defaultIngesterTestConfig(t)
 	cfg.FlushCheckPeriod = time.Millisecond * 100
@@ -0,0 +1,365 @@
+package ingester
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/ring"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/tsdb/chunks"
+	tsdb_record "github.com/prometheus/prometheus/tsdb/record"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/loki/pkg/chunkenc"
+	"github.com/grafana/loki/pkg/ingester/wal"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/logql/log"
+	lokiutil "github.com/grafana/loki/pkg/util"
+	util_log "github.com/grafana/loki/pkg/util/log"
+)
+
+var handedOverChunks = promauto.NewCounter(prometheus.CounterOpts{
+	Namespace: "loki",
+	Name:      "ingester_handed_over_chunks_received_total",
+	Help:      "The total number of chunks handed over to this ingester by leaving ingesters.",
+})
+
+// HandoverChunks receives the chunks of the streams handed over by a leaving
+// ingester. The entries of the chunks are appended to the streams of this
+// ingester, so they are merged with the entries it received in the meantime
+// and written to its WAL. The entries were already accepted by the leaving
+// ingester, so they bypass the limits of the pushes. Entries which still can't
+// be appended fail the handover, so the leaving ingester flushes its streams
+// instead of discarding them.
+func (i *Ingester) HandoverChunks(stream logproto.Ingester_HandoverChunksServer) error {
+	logger := util_log.WithContext(stream.Context(), util_log.Logger)
+	if state := i.lifecycler.GetState(); state != ring.ACTIVE {
+		return fmt.Errorf("cannot receive handed over streams in %v state", state)
+	}
+
+	fromIngesterID := ""
+	seriesReceived := 0
+
+	for {
+		chunkSet, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		if i.readonly {
+			return ErrReadOnly
+		}
+
+		if fromIngesterID == "" {
+			fromIngesterID = chunkSet.FromIngesterId
+			level.Info(logger).Log("msg", "processing HandoverChunks request", "from_ingester", fromIngesterID)
+		}
+
+		lbls := make(labels.Labels, 0, len(chunkSet.Labels))
+		for _, lbl := range chunkSet.Labels {
+			lbls = append(lbls, labels.Label{Name: lbl.Name, Value: lbl.Value})
+		}
+
+		instance, err := i.GetOrCreateInstance(chunkSet.UserId)
+		if err != nil {
+			return err
+		}
+		userCtx := user.InjectOrgID(stream.Context(), chunkSet.UserId)
+		for _, chunk := range chunkSet.Chunks {
+			entries, err := chunkEntries(userCtx, lbls, chunk)
+			if err != nil {
+				return err
+			}
+			if err := instance.pushHandedOver(userCtx, lbls, entries); err != nil {
+				level.Error(logger).Log("msg", "failed to append handed over entries", "from_ingester", fromIngesterID, "user", chunkSet.UserId, "err", err)
+				return err
+			}
+		}
+
+		seriesReceived++
+		handedOverChunks.Add(float64(len(chunkSet.Chunks)))
+	}
+
+	if err := stream.SendAndClose(&logproto.TransferChunksResponse{}); err != nil {
+		level.Error(logger).Log("msg", "Error closing HandoverChunks stream", "from_ingester", fromIngesterID, "err", err)
+		return err
+	}
+	level.Info(logger).Log("msg", "Successfully received handed over chunks", "from_ingester", fromIngesterID, "series_received", seriesReceived)
+	return nil
+}
+
+// pushHandedOver appends the entries handed over by a leaving ingester to the
+// stream with the given labels, which is created even if the tenant reached
+// its streams limit.
+func (i *instance) pushHandedOver(ctx context.Context, ls labels.Labels, entries []logproto.Entry) error {
+	record := recordPool.GetRecord()
+	record.UserID = i.instanceID
+	defer recordPool.PutRecord(record)
+
+	fp := i.getHashForLabels(ls)
+	s, _, _ := i.streams.LoadOrStoreNewByFP(fp,
+		func() (*stream, error) {
+			s := i.createStreamByFP(ls, fp)
+			record.Series = append(record.Series, tsdb_record.RefSeries{
+				Ref:    chunks.HeadSeriesRef(fp),
+				Labels: s.labels,
+			})
+			s.chunkMtx.Lock()
+			return s, nil
+		},
+		func(s *stream) error {
+			s.chunkMtx.Lock()
+			return nil
+		},
+	)
+
+	err := s.appendHandedOver(ctx, entries, record)
+	if s.evicted {
+		s.evicted = false
+		i.evictedStreams.Dec()
+	}
+	s.chunkMtx.Unlock()
+	i.markPushed(s)
+
+	if !record.IsEmpty() {
+		if walErr := i.wal.Log(record); walErr != nil {
+			return walErr
+		}
+	}
+	return err
+}
+
+// appendHandedOver appends the entries handed over by a leaving ingester
+// without the rate limit, out-of-order window and duplicates checks of the
+// pushes. It returns an error if some entries can't be appended, which only
+// happens to entries out of order of a stream without unordered writes.
+// chunkMtx must be held.
+func (s *stream) appendHandedOver(ctx context.Context, entries []logproto.Entry, record *wal.Record) error {
+	prevNumChunks := len(s.chunks)
+	if prevNumChunks == 0 {
+		s.chunks = append(s.chunks, chunkDesc{
+			chunk: s.NewChunk(),
+		})
+		s.metrics.chunksCreatedTotal.Inc()
+		s.metrics.chunkCreatedStats.Inc(1)
+	}
+
+	_, storedEntries, invalid := s.storeEntries(ctx, entries)
+	s.recordAndSendToTailers(record, storedEntries)
+
+	if len(s.chunks) != prevNumChunks {
+		s.metrics.memoryChunks.Add(float64(len(s.chunks) - prevNumChunks))
+	}
+	return errorForFailedEntries(s, invalid, len(entries))
+}
+
+// chunkEntries returns all entries of the chunk in ascending timestamp order.
+func chunkEntries(ctx context.Context, lbls labels.Labels, chunk *logproto.Chunk) ([]logproto.Entry, error) {
+	c, err := chunkenc.NewByteChunk(chunk.Data, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	// The lower bound of the chunk is not used, as it skips entries at the Unix epoch.
+	_, through := c.Bounds()
+	it, err := c.Iterator(ctx, time.Unix(0, 0), through.Add(time.Nanosecond), logproto.FORWARD, log.NewNoopPipeline().ForStream(lbls))
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	var entries []logproto.Entry
+	for it.Next() {
+		entries = append(entries, it.Entry())
+	}
+	return entries, it.Error()
+}
+
+// handoverOut hands over the streams of the ingester to the ingesters which
+// join the replication sets of their tokens once it left the ring. The
+// in-memory chunks are then discarded rather than flushed, which avoids
+// writing many small chunks on scale-down. The streams no ingester joins the
+// replication set of, e.g. because this ingester was not part of it, are
+// flushed instead, as handing them over would not add a replica.
+func (i *Ingester) handoverOut(ctx context.Context) error {
+	logger := util_log.WithContext(ctx, util_log.Logger)
+	targets, err := i.findHandoverTargets(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot find ingesters to hand over streams to: %v", err)
+	}
+
+	ctx = user.InjectOrgID(ctx, "-1")
+	streams := map[string]logproto.Ingester_HandoverChunksClient{}
+	var closers []io.Closer
+	defer func() {
+		for _, c := range closers {
+			lokiutil.LogErrorWithContext(ctx, "closing client", c.Close)
+		}
+	}()
+	openStream := func(addr string) (logproto.Ingester_HandoverChunksClient, error) {
+		if s, ok := streams[addr]; ok {
+			return s, nil
+		}
+		level.Info(logger).Log("msg", "handing over streams", "to_ingester", addr)
+		c, err := i.cfg.ingesterClientFactory(i.clientConfig, addr)
+		if err != nil {
+			return nil, err
+		}
+		if c, ok := c.(io.Closer); ok {
+			closers = append(closers, c)
+		}
+		s, err := c.(logproto.IngesterClient).HandoverChunks(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "HandoverChunks")
+		}
+		streams[addr] = s
+		return s, nil
+	}
+
+	toFlush := map[string][]*stream{}
+	for instanceID, inst := range i.instances {
+		err := inst.streams.ForEach(func(istream *stream) (bool, error) {
+			addr := targets.target(lokiutil.TokenFor(instanceID, istream.labelsString))
+			if addr == "" {
+				toFlush[instanceID] = append(toFlush[instanceID], istream)
+				return true, nil
+			}
+			s, err := openStream(addr)
+			if err != nil {
+				return false, err
+			}
+			if err := i.sendStreamChunks(instanceID, istream, s.Send); err != nil {
+				level.Error(logger).Log("msg", "failed handing over stream's chunks to ingester", "to_ingester", addr, "err", err)
+				return false, err
+			}
+			return true, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// The chunks are only discarded once all the targets acknowledged them.
+	for addr, s := range streams {
+		if _, err := s.CloseAndRecv(); err != nil {
+			return errors.Wrapf(err, "CloseAndRecv %s", addr)
+		}
+	}
+
+	for instanceID, toFlush := range toFlush {
+		for _, s := range toFlush {
+			if err := i.flushUserSeries(instanceID, s.fp, true); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, flushQueue := range i.flushQueues {
+		flushQueue.DiscardAndClose()
+	}
+	i.flushQueuesDone.Wait()
+
+	level.Info(logger).Log("msg", "successfully handed over streams", "to_ingesters", len(streams), "flushed_users", len(toFlush))
+	return nil
+}
+
+// handoverTargets maps the tokens of this ingester and of the remaining
+// ingesters to their address.
+type handoverTargets struct {
+	tokens []uint32
+	addrs  []string
+
+	self              string
+	replicationFactor int
+}
+
+func (t *handoverTargets) Len() int           { return len(t.tokens) }
+func (t *handoverTargets) Less(i, j int) bool { return t.tokens[i] < t.tokens[j] }
+func (t *handoverTargets) Swap(i, j int) {
+	t.tokens[i], t.tokens[j] = t.tokens[j], t.tokens[i]
+	t.addrs[i], t.addrs[j] = t.addrs[j], t.addrs[i]
+}
+
+// target returns the address of the ingester joining the replication set of
+// the token once this ingester left the ring, or an empty string if this
+// ingester is not part of the replication set or no ingester joins it. The
+// replication set consists of the ingesters of the next tokens in the ring,
+// so the joining ingester is the one following the other replicas.
+func (t *handoverTargets) target(token uint32) string {
+	idx := sort.Search(len(t.tokens), func(n int) bool { return t.tokens[n] >= token })
+	replicas := make([]string, 0, t.replicationFactor+1)
+	for n := 0; n < len(t.tokens) && len(replicas) <= t.replicationFactor; n++ {
+		addr := t.addrs[(idx+n)%len(t.tokens)]
+		if !contains(replicas, addr) {
+			replicas = append(replicas, addr)
+		}
+	}
+	// Once this ingester left the replication set of the first ingesters,
+	// the next ingester joins it.
+	if len(replicas) <= t.replicationFactor || !contains(replicas[:t.replicationFactor], t.self) {
+		return ""
+	}
+	return replicas[t.replicationFactor]
+}
+
+func contains(addrs []string, addr string) bool {
+	for _, a := range addrs {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// findHandoverTargets builds the ring of this ingester and of the other ACTIVE
+// ingesters. With zone awareness, only the ingesters of the same zone are
+// used, as each zone holds a single replica of the streams.
+func (i *Ingester) findHandoverTargets(ctx context.Context) (*handoverTargets, error) {
+	v, err := i.lifecycler.KVStore.Get(ctx, RingKey)
+	if err != nil {
+		return nil, err
+	}
+	desc, ok := v.(*ring.Desc)
+	if !ok || desc == nil {
+		return nil, fmt.Errorf("ring not found, got %T", v)
+	}
+
+	targets := &handoverTargets{
+		self:              i.lifecycler.Addr,
+		replicationFactor: i.cfg.LifecyclerConfig.RingConfig.ReplicationFactor,
+	}
+	if i.cfg.LifecyclerConfig.RingConfig.ZoneAwarenessEnabled {
+		targets.replicationFactor = 1
+	}
+	var others int
+	for id, ing := range desc.Ingesters {
+		if id != i.lifecycler.ID && ing.State != ring.ACTIVE {
+			continue
+		}
+		if i.cfg.LifecyclerConfig.RingConfig.ZoneAwarenessEnabled && ing.Zone != i.lifecycler.Zone {
+			continue
+		}
+		addr := ing.Addr
+		if id == i.lifecycler.ID {
+			addr = targets.self
+		} else {
+			others++
+		}
+		for _, token := range ing.Tokens {
+			targets.tokens = append(targets.tokens, token)
+			targets.addrs = append(targets.addrs, addr)
+		}
+	}
+	if others == 0 {
+		return nil, fmt.Errorf("no active ingesters")
+	}
+	sort.Sort(targets)
+	return targets, nil
+}
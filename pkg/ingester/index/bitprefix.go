@@ -134,6 +134,7 @@ func (ii *BitPrefixInvertedIndex) Lookup(matchers []*labels.Matcher, shard *astm
 			result = append(result, fps...)
 		}
 	} else {
+		matchers = sortMatchers(matchers)
 		for i := range shards {
 			fps := shards[i].lookup(matchers)
 			result = append(result, fps...)
@@ -158,8 +159,8 @@ func (ii *BitPrefixInvertedIndex) Lookup(matchers []*labels.Matcher, shard *astm
 	return result, nil
 }
 
-// LabelNames returns all label names.
-func (ii *BitPrefixInvertedIndex) LabelNames(shard *astmapper.ShardAnnotation) ([]string, error) {
+// LabelNames returns all label names, or the label names of the series matching the matchers.
+func (ii *BitPrefixInvertedIndex) LabelNames(shard *astmapper.ShardAnnotation, matchers ...*labels.Matcher) ([]string, error) {
 	if err := ii.validateShard(shard); err != nil {
 		return nil, err
 	}
@@ -167,6 +168,16 @@ func (ii *BitPrefixInvertedIndex) LabelNames(shard *astmapper.ShardAnnotation) (
 	var extractor func(unlockIndex) []string
 	shards, filter := ii.getShards(shard)
 
+	if len(matchers) > 0 {
+		matchers = sortMatchers(matchers)
+		keep := ii.keepFunc(shard, filter)
+		results := make([][]string, 0, len(shards))
+		for i := range shards {
+			results = append(results, shards[i].matchingLabelNames(matchers, keep))
+		}
+		return mergeStringSlices(results), nil
+	}
+
 	// If we need to check shard inclusion, we have to do it the expensive way :(
 	// Therefore it's more performant to request shard factors lower or equal to the
 	// inverted index factor
@@ -200,14 +211,24 @@ func (ii *BitPrefixInvertedIndex) LabelNames(shard *astmapper.ShardAnnotation) (
 	return mergeStringSlices(results), nil
 }
 
-// LabelValues returns the values for the given label.
-func (ii *BitPrefixInvertedIndex) LabelValues(name string, shard *astmapper.ShardAnnotation) ([]string, error) {
+// LabelValues returns the values for the given label, or the values of the series matching the matchers.
+func (ii *BitPrefixInvertedIndex) LabelValues(name string, shard *astmapper.ShardAnnotation, matchers ...*labels.Matcher) ([]string, error) {
 	if err := ii.validateShard(shard); err != nil {
 		return nil, err
 	}
 
 	var extractor func(indexEntry) []string
 	shards, filter := ii.getShards(shard)
+
+	if len(matchers) > 0 {
+		matchers = sortMatchers(matchers)
+		keep := ii.keepFunc(shard, filter)
+		results := make([][]string, 0, len(shards))
+		for i := range shards {
+			results = append(results, shards[i].matchingLabelValues(name, matchers, keep))
+		}
+		return mergeStringSlices(results), nil
+	}
 	if filter {
 		s := shard.TSDB()
 
@@ -236,6 +257,15 @@ func (ii *BitPrefixInvertedIndex) LabelValues(name string, shard *astmapper.Shar
 	return mergeStringSlices(results), nil
 }
 
+// keepFunc returns the check of the fingerprints belonging to the requested shard,
+// or nil if all the fingerprints of the selected index shards belong to it.
+func (ii *BitPrefixInvertedIndex) keepFunc(shard *astmapper.ShardAnnotation, filter bool) func(model.Fingerprint) bool {
+	if !filter {
+		return nil
+	}
+	return shard.TSDB().Match
+}
+
 // Delete a fingerprint with the given label pairs.
 func (ii *BitPrefixInvertedIndex) Delete(labels labels.Labels, fp model.Fingerprint) {
 	localShard := index.NewShard(0, uint32(len(ii.shards)))
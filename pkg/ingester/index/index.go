@@ -29,8 +29,8 @@ var ErrInvalidShardQuery = errors.New("incompatible index shard query")
 type Interface interface {
 	Add(labels []logproto.LabelAdapter, fp model.Fingerprint) labels.Labels
 	Lookup(matchers []*labels.Matcher, shard *astmapper.ShardAnnotation) ([]model.Fingerprint, error)
-	LabelNames(shard *astmapper.ShardAnnotation) ([]string, error)
-	LabelValues(name string, shard *astmapper.ShardAnnotation) ([]string, error)
+	LabelNames(shard *astmapper.ShardAnnotation, matchers ...*labels.Matcher) ([]string, error)
+	LabelValues(name string, shard *astmapper.ShardAnnotation, matchers ...*labels.Matcher) ([]string, error)
 	Delete(labels labels.Labels, fp model.Fingerprint)
 }
 
@@ -167,6 +167,7 @@ func (ii *InvertedIndex) Lookup(matchers []*labels.Matcher, shard *astmapper.Sha
 		return result, nil
 	}
 
+	matchers = sortMatchers(matchers)
 	for i := range shards {
 		fps := shards[i].lookup(matchers)
 		result = append(result, fps...)
@@ -174,31 +175,43 @@ func (ii *InvertedIndex) Lookup(matchers []*labels.Matcher, shard *astmapper.Sha
 	return result, nil
 }
 
-// LabelNames returns all label names.
-func (ii *InvertedIndex) LabelNames(shard *astmapper.ShardAnnotation) ([]string, error) {
+// LabelNames returns all label names, or the label names of the series matching the matchers.
+func (ii *InvertedIndex) LabelNames(shard *astmapper.ShardAnnotation, matchers ...*labels.Matcher) ([]string, error) {
 	if err := ii.validateShard(shard); err != nil {
 		return nil, err
 	}
 	shards := ii.getShards(shard)
 	results := make([][]string, 0, len(shards))
+	matchers = sortMatchers(matchers)
 	for i := range shards {
-		shardResult := shards[i].labelNames(nil)
+		var shardResult []string
+		if len(matchers) == 0 {
+			shardResult = shards[i].labelNames(nil)
+		} else {
+			shardResult = shards[i].matchingLabelNames(matchers, nil)
+		}
 		results = append(results, shardResult)
 	}
 
 	return mergeStringSlices(results), nil
 }
 
-// LabelValues returns the values for the given label.
-func (ii *InvertedIndex) LabelValues(name string, shard *astmapper.ShardAnnotation) ([]string, error) {
+// LabelValues returns the values for the given label, or the values of the series matching the matchers.
+func (ii *InvertedIndex) LabelValues(name string, shard *astmapper.ShardAnnotation, matchers ...*labels.Matcher) ([]string, error) {
 	if err := ii.validateShard(shard); err != nil {
 		return nil, err
 	}
 	shards := ii.getShards(shard)
 	results := make([][]string, 0, len(shards))
+	matchers = sortMatchers(matchers)
 
 	for i := range shards {
-		shardResult := shards[i].labelValues(name, nil)
+		var shardResult []string
+		if len(matchers) == 0 {
+			shardResult = shards[i].labelValues(name, nil)
+		} else {
+			shardResult = shards[i].matchingLabelValues(name, matchers, nil)
+		}
 		results = append(results, shardResult)
 	}
 
@@ -279,6 +292,7 @@ func (shard *indexShard) add(metric []logproto.LabelAdapter, fp model.Fingerprin
 	return internedLabels
 }
 
+// lookup expects the matchers to be ordered by sortMatchers.
 func (shard *indexShard) lookup(matchers []*labels.Matcher) []model.Fingerprint {
 	// index slice values must only be accessed under lock, so all
 	// code paths must take a copy before returning
@@ -294,40 +308,170 @@ func (shard *indexShard) lookup(matchers []*labels.Matcher) []model.Fingerprint
 		if !ok {
 			return nil
 		}
-		var toIntersect model.Fingerprints
-		if matcher.Type == labels.MatchEqual {
-			fps := values.fps[matcher.Value]
-			toIntersect = append(toIntersect, fps.fps...) // deliberate copy
-		} else if matcher.Type == labels.MatchRegexp && len(series.FindSetMatches(matcher.Value)) > 0 {
-			// The lookup is of the form `=~"a|b|c|d"`
-			set := series.FindSetMatches(matcher.Value)
-			for _, value := range set {
-				toIntersect = append(toIntersect, values.fps[value].fps...)
+		result = intersect(result, matchingValueFPs(values, matcher))
+		if len(result) == 0 {
+			return nil
+		}
+	}
+
+	return result
+}
+
+// matchingValueFPs returns a sorted copy of the fingerprints of the label values matching the matcher.
+func matchingValueFPs(values indexEntry, matcher *labels.Matcher) model.Fingerprints {
+	var result model.Fingerprints
+	if matcher.Type == labels.MatchEqual {
+		fps := values.fps[matcher.Value]
+		result = append(result, fps.fps...) // deliberate copy
+	} else if matcher.Type == labels.MatchRegexp && len(series.FindSetMatches(matcher.Value)) > 0 {
+		// The lookup is of the form `=~"a|b|c|d"`
+		set := series.FindSetMatches(matcher.Value)
+		for _, value := range set {
+			result = append(result, values.fps[value].fps...)
+		}
+		sort.Sort(result)
+	} else {
+		// accumulate the matching fingerprints (which are all distinct)
+		// then sort to maintain the invariant
+		for value, fps := range values.fps {
+			if matcher.Matches(value) {
+				result = append(result, fps.fps...)
+			}
+		}
+		sort.Sort(result)
+	}
+	return result
+}
+
+// matchingFPs returns the sorted fingerprints of the series matching all the matchers.
+// Unlike lookup, the matchers matching the empty value also select the series without the label.
+// The matchers must be ordered by sortMatchers and the shard lock must be held.
+func (shard *indexShard) matchingFPs(matchers []*labels.Matcher, keep func(model.Fingerprint) bool) []model.Fingerprint {
+	var (
+		result   []model.Fingerprint
+		narrowed bool
+	)
+	for _, matcher := range matchers {
+		values, ok := shard.idx[matcher.Name]
+		if !matcher.Matches("") {
+			if !ok {
+				return nil
 			}
-			sort.Sort(toIntersect)
+			result = intersect(result, matchingValueFPs(values, matcher))
 		} else {
-			// accumulate the matching fingerprints (which are all distinct)
-			// then sort to maintain the invariant
-			for value, fps := range values.fps {
-				if matcher.Matches(value) {
-					toIntersect = append(toIntersect, fps.fps...)
+			if !narrowed {
+				result = shard.allFPsLocked()
+				sort.Sort(model.Fingerprints(result))
+			}
+			if ok {
+				var toSubtract model.Fingerprints
+				for value, fps := range values.fps {
+					if !matcher.Matches(value) {
+						toSubtract = append(toSubtract, fps.fps...)
+					}
 				}
+				sort.Sort(toSubtract)
+				result = subtract(result, toSubtract)
 			}
-			sort.Sort(toIntersect)
 		}
-		result = intersect(result, toIntersect)
+		narrowed = true
 		if len(result) == 0 {
 			return nil
 		}
 	}
 
+	if keep != nil {
+		kept := result[:0]
+		for _, fp := range result {
+			if keep(fp) {
+				kept = append(kept, fp)
+			}
+		}
+		result = kept
+	}
 	return result
 }
 
+func (shard *indexShard) matchingLabelNames(matchers []*labels.Matcher, keep func(model.Fingerprint) bool) []string {
+	shard.mtx.RLock()
+	defer shard.mtx.RUnlock()
+
+	fps := shard.matchingFPs(matchers, keep)
+	if len(fps) == 0 {
+		return nil
+	}
+
+	var results []string
+	for name, entry := range shard.idx {
+		for _, valEntry := range entry.fps {
+			if intersects(fps, valEntry.fps) {
+				results = append(results, name)
+				break
+			}
+		}
+	}
+
+	sort.Strings(results)
+	return results
+}
+
+func (shard *indexShard) matchingLabelValues(name string, matchers []*labels.Matcher, keep func(model.Fingerprint) bool) []string {
+	shard.mtx.RLock()
+	defer shard.mtx.RUnlock()
+
+	values, ok := shard.idx[name]
+	if !ok {
+		return nil
+	}
+	fps := shard.matchingFPs(matchers, keep)
+	if len(fps) == 0 {
+		return nil
+	}
+
+	var results []string
+	for val, valEntry := range values.fps {
+		if intersects(fps, valEntry.fps) {
+			results = append(results, val)
+		}
+	}
+
+	sort.Strings(results)
+	return results
+}
+
+// sortMatchers returns the matchers in the order they narrow down the intersection the cheapest:
+// the equality matchers first and the matchers matching the empty value last.
+// It is called once per query rather than for every index shard.
+func sortMatchers(matchers []*labels.Matcher) []*labels.Matcher {
+	if len(matchers) < 2 {
+		return matchers
+	}
+	sorted := make([]*labels.Matcher, len(matchers))
+	copy(sorted, matchers)
+	rank := func(m *labels.Matcher) int {
+		switch {
+		case m.Type == labels.MatchEqual && m.Value != "":
+			return 0
+		case m.Matches(""):
+			return 2
+		default:
+			return 1
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return rank(sorted[i]) < rank(sorted[j])
+	})
+	return sorted
+}
+
 func (shard *indexShard) allFPs() model.Fingerprints {
 	shard.mtx.RLock()
 	defer shard.mtx.RUnlock()
 
+	return shard.allFPsLocked()
+}
+
+func (shard *indexShard) allFPsLocked() model.Fingerprints {
 	var fps model.Fingerprints
 	for _, ie := range shard.idx {
 		for _, ive := range ie.fps {
@@ -449,6 +593,37 @@ func intersect(a, b []model.Fingerprint) []model.Fingerprint {
 	return result
 }
 
+// intersects returns whether two sorted lists of fingerprints have a fingerprint in common.
+func intersects(a, b []model.Fingerprint) bool {
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		if a[i] == b[j] {
+			return true
+		}
+		if a[i] < b[j] {
+			i++
+		} else {
+			j++
+		}
+	}
+	return false
+}
+
+// subtract the fingerprints of the sorted list b from the sorted list a.
+func subtract(a, b []model.Fingerprint) []model.Fingerprint {
+	result := []model.Fingerprint{}
+	j := 0
+	for _, fp := range a {
+		for j < len(b) && b[j] < fp {
+			j++
+		}
+		if j < len(b) && b[j] == fp {
+			continue
+		}
+		result = append(result, fp)
+	}
+	return result
+}
+
 func mergeStringSlices(ss [][]string) []string {
 	switch len(ss) {
 	case 0:
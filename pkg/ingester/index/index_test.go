@@ -178,3 +178,75 @@ func Test_ConsistentMapping(t *testing.T) {
 	}
 
 }
+
+func Test_MatchingLabelNamesAndValues(t *testing.T) {
+	series := []labels.Labels{
+		labels.FromStrings("app", "api", "env", "prod", "pod", "api-1"),
+		labels.FromStrings("app", "api", "env", "dev", "pod", "api-2"),
+		labels.FromStrings("app", "web", "env", "prod", "region", "eu"),
+		labels.FromStrings("app", "db"),
+	}
+	bitPrefix, err := NewBitPrefixWithShards(4)
+	require.NoError(t, err)
+
+	for name, ii := range map[string]Interface{
+		"inverted":   NewWithShards(16),
+		"bit prefix": bitPrefix,
+	} {
+		t.Run(name, func(t *testing.T) {
+			for _, lbs := range series {
+				ii.Add(logproto.FromLabelsToLabelAdapters(lbs), model.Fingerprint(lbs.Hash()))
+			}
+
+			for _, tc := range []struct {
+				matchers []*labels.Matcher
+				names    []string
+				values   []string
+			}{
+				{
+					matchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "app", "api")},
+					names:    []string{"app", "env", "pod"},
+					values:   []string{"dev", "prod"},
+				},
+				{
+					matchers: []*labels.Matcher{
+						labels.MustNewMatcher(labels.MatchRegexp, "app", "api|web"),
+						labels.MustNewMatcher(labels.MatchEqual, "env", "prod"),
+					},
+					names:  []string{"app", "env", "pod", "region"},
+					values: []string{"prod"},
+				},
+				{
+					// the matchers matching the empty value also select the series without the label
+					matchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchNotEqual, "env", "prod")},
+					names:    []string{"app", "env", "pod"},
+					values:   []string{"dev"},
+				},
+				{
+					matchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "app", "none")},
+					names:    []string{},
+					values:   []string{},
+				},
+			} {
+				names, err := ii.LabelNames(nil, tc.matchers...)
+				require.NoError(t, err)
+				require.ElementsMatch(t, tc.names, names, tc.matchers)
+
+				values, err := ii.LabelValues("env", nil, tc.matchers...)
+				require.NoError(t, err)
+				require.ElementsMatch(t, tc.values, values, tc.matchers)
+			}
+		})
+	}
+}
+
+func Test_SortMatchers(t *testing.T) {
+	regexp := labels.MustNewMatcher(labels.MatchRegexp, "a", "b.+")
+	notEqual := labels.MustNewMatcher(labels.MatchNotEqual, "c", "d")
+	equal := labels.MustNewMatcher(labels.MatchEqual, "e", "f")
+	matchers := []*labels.Matcher{notEqual, regexp, equal}
+
+	require.Equal(t, []*labels.Matcher{equal, regexp, notEqual}, sortMatchers(matchers))
+	// the matchers of the query are left untouched
+	require.Equal(t, []*labels.Matcher{notEqual, regexp, equal}, matchers)
+}
@@ -84,12 +84,12 @@ func (m *Multi) Lookup(t time.Time, matchers []*labels.Matcher, shard *astmapper
 	return m.indexFor(t).Lookup(matchers, shard)
 }
 
-func (m *Multi) LabelNames(t time.Time, shard *astmapper.ShardAnnotation) ([]string, error) {
-	return m.indexFor(t).LabelNames(shard)
+func (m *Multi) LabelNames(t time.Time, shard *astmapper.ShardAnnotation, matchers ...*labels.Matcher) ([]string, error) {
+	return m.indexFor(t).LabelNames(shard, matchers...)
 }
 
-func (m *Multi) LabelValues(t time.Time, name string, shard *astmapper.ShardAnnotation) ([]string, error) {
-	return m.indexFor(t).LabelValues(name, shard)
+func (m *Multi) LabelValues(t time.Time, name string, shard *astmapper.ShardAnnotation, matchers ...*labels.Matcher) ([]string, error) {
+	return m.indexFor(t).LabelValues(name, shard, matchers...)
 }
 
 // Query planning is responsible for ensuring no query spans more than one inverted index.
@@ -115,10 +115,10 @@ func (noopInvertedIndex) Lookup(_ []*labels.Matcher, _ *astmapper.ShardAnnotatio
 	return nil, nil
 }
 
-func (noopInvertedIndex) LabelNames(_ *astmapper.ShardAnnotation) ([]string, error) {
+func (noopInvertedIndex) LabelNames(_ *astmapper.ShardAnnotation, _ ...*labels.Matcher) ([]string, error) {
 	return nil, nil
 }
 
-func (noopInvertedIndex) LabelValues(_ string, _ *astmapper.ShardAnnotation) ([]string, error) {
+func (noopInvertedIndex) LabelValues(_ string, _ *astmapper.ShardAnnotation, _ ...*labels.Matcher) ([]string, error) {
 	return nil, nil
 }
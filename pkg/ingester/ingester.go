@@ -24,6 +24,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/weaveworks/common/httpgrpc"
 	"google.golang.org/grpc/health/grpc_health_v1"
 
 	"github.com/grafana/loki/pkg/analytics"
@@ -76,8 +77,11 @@ type Config struct {
 
 	// Config for transferring chunks.
 	MaxTransferRetries int `yaml:"max_transfer_retries,omitempty"`
+	// Config for handing over streams to the remaining ingesters.
+	HandoverOnScaleDown bool `yaml:"handover_on_scale_down"`
 
 	ConcurrentFlushes   int               `yaml:"concurrent_flushes"`
+	FlushQueueLength    int               `yaml:"flush_queue_length"`
 	FlushCheckPeriod    time.Duration     `yaml:"flush_check_period"`
 	FlushOpTimeout      time.Duration     `yaml:"flush_op_timeout"`
 	RetainPeriod        time.Duration     `yaml:"chunk_retain_period"`
@@ -86,6 +90,7 @@ type Config struct {
 	TargetChunkSize     int               `yaml:"chunk_target_size"`
 	ChunkEncoding       string            `yaml:"chunk_encoding"`
 	parsedEncoding      chunkenc.Encoding `yaml:"-"` // placeholder for validated encoding
+	ChunkZstdLevel      int               `yaml:"chunk_zstd_level"`
 	MaxChunkAge         time.Duration     `yaml:"max_chunk_age"`
 	AutoForgetUnhealthy bool              `yaml:"autoforget_unhealthy"`
 
@@ -109,7 +114,9 @@ type Config struct {
 
 	IndexShards int `yaml:"index_shards"`
 
-	MaxDroppedStreams int `yaml:"max_dropped_streams"`
+	MaxDroppedStreams      int           `yaml:"max_dropped_streams"`
+	TailerBufferSize       int           `yaml:"tailer_buffer_size"`
+	TailerMaxBlockedPeriod time.Duration `yaml:"tailer_max_blocked_period"`
 
 	ShutdownMarkerPath string `yaml:"shutdown_marker_path"`
 }
@@ -120,14 +127,17 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.WAL.RegisterFlags(f)
 
 	f.IntVar(&cfg.MaxTransferRetries, "ingester.max-transfer-retries", 0, "Number of times to try and transfer chunks before falling back to flushing. If set to 0 or negative value, transfers are disabled.")
+	f.BoolVar(&cfg.HandoverOnScaleDown, "ingester.handover-on-scale-down", false, "Hand over the in-memory streams to the ingesters joining the replication sets of their tokens once this ingester left the ring, instead of flushing them when shutting down with flush. The streams are flushed if the handover fails, or if no ingester joins their replication set.")
 	f.IntVar(&cfg.ConcurrentFlushes, "ingester.concurrent-flushes", 32, "How many flushes can happen concurrently from each stream.")
 	f.DurationVar(&cfg.FlushCheckPeriod, "ingester.flush-check-period", 30*time.Second, "How often should the ingester see if there are any blocks to flush.")
+	f.IntVar(&cfg.FlushQueueLength, "ingester.flush-queue-length", 0, "Maximum number of streams waiting in each flush queue. When a queue is full, the periodic flushes of further streams are skipped until the next flush check. The flushes of full chunks are queued before the flushes of chunks reaching their max age and of idle chunks, the oldest first. 0 for no limit.")
 	f.DurationVar(&cfg.FlushOpTimeout, "ingester.flush-op-timeout", 10*time.Minute, "The timeout before a flush is cancelled.")
 	f.DurationVar(&cfg.RetainPeriod, "ingester.chunks-retain-period", 0, "How long chunks should be retained in-memory after they've been flushed.")
 	f.DurationVar(&cfg.MaxChunkIdle, "ingester.chunks-idle-period", 30*time.Minute, "How long chunks should sit in-memory with no updates before being flushed if they don't hit the max block size. This means that half-empty chunks will still be flushed after a certain period as long as they receive no further activity.")
 	f.IntVar(&cfg.BlockSize, "ingester.chunks-block-size", 256*1024, "The targeted _uncompressed_ size in bytes of a chunk block When this threshold is exceeded the head block will be cut and compressed inside the chunk.")
 	f.IntVar(&cfg.TargetChunkSize, "ingester.chunk-target-size", 1572864, "A target _compressed_ size in bytes for chunks. This is a desired size not an exact size, chunks may be slightly bigger or significantly smaller if they get flushed for other reasons (e.g. chunk_idle_period). A value of 0 creates chunks with a fixed 10 blocks, a non zero value will create chunks with a variable number of blocks to meet the target size.") // 1.5 MB
 	f.StringVar(&cfg.ChunkEncoding, "ingester.chunk-encoding", chunkenc.EncGZIP.String(), fmt.Sprintf("The algorithm to use for compressing chunk. (%s)", chunkenc.SupportedEncoding()))
+	f.IntVar(&cfg.ChunkZstdLevel, "ingester.chunk-zstd-level", 3, "The compression level of chunks using the zstd encoding, from 1 (fastest) to 22 (best compression). Higher levels trade CPU for smaller chunks.")
 	f.DurationVar(&cfg.SyncPeriod, "ingester.sync-period", 0, "Parameters used to synchronize ingesters to cut chunks at the same moment. Sync period is used to roll over incoming entry to a new chunk. If chunk's utilization isn't high enough (eg. less than 50% when sync_min_utilization is set to 0.5), then this chunk rollover doesn't happen.")
 	f.Float64Var(&cfg.SyncMinUtilization, "ingester.sync-min-utilization", 0, "Minimum utilization of chunk when doing synchronization.")
 	f.IntVar(&cfg.MaxReturnedErrors, "ingester.max-ignored-stream-errors", 10, "The maximum number of errors a stream will report to the user when a push fails. 0 to make unlimited.")
@@ -136,6 +146,8 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.BoolVar(&cfg.AutoForgetUnhealthy, "ingester.autoforget-unhealthy", false, "Forget about ingesters having heartbeat timestamps older than `ring.kvstore.heartbeat_timeout`. This is equivalent to clicking on the `/ring` `forget` button in the UI: the ingester is removed from the ring. This is a useful setting when you are sure that an unhealthy node won't return. An example is when not using stateful sets or the equivalent. Use `memberlist.rejoin_interval` > 0 to handle network partition cases when using a memberlist.")
 	f.IntVar(&cfg.IndexShards, "ingester.index-shards", index.DefaultIndexShards, "Shard factor used in the ingesters for the in process reverse index. This MUST be evenly divisible by ALL schema shard factors or Loki will not start.")
 	f.IntVar(&cfg.MaxDroppedStreams, "ingester.tailer.max-dropped-streams", 10, "Maximum number of dropped streams to keep in memory during tailing.")
+	f.IntVar(&cfg.TailerBufferSize, "ingester.tailer.buffer-size", bufferSizeForTailResponse, "Number of streams buffered for each tail client. Streams are dropped when the buffer is full and reported to the client as dropped.")
+	f.DurationVar(&cfg.TailerMaxBlockedPeriod, "ingester.tailer.max-blocked-period", maxTailerBlockedPeriod, "Maximum duration a tail client may not consume its streams before it is disconnected.")
 	f.StringVar(&cfg.ShutdownMarkerPath, "ingester.shutdown-marker-path", "", "Path where the shutdown marker file is stored. If not set and common.path_prefix is set then common.path_prefix will be used.")
 }
 
@@ -146,6 +158,10 @@ func (cfg *Config) Validate() error {
 	}
 	cfg.parsedEncoding = enc
 
+	if enc == chunkenc.EncZstd && (cfg.ChunkZstdLevel < 1 || cfg.ChunkZstdLevel > 22) {
+		return fmt.Errorf("invalid zstd chunk compression level: %d, must be between 1 and 22", cfg.ChunkZstdLevel)
+	}
+
 	if err = cfg.WAL.Validate(); err != nil {
 		return err
 	}
@@ -154,10 +170,20 @@ func (cfg *Config) Validate() error {
 		return errors.New("the use of the write ahead log (WAL) is incompatible with chunk transfers. It's suggested to use the WAL. Please try setting ingester.max-transfer-retries to 0 to disable transfers")
 	}
 
+	if cfg.MaxTransferRetries > 0 && cfg.HandoverOnScaleDown {
+		return errors.New("chunk transfers and stream handovers on scale-down cannot be enabled at the same time")
+	}
+
 	if cfg.IndexShards <= 0 {
 		return fmt.Errorf("invalid ingester index shard factor: %d", cfg.IndexShards)
 	}
 
+	// Without a zone, the ingester would be the only one of its zone and
+	// could break the replication of streams across distinct zones.
+	if cfg.LifecyclerConfig.RingConfig.ZoneAwarenessEnabled && cfg.LifecyclerConfig.Zone == "" {
+		return errors.New("the ingester availability zone must be set when zone awareness is enabled")
+	}
+
 	return nil
 }
 
@@ -252,6 +278,9 @@ func New(cfg Config, clientConfig client.Config, store ChunkStore, limits Limits
 		cfg.ingesterClientFactory = client.New
 	}
 	compressionStats.Set(cfg.ChunkEncoding)
+	if cfg.parsedEncoding == chunkenc.EncZstd {
+		chunkenc.SetZstdLevel(cfg.ChunkZstdLevel)
+	}
 	targetSizeStats.Set(int64(cfg.TargetChunkSize))
 	walStats.Set("disabled")
 	if cfg.WAL.Enabled {
@@ -1237,7 +1266,17 @@ func (i *Ingester) Tail(req *logproto.TailRequest, queryServer logproto.Querier_
 	if err != nil {
 		return err
 	}
-	tailer, err := newTailer(instanceID, req.Query, queryServer, i.cfg.MaxDroppedStreams)
+	if limit := i.limiter.MaxTailersPerUser(instanceID); limit > 0 {
+		if count := instance.openTailersCount(); count >= uint32(limit) {
+			return httpgrpc.Errorf(http.StatusTooManyRequests, "max concurrent tail requests per ingester limit exceeded, count > limit (%d > %d)", count+1, limit)
+		}
+	}
+
+	tailer, err := newTailer(instanceID, req.Query, queryServer, tailerConfig{
+		maxDroppedStreams: i.cfg.MaxDroppedStreams,
+		bufferSize:        i.cfg.TailerBufferSize,
+		maxBlockedPeriod:  i.cfg.TailerMaxBlockedPeriod,
+	}, i.metrics)
 	if err != nil {
 		return err
 	}
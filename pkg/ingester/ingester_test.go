@@ -6,12 +6,15 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"sort"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/dskit/ring"
 	"github.com/grafana/dskit/services"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
@@ -126,6 +129,51 @@ func TestPrepareShutdown(t *testing.T) {
 	})
 }
 
+func TestShutdownHandler(t *testing.T) {
+	ingesterConfig := defaultIngesterTestConfig(t)
+	ingesterConfig.LifecyclerConfig.TokensFilePath = filepath.Join(t.TempDir(), "tokens")
+	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
+	require.NoError(t, err)
+
+	store := &mockStore{
+		chunks: map[string][]chunk.Chunk{},
+	}
+
+	i, err := New(ingesterConfig, client.Config{}, store, limits, runtime.DefaultTenantConfigs(), nil, writefailures.Cfg{})
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), i))
+	defer services.StopAndAwaitTerminated(context.Background(), i) //nolint:errcheck
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(ingesterConfig.LifecyclerConfig.TokensFilePath)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	_, err = i.Push(ctx, &logproto.PushRequest{Streams: []logproto.Stream{{
+		Labels:  `{foo="bar"}`,
+		Entries: []logproto.Entry{{Timestamp: time.Now(), Line: "line"}},
+	}}})
+	require.NoError(t, err)
+
+	resp := httptest.NewRecorder()
+	i.ShutdownHandler(resp, httptest.NewRequest("POST", "/ingester/shutdown?flush=true&delete_ring_tokens=true&terminate=false", nil))
+	require.Equal(t, http.StatusNoContent, resp.Code)
+	require.Equal(t, services.Terminated, i.State())
+	require.False(t, i.terminateOnShutdown)
+
+	// The chunks have been flushed and the tokens file deleted.
+	store.mtx.Lock()
+	require.Len(t, store.chunks["test"], 1)
+	store.mtx.Unlock()
+	_, err = os.Stat(ingesterConfig.LifecyclerConfig.TokensFilePath)
+	require.True(t, os.IsNotExist(err))
+
+	// The ingester can only be shut down once.
+	resp = httptest.NewRecorder()
+	i.ShutdownHandler(resp, httptest.NewRequest("POST", "/ingester/shutdown", nil))
+	require.Equal(t, http.StatusServiceUnavailable, resp.Code)
+}
+
 func TestIngester_GetStreamRates_Correctness(t *testing.T) {
 	ingesterConfig := defaultIngesterTestConfig(t)
 	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
@@ -403,6 +451,55 @@ func TestIngesterStreamLimitExceeded(t *testing.T) {
 	}
 }
 
+func TestIngesterStreamLimitEviction(t *testing.T) {
+	ingesterConfig := defaultIngesterTestConfig(t)
+	defaultLimits := defaultLimitsTestConfig()
+	defaultLimits.MaxLocalStreamsPerUser = 2
+	defaultLimits.EvictStreamsOnLimit = true
+	overrides, err := validation.NewOverrides(defaultLimits, nil)
+	require.NoError(t, err)
+
+	i, err := New(ingesterConfig, client.Config{}, &mockStore{chunks: map[string][]chunk.Chunk{}}, overrides, runtime.DefaultTenantConfigs(), nil, writefailures.Cfg{})
+	require.NoError(t, err)
+	defer services.StopAndAwaitTerminated(context.Background(), i) //nolint:errcheck
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	push := func(labels string) error {
+		_, err := i.Push(ctx, &logproto.PushRequest{Streams: []logproto.Stream{{
+			Labels:  labels,
+			Entries: []logproto.Entry{{Timestamp: time.Now(), Line: labels}},
+		}}})
+		return err
+	}
+	for _, labels := range []string{`{bar="baz1", foo="bar"}`, `{bar="baz2", foo="bar"}`, `{bar="baz1", foo="bar"}`, `{bar="baz3", foo="bar"}`} {
+		require.NoError(t, push(labels))
+	}
+
+	// The least recently written stream is evicted to make room for the new one.
+	inst := i.instances["test"]
+	require.Equal(t, 3, inst.streams.Len())
+	require.Equal(t, int64(1), inst.evictedStreams.Load())
+	evicted, ok := inst.streams.Load(`{bar="baz2", foo="bar"}`)
+	require.True(t, ok)
+	require.True(t, evicted.evicted)
+	shouldFlush, reason := i.shouldFlushChunk(evicted, &evicted.chunks[0])
+	require.True(t, shouldFlush)
+	require.Equal(t, flushReasonEvicted, reason)
+
+	// Writing to the evicted stream keeps it.
+	require.NoError(t, push(`{bar="baz2", foo="bar"}`))
+	require.False(t, evicted.evicted)
+	require.Equal(t, int64(0), inst.evictedStreams.Load())
+
+	// The streams are evicted in the order they were last written to.
+	require.NoError(t, push(`{bar="baz4", foo="bar"}`))
+	require.Equal(t, int64(1), inst.evictedStreams.Load())
+	evicted, ok = inst.streams.Load(`{bar="baz1", foo="bar"}`)
+	require.True(t, ok)
+	require.True(t, evicted.evicted)
+	require.Equal(t, 3, inst.streamsByPush.Len())
+}
+
 type mockStore struct {
 	mtx    sync.Mutex
 	chunks map[string][]chunk.Chunk
@@ -684,6 +781,27 @@ func TestValidate(t *testing.T) {
 				IndexShards:    index.DefaultIndexShards,
 			},
 		},
+		{
+			in: Config{
+				ChunkEncoding:  chunkenc.EncZstd.String(),
+				ChunkZstdLevel: 19,
+				IndexShards:    index.DefaultIndexShards,
+			},
+			expected: Config{
+				ChunkEncoding:  chunkenc.EncZstd.String(),
+				ChunkZstdLevel: 19,
+				parsedEncoding: chunkenc.EncZstd,
+				IndexShards:    index.DefaultIndexShards,
+			},
+		},
+		{
+			in: Config{
+				ChunkEncoding:  chunkenc.EncZstd.String(),
+				ChunkZstdLevel: 23,
+				IndexShards:    index.DefaultIndexShards,
+			},
+			err: true,
+		},
 		{
 			in: Config{
 				IndexShards:   index.DefaultIndexShards,
@@ -698,6 +816,35 @@ func TestValidate(t *testing.T) {
 			},
 			err: true,
 		},
+		{
+			in: Config{
+				ChunkEncoding: chunkenc.EncGZIP.String(),
+				IndexShards:   index.DefaultIndexShards,
+				LifecyclerConfig: ring.LifecyclerConfig{
+					RingConfig: ring.Config{ZoneAwarenessEnabled: true},
+				},
+			},
+			err: true,
+		},
+		{
+			in: Config{
+				ChunkEncoding: chunkenc.EncGZIP.String(),
+				IndexShards:   index.DefaultIndexShards,
+				LifecyclerConfig: ring.LifecyclerConfig{
+					RingConfig: ring.Config{ZoneAwarenessEnabled: true},
+					Zone:       "zone-a",
+				},
+			},
+			expected: Config{
+				ChunkEncoding:  chunkenc.EncGZIP.String(),
+				parsedEncoding: chunkenc.EncGZIP,
+				IndexShards:    index.DefaultIndexShards,
+				LifecyclerConfig: ring.LifecyclerConfig{
+					RingConfig: ring.Config{ZoneAwarenessEnabled: true},
+					Zone:       "zone-a",
+				},
+			},
+		},
 	} {
 		t.Run(fmt.Sprint(i), func(t *testing.T) {
 			err := tc.in.Validate()
@@ -1,6 +1,7 @@
 package ingester
 
 import (
+	"container/list"
 	"context"
 	"net/http"
 	"os"
@@ -44,7 +45,7 @@ import (
 const (
 	// ShardLbName is the internal label to be used by Loki when dividing a stream into smaller pieces.
 	// Possible values are only increasing integers starting from 0.
-	ShardLbName        = "__stream_shard__"
+	ShardLbName        = util.ShardLbName
 	ShardLbPlaceholder = "__placeholder__"
 
 	queryBatchSize       = 128
@@ -89,6 +90,14 @@ type instance struct {
 
 	streamsCreatedTotal prometheus.Counter
 	streamsRemovedTotal prometheus.Counter
+	// evictedStreams is the number of evicted streams which are not removed yet.
+	// They do not count towards the streams limit.
+	evictedStreams *atomic.Int64
+	// streamsByPush holds the streams which are not evicted, the most
+	// recently written first, so the least recently written stream is
+	// evicted without going through all the streams.
+	streamsByPush    *list.List
+	streamsByPushMtx sync.Mutex
 
 	tailers   map[uint32]*tailer
 	tailerMtx sync.RWMutex
@@ -136,6 +145,8 @@ func newInstance(
 
 		streamsCreatedTotal: streamsCreatedTotal.WithLabelValues(instanceID),
 		streamsRemovedTotal: streamsRemovedTotal.WithLabelValues(instanceID),
+		evictedStreams:      atomic.NewInt64(0),
+		streamsByPush:       list.New(),
 
 		tailers: map[uint32]*tailer{},
 		limiter: limiter,
@@ -214,8 +225,17 @@ func (i *instance) Push(ctx context.Context, req *logproto.PushRequest) error {
 			continue
 		}
 
-		_, appendErr = s.Push(ctx, reqStream.Entries, record, 0, false, rateLimitWholeStream)
+		var bytesAdded int
+		bytesAdded, appendErr = s.Push(ctx, reqStream.Entries, record, 0, false, rateLimitWholeStream)
+		// Writing to an evicted stream makes it recently written again, so it is kept.
+		if s.evicted && bytesAdded > 0 {
+			s.evicted = false
+			i.evictedStreams.Dec()
+		}
 		s.chunkMtx.Unlock()
+		if bytesAdded > 0 {
+			i.markPushed(s)
+		}
 	}
 
 	if !record.IsEmpty() {
@@ -242,7 +262,10 @@ func (i *instance) createStream(pushReqStream logproto.Stream, record *wal.Recor
 	// reducing the stream limits, for instance.
 	var err error
 	if record != nil {
-		err = i.limiter.AssertMaxStreamsPerUser(i.instanceID, i.streams.Len())
+		err = i.limiter.AssertMaxStreamsPerUser(i.instanceID, i.streams.Len()-int(i.evictedStreams.Load()))
+		if err != nil && i.limiter.EvictStreamsOnLimit(i.instanceID) && i.evictStream() {
+			err = nil
+		}
 	}
 
 	if err != nil {
@@ -279,7 +302,8 @@ func (i *instance) createStream(pushReqStream logproto.Stream, record *wal.Recor
 	fp := i.getHashForLabels(labels)
 
 	sortedLabels := i.index.Add(logproto.FromLabelsToLabelAdapters(labels), fp)
-	s := newStream(i.cfg, i.limiter, i.instanceID, fp, sortedLabels, i.limiter.UnorderedWrites(i.instanceID), i.streamRateCalculator, i.metrics, i.writeFailures)
+	s := newStream(i.cfg, i.limiter, i.instanceID, fp, sortedLabels, i.limiter.UnorderedWrites(i.instanceID), i.limiter.AllowStructuredMetadata(i.instanceID), i.streamRateCalculator, i.metrics, i.writeFailures)
+	s.setTenantLimits(i.limiter)
 
 	// record will be nil when replaying the wal (we don't want to rewrite wal entries as we replay them).
 	if record != nil {
@@ -296,6 +320,7 @@ func (i *instance) createStream(pushReqStream logproto.Stream, record *wal.Recor
 	memoryStreamsLabelsBytes.Add(float64(len(s.labels.String())))
 	i.streamsCreatedTotal.Inc()
 	i.addTailersToNewStream(s)
+	i.markPushed(s)
 	streamsCountStats.Add(1)
 
 	if i.configs.LogStreamCreation(i.instanceID) {
@@ -309,14 +334,72 @@ func (i *instance) createStream(pushReqStream logproto.Stream, record *wal.Recor
 	return s, nil
 }
 
+// markPushed makes the stream the most recently written one.
+func (i *instance) markPushed(s *stream) {
+	i.streamsByPushMtx.Lock()
+	defer i.streamsByPushMtx.Unlock()
+	if s.pushElem != nil {
+		i.streamsByPush.MoveToFront(s.pushElem)
+		return
+	}
+	s.pushElem = i.streamsByPush.PushFront(s)
+}
+
+// popLeastRecentlyPushed removes the least recently written stream from the
+// streams ordered by last push and returns it, nil if there is none.
+func (i *instance) popLeastRecentlyPushed() *stream {
+	i.streamsByPushMtx.Lock()
+	defer i.streamsByPushMtx.Unlock()
+	e := i.streamsByPush.Back()
+	if e == nil {
+		return nil
+	}
+	s := i.streamsByPush.Remove(e).(*stream)
+	s.pushElem = nil
+	return s
+}
+
+// evictStream evicts the least recently written stream, to make room for a new
+// stream. It returns false if all streams are already evicted.
+// It must be called with the streams map locked, like createStream.
+func (i *instance) evictStream() bool {
+	var lru *stream
+	for lru == nil {
+		if lru = i.popLeastRecentlyPushed(); lru == nil {
+			return false
+		}
+		lru.chunkMtx.Lock()
+		// The stream can already be evicted when it was written to while it
+		// was evicted before.
+		if lru.evicted {
+			lru.chunkMtx.Unlock()
+			lru = nil
+		}
+	}
+	defer lru.chunkMtx.Unlock()
+	lru.evicted = true
+	i.evictedStreams.Inc()
+	i.metrics.streamsEvicted.WithLabelValues(i.instanceID).Inc()
+	if i.configs.LogStreamCreation(i.instanceID) {
+		level.Debug(util_log.Logger).Log(
+			"msg", "evicted stream to make room for a new stream",
+			"org_id", i.instanceID,
+			"stream", lru.labelsString,
+		)
+	}
+	return true
+}
+
 func (i *instance) createStreamByFP(ls labels.Labels, fp model.Fingerprint) *stream {
 	sortedLabels := i.index.Add(logproto.FromLabelsToLabelAdapters(ls), fp)
-	s := newStream(i.cfg, i.limiter, i.instanceID, fp, sortedLabels, i.limiter.UnorderedWrites(i.instanceID), i.streamRateCalculator, i.metrics, i.writeFailures)
+	s := newStream(i.cfg, i.limiter, i.instanceID, fp, sortedLabels, i.limiter.UnorderedWrites(i.instanceID), i.limiter.AllowStructuredMetadata(i.instanceID), i.streamRateCalculator, i.metrics, i.writeFailures)
+	s.setTenantLimits(i.limiter)
 
 	i.streamsCreatedTotal.Inc()
 	memoryStreams.WithLabelValues(i.instanceID).Inc()
 	memoryStreamsLabelsBytes.Add(float64(len(s.labels.String())))
 	i.addTailersToNewStream(s)
+	i.markPushed(s)
 
 	return s
 }
@@ -335,6 +418,15 @@ func (i *instance) getOrCreateStream(pushReqStream logproto.Stream, record *wal.
 // removeStream removes a stream from the instance.
 func (i *instance) removeStream(s *stream) {
 	if i.streams.Delete(s) {
+		if s.evicted {
+			i.evictedStreams.Dec()
+		}
+		i.streamsByPushMtx.Lock()
+		if s.pushElem != nil {
+			i.streamsByPush.Remove(s.pushElem)
+			s.pushElem = nil
+		}
+		i.streamsByPushMtx.Unlock()
 		i.index.Delete(s.labels, s.fp)
 		i.streamsRemovedTotal.Inc()
 		memoryStreams.WithLabelValues(i.instanceID).Dec()
@@ -388,7 +480,7 @@ func (i *instance) Query(ctx context.Context, req logql.SelectLogParams) (iter.E
 		expr.Matchers(),
 		shard,
 		func(stream *stream) error {
-			iter, err := stream.Iterator(ctx, stats, req.Start, req.End, req.Direction, pipeline.ForStream(stream.labels))
+			iter, err := stream.Iterator(ctx, stats, req.Start, req.End, req.Direction, pipeline.ForStream(util.WithoutShardLabel(stream.labels)))
 			if err != nil {
 				return err
 			}
@@ -443,7 +535,7 @@ func (i *instance) QuerySample(ctx context.Context, req logql.SelectSampleParams
 		selector.Matchers(),
 		shard,
 		func(stream *stream) error {
-			iter, err := stream.SampleIterator(ctx, stats, req.Start, req.End, extractor.ForStream(stream.labels))
+			iter, err := stream.SampleIterator(ctx, stats, req.Start, req.End, extractor.ForStream(util.WithoutShardLabel(stream.labels)))
 			if err != nil {
 				return err
 			}
@@ -459,14 +551,18 @@ func (i *instance) QuerySample(ctx context.Context, req logql.SelectSampleParams
 }
 
 // Label returns the label names or values depending on the given request
-// Without label matchers the label names and values are retrieved from the index directly.
-// If label matchers are given only the matching streams are fetched from the index.
-// The label names or values are then retrieved from those matching streams.
+// The label names and values are retrieved from the index directly, restricted to the series matching the label matchers if given.
+// Only with a chunk filter are the label names or values retrieved from the matching streams instead.
 func (i *instance) Label(ctx context.Context, req *logproto.LabelRequest, matchers ...*labels.Matcher) (*logproto.LabelResponse, error) {
-	if len(matchers) == 0 {
+	// the internal stream shard label isn't exposed.
+	if req.Values && req.Name == ShardLbName {
+		return &logproto.LabelResponse{}, nil
+	}
+
+	if len(matchers) == 0 || i.chunkFilter == nil {
 		var labels []string
 		if req.Values {
-			values, err := i.index.LabelValues(*req.Start, req.Name, nil)
+			values, err := i.index.LabelValues(*req.Start, req.Name, nil, matchers...)
 			if err != nil {
 				return nil, err
 			}
@@ -476,10 +572,11 @@ func (i *instance) Label(ctx context.Context, req *logproto.LabelRequest, matche
 				Values: labels,
 			}, nil
 		}
-		names, err := i.index.LabelNames(*req.Start, nil)
+		names, err := i.index.LabelNames(*req.Start, nil, matchers...)
 		if err != nil {
 			return nil, err
 		}
+		names = util.WithoutShardLabelName(names)
 		labels = make([]string, len(names))
 		copy(labels, names)
 		return &logproto.LabelResponse{
@@ -487,15 +584,28 @@ func (i *instance) Label(ctx context.Context, req *logproto.LabelRequest, matche
 		}, nil
 	}
 
-	labels := util.NewUniqueStrings(0)
+	// the chunk filter needs the labels of the streams.
+	values := util.NewUniqueStrings(0)
+	if req.Values {
+		// Only the streams with the label are looked up in the inverted index,
+		// instead of going through all the streams matching the selector.
+		matchers = append(matchers[:len(matchers):len(matchers)], labels.MustNewMatcher(labels.MatchNotEqual, req.Name, ""))
+		err := i.forMatchingStreams(ctx, *req.Start, matchers, nil, func(s *stream) error {
+			values.Add(s.labels.Get(req.Name))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &logproto.LabelResponse{
+			Values: values.Strings(),
+		}, nil
+	}
+
 	err := i.forMatchingStreams(ctx, *req.Start, matchers, nil, func(s *stream) error {
 		for _, label := range s.labels {
-			if req.Values && label.Name == req.Name {
-				labels.Add(label.Value)
-				continue
-			}
-			if !req.Values {
-				labels.Add(label.Name)
+			if label.Name != ShardLbName {
+				values.Add(label.Name)
 			}
 		}
 		return nil
@@ -505,7 +615,7 @@ func (i *instance) Label(ctx context.Context, req *logproto.LabelRequest, matche
 	}
 
 	return &logproto.LabelResponse{
-		Values: labels.Strings(),
+		Values: values.Strings(),
 	}, nil
 }
 
@@ -519,49 +629,40 @@ func (i *instance) Series(ctx context.Context, req *logproto.SeriesRequest) (*lo
 		return nil, err
 	}
 
-	var series []logproto.SeriesIdentifier
+	// the shards of a stream are collapsed into a single series without the internal stream shard label.
+	dedupedSeries := make(map[uint64]logproto.SeriesIdentifier)
+	addSeries := func(stream *stream) error {
+		// consider the stream only if it overlaps the request time range
+		if shouldConsiderStream(stream, req.Start, req.End) {
+			// exit early when this stream was added by an earlier group or shard
+			key := stream.labelHashNoShard
+			if _, found := dedupedSeries[key]; found {
+				return nil
+			}
+
+			dedupedSeries[key] = logproto.SeriesIdentifier{
+				Labels: util.WithoutShardLabel(stream.labels).Map(),
+			}
+		}
+		return nil
+	}
 
 	// If no matchers were supplied we include all streams.
 	if len(groups) == 0 {
-		series = make([]logproto.SeriesIdentifier, 0, i.streams.Len())
-		err = i.forMatchingStreams(ctx, req.Start, nil, shard, func(stream *stream) error {
-			// consider the stream only if it overlaps the request time range
-			if shouldConsiderStream(stream, req.Start, req.End) {
-				series = append(series, logproto.SeriesIdentifier{
-					Labels: stream.labels.Map(),
-				})
-			}
-			return nil
-		})
-		if err != nil {
+		if err = i.forMatchingStreams(ctx, req.Start, nil, shard, addSeries); err != nil {
 			return nil, err
 		}
 	} else {
-		dedupedSeries := make(map[uint64]logproto.SeriesIdentifier)
 		for _, matchers := range groups {
-			err = i.forMatchingStreams(ctx, req.Start, matchers, shard, func(stream *stream) error {
-				// consider the stream only if it overlaps the request time range
-				if shouldConsiderStream(stream, req.Start, req.End) {
-					// exit early when this stream was added by an earlier group
-					key := stream.labelHash
-					if _, found := dedupedSeries[key]; found {
-						return nil
-					}
-
-					dedupedSeries[key] = logproto.SeriesIdentifier{
-						Labels: stream.labels.Map(),
-					}
-				}
-				return nil
-			})
-			if err != nil {
+			if err = i.forMatchingStreams(ctx, req.Start, matchers, shard, addSeries); err != nil {
 				return nil, err
 			}
 		}
-		series = make([]logproto.SeriesIdentifier, 0, len(dedupedSeries))
-		for _, v := range dedupedSeries {
-			series = append(series, v)
-		}
+	}
+
+	series := make([]logproto.SeriesIdentifier, 0, len(dedupedSeries))
+	for _, v := range dedupedSeries {
+		series = append(series, v)
 	}
 
 	return &logproto.SeriesResponse{Series: series}, nil
@@ -294,7 +294,7 @@ func setupTestStreams(t *testing.T) (*instance, time.Time, int) {
 	for _, testStream := range testStreams {
 		stream, err := instance.getOrCreateStream(testStream, recordPool.GetRecord())
 		require.NoError(t, err)
-		chunk := newStream(cfg, limiter, "fake", 0, nil, true, NewStreamRateCalculator(), NilMetrics, nil).NewChunk()
+		chunk := newStream(cfg, limiter, "fake", 0, nil, true, false, NewStreamRateCalculator(), NilMetrics, nil).NewChunk()
 		for _, entry := range testStream.Entries {
 			err = chunk.Append(&entry)
 			require.NoError(t, err)
@@ -366,6 +366,32 @@ func Test_LabelQuery(t *testing.T) {
 			},
 			[]*labels.Matcher{m},
 		},
+		{
+			"label values - with matcher, label missing from the matching streams",
+			&logproto.LabelRequest{
+				Name:   "missing",
+				Values: true,
+				Start:  start,
+				End:    end,
+			},
+			logproto.LabelResponse{
+				Values: []string{},
+			},
+			[]*labels.Matcher{m},
+		},
+		{
+			"label values - with a matcher matching the empty value",
+			&logproto.LabelRequest{
+				Name:   "app",
+				Values: true,
+				Start:  start,
+				End:    end,
+			},
+			logproto.LabelResponse{
+				Values: []string{"test2"},
+			},
+			[]*labels.Matcher{labels.MustNewMatcher(labels.MatchNotEqual, "app", "test")},
+		},
 	}
 
 	for _, tc := range tests {
@@ -532,7 +558,7 @@ func Benchmark_instance_addNewTailer(b *testing.B) {
 	ctx := context.Background()
 
 	inst, _ := newInstance(&Config{}, defaultPeriodConfigs, "test", limiter, loki_runtime.DefaultTenantConfigs(), noopWAL{}, NilMetrics, &OnceSwitch{}, nil, NewStreamRateCalculator(), nil)
-	t, err := newTailer("foo", `{namespace="foo",pod="bar",instance=~"10.*"}`, nil, 10)
+	t, err := newTailer("foo", `{namespace="foo",pod="bar",instance=~"10.*"}`, nil, tailerConfig{maxDroppedStreams: 10}, NilMetrics)
 	require.NoError(b, err)
 	for i := 0; i < 10000; i++ {
 		require.NoError(b, inst.Push(ctx, &logproto.PushRequest{
@@ -547,7 +573,7 @@ func Benchmark_instance_addNewTailer(b *testing.B) {
 	lbs := makeRandomLabels()
 	b.Run("addTailersToNewStream", func(b *testing.B) {
 		for n := 0; n < b.N; n++ {
-			inst.addTailersToNewStream(newStream(nil, limiter, "fake", 0, lbs, true, NewStreamRateCalculator(), NilMetrics, nil))
+			inst.addTailersToNewStream(newStream(nil, limiter, "fake", 0, lbs, true, false, NewStreamRateCalculator(), NilMetrics, nil))
 		}
 	})
 }
@@ -617,6 +643,92 @@ func Test_Iterator(t *testing.T) {
 	require.Equal(t, int64(8*1e6), res.Streams[1].Entries[0].Timestamp.UnixNano())
 }
 
+func Test_IteratorCollapsesStreamShards(t *testing.T) {
+	instance := defaultInstance(t)
+
+	require.NoError(t, instance.Push(context.TODO(), &logproto.PushRequest{
+		Streams: []logproto.Stream{
+			{
+				Labels:  `{app="hot", __stream_shard__="0"}`,
+				Entries: []logproto.Entry{{Timestamp: time.Unix(0, 1), Line: "1"}},
+			},
+			{
+				Labels:  `{app="hot", __stream_shard__="1"}`,
+				Entries: []logproto.Entry{{Timestamp: time.Unix(0, 2), Line: "2"}},
+			},
+		},
+	}))
+
+	it, err := instance.Query(context.TODO(),
+		logql.SelectLogParams{
+			QueryRequest: &logproto.QueryRequest{
+				Selector:  `{app="hot"}`,
+				Limit:     uint32(10),
+				Start:     time.Unix(0, 0),
+				End:       time.Unix(0, 100000000),
+				Direction: logproto.FORWARD,
+			},
+		},
+	)
+	require.NoError(t, err)
+
+	var res *logproto.QueryResponse
+	require.NoError(t,
+		sendBatches(context.TODO(), it,
+			fakeQueryServer(
+				func(qr *logproto.QueryResponse) error {
+					res = qr
+					return nil
+				},
+			),
+			int32(10)),
+	)
+	require.Equal(t, 1, len(res.Streams))
+	require.Equal(t, `{app="hot"}`, res.Streams[0].Labels)
+	require.Equal(t, 2, len(res.Streams[0].Entries))
+}
+
+func Test_LabelsAndSeriesHideStreamShards(t *testing.T) {
+	instance := defaultInstance(t)
+
+	require.NoError(t, instance.Push(context.TODO(), &logproto.PushRequest{
+		Streams: []logproto.Stream{
+			{
+				Labels:  `{app="hot", __stream_shard__="0"}`,
+				Entries: []logproto.Entry{{Timestamp: time.Unix(0, 1), Line: "1"}},
+			},
+			{
+				Labels:  `{app="hot", __stream_shard__="1"}`,
+				Entries: []logproto.Entry{{Timestamp: time.Unix(0, 2), Line: "2"}},
+			},
+		},
+	}))
+
+	start, end := time.Unix(0, 0), time.Unix(0, 100000000)
+	for _, matchers := range [][]*labels.Matcher{nil, {labels.MustNewMatcher(labels.MatchEqual, "app", "hot")}} {
+		names, err := instance.Label(context.TODO(), &logproto.LabelRequest{Start: &start, End: &end}, matchers...)
+		require.NoError(t, err)
+		require.NotContains(t, names.Values, ShardLbName)
+
+		values, err := instance.Label(context.TODO(), &logproto.LabelRequest{Name: ShardLbName, Values: true, Start: &start, End: &end}, matchers...)
+		require.NoError(t, err)
+		require.Empty(t, values.Values)
+	}
+
+	for _, groups := range [][]string{nil, {`{app="hot"}`}} {
+		series, err := instance.Series(context.TODO(), &logproto.SeriesRequest{Start: start, End: end, Groups: groups})
+		require.NoError(t, err)
+		var hot []logproto.SeriesIdentifier
+		for _, s := range series.Series {
+			require.NotContains(t, s.Labels, ShardLbName)
+			if s.Labels["app"] == "hot" {
+				hot = append(hot, s)
+			}
+		}
+		require.Equal(t, []logproto.SeriesIdentifier{{Labels: map[string]string{"app": "hot"}}}, hot)
+	}
+}
+
 type testFilter struct{}
 
 func (t *testFilter) ForRequest(_ context.Context) chunk.Filterer {
@@ -6,9 +6,11 @@ import (
 	"sync"
 	"time"
 
+	"github.com/prometheus/prometheus/model/labels"
 	"golang.org/x/time/rate"
 
 	"github.com/grafana/loki/pkg/distributor/shardstreams"
+	"github.com/grafana/loki/pkg/storage/stores/indexshipper/compactor/retention"
 	"github.com/grafana/loki/pkg/validation"
 )
 
@@ -24,8 +26,19 @@ type RingCount interface {
 
 type Limits interface {
 	UnorderedWrites(userID string) bool
+	OutOfOrderTimeWindow(userID string) time.Duration
+	DuplicateEntriesWindow(userID string) time.Duration
+	ChunkTargetSize(userID string) int
+	ChunkIdlePeriod(userID string) time.Duration
+	MaxChunkAge(userID string) time.Duration
+	AllowStructuredMetadata(userID string) bool
 	MaxLocalStreamsPerUser(userID string) int
 	MaxGlobalStreamsPerUser(userID string) int
+	EvictStreamsOnLimit(userID string) bool
+	MaxTailersPerUser(userID string) int
+	RetentionPeriod(userID string) time.Duration
+	StreamRetention(userID string) []validation.StreamRetention
+	EnforceRetention(userID string) bool
 	PerStreamRateLimit(userID string) validation.RateLimit
 	ShardStreams(userID string) *shardstreams.Config
 }
@@ -76,6 +89,56 @@ func (l *Limiter) UnorderedWrites(userID string) bool {
 	return l.limits.UnorderedWrites(userID)
 }
 
+// OutOfOrderTimeWindow returns the maximum age of out-of-order entries relative
+// to the newest entry of their stream, 0 for the default window.
+func (l *Limiter) OutOfOrderTimeWindow(userID string) time.Duration {
+	return l.limits.OutOfOrderTimeWindow(userID)
+}
+
+// DuplicateEntriesWindow returns the window during which duplicate entries are suppressed, 0 to disable.
+func (l *Limiter) DuplicateEntriesWindow(userID string) time.Duration {
+	return l.limits.DuplicateEntriesWindow(userID)
+}
+
+// ChunkTargetSize returns the target size of the chunks of the tenant, 0 for the ingester default.
+func (l *Limiter) ChunkTargetSize(userID string) int {
+	return l.limits.ChunkTargetSize(userID)
+}
+
+// ChunkIdlePeriod returns the idle period of the chunks of the tenant, 0 for the ingester default.
+func (l *Limiter) ChunkIdlePeriod(userID string) time.Duration {
+	return l.limits.ChunkIdlePeriod(userID)
+}
+
+// MaxChunkAge returns the maximum age of the chunks of the tenant, 0 for the ingester default.
+func (l *Limiter) MaxChunkAge(userID string) time.Duration {
+	return l.limits.MaxChunkAge(userID)
+}
+
+// EvictStreamsOnLimit returns whether the least recently written streams of the tenant are evicted
+// to make room for new streams when the streams limit is reached.
+func (l *Limiter) EvictStreamsOnLimit(userID string) bool {
+	return l.limits.EvictStreamsOnLimit(userID)
+}
+
+// MaxTailersPerUser returns the maximum number of concurrent tail requests of the tenant on this ingester, 0 for no limit.
+func (l *Limiter) MaxTailersPerUser(userID string) int {
+	return l.limits.MaxTailersPerUser(userID)
+}
+
+// EnforcedRetentionPeriod returns the retention period of the stream of the tenant
+// when its retention is enforced before the compactor applies it, 0 otherwise.
+func (l *Limiter) EnforcedRetentionPeriod(userID string, lbs labels.Labels) time.Duration {
+	if !l.limits.EnforceRetention(userID) {
+		return 0
+	}
+	return retention.RetentionPeriodFor(l.limits, userID, lbs)
+}
+
+func (l *Limiter) AllowStructuredMetadata(userID string) bool {
+	return l.limits.AllowStructuredMetadata(userID)
+}
+
 // AssertMaxStreamsPerUser ensures limit has not been reached compared to the current
 // number of streams in input and returns an error if so.
 func (l *Limiter) AssertMaxStreamsPerUser(userID string, streams int) error {
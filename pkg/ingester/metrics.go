@@ -62,6 +62,15 @@ type ingesterMetrics struct {
 
 	// Shutdown marker for ingester scale down
 	shutdownMarker prometheus.Gauge
+
+	tailerDroppedEntries         *prometheus.CounterVec
+	tailerSlowConsumerDisconnect *prometheus.CounterVec
+
+	duplicateEntriesSuppressed *prometheus.CounterVec
+	chunksExpired              *prometheus.CounterVec
+	streamsEvicted             *prometheus.CounterVec
+	flushQueueEnqueued         *prometheus.CounterVec
+	flushQueueDropped          *prometheus.CounterVec
 }
 
 // setRecoveryBytesInUse bounds the bytes reports to >= 0.
@@ -277,5 +286,48 @@ func newIngesterMetrics(r prometheus.Registerer) *ingesterMetrics {
 			Name:      "shutdown_marker",
 			Help:      "1 if prepare shutdown has been called, 0 otherwise",
 		}),
+
+		tailerDroppedEntries: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki",
+			Subsystem: "ingester",
+			Name:      "tail_dropped_entries_total",
+			Help:      "Total number of entries dropped because the tail client is not consuming them fast enough.",
+		}, []string{"tenant"}),
+		tailerSlowConsumerDisconnect: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki",
+			Subsystem: "ingester",
+			Name:      "tail_slow_consumer_disconnects_total",
+			Help:      "Total number of tail clients disconnected because they were blocked for longer than the maximum blocked period.",
+		}, []string{"tenant"}),
+		duplicateEntriesSuppressed: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki",
+			Subsystem: "ingester",
+			Name:      "duplicate_entries_suppressed_total",
+			Help:      "Total number of pushed entries suppressed because an entry with the same timestamp and line was pushed to their stream within the duplicate entries window.",
+		}, []string{"tenant"}),
+		chunksExpired: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki",
+			Subsystem: "ingester",
+			Name:      "chunks_expired_total",
+			Help:      "Total number of chunks discarded instead of flushed because they are beyond the enforced retention of their stream.",
+		}, []string{"tenant"}),
+		streamsEvicted: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki",
+			Subsystem: "ingester",
+			Name:      "streams_evicted_total",
+			Help:      "Total number of streams evicted to make room for new streams when the streams limit of their tenant was reached.",
+		}, []string{"tenant"}),
+		flushQueueEnqueued: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki",
+			Subsystem: "ingester",
+			Name:      "flush_queue_enqueued_total",
+			Help:      "Total number of streams queued for flushing, by flush reason.",
+		}, []string{"reason"}),
+		flushQueueDropped: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki",
+			Subsystem: "ingester",
+			Name:      "flush_queue_dropped_total",
+			Help:      "Total number of periodic flushes of streams skipped because the flush queue was full, by flush reason.",
+		}, []string{"reason"}),
 	}
 }
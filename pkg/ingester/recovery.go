@@ -210,7 +210,7 @@ func (r *ingesterRecoverer) Close() {
 			s.unorderedWrites = isAllowed
 
 			if !isAllowed && old {
-				err := s.chunks[len(s.chunks)-1].chunk.ConvertHead(headBlockType(isAllowed))
+				err := s.chunks[len(s.chunks)-1].chunk.ConvertHead(headBlockType(isAllowed, s.allowStructuredMetadata))
 				if err != nil {
 					level.Warn(util_log.Logger).Log(
 						"msg", "error converting headblock",
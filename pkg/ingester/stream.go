@@ -2,6 +2,7 @@ package ingester
 
 import (
 	"bytes"
+	"container/list"
 	"context"
 	"fmt"
 	"net/http"
@@ -70,8 +71,30 @@ type stream struct {
 	// introduced to facilitate removing the ordering constraint.
 	entryCt int64
 
-	unorderedWrites      bool
-	streamRateCalculator *StreamRateCalculator
+	unorderedWrites bool
+	// outOfOrderWindow is the maximum age of out-of-order entries relative to
+	// highestTs, 0 for half of the max chunk age.
+	outOfOrderWindow        time.Duration
+	allowStructuredMetadata bool
+	streamRateCalculator    *StreamRateCalculator
+
+	// Chunking settings of the stream, which default to the ingester config
+	// and can be overridden per tenant.
+	chunkTargetSize int
+	chunkIdlePeriod time.Duration
+	maxChunkAge     time.Duration
+
+	// recentEntries is used to suppress duplicates of the entries pushed
+	// within the duplicate entries window, nil if disabled.
+	recentEntries *recentEntries
+
+	// evicted is set when the stream is evicted to make room for new streams.
+	// Its chunks are then flushed and the stream is removed, unless it is
+	// written to again in the meantime.
+	evicted bool
+	// pushElem is the element of the stream in the streams of its instance
+	// ordered by last push, guarded by the mutex of that list.
+	pushElem *list.Element
 
 	writeFailures *writefailures.Manager
 }
@@ -91,7 +114,7 @@ type entryWithError struct {
 	e     error
 }
 
-func newStream(cfg *Config, limits RateLimiterStrategy, tenant string, fp model.Fingerprint, labels labels.Labels, unorderedWrites bool, streamRateCalculator *StreamRateCalculator, metrics *ingesterMetrics, writeFailures *writefailures.Manager) *stream {
+func newStream(cfg *Config, limits RateLimiterStrategy, tenant string, fp model.Fingerprint, labels labels.Labels, unorderedWrites, allowStructuredMetadata bool, streamRateCalculator *StreamRateCalculator, metrics *ingesterMetrics, writeFailures *writefailures.Manager) *stream {
 	hashNoShard, _ := labels.HashWithoutLabels(make([]byte, 0, 1024), ShardLbName)
 	return &stream{
 		limiter:              NewStreamRateLimiter(limits, tenant, 10*time.Second),
@@ -106,8 +129,31 @@ func newStream(cfg *Config, limits RateLimiterStrategy, tenant string, fp model.
 		tenant:               tenant,
 		streamRateCalculator: streamRateCalculator,
 
-		unorderedWrites: unorderedWrites,
-		writeFailures:   writeFailures,
+		unorderedWrites:         unorderedWrites,
+		allowStructuredMetadata: allowStructuredMetadata,
+		writeFailures:           writeFailures,
+
+		chunkTargetSize: cfg.TargetChunkSize,
+		chunkIdlePeriod: cfg.MaxChunkIdle,
+		maxChunkAge:     cfg.MaxChunkAge,
+	}
+}
+
+// setTenantLimits applies the per-tenant overrides of the out-of-order time
+// window and of the chunking settings to the stream.
+func (s *stream) setTenantLimits(limits *Limiter) {
+	s.outOfOrderWindow = limits.OutOfOrderTimeWindow(s.tenant)
+	if size := limits.ChunkTargetSize(s.tenant); size > 0 {
+		s.chunkTargetSize = size
+	}
+	if period := limits.ChunkIdlePeriod(s.tenant); period > 0 {
+		s.chunkIdlePeriod = period
+	}
+	if age := limits.MaxChunkAge(s.tenant); age > 0 {
+		s.maxChunkAge = age
+	}
+	if window := limits.DuplicateEntriesWindow(s.tenant); window > 0 {
+		s.recentEntries = newRecentEntries(window)
 	}
 }
 
@@ -116,7 +162,7 @@ func newStream(cfg *Config, limits RateLimiterStrategy, tenant string, fp model.
 // Must hold chunkMtx
 // DEPRECATED: chunk transfers are no longer suggested and remain for compatibility.
 func (s *stream) consumeChunk(_ context.Context, chunk *logproto.Chunk) error {
-	c, err := chunkenc.NewByteChunk(chunk.Data, s.cfg.BlockSize, s.cfg.TargetChunkSize)
+	c, err := chunkenc.NewByteChunk(chunk.Data, s.cfg.BlockSize, s.chunkTargetSize)
 	if err != nil {
 		return err
 	}
@@ -145,7 +191,7 @@ func (s *stream) setChunks(chunks []Chunk) (bytesAdded, entriesAdded int, err er
 }
 
 func (s *stream) NewChunk() *chunkenc.MemChunk {
-	return chunkenc.NewMemChunk(s.cfg.parsedEncoding, headBlockType(s.unorderedWrites), s.cfg.BlockSize, s.cfg.TargetChunkSize)
+	return chunkenc.NewMemChunk(s.cfg.parsedEncoding, headBlockType(s.unorderedWrites, s.allowStructuredMetadata), s.cfg.BlockSize, s.chunkTargetSize)
 }
 
 func (s *stream) Push(
@@ -197,6 +243,9 @@ func (s *stream) Push(
 	}
 
 	bytesAdded, storedEntries, entriesWithErr := s.storeEntries(ctx, toStore)
+	if s.recentEntries != nil && !isReplay {
+		s.recentEntries.add(storedEntries, time.Now())
+	}
 	s.recordAndSendToTailers(record, storedEntries)
 
 	if len(s.chunks) != prevNumChunks {
@@ -344,12 +393,19 @@ func (s *stream) validateEntries(entries []logproto.Entry, isReplay, rateLimitWh
 		outOfOrderSamples, outOfOrderBytes   int
 		rateLimitedSamples, rateLimitedBytes int
 		validBytes, totalBytes               int
+		duplicateSamples                     int
 		failedEntriesWithError               []entryWithError
 		limit                                = s.limiter.lim.Limit()
 		lastLine                             = s.lastLine
 		highestTs                            = s.highestTs
 		toStore                              = make([]logproto.Entry, 0, len(entries))
+		// batch remembers the entries of the push accepted so far, to suppress the duplicates within the push too.
+		batch    *recentEntries
+		pushedAt = time.Now()
 	)
+	if s.recentEntries != nil && !isReplay {
+		batch = newRecentEntries(s.recentEntries.window)
+	}
 
 	for i := range entries {
 		// If this entry matches our last appended line's timestamp and contents,
@@ -363,6 +419,10 @@ func (s *stream) validateEntries(entries []logproto.Entry, isReplay, rateLimitWh
 		if entries[i].Timestamp.Equal(lastLine.ts) && entries[i].Line == lastLine.content {
 			continue
 		}
+		if batch != nil && (s.recentEntries.contains(&entries[i], pushedAt) || batch.contains(&entries[i], pushedAt)) {
+			duplicateSamples++
+			continue
+		}
 
 		lineBytes := len(entries[i].Line)
 		totalBytes += lineBytes
@@ -376,8 +436,7 @@ func (s *stream) validateEntries(entries []logproto.Entry, isReplay, rateLimitWh
 			continue
 		}
 
-		// The validity window for unordered writes is the highest timestamp present minus 1/2 * max-chunk-age.
-		cutoff := highestTs.Add(-s.cfg.MaxChunkAge / 2)
+		cutoff := s.outOfOrderCutoff(highestTs)
 		if !isReplay && s.unorderedWrites && !highestTs.IsZero() && cutoff.After(entries[i].Timestamp) {
 			failedEntriesWithError = append(failedEntriesWithError, entryWithError{&entries[i], chunkenc.ErrTooFarBehind(cutoff)})
 			s.writeFailures.Log(s.tenant, failedEntriesWithError[len(failedEntriesWithError)-1].e)
@@ -395,6 +454,9 @@ func (s *stream) validateEntries(entries []logproto.Entry, isReplay, rateLimitWh
 		}
 
 		toStore = append(toStore, entries[i])
+		if batch != nil {
+			batch.addEntry(&entries[i], pushedAt)
+		}
 	}
 
 	// Each successful call to 'AllowN' advances the limiter. With all-or-nothing
@@ -413,9 +475,23 @@ func (s *stream) validateEntries(entries []logproto.Entry, isReplay, rateLimitWh
 
 	s.streamRateCalculator.Record(s.tenant, s.labelHash, s.labelHashNoShard, totalBytes)
 	s.reportMetrics(outOfOrderSamples, outOfOrderBytes, rateLimitedSamples, rateLimitedBytes)
+	if duplicateSamples > 0 {
+		s.metrics.duplicateEntriesSuppressed.WithLabelValues(s.tenant).Add(float64(duplicateSamples))
+	}
 	return toStore, failedEntriesWithError
 }
 
+// outOfOrderCutoff returns the oldest timestamp accepted with unordered writes.
+// The validity window is the highest timestamp present minus the tenant's
+// out-of-order time window, which defaults to 1/2 * max-chunk-age.
+func (s *stream) outOfOrderCutoff(highestTs time.Time) time.Time {
+	window := s.maxChunkAge / 2
+	if s.outOfOrderWindow > 0 {
+		window = s.outOfOrderWindow
+	}
+	return highestTs.Add(-window)
+}
+
 func (s *stream) reportMetrics(outOfOrderSamples, outOfOrderBytes, rateLimitedSamples, rateLimitedBytes int) {
 	if outOfOrderSamples > 0 {
 		name := validation.OutOfOrder
@@ -592,8 +668,11 @@ func (s *stream) resetCounter() {
 	s.entryCt = 0
 }
 
-func headBlockType(unorderedWrites bool) chunkenc.HeadBlockFmt {
+func headBlockType(unorderedWrites, allowStructuredMetadata bool) chunkenc.HeadBlockFmt {
 	if unorderedWrites {
+		if allowStructuredMetadata {
+			return chunkenc.UnorderedWithStructuredMetadataHeadBlockFmt
+		}
 		return chunkenc.UnorderedHeadBlockFmt
 	}
 	return chunkenc.OrderedHeadBlockFmt
@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/require"
@@ -61,6 +62,7 @@ func TestMaxReturnedStreamsErrors(t *testing.T) {
 					{Name: "foo", Value: "bar"},
 				},
 				true,
+				false,
 				NewStreamRateCalculator(),
 				NilMetrics,
 				nil,
@@ -109,6 +111,7 @@ func TestPushDeduplication(t *testing.T) {
 			{Name: "foo", Value: "bar"},
 		},
 		true,
+		false,
 		NewStreamRateCalculator(),
 		NilMetrics,
 		nil,
@@ -140,6 +143,7 @@ func TestPushRejectOldCounter(t *testing.T) {
 			{Name: "foo", Value: "bar"},
 		},
 		true,
+		false,
 		NewStreamRateCalculator(),
 		NilMetrics,
 		nil,
@@ -240,6 +244,7 @@ func TestEntryErrorCorrectlyReported(t *testing.T) {
 			{Name: "foo", Value: "bar"},
 		},
 		true,
+		false,
 		NewStreamRateCalculator(),
 		NilMetrics,
 		nil,
@@ -271,6 +276,7 @@ func TestUnorderedPush(t *testing.T) {
 			{Name: "foo", Value: "bar"},
 		},
 		true,
+		false,
 		NewStreamRateCalculator(),
 		NilMetrics,
 		nil,
@@ -351,6 +357,123 @@ func TestUnorderedPush(t *testing.T) {
 	require.Equal(t, false, sItr.Next())
 }
 
+func TestUnorderedPushOutOfOrderTimeWindow(t *testing.T) {
+	cfg := defaultIngesterTestConfig(t)
+	cfg.MaxChunkAge = 10 * time.Second
+	l := defaultLimitsTestConfig()
+	l.OutOfOrderTimeWindow = model.Duration(time.Minute)
+	limits, err := validation.NewOverrides(l, nil)
+	require.NoError(t, err)
+	limiter := NewLimiter(limits, NilMetrics, &ringCountMock{count: 1}, 1)
+
+	s := newStream(&cfg, limiter, "fake", model.Fingerprint(0), labels.Labels{{Name: "foo", Value: "bar"}}, true, false, NewStreamRateCalculator(), NilMetrics, nil)
+	s.setTenantLimits(limiter)
+
+	_, err = s.Push(context.Background(), []logproto.Entry{{Timestamp: time.Unix(100, 0), Line: "x"}}, recordPool.GetRecord(), 0, true, false)
+	require.NoError(t, err)
+
+	// Further behind than half of the max chunk age, but within the window.
+	written, err := s.Push(context.Background(), []logproto.Entry{{Timestamp: time.Unix(50, 0), Line: "x"}}, recordPool.GetRecord(), 0, true, false)
+	require.NoError(t, err)
+	require.Equal(t, 1, written)
+
+	// Outside of the window.
+	_, err = s.Push(context.Background(), []logproto.Entry{{Timestamp: time.Unix(30, 0), Line: "x"}}, recordPool.GetRecord(), 0, true, false)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "too far behind")
+}
+
+func TestStreamDuplicateEntriesWindow(t *testing.T) {
+	cfg := defaultIngesterTestConfig(t)
+	l := defaultLimitsTestConfig()
+	l.DuplicateEntriesWindow = model.Duration(time.Minute)
+	limits, err := validation.NewOverrides(l, nil)
+	require.NoError(t, err)
+	limiter := NewLimiter(limits, NilMetrics, &ringCountMock{count: 1}, 1)
+	metrics := newIngesterMetrics(nil)
+
+	s := newStream(&cfg, limiter, "fake", model.Fingerprint(0), labels.Labels{{Name: "foo", Value: "bar"}}, true, false, NewStreamRateCalculator(), metrics, nil)
+	s.setTenantLimits(limiter)
+
+	entries := []logproto.Entry{
+		{Timestamp: time.Unix(1, 0), Line: "a"},
+		{Timestamp: time.Unix(2, 0), Line: "b"},
+	}
+	written, err := s.Push(context.Background(), entries, recordPool.GetRecord(), 0, true, false)
+	require.NoError(t, err)
+	require.Equal(t, 2, written)
+
+	// A retry of the push, with a new entry.
+	written, err = s.Push(context.Background(), append(entries, logproto.Entry{Timestamp: time.Unix(1, 0), Line: "c"}), recordPool.GetRecord(), 0, true, false)
+	require.NoError(t, err)
+	require.Equal(t, 1, written)
+	// The duplicate of the last entry of the stream is suppressed regardless of the window.
+	require.Equal(t, 1.0, testutil.ToFloat64(metrics.duplicateEntriesSuppressed.WithLabelValues("fake")))
+
+	// The duplicates within a push are suppressed too, but not the entries only differing by their structured metadata.
+	written, err = s.Push(context.Background(), []logproto.Entry{
+		{Timestamp: time.Unix(3, 0), Line: "d"},
+		{Timestamp: time.Unix(4, 0), Line: "e"},
+		{Timestamp: time.Unix(3, 0), Line: "d"},
+		{Timestamp: time.Unix(3, 0), Line: "d", StructuredMetadata: logproto.FromLabelsToStructuredMetadata(labels.FromStrings("trace_id", "1"))},
+	}, recordPool.GetRecord(), 0, true, false)
+	require.NoError(t, err)
+	require.Equal(t, 3, written)
+	require.Equal(t, 2.0, testutil.ToFloat64(metrics.duplicateEntriesSuppressed.WithLabelValues("fake")))
+
+	// Entries pushed before the window are forgotten.
+	now := time.Now()
+	s.recentEntries.add(nil, now.Add(2*time.Minute))
+	require.Empty(t, s.recentEntries.pushed)
+	require.False(t, s.recentEntries.contains(&entries[0], now))
+}
+
+func TestRecentEntriesHashCollision(t *testing.T) {
+	now := time.Now()
+	r := newRecentEntries(time.Minute)
+	e := logproto.Entry{Timestamp: time.Unix(1, 0), Line: "a"}
+
+	// Another entry pushed with the same hash isn't a duplicate.
+	r.pushed[entryHash(&e)] = []recentEntry{{entry: logproto.Entry{Timestamp: time.Unix(1, 0), Line: "b"}, pushedAt: now}}
+	require.False(t, r.contains(&e, now))
+
+	r.add([]logproto.Entry{e}, now)
+	require.Len(t, r.pushed[entryHash(&e)], 2)
+	require.True(t, r.contains(&e, now))
+	require.False(t, r.contains(&logproto.Entry{Timestamp: time.Unix(1, 0), Line: "a", StructuredMetadata: logproto.FromLabelsToStructuredMetadata(labels.FromStrings("a", "b"))}, now))
+}
+
+func TestStreamTenantChunkLimits(t *testing.T) {
+	cfg := defaultIngesterTestConfig(t)
+	cfg.TargetChunkSize = 1 << 20
+	cfg.MaxChunkIdle = time.Hour
+	cfg.MaxChunkAge = 2 * time.Hour
+
+	l := defaultLimitsTestConfig()
+	l.ChunkTargetSize = 64 << 10
+	l.MaxChunkAge = model.Duration(time.Minute)
+	limits, err := validation.NewOverrides(l, nil)
+	require.NoError(t, err)
+	limiter := NewLimiter(limits, NilMetrics, &ringCountMock{count: 1}, 1)
+
+	s := newStream(&cfg, limiter, "fake", model.Fingerprint(0), labels.Labels{{Name: "foo", Value: "bar"}}, true, false, NewStreamRateCalculator(), NilMetrics, nil)
+	s.setTenantLimits(limiter)
+	require.Equal(t, 64<<10, s.chunkTargetSize)
+	require.Equal(t, time.Hour, s.chunkIdlePeriod)
+	require.Equal(t, time.Minute, s.maxChunkAge)
+
+	_, err = s.Push(context.Background(), []logproto.Entry{
+		{Timestamp: time.Unix(0, 0), Line: "1"},
+		{Timestamp: time.Unix(61, 0), Line: "2"},
+	}, recordPool.GetRecord(), 0, true, false)
+	require.NoError(t, err)
+
+	i := &Ingester{cfg: cfg}
+	shouldFlush, reason := i.shouldFlushChunk(s, &s.chunks[0])
+	require.True(t, shouldFlush)
+	require.Equal(t, flushReasonMaxAge, reason)
+}
+
 func TestPushRateLimit(t *testing.T) {
 	l := validation.Limits{
 		PerStreamRateLimit:      10,
@@ -369,6 +492,7 @@ func TestPushRateLimit(t *testing.T) {
 			{Name: "foo", Value: "bar"},
 		},
 		true,
+		false,
 		NewStreamRateCalculator(),
 		NilMetrics,
 		nil,
@@ -404,6 +528,7 @@ func TestPushRateLimitAllOrNothing(t *testing.T) {
 			{Name: "foo", Value: "bar"},
 		},
 		true,
+		false,
 		NewStreamRateCalculator(),
 		NilMetrics,
 		nil,
@@ -438,6 +563,7 @@ func TestReplayAppendIgnoresValidityWindow(t *testing.T) {
 			{Name: "foo", Value: "bar"},
 		},
 		true,
+		false,
 		NewStreamRateCalculator(),
 		NilMetrics,
 		nil,
@@ -490,8 +616,8 @@ func Benchmark_PushStream(b *testing.B) {
 	require.NoError(b, err)
 	limiter := NewLimiter(limits, NilMetrics, &ringCountMock{count: 1}, 1)
 
-	s := newStream(&Config{MaxChunkAge: 24 * time.Hour}, limiter, "fake", model.Fingerprint(0), ls, true, NewStreamRateCalculator(), NilMetrics, nil)
-	t, err := newTailer("foo", `{namespace="loki-dev"}`, &fakeTailServer{}, 10)
+	s := newStream(&Config{MaxChunkAge: 24 * time.Hour}, limiter, "fake", model.Fingerprint(0), ls, true, false, NewStreamRateCalculator(), NilMetrics, nil)
+	t, err := newTailer("foo", `{namespace="loki-dev"}`, &fakeTailServer{}, tailerConfig{maxDroppedStreams: 10}, NilMetrics)
 	require.NoError(b, err)
 
 	go t.loop()
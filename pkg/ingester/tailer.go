@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/prometheus/model/labels"
 	"golang.org/x/net/context"
 
@@ -17,7 +18,20 @@ import (
 	util_log "github.com/grafana/loki/pkg/util/log"
 )
 
-const bufferSizeForTailResponse = 5
+const (
+	bufferSizeForTailResponse = 5
+	maxTailerBlockedPeriod    = 15 * time.Second
+)
+
+// tailerConfig configures the buffering of the streams sent to a tail client.
+type tailerConfig struct {
+	maxDroppedStreams int
+	// bufferSize is the number of streams buffered before they are dropped.
+	bufferSize int
+	// maxBlockedPeriod is how long the client may not consume the streams
+	// before it is disconnected.
+	maxBlockedPeriod time.Duration
+}
 
 type TailServer interface {
 	Send(*logproto.TailResponse) error
@@ -42,11 +56,15 @@ type tailer struct {
 	blockedMtx        sync.RWMutex
 	droppedStreams    []*logproto.DroppedStream
 	maxDroppedStreams int
+	maxBlockedPeriod  time.Duration
+
+	droppedEntries         prometheus.Counter
+	slowConsumerDisconnect prometheus.Counter
 
 	conn TailServer
 }
 
-func newTailer(orgID, query string, conn TailServer, maxDroppedStreams int) (*tailer, error) {
+func newTailer(orgID, query string, conn TailServer, cfg tailerConfig, metrics *ingesterMetrics) (*tailer, error) {
 	expr, err := syntax.ParseLogSelector(query, true)
 	if err != nil {
 		return nil, err
@@ -59,16 +77,26 @@ func newTailer(orgID, query string, conn TailServer, maxDroppedStreams int) (*ta
 	}
 	matchers := expr.Matchers()
 
+	if cfg.bufferSize <= 0 {
+		cfg.bufferSize = bufferSizeForTailResponse
+	}
+	if cfg.maxBlockedPeriod <= 0 {
+		cfg.maxBlockedPeriod = maxTailerBlockedPeriod
+	}
+
 	return &tailer{
-		orgID:             orgID,
-		matchers:          matchers,
-		sendChan:          make(chan *logproto.Stream, bufferSizeForTailResponse),
-		conn:              conn,
-		droppedStreams:    make([]*logproto.DroppedStream, 0, maxDroppedStreams),
-		maxDroppedStreams: maxDroppedStreams,
-		id:                generateUniqueID(orgID, query),
-		closeChan:         make(chan struct{}),
-		expr:              expr,
+		orgID:                  orgID,
+		matchers:               matchers,
+		sendChan:               make(chan *logproto.Stream, cfg.bufferSize),
+		conn:                   conn,
+		droppedStreams:         make([]*logproto.DroppedStream, 0, cfg.maxDroppedStreams),
+		maxDroppedStreams:      cfg.maxDroppedStreams,
+		maxBlockedPeriod:       cfg.maxBlockedPeriod,
+		droppedEntries:         metrics.tailerDroppedEntries.WithLabelValues(orgID),
+		slowConsumerDisconnect: metrics.tailerSlowConsumerDisconnect.WithLabelValues(orgID),
+		id:                     generateUniqueID(orgID, query),
+		closeChan:              make(chan struct{}),
+		expr:                   expr,
 	}, nil
 }
 
@@ -113,7 +141,9 @@ func (t *tailer) send(stream logproto.Stream, lbs labels.Labels) {
 
 	// if we are already dropping streams due to blocked connection, drop new streams directly to save some effort
 	if blockedSince := t.blockedSince(); blockedSince != nil {
-		if blockedSince.Before(time.Now().Add(-time.Second * 15)) {
+		if blockedSince.Before(time.Now().Add(-t.maxBlockedPeriod)) {
+			level.Warn(util_log.Logger).Log("msg", "disconnecting slow tail client", "org_id", t.orgID, "blocked_since", blockedSince)
+			t.slowConsumerDisconnect.Inc()
 			t.close()
 			return
 		}
@@ -152,7 +182,7 @@ func (t *tailer) processStream(stream logproto.Stream, lbs labels.Labels) []*log
 
 	sp := pipeline.ForStream(lbs)
 	for _, e := range stream.Entries {
-		newLine, parsedLbs, ok := sp.ProcessString(e.Timestamp.UnixNano(), e.Line)
+		newLine, parsedLbs, ok := sp.ProcessString(e.Timestamp.UnixNano(), e.Line, logproto.FromStructuredMetadataToLabels(e.StructuredMetadata)...)
 		if !ok {
 			continue
 		}
@@ -164,8 +194,9 @@ func (t *tailer) processStream(stream logproto.Stream, lbs labels.Labels) []*log
 			streams[parsedLbs.Hash()] = stream
 		}
 		stream.Entries = append(stream.Entries, logproto.Entry{
-			Timestamp: e.Timestamp,
-			Line:      newLine,
+			Timestamp:          e.Timestamp,
+			Line:               newLine,
+			StructuredMetadata: e.StructuredMetadata,
 		})
 	}
 	streamsResult := make([]*logproto.Stream, 0, len(streams))
@@ -217,6 +248,7 @@ func (t *tailer) dropStream(stream logproto.Stream) {
 	if len(stream.Entries) == 0 {
 		return
 	}
+	t.droppedEntries.Add(float64(len(stream.Entries)))
 
 	t.blockedMtx.Lock()
 	defer t.blockedMtx.Unlock()
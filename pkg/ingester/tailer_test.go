@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -26,7 +27,7 @@ func TestTailer_sendRaceConditionOnSendWhileClosing(t *testing.T) {
 	}
 
 	for run := 0; run < runs; run++ {
-		tailer, err := newTailer("org-id", stream.Labels, nil, 10)
+		tailer, err := newTailer("org-id", stream.Labels, nil, tailerConfig{maxDroppedStreams: 10}, NilMetrics)
 		require.NoError(t, err)
 		require.NotNil(t, tailer)
 
@@ -78,7 +79,7 @@ func Test_dropstream(t *testing.T) {
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			tail, err := newTailer("foo", `{app="foo"} |= "foo"`, &fakeTailServer{}, maxDroppedStreams)
+			tail, err := newTailer("foo", `{app="foo"} |= "foo"`, &fakeTailServer{}, tailerConfig{maxDroppedStreams: maxDroppedStreams}, NilMetrics)
 			require.NoError(t, err)
 
 			for i := 0; i < c.drop; i++ {
@@ -93,13 +94,40 @@ func Test_dropstream(t *testing.T) {
 	}
 }
 
+func Test_TailerSlowConsumer(t *testing.T) {
+	metrics := newIngesterMetrics(nil)
+	tail, err := newTailer("foo", `{app="foo"}`, &fakeTailServer{}, tailerConfig{
+		maxDroppedStreams: 10,
+		bufferSize:        1,
+		maxBlockedPeriod:  10 * time.Millisecond,
+	}, metrics)
+	require.NoError(t, err)
+
+	lbs := labels.Labels{{Name: "app", Value: "foo"}}
+	stream := logproto.Stream{
+		Labels:  lbs.String(),
+		Entries: []logproto.Entry{{Timestamp: time.Unix(0, 1), Line: "1"}, {Timestamp: time.Unix(0, 2), Line: "2"}},
+	}
+
+	// The loop is not running, so the second stream does not fit in the buffer.
+	tail.send(stream, lbs)
+	tail.send(stream, lbs)
+	require.False(t, tail.isClosed())
+	require.Equal(t, 2.0, testutil.ToFloat64(metrics.tailerDroppedEntries.WithLabelValues("foo")))
+
+	time.Sleep(20 * time.Millisecond)
+	tail.send(stream, lbs)
+	require.True(t, tail.isClosed())
+	require.Equal(t, 1.0, testutil.ToFloat64(metrics.tailerSlowConsumerDisconnect.WithLabelValues("foo")))
+}
+
 type fakeTailServer struct{}
 
 func (f *fakeTailServer) Send(*logproto.TailResponse) error { return nil }
 func (f *fakeTailServer) Context() context.Context          { return context.Background() }
 
 func Test_TailerSendRace(t *testing.T) {
-	tail, err := newTailer("foo", `{app="foo"} |= "foo"`, &fakeTailServer{}, 10)
+	tail, err := newTailer("foo", `{app="foo"} |= "foo"`, &fakeTailServer{}, tailerConfig{maxDroppedStreams: 10}, NilMetrics)
 	require.NoError(t, err)
 
 	var wg sync.WaitGroup
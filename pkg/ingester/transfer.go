@@ -185,6 +185,10 @@ func (i *Ingester) stopIncomingRequests() {
 
 // TransferOut implements ring.Lifecycler.
 func (i *Ingester) TransferOut(ctx context.Context) error {
+	if i.cfg.HandoverOnScaleDown && i.lifecycler.FlushOnShutdown() {
+		return i.handoverOut(ctx)
+	}
+
 	if i.cfg.MaxTransferRetries <= 0 {
 		return ring.ErrTransferDisabled
 	}
@@ -233,51 +237,8 @@ func (i *Ingester) transferOut(ctx context.Context) error {
 
 	for instanceID, inst := range i.instances {
 		err := inst.streams.ForEach(func(istream *stream) (bool, error) {
-			err = func() error {
-				istream.chunkMtx.Lock()
-				defer istream.chunkMtx.Unlock()
-				lbls := []*logproto.LabelPair{}
-				for _, lbl := range istream.labels {
-					lbls = append(lbls, &logproto.LabelPair{Name: lbl.Name, Value: lbl.Value})
-				}
-
-				// We moved to sending one chunk at a time in a stream instead of sending all chunks for a stream
-				// as large chunks can create large payloads of >16MB which can hit GRPC limits,
-				// typically streams won't have many chunks in memory so sending one at a time
-				// shouldn't add too much overhead.
-				for _, c := range istream.chunks {
-					// Close the chunk first, writing any data in the headblock to a new block.
-					err := c.chunk.Close()
-					if err != nil {
-						return err
-					}
-
-					bb, err := c.chunk.Bytes()
-					if err != nil {
-						return err
-					}
-
-					chunks := make([]*logproto.Chunk, 1)
-					chunks[0] = &logproto.Chunk{
-						Data: bb,
-					}
-
-					err = s.Send(&logproto.TimeSeriesChunk{
-						Chunks:         chunks,
-						UserId:         instanceID,
-						Labels:         lbls,
-						FromIngesterId: i.lifecycler.ID,
-					})
-					if err != nil {
-						level.Error(logger).Log("msg", "failed sending stream's chunks to ingester", "to_ingester", targetIngester.Addr, "err", err)
-						return err
-					}
-
-					sentChunks.Add(float64(len(chunks)))
-				}
-				return nil
-			}()
-			if err != nil {
+			if err := i.sendStreamChunks(instanceID, istream, s.Send); err != nil {
+				level.Error(logger).Log("msg", "failed sending stream's chunks to ingester", "to_ingester", targetIngester.Addr, "err", err)
 				return false, err
 			}
 			return true, nil
@@ -301,6 +262,51 @@ func (i *Ingester) transferOut(ctx context.Context) error {
 	return nil
 }
 
+// sendStreamChunks closes the chunks of the stream and sends them one at a time.
+func (i *Ingester) sendStreamChunks(instanceID string, istream *stream, send func(*logproto.TimeSeriesChunk) error) error {
+	istream.chunkMtx.Lock()
+	defer istream.chunkMtx.Unlock()
+	lbls := []*logproto.LabelPair{}
+	for _, lbl := range istream.labels {
+		lbls = append(lbls, &logproto.LabelPair{Name: lbl.Name, Value: lbl.Value})
+	}
+
+	// We moved to sending one chunk at a time in a stream instead of sending all chunks for a stream
+	// as large chunks can create large payloads of >16MB which can hit GRPC limits,
+	// typically streams won't have many chunks in memory so sending one at a time
+	// shouldn't add too much overhead.
+	for _, c := range istream.chunks {
+		// Close the chunk first, writing any data in the headblock to a new block.
+		err := c.chunk.Close()
+		if err != nil {
+			return err
+		}
+
+		bb, err := c.chunk.Bytes()
+		if err != nil {
+			return err
+		}
+
+		chunks := make([]*logproto.Chunk, 1)
+		chunks[0] = &logproto.Chunk{
+			Data: bb,
+		}
+
+		err = send(&logproto.TimeSeriesChunk{
+			Chunks:         chunks,
+			UserId:         instanceID,
+			Labels:         lbls,
+			FromIngesterId: i.lifecycler.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		sentChunks.Add(float64(len(chunks)))
+	}
+	return nil
+}
+
 // findTransferTarget finds an ingester in a PENDING state to use for transferring
 // chunks to.
 func (i *Ingester) findTransferTarget(ctx context.Context) (*ring.InstanceDesc, error) {
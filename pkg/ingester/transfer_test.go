@@ -10,8 +10,11 @@ import (
 	gokitlog "github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/grafana/dskit/kv"
+	"github.com/grafana/dskit/kv/consul"
 	"github.com/grafana/dskit/ring"
 	"github.com/grafana/dskit/services"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/weaveworks/common/user"
@@ -22,7 +25,9 @@ import (
 	"github.com/grafana/loki/pkg/ingester/client"
 	"github.com/grafana/loki/pkg/logproto"
 	"github.com/grafana/loki/pkg/logql/log"
+	loki_runtime "github.com/grafana/loki/pkg/runtime"
 	util_log "github.com/grafana/loki/pkg/util/log"
+	"github.com/grafana/loki/pkg/validation"
 )
 
 func TestTransferOut(t *testing.T) {
@@ -99,21 +104,150 @@ func TestTransferOut(t *testing.T) {
 	}
 }
 
+func TestHandoverOut(t *testing.T) {
+	f := newTestIngesterFactory(t)
+
+	ing := f.getIngester(time.Duration(0), t)
+	ing.cfg.MaxTransferRetries = 0
+	ing.cfg.HandoverOnScaleDown = true
+	ing.cfg.LifecyclerConfig.RingConfig.ReplicationFactor = 1
+	ing2 := f.getIngester(time.Duration(0), t)
+	defer services.StopAndAwaitTerminated(context.Background(), ing2) //nolint:errcheck
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	var streams []logproto.Stream
+	for n := 0; n < 10; n++ {
+		streams = append(streams, logproto.Stream{
+			Entries: []logproto.Entry{
+				{Line: "line 0", Timestamp: time.Unix(0, 0)},
+				{Line: "line 1", Timestamp: time.Unix(1, 0)},
+			},
+			Labels: fmt.Sprintf(`{bar="baz%d", foo="bar"}`, n),
+		})
+	}
+	_, err := ing.Push(ctx, &logproto.PushRequest{Streams: streams})
+	require.NoError(t, err)
+	_, err = ing2.Push(ctx, &logproto.PushRequest{Streams: []logproto.Stream{{
+		Entries: []logproto.Entry{{Line: "line 2", Timestamp: time.Unix(2, 0)}},
+		Labels:  `{bar="baz0", foo="bar"}`,
+	}}})
+	require.NoError(t, err)
+
+	// Stopping the ingester hands over the streams of its tokens to the only
+	// other ingester of the ring, and flushes the streams of the tokens of the
+	// other ingester, which already is their replica.
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), ing))
+
+	flushed := map[string]struct{}{}
+	for _, c := range f.stores[ing.lifecycler.Addr].getChunksForUser("test") {
+		flushed[c.Metric.String()] = struct{}{}
+	}
+	lines := map[string]int{}
+	_ = ing2.instances["test"].streams.ForEach(func(s *stream) (bool, error) {
+		it, err := s.Iterator(context.TODO(), nil, time.Unix(0, 0), time.Unix(10, 0), logproto.FORWARD, log.NewNoopPipeline().ForStream(s.labels))
+		require.NoError(t, err)
+		for it.Next() {
+			lines[s.labels.String()]++
+		}
+		return true, nil
+	})
+	for _, stream := range streams {
+		_, isFlushed := flushed[stream.Labels]
+		expected := 2
+		if isFlushed {
+			expected = 0
+		}
+		if stream.Labels == `{bar="baz0", foo="bar"}` {
+			expected++
+		}
+		require.Equal(t, expected, lines[stream.Labels], stream.Labels)
+	}
+}
+
+func TestPushHandedOver(t *testing.T) {
+	l := defaultLimitsTestConfig()
+	l.MaxLocalStreamsPerUser = 1
+	l.PerStreamRateLimit = 1
+	l.PerStreamRateLimitBurst = 1
+	l.DuplicateEntriesWindow = model.Duration(time.Minute)
+	limits, err := validation.NewOverrides(l, nil)
+	require.NoError(t, err)
+	limiter := NewLimiter(limits, NilMetrics, &ringCountMock{count: 1}, 1)
+
+	inst, err := newInstance(defaultConfig(), defaultPeriodConfigs, "test", limiter, loki_runtime.DefaultTenantConfigs(), noopWAL{}, NilMetrics, &OnceSwitch{}, nil, NewStreamRateCalculator(), nil)
+	require.NoError(t, err)
+
+	// The pushes of the tenant are limited to a single stream and a rate which rejects every entry.
+	require.Error(t, inst.Push(context.Background(), &logproto.PushRequest{Streams: []logproto.Stream{
+		{Labels: `{app="a"}`, Entries: []logproto.Entry{{Timestamp: time.Unix(10, 0), Line: "line 10"}}},
+	}}))
+
+	// The handed over entries bypass the limits of the pushes.
+	for _, app := range []string{"a", "b"} {
+		require.NoError(t, inst.pushHandedOver(context.Background(), labels.FromStrings("app", app), []logproto.Entry{
+			{Timestamp: time.Unix(1, 0), Line: "line 1"},
+			{Timestamp: time.Unix(2, 0), Line: "line 2"},
+		}))
+	}
+	require.Equal(t, 2, inst.numStreams())
+	_ = inst.streams.ForEach(func(s *stream) (bool, error) {
+		it, err := s.Iterator(context.Background(), nil, time.Unix(0, 0), time.Unix(100, 0), logproto.FORWARD, log.NewNoopPipeline().ForStream(s.labels))
+		require.NoError(t, err)
+		var lines int
+		for it.Next() {
+			lines++
+		}
+		require.Equal(t, 2, lines, s.labelsString)
+		return true, nil
+	})
+}
+
+func TestHandoverTargets(t *testing.T) {
+	targets := &handoverTargets{
+		tokens: []uint32{10, 20, 30, 40},
+		addrs:  []string{"self", "ing-1", "ing-2", "ing-3"},
+		self:   "self",
+	}
+
+	for _, tc := range []struct {
+		replicationFactor int
+		token             uint32
+		expected          string
+	}{
+		// the ingester joining the replication set of this ingester and the next ones
+		{replicationFactor: 1, token: 5, expected: "ing-1"},
+		{replicationFactor: 2, token: 5, expected: "ing-2"},
+		{replicationFactor: 3, token: 35, expected: "ing-2"},
+		// this ingester is not part of the replication set
+		{replicationFactor: 1, token: 15, expected: ""},
+		{replicationFactor: 2, token: 25, expected: ""},
+		// no ingester is left to join the replication set
+		{replicationFactor: 4, token: 5, expected: ""},
+	} {
+		targets.replicationFactor = tc.replicationFactor
+		require.Equal(t, tc.expected, targets.target(tc.token), "rf %d, token %d", tc.replicationFactor, tc.token)
+	}
+}
+
 type testIngesterFactory struct {
 	t         *testing.T
 	store     kv.Client
 	n         int
 	ingesters map[string]*Ingester
+	stores    map[string]*testStore
 }
 
 func newTestIngesterFactory(t *testing.T) *testIngesterFactory {
-	kvClient, err := kv.NewClient(kv.Config{Store: "inmemory"}, ring.GetCodec(), nil, gokitlog.NewNopLogger())
-	require.NoError(t, err)
+	// The "inmemory" KV store is shared by all clients, use a dedicated one to
+	// only see the ingesters of this factory in the ring.
+	kvClient, closer := consul.NewInMemoryClient(ring.GetCodec(), gokitlog.NewNopLogger(), nil)
+	t.Cleanup(func() { assert.NoError(t, closer.Close()) })
 
 	return &testIngesterFactory{
 		t:         t,
 		store:     kvClient,
 		ingesters: make(map[string]*Ingester),
+		stores:    make(map[string]*testStore),
 	}
 }
 
@@ -146,8 +280,9 @@ func (f *testIngesterFactory) getIngester(joinAfter time.Duration, t *testing.T)
 		}, nil
 	}
 
-	_, ing := newTestStore(f.t, cfg, nil)
+	store, ing := newTestStore(f.t, cfg, nil)
 	f.ingesters[fmt.Sprintf("%s:0", cfg.LifecyclerConfig.ID)] = ing
+	f.stores[fmt.Sprintf("%s:0", cfg.LifecyclerConfig.ID)] = store
 
 	// NB there's some kind of race condition with the in-memory KV client when
 	// we don't give the ingester a little bit of time to initialize. a 100ms
@@ -197,6 +332,21 @@ func (c *testIngesterClient) TransferChunks(context.Context, ...grpc.CallOption)
 	return client, nil
 }
 
+func (c *testIngesterClient) HandoverChunks(context.Context, ...grpc.CallOption) (logproto.Ingester_HandoverChunksClient, error) {
+	chunkCh := make(chan *logproto.TimeSeriesChunk)
+	respCh := make(chan *logproto.TransferChunksResponse)
+	waitCh := make(chan bool)
+	close(waitCh)
+
+	client := &testTransferChunksClient{ch: chunkCh, resp: respCh, wait: waitCh}
+	go func() {
+		server := &testTransferChunksServer{ch: chunkCh, resp: respCh}
+		err := c.i.HandoverChunks(server)
+		require.NoError(c.t, err)
+	}()
+	return client, nil
+}
+
 type testTransferChunksClient struct {
 	wait chan bool
 	ch   chan *logproto.TimeSeriesChunk
@@ -2,6 +2,8 @@ package ingester
 
 import (
 	"flag"
+	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -24,18 +26,21 @@ const walSegmentSize = wlog.DefaultSegmentSize * 4
 const defaultCeiling = 4 << 30 // 4GB
 
 type WALConfig struct {
-	Enabled             bool             `yaml:"enabled"`
-	Dir                 string           `yaml:"dir"`
-	CheckpointDuration  time.Duration    `yaml:"checkpoint_duration"`
-	FlushOnShutdown     bool             `yaml:"flush_on_shutdown"`
-	ReplayMemoryCeiling flagext.ByteSize `yaml:"replay_memory_ceiling"`
+	Enabled              bool             `yaml:"enabled"`
+	Dir                  string           `yaml:"dir"`
+	CheckpointDuration   time.Duration    `yaml:"checkpoint_duration"`
+	FlushOnShutdown      bool             `yaml:"flush_on_shutdown"`
+	CheckpointOnShutdown bool             `yaml:"checkpoint_on_shutdown"`
+	ReplayMemoryCeiling  flagext.ByteSize `yaml:"replay_memory_ceiling"`
+	Compression          string           `yaml:"compression"`
 }
 
 func (cfg *WALConfig) Validate() error {
 	if cfg.Enabled && cfg.CheckpointDuration < 1 {
 		return errors.Errorf("invalid checkpoint duration: %v", cfg.CheckpointDuration)
 	}
-	return nil
+	_, err := wal.ParseCompression(cfg.Compression)
+	return err
 }
 
 // RegisterFlags adds the flags required to config this to the given FlagSet
@@ -44,10 +49,12 @@ func (cfg *WALConfig) RegisterFlags(f *flag.FlagSet) {
 	f.BoolVar(&cfg.Enabled, "ingester.wal-enabled", true, "Enable writing of ingested data into WAL.")
 	f.DurationVar(&cfg.CheckpointDuration, "ingester.checkpoint-duration", 5*time.Minute, "Interval at which checkpoints should be created.")
 	f.BoolVar(&cfg.FlushOnShutdown, "ingester.flush-on-shutdown", false, "When WAL is enabled, should chunks be flushed to long-term storage on shutdown.")
+	f.BoolVar(&cfg.CheckpointOnShutdown, "ingester.wal-checkpoint-on-shutdown", false, "When WAL is enabled, write a checkpoint of the in-memory streams on shutdown, so that the ingester restarts from the checkpoint without replaying the WAL segments.")
 
 	// Need to set default here
 	cfg.ReplayMemoryCeiling = flagext.ByteSize(defaultCeiling)
 	f.Var(&cfg.ReplayMemoryCeiling, "ingester.wal-replay-memory-ceiling", "Maximum memory size the WAL may use during replay. After hitting this, it will flush data to storage before continuing. A unit suffix (KB, MB, GB) may be applied.")
+	f.StringVar(&cfg.Compression, "ingester.wal-compression", "none", fmt.Sprintf("Compression of the records written to the WAL. Supported values: %s. Segments with records of any compression can be replayed, but older versions of Loki cannot replay compressed records.", strings.Join(wal.SupportedCompressions, ", ")))
 }
 
 // WAL interface allows us to have a no-op WAL when the WAL is disabled.
@@ -66,10 +73,11 @@ func (noopWAL) Log(*wal.Record) error { return nil }
 func (noopWAL) Stop() error           { return nil }
 
 type walWrapper struct {
-	cfg        WALConfig
-	wal        *wlog.WL
-	metrics    *ingesterMetrics
-	seriesIter SeriesIter
+	cfg         WALConfig
+	compression wal.Compression
+	wal         *wlog.WL
+	metrics     *ingesterMetrics
+	seriesIter  SeriesIter
 
 	wait sync.WaitGroup
 	quit chan struct{}
@@ -81,17 +89,23 @@ func newWAL(cfg WALConfig, registerer prometheus.Registerer, metrics *ingesterMe
 		return noopWAL{}, nil
 	}
 
+	compression, err := wal.ParseCompression(cfg.Compression)
+	if err != nil {
+		return nil, err
+	}
+
 	tsdbWAL, err := wlog.NewSize(util_log.Logger, registerer, cfg.Dir, walSegmentSize, false)
 	if err != nil {
 		return nil, err
 	}
 
 	w := &walWrapper{
-		cfg:        cfg,
-		quit:       make(chan struct{}),
-		wal:        tsdbWAL,
-		metrics:    metrics,
-		seriesIter: seriesIter,
+		cfg:         cfg,
+		compression: compression,
+		quit:        make(chan struct{}),
+		wal:         tsdbWAL,
+		metrics:     metrics,
+		seriesIter:  seriesIter,
 	}
 
 	return w, nil
@@ -118,25 +132,37 @@ func (w *walWrapper) Log(record *wal.Record) error {
 		// Always write series then entries.
 		if len(record.Series) > 0 {
 			*buf = record.EncodeSeries(*buf)
-			if err := w.wal.Log(*buf); err != nil {
+			if err := w.log(*buf); err != nil {
 				return err
 			}
-			w.metrics.walRecordsLogged.Inc()
-			w.metrics.walLoggedBytesTotal.Add(float64(len(*buf)))
 			*buf = (*buf)[:0]
 		}
 		if len(record.RefEntries) > 0 {
 			*buf = record.EncodeEntries(wal.CurrentEntriesRec, *buf)
-			if err := w.wal.Log(*buf); err != nil {
+			if err := w.log(*buf); err != nil {
 				return err
 			}
-			w.metrics.walRecordsLogged.Inc()
-			w.metrics.walLoggedBytesTotal.Add(float64(len(*buf)))
 		}
 		return nil
 	}
 }
 
+// log writes the encoded record to the WAL, compressed with the configured codec.
+func (w *walWrapper) log(rec []byte) error {
+	if w.compression != wal.CompressionNone {
+		buf := recordPool.GetBytes()
+		defer recordPool.PutBytes(buf)
+		*buf = wal.CompressRecord(w.compression, rec, (*buf)[:0])
+		rec = *buf
+	}
+	if err := w.wal.Log(rec); err != nil {
+		return err
+	}
+	w.metrics.walRecordsLogged.Inc()
+	w.metrics.walLoggedBytesTotal.Add(float64(len(rec)))
+	return nil
+}
+
 func (w *walWrapper) Stop() error {
 	close(w.quit)
 	w.wait.Wait()
@@ -161,6 +187,7 @@ func (w *walWrapper) run() {
 		w.seriesIter,
 		w.checkpointWriter(),
 		w.metrics,
+		w.cfg.CheckpointOnShutdown,
 		w.quit,
 	)
 	checkpointer.Run()
@@ -0,0 +1,75 @@
+package wal
+
+import (
+	"fmt"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+// Compression is the codec of compressed WAL records.
+type Compression byte
+
+const (
+	CompressionNone Compression = iota
+	CompressionSnappy
+	CompressionZstd
+)
+
+// SupportedCompressions lists the names of the WAL record codecs.
+var SupportedCompressions = []string{"none", "snappy", "zstd"}
+
+// ParseCompression returns the codec with the given name.
+func ParseCompression(name string) (Compression, error) {
+	switch name {
+	case "", "none":
+		return CompressionNone, nil
+	case "snappy":
+		return CompressionSnappy, nil
+	case "zstd":
+		return CompressionZstd, nil
+	default:
+		return CompressionNone, fmt.Errorf("unsupported WAL compression %q, supported: %v", name, SupportedCompressions)
+	}
+}
+
+var (
+	// The zstd encoder and decoder are safe for concurrent use with EncodeAll and DecodeAll.
+	zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedFastest), zstd.WithEncoderConcurrency(1))
+	zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
+)
+
+// CompressRecord appends the encoded record rec compressed with the codec to b.
+// The compressed record has its own record type, so compressed and uncompressed
+// records can be mixed in the same segment.
+func CompressRecord(c Compression, rec, b []byte) []byte {
+	b = append(b, byte(WALRecordCompressed), byte(c))
+	switch c {
+	case CompressionSnappy:
+		n := len(b)
+		b = append(b, make([]byte, snappy.MaxEncodedLen(len(rec)))...)
+		return b[:n+len(snappy.Encode(b[n:], rec))]
+	case CompressionZstd:
+		return zstdEncoder.EncodeAll(rec, b)
+	default:
+		return append(b, rec...)
+	}
+}
+
+// decompressRecord returns the encoded record of the payload of a compressed record.
+func decompressRecord(b []byte) ([]byte, error) {
+	if len(b) < 1 {
+		return nil, errors.New("compressed record without codec")
+	}
+	switch c, payload := Compression(b[0]), b[1:]; c {
+	case CompressionNone:
+		return payload, nil
+	case CompressionSnappy:
+		return snappy.Decode(nil, payload)
+	case CompressionZstd:
+		return zstdDecoder.DecodeAll(payload, nil)
+	default:
+		return nil, errors.Errorf("unknown record compression %d", c)
+	}
+}
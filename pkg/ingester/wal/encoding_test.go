@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/push"
 )
 
 var (
@@ -132,6 +133,47 @@ func Test_Encoding_Entries(t *testing.T) {
 			},
 			version: WALRecordEntriesV2,
 		},
+		{
+			desc: "v3",
+			rec: &Record{
+				entryIndexMap: make(map[uint64]int),
+				UserID:        "123",
+				RefEntries: []RefEntries{
+					{
+						Ref:     456,
+						Counter: 1,
+						Entries: []logproto.Entry{
+							{
+								Timestamp: time.Unix(1000, 0),
+								Line:      "first",
+								StructuredMetadata: push.LabelsAdapter{
+									{Name: "traceID", Value: "123"},
+									{Name: "userID", Value: "a"},
+								},
+							},
+							{
+								Timestamp: time.Unix(2000, 0),
+								Line:      "second",
+							},
+						},
+					},
+					{
+						Ref:     789,
+						Counter: 2,
+						Entries: []logproto.Entry{
+							{
+								Timestamp: time.Unix(3000, 0),
+								Line:      "third",
+								StructuredMetadata: push.LabelsAdapter{
+									{Name: "traceID", Value: "456"},
+								},
+							},
+						},
+					},
+				},
+			},
+			version: WALRecordEntriesV3,
+		},
 	} {
 		decoded := recordPool.GetRecord()
 		buf := tc.rec.EncodeEntries(tc.version, nil)
@@ -206,3 +248,56 @@ func Benchmark_DecodeWAL(b *testing.B) {
 		require.NoError(b, DecodeRecord(buf, rec))
 	}
 }
+
+func Test_Encoding_Compressed(t *testing.T) {
+	rec := &Record{
+		entryIndexMap: make(map[uint64]int),
+		UserID:        "123",
+		Series: []record.RefSeries{
+			{
+				Ref:    456,
+				Labels: labels.FromStrings("foo", "bar"),
+			},
+		},
+		RefEntries: []RefEntries{
+			{
+				Ref:     456,
+				Counter: 1,
+				Entries: []logproto.Entry{
+					{
+						Timestamp:          time.Unix(1000, 0),
+						Line:               "first",
+						StructuredMetadata: push.LabelsAdapter{{Name: "traceID", Value: "123"}},
+					},
+					{
+						Timestamp: time.Unix(2000, 0),
+						Line:      "second",
+					},
+				},
+			},
+		},
+	}
+
+	for _, name := range SupportedCompressions {
+		t.Run(name, func(t *testing.T) {
+			c, err := ParseCompression(name)
+			require.NoError(t, err)
+
+			series := CompressRecord(c, rec.EncodeSeries(nil), nil)
+			require.Equal(t, WALRecordCompressed, RecordType(series[0]))
+			decoded := recordPool.GetRecord()
+			require.NoError(t, DecodeRecord(series, decoded))
+			require.Equal(t, rec.Series, decoded.Series)
+
+			entries := CompressRecord(c, rec.EncodeEntries(CurrentEntriesRec, nil), nil)
+			decoded = recordPool.GetRecord()
+			require.NoError(t, DecodeRecord(entries, decoded))
+			require.Equal(t, rec.UserID, decoded.UserID)
+			require.Equal(t, rec.RefEntries, decoded.RefEntries)
+		})
+	}
+
+	_, err := ParseCompression("lz4")
+	require.Error(t, err)
+	require.Error(t, DecodeRecord([]byte{byte(WALRecordCompressed), 42}, recordPool.GetRecord()))
+}
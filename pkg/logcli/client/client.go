@@ -31,6 +31,8 @@ const (
 	labelsPath        = "/loki/api/v1/labels"
 	labelValuesPath   = "/loki/api/v1/label/%s/values"
 	seriesPath        = "/loki/api/v1/series"
+	statsPath         = "/loki/api/v1/index/stats"
+	volumePath        = "/loki/api/v1/index/series_volume"
 	tailPath          = "/loki/api/v1/tail"
 	defaultAuthHeader = "Authorization"
 )
@@ -44,6 +46,8 @@ type Client interface {
 	ListLabelNames(quiet bool, start, end time.Time) (*loghttp.LabelResponse, error)
 	ListLabelValues(name string, quiet bool, start, end time.Time) (*loghttp.LabelResponse, error)
 	Series(matchers []string, start, end time.Time, quiet bool) (*loghttp.SeriesResponse, error)
+	GetStats(queryStr string, start, end time.Time, quiet bool) (*logproto.IndexStatsResponse, error)
+	GetVolume(queryStr string, start, end time.Time, limit int, quiet bool) (*logproto.VolumeResponse, error)
 	LiveTailQueryConn(queryStr string, delayFor time.Duration, limit int, start time.Time, quiet bool) (*websocket.Conn, error)
 	GetOrgID() string
 }
@@ -148,6 +152,35 @@ func (c *DefaultClient) Series(matchers []string, start, end time.Time, quiet bo
 	return &seriesResponse, nil
 }
 
+// GetStats uses the /loki/api/v1/index/stats endpoint to estimate the data matching the query
+func (c *DefaultClient) GetStats(queryStr string, start, end time.Time, quiet bool) (*logproto.IndexStatsResponse, error) {
+	params := util.NewQueryStringBuilder()
+	params.SetInt("start", start.UnixNano())
+	params.SetInt("end", end.UnixNano())
+	params.SetString("query", queryStr)
+
+	var statsResponse logproto.IndexStatsResponse
+	if err := c.doRequest(statsPath, params.Encode(), quiet, &statsResponse); err != nil {
+		return nil, err
+	}
+	return &statsResponse, nil
+}
+
+// GetVolume uses the /loki/api/v1/index/series_volume endpoint to rank the label sets matching the query by volume
+func (c *DefaultClient) GetVolume(queryStr string, start, end time.Time, limit int, quiet bool) (*logproto.VolumeResponse, error) {
+	params := util.NewQueryStringBuilder()
+	params.SetInt("start", start.UnixNano())
+	params.SetInt("end", end.UnixNano())
+	params.SetString("query", queryStr)
+	params.SetInt32("limit", limit)
+
+	var volumeResponse logproto.VolumeResponse
+	if err := c.doRequest(volumePath, params.Encode(), quiet, &volumeResponse); err != nil {
+		return nil, err
+	}
+	return &volumeResponse, nil
+}
+
 // LiveTailQueryConn uses /api/prom/tail to set up a websocket connection and returns it
 func (c *DefaultClient) LiveTailQueryConn(queryStr string, delayFor time.Duration, limit int, start time.Time, quiet bool) (*websocket.Conn, error) {
 	params := util.NewQueryStringBuilder()
@@ -174,6 +174,14 @@ func (f *FileClient) Series(_ []string, _, _ time.Time, _ bool) (*loghttp.Series
 	}, nil
 }
 
+func (f *FileClient) GetStats(_ string, _, _ time.Time, _ bool) (*logproto.IndexStatsResponse, error) {
+	return nil, fmt.Errorf("GetStats: %w", ErrNotSupported)
+}
+
+func (f *FileClient) GetVolume(_ string, _, _ time.Time, _ int, _ bool) (*logproto.VolumeResponse, error) {
+	return nil, fmt.Errorf("GetVolume: %w", ErrNotSupported)
+}
+
 func (f *FileClient) LiveTailQueryConn(_ string, _ time.Duration, _ int, _ time.Time, _ bool) (*websocket.Conn, error) {
 	return nil, fmt.Errorf("LiveTailQuery: %w", ErrNotSupported)
 }
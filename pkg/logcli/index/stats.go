@@ -0,0 +1,46 @@
+package index
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/grafana/loki/pkg/logcli/client"
+	"github.com/grafana/loki/pkg/logproto"
+)
+
+// StatsQuery contains all necessary fields to execute index stats queries and print out the results
+type StatsQuery struct {
+	QueryString string
+	Start       time.Time
+	End         time.Time
+	Quiet       bool
+}
+
+// DoStats prints out the estimated streams, chunks, entries and bytes matching the query
+func (q *StatsQuery) DoStats(c client.Client) {
+	printStats(os.Stdout, q.GetStats(c))
+}
+
+// GetStats returns the index stats of the query
+func (q *StatsQuery) GetStats(c client.Client) *logproto.IndexStatsResponse {
+	statsResponse, err := c.GetStats(q.QueryString, q.Start, q.End, q.Quiet)
+	if err != nil {
+		log.Fatalf("Error doing request: %+v", err)
+	}
+	return statsResponse
+}
+
+func printStats(out io.Writer, stats *logproto.IndexStatsResponse) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Streams:\t%d\n", stats.Streams)
+	fmt.Fprintf(w, "Chunks:\t%d\n", stats.Chunks)
+	fmt.Fprintf(w, "Entries:\t%d\n", stats.Entries)
+	fmt.Fprintf(w, "Bytes:\t%s\n", humanize.Bytes(stats.Bytes))
+	w.Flush()
+}
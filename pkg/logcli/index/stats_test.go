@@ -0,0 +1,20 @@
+package index
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/logproto"
+)
+
+func TestPrintStats(t *testing.T) {
+	var out bytes.Buffer
+	printStats(&out, &logproto.IndexStatsResponse{Streams: 2, Chunks: 12, Entries: 184732, Bytes: 53 << 20})
+	require.Equal(t, `Streams:  2
+Chunks:   12
+Entries:  184732
+Bytes:    56 MB
+`, out.String())
+}
@@ -0,0 +1,47 @@
+package index
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/grafana/loki/pkg/logcli/client"
+	"github.com/grafana/loki/pkg/logproto"
+)
+
+// VolumeQuery contains all necessary fields to execute series volume queries and print out the results
+type VolumeQuery struct {
+	QueryString string
+	Start       time.Time
+	End         time.Time
+	Limit       int
+	Quiet       bool
+}
+
+// DoVolume prints out the label sets matching the query, ranked by volume
+func (q *VolumeQuery) DoVolume(c client.Client) {
+	printVolumes(os.Stdout, q.GetVolume(c))
+}
+
+// GetVolume returns the volumes of the label sets matching the query
+func (q *VolumeQuery) GetVolume(c client.Client) *logproto.VolumeResponse {
+	volumeResponse, err := c.GetVolume(q.QueryString, q.Start, q.End, q.Limit, q.Quiet)
+	if err != nil {
+		log.Fatalf("Error doing request: %+v", err)
+	}
+	return volumeResponse
+}
+
+func printVolumes(out io.Writer, resp *logproto.VolumeResponse) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Volume\tLabels\n")
+	for _, v := range resp.Volumes {
+		fmt.Fprintf(w, "%s\t%s\n", humanize.Bytes(v.Volume), v.Name)
+	}
+	w.Flush()
+}
@@ -0,0 +1,25 @@
+package index
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/logproto"
+)
+
+func TestPrintVolumes(t *testing.T) {
+	var out bytes.Buffer
+	printVolumes(&out, &logproto.VolumeResponse{
+		Volumes: []logproto.Volume{
+			{Name: `{app="api"}`, Volume: 700 << 20},
+			{Name: `{app="web"}`, Volume: 50 << 20},
+		},
+		Limit: 100,
+	})
+	require.Equal(t, `Volume  Labels
+734 MB  {app="api"}
+52 MB   {app="web"}
+`, out.String())
+}
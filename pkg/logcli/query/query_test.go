@@ -602,6 +602,14 @@ func (t *testQueryClient) Series(_ []string, _, _ time.Time, _ bool) (*loghttp.S
 	panic("implement me")
 }
 
+func (t *testQueryClient) GetStats(_ string, _, _ time.Time, _ bool) (*logproto.IndexStatsResponse, error) {
+	panic("implement me")
+}
+
+func (t *testQueryClient) GetVolume(_ string, _, _ time.Time, _ int, _ bool) (*logproto.VolumeResponse, error) {
+	panic("implement me")
+}
+
 func (t *testQueryClient) LiveTailQueryConn(_ string, _ time.Duration, _ int, _ time.Time, _ bool) (*websocket.Conn, error) {
 	panic("implement me")
 }
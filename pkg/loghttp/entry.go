@@ -8,6 +8,7 @@ import (
 	"github.com/buger/jsonparser"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/modern-go/reflect2"
+	"github.com/prometheus/prometheus/model/labels"
 )
 
 func init() {
@@ -15,9 +16,12 @@ func init() {
 }
 
 // Entry represents a log entry.  It includes a log message and the time it occurred at.
+// The optional structured metadata is sent as a third element of the array:
+// [ "<ts>", "<line>", { "<name>": "<value>" } ]
 type Entry struct {
-	Timestamp time.Time
-	Line      string
+	Timestamp          time.Time
+	Line               string
+	StructuredMetadata labels.Labels
 }
 
 func (e *Entry) UnmarshalJSON(data []byte) error {
@@ -26,8 +30,12 @@ func (e *Entry) UnmarshalJSON(data []byte) error {
 		parseError error
 	)
 	_, err := jsonparser.ArrayEach(data, func(value []byte, t jsonparser.ValueType, _ int, _ error) {
-		// assert that both items in array are of type string
-		if t != jsonparser.String {
+		if parseError != nil {
+			return
+		}
+		// assert that the timestamp and line are of type string
+		// and the structured metadata is an object of strings.
+		if (i < 2 && t != jsonparser.String) || (i == 2 && t != jsonparser.Object) {
 			parseError = jsonparser.MalformedStringError
 			return
 		}
@@ -46,6 +54,24 @@ func (e *Entry) UnmarshalJSON(data []byte) error {
 				return
 			}
 			e.Line = v
+		case 2: // structured metadata
+			parseError = jsonparser.ObjectEach(value, func(key, val []byte, dataType jsonparser.ValueType, _ int) error {
+				if dataType != jsonparser.String {
+					return jsonparser.MalformedStringError
+				}
+				name, err := jsonparser.ParseString(key)
+				if err != nil {
+					return err
+				}
+				v, err := jsonparser.ParseString(val)
+				if err != nil {
+					return err
+				}
+				e.StructuredMetadata = append(e.StructuredMetadata, labels.Label{Name: name, Value: v})
+				return nil
+			})
+		default:
+			parseError = jsonparser.MalformedArrayError
 		}
 		i++
 	})
@@ -67,6 +93,7 @@ func (sliceEntryDecoder) Decode(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
 		i := 0
 		var ts time.Time
 		var line string
+		var structuredMetadata labels.Labels
 		ok := iter.ReadArrayCB(func(iter *jsoniter.Iterator) bool {
 			var ok bool
 			switch i {
@@ -81,15 +108,23 @@ func (sliceEntryDecoder) Decode(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
 					return false
 				}
 				return true
+			case 2:
+				iter.ReadMapCB(func(iter *jsoniter.Iterator, name string) bool {
+					structuredMetadata = append(structuredMetadata, labels.Label{Name: name, Value: iter.ReadString()})
+					return iter.Error == nil
+				})
+				i++
+				return iter.Error == nil
 			default:
-				iter.ReportError("error reading entry", "array must contains 2 values")
+				iter.ReportError("error reading entry", "array must have at least 2 and up to 3 values")
 				return false
 			}
 		})
 		if ok {
 			*((*[]Entry)(ptr)) = append(*((*[]Entry)(ptr)), Entry{
-				Timestamp: ts,
-				Line:      line,
+				Timestamp:          ts,
+				Line:               line,
+				StructuredMetadata: structuredMetadata,
 			})
 			return true
 		}
@@ -126,6 +161,18 @@ func (EntryEncoder) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
 	stream.WriteRaw(`"`)
 	stream.WriteMore()
 	stream.WriteStringWithHTMLEscaped(e.Line)
+	if len(e.StructuredMetadata) > 0 {
+		stream.WriteMore()
+		stream.WriteObjectStart()
+		for i, l := range e.StructuredMetadata {
+			if i > 0 {
+				stream.WriteMore()
+			}
+			stream.WriteObjectField(l.Name)
+			stream.WriteStringWithHTMLEscaped(l.Value)
+		}
+		stream.WriteObjectEnd()
+	}
 	stream.WriteArrayEnd()
 }
 
@@ -8,11 +8,13 @@ import (
 	"math"
 	"mime"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/dustin/go-humanize"
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	"github.com/klauspost/compress/zstd"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/prometheus/model/labels"
@@ -30,6 +32,7 @@ import (
 var (
 	contentType   = http.CanonicalHeaderKey("Content-Type")
 	contentEnc    = http.CanonicalHeaderKey("Content-Encoding")
+	acceptEnc     = http.CanonicalHeaderKey("Accept-Encoding")
 	bytesIngested = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "loki",
 		Name:      "distributor_bytes_received_total",
@@ -47,13 +50,53 @@ var (
 
 const applicationJSON = "application/json"
 
+// SupportedContentEncodings are the content encodings accepted for the body of push requests.
+var SupportedContentEncodings = []string{"snappy", "gzip", "deflate", "zstd"}
+
+// UnsupportedContentEncodingError is returned for push requests with an unsupported Content-Encoding.
+type UnsupportedContentEncodingError string
+
+func (e UnsupportedContentEncodingError) Error() string {
+	return fmt.Sprintf("Content-Encoding %q not supported", string(e))
+}
+
+// AdvertiseContentEncodings sets the Accept-Encoding response header to the supported content encodings.
+func AdvertiseContentEncodings(w http.ResponseWriter) {
+	w.Header().Set(acceptEnc, strings.Join(SupportedContentEncodings, ", "))
+}
+
+// maxSizeReader fails reads once more than max bytes have been read.
+type maxSizeReader struct {
+	r         io.Reader
+	read, max int64
+	err       error
+}
+
+func (m *maxSizeReader) Read(p []byte) (int, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+	n, err := m.r.Read(p)
+	m.read += int64(n)
+	if m.read > m.max {
+		// Only return the bytes within the limit, so the body can't be decoded past it.
+		m.err = fmt.Errorf("decompressed push request body exceeds the limit of %s", humanize.Bytes(uint64(m.max)))
+		return n - int(m.read-m.max), m.err
+	}
+	return n, err
+}
+
 type TenantsRetention interface {
 	RetentionPeriodFor(userID string, lbs labels.Labels) time.Duration
 }
 
-func ParseRequest(logger log.Logger, userID string, r *http.Request, tenantsRetention TenantsRetention) (*logproto.PushRequest, error) {
+// ParseRequest parses the push request from the HTTP request.
+// The size of the decompressed body is limited to maxDecompressedSize bytes, 0 means no limit.
+func ParseRequest(logger log.Logger, userID string, r *http.Request, tenantsRetention TenantsRetention, maxDecompressedSize int) (*logproto.PushRequest, error) {
 	// Body
 	var body io.Reader
+	// The protobuf body is snappy-compressed unless the Content-Encoding is zstd.
+	protoCompression := util.RawSnappy
 	// bodySize should always reflect the compressed size of the request body
 	bodySize := loki_util.NewSizeReader(r.Body)
 	contentEncoding := r.Header.Get(contentEnc)
@@ -76,8 +119,22 @@ func ParseRequest(logger log.Logger, userID string, r *http.Request, tenantsRete
 		flateReader := flate.NewReader(bodySize)
 		defer flateReader.Close()
 		body = flateReader
+	case "zstd":
+		zstdReader, err := zstd.NewReader(bodySize, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		defer zstdReader.Close()
+		body = zstdReader
+		protoCompression = util.NoCompression
 	default:
-		return nil, fmt.Errorf("Content-Encoding %q not supported", contentEncoding)
+		return nil, UnsupportedContentEncodingError(contentEncoding)
+	}
+
+	maxSize := math.MaxInt32
+	if maxDecompressedSize > 0 {
+		maxSize = maxDecompressedSize
+		body = &maxSizeReader{r: body, max: int64(maxDecompressedSize)}
 	}
 
 	contentType := r.Header.Get(contentType)
@@ -112,8 +169,9 @@ func ParseRequest(logger log.Logger, userID string, r *http.Request, tenantsRete
 
 	default:
 		// When no content-type header is set or when it is set to
-		// `application/x-protobuf`: expect snappy compression.
-		if err := util.ParseProtoReader(r.Context(), body, int(r.ContentLength), math.MaxInt32, &req, util.RawSnappy); err != nil {
+		// `application/x-protobuf`: expect snappy compression, unless
+		// the body is zstd-encoded.
+		if err := util.ParseProtoReader(r.Context(), body, int(r.ContentLength), maxSize, &req, protoCompression); err != nil {
 			return nil, err
 		}
 	}
@@ -9,8 +9,11 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/loki/pkg/logproto"
 	util_log "github.com/grafana/loki/pkg/util/log"
 )
 
@@ -40,6 +43,16 @@ func deflateString(source string) string {
 	return buf.String()
 }
 
+// Zstd source string and return compressed string
+func zstdString(source string) string {
+	zw, err := zstd.NewWriter(nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer zw.Close()
+	return string(zw.EncodeAll([]byte(source), nil))
+}
+
 func TestParseRequest(t *testing.T) {
 	tests := []struct {
 		path            string
@@ -157,6 +170,20 @@ func TestParseRequest(t *testing.T) {
 			contentEncoding: `deflate`,
 			valid:           false,
 		},
+		{
+			path:            `/loki/api/v1/push`,
+			body:            zstdString(`{"streams": [{ "stream": { "foo": "bar2" }, "values": [ [ "1570818238000000000", "fizzbuzz" ] ] }]}`),
+			contentType:     `application/json`,
+			contentEncoding: `zstd`,
+			valid:           true,
+		},
+		{
+			path:            `/loki/api/v1/push`,
+			body:            zstdString(`{"streams": [{ "stream": { "foo": "bar2" }, "values": [ [ "1570818238000000000", "fizzbuzz" ] ] }]}`),
+			contentType:     `application/json`,
+			contentEncoding: `br`,
+			valid:           false,
+		},
 	}
 
 	// Testing input array
@@ -168,7 +195,7 @@ func TestParseRequest(t *testing.T) {
 		if len(test.contentEncoding) > 0 {
 			request.Header.Add("Content-Encoding", test.contentEncoding)
 		}
-		data, err := ParseRequest(util_log.Logger, "", request, nil)
+		data, err := ParseRequest(util_log.Logger, "", request, nil, 0)
 		if test.valid {
 			assert.Nil(t, err, "Should not give error for %d", index)
 			assert.NotNil(t, data, "Should give data for %d", index)
@@ -178,3 +205,42 @@ func TestParseRequest(t *testing.T) {
 		}
 	}
 }
+
+func TestParseRequest_ZstdProtobuf(t *testing.T) {
+	req := logproto.PushRequest{Streams: []logproto.Stream{{Labels: `{foo="bar"}`, Entries: []logproto.Entry{{Line: "fizzbuzz"}}}}}
+	buf, err := req.Marshal()
+	require.NoError(t, err)
+
+	request := httptest.NewRequest("POST", `/loki/api/v1/push`, strings.NewReader(zstdString(string(buf))))
+	request.Header.Add("Content-Type", "application/x-protobuf")
+	request.Header.Add("Content-Encoding", "zstd")
+	data, err := ParseRequest(util_log.Logger, "", request, nil, 0)
+	require.NoError(t, err)
+	require.Equal(t, req.Streams[0].Labels, data.Streams[0].Labels)
+	require.Equal(t, req.Streams[0].Entries[0].Line, data.Streams[0].Entries[0].Line)
+}
+
+func TestParseRequest_MaxDecompressedSize(t *testing.T) {
+	body := `{"streams": [{ "stream": { "foo": "bar2" }, "values": [ [ "1570818238000000000", "fizzbuzz" ] ] }]}`
+	for _, contentEncoding := range []string{"", "gzip", "zstd"} {
+		encoded := body
+		switch contentEncoding {
+		case "gzip":
+			encoded = gzipString(body)
+		case "zstd":
+			encoded = zstdString(body)
+		}
+
+		request := httptest.NewRequest("POST", `/loki/api/v1/push`, strings.NewReader(encoded))
+		request.Header.Add("Content-Type", "application/json")
+		request.Header.Add("Content-Encoding", contentEncoding)
+		_, err := ParseRequest(util_log.Logger, "", request, nil, len(body)-1)
+		require.Error(t, err, contentEncoding)
+
+		request = httptest.NewRequest("POST", `/loki/api/v1/push`, strings.NewReader(encoded))
+		request.Header.Add("Content-Type", "application/json")
+		request.Header.Add("Content-Encoding", contentEncoding)
+		_, err = ParseRequest(util_log.Logger, "", request, nil, len(body))
+		require.NoError(t, err, contentEncoding)
+	}
+}
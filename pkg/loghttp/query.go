@@ -33,6 +33,10 @@ const (
 	QueryStatusFail    = "fail"
 )
 
+// StreamingContentType is the content type of the streaming responses of the log range queries,
+// with a JSON line per stream of a split of the query, requested by its Accept header.
+const StreamingContentType = "application/x-ndjson"
+
 // QueryResponse represents the http json response to a Loki range and instant query
 type QueryResponse struct {
 	Status string            `json:"status"`
@@ -8,10 +8,12 @@ import (
 	"time"
 
 	jsoniter "github.com/json-iterator/go"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/loki/pkg/logproto"
 	"github.com/grafana/loki/pkg/logqlmodel/stats"
+	"github.com/grafana/loki/pkg/push"
 )
 
 func TestParseRangeQuery(t *testing.T) {
@@ -160,7 +162,7 @@ func TestStreams_ToProto(t *testing.T) {
 					Labels: map[string]string{"foo": "bar", "lvl": "error"},
 					Entries: []Entry{
 						{Timestamp: time.Unix(0, 3), Line: "3"},
-						{Timestamp: time.Unix(0, 4), Line: "4"},
+						{Timestamp: time.Unix(0, 4), Line: "4", StructuredMetadata: labels.Labels{{Name: "traceID", Value: "123"}}},
 					},
 				},
 			},
@@ -176,7 +178,7 @@ func TestStreams_ToProto(t *testing.T) {
 					Labels: `{foo="bar", lvl="error"}`,
 					Entries: []logproto.Entry{
 						{Timestamp: time.Unix(0, 3), Line: "3"},
-						{Timestamp: time.Unix(0, 4), Line: "4"},
+						{Timestamp: time.Unix(0, 4), Line: "4", StructuredMetadata: push.LabelsAdapter{{Name: "traceID", Value: "123"}}},
 					},
 				},
 			},
@@ -230,7 +232,7 @@ func Test_QueryResponseUnmarshal(t *testing.T) {
 						Labels: LabelSet{"foo": "bar"},
 						Entries: []Entry{
 							{Timestamp: time.Unix(0, 1), Line: "log line 1"},
-							{Timestamp: time.Unix(0, 2), Line: "some log line 2"},
+							{Timestamp: time.Unix(0, 2), Line: "some log line 2", StructuredMetadata: labels.Labels{{Name: "traceID", Value: "123"}, {Name: "user", Value: "a"}}},
 						},
 					},
 					Stream{
@@ -17,6 +17,7 @@ import (
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/timestamp"
 
+	"github.com/grafana/loki/pkg/push"
 	"github.com/grafana/loki/pkg/querier/queryrange/queryrangebase/definitions"
 	"github.com/grafana/loki/pkg/util"
 )
@@ -57,6 +58,18 @@ func FromLabelsToLabelAdapters(ls labels.Labels) []LabelAdapter {
 	return *(*[]LabelAdapter)(unsafe.Pointer(&ls))
 }
 
+// FromStructuredMetadataToLabels casts the structured metadata of an entry to labels.Labels.
+// It uses unsafe, but as push.LabelAdapter == labels.Label this should be safe.
+func FromStructuredMetadataToLabels(ls push.LabelsAdapter) labels.Labels {
+	return *(*labels.Labels)(unsafe.Pointer(&ls))
+}
+
+// FromLabelsToStructuredMetadata casts labels.Labels to the structured metadata of an entry.
+// It uses unsafe, but as push.LabelAdapter == labels.Label this should be safe.
+func FromLabelsToStructuredMetadata(ls labels.Labels) push.LabelsAdapter {
+	return *(*push.LabelsAdapter)(unsafe.Pointer(&ls))
+}
+
 // FromLabelAdaptersToMetric converts []LabelAdapter to a model.Metric.
 // Don't do this on any performance sensitive paths.
 func FromLabelAdaptersToMetric(ls []LabelAdapter) model.Metric {
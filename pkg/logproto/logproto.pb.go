@@ -2501,145 +2501,146 @@ func init() {
 func init() { proto.RegisterFile("pkg/logproto/logproto.proto", fileDescriptor_c28a5f14f1f4c79a) }
 
 var fileDescriptor_c28a5f14f1f4c79a = []byte{
-	// 2205 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xd4, 0x19, 0x4d, 0x8f, 0x1b, 0x49,
-	0xd5, 0x65, 0xb7, 0xbf, 0x9e, 0xed, 0x19, 0xa7, 0xc6, 0x9b, 0x18, 0x27, 0xb1, 0x27, 0xad, 0x25,
-	0x19, 0x25, 0x59, 0x7b, 0x33, 0x0b, 0x4b, 0x36, 0x61, 0x41, 0xf1, 0xcc, 0x26, 0x3b, 0xf9, 0xde,
-	0x9a, 0x10, 0xd0, 0x8a, 0x55, 0xd4, 0x63, 0x97, 0x3f, 0x34, 0x6e, 0xb7, 0xd3, 0xdd, 0xde, 0xec,
-	0x48, 0x1c, 0xf8, 0x03, 0x2b, 0xed, 0x0d, 0x71, 0x41, 0x1c, 0x90, 0x40, 0x48, 0x5c, 0xf8, 0x01,
-	0xc0, 0x01, 0x89, 0x70, 0x0b, 0xb7, 0x15, 0x07, 0x43, 0x26, 0x17, 0x34, 0xa7, 0xfd, 0x03, 0x20,
-	0x54, 0x5f, 0xdd, 0xd5, 0x3d, 0xce, 0xee, 0x3a, 0x44, 0x42, 0xb9, 0x8c, 0xfb, 0xbd, 0x7a, 0xf5,
-	0xea, 0x7d, 0xbf, 0x7a, 0x35, 0x70, 0x7c, 0xb2, 0xdb, 0x6f, 0x8d, 0x9c, 0xfe, 0xc4, 0x75, 0x7c,
-	0x27, 0xf8, 0x68, 0xf2, 0xbf, 0x38, 0xa7, 0xe0, 0x5a, 0xa5, 0xef, 0xf4, 0x1d, 0x41, 0xc3, 0xbe,
-	0xc4, 0x7a, 0xad, 0xd1, 0x77, 0x9c, 0xfe, 0x88, 0xb6, 0x38, 0xb4, 0x33, 0xed, 0xb5, 0xfc, 0xa1,
-	0x4d, 0x3d, 0xdf, 0xb2, 0x27, 0x92, 0x60, 0x55, 0x72, 0x7f, 0x38, 0xb2, 0x9d, 0x2e, 0x1d, 0xb5,
-	0x3c, 0xdf, 0xf2, 0x3d, 0xf1, 0x57, 0x52, 0xac, 0x30, 0x8a, 0xc9, 0xd4, 0x1b, 0xf0, 0x3f, 0x02,
-	0x69, 0x56, 0x00, 0x6f, 0xfb, 0x2e, 0xb5, 0x6c, 0x62, 0xf9, 0xd4, 0x23, 0xf4, 0xe1, 0x94, 0x7a,
-	0xbe, 0x79, 0x0b, 0x56, 0x22, 0x58, 0x6f, 0xe2, 0x8c, 0x3d, 0x8a, 0xdf, 0x86, 0x82, 0x17, 0xa2,
-	0xab, 0x68, 0x35, 0xb5, 0x56, 0x58, 0xaf, 0x34, 0x03, 0x55, 0xc2, 0x3d, 0x44, 0x27, 0x34, 0x7f,
-	0x81, 0x00, 0xc2, 0x35, 0x5c, 0x07, 0x10, 0xab, 0xef, 0x5b, 0xde, 0xa0, 0x8a, 0x56, 0xd1, 0x9a,
-	0x41, 0x34, 0x0c, 0x3e, 0x0f, 0x47, 0x42, 0xe8, 0xb6, 0xb3, 0x3d, 0xb0, 0xdc, 0x6e, 0x35, 0xc9,
-	0xc9, 0x0e, 0x2f, 0x60, 0x0c, 0x86, 0x6b, 0xf9, 0xb4, 0x9a, 0x5a, 0x45, 0x6b, 0x29, 0xc2, 0xbf,
-	0xf1, 0x51, 0xc8, 0xf8, 0x74, 0x6c, 0x8d, 0xfd, 0xaa, 0xb1, 0x8a, 0xd6, 0xf2, 0x44, 0x42, 0x0c,
-	0xcf, 0x74, 0xa7, 0x5e, 0x35, 0xbd, 0x8a, 0xd6, 0x4a, 0x44, 0x42, 0xe6, 0x5f, 0x92, 0x50, 0xfc,
-	0x60, 0x4a, 0xdd, 0x3d, 0x69, 0x00, 0x5c, 0x83, 0x9c, 0x47, 0x47, 0xb4, 0xe3, 0x3b, 0x2e, 0x17,
-	0x30, 0x4f, 0x02, 0x18, 0x57, 0x20, 0x3d, 0x1a, 0xda, 0x43, 0x9f, 0x8b, 0x54, 0x22, 0x02, 0xc0,
-	0x97, 0x20, 0xed, 0xf9, 0x96, 0xeb, 0x73, 0x39, 0x0a, 0xeb, 0xb5, 0xa6, 0x70, 0x58, 0x53, 0x39,
-	0xac, 0x79, 0x4f, 0x39, 0xac, 0x9d, 0x7b, 0x3c, 0x6b, 0x24, 0x3e, 0xfb, 0x47, 0x03, 0x11, 0xb1,
-	0x05, 0xbf, 0x0d, 0x29, 0x3a, 0xee, 0x72, 0x59, 0xbf, 0xee, 0x4e, 0xb6, 0x01, 0x5f, 0x80, 0x7c,
-	0x77, 0xe8, 0xd2, 0x8e, 0x3f, 0x74, 0xc6, 0x5c, 0xa3, 0xa5, 0xf5, 0x95, 0xd0, 0x1b, 0x9b, 0x6a,
-	0x89, 0x84, 0x54, 0xf8, 0x3c, 0x64, 0x3c, 0x66, 0x36, 0xaf, 0x9a, 0x5d, 0x4d, 0xad, 0xe5, 0xdb,
-	0x95, 0x83, 0x59, 0xa3, 0x2c, 0x30, 0xe7, 0x1d, 0x7b, 0xe8, 0x53, 0x7b, 0xe2, 0xef, 0x11, 0x49,
-	0x83, 0xcf, 0x42, 0xb6, 0x4b, 0x47, 0x94, 0x39, 0x3b, 0xc7, 0x9d, 0x5d, 0xd6, 0xd8, 0xf3, 0x05,
-	0xa2, 0x08, 0xae, 0x1b, 0xb9, 0x4c, 0x39, 0x6b, 0xfe, 0x07, 0x01, 0xde, 0xb6, 0xec, 0xc9, 0x88,
-	0x7e, 0x6d, 0x7b, 0x06, 0x96, 0x4b, 0xbe, 0xb0, 0xe5, 0x52, 0x8b, 0x5a, 0x2e, 0x34, 0x83, 0xb1,
-	0x98, 0x19, 0xd2, 0x5f, 0x61, 0x06, 0xf3, 0x26, 0x64, 0x04, 0xea, 0xab, 0x62, 0x28, 0xd4, 0x39,
-	0xa5, 0xb4, 0x29, 0x87, 0xda, 0xa4, 0xb8, 0x9c, 0xe6, 0x2f, 0x11, 0x94, 0xa4, 0x21, 0x65, 0x0e,
-	0xee, 0x40, 0x56, 0xe4, 0x80, 0xca, 0xbf, 0x63, 0xf1, 0xfc, 0xbb, 0xd2, 0xb5, 0x26, 0x3e, 0x75,
-	0xdb, 0xad, 0xc7, 0xb3, 0x06, 0xfa, 0xfb, 0xac, 0x71, 0xa6, 0x3f, 0xf4, 0x07, 0xd3, 0x9d, 0x66,
-	0xc7, 0xb1, 0x5b, 0x7d, 0xd7, 0xea, 0x59, 0x63, 0xab, 0x35, 0x72, 0x76, 0x87, 0x2d, 0x55, 0x0f,
-	0x54, 0xde, 0x2a, 0xc6, 0xf8, 0x1c, 0x97, 0xce, 0xf7, 0xa4, 0x47, 0x96, 0x9b, 0xa2, 0x8c, 0x6c,
-	0x8d, 0xfb, 0xd4, 0x63, 0x9c, 0x0d, 0x66, 0x4c, 0x22, 0x68, 0xcc, 0x9f, 0xc0, 0x4a, 0xc4, 0xe1,
-	0x52, 0xce, 0x8b, 0x90, 0xf1, 0xa8, 0x3b, 0x0c, 0xca, 0x84, 0x66, 0xb2, 0x6d, 0x8e, 0x6f, 0x2f,
-	0x49, 0xf9, 0x32, 0x02, 0x26, 0x92, 0x7e, 0xb1, 0xd3, 0xff, 0x8c, 0xa0, 0x78, 0xd3, 0xda, 0xa1,
-	0x23, 0x15, 0x69, 0x18, 0x8c, 0xb1, 0x65, 0x53, 0x69, 0x71, 0xfe, 0xcd, 0xd2, 0xfe, 0x63, 0x6b,
-	0x34, 0xa5, 0x82, 0x65, 0x8e, 0x48, 0x68, 0xd1, 0x9c, 0x45, 0x2f, 0x9c, 0xb3, 0x28, 0x8c, 0xbc,
-	0x0a, 0xa4, 0x1f, 0x32, 0x43, 0xf1, 0x7c, 0xcd, 0x13, 0x01, 0x98, 0x67, 0xa0, 0x24, 0xb5, 0x90,
-	0xe6, 0x0b, 0x45, 0x66, 0xe6, 0xcb, 0x2b, 0x91, 0x4d, 0x1b, 0x32, 0xc2, 0xda, 0xf8, 0x75, 0xc8,
-	0x07, 0x3d, 0x80, 0x6b, 0x9b, 0x6a, 0x67, 0x0e, 0x66, 0x8d, 0xa4, 0xef, 0x91, 0x70, 0x01, 0x37,
-	0x20, 0xcd, 0x77, 0x72, 0xcd, 0x51, 0x3b, 0x7f, 0x30, 0x6b, 0x08, 0x04, 0x11, 0x3f, 0xf8, 0x04,
-	0x18, 0x03, 0x56, 0x86, 0x99, 0x09, 0x8c, 0x76, 0xee, 0x60, 0xd6, 0xe0, 0x30, 0xe1, 0x7f, 0xcd,
-	0x6b, 0x50, 0xbc, 0x49, 0xfb, 0x56, 0x67, 0x4f, 0x1e, 0x5a, 0x51, 0xec, 0xd8, 0x81, 0x48, 0xf1,
-	0x38, 0x05, 0xc5, 0xe0, 0xc4, 0x07, 0xb6, 0x27, 0x83, 0xba, 0x10, 0xe0, 0x6e, 0x79, 0xe6, 0xcf,
-	0x11, 0x48, 0x3f, 0x63, 0x13, 0x32, 0x23, 0xa6, 0xab, 0x27, 0x7c, 0xd4, 0x86, 0x83, 0x59, 0x43,
-	0x62, 0x88, 0xfc, 0xc5, 0x97, 0x21, 0xeb, 0xf1, 0x13, 0x19, 0xb3, 0x78, 0xf8, 0xf0, 0x85, 0xf6,
-	0x32, 0x0b, 0x83, 0x83, 0x59, 0x43, 0x11, 0x12, 0xf5, 0x81, 0x9b, 0x91, 0xfe, 0x22, 0x14, 0x5b,
-	0x3a, 0x98, 0x35, 0x34, 0xac, 0xde, 0x6f, 0xcc, 0x9f, 0x21, 0x28, 0xdc, 0xb3, 0x86, 0x41, 0x08,
-	0x05, 0x2e, 0x42, 0x9a, 0x8b, 0x58, 0x3a, 0x77, 0xe9, 0xc8, 0xda, 0xbb, 0xea, 0xb8, 0x9c, 0x67,
-	0x89, 0x04, 0x70, 0xd8, 0x12, 0x8c, 0xb9, 0x2d, 0x21, 0xbd, 0x70, 0x61, 0xbb, 0x6e, 0xe4, 0x92,
-	0xe5, 0x94, 0xf9, 0x3b, 0x04, 0x45, 0x21, 0x99, 0x0c, 0x8b, 0x1f, 0x43, 0x46, 0x08, 0xce, 0x65,
-	0xfb, 0x92, 0xe4, 0x3f, 0xb7, 0x48, 0xe2, 0x4b, 0x9e, 0xf8, 0xfb, 0xb0, 0xd4, 0x75, 0x9d, 0xc9,
-	0x84, 0x76, 0xb7, 0x65, 0x89, 0x49, 0xc6, 0x4b, 0xcc, 0xa6, 0xbe, 0x4e, 0x62, 0xe4, 0xe6, 0x5f,
-	0x11, 0x94, 0x64, 0x36, 0x4b, 0x5b, 0x06, 0x36, 0x40, 0x2f, 0x5c, 0xdc, 0x93, 0x8b, 0x16, 0xf7,
-	0xa3, 0x90, 0xe9, 0xbb, 0xce, 0x74, 0xe2, 0x55, 0x53, 0x22, 0x77, 0x04, 0xb4, 0x58, 0xd1, 0x37,
-	0xaf, 0xc3, 0x92, 0x52, 0xe5, 0x39, 0x25, 0xad, 0x16, 0x2f, 0x69, 0x5b, 0x5d, 0x3a, 0xf6, 0x87,
-	0xbd, 0x61, 0x50, 0xa4, 0x24, 0xbd, 0xf9, 0x29, 0x82, 0x72, 0x9c, 0x04, 0x7f, 0x4f, 0xcb, 0x03,
-	0xc6, 0xee, 0xf4, 0xf3, 0xd9, 0x35, 0x79, 0x71, 0xf0, 0xde, 0x1b, 0xfb, 0xee, 0x9e, 0xca, 0x91,
-	0xda, 0x3b, 0x50, 0xd0, 0xd0, 0xac, 0x79, 0xec, 0x52, 0x15, 0xb3, 0xec, 0x33, 0x4c, 0xd6, 0xa4,
-	0x88, 0x63, 0x0e, 0x5c, 0x4a, 0x5e, 0x44, 0x2c, 0xe2, 0x4b, 0x11, 0x4f, 0xe2, 0x8b, 0x60, 0xf4,
-	0x5c, 0xc7, 0x5e, 0xc8, 0x4d, 0x7c, 0x07, 0xfe, 0x16, 0x24, 0x7d, 0x67, 0x21, 0x27, 0x25, 0x7d,
-	0x87, 0xf9, 0x48, 0x2a, 0x9f, 0x12, 0x37, 0x34, 0x01, 0x99, 0xbf, 0x45, 0xb0, 0xcc, 0xf6, 0x08,
-	0x0b, 0x6c, 0x0c, 0xa6, 0xe3, 0x5d, 0xbc, 0x06, 0x65, 0x76, 0xd2, 0x83, 0xa1, 0xec, 0x00, 0x0f,
-	0x86, 0x5d, 0xa9, 0xe6, 0x12, 0xc3, 0xab, 0xc6, 0xb0, 0xd5, 0xc5, 0xc7, 0x20, 0x3b, 0xf5, 0x04,
-	0x81, 0xd0, 0x39, 0xc3, 0xc0, 0xad, 0x2e, 0x3e, 0xa7, 0x1d, 0xc7, 0x6c, 0xad, 0x5d, 0x93, 0xb8,
-	0x0d, 0xef, 0x5a, 0x43, 0x37, 0x28, 0x3e, 0x67, 0x20, 0xd3, 0x61, 0x07, 0x8b, 0x38, 0x61, 0x1d,
-	0x28, 0x20, 0xe6, 0x02, 0x11, 0xb9, 0x6c, 0x7e, 0x1b, 0xf2, 0xc1, 0xee, 0xb9, 0x8d, 0x67, 0xae,
-	0x07, 0xcc, 0xcb, 0xb0, 0x2c, 0x8a, 0xea, 0xfc, 0xcd, 0xc5, 0x79, 0x9b, 0x8b, 0x6a, 0xf3, 0x71,
-	0x48, 0x0b, 0xab, 0x60, 0x30, 0xba, 0x96, 0x6f, 0xa9, 0x2d, 0xec, 0xdb, 0xac, 0xc2, 0xd1, 0x7b,
-	0xae, 0x35, 0xf6, 0x7a, 0xd4, 0xe5, 0x44, 0x41, 0xec, 0x9a, 0xaf, 0xc1, 0x0a, 0x2b, 0x24, 0xd4,
-	0xf5, 0x36, 0x9c, 0xe9, 0xd8, 0x57, 0x17, 0xfd, 0xf3, 0x50, 0x89, 0xa2, 0x65, 0xa8, 0x57, 0x20,
-	0xdd, 0x61, 0x08, 0xce, 0xbd, 0x44, 0x04, 0x60, 0xfe, 0x0a, 0x01, 0xbe, 0x46, 0x7d, 0xce, 0x7a,
-	0x6b, 0xd3, 0xd3, 0x2e, 0x77, 0xb6, 0xe5, 0x77, 0x06, 0xd4, 0xf5, 0xd4, 0x45, 0x47, 0xc1, 0xff,
-	0x8f, 0xcb, 0x9d, 0x79, 0x01, 0x56, 0x22, 0x52, 0x4a, 0x9d, 0x6a, 0x90, 0xeb, 0x48, 0x9c, 0x6c,
-	0xaa, 0x01, 0x6c, 0xfe, 0x3e, 0x09, 0x39, 0xe1, 0x5b, 0xda, 0xc3, 0x17, 0xa0, 0xd0, 0x63, 0xb1,
-	0xe6, 0x4e, 0xdc, 0xa1, 0x34, 0x81, 0xd1, 0x5e, 0x3e, 0x98, 0x35, 0x74, 0x34, 0xd1, 0x01, 0xfc,
-	0x46, 0x2c, 0xf0, 0xda, 0x95, 0xfd, 0x59, 0x23, 0xf3, 0x03, 0x16, 0x7c, 0x9b, 0xac, 0xbd, 0xf1,
-	0x30, 0xdc, 0x0c, 0xc2, 0xf1, 0x86, 0xcc, 0x36, 0x7e, 0xd3, 0x6b, 0x7f, 0x87, 0x89, 0x1f, 0xab,
-	0xd7, 0x13, 0xd7, 0xb1, 0xa9, 0x3f, 0xa0, 0x53, 0xaf, 0xd5, 0x71, 0x6c, 0xdb, 0x19, 0xb7, 0xf8,
-	0x58, 0xc7, 0x95, 0x66, 0x3d, 0x9a, 0x6d, 0x97, 0x09, 0x78, 0x0f, 0xb2, 0xfe, 0xc0, 0x75, 0xa6,
-	0xfd, 0x01, 0x6f, 0x3f, 0xa9, 0xf6, 0xa5, 0xc5, 0xf9, 0x29, 0x0e, 0x44, 0x7d, 0xe0, 0x53, 0xcc,
-	0x5a, 0xb4, 0xb3, 0xeb, 0x4d, 0x6d, 0x31, 0x2c, 0xb5, 0xd3, 0x07, 0xb3, 0x06, 0x7a, 0x83, 0x04,
-	0x68, 0xf3, 0xd3, 0x24, 0x34, 0x78, 0x08, 0xdf, 0xe7, 0x77, 0x93, 0xab, 0x8e, 0x7b, 0x8b, 0xfa,
-	0xee, 0xb0, 0x73, 0xdb, 0xb2, 0xa9, 0x8a, 0x8d, 0x06, 0x14, 0x6c, 0x8e, 0x7c, 0xa0, 0x25, 0x07,
-	0xd8, 0x01, 0x1d, 0x3e, 0x09, 0xc0, 0xd3, 0x4e, 0xac, 0x8b, 0x3c, 0xc9, 0x73, 0x0c, 0x5f, 0xde,
-	0x88, 0x58, 0xaa, 0xb5, 0xa0, 0x66, 0xd2, 0x42, 0x5b, 0x71, 0x0b, 0x2d, 0xcc, 0x27, 0x30, 0x8b,
-	0x1e, 0xeb, 0xe9, 0x68, 0xac, 0x9b, 0x7f, 0x43, 0x50, 0xbf, 0xa9, 0x24, 0x7f, 0x41, 0x73, 0x28,
-	0x7d, 0x93, 0x2f, 0x49, 0xdf, 0xd4, 0xff, 0xa6, 0xaf, 0xf9, 0x27, 0x2d, 0xe5, 0x09, 0xed, 0x29,
-	0x3d, 0x36, 0xb4, 0x76, 0xf1, 0x32, 0xc4, 0x4c, 0xbe, 0x44, 0xb7, 0xa4, 0x62, 0x6e, 0x79, 0x37,
-	0x2c, 0x07, 0x5c, 0x03, 0x59, 0x0e, 0x4e, 0x83, 0xe1, 0xd2, 0x9e, 0x6a, 0xbe, 0x38, 0x5e, 0xe3,
-	0x69, 0x8f, 0xf0, 0x75, 0xf3, 0x0f, 0x08, 0xca, 0xd7, 0xa8, 0x1f, 0xbd, 0xd6, 0xbc, 0x4a, 0xfa,
-	0xbf, 0x0f, 0x47, 0x34, 0xf9, 0xa5, 0xf6, 0x6f, 0xc5, 0xee, 0x32, 0xaf, 0x85, 0xfa, 0x6f, 0x8d,
-	0xbb, 0xf4, 0x13, 0x39, 0xa3, 0x45, 0xaf, 0x31, 0x77, 0xa1, 0xa0, 0x2d, 0xe2, 0x2b, 0xb1, 0x0b,
-	0xcc, 0xbc, 0xa6, 0xda, 0xae, 0x48, 0x9d, 0xc4, 0x94, 0x26, 0xaf, 0xa7, 0x41, 0xbb, 0xdf, 0x06,
-	0xcc, 0xc7, 0x46, 0xce, 0x56, 0xaf, 0xd4, 0x1c, 0x7b, 0x23, 0xb8, 0xcf, 0x04, 0x30, 0x3e, 0x05,
-	0x86, 0xeb, 0x3c, 0x52, 0x37, 0xd3, 0x52, 0x78, 0x24, 0x71, 0x1e, 0x11, 0xbe, 0x64, 0x5e, 0x86,
-	0x14, 0x71, 0x1e, 0xe1, 0x3a, 0x80, 0x6b, 0x8d, 0xfb, 0xf4, 0x7e, 0x30, 0xb0, 0x14, 0x89, 0x86,
-	0x79, 0x4e, 0x7f, 0xdd, 0x80, 0x23, 0xba, 0x44, 0xc2, 0xdd, 0x4d, 0xc8, 0x32, 0xe4, 0x70, 0xde,
-	0xa3, 0x17, 0x27, 0x14, 0xb3, 0xaf, 0x22, 0x62, 0x31, 0x03, 0x21, 0x1e, 0x9f, 0x80, 0xbc, 0x6f,
-	0xed, 0x8c, 0xe8, 0xed, 0x30, 0xe7, 0x43, 0x04, 0x5b, 0x65, 0xb3, 0xd6, 0x7d, 0xed, 0xa2, 0x10,
-	0x22, 0xf0, 0x59, 0x28, 0x87, 0x32, 0xdf, 0x75, 0x69, 0x6f, 0xf8, 0x09, 0xf7, 0x70, 0x91, 0x1c,
-	0xc2, 0xe3, 0x35, 0x58, 0x0e, 0x71, 0xdb, 0xbc, 0xed, 0x1a, 0x9c, 0x34, 0x8e, 0x66, 0xb6, 0xe1,
-	0xea, 0xbe, 0xf7, 0x70, 0x6a, 0x8d, 0x78, 0x21, 0x2b, 0x12, 0x0d, 0x63, 0xfe, 0x11, 0xc1, 0x11,
-	0xe1, 0x6a, 0x36, 0x65, 0xbf, 0x8a, 0x51, 0xff, 0x6b, 0x04, 0x58, 0xd7, 0x40, 0x86, 0xd6, 0x37,
-	0xf5, 0xe7, 0x13, 0xd6, 0xd7, 0x0b, 0x7c, 0x84, 0x14, 0xa8, 0xf0, 0x05, 0xc4, 0x0c, 0xae, 0x80,
-	0xfc, 0xdd, 0x51, 0xcc, 0xa8, 0x02, 0xa3, 0x6e, 0x7f, 0x6c, 0xb4, 0xde, 0xd9, 0xf3, 0xa9, 0x27,
-	0x27, 0x4c, 0x3e, 0x5a, 0x73, 0x04, 0x11, 0x3f, 0xec, 0x2c, 0x3a, 0xf6, 0x79, 0xd4, 0x18, 0xe1,
-	0x59, 0x12, 0x45, 0xd4, 0x07, 0x6b, 0x1b, 0xa5, 0xfb, 0xce, 0x68, 0x1a, 0x76, 0x89, 0x57, 0xc8,
-	0xce, 0xd1, 0xd1, 0x37, 0x2d, 0x47, 0x5f, 0xf3, 0x47, 0xb0, 0xa4, 0x54, 0x92, 0x86, 0x7f, 0x13,
-	0xb2, 0x1f, 0x73, 0xcc, 0x9c, 0x07, 0x21, 0x41, 0x2a, 0x8b, 0x8d, 0x22, 0x8b, 0xbe, 0xb3, 0x06,
-	0x9c, 0x77, 0x21, 0x23, 0xc8, 0xf1, 0x09, 0xfd, 0xc2, 0x2d, 0x5e, 0x2e, 0x18, 0x2c, 0x6f, 0xcf,
-	0x91, 0x87, 0x8f, 0xfc, 0x9c, 0x87, 0x0f, 0x13, 0x32, 0xe2, 0x24, 0xe9, 0x3f, 0xee, 0x62, 0x81,
-	0x21, 0xf2, 0xf7, 0xec, 0x69, 0xc8, 0x07, 0xaf, 0xa8, 0xb8, 0x00, 0xd9, 0xab, 0x77, 0xc8, 0x0f,
-	0xaf, 0x90, 0xcd, 0x72, 0x02, 0x17, 0x21, 0xd7, 0xbe, 0xb2, 0x71, 0x83, 0x43, 0x68, 0xfd, 0xdf,
-	0x86, 0x2a, 0x10, 0x2e, 0xfe, 0x2e, 0xa4, 0x45, 0xd6, 0x1f, 0x0d, 0x15, 0xd4, 0xdf, 0x42, 0x6b,
-	0xc7, 0x0e, 0xe1, 0xe5, 0x1d, 0x3d, 0xf1, 0x26, 0xc2, 0xb7, 0xa1, 0xc0, 0x91, 0xf2, 0xbd, 0xe5,
-	0x44, 0xfc, 0xd9, 0x23, 0xc2, 0xe9, 0xe4, 0x73, 0x56, 0x35, 0x7e, 0x97, 0x20, 0xcd, 0x0b, 0xaf,
-	0x2e, 0x8d, 0xfe, 0x5e, 0xa6, 0x4b, 0x13, 0x79, 0x81, 0x32, 0x13, 0xf8, 0x1d, 0x30, 0xd8, 0x70,
-	0x80, 0xb5, 0xde, 0xa0, 0x3d, 0x93, 0xd4, 0x8e, 0xc6, 0xd1, 0xda, 0xb1, 0xef, 0x06, 0xaf, 0x3d,
-	0xc7, 0xe2, 0x53, 0xad, 0xda, 0x5e, 0x3d, 0xbc, 0x10, 0x9c, 0x7c, 0x47, 0x3c, 0x7b, 0xa8, 0xb1,
-	0x04, 0x9f, 0x8c, 0x1e, 0x15, 0x9b, 0x62, 0x6a, 0xf5, 0xe7, 0x2d, 0x07, 0x0c, 0x6f, 0x42, 0x41,
-	0x1b, 0x09, 0x74, 0xb3, 0x1e, 0x9e, 0x67, 0x74, 0xb3, 0xce, 0x99, 0x23, 0xcc, 0x04, 0xbe, 0x06,
-	0x39, 0xd6, 0x51, 0x59, 0x61, 0xc1, 0xc7, 0xe3, 0x8d, 0x53, 0x2b, 0x98, 0xb5, 0x13, 0xf3, 0x17,
-	0x03, 0x46, 0x57, 0x61, 0x39, 0x68, 0xcd, 0x32, 0xaa, 0x8f, 0xc5, 0xd3, 0x62, 0x8e, 0xbd, 0xa2,
-	0xa9, 0x65, 0x26, 0xd6, 0x3f, 0x82, 0x9c, 0x9a, 0x82, 0xf1, 0x07, 0xb0, 0x14, 0x9d, 0x01, 0xf1,
-	0x37, 0x34, 0xf3, 0x44, 0x47, 0xeb, 0xda, 0xaa, 0xb6, 0x34, 0x7f, 0x70, 0x4c, 0xac, 0xa1, 0xf5,
-	0x8f, 0xd4, 0xbf, 0x6f, 0x36, 0x2d, 0xdf, 0xc2, 0x77, 0x60, 0x89, 0x6b, 0x1f, 0xfc, 0x7f, 0x27,
-	0x12, 0xa5, 0x87, 0xfe, 0x99, 0x14, 0x89, 0xd2, 0xc3, 0xff, 0x54, 0x32, 0x13, 0xed, 0x0f, 0x9f,
-	0x3c, 0xad, 0x27, 0x3e, 0x7f, 0x5a, 0x4f, 0x7c, 0xf1, 0xb4, 0x8e, 0x7e, 0xba, 0x5f, 0x47, 0xbf,
-	0xd9, 0xaf, 0xa3, 0xc7, 0xfb, 0x75, 0xf4, 0x64, 0xbf, 0x8e, 0xfe, 0xb9, 0x5f, 0x47, 0xff, 0xda,
-	0xaf, 0x27, 0xbe, 0xd8, 0xaf, 0xa3, 0xcf, 0x9e, 0xd5, 0x13, 0x4f, 0x9e, 0xd5, 0x13, 0x9f, 0x3f,
-	0xab, 0x27, 0x3e, 0x7c, 0xfd, 0xcb, 0x5e, 0xb7, 0xd4, 0x89, 0x3b, 0x19, 0xfe, 0xf3, 0xd6, 0x7f,
-	0x03, 0x00, 0x00, 0xff, 0xff, 0x0a, 0x2d, 0xf5, 0x83, 0x7d, 0x1b, 0x00, 0x00,
+	// 2209 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xd4, 0x39, 0xdb, 0x6f, 0x5b, 0x49,
+	0xf9, 0x1e, 0xfb, 0xd8, 0xb1, 0x3f, 0x3b, 0x97, 0x4e, 0xbc, 0x8d, 0x7f, 0x6e, 0x6b, 0xa7, 0x47,
+	0xfb, 0x6b, 0xa3, 0xb6, 0x6b, 0x6f, 0xb3, 0xb0, 0x74, 0x5b, 0x16, 0x54, 0x27, 0xdb, 0x36, 0x6d,
+	0x7a, 0x9b, 0x94, 0x82, 0x56, 0xa0, 0xea, 0xc4, 0x1e, 0x3b, 0x56, 0x7c, 0x7c, 0xdc, 0x73, 0xc6,
+	0xed, 0x46, 0xe2, 0x81, 0x7f, 0x60, 0xa5, 0x7d, 0x43, 0xbc, 0x20, 0x1e, 0x90, 0x40, 0x48, 0x48,
+	0x88, 0x3f, 0x00, 0x78, 0x40, 0xa2, 0xbc, 0x95, 0xb7, 0x15, 0x0f, 0x86, 0xa6, 0x2f, 0x28, 0x4f,
+	0xfb, 0x0f, 0x80, 0xd0, 0xdc, 0xce, 0x19, 0x9f, 0x38, 0xbb, 0xb8, 0x54, 0x42, 0x7d, 0x89, 0xcf,
+	0x77, 0x99, 0x6f, 0xe6, 0xbb, 0xcf, 0x37, 0x81, 0x13, 0x83, 0xdd, 0x4e, 0xbd, 0xe7, 0x75, 0x06,
+	0xbe, 0xc7, 0xbc, 0xf0, 0xa3, 0x26, 0xfe, 0xe2, 0xac, 0x86, 0xcb, 0xc5, 0x8e, 0xd7, 0xf1, 0x24,
+	0x0f, 0xff, 0x92, 0xf4, 0x72, 0xb5, 0xe3, 0x79, 0x9d, 0x1e, 0xad, 0x0b, 0x68, 0x7b, 0xd8, 0xae,
+	0xb3, 0xae, 0x4b, 0x03, 0xe6, 0xb8, 0x03, 0xc5, 0xb0, 0xac, 0xa4, 0x3f, 0xee, 0xb9, 0x5e, 0x8b,
+	0xf6, 0xea, 0x01, 0x73, 0x58, 0x20, 0xff, 0x2a, 0x8e, 0x45, 0xce, 0x31, 0x18, 0x06, 0x3b, 0xe2,
+	0x8f, 0x44, 0xda, 0x45, 0xc0, 0x5b, 0xcc, 0xa7, 0x8e, 0x4b, 0x1c, 0x46, 0x03, 0x42, 0x1f, 0x0f,
+	0x69, 0xc0, 0xec, 0xdb, 0xb0, 0x38, 0x86, 0x0d, 0x06, 0x5e, 0x3f, 0xa0, 0xf8, 0x7d, 0xc8, 0x07,
+	0x11, 0xba, 0x84, 0x96, 0x53, 0x2b, 0xf9, 0xd5, 0x62, 0x2d, 0x54, 0x25, 0x5a, 0x43, 0x4c, 0x46,
+	0xfb, 0xa7, 0x08, 0x20, 0xa2, 0xe1, 0x0a, 0x80, 0xa4, 0xde, 0x70, 0x82, 0x9d, 0x12, 0x5a, 0x46,
+	0x2b, 0x16, 0x31, 0x30, 0xf8, 0x02, 0x1c, 0x8b, 0xa0, 0x3b, 0xde, 0xd6, 0x8e, 0xe3, 0xb7, 0x4a,
+	0x49, 0xc1, 0x76, 0x98, 0x80, 0x31, 0x58, 0xbe, 0xc3, 0x68, 0x29, 0xb5, 0x8c, 0x56, 0x52, 0x44,
+	0x7c, 0xe3, 0xe3, 0x90, 0x61, 0xb4, 0xef, 0xf4, 0x59, 0xc9, 0x5a, 0x46, 0x2b, 0x39, 0xa2, 0x20,
+	0x8e, 0xe7, 0xba, 0xd3, 0xa0, 0x94, 0x5e, 0x46, 0x2b, 0xb3, 0x44, 0x41, 0xf6, 0x9f, 0x92, 0x50,
+	0xb8, 0x3f, 0xa4, 0xfe, 0x9e, 0x32, 0x00, 0x2e, 0x43, 0x36, 0xa0, 0x3d, 0xda, 0x64, 0x9e, 0x2f,
+	0x0e, 0x98, 0x23, 0x21, 0x8c, 0x8b, 0x90, 0xee, 0x75, 0xdd, 0x2e, 0x13, 0x47, 0x9a, 0x25, 0x12,
+	0xc0, 0x97, 0x21, 0x1d, 0x30, 0xc7, 0x67, 0xe2, 0x1c, 0xf9, 0xd5, 0x72, 0x4d, 0x3a, 0xac, 0xa6,
+	0x1d, 0x56, 0x7b, 0xa0, 0x1d, 0xd6, 0xc8, 0x3e, 0x1b, 0x55, 0x13, 0x9f, 0xfd, 0xad, 0x8a, 0x88,
+	0x5c, 0x82, 0xdf, 0x87, 0x14, 0xed, 0xb7, 0x4a, 0xd6, 0x14, 0x2b, 0xf9, 0x02, 0x7c, 0x11, 0x72,
+	0xad, 0xae, 0x4f, 0x9b, 0xac, 0xeb, 0xf5, 0x85, 0x46, 0x73, 0xab, 0x8b, 0x91, 0x37, 0xd6, 0x35,
+	0x89, 0x44, 0x5c, 0xf8, 0x02, 0x64, 0x02, 0x6e, 0xb6, 0xa0, 0x34, 0xb3, 0x9c, 0x5a, 0xc9, 0x35,
+	0x8a, 0x07, 0xa3, 0xea, 0x82, 0xc4, 0x5c, 0xf0, 0xdc, 0x2e, 0xa3, 0xee, 0x80, 0xed, 0x11, 0xc5,
+	0x83, 0xcf, 0xc1, 0x4c, 0x8b, 0xf6, 0x28, 0x77, 0x76, 0x56, 0x38, 0x7b, 0xc1, 0x10, 0x2f, 0x08,
+	0x44, 0x33, 0xdc, 0xb4, 0xb2, 0x99, 0x85, 0x19, 0xfb, 0x5f, 0x08, 0xf0, 0x96, 0xe3, 0x0e, 0x7a,
+	0xf4, 0x3f, 0xb6, 0x67, 0x68, 0xb9, 0xe4, 0x2b, 0x5b, 0x2e, 0x35, 0xad, 0xe5, 0x22, 0x33, 0x58,
+	0xd3, 0x99, 0x21, 0xfd, 0x15, 0x66, 0xb0, 0x37, 0x21, 0x23, 0x51, 0x5f, 0x15, 0x43, 0x91, 0xce,
+	0x29, 0xad, 0xcd, 0x42, 0xa4, 0x4d, 0x4a, 0x9c, 0xd3, 0xfe, 0x19, 0x82, 0x59, 0x65, 0x48, 0x95,
+	0x83, 0xdb, 0x30, 0x23, 0x73, 0x40, 0xe7, 0xdf, 0x52, 0x3c, 0xff, 0xae, 0xb6, 0x9c, 0x01, 0xa3,
+	0x7e, 0xa3, 0xfe, 0x6c, 0x54, 0x45, 0x7f, 0x1d, 0x55, 0xcf, 0x76, 0xba, 0x6c, 0x67, 0xb8, 0x5d,
+	0x6b, 0x7a, 0x6e, 0xbd, 0xe3, 0x3b, 0x6d, 0xa7, 0xef, 0xd4, 0x7b, 0xde, 0x6e, 0xb7, 0xae, 0xeb,
+	0x81, 0xce, 0x5b, 0x2d, 0x18, 0x9f, 0x17, 0xa7, 0x63, 0x81, 0xf2, 0xc8, 0x7c, 0x4d, 0x40, 0xb5,
+	0x8d, 0x7e, 0x87, 0x06, 0x5c, 0xb2, 0xc5, 0x8d, 0x49, 0x24, 0x8f, 0xfd, 0x43, 0x58, 0x1c, 0x73,
+	0xb8, 0x3a, 0xe7, 0x25, 0xc8, 0x04, 0xd4, 0xef, 0x86, 0x65, 0xc2, 0x30, 0xd9, 0x96, 0xc0, 0x37,
+	0xe6, 0xd4, 0xf9, 0x32, 0x12, 0x26, 0x8a, 0x7f, 0xba, 0xdd, 0xff, 0x88, 0xa0, 0xb0, 0xe9, 0x6c,
+	0xd3, 0x9e, 0x8e, 0x34, 0x0c, 0x56, 0xdf, 0x71, 0xa9, 0xb2, 0xb8, 0xf8, 0xe6, 0x69, 0xff, 0xc4,
+	0xe9, 0x0d, 0xa9, 0x14, 0x99, 0x25, 0x0a, 0x9a, 0x36, 0x67, 0xd1, 0x2b, 0xe7, 0x2c, 0x8a, 0x22,
+	0xaf, 0x08, 0xe9, 0xc7, 0xdc, 0x50, 0x22, 0x5f, 0x73, 0x44, 0x02, 0xf6, 0x59, 0x98, 0x55, 0x5a,
+	0x28, 0xf3, 0x45, 0x47, 0xe6, 0xe6, 0xcb, 0xe9, 0x23, 0xdb, 0x2e, 0x64, 0xa4, 0xb5, 0xf1, 0xdb,
+	0x90, 0x0b, 0x7b, 0x80, 0xd0, 0x36, 0xd5, 0xc8, 0x1c, 0x8c, 0xaa, 0x49, 0x16, 0x90, 0x88, 0x80,
+	0xab, 0x90, 0x16, 0x2b, 0x85, 0xe6, 0xa8, 0x91, 0x3b, 0x18, 0x55, 0x25, 0x82, 0xc8, 0x1f, 0x7c,
+	0x12, 0xac, 0x1d, 0x5e, 0x86, 0xb9, 0x09, 0xac, 0x46, 0xf6, 0x60, 0x54, 0x15, 0x30, 0x11, 0x7f,
+	0xed, 0xeb, 0x50, 0xd8, 0xa4, 0x1d, 0xa7, 0xb9, 0xa7, 0x36, 0x2d, 0x6a, 0x71, 0x7c, 0x43, 0xa4,
+	0x65, 0x9c, 0x86, 0x42, 0xb8, 0xe3, 0x23, 0x37, 0x50, 0x41, 0x9d, 0x0f, 0x71, 0xb7, 0x03, 0xfb,
+	0x27, 0x08, 0x94, 0x9f, 0xb1, 0x0d, 0x99, 0x1e, 0xd7, 0x35, 0x90, 0x3e, 0x6a, 0xc0, 0xc1, 0xa8,
+	0xaa, 0x30, 0x44, 0xfd, 0xe2, 0x2b, 0x30, 0x13, 0x88, 0x1d, 0xb9, 0xb0, 0x78, 0xf8, 0x08, 0x42,
+	0x63, 0x9e, 0x87, 0xc1, 0xc1, 0xa8, 0xaa, 0x19, 0x89, 0xfe, 0xc0, 0xb5, 0xb1, 0xfe, 0x22, 0x15,
+	0x9b, 0x3b, 0x18, 0x55, 0x0d, 0xac, 0xd9, 0x6f, 0xec, 0x1f, 0x23, 0xc8, 0x3f, 0x70, 0xba, 0x61,
+	0x08, 0x85, 0x2e, 0x42, 0x86, 0x8b, 0x78, 0x3a, 0xb7, 0x68, 0xcf, 0xd9, 0xbb, 0xe6, 0xf9, 0x42,
+	0xe6, 0x2c, 0x09, 0xe1, 0xa8, 0x25, 0x58, 0x13, 0x5b, 0x42, 0x7a, 0xea, 0xc2, 0x76, 0xd3, 0xca,
+	0x26, 0x17, 0x52, 0xf6, 0xaf, 0x11, 0x14, 0xe4, 0xc9, 0x54, 0x58, 0x7c, 0x1f, 0x32, 0xf2, 0xe0,
+	0xe2, 0x6c, 0x5f, 0x92, 0xfc, 0xe7, 0xa7, 0x49, 0x7c, 0x25, 0x13, 0x7f, 0x1b, 0xe6, 0x5a, 0xbe,
+	0x37, 0x18, 0xd0, 0xd6, 0x96, 0x2a, 0x31, 0xc9, 0x78, 0x89, 0x59, 0x37, 0xe9, 0x24, 0xc6, 0x6e,
+	0xff, 0x19, 0xc1, 0xac, 0xca, 0x66, 0x65, 0xcb, 0xd0, 0x06, 0xe8, 0x95, 0x8b, 0x7b, 0x72, 0xda,
+	0xe2, 0x7e, 0x1c, 0x32, 0x1d, 0xdf, 0x1b, 0x0e, 0x82, 0x52, 0x4a, 0xe6, 0x8e, 0x84, 0xa6, 0x2b,
+	0xfa, 0xf6, 0x4d, 0x98, 0xd3, 0xaa, 0x1c, 0x51, 0xd2, 0xca, 0xf1, 0x92, 0xb6, 0xd1, 0xa2, 0x7d,
+	0xd6, 0x6d, 0x77, 0xc3, 0x22, 0xa5, 0xf8, 0xed, 0x4f, 0x11, 0x2c, 0xc4, 0x59, 0xf0, 0xb7, 0x8c,
+	0x3c, 0xe0, 0xe2, 0xce, 0x1c, 0x2d, 0xae, 0x26, 0x8a, 0x43, 0xf0, 0x51, 0x9f, 0xf9, 0x7b, 0x3a,
+	0x47, 0xca, 0x1f, 0x40, 0xde, 0x40, 0xf3, 0xe6, 0xb1, 0x4b, 0x75, 0xcc, 0xf2, 0xcf, 0x28, 0x59,
+	0x93, 0x32, 0x8e, 0x05, 0x70, 0x39, 0x79, 0x09, 0xf1, 0x88, 0x9f, 0x1d, 0xf3, 0x24, 0xbe, 0x04,
+	0x56, 0xdb, 0xf7, 0xdc, 0xa9, 0xdc, 0x24, 0x56, 0xe0, 0xaf, 0x41, 0x92, 0x79, 0x53, 0x39, 0x29,
+	0xc9, 0x3c, 0xee, 0x23, 0xa5, 0x7c, 0x4a, 0xde, 0xd0, 0x24, 0x64, 0xff, 0x0a, 0xc1, 0x3c, 0x5f,
+	0x23, 0x2d, 0xb0, 0xb6, 0x33, 0xec, 0xef, 0xe2, 0x15, 0x58, 0xe0, 0x3b, 0x3d, 0xea, 0xaa, 0x0e,
+	0xf0, 0xa8, 0xdb, 0x52, 0x6a, 0xce, 0x71, 0xbc, 0x6e, 0x0c, 0x1b, 0x2d, 0xbc, 0x04, 0x33, 0xc3,
+	0x40, 0x32, 0x48, 0x9d, 0x33, 0x1c, 0xdc, 0x68, 0xe1, 0xf3, 0xc6, 0x76, 0xdc, 0xd6, 0xc6, 0x35,
+	0x49, 0xd8, 0xf0, 0x9e, 0xd3, 0xf5, 0xc3, 0xe2, 0x73, 0x16, 0x32, 0x4d, 0xbe, 0xb1, 0x8c, 0x13,
+	0xde, 0x81, 0x42, 0x66, 0x71, 0x20, 0xa2, 0xc8, 0xf6, 0xd7, 0x21, 0x17, 0xae, 0x9e, 0xd8, 0x78,
+	0x26, 0x7a, 0xc0, 0xbe, 0x02, 0xf3, 0xb2, 0xa8, 0x4e, 0x5e, 0x5c, 0x98, 0xb4, 0xb8, 0xa0, 0x17,
+	0x9f, 0x80, 0xb4, 0xb4, 0x0a, 0x06, 0xab, 0xe5, 0x30, 0x47, 0x2f, 0xe1, 0xdf, 0x76, 0x09, 0x8e,
+	0x3f, 0xf0, 0x9d, 0x7e, 0xd0, 0xa6, 0xbe, 0x60, 0x0a, 0x63, 0xd7, 0x7e, 0x0b, 0x16, 0x79, 0x21,
+	0xa1, 0x7e, 0xb0, 0xe6, 0x0d, 0xfb, 0x4c, 0x5f, 0xf4, 0x2f, 0x40, 0x71, 0x1c, 0xad, 0x42, 0xbd,
+	0x08, 0xe9, 0x26, 0x47, 0x08, 0xe9, 0xb3, 0x44, 0x02, 0xf6, 0xcf, 0x11, 0xe0, 0xeb, 0x94, 0x09,
+	0xd1, 0x1b, 0xeb, 0x81, 0x71, 0xb9, 0x73, 0x1d, 0xd6, 0xdc, 0xa1, 0x7e, 0xa0, 0x2f, 0x3a, 0x1a,
+	0xfe, 0x5f, 0x5c, 0xee, 0xec, 0x8b, 0xb0, 0x38, 0x76, 0x4a, 0xa5, 0x53, 0x19, 0xb2, 0x4d, 0x85,
+	0x53, 0x4d, 0x35, 0x84, 0xed, 0xdf, 0x26, 0x21, 0x2b, 0x7d, 0x4b, 0xdb, 0xf8, 0x22, 0xe4, 0xdb,
+	0x3c, 0xd6, 0xfc, 0x81, 0xdf, 0x55, 0x26, 0xb0, 0x1a, 0xf3, 0x07, 0xa3, 0xaa, 0x89, 0x26, 0x26,
+	0x80, 0xdf, 0x89, 0x05, 0x5e, 0xa3, 0xb8, 0x3f, 0xaa, 0x66, 0xbe, 0xc3, 0x83, 0x6f, 0x9d, 0xb7,
+	0x37, 0x11, 0x86, 0xeb, 0x61, 0x38, 0xde, 0x52, 0xd9, 0x26, 0x6e, 0x7a, 0x8d, 0x6f, 0xf0, 0xe3,
+	0xc7, 0xea, 0xf5, 0xc0, 0xf7, 0x5c, 0xca, 0x76, 0xe8, 0x30, 0xa8, 0x37, 0x3d, 0xd7, 0xf5, 0xfa,
+	0x75, 0x31, 0xd6, 0x09, 0xa5, 0x79, 0x8f, 0xe6, 0xcb, 0x55, 0x02, 0x3e, 0x80, 0x19, 0xb6, 0xe3,
+	0x7b, 0xc3, 0xce, 0x8e, 0x68, 0x3f, 0xa9, 0xc6, 0xe5, 0xe9, 0xe5, 0x69, 0x09, 0x44, 0x7f, 0xe0,
+	0xd3, 0xdc, 0x5a, 0xb4, 0xb9, 0x1b, 0x0c, 0x5d, 0x39, 0x2c, 0x35, 0xd2, 0x07, 0xa3, 0x2a, 0x7a,
+	0x87, 0x84, 0x68, 0xfb, 0xd3, 0x24, 0x54, 0x45, 0x08, 0x3f, 0x14, 0x77, 0x93, 0x6b, 0x9e, 0x7f,
+	0x9b, 0x32, 0xbf, 0xdb, 0xbc, 0xe3, 0xb8, 0x54, 0xc7, 0x46, 0x15, 0xf2, 0xae, 0x40, 0x3e, 0x32,
+	0x92, 0x03, 0xdc, 0x90, 0x0f, 0x9f, 0x02, 0x10, 0x69, 0x27, 0xe9, 0x32, 0x4f, 0x72, 0x02, 0x23,
+	0xc8, 0x6b, 0x63, 0x96, 0xaa, 0x4f, 0xa9, 0x99, 0xb2, 0xd0, 0x46, 0xdc, 0x42, 0x53, 0xcb, 0x09,
+	0xcd, 0x62, 0xc6, 0x7a, 0x7a, 0x3c, 0xd6, 0xed, 0xbf, 0x20, 0xa8, 0x6c, 0xea, 0x93, 0xbf, 0xa2,
+	0x39, 0xb4, 0xbe, 0xc9, 0xd7, 0xa4, 0x6f, 0xea, 0xbf, 0xd3, 0xd7, 0xfe, 0x83, 0x91, 0xf2, 0x84,
+	0xb6, 0xb5, 0x1e, 0x6b, 0x46, 0xbb, 0x78, 0x1d, 0xc7, 0x4c, 0xbe, 0x46, 0xb7, 0xa4, 0x62, 0x6e,
+	0xf9, 0x10, 0x16, 0xc7, 0x34, 0x50, 0xe5, 0xe0, 0x0c, 0x58, 0x3e, 0x6d, 0xeb, 0xe6, 0x8b, 0xe3,
+	0x35, 0x9e, 0xb6, 0x89, 0xa0, 0xdb, 0xbf, 0x43, 0xb0, 0x70, 0x9d, 0xb2, 0xf1, 0x6b, 0xcd, 0x9b,
+	0xa4, 0xff, 0x0d, 0x38, 0x66, 0x9c, 0x5f, 0x69, 0xff, 0x5e, 0xec, 0x2e, 0xf3, 0x56, 0xa4, 0xff,
+	0x46, 0xbf, 0x45, 0x3f, 0x51, 0x33, 0xda, 0xf8, 0x35, 0xe6, 0x1e, 0xe4, 0x0d, 0x22, 0xbe, 0x1a,
+	0xbb, 0xc0, 0x4c, 0x6a, 0xaa, 0x8d, 0xa2, 0xd2, 0x49, 0x4e, 0x69, 0xea, 0x7a, 0x1a, 0xb6, 0xfb,
+	0x2d, 0xc0, 0x62, 0x6c, 0x14, 0x62, 0xcd, 0x4a, 0x2d, 0xb0, 0xb7, 0xc2, 0xfb, 0x4c, 0x08, 0xe3,
+	0xd3, 0x60, 0xf9, 0xde, 0x53, 0x7d, 0x33, 0x9d, 0x8d, 0xb6, 0x24, 0xde, 0x53, 0x22, 0x48, 0xf6,
+	0x15, 0x48, 0x11, 0xef, 0x29, 0x7f, 0x66, 0xf2, 0x9d, 0x7e, 0x87, 0x3e, 0x0c, 0x07, 0x96, 0x02,
+	0x31, 0x30, 0x47, 0xf4, 0xd7, 0x35, 0x38, 0x66, 0x9e, 0x48, 0xba, 0xbb, 0x06, 0x33, 0xf7, 0x87,
+	0xa6, 0xb9, 0x8a, 0x31, 0x73, 0x89, 0x25, 0x44, 0x33, 0xf1, 0x98, 0x81, 0x08, 0x8f, 0x4f, 0x42,
+	0x8e, 0x39, 0xdb, 0x3d, 0x7a, 0x27, 0xca, 0xf9, 0x08, 0xc1, 0xa9, 0x7c, 0xd6, 0x7a, 0x68, 0x5c,
+	0x14, 0x22, 0x04, 0x3e, 0x07, 0x0b, 0xd1, 0x99, 0xef, 0xf9, 0xb4, 0xdd, 0xfd, 0x44, 0x78, 0xb8,
+	0x40, 0x0e, 0xe1, 0xf1, 0x0a, 0xcc, 0x47, 0xb8, 0x2d, 0xd1, 0x76, 0x2d, 0xc1, 0x1a, 0x47, 0x73,
+	0xdb, 0x08, 0x75, 0x3f, 0x7a, 0x3c, 0x74, 0x7a, 0xa2, 0x90, 0x15, 0x88, 0x81, 0xb1, 0x7f, 0x8f,
+	0xe0, 0x98, 0x74, 0x35, 0x73, 0xd8, 0x1b, 0x19, 0xf5, 0xbf, 0x40, 0x80, 0x4d, 0x0d, 0x54, 0x68,
+	0xfd, 0xbf, 0xf9, 0x7c, 0xc2, 0xfb, 0x7a, 0x5e, 0x8c, 0x90, 0x12, 0x15, 0xbd, 0x80, 0xd8, 0xe1,
+	0x15, 0x50, 0xbc, 0x3b, 0xca, 0x19, 0x55, 0x62, 0xf4, 0xed, 0x8f, 0x8f, 0xd6, 0xdb, 0x7b, 0x8c,
+	0x06, 0x6a, 0xc2, 0x14, 0xa3, 0xb5, 0x40, 0x10, 0xf9, 0xc3, 0xf7, 0xa2, 0x7d, 0x26, 0xa2, 0xc6,
+	0x8a, 0xf6, 0x52, 0x28, 0xa2, 0x3f, 0x78, 0xdb, 0x98, 0x7d, 0xe8, 0xf5, 0x86, 0x2e, 0x7d, 0x03,
+	0xed, 0x3c, 0x3e, 0xfa, 0xa6, 0xd5, 0xe8, 0x6b, 0x7f, 0x0f, 0xe6, 0xb4, 0x4a, 0xca, 0xf0, 0xef,
+	0xc2, 0xcc, 0x13, 0x81, 0x99, 0xf0, 0x20, 0x24, 0x59, 0x55, 0xb1, 0xd1, 0x6c, 0xe3, 0xef, 0xac,
+	0xa1, 0xe4, 0x5d, 0xc8, 0x48, 0x76, 0xfe, 0x72, 0x11, 0x35, 0x51, 0xf9, 0x72, 0xc1, 0x61, 0x75,
+	0x7b, 0x1e, 0x7b, 0xf8, 0xc8, 0x4d, 0x78, 0xf8, 0xb0, 0x21, 0x23, 0x77, 0x2a, 0xa5, 0x22, 0x17,
+	0x4b, 0x0c, 0x51, 0xbf, 0xe7, 0xce, 0x40, 0x2e, 0x7c, 0x45, 0xc5, 0x79, 0x98, 0xb9, 0x76, 0x97,
+	0x7c, 0xf7, 0x2a, 0x59, 0x5f, 0x48, 0xe0, 0x02, 0x64, 0x1b, 0x57, 0xd7, 0x6e, 0x09, 0x08, 0xad,
+	0xfe, 0xd3, 0xd2, 0x05, 0xc2, 0xc7, 0xdf, 0x84, 0xb4, 0xcc, 0xfa, 0xe3, 0x91, 0x82, 0xe6, 0x5b,
+	0x68, 0x79, 0xe9, 0x10, 0x5e, 0xdd, 0xd1, 0x13, 0xef, 0x22, 0x7c, 0x07, 0xf2, 0x02, 0xa9, 0xde,
+	0x5b, 0x4e, 0xc6, 0x9f, 0x3d, 0xc6, 0x24, 0x9d, 0x3a, 0x82, 0x6a, 0xc8, 0xbb, 0x0c, 0x69, 0x51,
+	0x78, 0xcd, 0xd3, 0x98, 0xef, 0x65, 0xe5, 0xa5, 0x43, 0x78, 0xbd, 0x1a, 0x7f, 0x00, 0x16, 0x1f,
+	0x0e, 0xb0, 0xd1, 0x1b, 0x8c, 0x67, 0x92, 0xf2, 0xf1, 0x38, 0xda, 0xd8, 0xf6, 0xc3, 0xf0, 0xb5,
+	0x67, 0x29, 0x3e, 0xd5, 0xea, 0xe5, 0xa5, 0xc3, 0x84, 0x70, 0xe7, 0xbb, 0x50, 0x30, 0xc7, 0x12,
+	0x7c, 0x6a, 0x7c, 0xab, 0xd8, 0x14, 0x53, 0xae, 0x1c, 0x45, 0x0e, 0x05, 0x6e, 0x42, 0xde, 0x18,
+	0x09, 0x4c, 0xb3, 0x1e, 0x9e, 0x67, 0xca, 0xa7, 0x8e, 0xa0, 0x86, 0xd2, 0xae, 0x43, 0x96, 0x77,
+	0x54, 0x5e, 0x58, 0xf0, 0x89, 0x78, 0xe3, 0x34, 0x0a, 0x66, 0xf9, 0xe4, 0x64, 0x62, 0x28, 0xe8,
+	0x1a, 0xcc, 0x87, 0xad, 0x59, 0x45, 0xf5, 0x52, 0x3c, 0x2d, 0x26, 0xd8, 0x6b, 0x3c, 0xb5, 0xec,
+	0xc4, 0xea, 0x6f, 0x10, 0x64, 0xf5, 0x18, 0x8c, 0xef, 0xc3, 0xdc, 0xf8, 0x10, 0x88, 0xff, 0xcf,
+	0xb0, 0xcf, 0xf8, 0x6c, 0x5d, 0x5e, 0x36, 0x48, 0x93, 0x27, 0xc7, 0xc4, 0x0a, 0xe2, 0x22, 0x6f,
+	0x38, 0xfd, 0x96, 0xf7, 0xe4, 0xb5, 0x89, 0x5c, 0xfd, 0x81, 0xfe, 0x97, 0xd0, 0xba, 0xc3, 0x1c,
+	0x7c, 0x17, 0xe6, 0x84, 0x45, 0xc3, 0xff, 0x19, 0x8d, 0x45, 0xfe, 0xa1, 0x7f, 0x50, 0x95, 0x4f,
+	0x1d, 0x41, 0xd5, 0x1b, 0x34, 0x3e, 0x7e, 0xfe, 0xa2, 0x92, 0xf8, 0xfc, 0x45, 0x25, 0xf1, 0xc5,
+	0x8b, 0x0a, 0xfa, 0xd1, 0x7e, 0x05, 0xfd, 0x72, 0xbf, 0x82, 0x9e, 0xed, 0x57, 0xd0, 0xf3, 0xfd,
+	0x0a, 0xfa, 0xfb, 0x7e, 0x05, 0xfd, 0x63, 0xbf, 0x92, 0xf8, 0x62, 0xbf, 0x82, 0x3e, 0x7b, 0x59,
+	0x49, 0x3c, 0x7f, 0x59, 0x49, 0x7c, 0xfe, 0xb2, 0x92, 0xf8, 0xf8, 0xed, 0x2f, 0x7b, 0x31, 0xd3,
+	0x3b, 0x6e, 0x67, 0xc4, 0xcf, 0x7b, 0xff, 0x1e, 0x00, 0x90, 0xf8, 0x9a, 0x3e, 0xd1, 0x1b, 0x00,
+	0x00,
 }
 
 func (x Direction) String() string {
@@ -5116,6 +5117,9 @@ var _Querier_serviceDesc = grpc.ServiceDesc{
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type IngesterClient interface {
 	TransferChunks(ctx context.Context, opts ...grpc.CallOption) (Ingester_TransferChunksClient, error)
+	// HandoverChunks receives the chunks of the streams handed over by a leaving ingester
+	// to the ingester owning their tokens after it left.
+	HandoverChunks(ctx context.Context, opts ...grpc.CallOption) (Ingester_HandoverChunksClient, error)
 }
 
 type ingesterClient struct {
@@ -5160,9 +5164,46 @@ func (x *ingesterTransferChunksClient) CloseAndRecv() (*TransferChunksResponse,
 	return m, nil
 }
 
+func (c *ingesterClient) HandoverChunks(ctx context.Context, opts ...grpc.CallOption) (Ingester_HandoverChunksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Ingester_serviceDesc.Streams[1], "/logproto.Ingester/HandoverChunks", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &ingesterHandoverChunksClient{stream}
+	return x, nil
+}
+
+type Ingester_HandoverChunksClient interface {
+	Send(*TimeSeriesChunk) error
+	CloseAndRecv() (*TransferChunksResponse, error)
+	grpc.ClientStream
+}
+
+type ingesterHandoverChunksClient struct {
+	grpc.ClientStream
+}
+
+func (x *ingesterHandoverChunksClient) Send(m *TimeSeriesChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *ingesterHandoverChunksClient) CloseAndRecv() (*TransferChunksResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(TransferChunksResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // IngesterServer is the server API for Ingester service.
 type IngesterServer interface {
 	TransferChunks(Ingester_TransferChunksServer) error
+	// HandoverChunks receives the chunks of the streams handed over by a leaving ingester
+	// to the ingester owning their tokens after it left.
+	HandoverChunks(Ingester_HandoverChunksServer) error
 }
 
 // UnimplementedIngesterServer can be embedded to have forward compatible implementations.
@@ -5172,6 +5213,9 @@ type UnimplementedIngesterServer struct {
 func (*UnimplementedIngesterServer) TransferChunks(srv Ingester_TransferChunksServer) error {
 	return status.Errorf(codes.Unimplemented, "method TransferChunks not implemented")
 }
+func (*UnimplementedIngesterServer) HandoverChunks(srv Ingester_HandoverChunksServer) error {
+	return status.Errorf(codes.Unimplemented, "method HandoverChunks not implemented")
+}
 
 func RegisterIngesterServer(s *grpc.Server, srv IngesterServer) {
 	s.RegisterService(&_Ingester_serviceDesc, srv)
@@ -5203,6 +5247,32 @@ func (x *ingesterTransferChunksServer) Recv() (*TimeSeriesChunk, error) {
 	return m, nil
 }
 
+func _Ingester_HandoverChunks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(IngesterServer).HandoverChunks(&ingesterHandoverChunksServer{stream})
+}
+
+type Ingester_HandoverChunksServer interface {
+	SendAndClose(*TransferChunksResponse) error
+	Recv() (*TimeSeriesChunk, error)
+	grpc.ServerStream
+}
+
+type ingesterHandoverChunksServer struct {
+	grpc.ServerStream
+}
+
+func (x *ingesterHandoverChunksServer) SendAndClose(m *TransferChunksResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *ingesterHandoverChunksServer) Recv() (*TimeSeriesChunk, error) {
+	m := new(TimeSeriesChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 var _Ingester_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "logproto.Ingester",
 	HandlerType: (*IngesterServer)(nil),
@@ -5213,6 +5283,11 @@ var _Ingester_serviceDesc = grpc.ServiceDesc{
 			Handler:       _Ingester_TransferChunks_Handler,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "HandoverChunks",
+			Handler:       _Ingester_HandoverChunks_Handler,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "pkg/logproto/logproto.proto",
 }
@@ -13,26 +13,41 @@ import (
 )
 
 type queryBlocker struct {
-	ctx    context.Context
-	q      *query
+	query  string
+	limits Limits
 	logger log.Logger
 }
 
-func newQueryBlocker(ctx context.Context, q *query) *queryBlocker {
+func newQueryBlocker(ctx context.Context, query string, limits Limits, logger log.Logger) *queryBlocker {
 	return &queryBlocker{
-		ctx:    ctx,
-		q:      q,
-		logger: logutil.WithContext(ctx, q.logger),
+		query:  query,
+		limits: limits,
+		logger: logutil.WithContext(ctx, logger),
 	}
 }
 
+// IsQueryBlocked returns true if the query is blocked by the blocked_queries of one of the tenants.
+// It is used by the frontend to reject blocked queries before splitting and sharding them.
+func IsQueryBlocked(ctx context.Context, query string, tenants []string, limits Limits, logger log.Logger) bool {
+	blocker := newQueryBlocker(ctx, query, limits, logger)
+
+	for _, tenant := range tenants {
+		if blocker.isBlocked(ctx, tenant) {
+			QueriesBlocked.WithLabelValues(tenant).Inc()
+			return true
+		}
+	}
+
+	return false
+}
+
 func (qb *queryBlocker) isBlocked(ctx context.Context, tenant string) bool {
-	blocks := qb.q.limits.BlockedQueries(ctx, tenant)
+	blocks := qb.limits.BlockedQueries(ctx, tenant)
 	if len(blocks) <= 0 {
 		return false
 	}
 
-	query := qb.q.params.Query()
+	query := qb.query
 	typ, err := QueryType(query)
 	if err != nil {
 		typ = "unknown"
@@ -45,33 +60,42 @@ func (qb *queryBlocker) isBlocked(ctx context.Context, tenant string) bool {
 		if b.Hash > 0 {
 			if b.Hash == HashedQuery(query) {
 				level.Warn(logger).Log("msg", "query blocker matched with hash policy", "hash", b.Hash, "query", query)
-				return qb.block(b, typ, logger)
+				if qb.block(b, typ, logger) {
+					return true
+				}
 			}
 
-			return false
+			continue
 		}
 
-		// if no pattern is given, assume we want to match all queries
-		if b.Pattern == "" {
-			b.Pattern = ".*"
-			b.Regex = true
+		// if no pattern is given, assume we want to match all queries.
+		// The policy is shared by all queries of the tenant, so it is not modified.
+		pattern, regex := b.Pattern, b.Regex
+		if pattern == "" {
+			pattern = ".*"
+			regex = true
 		}
 
-		if strings.TrimSpace(b.Pattern) == strings.TrimSpace(query) {
+		if strings.TrimSpace(pattern) == strings.TrimSpace(query) {
 			level.Warn(logger).Log("msg", "query blocker matched with exact match policy", "query", query)
-			return qb.block(b, typ, logger)
+			if qb.block(b, typ, logger) {
+				return true
+			}
+			continue
 		}
 
-		if b.Regex {
-			r, err := regexp.Compile(b.Pattern)
+		if regex {
+			r, err := regexp.Compile(pattern)
 			if err != nil {
-				level.Error(logger).Log("msg", "query blocker regex does not compile", "pattern", b.Pattern, "err", err)
+				level.Error(logger).Log("msg", "query blocker regex does not compile", "pattern", pattern, "err", err)
 				continue
 			}
 
 			if r.MatchString(query) {
-				level.Warn(logger).Log("msg", "query blocker matched with regex policy", "pattern", b.Pattern, "query", query)
-				return qb.block(b, typ, logger)
+				level.Warn(logger).Log("msg", "query blocker matched with regex policy", "pattern", pattern, "query", query)
+				if qb.block(b, typ, logger) {
+					return true
+				}
 			}
 		}
 	}
@@ -136,6 +136,31 @@ func TestEngine_ExecWithBlockedQueries(t *testing.T) {
 				},
 			}, nil,
 		},
+		{
+			"match after an incorrect FNV32 hash",
+			defaultQuery, []*validation.BlockedQuery{
+				{
+					Hash: HashedQuery(defaultQuery) + 1,
+				},
+				{
+					Pattern: defaultQuery,
+				},
+			}, logqlmodel.ErrBlocked,
+		},
+		{
+			"match after a pattern not matching the type",
+			defaultQuery, []*validation.BlockedQuery{
+				{
+					Pattern: defaultQuery,
+					Types:   []string{QueryTypeFilter},
+				},
+				{
+					Pattern: ".*foo.*",
+					Regex:   true,
+					Types:   []string{QueryTypeMetric},
+				},
+			}, logqlmodel.ErrBlocked,
+		},
 		{
 			"no blocked queries",
 			defaultQuery, []*validation.BlockedQuery{}, nil,
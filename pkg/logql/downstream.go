@@ -193,6 +193,8 @@ type DownstreamQuery struct {
 	Expr   syntax.Expr
 	Params Params
 	Shards Shards
+	// QuantileSketches asks for the quantile sketches of the quantile_over_time Expr instead of its quantiles.
+	QuantileSketches bool
 }
 
 // Downstreamer is an interface for deferring responsibility for query execution.
@@ -236,6 +238,15 @@ func (ev DownstreamEvaluator) Downstream(ctx context.Context, queries []Downstre
 	return results, nil
 }
 
+// unwrapQuantileSketch sends a QuantileSketchExpr as its quantile_over_time query,
+// asking for its quantile sketches.
+func unwrapQuantileSketch(qry *DownstreamQuery) {
+	if e, ok := qry.Expr.(*QuantileSketchExpr); ok {
+		qry.Expr = e.RangeAggregationExpr
+		qry.QuantileSketches = true
+	}
+}
+
 type errorQuerier struct{}
 
 func (errorQuerier) SelectLogs(_ context.Context, _ SelectLogParams) (iter.EntryIterator, error) {
@@ -268,11 +279,13 @@ func (ev *DownstreamEvaluator) StepEvaluator(
 		if e.shard != nil {
 			shards = append(shards, *e.shard)
 		}
-		results, err := ev.Downstream(ctx, []DownstreamQuery{{
+		qry := DownstreamQuery{
 			Expr:   e.SampleExpr,
 			Params: params,
 			Shards: shards,
-		}})
+		}
+		unwrapQuantileSketch(&qry)
+		results, err := ev.Downstream(ctx, []DownstreamQuery{qry})
 		if err != nil {
 			return nil, err
 		}
@@ -289,6 +302,7 @@ func (ev *DownstreamEvaluator) StepEvaluator(
 			if shard := cur.DownstreamSampleExpr.shard; shard != nil {
 				qry.Shards = Shards{*shard}
 			}
+			unwrapQuantileSketch(&qry)
 			queries = append(queries, qry)
 			cur = cur.next
 		}
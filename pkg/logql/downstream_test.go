@@ -13,6 +13,8 @@ import (
 	"github.com/weaveworks/common/user"
 
 	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/logql/sketch"
+	"github.com/grafana/loki/pkg/logql/syntax"
 )
 
 var nilShardMetrics = NewShardMapperMetrics(nil)
@@ -43,6 +45,13 @@ func TestMappingEquivalence(t *testing.T) {
 		{`sum by (a) (rate({a=~".+"}[1s]))`, false},
 		{`sum(rate({a=~".+"}[1s]))`, false},
 		{`max without (a) (rate({a=~".+"}[1s]))`, false},
+		{`min by (a) (rate({a=~".+"}[1s]))`, false},
+		{`max(sum_over_time({a=~".+"} | unwrap b [1s]))`, false},
+		{`max_over_time({a=~".+"} | unwrap b [1s]) by (a)`, false},
+		{`sum(min_over_time({a=~".+"} | unwrap b [1s]) by (a))`, false},
+		{`min(max_over_time({a=~".+"} | unwrap b [1s]) by (a))`, false},
+		{`first_over_time({a=~".+"} | unwrap b [1s])`, false},
+		{`sum by (a) (last_over_time({a=~".+"} | unwrap b [1s]))`, false},
 		{`count(rate({a=~".+"}[1s]))`, false},
 		{`avg(rate({a=~".+"}[1s]))`, true},
 		{`avg(rate({a=~".+"}[1s])) by (a)`, true},
@@ -51,6 +60,8 @@ func TestMappingEquivalence(t *testing.T) {
 		{`max(count(rate({a=~".+"}[1s])))`, false},
 		{`max(sum by (cluster) (rate({a=~".+"}[1s]))) / count(rate({a=~".+"}[1s]))`, false},
 		{`sum(rate({a=~".+"} |= "foo" != "foo"[1s]) or vector(1))`, false},
+		{`max_over_time(sum by (a) (rate({a=~".+"}[1s]))[5s:1s])`, false},
+		{`sum(avg_over_time(rate({a=~".+"}[1s])[3s:2s] offset 1s))`, false},
 		// topk prefers already-seen values in tiebreakers. Since the test data generates
 		// the same log lines for each series & the resulting promql.Vectors aren't deterministically
 		// sorted by labels, we don't expect this to pass.
@@ -80,7 +91,7 @@ func TestMappingEquivalence(t *testing.T) {
 			qry := regular.Query(params)
 			ctx := user.InjectOrgID(context.Background(), "fake")
 
-			mapper := NewShardMapper(ConstantShards(shards), nilShardMetrics)
+			mapper := NewShardMapper(ConstantShards(shards), nilShardMetrics, false)
 			_, _, mapped, err := mapper.Parse(tc.query)
 			require.Nil(t, err)
 
@@ -101,6 +112,91 @@ func TestMappingEquivalence(t *testing.T) {
 	}
 }
 
+func TestQuantileSketchMappingEquivalence(t *testing.T) {
+	var (
+		shards   = 3
+		nStreams = 60
+		rounds   = 20
+		streams  = randomStreams(nStreams, rounds+1, shards, []string{"a", "b", "c", "d"})
+		start    = time.Unix(0, 0)
+		end      = time.Unix(0, int64(time.Second*time.Duration(rounds)))
+		step     = time.Second
+		interval = time.Duration(0)
+		limit    = 100
+	)
+
+	for _, tc := range []struct {
+		query           string
+		splitByInterval time.Duration
+	}{
+		{`quantile_over_time(0.5, {a=~".+"} | logfmt | unwrap line [5s])`, 0},
+		{`quantile_over_time(0.99, {a=~".+"} | logfmt | unwrap line [5s]) by (a)`, 0},
+		{`quantile_over_time(0.9, {a=~".+"} | logfmt | unwrap line [5s] offset 2s) by (a, b)`, 0},
+		{`max(quantile_over_time(0.75, {a=~".+"} | logfmt | unwrap line [3s]) by (a))`, 0},
+		{`quantile_over_time(0.99, {a=~".+"} | logfmt | unwrap line [5s]) by (a)`, 2 * time.Second},
+		{`max(quantile_over_time(0.5, {a=~".+"} | logfmt | unwrap line [4s]) by (a))`, time.Second},
+	} {
+		q := NewMockQuerier(
+			shards,
+			streams,
+		)
+
+		opts := EngineOpts{}
+		regular := NewEngine(opts, q, NoLimits, log.NewNopLogger())
+		sharded := NewDownstreamEngine(opts, MockDownstreamer{regular}, NoLimits, log.NewNopLogger())
+
+		t.Run(tc.query, func(t *testing.T) {
+			params := NewLiteralParams(
+				tc.query,
+				start,
+				end,
+				step,
+				interval,
+				logproto.FORWARD,
+				uint32(limit),
+				nil,
+			)
+			qry := regular.Query(params)
+			ctx := user.InjectOrgID(context.Background(), "fake")
+
+			var noop bool
+			var mapped syntax.Expr
+			var err error
+			if tc.splitByInterval > 0 {
+				rangeMapper, err := NewRangeMapper(tc.splitByInterval, nilRangeMetrics, true)
+				require.Nil(t, err)
+				noop, mapped, err = rangeMapper.Parse(tc.query)
+				require.Nil(t, err)
+			} else {
+				mapper := NewShardMapper(ConstantShards(shards), nilShardMetrics, true)
+				noop, _, mapped, err = mapper.Parse(tc.query)
+				require.Nil(t, err)
+			}
+			require.False(t, noop)
+
+			shardedQry := sharded.Query(ctx, params, mapped)
+
+			res, err := qry.Exec(ctx)
+			require.Nil(t, err)
+
+			shardedRes, err := shardedQry.Exec(ctx)
+			require.Nil(t, err)
+
+			// the quantiles of the sketches are within 1% of the exact quantiles.
+			as, bs := res.Data.(promql.Matrix), shardedRes.Data.(promql.Matrix)
+			require.Equal(t, len(as), len(bs))
+			for i := range as {
+				require.Equal(t, as[i].Metric, bs[i].Metric)
+				require.Equal(t, len(as[i].Floats), len(bs[i].Floats))
+				for j, a := range as[i].Floats {
+					require.Equal(t, a.T, bs[i].Floats[j].T)
+					require.InDelta(t, a.F, bs[i].Floats[j].F, math.Abs(a.F)*sketch.DefaultRelativeAccuracy+1e-9)
+				}
+			}
+		})
+	}
+}
+
 func TestShardCounter(t *testing.T) {
 	var (
 		shards   = 3
@@ -145,7 +241,7 @@ func TestShardCounter(t *testing.T) {
 			)
 			ctx := user.InjectOrgID(context.Background(), "fake")
 
-			mapper := NewShardMapper(ConstantShards(shards), nilShardMetrics)
+			mapper := NewShardMapper(ConstantShards(shards), nilShardMetrics, false)
 			noop, _, mapped, err := mapper.Parse(tc.query)
 			require.Nil(t, err)
 
@@ -404,7 +500,7 @@ func TestRangeMappingEquivalence(t *testing.T) {
 			require.Nil(t, err)
 
 			// Downstream engine - split by range
-			rangeMapper, err := NewRangeMapper(tc.splitByInterval, nilRangeMetrics)
+			rangeMapper, err := NewRangeMapper(tc.splitByInterval, nilRangeMetrics, false)
 			require.Nil(t, err)
 			noop, rangeExpr, err := rangeMapper.Parse(tc.query)
 			require.Nil(t, err)
@@ -290,6 +290,10 @@ func (q *query) Eval(ctx context.Context) (promql_parser.Value, error) {
 		return nil, logqlmodel.ErrBlocked
 	}
 
+	if rate := samplingRate(expr); rate < 1 {
+		stats.FromContext(ctx).SetSamplingRate(rate)
+	}
+
 	switch e := expr.(type) {
 	case syntax.SampleExpr:
 		value, err := q.evalSample(ctx, e)
@@ -310,16 +314,7 @@ func (q *query) Eval(ctx context.Context) (promql_parser.Value, error) {
 }
 
 func (q *query) checkBlocked(ctx context.Context, tenants []string) bool {
-	blocker := newQueryBlocker(ctx, q)
-
-	for _, tenant := range tenants {
-		if blocker.isBlocked(ctx, tenant) {
-			QueriesBlocked.WithLabelValues(tenant).Inc()
-			return true
-		}
-	}
-
-	return false
+	return IsQueryBlocked(ctx, q.params.Query(), tenants, q.limits, q.logger)
 }
 
 // evalSample evaluate a sampleExpr
@@ -350,6 +345,16 @@ func (q *query) evalSample(ctx context.Context, expr syntax.SampleExpr) (promql_
 		return nil, err
 	}
 
+	// the frontend asks for the quantile sketches of the quantile_over_time queries it shards and splits.
+	quantileSketches := httpreq.ExtractHeader(ctx, httpreq.LokiQuantileSketchesHeader) != ""
+	if quantileSketches {
+		rangeExpr, ok := expr.(*syntax.RangeAggregationExpr)
+		if !ok || rangeExpr.Operation != syntax.OpRangeTypeQuantile {
+			return nil, fmt.Errorf("%w: the quantile sketches can only be returned for a quantile_over_time query", logqlmodel.ErrParse)
+		}
+		expr = &QuantileSketchExpr{rangeExpr}
+	}
+
 	stepEvaluator, err := q.evaluator.StepEvaluator(ctx, q.evaluator, expr, q.params)
 	if err != nil {
 		return nil, err
@@ -360,13 +365,29 @@ func (q *query) evalSample(ctx context.Context, expr syntax.SampleExpr) (promql_
 	maxSeries := validation.SmallestPositiveIntPerTenant(tenantIDs, maxSeriesCapture)
 	seriesIndex := map[uint64]*promql.Series{}
 
+	// every bucket of a quantile sketch is returned as a series of its own,
+	// so only the series of the sketches are counted against the limit.
+	sketchSeries := map[uint64]struct{}{}
+	buf := make([]byte, 0, 1024)
+	exceedsMaxSeries := func(vec promql.Vector, count int) bool {
+		if !quantileSketches {
+			return count > maxSeries
+		}
+		for _, p := range vec {
+			var key uint64
+			key, buf = p.Metric.HashWithoutLabels(buf, QuantileSketchBucketLabel)
+			sketchSeries[key] = struct{}{}
+		}
+		return len(sketchSeries) > maxSeries
+	}
+
 	next, ts, vec := stepEvaluator.Next()
 	if stepEvaluator.Error() != nil {
 		return nil, stepEvaluator.Error()
 	}
 
 	// fail fast for the first step or instant query
-	if len(vec) > maxSeries {
+	if exceedsMaxSeries(vec, len(vec)) {
 		return nil, logqlmodel.NewSeriesLimitError(maxSeries)
 	}
 
@@ -408,7 +429,7 @@ func (q *query) evalSample(ctx context.Context, expr syntax.SampleExpr) (promql_
 			})
 		}
 		// as we slowly build the full query for each steps, make sure we don't go over the limit of unique series.
-		if len(seriesIndex) > maxSeries {
+		if exceedsMaxSeries(vec, len(seriesIndex)) {
 			return nil, logqlmodel.NewSeriesLimitError(maxSeries)
 		}
 		next, ts, vec = stepEvaluator.Next()
@@ -441,6 +462,18 @@ func (q *query) checkIntervalLimit(expr syntax.SampleExpr, limit time.Duration)
 	return err
 }
 
+// samplingRate returns the fraction of the log lines kept by the sample stages of the expression.
+// The stages sample the same log lines, so the smallest rate applies.
+func samplingRate(expr syntax.Expr) float64 {
+	rate := 1.
+	expr.Walk(func(e interface{}) {
+		if s, ok := e.(*syntax.SampleFilterExpr); ok && s.Rate < rate {
+			rate = s.Rate
+		}
+	})
+	return rate
+}
+
 func (q *query) evalLiteral(_ context.Context, expr *syntax.LiteralExpr) (promql_parser.Value, error) {
 	value, err := expr.Value()
 	if err != nil {
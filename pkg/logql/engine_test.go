@@ -274,6 +274,12 @@ func TestEngine_LogsInstantQuery(t *testing.T) {
 			[]SelectSampleParams{},
 			promql.Vector{promql.Sample{T: 5 * 60 * 1000, F: 1, Metric: labels.FromStrings("app", "foo")}},
 		},
+		{
+			`absent_over_time(rate({app="foo"} |~".+bar" [10s])[1m:30s])`, time.Unix(60, 0), logproto.BACKWARD, 10,
+			[][]logproto.Series{},
+			[]SelectSampleParams{},
+			promql.Vector{promql.Sample{T: 60 * 1000, F: 1, Metric: labels.FromStrings("app", "foo")}},
+		},
 		{
 			`avg(count_over_time({app=~"foo|bar"} |~".+bar" [1m]))`, time.Unix(60, 0), logproto.FORWARD, 100,
 			[][]logproto.Series{
@@ -531,6 +537,44 @@ func TestEngine_LogsInstantQuery(t *testing.T) {
 				{T: 60 * 1000, F: 0.2, Metric: labels.FromStrings("app", "fuzz")},
 			},
 		},
+		{
+			`approx_topk(1,sum by (app) (rate(({app=~"foo|bar"} |~".+bar")[1m])))`, time.Unix(60, 0), logproto.FORWARD, 100,
+			[][]logproto.Series{
+				{
+					newSeries(testSize, factor(2, identity), `{app="foo"}`), newSeries(testSize, offset(46, identity), `{app="bar"}`),
+				},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(0, 0), End: time.Unix(60, 0), Selector: `sum by (app)(rate({app=~"foo|bar"}|~".+bar"[1m]))`}},
+			},
+			promql.Vector{
+				{T: 60 * 1000, F: 0.5, Metric: labels.FromStrings("app", "foo")},
+			},
+		},
+		{
+			`sum_over_time(count_over_time({app="foo"}[10s])[1m:30s])`, time.Unix(60, 0), logproto.FORWARD, 100,
+			[][]logproto.Series{
+				{newSeries(testSize, identity, `{app="foo"}`)},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(20, 0), End: time.Unix(60, 0), Selector: `count_over_time({app="foo"}[10s])`}},
+			},
+			promql.Vector{
+				{T: 60 * 1000, F: 20, Metric: labels.FromStrings("app", "foo")},
+			},
+		},
+		{
+			`max_over_time(sum(count_over_time({app="foo"}[10s]))[1m:30s] offset 10s)`, time.Unix(70, 0), logproto.FORWARD, 100,
+			[][]logproto.Series{
+				{newSeries(testSize, identity, `{app="foo"}`), newSeries(testSize, factor(2, identity), `{app="bar"}`)},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(20, 0), End: time.Unix(60, 0), Selector: `sum(count_over_time({app="foo"}[10s]))`}},
+			},
+			promql.Vector{
+				{T: 70 * 1000, F: 15, Metric: labels.EmptyLabels()},
+			},
+		},
 		{
 			`bottomk(2,rate(({app=~"foo|bar"} |~".+bar")[1m]))`, time.Unix(60, 0), logproto.FORWARD, 100,
 			[][]logproto.Series{
@@ -2235,6 +2279,33 @@ func TestEngine_Stats(t *testing.T) {
 	require.Equal(t, queueTime.Seconds(), r.Statistics.Summary.QueueTime)
 }
 
+func TestEngine_Stats_SamplingRate(t *testing.T) {
+	eng := NewEngine(EngineOpts{}, &statsQuerier{}, NoLimits, log.NewNopLogger())
+
+	for _, tc := range []struct {
+		qs       string
+		expected float64
+	}{
+		{`{foo="bar"}`, 0},
+		{`{foo="bar"} | sample 1`, 0},
+		{`{foo="bar"} | sample 0.1 | logfmt | sample 0.5`, 0.1},
+		{`sum(count_over_time({foo="bar"} | sample 0.5 [1m])) / sum(count_over_time({foo="bar"} | sample 0.01 [1m]))`, 0.01},
+	} {
+		t.Run(tc.qs, func(t *testing.T) {
+			q := eng.Query(LiteralParams{
+				qs:        tc.qs,
+				start:     time.Now(),
+				end:       time.Now(),
+				direction: logproto.BACKWARD,
+				limit:     1000,
+			})
+			r, err := q.Exec(user.InjectOrgID(context.Background(), "fake"))
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, r.Statistics.Summary.SamplingRate)
+		})
+	}
+}
+
 type metaQuerier struct{}
 
 func (metaQuerier) SelectLogs(ctx context.Context, _ SelectLogParams) (iter.EntryIterator, error) {
@@ -2404,6 +2475,37 @@ func TestStepEvaluator_Error(t *testing.T) {
 	}
 }
 
+func TestEngine_SubqueryMaxPoints(t *testing.T) {
+	eng := NewEngine(EngineOpts{}, getLocalQuerier(100000), NoLimits, log.NewNopLogger())
+
+	for _, test := range []struct {
+		qs             string
+		expectLimitErr bool
+	}{
+		{`sum_over_time(count_over_time({app="foo"}[10s])[1h:1s])`, false},
+		// the steps of the query add to the range of the subquery.
+		{`sum_over_time(count_over_time({app="foo"}[10s])[3h:1s])`, true},
+		{`sum_over_time(count_over_time({app="foo"}[10s])[1d:1m])`, false},
+	} {
+		t.Run(test.qs, func(t *testing.T) {
+			q := eng.Query(LiteralParams{
+				qs:        test.qs,
+				start:     time.Unix(100000, 0),
+				end:       time.Unix(101000, 0),
+				step:      60 * time.Second,
+				direction: logproto.FORWARD,
+				limit:     1000,
+			})
+			_, err := q.Exec(user.InjectOrgID(context.Background(), "fake"))
+			if test.expectLimitErr {
+				require.True(t, errors.Is(err, logqlmodel.ErrLimit))
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestEngine_MaxSeries(t *testing.T) {
 	eng := NewEngine(EngineOpts{}, getLocalQuerier(100000), &fakeLimits{maxSeries: 1}, log.NewNopLogger())
 
@@ -2440,6 +2542,45 @@ func TestEngine_MaxSeries(t *testing.T) {
 	}
 }
 
+func TestEngine_MaxSeriesQuantileSketches(t *testing.T) {
+	streams := randomStreams(2, 20, 1, []string{"a"})
+	ctx := httpreq.InjectHeader(user.InjectOrgID(context.Background(), "fake"), httpreq.LokiQuantileSketchesHeader, "true")
+
+	for _, test := range []struct {
+		qs             string
+		maxSeries      int
+		expectLimitErr bool
+		expectErr      bool
+	}{
+		{`quantile_over_time(0.99, {a=~".+"} | logfmt | unwrap line [5s]) by (index)`, 2, false, false},
+		{`quantile_over_time(0.99, {a=~".+"} | logfmt | unwrap line [5s]) by (index)`, 1, true, false},
+		{`sum_over_time({a=~".+"} | logfmt | unwrap line [5s]) by (index)`, 2, false, true},
+	} {
+		t.Run(fmt.Sprintf("%s max_series=%d", test.qs, test.maxSeries), func(t *testing.T) {
+			eng := NewEngine(EngineOpts{}, NewMockQuerier(1, streams), &fakeLimits{maxSeries: test.maxSeries}, log.NewNopLogger())
+			q := eng.Query(LiteralParams{
+				qs:        test.qs,
+				start:     time.Unix(0, 0),
+				end:       time.Unix(20, 0),
+				step:      time.Second,
+				direction: logproto.FORWARD,
+				limit:     1000,
+			})
+			res, err := q.Exec(ctx)
+			switch {
+			case test.expectLimitErr:
+				require.True(t, errors.Is(err, logqlmodel.ErrLimit))
+			case test.expectErr:
+				require.Error(t, err)
+			default:
+				require.NoError(t, err)
+				// the buckets of the sketches are series of their own.
+				require.Greater(t, len(res.Data.(promql.Matrix)), test.maxSeries)
+			}
+		})
+	}
+}
+
 func TestEngine_MaxRangeInterval(t *testing.T) {
 	eng := NewEngine(EngineOpts{}, getLocalQuerier(100000), &fakeLimits{rangeLimit: 24 * time.Hour, maxSeries: 100000}, log.NewNopLogger())
 
@@ -103,24 +103,33 @@ func GetRangeType(q Params) QueryRangeType {
 	return RangeType
 }
 
-// Sortable logql contain sort or sort_desc.
+// Sortable logql contain sort or sort_desc, whose order is kept up to the result.
 func Sortable(q Params) (bool, error) {
-	var sortable bool
 	expr, err := syntax.ParseSampleExpr(q.Query())
 	if err != nil {
 		return false, err
 	}
-	expr.Walk(func(e interface{}) {
-		rangeExpr, ok := e.(*syntax.VectorAggregationExpr)
-		if !ok {
-			return
+	return keepsSortOrder(expr), nil
+}
+
+// keepsSortOrder returns whether the result of the expression is in the order of a sort
+// or sort_desc. The other aggregations and the binary operations between vectors don't keep the
+// order of their inputs, contrary to label_replace and the binary operations with literals.
+func keepsSortOrder(expr syntax.SampleExpr) bool {
+	switch e := expr.(type) {
+	case *syntax.VectorAggregationExpr:
+		return e.Operation == syntax.OpTypeSort || e.Operation == syntax.OpTypeSortDesc
+	case *syntax.LabelReplaceExpr:
+		return keepsSortOrder(e.Left)
+	case *syntax.BinOpExpr:
+		if _, ok := e.RHS.(*syntax.LiteralExpr); ok {
+			return keepsSortOrder(e.SampleExpr)
 		}
-		if rangeExpr.Operation == syntax.OpTypeSort || rangeExpr.Operation == syntax.OpTypeSortDesc {
-			sortable = true
-			return
+		if _, ok := e.SampleExpr.(*syntax.LiteralExpr); ok {
+			return keepsSortOrder(e.RHS)
 		}
-	})
-	return sortable, nil
+	}
+	return false
 }
 
 // Evaluator is an interface for iterating over data at different nodes in the AST
@@ -223,10 +232,27 @@ func (ev *DefaultEvaluator) StepEvaluator(
 			return nil, err
 		}
 		return rangeAggEvaluator(iter.NewPeekingSampleIterator(it), e, q, e.Left.Offset)
+	case *QuantileSketchExpr:
+		it, err := ev.querier.SelectSamples(ctx, SelectSampleParams{
+			&logproto.SampleQueryRequest{
+				Start:    q.Start().Add(-e.Left.Interval).Add(-e.Left.Offset),
+				End:      q.End().Add(-e.Left.Offset),
+				Selector: e.RangeAggregationExpr.String(),
+				Shards:   q.Shards(),
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return quantileSketchEvaluator(iter.NewPeekingSampleIterator(it), e.RangeAggregationExpr, q, e.Left.Offset)
 	case *syntax.BinOpExpr:
 		return binOpStepEvaluator(ctx, nextEv, e, q)
 	case *syntax.LabelReplaceExpr:
 		return labelReplaceEvaluator(ctx, nextEv, e, q)
+	case *syntax.SubqueryExpr:
+		return subqueryEvaluator(ctx, nextEv, e, q)
+	case *QuantileSketchEvalExpr:
+		return quantileSketchEvalEvaluator(ctx, nextEv, e, q)
 	case *syntax.VectorExpr:
 		val, err := e.Value()
 		if err != nil {
@@ -247,6 +273,15 @@ func vectorAggEvaluator(
 	if expr.Grouping == nil {
 		return nil, errors.Errorf("aggregation operator '%q' without grouping", expr.Operation)
 	}
+	if expr.Operation == syntax.OpTypeApproxTopK {
+		// approx_topk is evaluated as an exact topk, as the shards return the partial sums of all the groups anyway.
+		expr = &syntax.VectorAggregationExpr{
+			Left:      expr.Left,
+			Grouping:  expr.Grouping,
+			Params:    expr.Params,
+			Operation: syntax.OpTypeTopK,
+		}
+	}
 	nextEvaluator, err := ev.StepEvaluator(ctx, ev, expr.Left, q)
 	if err != nil {
 		return nil, err
@@ -52,6 +52,16 @@ func TestDefaultEvaluator_Sortable(t *testing.T) {
 	}
 	require.Equal(t, false, sortableSum)
 
+	for qs, expected := range map[string]bool{
+		`sort_desc(rate({app="foo"}[1m])) + 1`:                                   true,
+		`label_replace(sort(rate({app="foo"}[1m])), "dst", "$1", "app", "(.*)")`: true,
+		`sum by (app) (sort(rate({app="foo"}[1m])))`:                             false,
+		`sort(rate({app="foo"}[1m])) / rate({app="bar"}[1m])`:                    false,
+	} {
+		sortable, err := Sortable(LiteralParams{qs: qs})
+		require.NoError(t, err)
+		require.Equal(t, expected, sortable, qs)
+	}
 }
 func TestEvaluator_mergeBinOpComparisons(t *testing.T) {
 	for _, tc := range []struct {
@@ -204,6 +204,13 @@ func newOrFilter(left Filterer, right Filterer) Filterer {
 		return left
 	}
 
+	// Join the contains filters in a single set of matches.
+	if leftMatches, ok := containsMatches(left); ok {
+		if rightMatches, ok := containsMatches(right); ok {
+			return &containsAnyFilter{matches: append(leftMatches, rightMatches...)}
+		}
+	}
+
 	return orFilter{
 		left:  left,
 		right: right,
@@ -292,6 +299,9 @@ func (l equalFilter) String() string {
 }
 
 func newEqualFilter(match []byte, caseInsensitive bool) Filterer {
+	if caseInsensitive {
+		match = bytes.ToLower(match)
+	}
 	return equalFilter{match, caseInsensitive}
 }
 
@@ -311,44 +321,51 @@ func contains(line, substr []byte, caseInsensitive bool) bool {
 	return containsLower(line, substr)
 }
 
+// containsLower returns whether the line contains the lowercase substr, ignoring the case of the line.
 func containsLower(line, substr []byte) bool {
 	if len(substr) == 0 {
 		return true
 	}
-	if len(substr) > len(line) {
-		return false
-	}
-	j := 0
 	for len(line) > 0 {
+		if hasLowerPrefix(line, substr) {
+			return true
+		}
+		if line[0] < utf8.RuneSelf {
+			line = line[1:]
+			continue
+		}
+		_, wid := utf8.DecodeRune(line)
+		line = line[wid:]
+	}
+	return false
+}
+
+// hasLowerPrefix returns whether the line starts with the lowercase prefix, ignoring the case of the line.
+func hasLowerPrefix(line, prefix []byte) bool {
+	for len(prefix) > 0 {
+		if len(line) == 0 {
+			return false
+		}
 		// ascii fast case
-		if c := line[0]; c < utf8.RuneSelf && substr[j] < utf8.RuneSelf {
-			if c == substr[j] || c+'a'-'A' == substr[j] || c == substr[j]+'a'-'A' {
-				j++
-				if j == len(substr) {
-					return true
-				}
-				line = line[1:]
-				continue
+		if c, m := line[0], prefix[0]; c < utf8.RuneSelf && m < utf8.RuneSelf {
+			if 'A' <= c && c <= 'Z' {
+				c += 'a' - 'A'
 			}
-			line = line[1:]
-			j = 0
+			if c != m {
+				return false
+			}
+			line, prefix = line[1:], prefix[1:]
 			continue
 		}
 		// unicode slow case
 		lr, lwid := utf8.DecodeRune(line)
-		mr, mwid := utf8.DecodeRune(substr[j:])
-		if lr == mr || mr == unicode.To(unicode.LowerCase, lr) {
-			j += mwid
-			if j == len(substr) {
-				return true
-			}
-			line = line[lwid:]
-			continue
+		mr, mwid := utf8.DecodeRune(prefix)
+		if lr != mr && unicode.ToLower(lr) != mr {
+			return false
 		}
-		line = line[lwid:]
-		j = 0
+		line, prefix = line[lwid:], prefix[mwid:]
 	}
-	return false
+	return true
 }
 
 func (l containsFilter) ToStage() Stage {
@@ -406,6 +423,93 @@ func (f containsAllFilter) ToStage() Stage {
 	}
 }
 
+type containsAnyFilter struct {
+	matches []containsFilter
+}
+
+// containsMatches returns a copy of the matches of a contains filter, or of a set of contains filters.
+func containsMatches(f Filterer) ([]containsFilter, bool) {
+	switch c := f.(type) {
+	case *containsFilter:
+		return []containsFilter{*c}, true
+	case *containsAnyFilter:
+		return append([]containsFilter(nil), c.matches...), true
+	}
+	return nil, false
+}
+
+func (f containsAnyFilter) Filter(line []byte) bool {
+	for _, m := range f.matches {
+		if contains(line, m.match, m.caseInsensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f containsAnyFilter) ToStage() Stage {
+	return StageFunc{
+		process: func(_ int64, line []byte, _ *LabelsBuilder) ([]byte, bool) {
+			return line, f.Filter(line)
+		},
+	}
+}
+
+type prefixFilter struct {
+	match           []byte
+	caseInsensitive bool
+}
+
+func (l prefixFilter) Filter(line []byte) bool {
+	if !l.caseInsensitive {
+		return bytes.HasPrefix(line, l.match)
+	}
+	return hasLowerPrefix(line, l.match)
+}
+
+func (l prefixFilter) ToStage() Stage {
+	return StageFunc{
+		process: func(_ int64, line []byte, _ *LabelsBuilder) ([]byte, bool) {
+			return line, l.Filter(line)
+		},
+	}
+}
+
+func (l prefixFilter) String() string {
+	return string(l.match)
+}
+
+// newPrefixFilter creates a filter that checks if a log line starts with a match.
+func newPrefixFilter(match []byte, caseInsensitive bool) Filterer {
+	if len(match) == 0 {
+		return TrueFilter
+	}
+	if caseInsensitive {
+		match = bytes.ToLower(match)
+	}
+	return prefixFilter{match, caseInsensitive}
+}
+
+type suffixFilter struct {
+	match []byte
+}
+
+func (l suffixFilter) Filter(line []byte) bool {
+	return bytes.HasSuffix(line, l.match)
+}
+
+func (l suffixFilter) ToStage() Stage {
+	return StageFunc{
+		process: func(_ int64, line []byte, _ *LabelsBuilder) ([]byte, bool) {
+			return line, l.Filter(line)
+		},
+	}
+}
+
+func (l suffixFilter) String() string {
+	return string(l.match)
+}
+
 // NewFilter creates a new line filter from a match string and type.
 func NewFilter(match string, mt labels.MatchType) (Filterer, error) {
 	switch mt {
@@ -472,6 +576,9 @@ func simplify(reg *syntax.Regexp, isLabel bool) (Filterer, bool) {
 	case syntax.OpAlternate:
 		return simplifyAlternate(reg, isLabel)
 	case syntax.OpConcat:
+		if f, ok := simplifyAnchoredConcat(reg, isLabel); ok {
+			return f, true
+		}
 		return simplifyConcat(reg, nil)
 	case syntax.OpCapture:
 		util.ClearCapture(reg)
@@ -579,6 +686,52 @@ func simplifyConcat(reg *syntax.Regexp, baseLiteral []byte) (Filterer, bool) {
 	return nil, false
 }
 
+// simplifyAnchoredConcat simplifies the literals anchored to the beginning and/or the end of the line,
+// such as ^foo, ^foo.*, foo$, .*foo$ and ^foo$, with prefix, suffix and equal filters.
+// The case insensitive literals anchored only to the end of the line are not simplified.
+func simplifyAnchoredConcat(reg *syntax.Regexp, isLabel bool) (Filterer, bool) {
+	// label regexes are already anchored to the beginning and the end of the value.
+	if isLabel {
+		return nil, false
+	}
+	util.ClearCapture(reg.Sub...)
+	subs := reg.Sub
+	begin := len(subs) > 0 && subs[0].Op == syntax.OpBeginText
+	if begin {
+		subs = subs[1:]
+	}
+	end := len(subs) > 0 && subs[len(subs)-1].Op == syntax.OpEndText
+	if end {
+		subs = subs[:len(subs)-1]
+	}
+	// the side of the literal that isn't anchored can match anything.
+	switch {
+	case begin && !end && len(subs) == 2 && isAnyCharStar(subs[1]):
+		subs = subs[:1]
+	case end && !begin && len(subs) == 2 && isAnyCharStar(subs[0]):
+		subs = subs[1:]
+	}
+	if !(begin || end) || len(subs) != 1 || subs[0].Op != syntax.OpLiteral {
+		return nil, false
+	}
+
+	match := []byte(string(subs[0].Rune))
+	caseInsensitive := util.IsCaseInsensitive(subs[0])
+	switch {
+	case begin && end:
+		return newEqualFilter(match, caseInsensitive), true
+	case begin:
+		return newPrefixFilter(match, caseInsensitive), true
+	case !caseInsensitive:
+		return suffixFilter{match: match}, true
+	}
+	return nil, false
+}
+
+func isAnyCharStar(reg *syntax.Regexp) bool {
+	return reg.Op == syntax.OpStar && reg.Sub[0].Op == syntax.OpAnyCharNotNL
+}
+
 // simplifyConcatAlternate simplifies concat alternate operations.
 // A concat alternate is found when a concat operation has a sub alternate and is preceded by a literal.
 // For instance bar|b|buzz is expressed as b(ar|(?:)|uzz) => b concat alternate(ar,(?:),uzz).
@@ -10,7 +10,7 @@ import (
 func Test_SimplifiedRegex(t *testing.T) {
 	fixtures := []string{
 		"foo", "foobar", "bar", "foobuzz", "buzz", "f", "  ", "fba", "foofoofoo", "b", "foob", "bfoo", "FoO",
-		"foo, 世界", allunicode(), "fooÏbar",
+		"foo, 世界", allunicode(), "fooÏbar", "fofoo", "FFOO", "fOï界ÏBar", "foo\nbar", "bar\nfoo", "FOOBAR",
 	}
 	for _, test := range []struct {
 		re string
@@ -67,6 +67,16 @@ func Test_SimplifiedRegex(t *testing.T) {
 		{"(?i)f|fatal|e.*", true, newOrFilter(newOrFilter(newContainsFilter([]byte("F"), true), newContainsFilter([]byte("FATAL"), true)), newContainsFilter([]byte("E"), true)), true},
 		{"(?i).*foo.*", true, newContainsFilter([]byte("FOO"), true), true},
 		{".+", true, ExistsFilter, true},
+		{"foo|bar|buzz", true, &containsAnyFilter{matches: []containsFilter{{match: []byte("foo")}, {match: []byte("bar")}, {match: []byte("buzz")}}}, true},
+		{"^foo", true, newPrefixFilter([]byte("foo"), false), true},
+		{"^(foo).*", true, newPrefixFilter([]byte("foo"), false), true},
+		{"(?i)^foo", true, newPrefixFilter([]byte("foo"), true), true},
+		{"^foo", true, newNotFilter(newPrefixFilter([]byte("foo"), false)), false},
+		{"bar$", true, suffixFilter{match: []byte("bar")}, true},
+		{".*bar$", true, suffixFilter{match: []byte("bar")}, true},
+		{"^foobar$", true, newEqualFilter([]byte("foobar"), false), true},
+		{"(?i)^foo$", true, newEqualFilter([]byte("foo"), true), true},
+		{"^foo|bar", true, newOrFilter(newPrefixFilter([]byte("foo"), false), newContainsFilter([]byte("bar"), false)), true},
 
 		// These regexes are rewritten to be non-greedy but no new
 		// filter is generated.
@@ -85,6 +95,8 @@ func Test_SimplifiedRegex(t *testing.T) {
 		{`.*f.*oo|fo{1,2}`, true, nil, true},
 		{"f|f(?i)oo", true, nil, true},
 		{".foo+", true, nil, true},
+		{"(?i)foo$", true, nil, true},
+		{"^.*foo", true, nil, true},
 	} {
 		t.Run(test.re, func(t *testing.T) {
 			d, err := newRegexpFilter(test.re, test.re, test.match)
@@ -219,3 +231,26 @@ func benchmarkRegex(b *testing.B, re, line string, match bool) {
 func Test_rune(t *testing.T) {
 	require.True(t, newContainsFilter([]byte("foo"), true).Filter([]byte("foo")))
 }
+
+func Test_containsLower(t *testing.T) {
+	for _, tt := range []struct {
+		line, substr string
+		expected     bool
+	}{
+		{"foo", "", true},
+		{"", "foo", false},
+		{"FOO", "foo", true},
+		{"aab", "ab", true},
+		{"xERRerror", "error", true},
+		{"fofoo", "foo", true},
+		{"ÉÉa", "éa", true},
+		{"ÏB界", "ïb界", true},
+		{"fo", "foo", false},
+		{"@", "`", false},
+		{"[", "{", false},
+	} {
+		t.Run(tt.line+"/"+tt.substr, func(t *testing.T) {
+			require.Equal(t, tt.expected, containsLower([]byte(tt.line), []byte(tt.substr)))
+		})
+	}
+}
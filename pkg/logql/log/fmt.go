@@ -12,6 +12,7 @@ import (
 
 	"github.com/Masterminds/sprig/v3"
 	"github.com/grafana/regexp"
+	lru "github.com/hashicorp/golang-lru"
 
 	"github.com/grafana/loki/pkg/logqlmodel"
 )
@@ -38,21 +39,21 @@ var (
 		"TrimSuffix": strings.TrimSuffix,
 		"TrimSpace":  strings.TrimSpace,
 		"regexReplaceAll": func(regex string, s string, repl string) (string, error) {
-			r, err := regexp.Compile(regex)
+			r, err := compileTemplateRegex(regex)
 			if err != nil {
 				return "", err
 			}
 			return r.ReplaceAllString(s, repl), nil
 		},
 		"regexReplaceAllLiteral": func(regex string, s string, repl string) (string, error) {
-			r, err := regexp.Compile(regex)
+			r, err := compileTemplateRegex(regex)
 			if err != nil {
 				return "", err
 			}
 			return r.ReplaceAllLiteralString(s, repl), nil
 		},
 		"count": func(regexsubstr string, s string) (int, error) {
-			r, err := regexp.Compile(regexsubstr)
+			r, err := compileTemplateRegex(regexsubstr)
 			if err != nil {
 				return 0, err
 			}
@@ -119,6 +120,25 @@ var (
 	}
 )
 
+// maxTemplateRegexes is the maximum number of regexes of the template functions kept compiled.
+const maxTemplateRegexes = 1000
+
+// templateRegexes caches the regexes of the template functions, which are evaluated for every line.
+// The regexes can come from the labels, so the least recently used ones are evicted.
+var templateRegexes, _ = lru.New(maxTemplateRegexes)
+
+func compileTemplateRegex(expr string) (*regexp.Regexp, error) {
+	if r, ok := templateRegexes.Get(expr); ok {
+		return r.(*regexp.Regexp), nil
+	}
+	r, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	templateRegexes.Add(expr, r)
+	return r, nil
+}
+
 func addLineAndTimestampFunctions(currLine func() string, currTimestamp func() int64) map[string]interface{} {
 	functions := make(map[string]interface{}, len(functionMap)+2)
 	for k, v := range functionMap {
@@ -459,13 +479,21 @@ type Decolorizer struct{}
 // RegExp to select ANSI characters courtesy of https://github.com/acarl005/stripansi
 const ansiPattern = "[\u001B\u009B][[\\]()#;?]*(?:(?:(?:[a-zA-Z\\d]*(?:;[a-zA-Z\\d]*)*)?\u0007)|(?:(?:\\d{1,4}(?:;\\d{0,4})*)?[\\dA-PRZcf-ntqry=><~]))"
 
-var ansiRegex = regexp.MustCompile(ansiPattern)
+var (
+	ansiRegex = regexp.MustCompile(ansiPattern)
+	// ansiCSI is the UTF-8 encoding of the 8-bit control sequence introducer.
+	ansiCSI = []byte("\u009B")
+)
 
 func NewDecolorizer() (*Decolorizer, error) {
 	return &Decolorizer{}, nil
 }
 
 func (Decolorizer) Process(_ int64, line []byte, _ *LabelsBuilder) ([]byte, bool) {
+	// Most lines are not colored, so they are returned as is without going through the regexp.
+	if bytes.IndexByte(line, '\u001B') == -1 && !bytes.Contains(line, ansiCSI) {
+		return line, true
+	}
 	return ansiRegex.ReplaceAll(line, []byte{}), true
 }
 func (Decolorizer) RequiredLabelNames() []string { return []string{} }
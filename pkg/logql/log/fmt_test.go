@@ -753,6 +753,26 @@ func Test_InvalidRegex(t *testing.T) {
 	})
 }
 
+func Test_compileTemplateRegex(t *testing.T) {
+	r, err := compileTemplateRegex("(p)+")
+	require.NoError(t, err)
+	cached, err := compileTemplateRegex("(p)+")
+	require.NoError(t, err)
+	require.Same(t, r, cached)
+
+	_, err = compileTemplateRegex("a|b|\\q")
+	require.Error(t, err)
+	require.False(t, templateRegexes.Contains("a|b|\\q"))
+
+	// The least recently used regexes are evicted.
+	for i := 0; i < maxTemplateRegexes; i++ {
+		_, err = compileTemplateRegex(fmt.Sprintf("(p%d)+", i))
+		require.NoError(t, err)
+	}
+	require.False(t, templateRegexes.Contains("(p)+"))
+	require.Equal(t, maxTemplateRegexes, templateRegexes.Len())
+}
+
 func Test_validate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -907,6 +927,7 @@ func TestDecolorizer(t *testing.T) {
 	}{
 		{"uncolored text remains the same", []byte("sample text"), []byte("sample text")},
 		{"colored text loses color", []byte("\033[0;32mgreen\033[0m \033[0;31mred\033[0m"), []byte("green red")},
+		{"8-bit control sequences are stripped", []byte("\u009B1mbold\u009B0m"), []byte("bold")},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
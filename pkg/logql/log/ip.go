@@ -1,6 +1,7 @@
 package log
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"unicode"
@@ -23,6 +24,11 @@ const (
 	IPv6Charset = "0123456789abcdefABCDEF:."
 )
 
+var (
+	ipv4Chars = newCharset(IPv4Charset)
+	ipv6Chars = newCharset(IPv6Charset)
+)
+
 // Should be one of the netip.Addr, netip.Prefix, netipx.IPRange.
 type IPMatcher interface{}
 
@@ -187,12 +193,13 @@ func (f *ipFilter) filter(line []byte) bool {
 
 	n := len(line)
 
-	filterFn := func(line []byte, start int, charset string) (bool, int) {
-		iplen := bytesSpan(line[start:], []byte(charset))
+	filterFn := func(line []byte, start int, charset *[256]bool) (bool, int) {
+		iplen := bytesSpan(line[start:], charset)
 		if iplen < 0 {
 			return false, 0
 		}
-		ip, err := netip.ParseAddr(string(line[start : start+iplen]))
+		// The dots ending the sentences are not part of the addresses.
+		ip, err := netip.ParseAddr(string(bytes.TrimRight(line[start:start+iplen], ".")))
 		if err == nil {
 			if containsIP(f.matcher, ip) {
 				return true, 0
@@ -205,7 +212,7 @@ func (f *ipFilter) filter(line []byte) bool {
 	// It uses IPv4 and IPv6 prefix hints to find the IP addresses faster without using regexp.
 	for i := 0; i < n; i++ {
 		if i+3 < n && ipv4Hint([4]byte{line[i], line[i+1], line[i+2], line[i+3]}) {
-			ok, iplen := filterFn(line, i, IPv4Charset)
+			ok, iplen := filterFn(line, i, &ipv4Chars)
 			if ok {
 				return true
 			}
@@ -214,7 +221,7 @@ func (f *ipFilter) filter(line []byte) bool {
 		}
 
 		if i+4 < n && ipv6Hint([5]byte{line[i], line[i+1], line[i+2], line[i+3], line[i+4]}) {
-			ok, iplen := filterFn(line, i, IPv6Charset)
+			ok, iplen := filterFn(line, i, &ipv6Chars)
 			if ok {
 				return true
 			}
@@ -278,18 +285,20 @@ func isHexDigit(r byte) bool {
 	return unicode.IsDigit(rune(r)) || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
 }
 
+// newCharset returns the set of the given chars, to look them up without allocating.
+func newCharset(chars string) (set [256]bool) {
+	for i := 0; i < len(chars); i++ {
+		set[chars[i]] = true
+	}
+	return set
+}
+
 // bytesSpan is same as C's `strcspan()` function.
 // It returns the number of chars in the initial segment of `s`
 // which consist only of chars from `accept`.
-func bytesSpan(s, accept []byte) int {
-	m := make(map[byte]bool)
-
-	for _, r := range accept {
-		m[r] = true
-	}
-
+func bytesSpan(s []byte, accept *[256]bool) int {
 	for i, r := range s {
-		if !m[r] {
+		if !accept[r] {
 			return i
 		}
 	}
@@ -28,8 +28,9 @@ func Test_IPFilter(t *testing.T) {
 				"x",
 				"hello world!",
 				"",
+				"vm connected from 192.168.0.1.", // the ending dot is not part of the address
 			},
-			expected: []int{1}, // should match with only line at index `1` from the input
+			expected: []int{1, 5}, // should match with only lines at index `1` and `5` from the input
 
 		},
 		{
@@ -8,6 +8,8 @@ import (
 
 type KeepLabels struct {
 	keepLabels []KeepLabel
+	// buf is reused across the lines, like the buffers of the parsers.
+	buf labels.Labels
 }
 
 type KeepLabel struct {
@@ -31,8 +33,8 @@ func (kl *KeepLabels) Process(_ int64, line []byte, lbls *LabelsBuilder) ([]byte
 		return line, true
 	}
 
-	// TODO: Reuse buf?
-	for _, lb := range lbls.UnsortedLabels(nil) {
+	kl.buf = lbls.UnsortedLabels(kl.buf)
+	for _, lb := range kl.buf {
 		if isSpecialLabel(lb.Name) {
 			continue
 		}
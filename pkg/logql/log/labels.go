@@ -244,6 +244,19 @@ func (b *LabelsBuilder) Set(n, v string) *LabelsBuilder {
 	return b
 }
 
+// Add adds the labels to the builder, like the structured metadata of an entry.
+// A suffix is added to the names of the labels that already exist in the base labels.
+func (b *LabelsBuilder) Add(lbs ...labels.Label) *LabelsBuilder {
+	for _, l := range lbs {
+		name := l.Name
+		if b.BaseHas(name) {
+			name = name + duplicateSuffix
+		}
+		b.Set(name, l.Value)
+	}
+	return b
+}
+
 // Labels returns the labels from the builder. If no modifications
 // were made, the original labels are returned.
 func (b *LabelsBuilder) labels() labels.Labels {
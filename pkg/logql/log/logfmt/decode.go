@@ -190,6 +190,15 @@ func (dec *Decoder) Err() error {
 	return dec.err
 }
 
+// Skip discards the malformed key/value pair of the last error, up to the next space,
+// so the next call to ScanKeyval decodes the following key/value pair.
+func (dec *Decoder) Skip() {
+	dec.err = nil
+	for dec.pos < len(dec.line) && dec.line[dec.pos] > ' ' {
+		dec.pos++
+	}
+}
+
 func (dec *Decoder) syntaxError(msg string) {
 	dec.err = &SyntaxError{
 		Msg: msg,
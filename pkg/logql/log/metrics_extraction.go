@@ -1,8 +1,10 @@
 package log
 
 import (
+	"math"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -13,8 +15,10 @@ import (
 
 const (
 	ConvertBytes    = "bytes"
+	ConvertBytesInt = "bytes_int"
 	ConvertDuration = "duration"
 	ConvertFloat    = "float"
+	ConvertToFloat  = "to_float"
 )
 
 // LineExtractor extracts a float64 from a log line.
@@ -34,8 +38,9 @@ type SampleExtractor interface {
 // A StreamSampleExtractor never mutate the received line.
 type StreamSampleExtractor interface {
 	BaseLabels() LabelsResult
-	Process(ts int64, line []byte) (float64, LabelsResult, bool)
-	ProcessString(ts int64, line string) (float64, LabelsResult, bool)
+	// Process extracts a sample of a log line, with the structured metadata of the entry added to the labels of the stream.
+	Process(ts int64, line []byte, structuredMetadata ...labels.Label) (float64, LabelsResult, bool)
+	ProcessString(ts int64, line string, structuredMetadata ...labels.Label) (float64, LabelsResult, bool)
 }
 
 type lineSampleExtractor struct {
@@ -80,12 +85,13 @@ type streamLineSampleExtractor struct {
 	builder *LabelsBuilder
 }
 
-func (l *streamLineSampleExtractor) Process(ts int64, line []byte) (float64, LabelsResult, bool) {
+func (l *streamLineSampleExtractor) Process(ts int64, line []byte, structuredMetadata ...labels.Label) (float64, LabelsResult, bool) {
+	l.builder.Reset()
+	l.builder.Add(structuredMetadata...)
 	// short circuit.
 	if l.Stage == NoopStage {
 		return l.LineExtractor(line), l.builder.GroupedLabels(), true
 	}
-	l.builder.Reset()
 	line, ok := l.Stage.Process(ts, line, l.builder)
 	if !ok {
 		return 0, nil, false
@@ -93,9 +99,9 @@ func (l *streamLineSampleExtractor) Process(ts int64, line []byte) (float64, Lab
 	return l.LineExtractor(line), l.builder.GroupedLabels(), true
 }
 
-func (l *streamLineSampleExtractor) ProcessString(ts int64, line string) (float64, LabelsResult, bool) {
+func (l *streamLineSampleExtractor) ProcessString(ts int64, line string, structuredMetadata ...labels.Label) (float64, LabelsResult, bool) {
 	// unsafe get bytes since we have the guarantee that the line won't be mutated.
-	return l.Process(ts, unsafeGetBytes(line))
+	return l.Process(ts, unsafeGetBytes(line), structuredMetadata...)
 }
 
 func (l *streamLineSampleExtractor) BaseLabels() LabelsResult { return l.builder.currentResult }
@@ -121,14 +127,35 @@ func LabelExtractorWithStages(
 	preStages []Stage,
 	postFilter Stage,
 ) (SampleExtractor, error) {
+	return LabelExtractorWithUnit(labelName, conversion, "", groups, without, noLabels, preStages, postFilter)
+}
+
+// LabelExtractorWithUnit creates a SampleExtractor like LabelExtractorWithStages, the values of the to_float
+// conversion are converted to the given unit. An empty unit converts them to ratios, seconds or bytes.
+func LabelExtractorWithUnit(
+	labelName, conversion, unit string,
+	groups []string, without, noLabels bool,
+	preStages []Stage,
+	postFilter Stage,
+) (SampleExtractor, error) {
+	if unit != "" && conversion != ConvertToFloat {
+		return nil, errors.Errorf("the conversion operation %s doesn't support a unit", conversion)
+	}
 	var convFn convertionFn
 	switch conversion {
 	case ConvertBytes:
 		convFn = convertBytes
+	case ConvertBytesInt:
+		convFn = convertBytesInt
 	case ConvertDuration:
 		convFn = convertDuration
 	case ConvertFloat:
 		convFn = convertFloat
+	case ConvertToFloat:
+		var err error
+		if convFn, err = toFloatConversion(unit); err != nil {
+			return nil, err
+		}
 	default:
 		return nil, errors.Errorf("unsupported conversion operation %s", conversion)
 	}
@@ -168,9 +195,10 @@ func (l *labelSampleExtractor) ForStream(labels labels.Labels) StreamSampleExtra
 	return res
 }
 
-func (l *streamLabelSampleExtractor) Process(ts int64, line []byte) (float64, LabelsResult, bool) {
+func (l *streamLabelSampleExtractor) Process(ts int64, line []byte, structuredMetadata ...labels.Label) (float64, LabelsResult, bool) {
 	// Apply the pipeline first.
 	l.builder.Reset()
+	l.builder.Add(structuredMetadata...)
 	line, ok := l.preStage.Process(ts, line, l.builder)
 	if !ok {
 		return 0, nil, false
@@ -198,9 +226,9 @@ func (l *streamLabelSampleExtractor) Process(ts int64, line []byte) (float64, La
 	return v, l.builder.GroupedLabels(), true
 }
 
-func (l *streamLabelSampleExtractor) ProcessString(ts int64, line string) (float64, LabelsResult, bool) {
+func (l *streamLabelSampleExtractor) ProcessString(ts int64, line string, structuredMetadata ...labels.Label) (float64, LabelsResult, bool) {
 	// unsafe get bytes since we have the guarantee that the line won't be mutated.
-	return l.Process(ts, unsafeGetBytes(line))
+	return l.Process(ts, unsafeGetBytes(line), structuredMetadata...)
 }
 
 func (l *streamLabelSampleExtractor) BaseLabels() LabelsResult { return l.builder.currentResult }
@@ -247,40 +275,125 @@ func (sp *filteringStreamExtractor) BaseLabels() LabelsResult {
 	return sp.extractor.BaseLabels()
 }
 
-func (sp *filteringStreamExtractor) Process(ts int64, line []byte) (float64, LabelsResult, bool) {
+func (sp *filteringStreamExtractor) Process(ts int64, line []byte, structuredMetadata ...labels.Label) (float64, LabelsResult, bool) {
 	for _, filter := range sp.filters {
 		if ts < filter.start || ts > filter.end {
 			continue
 		}
 
-		_, _, matches := filter.pipeline.Process(ts, line)
+		_, _, matches := filter.pipeline.Process(ts, line, structuredMetadata...)
 		if matches { //When the filter matches, don't run the next step
 			return 0, nil, false
 		}
 	}
 
-	return sp.extractor.Process(ts, line)
+	return sp.extractor.Process(ts, line, structuredMetadata...)
 }
 
-func (sp *filteringStreamExtractor) ProcessString(ts int64, line string) (float64, LabelsResult, bool) {
+func (sp *filteringStreamExtractor) ProcessString(ts int64, line string, structuredMetadata ...labels.Label) (float64, LabelsResult, bool) {
 	for _, filter := range sp.filters {
 		if ts < filter.start || ts > filter.end {
 			continue
 		}
 
-		_, _, matches := filter.pipeline.ProcessString(ts, line)
+		_, _, matches := filter.pipeline.ProcessString(ts, line, structuredMetadata...)
 		if matches { //When the filter matches, don't run the next step
 			return 0, nil, false
 		}
 	}
 
-	return sp.extractor.ProcessString(ts, line)
+	return sp.extractor.ProcessString(ts, line, structuredMetadata...)
 }
 
 func convertFloat(v string) (float64, error) {
 	return strconv.ParseFloat(v, 64)
 }
 
+// convertToFloat converts the numbers with a unit suffix: the percentages to ratios,
+// the durations to seconds and the sizes to bytes, in this order. A number with the
+// bare m suffix is rejected, as it can be minutes as well as megabytes.
+func convertToFloat(v string) (float64, error) {
+	v = strings.TrimSpace(v)
+	if f, err := strconv.ParseFloat(v, 64); err == nil {
+		return f, nil
+	}
+	if percent, ok := strings.CutSuffix(v, "%"); ok {
+		f, err := strconv.ParseFloat(strings.TrimSpace(percent), 64)
+		if err != nil {
+			return 0, err
+		}
+		return f / 100, nil
+	}
+	if n, ok := strings.CutSuffix(v, "m"); ok {
+		if _, err := strconv.ParseFloat(strings.TrimSpace(n), 64); err == nil {
+			return 0, errors.Errorf("cannot convert %q to a float: the unit m is ambiguous, set the unit of the conversion, e.g. to_float(label, \"s\") or to_float(label, \"MB\")", v)
+		}
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		return d.Seconds(), nil
+	}
+	b, err := humanize.ParseBytes(v)
+	if err != nil {
+		return 0, errors.Errorf("cannot convert %q to a float: not a number, percentage, duration or size", v)
+	}
+	return float64(b), nil
+}
+
+// durationUnits are the units of the durations to_float can convert to.
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"µs": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+}
+
+// toFloatConversion returns the to_float conversion of the values to the unit: a duration unit
+// converts the durations, a size unit the sizes and % the percentages. The numbers without a
+// suffix are expected to be in the unit already.
+func toFloatConversion(unit string) (convertionFn, error) {
+	if unit == "" {
+		return convertToFloat, nil
+	}
+	var convert func(v string) (float64, error)
+	if unit == "%" {
+		convert = func(v string) (float64, error) {
+			percent, ok := strings.CutSuffix(v, "%")
+			if !ok {
+				return 0, errors.Errorf("cannot convert %q to %%: not a percentage", v)
+			}
+			return strconv.ParseFloat(strings.TrimSpace(percent), 64)
+		}
+	} else if d, ok := durationUnits[unit]; ok {
+		convert = func(v string) (float64, error) {
+			dur, err := time.ParseDuration(v)
+			if err != nil {
+				return 0, err
+			}
+			return float64(dur) / float64(d), nil
+		}
+	} else if size, err := humanize.ParseBytes("1" + unit); err == nil {
+		convert = func(v string) (float64, error) {
+			b, err := humanize.ParseBytes(v)
+			if err != nil {
+				return 0, err
+			}
+			return float64(b) / float64(size), nil
+		}
+	} else {
+		return nil, errors.Errorf("unsupported unit %q of the to_float conversion", unit)
+	}
+	return func(v string) (float64, error) {
+		v = strings.TrimSpace(v)
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f, nil
+		}
+		return convert(v)
+	}, nil
+}
+
 func convertDuration(v string) (float64, error) {
 	d, err := time.ParseDuration(v)
 	if err != nil {
@@ -296,3 +409,43 @@ func convertBytes(v string) (float64, error) {
 	}
 	return float64(b), nil
 }
+
+// binaryByteUnits are the multiples of 1024 bytes of the units of bytes_int.
+var binaryByteUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"k":   1 << 10,
+	"kb":  1 << 10,
+	"kib": 1 << 10,
+	"m":   1 << 20,
+	"mb":  1 << 20,
+	"mib": 1 << 20,
+	"g":   1 << 30,
+	"gb":  1 << 30,
+	"gib": 1 << 30,
+	"t":   1 << 40,
+	"tb":  1 << 40,
+	"tib": 1 << 40,
+	"p":   1 << 50,
+	"pb":  1 << 50,
+	"pib": 1 << 50,
+}
+
+// convertBytesInt converts the sizes to whole bytes with their units as multiples of 1024,
+// e.g. 10K, 10KB and 10KiB are all 10240 bytes, like the sizes logged by many applications.
+func convertBytesInt(v string) (float64, error) {
+	v = strings.TrimSpace(v)
+	num, unit := v, ""
+	if i := strings.IndexFunc(v, func(r rune) bool { return (r < '0' || r > '9') && r != '.' }); i >= 0 {
+		num, unit = v[:i], strings.TrimSpace(v[i:])
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, err
+	}
+	multiple, ok := binaryByteUnits[strings.ToLower(unit)]
+	if !ok {
+		return 0, errors.Errorf("cannot convert %q to bytes: unsupported unit %q", v, unit)
+	}
+	return math.Floor(f * multiple), nil
+}
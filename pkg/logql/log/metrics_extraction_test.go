@@ -101,6 +101,18 @@ func Test_labelSampleExtractor_Extract(t *testing.T) {
 			),
 			wantOk: true,
 		},
+		{
+			name: "convert to float",
+			ex: mustSampleExtractor(LabelExtractorWithStages(
+				"foo", ConvertToFloat, []string{"bar"}, false, false, nil, NoopStage,
+			)),
+			in: labels.FromStrings("foo", "250ms",
+				"bar", "foo",
+			),
+			want:    0.25,
+			wantLbs: labels.FromStrings("bar", "foo"),
+			wantOk:  true,
+		},
 		{
 			name: "not convertable",
 			ex: mustSampleExtractor(LabelExtractorWithStages(
@@ -270,6 +282,31 @@ func TestNewLineSampleExtractor(t *testing.T) {
 	require.False(t, ok)
 }
 
+func TestNewLineSampleExtractorWithStructuredMetadata(t *testing.T) {
+	se, err := NewLineSampleExtractor(CountExtractor, nil, []string{"trace_id"}, false, false)
+	require.NoError(t, err)
+
+	sse := se.ForStream(labels.FromStrings("namespace", "dev"))
+	f, l, ok := sse.Process(0, []byte(`foo`), labels.FromStrings("trace_id", "abc")...)
+	require.True(t, ok)
+	require.Equal(t, 1., f)
+	assertLabelResult(t, labels.FromStrings("trace_id", "abc"), l)
+
+	f, l, ok = sse.ProcessString(0, `foo`)
+	require.True(t, ok)
+	require.Equal(t, 1., f)
+	assertLabelResult(t, labels.EmptyLabels(), l)
+
+	se, err = LabelExtractorWithStages("latency", ConvertFloat, []string{"trace_id"}, false, false, nil, NoopStage)
+	require.NoError(t, err)
+
+	sse = se.ForStream(labels.FromStrings("namespace", "dev"))
+	f, l, ok = sse.ProcessString(0, `foo`, labels.FromStrings("latency", "1.5", "trace_id", "abc")...)
+	require.True(t, ok)
+	require.Equal(t, 1.5, f)
+	assertLabelResult(t, labels.FromStrings("trace_id", "abc"), l)
+}
+
 func TestFilteringSampleExtractor(t *testing.T) {
 	se := NewFilteringSampleExtractor([]PipelineFilter{
 		newPipelineFilter(2, 4, labels.FromStrings("foo", "bar", "bar", "baz"), "e"),
@@ -325,10 +362,111 @@ func (p *stubStreamExtractor) BaseLabels() LabelsResult {
 	return nil
 }
 
-func (p *stubStreamExtractor) Process(_ int64, _ []byte) (float64, LabelsResult, bool) {
+func (p *stubStreamExtractor) Process(_ int64, _ []byte, _ ...labels.Label) (float64, LabelsResult, bool) {
 	return 0, nil, true
 }
 
-func (p *stubStreamExtractor) ProcessString(_ int64, _ string) (float64, LabelsResult, bool) {
+func (p *stubStreamExtractor) ProcessString(_ int64, _ string, _ ...labels.Label) (float64, LabelsResult, bool) {
 	return 0, nil, true
 }
+
+func Test_convertToFloat(t *testing.T) {
+	for in, want := range map[string]float64{
+		"15.5":   15.5,
+		" 42 ":   42,
+		"1e3":    1000,
+		"95%":    0.95,
+		"12.5 %": 0.125,
+		"1.5ms":  0.0015,
+		"2m30s":  150,
+		"10 KiB": 10 * 1024,
+		"1.5MB":  1.5 * 1000 * 1000,
+		"3k":     3000,
+		"512 B":  512,
+		"1h":     3600,
+		"-0.25":  -0.25,
+		"100µs":  0.0001,
+	} {
+		got, err := convertToFloat(in)
+		require.NoError(t, err, in)
+		require.InDelta(t, want, got, 1e-9, in)
+	}
+
+	// the bare m suffix is ambiguous between minutes and megabytes.
+	for _, in := range []string{"", "fast", "5 parsecs", "%", "5m", "1.5 m"} {
+		_, err := convertToFloat(in)
+		require.Error(t, err, in)
+	}
+}
+
+func Test_toFloatConversion(t *testing.T) {
+	for _, tc := range []struct {
+		unit string
+		in   string
+		want float64
+	}{
+		{unit: "ms", in: "1.5s", want: 1500},
+		{unit: "ms", in: "250", want: 250},
+		{unit: "us", in: "2ms", want: 2000},
+		{unit: "s", in: "5m", want: 300},
+		{unit: "m", in: "5m", want: 5},
+		{unit: "m", in: "90s", want: 1.5},
+		{unit: "h", in: "30m", want: 0.5},
+		{unit: "KiB", in: "2 MiB", want: 2048},
+		{unit: "KB", in: "1.5MB", want: 1500},
+		{unit: "MB", in: "5m", want: 5},
+		{unit: "B", in: "3k", want: 3000},
+		{unit: "%", in: "95%", want: 95},
+		{unit: "%", in: "12.5", want: 12.5},
+	} {
+		convert, err := toFloatConversion(tc.unit)
+		require.NoError(t, err, tc.unit)
+		got, err := convert(tc.in)
+		require.NoError(t, err, "%s in %s", tc.in, tc.unit)
+		require.InDelta(t, tc.want, got, 1e-9, "%s in %s", tc.in, tc.unit)
+	}
+
+	for unit, in := range map[string]string{"ms": "10 KiB", "KiB": "10ms", "%": "0.5s"} {
+		convert, err := toFloatConversion(unit)
+		require.NoError(t, err, unit)
+		_, err = convert(in)
+		require.Error(t, err, "%s in %s", in, unit)
+	}
+
+	_, err := toFloatConversion("parsecs")
+	require.Error(t, err)
+	_, err = LabelExtractorWithUnit("foo", ConvertBytes, "ms", nil, false, false, nil, NoopStage)
+	require.Error(t, err)
+}
+
+func Test_convertBytesInt(t *testing.T) {
+	for in, want := range map[string]float64{
+		"512":     512,
+		"512 B":   512,
+		"10K":     10 * 1024,
+		"10KB":    10 * 1024,
+		"10 KiB":  10 * 1024,
+		"1.5m":    1.5 * 1024 * 1024,
+		"2MB":     2 * 1024 * 1024,
+		"3 MiB":   3 * 1024 * 1024,
+		"1G":      1 << 30,
+		"1gb":     1 << 30,
+		"2GiB":    2 << 30,
+		"1T":      1 << 40,
+		"1TiB":    1 << 40,
+		"1P":      1 << 50,
+		"1PB":     1 << 50,
+		" 1.7 b ": 1,
+		"0.5K":    512,
+		"1.0001K": 1024,
+	} {
+		got, err := convertBytesInt(in)
+		require.NoError(t, err, in)
+		require.Equal(t, want, got, in)
+	}
+
+	for _, in := range []string{"", "K", "-1K", "10 parsecs", "1.5.5K"} {
+		_, err := convertBytesInt(in)
+		require.Error(t, err, in)
+	}
+}
@@ -284,16 +284,27 @@ func (r *RegexpParser) Process(_ int64, line []byte, lbs *LabelsBuilder) ([]byte
 func (r *RegexpParser) RequiredLabelNames() []string { return []string{} }
 
 type LogfmtParser struct {
-	dec  *logfmt.Decoder
-	keys internedStringSet
+	strict    bool
+	keepEmpty bool
+	dec       *logfmt.Decoder
+	keys      internedStringSet
 }
 
 // NewLogfmtParser creates a parser that can extract labels from a logfmt log line.
 // Each keyval is extracted into a respective label.
 func NewLogfmtParser() *LogfmtParser {
+	return NewLogfmtParserWithFlags(true, true)
+}
+
+// NewLogfmtParserWithFlags creates the logfmt parser of `| logfmt` given flags.
+// The strict parser stops at the first malformed key/value pair and adds the error labels,
+// otherwise the malformed pairs are skipped. The keys without values are only extracted with keepEmpty.
+func NewLogfmtParserWithFlags(strict, keepEmpty bool) *LogfmtParser {
 	return &LogfmtParser{
-		dec:  logfmt.NewDecoder(nil),
-		keys: internedStringSet{},
+		strict:    strict,
+		keepEmpty: keepEmpty,
+		dec:       logfmt.NewDecoder(nil),
+		keys:      internedStringSet{},
 	}
 }
 
@@ -304,7 +315,14 @@ func (l *LogfmtParser) Process(_ int64, line []byte, lbs *LabelsBuilder) ([]byte
 	}
 
 	l.dec.Reset(line)
-	for l.dec.ScanKeyval() {
+	for {
+		if !l.dec.ScanKeyval() {
+			if l.dec.Err() == nil || l.strict {
+				break
+			}
+			l.dec.Skip()
+			continue
+		}
 		key, ok := l.keys.Get(l.dec.Key(), func() (string, bool) {
 			sanitized := sanitizeLabelKey(string(l.dec.Key()), true)
 			if len(sanitized) == 0 {
@@ -328,6 +346,9 @@ func (l *LogfmtParser) Process(_ int64, line []byte, lbs *LabelsBuilder) ([]byte
 		if bytes.ContainsRune(val, utf8.RuneError) {
 			val = nil
 		}
+		if len(val) == 0 && !l.keepEmpty {
+			continue
+		}
 
 		lbs.Set(key, string(val))
 		if !parserHints.ShouldContinueParsingLine(key, lbs) {
@@ -878,6 +878,62 @@ func Test_logfmtParser_Parse(t *testing.T) {
 	}
 }
 
+func Test_logfmtParser_ParseWithFlags(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      []byte
+		strict    bool
+		keepEmpty bool
+		want      labels.Labels
+	}{
+		{
+			"not logfmt skipped",
+			[]byte("foobar====wqe=sdad1r bar=foo"),
+			false, true,
+			labels.FromStrings("foo", "bar",
+				"bar", "foo",
+			),
+		},
+		{
+			"not logfmt strict",
+			[]byte("foobar====wqe=sdad1r bar=foo"),
+			true, false,
+			labels.FromStrings("foo", "bar",
+				"__error__", "LogfmtParserErr",
+				"__error_details__", "logfmt syntax error at pos 8 : unexpected '='",
+			),
+		},
+		{
+			"empty values dropped",
+			[]byte(`buzz bar=foo fizz= foo=""`),
+			true, false,
+			labels.FromStrings("foo", "bar",
+				"bar", "foo",
+			),
+		},
+		{
+			"empty values kept",
+			[]byte(`buzz bar=foo fizz=`),
+			false, true,
+			labels.FromStrings("foo", "bar",
+				"bar", "foo",
+				"buzz", "",
+				"fizz", "",
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewLogfmtParserWithFlags(tt.strict, tt.keepEmpty)
+			lbs := labels.FromStrings("foo", "bar")
+			b := NewBaseLabelsBuilderWithGrouping(nil, noParserHints, false, false).ForLabels(lbs, lbs.Hash())
+			b.Reset()
+			_, _ = p.Process(0, tt.line, b)
+			require.Equal(t, tt.want, b.LabelsResult().Labels())
+		})
+	}
+}
+
 func TestLogfmtExpressionParser(t *testing.T) {
 	testLine := []byte(`app=foo level=error spaces="value with ÜFT8👌" ts=2021-02-12T19:18:10.037940878Z`)
 
@@ -49,8 +49,8 @@ func (m *matcher) Matches(in []byte) [][]byte {
 	captures := m.captures[:0]
 	expr := m.e
 	if ls, ok := expr[0].(literals); ok {
-		i := bytes.Index(in, ls)
-		if i != 0 {
+		// Lines not starting with the literals are rejected without scanning them.
+		if !bytes.HasPrefix(in, ls) {
 			return nil
 		}
 		in = in[len(ls):]
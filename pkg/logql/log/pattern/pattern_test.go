@@ -103,6 +103,12 @@ var fixtures = []struct {
 		`[2016-04-15T20:17:00.310Z] "POST /api/v1/locations HTTP/2" 204 - 154 0 226 100 "10.0.35.28" "nsq2http" "cc21d9b0-cf5c-432b-8c7e-98aeb7988cd2" "locations" "tcp://10.0.2.1:80"`,
 		[]string{"POST", "/api/v1/locations", "204", "154", "0", "226", "100", "10.0.35.28", "nsq2http", "tcp://10.0.2.1:80"},
 	},
+	{
+		// The line must start with the leading literals.
+		`level=<level> <_>`,
+		`ts=2021-05-19T06:54:06Z level=info msg="hello"`,
+		nil,
+	},
 }
 
 func Test_matcher_Matches(t *testing.T) {
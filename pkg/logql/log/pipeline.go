@@ -13,6 +13,8 @@ var NoopStage Stage = &noopStage{}
 // Pipeline can create pipelines for each log stream.
 type Pipeline interface {
 	ForStream(labels labels.Labels) StreamPipeline
+	// Reset forgets the pipelines of the streams, for pipelines reused across requests.
+	Reset()
 }
 
 // StreamPipeline transform and filter log lines and labels.
@@ -20,9 +22,10 @@ type Pipeline interface {
 type StreamPipeline interface {
 	BaseLabels() LabelsResult
 	// Process processes a log line and returns the transformed line and the labels.
+	// The structured metadata of the entry are added to the labels of the stream before the stages.
 	// The buffer returned for the log line can be reused on subsequent calls to Process and therefore must be copied.
-	Process(ts int64, line []byte) (resultLine []byte, resultLabels LabelsResult, matches bool)
-	ProcessString(ts int64, line string) (resultLine string, resultLabels LabelsResult, matches bool)
+	Process(ts int64, line []byte, structuredMetadata ...labels.Label) (resultLine []byte, resultLabels LabelsResult, matches bool)
+	ProcessString(ts int64, line string, structuredMetadata ...labels.Label) (resultLine string, resultLabels LabelsResult, matches bool)
 }
 
 // Stage is a single step of a Pipeline.
@@ -36,12 +39,14 @@ type Stage interface {
 // NewNoopPipeline creates a pipelines that does not process anything and returns log streams as is.
 func NewNoopPipeline() Pipeline {
 	return &noopPipeline{
-		cache: map[uint64]*noopStreamPipeline{},
+		cache:       map[uint64]*noopStreamPipeline{},
+		baseBuilder: NewBaseLabelsBuilder(),
 	}
 }
 
 type noopPipeline struct {
-	cache map[uint64]*noopStreamPipeline
+	cache       map[uint64]*noopStreamPipeline
+	baseBuilder *BaseLabelsBuilder
 }
 
 // IsNoopPipeline tells if a pipeline is a Noop.
@@ -51,29 +56,41 @@ func IsNoopPipeline(p Pipeline) bool {
 }
 
 type noopStreamPipeline struct {
-	LabelsResult
+	builder *LabelsBuilder
 }
 
-func (n noopStreamPipeline) Process(_ int64, line []byte) ([]byte, LabelsResult, bool) {
-	return line, n.LabelsResult, true
+func (n noopStreamPipeline) Process(_ int64, line []byte, structuredMetadata ...labels.Label) ([]byte, LabelsResult, bool) {
+	if len(structuredMetadata) == 0 {
+		return line, n.builder.currentResult, true
+	}
+	n.builder.Reset()
+	n.builder.Add(structuredMetadata...)
+	return line, n.builder.LabelsResult(), true
 }
 
-func (n noopStreamPipeline) ProcessString(_ int64, line string) (string, LabelsResult, bool) {
-	return line, n.LabelsResult, true
+func (n noopStreamPipeline) ProcessString(ts int64, line string, structuredMetadata ...labels.Label) (string, LabelsResult, bool) {
+	_, lr, ok := n.Process(ts, unsafeGetBytes(line), structuredMetadata...)
+	return line, lr, ok
 }
 
-func (n noopStreamPipeline) BaseLabels() LabelsResult { return n.LabelsResult }
+func (n noopStreamPipeline) BaseLabels() LabelsResult { return n.builder.currentResult }
 
 func (n *noopPipeline) ForStream(labels labels.Labels) StreamPipeline {
 	h := labels.Hash()
 	if cached, ok := n.cache[h]; ok {
 		return cached
 	}
-	sp := &noopStreamPipeline{LabelsResult: NewLabelsResult(labels, h)}
+	sp := &noopStreamPipeline{builder: n.baseBuilder.ForLabels(labels, h)}
 	n.cache[h] = sp
 	return sp
 }
 
+func (n *noopPipeline) Reset() {
+	for k := range n.cache {
+		delete(n.cache, k)
+	}
+}
+
 type noopStage struct{}
 
 func (noopStage) Process(_ int64, line []byte, _ *LabelsBuilder) ([]byte, bool) {
@@ -156,9 +173,16 @@ func (p *pipeline) ForStream(labels labels.Labels) StreamPipeline {
 	return res
 }
 
-func (p *streamPipeline) Process(ts int64, line []byte) ([]byte, LabelsResult, bool) {
+func (p *pipeline) Reset() {
+	for k := range p.streamPipelines {
+		delete(p.streamPipelines, k)
+	}
+}
+
+func (p *streamPipeline) Process(ts int64, line []byte, structuredMetadata ...labels.Label) ([]byte, LabelsResult, bool) {
 	var ok bool
 	p.builder.Reset()
+	p.builder.Add(structuredMetadata...)
 	for _, s := range p.stages {
 		line, ok = s.Process(ts, line, p.builder)
 		if !ok {
@@ -168,9 +192,9 @@ func (p *streamPipeline) Process(ts int64, line []byte) ([]byte, LabelsResult, b
 	return line, p.builder.LabelsResult(), true
 }
 
-func (p *streamPipeline) ProcessString(ts int64, line string) (string, LabelsResult, bool) {
+func (p *streamPipeline) ProcessString(ts int64, line string, structuredMetadata ...labels.Label) (string, LabelsResult, bool) {
 	// Stages only read from the line.
-	lb, lr, ok := p.Process(ts, unsafeGetBytes(line))
+	lb, lr, ok := p.Process(ts, unsafeGetBytes(line), structuredMetadata...)
 	// but the returned line needs to be copied.
 	return string(lb), lr, ok
 }
@@ -221,6 +245,13 @@ func (p *filteringPipeline) ForStream(labels labels.Labels) StreamPipeline {
 	}
 }
 
+func (p *filteringPipeline) Reset() {
+	for _, f := range p.filters {
+		f.Pipeline.Reset()
+	}
+	p.pipeline.Reset()
+}
+
 func allMatch(matchers []*labels.Matcher, labels labels.Labels) bool {
 	for _, m := range matchers {
 		if !m.Matches(labels.Get(m.Name)) {
@@ -245,34 +276,34 @@ func (sp *filteringStreamPipeline) BaseLabels() LabelsResult {
 	return sp.pipeline.BaseLabels()
 }
 
-func (sp *filteringStreamPipeline) Process(ts int64, line []byte) ([]byte, LabelsResult, bool) {
+func (sp *filteringStreamPipeline) Process(ts int64, line []byte, structuredMetadata ...labels.Label) ([]byte, LabelsResult, bool) {
 	for _, filter := range sp.filters {
 		if ts < filter.start || ts > filter.end {
 			continue
 		}
 
-		_, _, matches := filter.pipeline.Process(ts, line)
+		_, _, matches := filter.pipeline.Process(ts, line, structuredMetadata...)
 		if matches { // When the filter matches, don't run the next step
 			return nil, nil, false
 		}
 	}
 
-	return sp.pipeline.Process(ts, line)
+	return sp.pipeline.Process(ts, line, structuredMetadata...)
 }
 
-func (sp *filteringStreamPipeline) ProcessString(ts int64, line string) (string, LabelsResult, bool) {
+func (sp *filteringStreamPipeline) ProcessString(ts int64, line string, structuredMetadata ...labels.Label) (string, LabelsResult, bool) {
 	for _, filter := range sp.filters {
 		if ts < filter.start || ts > filter.end {
 			continue
 		}
 
-		_, _, matches := filter.pipeline.ProcessString(ts, line)
+		_, _, matches := filter.pipeline.ProcessString(ts, line, structuredMetadata...)
 		if matches { // When the filter matches, don't run the next step
 			return "", nil, false
 		}
 	}
 
-	return sp.pipeline.ProcessString(ts, line)
+	return sp.pipeline.ProcessString(ts, line, structuredMetadata...)
 }
 
 // ReduceStages reduces multiple stages into one.
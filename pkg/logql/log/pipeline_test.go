@@ -50,6 +50,56 @@ func TestPipeline(t *testing.T) {
 	require.Equal(t, false, matches)
 }
 
+func TestPipelineReset(t *testing.T) {
+	lbs := labels.FromStrings("foo", "bar")
+	for _, p := range []Pipeline{
+		NewNoopPipeline(),
+		NewPipeline([]Stage{newMustLineFormatter("lbs {{.foo}}")}),
+	} {
+		sp := p.ForStream(lbs)
+		require.Same(t, sp, p.ForStream(lbs))
+
+		p.Reset()
+		require.NotSame(t, sp, p.ForStream(lbs))
+	}
+	require.Empty(t, NewNoopPipeline().(*noopPipeline).cache)
+
+	p := NewPipeline([]Stage{newMustLineFormatter("lbs {{.foo}}")}).(*pipeline)
+	p.ForStream(lbs)
+	p.ForStream(labels.FromStrings("foo", "baz"))
+	require.Len(t, p.streamPipelines, 2)
+	p.Reset()
+	require.Empty(t, p.streamPipelines)
+}
+
+func TestPipelineWithStructuredMetadata(t *testing.T) {
+	lbs := labels.FromStrings("foo", "bar")
+	structuredMetadata := labels.FromStrings("foo", "baz", "trace_id", "abc")
+
+	l, lbr, matches := NewNoopPipeline().ForStream(lbs).Process(0, []byte("line"), structuredMetadata...)
+	require.Equal(t, []byte("line"), l)
+	expected := labels.FromStrings("foo", "bar", "foo_extracted", "baz", "trace_id", "abc")
+	require.Equal(t, NewLabelsResult(expected, expected.Hash()), lbr)
+	require.Equal(t, true, matches)
+
+	p := NewPipeline([]Stage{
+		NewStringLabelFilter(labels.MustNewMatcher(labels.MatchEqual, "trace_id", "abc")),
+		newMustLineFormatter("{{.trace_id}}"),
+	})
+	sp := p.ForStream(lbs)
+	ls, lbr, matches := sp.ProcessString(0, "line", structuredMetadata...)
+	require.Equal(t, "abc", ls)
+	require.Equal(t, NewLabelsResult(expected, expected.Hash()), lbr)
+	require.Equal(t, true, matches)
+
+	// the structured metadata of an entry are not kept for the next entries.
+	_, _, matches = sp.ProcessString(0, "line")
+	require.Equal(t, false, matches)
+
+	_, _, matches = sp.ProcessString(0, "line", labels.FromStrings("trace_id", "def")...)
+	require.Equal(t, false, matches)
+}
+
 func TestFilteringPipeline(t *testing.T) {
 	p := NewFilteringPipeline([]PipelineFilter{
 		newPipelineFilter(2, 4, labels.FromStrings("foo", "bar", "bar", "baz"), "e"),
@@ -111,6 +161,8 @@ func (p *stubPipeline) ForStream(_ labels.Labels) StreamPipeline {
 	return p.sp
 }
 
+func (p *stubPipeline) Reset() {}
+
 // A stub always returns the same data
 type stubStreamPipeline struct{}
 
@@ -118,11 +170,11 @@ func (p *stubStreamPipeline) BaseLabels() LabelsResult {
 	return nil
 }
 
-func (p *stubStreamPipeline) Process(_ int64, _ []byte) ([]byte, LabelsResult, bool) {
+func (p *stubStreamPipeline) Process(_ int64, _ []byte, _ ...labels.Label) ([]byte, LabelsResult, bool) {
 	return nil, nil, true
 }
 
-func (p *stubStreamPipeline) ProcessString(_ int64, _ string) (string, LabelsResult, bool) {
+func (p *stubStreamPipeline) ProcessString(_ int64, _ string, _ ...labels.Label) (string, LabelsResult, bool) {
 	return "", nil, true
 }
 
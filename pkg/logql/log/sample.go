@@ -0,0 +1,37 @@
+package log
+
+import (
+	"encoding/binary"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+type sampleFilter struct {
+	rate   float64
+	digest *xxhash.Digest
+	buf    [8]byte
+}
+
+// NewSampleFilter creates a stage keeping the given fraction of the log lines.
+// A log line is kept depending on the hash of its timestamp and its content,
+// so the same log lines are kept whatever the way the query is split or sharded.
+func NewSampleFilter(rate float64) Stage {
+	return &sampleFilter{
+		rate:   rate,
+		digest: xxhash.New(),
+	}
+}
+
+func (s *sampleFilter) Process(ts int64, line []byte, _ *LabelsBuilder) ([]byte, bool) {
+	if s.rate >= 1 {
+		return line, true
+	}
+	binary.BigEndian.PutUint64(s.buf[:], uint64(ts))
+	s.digest.Reset()
+	_, _ = s.digest.Write(s.buf[:])
+	_, _ = s.digest.Write(line)
+	// uses the 53 most significant bits of the hash, the precision of a float64.
+	return line, float64(s.digest.Sum64()>>11)/(1<<53) < s.rate
+}
+
+func (s *sampleFilter) RequiredLabelNames() []string { return []string{} }
@@ -0,0 +1,58 @@
+package log
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SampleFilter(t *testing.T) {
+	lines := make([][]byte, 10000)
+	for i := range lines {
+		lines[i] = []byte(fmt.Sprintf("level=info msg=%d", i))
+	}
+
+	sample := func(rate float64) []bool {
+		s := NewSampleFilter(rate)
+		res := make([]bool, len(lines))
+		for i, line := range lines {
+			_, res[i] = s.Process(int64(i), line, nil)
+		}
+		return res
+	}
+
+	kept := func(res []bool) int {
+		var n int
+		for _, ok := range res {
+			if ok {
+				n++
+			}
+		}
+		return n
+	}
+
+	require.Equal(t, len(lines), kept(sample(1)))
+	require.InDelta(t, len(lines)/10, kept(sample(0.1)), 100)
+
+	// the same lines are kept by every sample stage.
+	require.Equal(t, sample(0.1), sample(0.1))
+
+	// the lines kept with a rate are also kept with a higher rate.
+	small, large := sample(0.1), sample(0.5)
+	for i := range lines {
+		if small[i] {
+			require.True(t, large[i])
+		}
+	}
+
+	// the timestamp is part of the hash.
+	s := NewSampleFilter(0.5)
+	var diff bool
+	for i, line := range lines {
+		_, a := s.Process(int64(i), line, nil)
+		_, b := s.Process(int64(i)+1, line, nil)
+		diff = diff || a != b
+	}
+	require.True(t, diff)
+}
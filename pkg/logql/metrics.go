@@ -145,6 +145,8 @@ func RecordRangeAndInstantQueryMetrics(
 		"cache_result_req", stats.Caches.Result.EntriesRequested,
 		"cache_result_hit", stats.Caches.Result.EntriesFound,
 		"cache_result_download_time", stats.Caches.Result.CacheDownloadTime(),
+		"cache_stats_results_req", stats.Caches.StatsResult.EntriesRequested,
+		"cache_stats_results_hit", stats.Caches.StatsResult.EntriesFound,
 	}...)
 
 	logValues = append(logValues, tagsToKeyValues(queryTags)...)
@@ -204,6 +206,8 @@ func RecordLabelQueryMetrics(
 		"throughput", strings.Replace(humanize.Bytes(uint64(stats.Summary.BytesProcessedPerSecond)), " ", "", 1),
 		"total_bytes", strings.Replace(humanize.Bytes(uint64(stats.Summary.TotalBytesProcessed)), " ", "", 1),
 		"total_entries", stats.Summary.TotalEntriesReturned,
+		"cache_label_results_req", stats.Caches.LabelResult.EntriesRequested,
+		"cache_label_results_hit", stats.Caches.LabelResult.EntriesFound,
 	)
 
 	bytesPerSecond.WithLabelValues(status, queryType, "", latencyType).
@@ -255,6 +259,8 @@ func RecordSeriesQueryMetrics(
 		"throughput", strings.Replace(humanize.Bytes(uint64(stats.Summary.BytesProcessedPerSecond)), " ", "", 1),
 		"total_bytes", strings.Replace(humanize.Bytes(uint64(stats.Summary.TotalBytesProcessed)), " ", "", 1),
 		"total_entries", stats.Summary.TotalEntriesReturned,
+		"cache_series_results_req", stats.Caches.SeriesResult.EntriesRequested,
+		"cache_series_results_hit", stats.Caches.SeriesResult.EntriesFound,
 	)
 
 	bytesPerSecond.WithLabelValues(status, queryType, "", latencyType).
@@ -0,0 +1,181 @@
+package logql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+
+	"github.com/grafana/loki/pkg/iter"
+	"github.com/grafana/loki/pkg/logql/sketch"
+	"github.com/grafana/loki/pkg/logql/syntax"
+	"github.com/grafana/loki/pkg/logqlmodel"
+)
+
+// QuantileSketchBucketLabel is the label of the samples of a QuantileSketchExpr
+// naming the bucket of the quantile sketch they count.
+const QuantileSketchBucketLabel = "__quantile_sketch_bucket__"
+
+// QuantileSketchExpr returns the buckets of the quantile sketches of the quantile_over_time
+// range aggregation it wraps, instead of its quantiles. It isn't expressible in LogQL:
+// it's sent downstream as the quantile_over_time query with the httpreq.LokiQuantileSketchesHeader.
+type QuantileSketchExpr struct {
+	*syntax.RangeAggregationExpr
+}
+
+func (e QuantileSketchExpr) String() string {
+	return fmt.Sprintf("quantileSketch<%s>", e.RangeAggregationExpr.String())
+}
+
+func (e *QuantileSketchExpr) Walk(f syntax.WalkFn) {
+	f(e)
+	e.RangeAggregationExpr.Walk(f)
+}
+
+// QuantileSketchEvalExpr computes the quantile of the quantile sketches returned by the shards
+// of a QuantileSketchExpr, summing the buckets of the same series first.
+type QuantileSketchEvalExpr struct {
+	syntax.SampleExpr
+	Quantile float64
+}
+
+func (e QuantileSketchEvalExpr) String() string {
+	return fmt.Sprintf("quantileSketchEval<%s, quantile=%v>", e.SampleExpr.String(), e.Quantile)
+}
+
+func (e *QuantileSketchEvalExpr) Walk(f syntax.WalkFn) {
+	f(e)
+	e.SampleExpr.Walk(f)
+}
+
+// quantileSketchEvaluator returns the buckets of the quantile sketches of the values of the series
+// within the range of each step, as one sample per bucket counting its values. The samples have the
+// labels of the series with the bucket in the QuantileSketchBucketLabel label.
+func quantileSketchEvaluator(it iter.PeekingSampleIterator, expr *syntax.RangeAggregationExpr, q Params, o time.Duration) (StepEvaluator, error) {
+	step := q.Step().Nanoseconds()
+	// forces at least one step.
+	if step == 0 {
+		step = 1
+	}
+	offset := o.Nanoseconds()
+	iter := &batchRangeVectorIterator{
+		iter:     it,
+		step:     step,
+		end:      q.End().UnixNano() - offset,
+		selRange: expr.Left.Interval.Nanoseconds(),
+		metrics:  map[string]labels.Labels{},
+		window:   map[string]*promql.Series{},
+		current:  q.Start().UnixNano() - offset - step, // first loop iteration will set it to start
+		offset:   offset,
+	}
+
+	s := sketch.NewDDSketch(sketch.DefaultRelativeAccuracy)
+	lb := labels.NewBuilder(nil)
+	var err error
+	return newStepEvaluator(func() (bool, int64, promql.Vector) {
+		if !iter.Next() {
+			return false, 0, promql.Vector{}
+		}
+		// convert ts from nano to milli seconds as the iterator work with nanoseconds
+		ts := iter.current/1e+6 + iter.offset/1e+6
+		var vec promql.Vector
+		for _, series := range iter.window {
+			// Errors are not allowed in metrics unless they've been specifically requested.
+			if series.Metric.Has(logqlmodel.ErrorLabel) && series.Metric.Get(logqlmodel.PreserveErrorLabel) != "true" {
+				err = logqlmodel.NewPipelineErr(series.Metric)
+				return false, 0, promql.Vector{}
+			}
+			s.Reset()
+			for _, p := range series.Floats {
+				s.Add(p.F)
+			}
+			s.Buckets(func(bucket string, count float64) {
+				lb.Reset(series.Metric)
+				lb.Set(QuantileSketchBucketLabel, bucket)
+				vec = append(vec, promql.Sample{
+					Metric: lb.Labels(),
+					T:      ts,
+					F:      count,
+				})
+			})
+		}
+		return true, ts, vec
+	}, iter.Close, func() error {
+		if err != nil {
+			return err
+		}
+		return iter.Error()
+	})
+}
+
+// quantileSketchEvalEvaluator merges the buckets of the quantile sketches of the same series,
+// returned by the shards of a QuantileSketchExpr, and returns the quantile of the merged sketches.
+func quantileSketchEvalEvaluator(
+	ctx context.Context,
+	ev SampleEvaluator,
+	expr *QuantileSketchEvalExpr,
+	q Params,
+) (StepEvaluator, error) {
+	nextEvaluator, err := ev.StepEvaluator(ctx, ev, expr.SampleExpr, q)
+	if err != nil {
+		return nil, err
+	}
+
+	type group struct {
+		labels labels.Labels
+		sketch *sketch.DDSketch
+	}
+	groups := map[uint64]*group{}
+	lb := labels.NewBuilder(nil)
+	buf := make([]byte, 0, 1024)
+	var sketchErr error
+	return newStepEvaluator(func() (bool, int64, promql.Vector) {
+		next, ts, vec := nextEvaluator.Next()
+		if !next {
+			return false, 0, promql.Vector{}
+		}
+
+		for _, g := range groups {
+			g.sketch.Reset()
+		}
+		for _, s := range vec {
+			var key uint64
+			key, buf = s.Metric.HashWithoutLabels(buf, QuantileSketchBucketLabel)
+			g, ok := groups[key]
+			if !ok {
+				lb.Reset(s.Metric)
+				lb.Del(QuantileSketchBucketLabel)
+				g = &group{
+					labels: lb.Labels(),
+					sketch: sketch.NewDDSketch(sketch.DefaultRelativeAccuracy),
+				}
+				groups[key] = g
+			}
+			if err := g.sketch.AddBucket(s.Metric.Get(QuantileSketchBucketLabel), s.F); err != nil {
+				sketchErr = err
+				return false, 0, promql.Vector{}
+			}
+		}
+
+		vec = vec[:0]
+		for key, g := range groups {
+			if g.sketch.Count() == 0 {
+				delete(groups, key)
+				continue
+			}
+			vec = append(vec, promql.Sample{
+				Metric: g.labels,
+				T:      ts,
+				F:      g.sketch.Quantile(expr.Quantile),
+			})
+		}
+		return next, ts, vec
+	}, nextEvaluator.Close, func() error {
+		if sketchErr != nil {
+			return sketchErr
+		}
+		return nextEvaluator.Error()
+	})
+}
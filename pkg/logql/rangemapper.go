@@ -55,17 +55,20 @@ var splittableRangeVectorOp = map[string]struct{}{
 type RangeMapper struct {
 	splitByInterval time.Duration
 	metrics         *MapperMetrics
+	// quantileSketches splits quantile_over_time by merging the quantile sketches of the split ranges.
+	quantileSketches bool
 }
 
 // NewRangeMapper creates a new RangeMapper instance with the given duration as
 // split interval. The interval must be greater than 0.
-func NewRangeMapper(interval time.Duration, metrics *MapperMetrics) (RangeMapper, error) {
+func NewRangeMapper(interval time.Duration, metrics *MapperMetrics, quantileSketches bool) (RangeMapper, error) {
 	if interval <= 0 {
 		return RangeMapper{}, fmt.Errorf("cannot create RangeMapper with splitByInterval <= 0; got %s", interval)
 	}
 	return RangeMapper{
-		splitByInterval: interval,
-		metrics:         metrics,
+		splitByInterval:  interval,
+		metrics:          metrics,
+		quantileSketches: quantileSketches,
 	}, nil
 }
 
@@ -86,7 +89,7 @@ func (m RangeMapper) Parse(query string) (bool, syntax.Expr, error) {
 
 	recorder := m.metrics.downstreamRecorder()
 
-	if !isSplittableByRange(origExpr) {
+	if !m.isSplittableByRange(origExpr) {
 		m.metrics.ParsedQueries.WithLabelValues(NoopKey).Inc()
 		return true, origExpr, nil
 	}
@@ -160,6 +163,9 @@ func (m RangeMapper) Map(expr syntax.SampleExpr, vectorAggrPushdown *syntax.Vect
 		return e, nil
 	case *syntax.VectorExpr:
 		return e, nil
+	case *syntax.SubqueryExpr:
+		// the samples of the subqueries are not split, as they are already evaluated at their resolution.
+		return e, nil
 	default:
 		// ConcatSampleExpr and DownstreamSampleExpr are not supported input expression types
 		return nil, errors.Errorf("unexpected expr type (%T) for ASTMapper type (%T) ", expr, m)
@@ -409,6 +415,25 @@ func (m RangeMapper) mapRangeAggregationExpr(expr *syntax.RangeAggregationExpr,
 			return expr
 		}
 		return m.sumOverFullRange(expr, vectorAggrPushdown, syntax.OpRangeTypeBytes, rangeInterval, recorder)
+	case syntax.OpRangeTypeQuantile:
+		if !m.quantileSketches {
+			return expr
+		}
+		// quantile_over_time(0.99, {app="foo"} | unwrap bar [2m])
+		// => quantile(0.99, quantileSketch<quantile_over_time(0.99, {app="foo"} | unwrap bar [1m])> ++ quantileSketch<quantile_over_time(0.99, {app="foo"} | unwrap bar [1m] offset 1m)>)
+		// The vector aggregation isn't pushed down, as the buckets of the sketches can't be aggregated.
+		downstreams, ok := m.mapConcatSampleExpr(expr, rangeInterval, recorder).(*ConcatSampleExpr)
+		if !ok {
+			return expr
+		}
+		// the downstreams are cloned through their string, so they're wrapped after the split.
+		for cur := downstreams; cur != nil; cur = cur.next {
+			cur.SampleExpr = &QuantileSketchExpr{cur.SampleExpr.(*syntax.RangeAggregationExpr)}
+		}
+		return &QuantileSketchEvalExpr{
+			SampleExpr: downstreams,
+			Quantile:   *expr.Params,
+		}
 	default:
 		// this should not be reachable.
 		// If an operation is splittable it should have an optimization listed.
@@ -425,15 +450,18 @@ func (m RangeMapper) mapRangeAggregationExpr(expr *syntax.RangeAggregationExpr,
 // A vector aggregation is splittable, if the aggregation operation is
 // supported and the inner expression is also splittable.
 // A range aggregation is splittable, if the aggregation operation is
-// supported.
+// supported, or if it's quantile_over_time and the quantile sketches are enabled.
 // A binary expression is splittable, if both the left and the right-hand side
 // are splittable.
-func isSplittableByRange(expr syntax.SampleExpr) bool {
+func (m RangeMapper) isSplittableByRange(expr syntax.SampleExpr) bool {
 	switch e := expr.(type) {
 	case *syntax.VectorAggregationExpr:
 		_, ok := splittableVectorOp[e.Operation]
-		return ok && isSplittableByRange(e.Left)
+		return ok && m.isSplittableByRange(e.Left)
 	case *syntax.RangeAggregationExpr:
+		if e.Operation == syntax.OpRangeTypeQuantile {
+			return m.quantileSketches
+		}
 		_, ok := splittableRangeVectorOp[e.Operation]
 		return ok
 	case *syntax.BinOpExpr:
@@ -441,9 +469,9 @@ func isSplittableByRange(expr syntax.SampleExpr) bool {
 		_, literalRHS := e.RHS.(*syntax.LiteralExpr)
 		// Note: if both left-hand side and right-hand side are literal expressions,
 		// the syntax.ParseSampleExpr returns a literal expression
-		return isSplittableByRange(e.SampleExpr) || literalLHS && isSplittableByRange(e.RHS) || literalRHS
+		return m.isSplittableByRange(e.SampleExpr) || literalLHS && m.isSplittableByRange(e.RHS) || literalRHS
 	case *syntax.LabelReplaceExpr:
-		return isSplittableByRange(e.Left)
+		return m.isSplittableByRange(e.Left)
 	case *syntax.VectorExpr:
 		return false
 	default:
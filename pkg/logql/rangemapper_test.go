@@ -10,7 +10,7 @@ import (
 )
 
 func Test_SplitRangeInterval(t *testing.T) {
-	rvm, err := NewRangeMapper(2*time.Second, nilShardMetrics)
+	rvm, err := NewRangeMapper(2*time.Second, nilShardMetrics, false)
 	require.NoError(t, err)
 
 	for _, tc := range []struct {
@@ -52,7 +52,7 @@ func Test_SplitRangeInterval(t *testing.T) {
 }
 
 func Test_SplitRangeVectorMapping(t *testing.T) {
-	rvm, err := NewRangeMapper(time.Minute, nilShardMetrics)
+	rvm, err := NewRangeMapper(time.Minute, nilShardMetrics, false)
 	require.NoError(t, err)
 
 	for _, tc := range []struct {
@@ -1559,7 +1559,7 @@ func Test_SplitRangeVectorMapping(t *testing.T) {
 }
 
 func Test_SplitRangeVectorMapping_Noop(t *testing.T) {
-	rvm, err := NewRangeMapper(time.Minute, nilShardMetrics)
+	rvm, err := NewRangeMapper(time.Minute, nilShardMetrics, false)
 	require.NoError(t, err)
 
 	for _, tc := range []struct {
@@ -1746,7 +1746,7 @@ func Test_SplitRangeVectorMapping_Noop(t *testing.T) {
 }
 
 func Test_FailQuery(t *testing.T) {
-	rvm, err := NewRangeMapper(2*time.Minute, nilShardMetrics)
+	rvm, err := NewRangeMapper(2*time.Minute, nilShardMetrics, false)
 	require.NoError(t, err)
 	_, _, err = rvm.Parse(`{app="foo"} |= "err"`)
 	require.Error(t, err)
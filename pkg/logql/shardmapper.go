@@ -28,12 +28,15 @@ func (s ConstantShards) GetStats(_ syntax.Expr) (stats.Stats, error) { return st
 type ShardMapper struct {
 	shards  ShardResolver
 	metrics *MapperMetrics
+	// quantileSketches shards quantile_over_time by merging the quantile sketches of the shards.
+	quantileSketches bool
 }
 
-func NewShardMapper(resolver ShardResolver, metrics *MapperMetrics) ShardMapper {
+func NewShardMapper(resolver ShardResolver, metrics *MapperMetrics, quantileSketches bool) ShardMapper {
 	return ShardMapper{
-		shards:  resolver,
-		metrics: metrics,
+		shards:           resolver,
+		metrics:          metrics,
+		quantileSketches: quantileSketches,
 	}
 }
 
@@ -87,6 +90,8 @@ func (m ShardMapper) Map(expr syntax.Expr, r *downstreamRecorder) (syntax.Expr,
 		return m.mapVectorAggregationExpr(e, r)
 	case *syntax.LabelReplaceExpr:
 		return m.mapLabelReplaceExpr(e, r)
+	case *syntax.SubqueryExpr:
+		return m.mapSubqueryExpr(e, r)
 	case *syntax.RangeAggregationExpr:
 		return m.mapRangeAggregationExpr(e, r)
 	case *syntax.BinOpExpr:
@@ -183,6 +188,23 @@ func (m ShardMapper) mapSampleExpr(expr syntax.SampleExpr, r *downstreamRecorder
 // technically, std{dev,var} are also parallelizable if there is no cross-shard merging
 // in descendent nodes in the AST. This optimization is currently avoided for simplicity.
 func (m ShardMapper) mapVectorAggregationExpr(expr *syntax.VectorAggregationExpr, r *downstreamRecorder) (syntax.SampleExpr, uint64, error) {
+	// max and min are not shardable as they do not distribute over the other
+	// aggregations, but they can be computed by the shards if every series of
+	// the child node is computed by a single shard.
+	// max(x) -> max(max(x, shard=1) ++ max(x, shard=2)...)
+	// same goes for min
+	if (expr.Operation == syntax.OpTypeMax || expr.Operation == syntax.OpTypeMin) && isShardedPerSeries(expr.Left) {
+		sharded, bytesPerShard, err := m.mapSampleExpr(expr, r)
+		if err != nil {
+			return nil, 0, err
+		}
+		return &syntax.VectorAggregationExpr{
+			Left:      sharded,
+			Grouping:  expr.Grouping,
+			Operation: expr.Operation,
+		}, bytesPerShard, nil
+	}
+
 	// if this AST contains unshardable operations, don't shard this at this level,
 	// but attempt to shard a child node.
 	if !expr.Shardable() {
@@ -282,8 +304,33 @@ func (m ShardMapper) mapLabelReplaceExpr(expr *syntax.LabelReplaceExpr, r *downs
 	return &cpy, bytesPerShard, nil
 }
 
+// mapSubqueryExpr shards the inner query of the subquery, whose samples are aggregated by the frontend.
+func (m ShardMapper) mapSubqueryExpr(expr *syntax.SubqueryExpr, r *downstreamRecorder) (syntax.SampleExpr, uint64, error) {
+	subMapped, bytesPerShard, err := m.Map(expr.Left, r)
+	if err != nil {
+		return nil, 0, err
+	}
+	cpy := *expr
+	cpy.Left = subMapped.(syntax.SampleExpr)
+	return &cpy, bytesPerShard, nil
+}
+
 func (m ShardMapper) mapRangeAggregationExpr(expr *syntax.RangeAggregationExpr, r *downstreamRecorder) (syntax.SampleExpr, uint64, error) {
-	if hasLabelModifier(expr) {
+	if expr.Operation == syntax.OpRangeTypeQuantile && m.quantileSketches {
+		// quantile_over_time(q, x) -> quantile(q, quantileSketch<quantile_over_time(q, x), shard=1> ++ quantileSketch<quantile_over_time(q, x), shard=2>...)
+		// The buckets of the sketches of the same labels are summed, so the shards can return the same series,
+		// even when the labels are modified.
+		sharded, bytesPerShard, err := m.mapSampleExpr(&QuantileSketchExpr{expr}, r)
+		if err != nil {
+			return nil, 0, err
+		}
+		return &QuantileSketchEvalExpr{
+			SampleExpr: sharded,
+			Quantile:   *expr.Params,
+		}, bytesPerShard, nil
+	}
+
+	if hasLabelModifier(expr) || (perSeriesRangeOps[expr.Operation] && keepsOrDropsLabels(expr)) {
 		// if an expr can modify labels this means multiple shards can return the same labelset.
 		// When this happens the merge strategy needs to be different from a simple concatenation.
 		// For instance for rates we need to sum data from different shards but same series.
@@ -302,6 +349,39 @@ func (m ShardMapper) mapRangeAggregationExpr(expr *syntax.RangeAggregationExpr,
 		// rate(x) -> rate(x, shard=1) ++ rate(x, shard=2)...
 		// same goes for bytes_rate and bytes_over_time
 		return m.mapSampleExpr(expr, r)
+	case syntax.OpRangeTypeSum:
+		// sum_over_time(x) -> sum_over_time(x, shard=1) ++ sum_over_time(x, shard=2)...
+		// sum_over_time doesn't support grouping.
+		return m.mapSampleExpr(expr, r)
+	case syntax.OpRangeTypeMax, syntax.OpRangeTypeMin:
+		// max_over_time(x) -> max_over_time(x, shard=1) ++ max_over_time(x, shard=2)...
+		// same goes for min_over_time
+		sharded, bytesPerShard, err := m.mapSampleExpr(expr, r)
+		if err != nil {
+			return nil, 0, err
+		}
+		if expr.Grouping == nil {
+			return sharded, bytesPerShard, nil
+		}
+		// max_over_time(x) by (a) -> max by (a) (max_over_time(x, shard=1) by (a) ++ max_over_time(x, shard=2) by (a)...)
+		// as the series of a group can be spread across shards.
+		op := syntax.OpTypeMax
+		if expr.Operation == syntax.OpRangeTypeMin {
+			op = syntax.OpTypeMin
+		}
+		return &syntax.VectorAggregationExpr{
+			Left:      sharded,
+			Grouping:  expr.Grouping,
+			Operation: op,
+		}, bytesPerShard, nil
+	case syntax.OpRangeTypeFirst, syntax.OpRangeTypeLast:
+		// first_over_time(x) -> first_over_time(x, shard=1) ++ first_over_time(x, shard=2)...
+		// same goes for last_over_time, as every series is computed by a single shard.
+		// There is no vector aggregation merging the first or last values of a group across shards though.
+		if expr.Grouping == nil {
+			return m.mapSampleExpr(expr, r)
+		}
+		fallthrough
 	default:
 		// This part of the query is not shardable, so the bytesPerShard is the bytes for all the log matchers in expr
 		exprStats, err := m.shards.GetStats(expr)
@@ -329,6 +409,48 @@ func hasLabelModifier(expr *syntax.RangeAggregationExpr) bool {
 	return false
 }
 
+// perSeriesRangeOps are the range aggregations whose partial results can't
+// be merged, so they are only sharded if every series is computed by a
+// single shard.
+var perSeriesRangeOps = map[string]bool{
+	syntax.OpRangeTypeSum:   true,
+	syntax.OpRangeTypeMax:   true,
+	syntax.OpRangeTypeMin:   true,
+	syntax.OpRangeTypeFirst: true,
+	syntax.OpRangeTypeLast:  true,
+}
+
+// keepsOrDropsLabels tells if an expression contains keep or drop stages,
+// which can make the streams of different shards result in the same series.
+func keepsOrDropsLabels(expr syntax.SampleExpr) bool {
+	found := false
+	expr.Walk(func(e interface{}) {
+		switch e.(type) {
+		case *syntax.KeepLabelsExpr, *syntax.DropLabelsExpr:
+			found = true
+		}
+	})
+	return found
+}
+
+// isShardedPerSeries tells if every series of a shardable expression is
+// computed by a single shard, i.e. the expression does not merge series
+// and does not modify the labels of the streams.
+func isShardedPerSeries(expr syntax.SampleExpr) bool {
+	if !expr.Shardable() {
+		return false
+	}
+	perSeries := true
+	expr.Walk(func(e interface{}) {
+		switch e.(type) {
+		case *syntax.VectorAggregationExpr, *syntax.BinOpExpr, *syntax.LabelReplaceExpr, *syntax.SubqueryExpr,
+			*syntax.LabelFmtExpr, *syntax.KeepLabelsExpr, *syntax.DropLabelsExpr:
+			perSeries = false
+		}
+	})
+	return perSeries
+}
+
 func badASTMapping(got syntax.Expr) error {
 	return fmt.Errorf("bad AST mapping: expected SampleExpr, but got (%T)", got)
 }
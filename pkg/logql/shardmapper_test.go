@@ -51,7 +51,7 @@ func TestShardedStringer(t *testing.T) {
 }
 
 func TestMapSampleExpr(t *testing.T) {
-	m := NewShardMapper(ConstantShards(2), nilShardMetrics)
+	m := NewShardMapper(ConstantShards(2), nilShardMetrics, false)
 
 	for _, tc := range []struct {
 		in  syntax.SampleExpr
@@ -113,7 +113,7 @@ func TestMapSampleExpr(t *testing.T) {
 }
 
 func TestMappingStrings(t *testing.T) {
-	m := NewShardMapper(ConstantShards(2), nilShardMetrics)
+	m := NewShardMapper(ConstantShards(2), nilShardMetrics, false)
 	for _, tc := range []struct {
 		in  string
 		out string
@@ -154,8 +154,8 @@ func TestMappingStrings(t *testing.T) {
 		{
 			in: `sum(max(rate({foo="bar"}[5m])))`,
 			out: `sum(max(
-				downstream<rate({foo="bar"}[5m]), shard=0_of_2>
-				++ downstream<rate({foo="bar"}[5m]), shard=1_of_2>
+				downstream<max(rate({foo="bar"}[5m])), shard=0_of_2>
+				++ downstream<max(rate({foo="bar"}[5m])), shard=1_of_2>
 			))`,
 		},
 		{
@@ -199,6 +199,59 @@ func TestMappingStrings(t *testing.T) {
 				++ downstream<sum by(cluster)(sum_over_time({foo="bar"}|="id=123"| logfmt | unwrap latency[5m])), shard=1_of_2>
 			)`,
 		},
+		{
+			in: `max by (cluster) (sum_over_time({foo="bar"} | logfmt | unwrap latency [5m]))`,
+			out: `max by (cluster) (
+				downstream<max by(cluster)(sum_over_time({foo="bar"} | logfmt | unwrap latency[5m])), shard=0_of_2>
+				++ downstream<max by(cluster)(sum_over_time({foo="bar"} | logfmt | unwrap latency[5m])), shard=1_of_2>
+			)`,
+		},
+		{
+			in:  `max_over_time({foo="bar"} | logfmt | keep cluster | unwrap latency [5m])`,
+			out: `max_over_time({foo="bar"} | logfmt | keep cluster | unwrap latency [5m])`,
+		},
+		{
+			in:  `max(rate({foo="bar"} | label_format cluster="{{.host}}" [5m]))`,
+			out: `max(rate({foo="bar"} | label_format cluster="{{.host}}" [5m]))`,
+		},
+		{
+			in: `min_over_time({foo="bar"} | logfmt | unwrap latency [5m]) by (cluster)`,
+			out: `min by (cluster) (
+				downstream<min_over_time({foo="bar"} | logfmt | unwrap latency[5m]) by (cluster), shard=0_of_2>
+				++ downstream<min_over_time({foo="bar"} | logfmt | unwrap latency[5m]) by (cluster), shard=1_of_2>
+			)`,
+		},
+		{
+			in: `max(max_over_time({foo="bar"} | logfmt | unwrap latency [5m]) by (cluster))`,
+			out: `max(max by (cluster) (
+				downstream<max_over_time({foo="bar"} | logfmt | unwrap latency[5m]) by (cluster), shard=0_of_2>
+				++ downstream<max_over_time({foo="bar"} | logfmt | unwrap latency[5m]) by (cluster), shard=1_of_2>
+			))`,
+		},
+		{
+			in: `approx_topk(10, sum by (cluster) (rate({foo="bar"}[5m])))`,
+			out: `approx_topk(10, sum by (cluster) (
+				downstream<sum by (cluster) (rate({foo="bar"}[5m])), shard=0_of_2>
+				++ downstream<sum by (cluster) (rate({foo="bar"}[5m])), shard=1_of_2>
+			))`,
+		},
+		{
+			in: `first_over_time({foo="bar"} | logfmt | unwrap latency [5m])`,
+			out: `downstream<first_over_time({foo="bar"} | logfmt | unwrap latency[5m]), shard=0_of_2>
+				++ downstream<first_over_time({foo="bar"} | logfmt | unwrap latency[5m]), shard=1_of_2>`,
+		},
+		{
+			in:  `first_over_time({foo="bar"} | logfmt | drop host | unwrap latency [5m])`,
+			out: `first_over_time({foo="bar"} | logfmt | drop host | unwrap latency [5m])`,
+		},
+		{
+			in:  `max(last_over_time({foo="bar"} | logfmt | drop host | unwrap latency [5m]))`,
+			out: `max(last_over_time({foo="bar"} | logfmt | drop host | unwrap latency [5m]))`,
+		},
+		{
+			in:  `last_over_time({foo="bar"} | logfmt | unwrap latency [5m]) by (cluster)`,
+			out: `last_over_time({foo="bar"} | logfmt | unwrap latency [5m]) by (cluster)`,
+		},
 		{
 			in:  `sum by (cluster) (stddev_over_time({foo="bar"} |= "id=123" | logfmt | unwrap latency [5m]))`,
 			out: `sum by (cluster) (stddev_over_time({foo="bar"} |= "id=123" | logfmt | unwrap latency [5m]))`,
@@ -307,8 +360,42 @@ func TestMappingStrings(t *testing.T) {
 	}
 }
 
+func TestQuantileSketchMappingStrings(t *testing.T) {
+	for _, tc := range []struct {
+		in               string
+		quantileSketches bool
+		out              string
+	}{
+		{
+			in:  `quantile_over_time(0.99, {a=~".+"} | unwrap b [1s])`,
+			out: `quantile_over_time(0.99, {a=~".+"} | unwrap b [1s])`,
+		},
+		{
+			in:               `quantile_over_time(0.99, {a=~".+"} | unwrap b [1s])`,
+			quantileSketches: true,
+			out:              `quantileSketchEval<downstream<quantileSketch<quantile_over_time(0.99,{a=~".+"}|unwrap b[1s])>,shard=0_of_2>++downstream<quantileSketch<quantile_over_time(0.99,{a=~".+"}|unwrap b[1s])>,shard=1_of_2>, quantile=0.99>`,
+		},
+		{
+			in:               `max(quantile_over_time(0.5, {a=~".+"} | label_format c="{{.b}}" | unwrap b [1s]) by (c))`,
+			quantileSketches: true,
+			out:              `max(quantileSketchEval<downstream<quantileSketch<quantile_over_time(0.5,{a=~".+"}|label_format c="{{.b}}"|unwrap b[1s]) by (c)>,shard=0_of_2>++downstream<quantileSketch<quantile_over_time(0.5,{a=~".+"}|label_format c="{{.b}}"|unwrap b[1s]) by (c)>,shard=1_of_2>, quantile=0.5>)`,
+		},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			m := NewShardMapper(ConstantShards(2), nilShardMetrics, tc.quantileSketches)
+			ast, err := syntax.ParseExpr(tc.in)
+			require.Nil(t, err)
+
+			mapped, _, err := m.Map(ast, nilShardMetrics.downstreamRecorder())
+			require.Nil(t, err)
+
+			require.Equal(t, removeWhiteSpace(tc.out), removeWhiteSpace(mapped.String()))
+		})
+	}
+}
+
 func TestMapping(t *testing.T) {
-	m := NewShardMapper(ConstantShards(2), nilShardMetrics)
+	m := NewShardMapper(ConstantShards(2), nilShardMetrics, false)
 
 	for _, tc := range []struct {
 		in   string
@@ -568,13 +655,20 @@ func TestMapping(t *testing.T) {
 							Shard: 0,
 							Of:    2,
 						},
-						SampleExpr: &syntax.RangeAggregationExpr{
-							Operation: syntax.OpRangeTypeRate,
-							Left: &syntax.LogRange{
-								Left: &syntax.MatchersExpr{
-									Mts: []*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")},
+						SampleExpr: &syntax.VectorAggregationExpr{
+							Grouping: &syntax.Grouping{
+								Without: true,
+								Groups:  []string{"env"},
+							},
+							Operation: syntax.OpTypeMax,
+							Left: &syntax.RangeAggregationExpr{
+								Operation: syntax.OpRangeTypeRate,
+								Left: &syntax.LogRange{
+									Left: &syntax.MatchersExpr{
+										Mts: []*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")},
+									},
+									Interval: 5 * time.Minute,
 								},
-								Interval: 5 * time.Minute,
 							},
 						},
 					},
@@ -584,13 +678,20 @@ func TestMapping(t *testing.T) {
 								Shard: 1,
 								Of:    2,
 							},
-							SampleExpr: &syntax.RangeAggregationExpr{
-								Operation: syntax.OpRangeTypeRate,
-								Left: &syntax.LogRange{
-									Left: &syntax.MatchersExpr{
-										Mts: []*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")},
+							SampleExpr: &syntax.VectorAggregationExpr{
+								Grouping: &syntax.Grouping{
+									Without: true,
+									Groups:  []string{"env"},
+								},
+								Operation: syntax.OpTypeMax,
+								Left: &syntax.RangeAggregationExpr{
+									Operation: syntax.OpRangeTypeRate,
+									Left: &syntax.LogRange{
+										Left: &syntax.MatchersExpr{
+											Mts: []*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")},
+										},
+										Interval: 5 * time.Minute,
 									},
-									Interval: 5 * time.Minute,
 								},
 							},
 						},
@@ -871,7 +972,7 @@ func TestMapping(t *testing.T) {
 				},
 			},
 		},
-		// sum(max) should not shard the maxes
+		// sum(max) should shard the maxes, but not the sum
 		{
 			in: `sum(max(rate({foo="bar"}[5m])))`,
 			expr: &syntax.VectorAggregationExpr{
@@ -886,13 +987,17 @@ func TestMapping(t *testing.T) {
 								Shard: 0,
 								Of:    2,
 							},
-							SampleExpr: &syntax.RangeAggregationExpr{
-								Operation: syntax.OpRangeTypeRate,
-								Left: &syntax.LogRange{
-									Left: &syntax.MatchersExpr{
-										Mts: []*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")},
+							SampleExpr: &syntax.VectorAggregationExpr{
+								Grouping:  &syntax.Grouping{},
+								Operation: syntax.OpTypeMax,
+								Left: &syntax.RangeAggregationExpr{
+									Operation: syntax.OpRangeTypeRate,
+									Left: &syntax.LogRange{
+										Left: &syntax.MatchersExpr{
+											Mts: []*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")},
+										},
+										Interval: 5 * time.Minute,
 									},
-									Interval: 5 * time.Minute,
 								},
 							},
 						},
@@ -902,13 +1007,17 @@ func TestMapping(t *testing.T) {
 									Shard: 1,
 									Of:    2,
 								},
-								SampleExpr: &syntax.RangeAggregationExpr{
-									Operation: syntax.OpRangeTypeRate,
-									Left: &syntax.LogRange{
-										Left: &syntax.MatchersExpr{
-											Mts: []*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")},
+								SampleExpr: &syntax.VectorAggregationExpr{
+									Grouping:  &syntax.Grouping{},
+									Operation: syntax.OpTypeMax,
+									Left: &syntax.RangeAggregationExpr{
+										Operation: syntax.OpRangeTypeRate,
+										Left: &syntax.LogRange{
+											Left: &syntax.MatchersExpr{
+												Mts: []*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")},
+											},
+											Interval: 5 * time.Minute,
 										},
-										Interval: 5 * time.Minute,
 									},
 								},
 							},
@@ -1273,7 +1382,7 @@ func TestStringTrimming(t *testing.T) {
 		},
 	} {
 		t.Run(tc.expr, func(t *testing.T) {
-			m := NewShardMapper(ConstantShards(tc.shards), nilShardMetrics)
+			m := NewShardMapper(ConstantShards(tc.shards), nilShardMetrics, false)
 			_, _, mappedExpr, err := m.Parse(tc.expr)
 			require.Nil(t, err)
 			require.Equal(t, removeWhiteSpace(tc.expected), removeWhiteSpace(mappedExpr.String()))
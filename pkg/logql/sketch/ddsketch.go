@@ -0,0 +1,198 @@
+package sketch
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+const (
+	// DefaultRelativeAccuracy sizes the buckets of the quantile sketches, so their quantiles
+	// are within 1% of the values of the exact quantiles.
+	DefaultRelativeAccuracy = 0.01
+
+	// minIndexableValue is the smallest absolute value that isn't counted in the zero bucket.
+	minIndexableValue = 1e-300
+	// maxIndex clamps the indexes of the values too large to be represented, like +Inf.
+	maxIndex = math.MaxInt16
+)
+
+// DDSketch estimates the quantiles of the values added, like DDSketch, by counting
+// them in buckets of logarithmic sizes. The quantiles are within the relative accuracy
+// of the exact quantiles, and the sketches of disjoint sets of values can be merged.
+type DDSketch struct {
+	relativeAccuracy   float64
+	gamma, logGamma    float64
+	positive, negative map[int32]float64
+	zero, count        float64
+}
+
+// NewDDSketch returns a sketch estimating the quantiles within the relative accuracy, between 0 and 1.
+func NewDDSketch(relativeAccuracy float64) *DDSketch {
+	gamma := (1 + relativeAccuracy) / (1 - relativeAccuracy)
+	return &DDSketch{
+		relativeAccuracy: relativeAccuracy,
+		gamma:            gamma,
+		logGamma:         math.Log(gamma),
+		positive:         map[int32]float64{},
+		negative:         map[int32]float64{},
+	}
+}
+
+// Add adds a value to the sketch. NaN values are ignored.
+func (s *DDSketch) Add(v float64) {
+	switch {
+	case math.IsNaN(v):
+		return
+	case v > minIndexableValue:
+		s.positive[s.index(v)]++
+	case v < -minIndexableValue:
+		s.negative[s.index(-v)]++
+	default:
+		s.zero++
+	}
+	s.count++
+}
+
+// index returns the index of the bucket (gamma^(i-1), gamma^i] of a positive value.
+func (s *DDSketch) index(v float64) int32 {
+	i := math.Ceil(math.Log(v) / s.logGamma)
+	if i > maxIndex {
+		return maxIndex
+	}
+	return int32(i)
+}
+
+// value returns the estimate of the values of the bucket of a positive index,
+// which is within the relative accuracy of all the values of the bucket.
+func (s *DDSketch) value(i int32) float64 {
+	return 2 * math.Pow(s.gamma, float64(i)) / (s.gamma + 1)
+}
+
+// Count returns the number of values added to the sketch.
+func (s *DDSketch) Count() float64 {
+	return s.count
+}
+
+// Quantile returns the estimate of the φ-quantile (0 ≤ φ ≤ 1) of the values.
+// It returns -Inf for φ < 0, +Inf for φ > 1 and NaN when the sketch is empty.
+func (s *DDSketch) Quantile(q float64) float64 {
+	switch {
+	case s.count == 0 || math.IsNaN(q):
+		return math.NaN()
+	case q < 0:
+		return math.Inf(-1)
+	case q > 1:
+		return math.Inf(1)
+	}
+
+	// the values of the ranks around φ*(n-1) are interpolated, like quantile_over_time does.
+	rank := q * (s.count - 1)
+	lower, upper := s.valueAt(math.Floor(rank)), s.valueAt(math.Ceil(rank))
+	weight := rank - math.Floor(rank)
+	return lower*(1-weight) + upper*weight
+}
+
+// valueAt returns the estimate of the value of a rank, counted from 0.
+func (s *DDSketch) valueAt(rank float64) float64 {
+	var n, v float64
+	s.forEachBucket(func(sign int, i int32, count float64) {
+		if n > rank {
+			return
+		}
+		n += count
+		v = float64(sign) * s.value(i)
+	})
+	return v
+}
+
+// Merge adds the counts of the other sketch, which must have the same relative accuracy.
+// The quantiles of the merged sketch are the quantiles of the values of both sketches.
+func (s *DDSketch) Merge(other *DDSketch) error {
+	if s.relativeAccuracy != other.relativeAccuracy {
+		return fmt.Errorf("cannot merge a quantile sketch with a relative accuracy of %v into one of %v", other.relativeAccuracy, s.relativeAccuracy)
+	}
+	for i, c := range other.positive {
+		s.positive[i] += c
+	}
+	for i, c := range other.negative {
+		s.negative[i] += c
+	}
+	s.zero += other.zero
+	s.count += other.count
+	return nil
+}
+
+// Buckets calls fn with the name and the count of every non-empty bucket, by increasing values.
+// The counts can be added back to a sketch of the same relative accuracy with AddBucket.
+func (s *DDSketch) Buckets(fn func(bucket string, count float64)) {
+	s.forEachBucket(func(sign int, i int32, count float64) {
+		switch sign {
+		case -1:
+			fn("-"+strconv.Itoa(int(i)), count)
+		case 0:
+			fn("0", count)
+		default:
+			fn("+"+strconv.Itoa(int(i)), count)
+		}
+	})
+}
+
+// forEachBucket calls fn with the sign, the index and the count of every non-empty bucket, by increasing values.
+func (s *DDSketch) forEachBucket(fn func(sign int, i int32, count float64)) {
+	negative := sortedIndexes(s.negative)
+	// the largest absolute values are the smallest negative values.
+	for i := len(negative) - 1; i >= 0; i-- {
+		fn(-1, negative[i], s.negative[negative[i]])
+	}
+	if s.zero > 0 {
+		fn(0, 0, s.zero)
+	}
+	for _, i := range sortedIndexes(s.positive) {
+		fn(1, i, s.positive[i])
+	}
+}
+
+// AddBucket adds the count of a bucket named by Buckets.
+func (s *DDSketch) AddBucket(bucket string, count float64) error {
+	if bucket == "0" {
+		s.zero += count
+		s.count += count
+		return nil
+	}
+	if len(bucket) < 2 || (bucket[0] != '+' && bucket[0] != '-') {
+		return fmt.Errorf("invalid quantile sketch bucket %q", bucket)
+	}
+	i, err := strconv.ParseInt(bucket[1:], 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid quantile sketch bucket %q: %w", bucket, err)
+	}
+	if bucket[0] == '+' {
+		s.positive[int32(i)] += count
+	} else {
+		s.negative[int32(i)] += count
+	}
+	s.count += count
+	return nil
+}
+
+// Reset removes all the values, keeping the relative accuracy.
+func (s *DDSketch) Reset() {
+	for i := range s.positive {
+		delete(s.positive, i)
+	}
+	for i := range s.negative {
+		delete(s.negative, i)
+	}
+	s.zero, s.count = 0, 0
+}
+
+func sortedIndexes(buckets map[int32]float64) []int32 {
+	indexes := make([]int32, 0, len(buckets))
+	for i := range buckets {
+		indexes = append(indexes, i)
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+	return indexes
+}
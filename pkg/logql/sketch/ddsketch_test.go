@@ -0,0 +1,57 @@
+package sketch
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDDSketch(t *testing.T) {
+	s := NewDDSketch(DefaultRelativeAccuracy)
+	require.True(t, math.IsNaN(s.Quantile(0.5)))
+
+	r := rand.New(rand.NewSource(1))
+	values := make([]float64, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		v := r.ExpFloat64() * 100
+		if i%10 == 0 {
+			v = -v
+		}
+		values = append(values, v)
+		s.Add(v)
+	}
+	s.Add(math.NaN())
+	require.Equal(t, 1000.0, s.Count())
+
+	// The quantiles are within the relative accuracy of the exact quantiles.
+	sort.Float64s(values)
+	for _, q := range []float64{0, 0.01, 0.05, 0.25, 0.5, 0.9, 0.99, 1} {
+		rank := q * float64(len(values)-1)
+		lower, upper := values[int(math.Floor(rank))], values[int(math.Ceil(rank))]
+		exact := lower + (upper-lower)*(rank-math.Floor(rank))
+		require.InDelta(t, exact, s.Quantile(q), math.Abs(exact)*DefaultRelativeAccuracy, "quantile %v", q)
+	}
+	require.Equal(t, math.Inf(-1), s.Quantile(-1))
+	require.Equal(t, math.Inf(1), s.Quantile(2))
+
+	// The buckets can be added back to another sketch, like the buckets returned by the shards.
+	other := NewDDSketch(DefaultRelativeAccuracy)
+	s.Buckets(func(bucket string, count float64) {
+		require.NoError(t, other.AddBucket(bucket, count))
+	})
+	require.Equal(t, s.Quantile(0.9), other.Quantile(0.9))
+	require.Error(t, other.AddBucket("1", 1))
+
+	require.NoError(t, s.Merge(other))
+	require.Equal(t, 2000.0, s.Count())
+	require.Equal(t, other.Quantile(0.5), s.Quantile(0.5))
+	require.Error(t, s.Merge(NewDDSketch(0.05)))
+
+	s.Reset()
+	require.Equal(t, 0.0, s.Count())
+	s.Add(0)
+	require.Equal(t, 0.0, s.Quantile(0.5))
+}
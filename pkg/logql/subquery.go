@@ -0,0 +1,116 @@
+package logql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/loki/pkg/iter"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/logql/syntax"
+	"github.com/grafana/loki/pkg/logqlmodel"
+)
+
+// maxSubqueryPoints is the maximum number of points per series the inner query of a subquery is evaluated at,
+// like the maximum resolution of range queries.
+const maxSubqueryPoints = 11000
+
+// subqueryEvaluator evaluates the inner query of the subquery at its resolution over the range
+// of all the steps, and aggregates its samples within the range of each step like unwrapped samples.
+// The evaluation times of the inner query are aligned to multiples of the resolution, like in Prometheus,
+// so the samples of the subquery don't depend on the start of the query, e.g. when it's split by time.
+func subqueryEvaluator(
+	ctx context.Context,
+	ev SampleEvaluator,
+	expr *syntax.SubqueryExpr,
+	q Params,
+) (StepEvaluator, error) {
+	step := expr.Step.Nanoseconds()
+	start := q.Start().Add(-expr.Offset).Add(-expr.Range).UnixNano()
+	// the lower bound of the range is not inclusive.
+	start = start - start%step + step
+	end := q.End().Add(-expr.Offset)
+
+	var it iter.SampleIterator = iter.NoopIterator
+	if start <= end.UnixNano() {
+		if points := (end.UnixNano()-start)/step + 1; points > maxSubqueryPoints {
+			return nil, fmt.Errorf("%w: the subquery %s is evaluated at %d points per series, which exceeds the maximum of %d points. Try increasing its resolution", logqlmodel.ErrLimit, expr.String(), points, maxSubqueryPoints)
+		}
+		params := NewLiteralParams(
+			expr.Left.String(),
+			time.Unix(0, start), end,
+			expr.Step, q.Interval(),
+			q.Direction(), q.Limit(), q.Shards(),
+		)
+		series, err := subquerySeries(ctx, ev, expr.Left, params)
+		if err != nil {
+			return nil, err
+		}
+		it = iter.NewMultiSeriesIterator(series)
+	}
+
+	if expr.Operation == syntax.OpRangeTypeAbsent {
+		// the labels of the absent series come from the selector of the inner query.
+		lbs, err := absentLabels(expr)
+		if err != nil {
+			return nil, err
+		}
+		iter, err := newRangeVectorIterator(
+			iter.NewPeekingSampleIterator(it), &syntax.RangeAggregationExpr{Operation: expr.Operation},
+			expr.Range.Nanoseconds(), q.Step().Nanoseconds(),
+			q.Start().UnixNano(), q.End().UnixNano(), expr.Offset.Nanoseconds(),
+		)
+		if err != nil {
+			return nil, err
+		}
+		return &absentRangeVectorEvaluator{
+			iter: iter,
+			lbs:  lbs,
+		}, nil
+	}
+
+	rangeExpr := &syntax.RangeAggregationExpr{
+		Left: &syntax.LogRange{
+			Interval: expr.Range,
+			Offset:   expr.Offset,
+			Unwrap:   &syntax.UnwrapExpr{},
+		},
+		Operation: expr.Operation,
+		Params:    expr.Params,
+	}
+	return rangeAggEvaluator(iter.NewPeekingSampleIterator(it), rangeExpr, q, expr.Offset)
+}
+
+// subquerySeries returns the series of the samples of the inner query of a subquery.
+func subquerySeries(ctx context.Context, ev SampleEvaluator, expr syntax.SampleExpr, q Params) ([]logproto.Series, error) {
+	stepEvaluator, err := ev.StepEvaluator(ctx, ev, expr, q)
+	if err != nil {
+		return nil, err
+	}
+	defer stepEvaluator.Close()
+
+	var series []logproto.Series
+	index := map[uint64]int{}
+	for next, ts, vec := stepEvaluator.Next(); next; next, ts, vec = stepEvaluator.Next() {
+		for _, s := range vec {
+			hash := s.Metric.Hash()
+			i, ok := index[hash]
+			if !ok {
+				i = len(series)
+				index[hash] = i
+				series = append(series, logproto.Series{
+					Labels:     s.Metric.String(),
+					StreamHash: hash,
+				})
+			}
+			series[i].Samples = append(series[i].Samples, logproto.Sample{
+				Timestamp: time.UnixMilli(ts).UnixNano(),
+				Value:     s.F,
+			})
+		}
+	}
+	if err := stepEvaluator.Error(); err != nil {
+		return nil, err
+	}
+	return series, nil
+}
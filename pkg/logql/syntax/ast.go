@@ -261,7 +261,7 @@ func (e *PipelineExpr) Pipeline() (log.Pipeline, error) {
 func (e *PipelineExpr) HasFilter() bool {
 	for _, p := range e.MultiStages {
 		switch p.(type) {
-		case *LineFilterExpr, *LabelFilterExpr, *DistinctFilterExpr:
+		case *LineFilterExpr, *LabelFilterExpr, *DistinctFilterExpr, *SampleFilterExpr:
 			return true
 		default:
 			continue
@@ -391,6 +391,9 @@ func (e *LineFilterExpr) Stage() (log.Stage, error) {
 type LabelParserExpr struct {
 	Op    string
 	Param string
+	// Strict and KeepEmpty are the flags of the logfmt parser, which keeps its default behavior without flags.
+	Strict    bool
+	KeepEmpty bool
 	implicit
 }
 
@@ -414,6 +417,22 @@ func newLabelParserExpr(op, param string) *LabelParserExpr {
 	}
 }
 
+// newLogfmtParserExpr returns the logfmt parser with the flags, e.g. | logfmt --strict --keep-empty.
+func newLogfmtParserExpr(flags []string) *LabelParserExpr {
+	e := &LabelParserExpr{Op: OpParserTypeLogfmt}
+	for _, f := range flags {
+		switch f {
+		case OpStrict:
+			e.Strict = true
+		case OpKeepEmpty:
+			e.KeepEmpty = true
+		default:
+			panic(logqlmodel.NewParseError(fmt.Sprintf("invalid logfmt parser flag %s", f), 0, 0))
+		}
+	}
+	return e
+}
+
 func (e *LabelParserExpr) Shardable() bool { return true }
 
 func (e *LabelParserExpr) Walk(f WalkFn) { f(e) }
@@ -423,6 +442,9 @@ func (e *LabelParserExpr) Stage() (log.Stage, error) {
 	case OpParserTypeJSON:
 		return log.NewJSONParser(), nil
 	case OpParserTypeLogfmt:
+		if e.Strict || e.KeepEmpty {
+			return log.NewLogfmtParserWithFlags(e.Strict, e.KeepEmpty), nil
+		}
 		return log.NewLogfmtParser(), nil
 	case OpParserTypeRegexp:
 		return log.NewRegexpParser(e.Param)
@@ -447,6 +469,12 @@ func (e *LabelParserExpr) String() string {
 	if (e.Op == OpParserTypeRegexp || e.Op == OpParserTypePattern) && e.Param == "" {
 		sb.WriteString(" \"\"")
 	}
+	if e.Strict {
+		sb.WriteString(" " + OpStrict)
+	}
+	if e.KeepEmpty {
+		sb.WriteString(" " + OpKeepEmpty)
+	}
 	return sb.String()
 }
 
@@ -703,6 +731,34 @@ func (e *DistinctFilterExpr) String() string {
 	return sb.String()
 }
 
+type SampleFilterExpr struct {
+	Rate float64
+	implicit
+}
+
+func newSampleFilterExpr(rate string) *SampleFilterExpr {
+	r := mustNewFloat(rate)
+	if r <= 0 || r > 1 {
+		panic(logqlmodel.NewParseError(fmt.Sprintf("invalid sampling rate %s, it must be greater than 0 and at most 1", rate), 0, 0))
+	}
+	return &SampleFilterExpr{
+		Rate: r,
+	}
+}
+
+// Shardable returns true as the lines are sampled by their own content and timestamp.
+func (e *SampleFilterExpr) Shardable() bool { return true }
+
+func (e *SampleFilterExpr) Walk(f WalkFn) { f(e) }
+
+func (e *SampleFilterExpr) Stage() (log.Stage, error) {
+	return log.NewSampleFilter(e.Rate), nil
+}
+
+func (e *SampleFilterExpr) String() string {
+	return fmt.Sprintf("%s %s %s", OpPipe, OpFilterSample, strconv.FormatFloat(e.Rate, 'f', -1, 64))
+}
+
 type internedStringSet map[string]struct {
 	s  string
 	ok bool
@@ -780,13 +836,17 @@ func mustNewFloat(s string) float64 {
 type UnwrapExpr struct {
 	Identifier string
 	Operation  string
+	// Unit is the unit the to_float conversion converts the values to.
+	Unit string
 
 	PostFilters []log.LabelFilterer
 }
 
 func (u UnwrapExpr) String() string {
 	var sb strings.Builder
-	if u.Operation != "" {
+	if u.Unit != "" {
+		sb.WriteString(fmt.Sprintf(" %s %s %s(%s, %s)", OpPipe, OpUnwrap, u.Operation, u.Identifier, strconv.Quote(u.Unit)))
+	} else if u.Operation != "" {
 		sb.WriteString(fmt.Sprintf(" %s %s %s(%s)", OpPipe, OpUnwrap, u.Operation, u.Identifier))
 	} else {
 		sb.WriteString(fmt.Sprintf(" %s %s %s", OpPipe, OpUnwrap, u.Identifier))
@@ -806,6 +866,10 @@ func newUnwrapExpr(id string, operation string) *UnwrapExpr {
 	return &UnwrapExpr{Identifier: id, Operation: operation}
 }
 
+func newUnwrapExprWithUnit(id string, operation string, unit string) *UnwrapExpr {
+	return &UnwrapExpr{Identifier: id, Operation: operation, Unit: unit}
+}
+
 type LogRange struct {
 	Left     LogSelectorExpr
 	Interval time.Duration
@@ -883,6 +947,8 @@ const (
 	OpTypeTopK     = "topk"
 	OpTypeSort     = "sort"
 	OpTypeSortDesc = "sort_desc"
+	// OpTypeApproxTopK is the topk of a sum aggregation, it is evaluated as an exact topk.
+	OpTypeApproxTopK = "approx_topk"
 
 	// range vector ops
 	OpRangeTypeCount       = "count_over_time"
@@ -932,6 +998,10 @@ const (
 	OpParserTypeUnpack  = "unpack"
 	OpParserTypePattern = "pattern"
 
+	// logfmt parser flags
+	OpStrict    = "--strict"
+	OpKeepEmpty = "--keep-empty"
+
 	OpFmtLine    = "line_format"
 	OpFmtLabel   = "label_format"
 	OpDecolorize = "decolorize"
@@ -948,8 +1018,10 @@ const (
 
 	// conversion Op
 	OpConvBytes           = "bytes"
+	OpConvBytesInt        = "bytes_int"
 	OpConvDuration        = "duration"
 	OpConvDurationSeconds = "duration_seconds"
+	OpConvToFloat         = "to_float"
 
 	OpLabelReplace = "label_replace"
 
@@ -958,6 +1030,8 @@ const (
 
 	OpFilterDistinct = "distinct"
 
+	OpFilterSample = "sample"
+
 	// drop labels
 	OpDrop = "drop"
 
@@ -1106,6 +1180,10 @@ func (e *RangeAggregationExpr) String() string {
 
 // impl SampleExpr
 func (e *RangeAggregationExpr) Shardable() bool {
+	// grouped range aggregations merge series which can be in different shards.
+	if e.Grouping != nil {
+		return false
+	}
 	return shardableOps[e.Operation] && e.Left.Shardable()
 }
 
@@ -1162,7 +1240,7 @@ func mustNewVectorAggregationExpr(left SampleExpr, operation string, gr *Groupin
 	var p int
 	var err error
 	switch operation {
-	case OpTypeBottomK, OpTypeTopK:
+	case OpTypeBottomK, OpTypeTopK, OpTypeApproxTopK:
 		if params == nil {
 			return &VectorAggregationExpr{err: logqlmodel.NewParseError(fmt.Sprintf("parameter required for operation %s", operation), 0, 0)}
 		}
@@ -1179,6 +1257,9 @@ func mustNewVectorAggregationExpr(left SampleExpr, operation string, gr *Groupin
 			return &VectorAggregationExpr{err: logqlmodel.NewParseError(fmt.Sprintf("unsupported parameter for operation %s(%s,", operation, *params), 0, 0)}
 		}
 	}
+	if operation == OpTypeApproxTopK && gr != nil {
+		return &VectorAggregationExpr{err: logqlmodel.NewParseError(fmt.Sprintf("grouping not allowed for %s aggregation, use it over a sum by aggregation instead", operation), 0, 0)}
+	}
 	if gr == nil {
 		gr = &Grouping{}
 	}
@@ -1236,7 +1317,7 @@ func (e *VectorAggregationExpr) String() string {
 	var params []string
 	switch e.Operation {
 	// bottomK and topk can have first parameter as 0
-	case OpTypeBottomK, OpTypeTopK:
+	case OpTypeBottomK, OpTypeTopK, OpTypeApproxTopK:
 		params = []string{fmt.Sprintf("%d", e.Params), e.Left.String()}
 	default:
 		if e.Params != 0 {
@@ -1451,6 +1532,10 @@ func mustNewBinOpExpr(op string, opts *BinOpOptions, lhs, rhs Expr) SampleExpr {
 		}
 	}
 
+	if err := validateBinOpOptions(op, opts, lOk || rOk); err != nil {
+		return &BinOpExpr{err: logqlmodel.NewParseError(err.Error(), 0, 0)}
+	}
+
 	// map expr like (1+1) -> 2
 	if lOk && rOk {
 		return reduceBinOp(op, leftVal, rightVal)
@@ -1465,6 +1550,36 @@ func mustNewBinOpExpr(op string, opts *BinOpOptions, lhs, rhs Expr) SampleExpr {
 	}
 }
 
+// validateBinOpOptions rejects the modifiers that don't apply to the binary operation, like PromQL.
+func validateBinOpOptions(op string, opts *BinOpOptions, literal bool) error {
+	if opts == nil {
+		return nil
+	}
+	if opts.ReturnBool && !IsComparisonOperator(op) {
+		return fmt.Errorf("bool modifier can only be used on comparison operators")
+	}
+	matching := opts.VectorMatching
+	if matching == nil {
+		return nil
+	}
+	if literal && (matching.On || len(matching.MatchingLabels) > 0 || matching.Card != CardOneToOne) {
+		return fmt.Errorf("vector matching only allowed between vectors")
+	}
+	if IsLogicalBinOp(op) && matching.Card != CardOneToOne {
+		return fmt.Errorf("no grouping allowed for %q operation", op)
+	}
+	if matching.On {
+		for _, l := range matching.Include {
+			for _, m := range matching.MatchingLabels {
+				if l == m {
+					return fmt.Errorf("label %q must not occur in ON and GROUP clause at once", l)
+				}
+			}
+		}
+	}
+	return nil
+}
+
 // Reduces a binary operation expression. A binop is reducible if both of its legs are literal expressions.
 // This is because literals need match all labels, which is currently difficult to encode into StepEvaluators.
 // Therefore, we ensure a binop can be reduced/simplified, maintaining the invariant that it does not have two literal legs.
@@ -1764,6 +1879,12 @@ func mustNewLabelReplaceExpr(left SampleExpr, dst, replacement, src, regex strin
 			err: logqlmodel.NewParseError(fmt.Sprintf("invalid regex in label_replace: %s", err.Error()), 0, 0),
 		}
 	}
+	// An empty destination is kept valid for the queries using label_replace without replacing anything.
+	if dst != "" && !model.LabelName(dst).IsValid() {
+		return &LabelReplaceExpr{
+			err: logqlmodel.NewParseError(fmt.Sprintf("invalid destination label name in label_replace: %s", dst), 0, 0),
+		}
+	}
 	return &LabelReplaceExpr{
 		Left:        left,
 		Dst:         dst,
@@ -1824,6 +1945,125 @@ func (e *LabelReplaceExpr) String() string {
 	return sb.String()
 }
 
+// SubqueryExpr is a range aggregation over the samples of a metric query evaluated at the
+// resolution of the subquery within its range, e.g. max_over_time(rate({app="foo"}[1m])[1h:1m]).
+type SubqueryExpr struct {
+	Left      SampleExpr
+	Operation string
+	Params    *float64
+
+	Range  time.Duration
+	Step   time.Duration
+	Offset time.Duration
+
+	err error
+	implicit
+}
+
+// subqueryRange is the range and the resolution of a subquery, e.g. [1h:1m].
+type subqueryRange struct {
+	Range, Step time.Duration
+}
+
+func newSubqueryExpr(left SampleExpr, operation string, r subqueryRange, o *OffsetExpr, stringParams *string) SampleExpr {
+	var params *float64
+	if stringParams != nil {
+		if operation != OpRangeTypeQuantile {
+			return &SubqueryExpr{err: logqlmodel.NewParseError(fmt.Sprintf("parameter %s not supported for operation %s", *stringParams, operation), 0, 0)}
+		}
+		var err error
+		params = new(float64)
+		*params, err = strconv.ParseFloat(*stringParams, 64)
+		if err != nil {
+			return &SubqueryExpr{err: logqlmodel.NewParseError(fmt.Sprintf("invalid parameter for operation %s: %s", operation, err), 0, 0)}
+		}
+	} else if operation == OpRangeTypeQuantile {
+		return &SubqueryExpr{err: logqlmodel.NewParseError(fmt.Sprintf("parameter required for operation %s", operation), 0, 0)}
+	}
+
+	switch operation {
+	case OpRangeTypeRate, OpRangeTypeRateCounter, OpRangeTypeCount, OpRangeTypeSum, OpRangeTypeAvg, OpRangeTypeMax,
+		OpRangeTypeMin, OpRangeTypeStddev, OpRangeTypeStdvar, OpRangeTypeQuantile, OpRangeTypeFirst, OpRangeTypeLast,
+		OpRangeTypeAbsent:
+	default:
+		return &SubqueryExpr{err: logqlmodel.NewParseError(fmt.Sprintf("invalid aggregation %s over a subquery", operation), 0, 0)}
+	}
+
+	e := &SubqueryExpr{
+		Left:      left,
+		Operation: operation,
+		Params:    params,
+		Range:     r.Range,
+		Step:      r.Step,
+	}
+	if o != nil {
+		e.Offset = o.Offset
+	}
+	return e
+}
+
+func (e *SubqueryExpr) Selector() (LogSelectorExpr, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.Left.Selector()
+}
+
+// MatcherGroups returns the matchers of the inner query, whose ranges are extended by the range
+// and the offset of the subquery.
+func (e *SubqueryExpr) MatcherGroups() ([]MatcherRange, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	groups, err := e.Left.MatcherGroups()
+	if err != nil {
+		return nil, err
+	}
+	for i := range groups {
+		groups[i].Interval += e.Range
+		groups[i].Offset += e.Offset
+	}
+	return groups, nil
+}
+
+func (e *SubqueryExpr) Extractor() (SampleExtractor, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.Left.Extractor()
+}
+
+// Shardable returns false, as the subquery itself is evaluated over the results of its inner query.
+func (e *SubqueryExpr) Shardable() bool {
+	return false
+}
+
+func (e *SubqueryExpr) Walk(f WalkFn) {
+	f(e)
+	if e.Left == nil {
+		return
+	}
+	e.Left.Walk(f)
+}
+
+func (e *SubqueryExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString(e.Operation)
+	sb.WriteString("(")
+	if e.Params != nil {
+		sb.WriteString(strconv.FormatFloat(*e.Params, 'f', -1, 64))
+		sb.WriteString(",")
+	}
+	sb.WriteString(e.Left.String())
+	sb.WriteString(fmt.Sprintf("[%v:%v]", model.Duration(e.Range), model.Duration(e.Step)))
+	if e.Offset != 0 {
+		offsetExpr := OffsetExpr{Offset: e.Offset}
+		sb.WriteString(offsetExpr.String())
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
+
 // shardableOps lists the operations which may be sharded.
 // topk, botk, max, & min all must be concatenated and then evaluated in order to avoid
 // potential data loss due to series distribution across shards.
@@ -1856,6 +2096,9 @@ var shardableOps = map[string]bool{
 	OpRangeTypeSum:       true,
 	OpRangeTypeMax:       true,
 	OpRangeTypeMin:       true,
+	// first and last are only shardable without grouping, see RangeAggregationExpr.Shardable.
+	OpRangeTypeFirst: true,
+	OpRangeTypeLast:  true,
 
 	// binops - arith
 	OpTypeAdd: true,
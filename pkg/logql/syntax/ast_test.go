@@ -44,6 +44,8 @@ func Test_logSelectorExpr_String(t *testing.T) {
 		{`{foo="bar"} |= "baz" |~ "blip" != "flip" !~ "flap" | regexp "(?P<foo>foo|bar)" | ( ( foo<5.01 , bar>20ms ) or foo="bar" ) | line_format "blip{{.boop}}bap" | label_format foo=bar,bar="blip{{.blop}}"`, true},
 		{`{foo="bar"} | distinct id`, true},
 		{`{foo="bar"} | distinct id,time`, true},
+		{`{foo="bar"} | sample 0.5 | logfmt`, true},
+		{`{foo="bar"} | logfmt --strict --keep-empty | level="error"`, true},
 	}
 
 	for _, tt := range tests {
@@ -82,6 +84,7 @@ func Test_SampleExpr_String(t *testing.T) {
 		`sum(count_over_time({job="mysql"}[5m]))`,
 		`sum(count_over_time({job="mysql"}[5m] offset 10m))`,
 		`sum(count_over_time({job="mysql"} | json [5m]))`,
+		`sum(count_over_time({job="mysql"} | sample 0.1 | json [5m]))`,
 		`sum(count_over_time({job="mysql"} | json [5m] offset 10m))`,
 		`sum(count_over_time({job="mysql"} | logfmt [5m]))`,
 		`sum(count_over_time({job="mysql"} | logfmt [5m] offset 10m))`,
@@ -89,6 +92,10 @@ func Test_SampleExpr_String(t *testing.T) {
 		`sum(count_over_time({job="mysql"} | unpack | json [5m]))`,
 		`sum(count_over_time({job="mysql"} | regexp "(?P<foo>foo|bar)" [5m]))`,
 		`sum(count_over_time({job="mysql"} | regexp "(?P<foo>foo|bar)" [5m] offset 10y))`,
+		`max_over_time(rate({job="mysql"}[1m])[1h:1m])`,
+		`absent_over_time(sum(rate({job="mysql"} | json | level="error" [1m]))[10m:1m])`,
+		`quantile_over_time(0.99, sum by (cluster) (count_over_time({job="mysql"}[5m]))[1d:5m] offset 1h)`,
+		`avg_over_time((sum(rate({job="mysql"}[1m])) / sum(rate({job="postgres"}[1m])))[1h:1m])`,
 		`topk(10,sum(rate({region="us-east1"}[5m])) by (name))`,
 		`topk by (name)(10,sum(rate({region="us-east1"}[5m])))`,
 		`avg( rate( ( {job="nginx"} |= "GET" ) [10s] ) ) by (region)`,
@@ -114,6 +121,8 @@ func Test_SampleExpr_String(t *testing.T) {
 		`stdvar_over_time({app="foo"} |= "bar" | json | latency >= 250ms or ( status_code < 500 and status_code > 200)
 		| line_format "blip{{ .foo }}blop {{.status_code}}" | label_format foo=bar,status_code="buzz{{.bar}}" | unwrap foo [5m] offset 10m)`,
 		`sum_over_time({namespace="tns"} |= "level=error" | json |foo>=5,bar<25ms|unwrap latency [5m])`,
+		`sum_over_time({namespace="tns"} | logfmt | unwrap to_float(latency, "ms") [5m])`,
+		`sum_over_time({namespace="tns"} | logfmt | unwrap bytes_int(size) [5m])`,
 		`sum by (job) (
 			sum_over_time({namespace="tns"} |= "level=error" | json | foo=5 and bar<25ms | unwrap latency[5m])
 		/
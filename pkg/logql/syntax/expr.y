@@ -34,15 +34,18 @@ import (
   bytes                   uint64
   str                     string
   duration                time.Duration
+  subqueryRange           subqueryRange
   LiteralExpr             *LiteralExpr
   BinOpModifier           *BinOpOptions
   BoolModifier            *BinOpOptions
   OnOrIgnoringModifier    *BinOpOptions
   LabelParser             *LabelParserExpr
+  ParserFlags             []string
   LineFilters             *LineFilterExpr
   LineFilter              *LineFilterExpr
   DistinctLabel           []string
   DistinctFilter          *DistinctFilterExpr
+  SampleFilter            *SampleFilterExpr
   PipelineExpr            MultiStageExpr
   PipelineStage           StageExpr
   BytesFilter             log.LabelFilterer
@@ -109,6 +112,7 @@ import (
 %type <LineFilter>            lineFilter
 %type <DistinctFilter>        distinctFilter
 %type <DistinctLabel>         distinctLabel
+%type <SampleFilter>          sampleFilter
 %type <LineFormatExpr>        lineFormatExpr
 %type <DecolorizeExpr>        decolorizeExpr
 %type <DropLabelsExpr>        dropLabelsExpr
@@ -124,20 +128,22 @@ import (
 %type <LabelExtractionExpressionList>    labelExtractionExpressionList
 %type <LogfmtExpressionParser>           logfmtExpressionParser
 %type <JSONExpressionParser>             jsonExpressionParser
+%type <ParserFlags>                      parserFlags
 %type <UnwrapExpr>            unwrapExpr
 %type <UnitFilter>            unitFilter
 %type <IPLabelFilter>         ipLabelFilter
 %type <OffsetExpr>            offsetExpr
 
 %token <bytes> BYTES
-%token <str>      IDENTIFIER STRING NUMBER
+%token <str>      IDENTIFIER STRING NUMBER PARSER_FLAG
 %token <duration> DURATION RANGE
+%token <subqueryRange> SUBQUERY_RANGE
 %token <val>      MATCHERS LABELS EQ RE NRE OPEN_BRACE CLOSE_BRACE OPEN_BRACKET CLOSE_BRACKET COMMA DOT PIPE_MATCH PIPE_EXACT
-                  OPEN_PARENTHESIS CLOSE_PARENTHESIS BY WITHOUT COUNT_OVER_TIME RATE RATE_COUNTER SUM SORT SORT_DESC AVG MAX MIN COUNT STDDEV STDVAR BOTTOMK TOPK
+                  OPEN_PARENTHESIS CLOSE_PARENTHESIS BY WITHOUT COUNT_OVER_TIME RATE RATE_COUNTER SUM SORT SORT_DESC AVG MAX MIN COUNT STDDEV STDVAR BOTTOMK TOPK APPROX_TOPK
                   BYTES_OVER_TIME BYTES_RATE BOOL JSON DISTINCT REGEXP LOGFMT PIPE LINE_FMT LABEL_FMT UNWRAP AVG_OVER_TIME SUM_OVER_TIME MIN_OVER_TIME
-                  MAX_OVER_TIME STDVAR_OVER_TIME STDDEV_OVER_TIME QUANTILE_OVER_TIME BYTES_CONV DURATION_CONV DURATION_SECONDS_CONV
+                  MAX_OVER_TIME STDVAR_OVER_TIME STDDEV_OVER_TIME QUANTILE_OVER_TIME BYTES_CONV BYTES_INT_CONV DURATION_CONV DURATION_SECONDS_CONV TO_FLOAT_CONV
                   FIRST_OVER_TIME LAST_OVER_TIME ABSENT_OVER_TIME VECTOR LABEL_REPLACE UNPACK OFFSET PATTERN IP ON IGNORING GROUP_LEFT GROUP_RIGHT
-                  DECOLORIZE DROP KEEP
+                  DECOLORIZE DROP KEEP SAMPLE
 
 // Operators are listed with increasing precedence.
 %left <binOp> OR
@@ -204,13 +210,16 @@ logRangeExpr:
 unwrapExpr:
     PIPE UNWRAP IDENTIFIER                                                   { $$ = newUnwrapExpr($3, "")}
   | PIPE UNWRAP convOp OPEN_PARENTHESIS IDENTIFIER CLOSE_PARENTHESIS         { $$ = newUnwrapExpr($5, $3)}
+  | PIPE UNWRAP convOp OPEN_PARENTHESIS IDENTIFIER COMMA STRING CLOSE_PARENTHESIS { $$ = newUnwrapExprWithUnit($5, $3, $7)}
   | unwrapExpr PIPE labelFilter                                              { $$ = $1.addPostFilter($3) }
   ;
 
 convOp:
     BYTES_CONV              { $$ = OpConvBytes }
+  | BYTES_INT_CONV          { $$ = OpConvBytesInt }
   | DURATION_CONV           { $$ = OpConvDuration }
   | DURATION_SECONDS_CONV   { $$ = OpConvDurationSeconds }
+  | TO_FLOAT_CONV           { $$ = OpConvToFloat }
   ;
 
 rangeAggregationExpr:
@@ -218,6 +227,10 @@ rangeAggregationExpr:
     | rangeOp OPEN_PARENTHESIS NUMBER COMMA logRangeExpr CLOSE_PARENTHESIS           { $$ = newRangeAggregationExpr($5, $1, nil, &$3) }
     | rangeOp OPEN_PARENTHESIS logRangeExpr CLOSE_PARENTHESIS grouping               { $$ = newRangeAggregationExpr($3, $1, $5, nil) }
     | rangeOp OPEN_PARENTHESIS NUMBER COMMA logRangeExpr CLOSE_PARENTHESIS grouping  { $$ = newRangeAggregationExpr($5, $1, $7, &$3) }
+    | rangeOp OPEN_PARENTHESIS metricExpr SUBQUERY_RANGE CLOSE_PARENTHESIS                               { $$ = newSubqueryExpr($3, $1, $4, nil, nil) }
+    | rangeOp OPEN_PARENTHESIS metricExpr SUBQUERY_RANGE offsetExpr CLOSE_PARENTHESIS                    { $$ = newSubqueryExpr($3, $1, $4, $5, nil) }
+    | rangeOp OPEN_PARENTHESIS NUMBER COMMA metricExpr SUBQUERY_RANGE CLOSE_PARENTHESIS                  { $$ = newSubqueryExpr($5, $1, $6, nil, &$3) }
+    | rangeOp OPEN_PARENTHESIS NUMBER COMMA metricExpr SUBQUERY_RANGE offsetExpr CLOSE_PARENTHESIS       { $$ = newSubqueryExpr($5, $1, $6, $7, &$3) }
     ;
 
 vectorAggregationExpr:
@@ -278,6 +291,7 @@ pipelineStage:
   | PIPE dropLabelsExpr          { $$ = $2 }
   | PIPE keepLabelsExpr          { $$ = $2 }
   | PIPE distinctFilter          { $$ = $2 }
+  | PIPE sampleFilter            { $$ = $2 }
  ;
 
 filterOp:
@@ -297,11 +311,17 @@ lineFilters:
 labelParser:
     JSON           { $$ = newLabelParserExpr(OpParserTypeJSON, "") }
   | LOGFMT         { $$ = newLabelParserExpr(OpParserTypeLogfmt, "") }
+  | LOGFMT parserFlags { $$ = newLogfmtParserExpr($2) }
   | REGEXP STRING  { $$ = newLabelParserExpr(OpParserTypeRegexp, $2) }
   | UNPACK         { $$ = newLabelParserExpr(OpParserTypeUnpack, "") }
   | PATTERN STRING { $$ = newLabelParserExpr(OpParserTypePattern, $2) }
   ;
 
+parserFlags:
+    PARSER_FLAG             { $$ = []string{$1} }
+  | parserFlags PARSER_FLAG { $$ = append($1, $2) }
+  ;
+
 jsonExpressionParser:
     JSON labelExtractionExpressionList { $$ = newJSONExpressionParser($2) }
 
@@ -334,6 +354,9 @@ distinctLabel:
 distinctFilter:
       DISTINCT distinctLabel { $$ = newDistinctFilterExpr($2) };
 
+sampleFilter:
+      SAMPLE NUMBER { $$ = newSampleFilterExpr($2) };
+
 labelFilter:
       matcher                                        { $$ = log.NewStringLabelFilter($1) }
     | ipLabelFilter                                  { $$ = $1 }
@@ -347,7 +370,8 @@ labelFilter:
     ;
 
 labelExtractionExpression:
-    IDENTIFIER EQ STRING { $$ = log.NewLabelExtractionExpr($1, $3) }
+    IDENTIFIER EQ STRING     { $$ = log.NewLabelExtractionExpr($1, $3) }
+  | IDENTIFIER EQ IDENTIFIER { $$ = log.NewLabelExtractionExpr($1, $3) }
   | IDENTIFIER           { $$ = log.NewLabelExtractionExpr($1, $1) }
 
 labelExtractionExpressionList:
@@ -528,6 +552,7 @@ vectorOp:
       | STDVAR  { $$ = OpTypeStdvar }
       | BOTTOMK { $$ = OpTypeBottomK }
       | TOPK    { $$ = OpTypeTopK }
+      | APPROX_TOPK { $$ = OpTypeApproxTopK }
       | SORT    { $$ = OpTypeSort }
       | SORT_DESC    { $$ = OpTypeSortDesc }
       ;
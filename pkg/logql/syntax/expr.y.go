@@ -1,9 +1,11 @@
 // Code generated by goyacc -p expr -o pkg/logql/syntax/expr.y.go pkg/logql/syntax/expr.y. DO NOT EDIT.
 
+//line pkg/logql/syntax/expr.y:2
 package syntax
 
 import __yyfmt__ "fmt"
 
+//line pkg/logql/syntax/expr.y:2
 
 import (
 	"github.com/grafana/loki/pkg/logql/log"
@@ -11,6 +13,7 @@ import (
 	"time"
 )
 
+//line pkg/logql/syntax/expr.y:12
 type exprSymType struct {
 	yys                   int
 	Expr                  Expr
@@ -37,15 +40,18 @@ type exprSymType struct {
 	bytes                 uint64
 	str                   string
 	duration              time.Duration
+	subqueryRange         subqueryRange
 	LiteralExpr           *LiteralExpr
 	BinOpModifier         *BinOpOptions
 	BoolModifier          *BinOpOptions
 	OnOrIgnoringModifier  *BinOpOptions
 	LabelParser           *LabelParserExpr
+	ParserFlags           []string
 	LineFilters           *LineFilterExpr
 	LineFilter            *LineFilterExpr
 	DistinctLabel         []string
 	DistinctFilter        *DistinctFilterExpr
+	SampleFilter          *SampleFilterExpr
 	PipelineExpr          MultiStageExpr
 	PipelineStage         StageExpr
 	BytesFilter           log.LabelFilterer
@@ -79,91 +85,97 @@ const BYTES = 57346
 const IDENTIFIER = 57347
 const STRING = 57348
 const NUMBER = 57349
-const DURATION = 57350
-const RANGE = 57351
-const MATCHERS = 57352
-const LABELS = 57353
-const EQ = 57354
-const RE = 57355
-const NRE = 57356
-const OPEN_BRACE = 57357
-const CLOSE_BRACE = 57358
-const OPEN_BRACKET = 57359
-const CLOSE_BRACKET = 57360
-const COMMA = 57361
-const DOT = 57362
-const PIPE_MATCH = 57363
-const PIPE_EXACT = 57364
-const OPEN_PARENTHESIS = 57365
-const CLOSE_PARENTHESIS = 57366
-const BY = 57367
-const WITHOUT = 57368
-const COUNT_OVER_TIME = 57369
-const RATE = 57370
-const RATE_COUNTER = 57371
-const SUM = 57372
-const SORT = 57373
-const SORT_DESC = 57374
-const AVG = 57375
-const MAX = 57376
-const MIN = 57377
-const COUNT = 57378
-const STDDEV = 57379
-const STDVAR = 57380
-const BOTTOMK = 57381
-const TOPK = 57382
-const BYTES_OVER_TIME = 57383
-const BYTES_RATE = 57384
-const BOOL = 57385
-const JSON = 57386
-const DISTINCT = 57387
-const REGEXP = 57388
-const LOGFMT = 57389
-const PIPE = 57390
-const LINE_FMT = 57391
-const LABEL_FMT = 57392
-const UNWRAP = 57393
-const AVG_OVER_TIME = 57394
-const SUM_OVER_TIME = 57395
-const MIN_OVER_TIME = 57396
-const MAX_OVER_TIME = 57397
-const STDVAR_OVER_TIME = 57398
-const STDDEV_OVER_TIME = 57399
-const QUANTILE_OVER_TIME = 57400
-const BYTES_CONV = 57401
-const DURATION_CONV = 57402
-const DURATION_SECONDS_CONV = 57403
-const FIRST_OVER_TIME = 57404
-const LAST_OVER_TIME = 57405
-const ABSENT_OVER_TIME = 57406
-const VECTOR = 57407
-const LABEL_REPLACE = 57408
-const UNPACK = 57409
-const OFFSET = 57410
-const PATTERN = 57411
-const IP = 57412
-const ON = 57413
-const IGNORING = 57414
-const GROUP_LEFT = 57415
-const GROUP_RIGHT = 57416
-const DECOLORIZE = 57417
-const DROP = 57418
-const KEEP = 57419
-const OR = 57420
-const AND = 57421
-const UNLESS = 57422
-const CMP_EQ = 57423
-const NEQ = 57424
-const LT = 57425
-const LTE = 57426
-const GT = 57427
-const GTE = 57428
-const ADD = 57429
-const SUB = 57430
-const MUL = 57431
-const DIV = 57432
-const MOD = 57433
-const POW = 57434
+const PARSER_FLAG = 57350
+const DURATION = 57351
+const RANGE = 57352
+const SUBQUERY_RANGE = 57353
+const MATCHERS = 57354
+const LABELS = 57355
+const EQ = 57356
+const RE = 57357
+const NRE = 57358
+const OPEN_BRACE = 57359
+const CLOSE_BRACE = 57360
+const OPEN_BRACKET = 57361
+const CLOSE_BRACKET = 57362
+const COMMA = 57363
+const DOT = 57364
+const PIPE_MATCH = 57365
+const PIPE_EXACT = 57366
+const OPEN_PARENTHESIS = 57367
+const CLOSE_PARENTHESIS = 57368
+const BY = 57369
+const WITHOUT = 57370
+const COUNT_OVER_TIME = 57371
+const RATE = 57372
+const RATE_COUNTER = 57373
+const SUM = 57374
+const SORT = 57375
+const SORT_DESC = 57376
+const AVG = 57377
+const MAX = 57378
+const MIN = 57379
+const COUNT = 57380
+const STDDEV = 57381
+const STDVAR = 57382
+const BOTTOMK = 57383
+const TOPK = 57384
+const APPROX_TOPK = 57385
+const BYTES_OVER_TIME = 57386
+const BYTES_RATE = 57387
+const BOOL = 57388
+const JSON = 57389
+const DISTINCT = 57390
+const REGEXP = 57391
+const LOGFMT = 57392
+const PIPE = 57393
+const LINE_FMT = 57394
+const LABEL_FMT = 57395
+const UNWRAP = 57396
+const AVG_OVER_TIME = 57397
+const SUM_OVER_TIME = 57398
+const MIN_OVER_TIME = 57399
+const MAX_OVER_TIME = 57400
+const STDVAR_OVER_TIME = 57401
+const STDDEV_OVER_TIME = 57402
+const QUANTILE_OVER_TIME = 57403
+const BYTES_CONV = 57404
+const BYTES_INT_CONV = 57405
+const DURATION_CONV = 57406
+const DURATION_SECONDS_CONV = 57407
+const TO_FLOAT_CONV = 57408
+const FIRST_OVER_TIME = 57409
+const LAST_OVER_TIME = 57410
+const ABSENT_OVER_TIME = 57411
+const VECTOR = 57412
+const LABEL_REPLACE = 57413
+const UNPACK = 57414
+const OFFSET = 57415
+const PATTERN = 57416
+const IP = 57417
+const ON = 57418
+const IGNORING = 57419
+const GROUP_LEFT = 57420
+const GROUP_RIGHT = 57421
+const DECOLORIZE = 57422
+const DROP = 57423
+const KEEP = 57424
+const SAMPLE = 57425
+const OR = 57426
+const AND = 57427
+const UNLESS = 57428
+const CMP_EQ = 57429
+const NEQ = 57430
+const LT = 57431
+const LTE = 57432
+const GT = 57433
+const GTE = 57434
+const ADD = 57435
+const SUB = 57436
+const MUL = 57437
+const DIV = 57438
+const MOD = 57439
+const POW = 57440
 
 var exprToknames = [...]string{
 	"$end",
@@ -173,8 +185,10 @@ var exprToknames = [...]string{
 	"IDENTIFIER",
 	"STRING",
 	"NUMBER",
+	"PARSER_FLAG",
 	"DURATION",
 	"RANGE",
+	"SUBQUERY_RANGE",
 	"MATCHERS",
 	"LABELS",
 	"EQ",
@@ -206,6 +220,7 @@ var exprToknames = [...]string{
 	"STDVAR",
 	"BOTTOMK",
 	"TOPK",
+	"APPROX_TOPK",
 	"BYTES_OVER_TIME",
 	"BYTES_RATE",
 	"BOOL",
@@ -225,8 +240,10 @@ var exprToknames = [...]string{
 	"STDDEV_OVER_TIME",
 	"QUANTILE_OVER_TIME",
 	"BYTES_CONV",
+	"BYTES_INT_CONV",
 	"DURATION_CONV",
 	"DURATION_SECONDS_CONV",
+	"TO_FLOAT_CONV",
 	"FIRST_OVER_TIME",
 	"LAST_OVER_TIME",
 	"ABSENT_OVER_TIME",
@@ -243,6 +260,7 @@ var exprToknames = [...]string{
 	"DECOLORIZE",
 	"DROP",
 	"KEEP",
+	"SAMPLE",
 	"OR",
 	"AND",
 	"UNLESS",
@@ -259,14 +277,17 @@ var exprToknames = [...]string{
 	"MOD",
 	"POW",
 }
+
 var exprStatenames = [...]string{}
 
 const exprEofCode = 1
 const exprErrCode = 2
 const exprInitialStackSize = 16
 
+//line pkg/logql/syntax/expr.y:592
 
-var exprExca = [...]int{
+//line yacctab:1
+var exprExca = [...]int8{
 	-1, 1,
 	1, -1,
 	-2, 0,
@@ -274,259 +295,276 @@ var exprExca = [...]int{
 
 const exprPrivate = 57344
 
-const exprLast = 580
-
-var exprAct = [...]int{
-
-	279, 222, 82, 4, 124, 64, 180, 200, 193, 196,
-	73, 231, 185, 63, 56, 5, 282, 150, 78, 75,
-	2, 184, 51, 52, 53, 54, 55, 56, 164, 165,
-	354, 16, 53, 54, 55, 56, 162, 163, 287, 13,
-	146, 148, 149, 284, 328, 354, 92, 6, 83, 84,
-	282, 21, 22, 23, 36, 45, 46, 37, 39, 40,
-	38, 41, 42, 43, 44, 24, 25, 374, 259, 107,
-	215, 260, 258, 112, 329, 233, 26, 27, 28, 29,
-	30, 31, 32, 284, 138, 154, 33, 34, 35, 47,
-	19, 159, 67, 283, 306, 369, 152, 57, 58, 61,
-	62, 59, 60, 51, 52, 53, 54, 55, 56, 161,
-	147, 17, 18, 166, 167, 168, 169, 170, 171, 172,
-	173, 174, 175, 176, 177, 178, 179, 362, 331, 332,
-	333, 135, 284, 135, 257, 190, 233, 198, 202, 205,
-	148, 149, 187, 135, 361, 182, 71, 182, 140, 128,
-	213, 128, 247, 69, 70, 304, 351, 182, 108, 359,
-	229, 128, 81, 296, 83, 84, 223, 338, 345, 225,
-	234, 226, 48, 49, 50, 57, 58, 61, 62, 59,
-	60, 51, 52, 53, 54, 55, 56, 319, 242, 243,
-	244, 49, 50, 57, 58, 61, 62, 59, 60, 51,
-	52, 53, 54, 55, 56, 181, 183, 181, 211, 206,
-	209, 210, 207, 208, 72, 296, 183, 181, 233, 296,
-	344, 296, 277, 280, 343, 286, 342, 289, 285, 107,
-	292, 112, 293, 71, 294, 281, 152, 303, 278, 290,
-	69, 70, 255, 336, 214, 256, 254, 218, 237, 328,
-	300, 302, 305, 307, 135, 198, 202, 310, 308, 315,
-	314, 285, 318, 233, 357, 283, 71, 224, 182, 320,
-	296, 71, 128, 69, 70, 298, 135, 135, 69, 70,
-	335, 321, 301, 323, 325, 296, 327, 107, 284, 218,
-	297, 326, 337, 322, 128, 128, 107, 227, 13, 339,
-	224, 72, 142, 141, 284, 224, 153, 317, 253, 241,
-	372, 291, 233, 233, 221, 119, 134, 121, 120, 71,
-	129, 131, 287, 348, 349, 282, 69, 70, 107, 350,
-	218, 235, 232, 240, 72, 352, 353, 239, 122, 72,
-	123, 358, 238, 151, 212, 158, 130, 132, 133, 157,
-	230, 13, 219, 224, 364, 156, 365, 366, 13, 153,
-	88, 87, 80, 368, 341, 295, 6, 252, 370, 251,
-	21, 22, 23, 36, 45, 46, 37, 39, 40, 38,
-	41, 42, 43, 44, 24, 25, 250, 72, 248, 245,
-	236, 228, 220, 135, 155, 26, 27, 28, 29, 30,
-	31, 32, 13, 249, 246, 33, 34, 35, 47, 19,
-	6, 128, 367, 356, 21, 22, 23, 36, 45, 46,
-	37, 39, 40, 38, 41, 42, 43, 44, 24, 25,
-	17, 18, 119, 134, 121, 120, 79, 129, 131, 26,
-	27, 28, 29, 30, 31, 32, 355, 77, 334, 33,
-	34, 35, 47, 19, 221, 122, 324, 123, 89, 71,
-	312, 313, 363, 130, 132, 133, 69, 70, 144, 288,
-	71, 160, 71, 373, 17, 18, 86, 69, 70, 69,
-	70, 85, 143, 274, 371, 145, 275, 273, 271, 360,
-	340, 272, 270, 224, 268, 3, 265, 269, 267, 266,
-	264, 347, 74, 346, 224, 309, 66, 299, 93, 94,
-	95, 96, 97, 98, 99, 100, 101, 102, 103, 104,
-	105, 106, 262, 276, 316, 263, 261, 72, 311, 217,
-	216, 194, 125, 215, 214, 191, 189, 188, 72, 201,
-	72, 197, 186, 79, 204, 194, 126, 110, 111, 192,
-	115, 199, 117, 195, 116, 114, 113, 203, 118, 65,
-	136, 127, 137, 109, 91, 90, 11, 10, 9, 139,
-	20, 12, 15, 8, 330, 14, 7, 76, 68, 1,
+const exprLast = 674
+
+var exprAct = [...]int16{
+	290, 4, 231, 83, 65, 126, 206, 184, 74, 202,
+	199, 239, 64, 5, 153, 3, 189, 76, 2, 79,
+	188, 57, 75, 49, 50, 51, 58, 59, 62, 63,
+	60, 61, 52, 53, 54, 55, 56, 57, 50, 51,
+	58, 59, 62, 63, 60, 61, 52, 53, 54, 55,
+	56, 57, 58, 59, 62, 63, 60, 61, 52, 53,
+	54, 55, 56, 57, 54, 55, 56, 57, 141, 108,
+	291, 212, 151, 152, 299, 113, 52, 53, 54, 55,
+	56, 57, 366, 155, 158, 292, 149, 151, 152, 375,
+	163, 72, 168, 169, 68, 156, 72, 298, 70, 71,
+	375, 351, 397, 70, 71, 166, 167, 93, 165, 289,
+	395, 291, 170, 171, 172, 173, 174, 175, 176, 177,
+	178, 179, 180, 181, 182, 183, 232, 84, 85, 291,
+	382, 232, 381, 138, 390, 138, 380, 143, 196, 389,
+	204, 208, 338, 192, 218, 213, 216, 217, 214, 215,
+	72, 186, 378, 130, 220, 130, 291, 70, 71, 74,
+	150, 109, 237, 73, 82, 297, 84, 85, 73, 229,
+	355, 233, 234, 75, 242, 121, 136, 123, 122, 72,
+	131, 133, 299, 298, 138, 230, 70, 71, 335, 394,
+	332, 72, 308, 250, 251, 252, 138, 362, 70, 71,
+	124, 301, 125, 338, 130, 138, 298, 306, 132, 134,
+	135, 137, 186, 308, 67, 185, 130, 256, 361, 372,
+	72, 225, 73, 245, 235, 130, 232, 70, 71, 288,
+	286, 294, 293, 295, 108, 241, 302, 145, 305, 304,
+	113, 156, 287, 296, 298, 333, 300, 121, 136, 123,
+	122, 73, 131, 133, 144, 232, 318, 241, 312, 314,
+	317, 319, 344, 73, 225, 204, 208, 328, 323, 327,
+	320, 225, 124, 292, 125, 187, 185, 291, 316, 72,
+	132, 134, 135, 137, 138, 308, 70, 71, 303, 308,
+	360, 241, 73, 337, 359, 226, 241, 339, 342, 341,
+	186, 108, 370, 352, 130, 108, 343, 340, 268, 356,
+	222, 269, 315, 267, 232, 241, 297, 313, 138, 346,
+	347, 348, 349, 350, 308, 241, 308, 230, 388, 310,
+	358, 309, 354, 72, 186, 367, 243, 365, 130, 368,
+	70, 71, 307, 369, 331, 108, 240, 330, 249, 248,
+	264, 73, 221, 265, 373, 263, 374, 298, 247, 377,
+	246, 219, 162, 187, 185, 16, 161, 160, 232, 89,
+	88, 261, 384, 81, 260, 13, 386, 387, 259, 266,
+	257, 253, 244, 6, 236, 227, 391, 21, 22, 23,
+	36, 46, 47, 37, 39, 40, 38, 41, 42, 43,
+	44, 45, 24, 25, 147, 73, 258, 254, 334, 228,
+	385, 80, 16, 26, 27, 28, 29, 30, 31, 32,
+	146, 262, 13, 148, 78, 33, 34, 35, 48, 19,
+	157, 376, 371, 353, 21, 22, 23, 36, 46, 47,
+	37, 39, 40, 38, 41, 42, 43, 44, 45, 24,
+	25, 17, 18, 283, 336, 255, 284, 211, 282, 238,
+	26, 27, 28, 29, 30, 31, 32, 190, 164, 13,
+	193, 87, 33, 34, 35, 48, 19, 6, 325, 326,
+	396, 21, 22, 23, 36, 46, 47, 37, 39, 40,
+	38, 41, 42, 43, 44, 45, 24, 25, 17, 18,
+	280, 86, 383, 281, 393, 279, 159, 26, 27, 28,
+	29, 30, 31, 32, 322, 321, 13, 392, 379, 33,
+	34, 35, 48, 19, 6, 364, 363, 311, 21, 22,
+	23, 36, 46, 47, 37, 39, 40, 38, 41, 42,
+	43, 44, 45, 24, 25, 17, 18, 277, 285, 357,
+	278, 224, 276, 154, 26, 27, 28, 29, 30, 31,
+	32, 324, 223, 13, 200, 329, 33, 34, 35, 48,
+	19, 157, 90, 222, 221, 21, 22, 23, 36, 46,
+	47, 37, 39, 40, 38, 41, 42, 43, 44, 45,
+	24, 25, 17, 18, 274, 271, 127, 275, 272, 273,
+	270, 26, 27, 28, 29, 30, 31, 32, 197, 195,
+	194, 207, 203, 33, 34, 35, 48, 19, 190, 80,
+	210, 200, 128, 94, 95, 96, 97, 98, 99, 100,
+	101, 102, 103, 104, 105, 106, 107, 191, 111, 17,
+	18, 112, 198, 116, 205, 118, 201, 117, 115, 114,
+	120, 209, 119, 66, 139, 129, 140, 110, 92, 91,
+	11, 10, 9, 142, 20, 12, 15, 8, 345, 14,
+	7, 77, 69, 1,
 }
-var exprPact = [...]int{
 
-	24, -1000, 94, -1000, -1000, 458, 24, -1000, -1000, -1000,
-	-1000, -1000, -1000, 431, 339, 139, -1000, 474, 469, 338,
-	337, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
+var exprPact = [...]int16{
+	358, -1000, -61, -1000, -1000, 163, 358, -1000, -1000, -1000,
+	-1000, -1000, -1000, 406, 348, 139, -1000, 494, 464, 345,
+	344, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
 	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 3, 3,
-	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 3, 458, -1000, 132, 388, -1000, 78, -1000,
-	-1000, -1000, -1000, 279, 278, 94, 466, -1000, -1000, 28,
-	336, 387, 332, 326, 322, -1000, -1000, 24, 464, 24,
-	-35, -45, -1000, 24, 24, 24, 24, 24, 24, 24,
-	24, 24, 24, 24, 24, 24, 24, -1000, -1000, -1000,
-	-1000, -1000, 138, -1000, -1000, -1000, -1000, -1000, -1000, 537,
-	537, 531, -1000, 530, -1000, -1000, -1000, -1000, 272, 529,
-	-1000, 540, 536, 534, 539, 127, -1000, -1000, -1000, 321,
-	-1000, -1000, -1000, -1000, -1000, 538, 528, 527, 524, 523,
-	328, 373, 305, 283, 273, 372, 343, 308, 307, 371,
-	224, 112, 319, 314, 310, 286, 16, 16, -57, -57,
-	-78, -78, -78, -78, -65, -65, -65, -65, -65, -65,
-	138, 272, 272, 272, 370, -1000, 392, 370, -1000, -1000,
-	128, -1000, 369, -1000, 391, 367, -1000, 28, -1000, 350,
-	-1000, 28, -1000, 348, -1000, 238, 64, 518, 492, 490,
-	484, 479, 517, -1000, -1000, -1000, -1000, -1000, -1000, 23,
-	283, 257, 84, 252, 271, 445, 287, 23, 24, 210,
-	346, 266, -1000, -1000, 251, -1000, 501, -1000, 258, 213,
-	131, 70, 249, 138, 126, 537, 499, -1000, 526, 455,
-	536, 534, 519, 284, -1000, -1000, -1000, 239, -1000, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 61,
+	61, 61, 61, 61, 61, 61, 61, 61, 61, 61,
+	61, 61, 61, 61, 163, -1000, 134, 200, -1000, 62,
+	-1000, -1000, -1000, -1000, 228, 211, -61, 402, -1000, -1000,
+	72, 546, 499, 342, 341, 337, -1000, -1000, 358, 461,
+	358, 29, 14, -1000, 358, 358, 358, 358, 358, 358,
+	358, 358, 358, 358, 358, 358, 358, 358, -1000, -1000,
+	-1000, -1000, -1000, 279, -1000, -1000, -1000, -1000, -1000, -1000,
+	-1000, 613, 462, 604, -1000, 603, -1000, -1000, -1000, -1000,
+	179, 602, -1000, 616, 607, 606, 615, 450, 57, -1000,
+	-1000, -1000, 336, -1000, -1000, -1000, -1000, -1000, 614, 568,
+	567, 556, 545, 269, 364, 398, 317, 405, 198, 363,
+	452, 320, 310, 361, 197, -47, 335, 333, 324, 323,
+	-35, -35, -31, -31, -77, -77, -77, -77, -17, -17,
+	-17, -17, -17, -17, 279, 179, 179, 179, 360, -1000,
+	393, 447, 360, -1000, -1000, -1000, 191, -1000, 359, -1000,
+	392, 357, -1000, 72, -1000, 353, -1000, 72, -1000, 350,
+	-1000, -1000, 346, 304, 591, 590, 543, 496, 449, 542,
+	-1000, -1000, -1000, -1000, -1000, -1000, 100, 405, 83, 263,
+	204, 155, 128, 175, 262, 100, 358, 181, 321, 305,
+	-1000, -1000, 303, -1000, 521, -1000, 291, 286, 252, 230,
+	313, 279, 130, 613, 509, -1000, -1000, 559, 473, 607,
+	606, 560, 322, -1000, -1000, -1000, 319, -1000, -1000, -1000,
 	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, 163, -1000, 245, 456,
-	-5, 456, 448, -52, 272, -52, 35, 69, 439, 256,
-	219, -1000, -1000, 143, -1000, 24, 485, -1000, -1000, 345,
-	202, -1000, 200, -1000, -1000, 196, -1000, 144, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, -1000, 497, 495, -1000,
-	23, -5, 456, -5, -1000, -1000, 138, -1000, -52, -1000,
-	133, -1000, -1000, -1000, -18, 437, 404, 240, 23, 135,
-	-1000, 483, -1000, -1000, -1000, -1000, 120, 103, -1000, -5,
-	-1000, 457, -3, -5, -13, -52, -52, 403, -1000, -1000,
-	344, -1000, -1000, 71, -5, -1000, -1000, -52, 478, -1000,
-	-1000, 291, 467, 43, -1000,
+	-1000, -1000, -1000, -1000, -1000, 164, -1000, 219, 397, -1000,
+	162, 445, -3, 132, 80, 46, 80, -3, 179, 257,
+	75, 423, 306, -1000, -1000, 144, -1000, 358, 544, -1000,
+	-1000, 309, 268, -1000, 264, -1000, -1000, 192, -1000, 171,
+	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
+	520, 519, -1000, 100, 56, -1000, -1000, -1000, -3, 46,
+	80, 46, -1000, 279, -1000, 277, -1000, -1000, -1000, -1000,
+	-1000, 422, 193, 38, 421, 100, 126, -1000, 512, -1000,
+	-1000, -1000, -1000, 110, 106, -1000, -1000, 104, -1000, 46,
+	497, -3, 400, 49, 46, 20, -3, -1000, -1000, 307,
+	-1000, -1000, -1000, 113, -1000, -3, 46, -1000, 511, -1000,
+	498, -1000, 168, 84, 474, -1000, 76, -1000,
 }
-var exprPgo = [...]int{
-
-	0, 579, 19, 578, 2, 11, 495, 3, 17, 4,
-	577, 576, 575, 574, 15, 573, 572, 571, 570, 569,
-	568, 567, 566, 458, 565, 564, 563, 13, 5, 562,
-	561, 560, 6, 559, 92, 558, 557, 556, 555, 554,
-	553, 9, 552, 551, 7, 550, 8, 549, 12, 21,
-	548, 547, 1, 546, 532, 0,
+
+var exprPgo = [...]int16{
+	0, 673, 17, 672, 3, 11, 15, 1, 14, 5,
+	671, 670, 669, 668, 13, 667, 666, 665, 664, 663,
+	662, 661, 660, 572, 659, 658, 657, 12, 4, 656,
+	655, 654, 7, 653, 94, 652, 651, 650, 649, 648,
+	647, 646, 9, 645, 644, 6, 643, 10, 642, 16,
+	20, 641, 638, 637, 2, 622, 596, 0,
 }
-var exprR1 = [...]int{
 
+var exprR1 = [...]int8{
 	0, 1, 2, 2, 7, 7, 7, 7, 7, 7,
 	7, 6, 6, 6, 8, 8, 8, 8, 8, 8,
 	8, 8, 8, 8, 8, 8, 8, 8, 8, 8,
 	8, 8, 8, 8, 8, 8, 8, 8, 8, 8,
-	52, 52, 52, 13, 13, 13, 11, 11, 11, 11,
-	15, 15, 15, 15, 15, 15, 22, 3, 3, 3,
-	3, 14, 14, 14, 10, 10, 9, 9, 9, 9,
-	27, 27, 28, 28, 28, 28, 28, 28, 28, 28,
-	28, 28, 28, 19, 34, 34, 33, 33, 26, 26,
-	26, 26, 26, 51, 50, 37, 38, 46, 46, 47,
-	47, 47, 45, 36, 36, 35, 32, 32, 32, 32,
-	32, 32, 32, 32, 32, 48, 48, 49, 49, 54,
-	54, 53, 53, 31, 31, 31, 31, 31, 31, 31,
-	29, 29, 29, 29, 29, 29, 29, 30, 30, 30,
-	30, 30, 30, 30, 41, 41, 40, 40, 39, 44,
-	44, 43, 43, 42, 20, 20, 20, 20, 20, 20,
-	20, 20, 20, 20, 20, 20, 20, 20, 20, 24,
-	24, 25, 25, 25, 25, 23, 23, 23, 23, 23,
-	23, 23, 23, 21, 21, 21, 17, 18, 16, 16,
-	16, 16, 16, 16, 16, 16, 16, 16, 16, 12,
-	12, 12, 12, 12, 12, 12, 12, 12, 12, 12,
-	12, 12, 12, 12, 55, 5, 5, 4, 4, 4,
-	4,
+	54, 54, 54, 54, 13, 13, 13, 13, 13, 11,
+	11, 11, 11, 11, 11, 11, 11, 15, 15, 15,
+	15, 15, 15, 22, 3, 3, 3, 3, 14, 14,
+	14, 10, 10, 9, 9, 9, 9, 27, 27, 28,
+	28, 28, 28, 28, 28, 28, 28, 28, 28, 28,
+	28, 19, 34, 34, 33, 33, 26, 26, 26, 26,
+	26, 26, 53, 53, 52, 51, 38, 39, 47, 47,
+	48, 48, 48, 46, 36, 36, 35, 37, 32, 32,
+	32, 32, 32, 32, 32, 32, 32, 49, 49, 49,
+	50, 50, 56, 56, 55, 55, 31, 31, 31, 31,
+	31, 31, 31, 29, 29, 29, 29, 29, 29, 29,
+	30, 30, 30, 30, 30, 30, 30, 42, 42, 41,
+	41, 40, 45, 45, 44, 44, 43, 20, 20, 20,
+	20, 20, 20, 20, 20, 20, 20, 20, 20, 20,
+	20, 20, 24, 24, 25, 25, 25, 25, 23, 23,
+	23, 23, 23, 23, 23, 23, 21, 21, 21, 17,
+	18, 16, 16, 16, 16, 16, 16, 16, 16, 16,
+	16, 16, 16, 12, 12, 12, 12, 12, 12, 12,
+	12, 12, 12, 12, 12, 12, 12, 12, 57, 5,
+	5, 4, 4, 4, 4,
 }
-var exprR2 = [...]int{
 
+var exprR2 = [...]int8{
 	0, 1, 1, 1, 1, 1, 1, 1, 1, 1,
 	3, 1, 2, 3, 2, 3, 4, 5, 3, 4,
 	5, 6, 3, 4, 5, 6, 3, 4, 5, 6,
 	4, 5, 6, 7, 3, 4, 4, 5, 3, 2,
-	3, 6, 3, 1, 1, 1, 4, 6, 5, 7,
-	4, 5, 5, 6, 7, 7, 12, 1, 1, 1,
-	1, 3, 3, 2, 1, 3, 3, 3, 3, 3,
-	1, 2, 1, 2, 2, 2, 2, 2, 2, 2,
-	2, 2, 2, 1, 2, 5, 1, 2, 1, 1,
-	2, 1, 2, 2, 2, 2, 1, 3, 3, 1,
-	3, 3, 2, 1, 3, 2, 1, 1, 1, 1,
-	3, 2, 3, 3, 3, 3, 1, 1, 3, 6,
-	6, 1, 1, 3, 3, 3, 3, 3, 3, 3,
+	3, 6, 8, 3, 1, 1, 1, 1, 1, 4,
+	6, 5, 7, 5, 6, 7, 8, 4, 5, 5,
+	6, 7, 7, 12, 1, 1, 1, 1, 3, 3,
+	2, 1, 3, 3, 3, 3, 3, 1, 2, 1,
+	2, 2, 2, 2, 2, 2, 2, 2, 2, 2,
+	2, 1, 2, 5, 1, 2, 1, 1, 2, 2,
+	1, 2, 1, 2, 2, 2, 2, 1, 3, 3,
+	1, 3, 3, 2, 1, 3, 2, 2, 1, 1,
+	1, 1, 3, 2, 3, 3, 3, 3, 3, 1,
+	1, 3, 6, 6, 1, 1, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 3, 3, 1, 1, 1, 3, 2, 1,
-	1, 1, 3, 2, 4, 4, 4, 4, 4, 4,
-	4, 4, 4, 4, 4, 4, 4, 4, 4, 0,
-	1, 5, 4, 5, 4, 1, 1, 2, 4, 5,
-	2, 4, 5, 1, 2, 2, 4, 1, 1, 1,
+	3, 3, 3, 3, 3, 3, 3, 1, 1, 1,
+	3, 2, 1, 1, 1, 3, 2, 4, 4, 4,
+	4, 4, 4, 4, 4, 4, 4, 4, 4, 4,
+	4, 4, 0, 1, 5, 4, 5, 4, 1, 1,
+	2, 4, 5, 2, 4, 5, 1, 2, 2, 4,
 	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
 	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
-	1, 1, 1, 1, 2, 1, 3, 4, 4, 3,
-	3,
+	1, 1, 1, 1, 1, 1, 1, 1, 2, 1,
+	3, 4, 4, 3, 3,
 }
-var exprChk = [...]int{
-
-	-1000, -1, -2, -6, -7, -14, 23, -11, -15, -20,
-	-21, -22, -17, 15, -12, -16, 7, 87, 88, 66,
-	-18, 27, 28, 29, 41, 42, 52, 53, 54, 55,
-	56, 57, 58, 62, 63, 64, 30, 33, 36, 34,
-	35, 37, 38, 39, 40, 31, 32, 65, 78, 79,
-	80, 87, 88, 89, 90, 91, 92, 81, 82, 85,
-	86, 83, 84, -27, -28, -33, 48, -34, -3, 21,
-	22, 14, 82, -7, -6, -2, -10, 16, -9, 5,
-	23, 23, -4, 25, 26, 7, 7, 23, 23, -23,
-	-24, -25, 43, -23, -23, -23, -23, -23, -23, -23,
-	-23, -23, -23, -23, -23, -23, -23, -28, -34, -26,
-	-51, -50, -32, -37, -38, -45, -39, -42, -35, 44,
-	47, 46, 67, 69, -9, -54, -53, -30, 23, 49,
-	75, 50, 76, 77, 45, 5, -31, -29, 6, -19,
-	70, 24, 24, 16, 2, 19, 12, 82, 13, 14,
-	-8, 7, -14, 23, -7, 7, 23, 23, 23, -7,
-	7, -2, 71, 72, 73, 74, -2, -2, -2, -2,
+
+var exprChk = [...]int16{
+	-1000, -1, -2, -6, -7, -14, 25, -11, -15, -20,
+	-21, -22, -17, 17, -12, -16, 7, 93, 94, 71,
+	-18, 29, 30, 31, 44, 45, 55, 56, 57, 58,
+	59, 60, 61, 67, 68, 69, 32, 35, 38, 36,
+	37, 39, 40, 41, 42, 43, 33, 34, 70, 84,
+	85, 86, 93, 94, 95, 96, 97, 98, 87, 88,
+	91, 92, 89, 90, -27, -28, -33, 51, -34, -3,
+	23, 24, 16, 88, -7, -6, -2, -10, 18, -9,
+	5, 25, 25, -4, 27, 28, 7, 7, 25, 25,
+	-23, -24, -25, 46, -23, -23, -23, -23, -23, -23,
+	-23, -23, -23, -23, -23, -23, -23, -23, -28, -34,
+	-26, -52, -51, -32, -38, -39, -46, -40, -43, -35,
+	-37, 47, 50, 49, 72, 74, -9, -56, -55, -30,
+	25, 52, 80, 53, 81, 82, 48, 83, 5, -31,
+	-29, 6, -19, 75, 26, 26, 18, 2, 21, 14,
+	88, 15, 16, -8, 7, -7, -14, 25, -7, 7,
+	25, 25, 25, -7, 7, -2, 76, 77, 78, 79,
 	-2, -2, -2, -2, -2, -2, -2, -2, -2, -2,
-	-32, 79, 19, 78, -49, -48, 5, -49, 6, 6,
-	-32, 6, -47, -46, 5, -40, -41, 5, -9, -43,
-	-44, 5, -9, -36, 5, 12, 82, 85, 86, 83,
-	84, 81, 23, -9, 6, 6, 6, 6, 2, 24,
-	19, 9, -52, -27, 48, -14, -8, 24, 19, -7,
-	7, -5, 24, 5, -5, 24, 19, 24, 23, 23,
-	23, 23, -32, -32, -32, 19, 12, 24, 19, 12,
-	19, 19, 19, 70, 8, 4, 7, 70, 8, 4,
-	7, 8, 4, 7, 8, 4, 7, 8, 4, 7,
-	8, 4, 7, 8, 4, 7, 6, -4, -8, -55,
-	-52, -27, 68, 9, 48, 9, -52, 51, 24, -52,
-	-27, 24, -4, -7, 24, 19, 19, 24, 24, 6,
-	-5, 24, -5, 24, 24, -5, 24, -5, -48, 6,
-	-46, 2, 5, 6, -41, -44, 5, 23, 23, 24,
-	24, -52, -27, -52, 8, -55, -32, -55, 9, 5,
-	-13, 59, 60, 61, 9, 24, 24, -52, 24, -7,
-	5, 19, 24, 24, 24, 24, 6, 6, -4, -52,
-	-55, 23, -55, -52, 48, 9, 9, 24, -4, 24,
-	6, 24, 24, 5, -52, -55, -55, 9, 19, 24,
-	-55, 6, 19, 6, 24,
+	-2, -2, -2, -2, -32, 85, 21, 84, -50, -49,
+	5, -53, -50, 8, 6, 6, -32, 6, -48, -47,
+	5, -41, -42, 5, -9, -44, -45, 5, -9, -36,
+	5, 7, 14, 88, 91, 92, 89, 90, 87, 25,
+	-9, 6, 6, 6, 6, 2, 26, 21, 11, -27,
+	10, -54, 51, -14, -8, 26, 21, -7, 7, -5,
+	26, 5, -5, 26, 21, 26, 25, 25, 25, 25,
+	-32, -32, -32, 21, 14, 8, 26, 21, 14, 21,
+	21, 21, 75, 9, 4, 7, 75, 9, 4, 7,
+	9, 4, 7, 9, 4, 7, 9, 4, 7, 9,
+	4, 7, 9, 4, 7, 6, -4, -8, -7, 26,
+	-57, 73, 10, -54, -57, -54, -27, 10, 51, 54,
+	-27, 26, -54, 26, -4, -7, 26, 21, 21, 26,
+	26, 6, -5, 26, -5, 26, 26, -5, 26, -5,
+	-49, 6, 5, -47, 2, 5, 6, -42, -45, 5,
+	25, 25, 26, 26, 11, 26, 9, -57, 10, -54,
+	-27, -54, -57, -32, 5, -13, 62, 63, 64, 65,
+	66, 26, -54, 10, 26, 26, -7, 5, 21, 26,
+	26, 26, 26, 6, 6, -4, 26, -57, -57, -54,
+	25, 10, 26, -57, -54, 51, 10, -4, 26, 6,
+	26, 26, 26, 5, -57, 10, -54, -57, 21, 26,
+	21, -57, 6, 6, 21, 26, 6, 26,
 }
-var exprDef = [...]int{
 
+var exprDef = [...]int16{
 	0, -2, 1, 2, 3, 11, 0, 4, 5, 6,
-	7, 8, 9, 0, 0, 0, 183, 0, 0, 0,
-	0, 199, 200, 201, 202, 203, 204, 205, 206, 207,
-	208, 209, 210, 211, 212, 213, 188, 189, 190, 191,
-	192, 193, 194, 195, 196, 197, 198, 187, 169, 169,
-	169, 169, 169, 169, 169, 169, 169, 169, 169, 169,
-	169, 169, 169, 12, 70, 72, 0, 86, 0, 57,
-	58, 59, 60, 3, 2, 0, 0, 63, 64, 0,
-	0, 0, 0, 0, 0, 184, 185, 0, 0, 0,
-	175, 176, 170, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 0, 71, 87, 73,
-	74, 75, 76, 77, 78, 79, 80, 81, 82, 88,
-	89, 0, 91, 0, 106, 107, 108, 109, 0, 0,
-	96, 0, 0, 0, 0, 0, 121, 122, 84, 0,
-	83, 10, 13, 61, 62, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 3, 183, 0, 0, 0, 3,
-	0, 154, 0, 0, 177, 180, 155, 156, 157, 158,
-	159, 160, 161, 162, 163, 164, 165, 166, 167, 168,
-	111, 0, 0, 0, 93, 117, 116, 94, 90, 92,
-	0, 95, 102, 99, 0, 148, 146, 144, 145, 153,
-	151, 149, 150, 105, 103, 0, 0, 0, 0, 0,
-	0, 0, 0, 65, 66, 67, 68, 69, 39, 46,
-	0, 14, 0, 0, 0, 0, 0, 50, 0, 3,
-	183, 0, 219, 215, 0, 220, 0, 186, 0, 0,
-	0, 0, 112, 113, 114, 0, 0, 110, 0, 0,
-	0, 0, 0, 0, 128, 135, 142, 0, 127, 134,
-	141, 123, 130, 137, 124, 131, 138, 125, 132, 139,
-	126, 133, 140, 129, 136, 143, 0, 48, 0, 15,
-	18, 34, 0, 22, 0, 26, 0, 0, 0, 0,
-	0, 38, 52, 3, 51, 0, 0, 217, 218, 0,
-	0, 172, 0, 174, 178, 0, 181, 0, 118, 115,
-	100, 101, 97, 98, 147, 152, 104, 0, 0, 85,
-	47, 19, 35, 36, 214, 23, 42, 27, 30, 40,
-	0, 43, 44, 45, 16, 0, 0, 0, 53, 3,
-	216, 0, 171, 173, 179, 182, 0, 0, 49, 37,
-	31, 0, 17, 20, 0, 24, 28, 0, 54, 55,
-	0, 119, 120, 0, 21, 25, 29, 32, 0, 41,
-	33, 0, 0, 0, 56,
+	7, 8, 9, 0, 0, 0, 196, 0, 0, 0,
+	0, 213, 214, 215, 216, 217, 218, 219, 220, 221,
+	222, 223, 224, 225, 226, 227, 201, 202, 203, 204,
+	205, 206, 207, 208, 209, 210, 211, 212, 200, 182,
+	182, 182, 182, 182, 182, 182, 182, 182, 182, 182,
+	182, 182, 182, 182, 12, 77, 79, 0, 94, 0,
+	64, 65, 66, 67, 3, 2, 0, 0, 70, 71,
+	0, 0, 0, 0, 0, 0, 197, 198, 0, 0,
+	0, 188, 189, 183, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 78, 95,
+	80, 81, 82, 83, 84, 85, 86, 87, 88, 89,
+	90, 96, 97, 0, 100, 0, 118, 119, 120, 121,
+	0, 0, 107, 0, 0, 0, 0, 0, 0, 134,
+	135, 92, 0, 91, 10, 13, 68, 69, 0, 0,
+	0, 0, 0, 0, 196, 3, 11, 0, 3, 196,
+	0, 0, 0, 3, 0, 167, 0, 0, 190, 193,
+	168, 169, 170, 171, 172, 173, 174, 175, 176, 177,
+	178, 179, 180, 181, 123, 0, 0, 0, 104, 130,
+	129, 98, 105, 102, 99, 101, 0, 106, 113, 110,
+	0, 161, 159, 157, 158, 166, 164, 162, 163, 116,
+	114, 117, 0, 0, 0, 0, 0, 0, 0, 0,
+	72, 73, 74, 75, 76, 39, 49, 0, 0, 12,
+	14, 0, 0, 11, 0, 57, 0, 3, 196, 0,
+	233, 229, 0, 234, 0, 199, 0, 0, 0, 0,
+	124, 125, 126, 0, 0, 103, 122, 0, 0, 0,
+	0, 0, 0, 141, 148, 155, 0, 140, 147, 154,
+	136, 143, 150, 137, 144, 151, 138, 145, 152, 139,
+	146, 153, 142, 149, 156, 0, 51, 0, 3, 53,
+	0, 0, 26, 0, 15, 18, 34, 22, 0, 0,
+	12, 0, 0, 38, 59, 3, 58, 0, 0, 231,
+	232, 0, 0, 185, 0, 187, 191, 0, 194, 0,
+	131, 127, 128, 111, 112, 108, 109, 160, 165, 115,
+	0, 0, 93, 50, 0, 54, 228, 27, 30, 19,
+	35, 36, 23, 43, 40, 0, 44, 45, 46, 47,
+	48, 0, 0, 16, 0, 60, 3, 230, 0, 184,
+	186, 192, 195, 0, 0, 52, 55, 0, 31, 37,
+	0, 28, 0, 17, 20, 0, 24, 61, 62, 0,
+	132, 133, 56, 0, 29, 32, 21, 25, 0, 41,
+	0, 33, 0, 0, 0, 42, 0, 63,
 }
-var exprTok1 = [...]int{
 
+var exprTok1 = [...]int8{
 	1,
 }
-var exprTok2 = [...]int{
 
+var exprTok2 = [...]int8{
 	2, 3, 4, 5, 6, 7, 8, 9, 10, 11,
 	12, 13, 14, 15, 16, 17, 18, 19, 20, 21,
 	22, 23, 24, 25, 26, 27, 28, 29, 30, 31,
@@ -536,9 +574,10 @@ var exprTok2 = [...]int{
 	62, 63, 64, 65, 66, 67, 68, 69, 70, 71,
 	72, 73, 74, 75, 76, 77, 78, 79, 80, 81,
 	82, 83, 84, 85, 86, 87, 88, 89, 90, 91,
-	92,
+	92, 93, 94, 95, 96, 97, 98,
 }
-var exprTok3 = [...]int{
+
+var exprTok3 = [...]int8{
 	0,
 }
 
@@ -548,6 +587,7 @@ var exprErrorMessages = [...]struct {
 	msg   string
 }{}
 
+//line yaccpar:1
 
 /*	parser for yacc output	*/
 
@@ -619,9 +659,9 @@ func exprErrorMessage(state, lookAhead int) string {
 	expected := make([]int, 0, 4)
 
 	// Look for shiftable tokens.
-	base := exprPact[state]
+	base := int(exprPact[state])
 	for tok := TOKSTART; tok-1 < len(exprToknames); tok++ {
-		if n := base + tok; n >= 0 && n < exprLast && exprChk[exprAct[n]] == tok {
+		if n := base + tok; n >= 0 && n < exprLast && int(exprChk[int(exprAct[n])]) == tok {
 			if len(expected) == cap(expected) {
 				return res
 			}
@@ -631,13 +671,13 @@ func exprErrorMessage(state, lookAhead int) string {
 
 	if exprDef[state] == -2 {
 		i := 0
-		for exprExca[i] != -1 || exprExca[i+1] != state {
+		for exprExca[i] != -1 || int(exprExca[i+1]) != state {
 			i += 2
 		}
 
 		// Look for tokens that we accept or reduce.
 		for i += 2; exprExca[i] >= 0; i += 2 {
-			tok := exprExca[i]
+			tok := int(exprExca[i])
 			if tok < TOKSTART || exprExca[i+1] == 0 {
 				continue
 			}
@@ -668,30 +708,30 @@ func exprlex1(lex exprLexer, lval *exprSymType) (char, token int) {
 	token = 0
 	char = lex.Lex(lval)
 	if char <= 0 {
-		token = exprTok1[0]
+		token = int(exprTok1[0])
 		goto out
 	}
 	if char < len(exprTok1) {
-		token = exprTok1[char]
+		token = int(exprTok1[char])
 		goto out
 	}
 	if char >= exprPrivate {
 		if char < exprPrivate+len(exprTok2) {
-			token = exprTok2[char-exprPrivate]
+			token = int(exprTok2[char-exprPrivate])
 			goto out
 		}
 	}
 	for i := 0; i < len(exprTok3); i += 2 {
-		token = exprTok3[i+0]
+		token = int(exprTok3[i+0])
 		if token == char {
-			token = exprTok3[i+1]
+			token = int(exprTok3[i+1])
 			goto out
 		}
 	}
 
 out:
 	if token == 0 {
-		token = exprTok2[1] /* unknown char */
+		token = int(exprTok2[1]) /* unknown char */
 	}
 	if exprDebug >= 3 {
 		__yyfmt__.Printf("lex %s(%d)\n", exprTokname(token), uint(char))
@@ -746,7 +786,7 @@ exprstack:
 	exprS[exprp].yys = exprstate
 
 exprnewstate:
-	exprn = exprPact[exprstate]
+	exprn = int(exprPact[exprstate])
 	if exprn <= exprFlag {
 		goto exprdefault /* simple state */
 	}
@@ -757,8 +797,8 @@ exprnewstate:
 	if exprn < 0 || exprn >= exprLast {
 		goto exprdefault
 	}
-	exprn = exprAct[exprn]
-	if exprChk[exprn] == exprtoken { /* valid shift */
+	exprn = int(exprAct[exprn])
+	if int(exprChk[exprn]) == exprtoken { /* valid shift */
 		exprrcvr.char = -1
 		exprtoken = -1
 		exprVAL = exprrcvr.lval
@@ -771,7 +811,7 @@ exprnewstate:
 
 exprdefault:
 	/* default state action */
-	exprn = exprDef[exprstate]
+	exprn = int(exprDef[exprstate])
 	if exprn == -2 {
 		if exprrcvr.char < 0 {
 			exprrcvr.char, exprtoken = exprlex1(exprlex, &exprrcvr.lval)
@@ -780,18 +820,18 @@ exprdefault:
 		/* look through exception table */
 		xi := 0
 		for {
-			if exprExca[xi+0] == -1 && exprExca[xi+1] == exprstate {
+			if exprExca[xi+0] == -1 && int(exprExca[xi+1]) == exprstate {
 				break
 			}
 			xi += 2
 		}
 		for xi += 2; ; xi += 2 {
-			exprn = exprExca[xi+0]
+			exprn = int(exprExca[xi+0])
 			if exprn < 0 || exprn == exprtoken {
 				break
 			}
 		}
-		exprn = exprExca[xi+1]
+		exprn = int(exprExca[xi+1])
 		if exprn < 0 {
 			goto ret0
 		}
@@ -813,10 +853,10 @@ exprdefault:
 
 			/* find a state where "error" is a legal shift action */
 			for exprp >= 0 {
-				exprn = exprPact[exprS[exprp].yys] + exprErrCode
+				exprn = int(exprPact[exprS[exprp].yys]) + exprErrCode
 				if exprn >= 0 && exprn < exprLast {
-					exprstate = exprAct[exprn] /* simulate a shift of "error" */
-					if exprChk[exprstate] == exprErrCode {
+					exprstate = int(exprAct[exprn]) /* simulate a shift of "error" */
+					if int(exprChk[exprstate]) == exprErrCode {
 						goto exprstack
 					}
 				}
@@ -852,7 +892,7 @@ exprdefault:
 	exprpt := exprp
 	_ = exprpt // guard against "declared and not used"
 
-	exprp -= exprR2[exprn]
+	exprp -= int(exprR2[exprn])
 	// exprp is now the index of $0. Perform the default action. Iff the
 	// reduced production is ε, $1 is possibly out of range.
 	if exprp+1 >= len(exprS) {
@@ -863,16 +903,16 @@ exprdefault:
 	exprVAL = exprS[exprp+1]
 
 	/* consult goto table to find next state */
-	exprn = exprR1[exprn]
-	exprg := exprPgo[exprn]
+	exprn = int(exprR1[exprn])
+	exprg := int(exprPgo[exprn])
 	exprj := exprg + exprS[exprp].yys + 1
 
 	if exprj >= exprLast {
-		exprstate = exprAct[exprg]
+		exprstate = int(exprAct[exprg])
 	} else {
-		exprstate = exprAct[exprj]
-		if exprChk[exprstate] != -exprn {
-			exprstate = exprAct[exprg]
+		exprstate = int(exprAct[exprj])
+		if int(exprChk[exprstate]) != -exprn {
+			exprstate = int(exprAct[exprg])
 		}
 	}
 	// dummy call; replaced with literal code
@@ -880,1102 +920,1404 @@ exprdefault:
 
 	case 1:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:158
 		{
 			exprlex.(*parser).expr = exprDollar[1].Expr
 		}
 	case 2:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:161
 		{
 			exprVAL.Expr = exprDollar[1].LogExpr
 		}
 	case 3:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:162
 		{
 			exprVAL.Expr = exprDollar[1].MetricExpr
 		}
 	case 4:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:166
 		{
 			exprVAL.MetricExpr = exprDollar[1].RangeAggregationExpr
 		}
 	case 5:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:167
 		{
 			exprVAL.MetricExpr = exprDollar[1].VectorAggregationExpr
 		}
 	case 6:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:168
 		{
 			exprVAL.MetricExpr = exprDollar[1].BinOpExpr
 		}
 	case 7:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:169
 		{
 			exprVAL.MetricExpr = exprDollar[1].LiteralExpr
 		}
 	case 8:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:170
 		{
 			exprVAL.MetricExpr = exprDollar[1].LabelReplaceExpr
 		}
 	case 9:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:171
 		{
 			exprVAL.MetricExpr = exprDollar[1].VectorExpr
 		}
 	case 10:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:172
 		{
 			exprVAL.MetricExpr = exprDollar[2].MetricExpr
 		}
 	case 11:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:176
 		{
 			exprVAL.LogExpr = newMatcherExpr(exprDollar[1].Selector)
 		}
 	case 12:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:177
 		{
 			exprVAL.LogExpr = newPipelineExpr(newMatcherExpr(exprDollar[1].Selector), exprDollar[2].PipelineExpr)
 		}
 	case 13:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:178
 		{
 			exprVAL.LogExpr = exprDollar[2].LogExpr
 		}
 	case 14:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:182
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[1].Selector), exprDollar[2].duration, nil, nil)
 		}
 	case 15:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:183
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[1].Selector), exprDollar[2].duration, nil, exprDollar[3].OffsetExpr)
 		}
 	case 16:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:184
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[2].Selector), exprDollar[4].duration, nil, nil)
 		}
 	case 17:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
+//line pkg/logql/syntax/expr.y:185
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[2].Selector), exprDollar[4].duration, nil, exprDollar[5].OffsetExpr)
 		}
 	case 18:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:186
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[1].Selector), exprDollar[2].duration, exprDollar[3].UnwrapExpr, nil)
 		}
 	case 19:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:187
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[1].Selector), exprDollar[2].duration, exprDollar[4].UnwrapExpr, exprDollar[3].OffsetExpr)
 		}
 	case 20:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
+//line pkg/logql/syntax/expr.y:188
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[2].Selector), exprDollar[4].duration, exprDollar[5].UnwrapExpr, nil)
 		}
 	case 21:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
+//line pkg/logql/syntax/expr.y:189
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[2].Selector), exprDollar[4].duration, exprDollar[6].UnwrapExpr, exprDollar[5].OffsetExpr)
 		}
 	case 22:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:190
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[1].Selector), exprDollar[3].duration, exprDollar[2].UnwrapExpr, nil)
 		}
 	case 23:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:191
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[1].Selector), exprDollar[3].duration, exprDollar[2].UnwrapExpr, exprDollar[4].OffsetExpr)
 		}
 	case 24:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
+//line pkg/logql/syntax/expr.y:192
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[2].Selector), exprDollar[5].duration, exprDollar[3].UnwrapExpr, nil)
 		}
 	case 25:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
+//line pkg/logql/syntax/expr.y:193
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[2].Selector), exprDollar[5].duration, exprDollar[3].UnwrapExpr, exprDollar[6].OffsetExpr)
 		}
 	case 26:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:194
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[1].Selector), exprDollar[2].PipelineExpr), exprDollar[3].duration, nil, nil)
 		}
 	case 27:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:195
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[1].Selector), exprDollar[2].PipelineExpr), exprDollar[3].duration, nil, exprDollar[4].OffsetExpr)
 		}
 	case 28:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
+//line pkg/logql/syntax/expr.y:196
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[2].Selector), exprDollar[3].PipelineExpr), exprDollar[5].duration, nil, nil)
 		}
 	case 29:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
+//line pkg/logql/syntax/expr.y:197
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[2].Selector), exprDollar[3].PipelineExpr), exprDollar[5].duration, nil, exprDollar[6].OffsetExpr)
 		}
 	case 30:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:198
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[1].Selector), exprDollar[2].PipelineExpr), exprDollar[4].duration, exprDollar[3].UnwrapExpr, nil)
 		}
 	case 31:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
+//line pkg/logql/syntax/expr.y:199
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[1].Selector), exprDollar[2].PipelineExpr), exprDollar[4].duration, exprDollar[3].UnwrapExpr, exprDollar[5].OffsetExpr)
 		}
 	case 32:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
+//line pkg/logql/syntax/expr.y:200
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[2].Selector), exprDollar[3].PipelineExpr), exprDollar[6].duration, exprDollar[4].UnwrapExpr, nil)
 		}
 	case 33:
 		exprDollar = exprS[exprpt-7 : exprpt+1]
+//line pkg/logql/syntax/expr.y:201
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[2].Selector), exprDollar[3].PipelineExpr), exprDollar[6].duration, exprDollar[4].UnwrapExpr, exprDollar[7].OffsetExpr)
 		}
 	case 34:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:202
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[1].Selector), exprDollar[3].PipelineExpr), exprDollar[2].duration, nil, nil)
 		}
 	case 35:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:203
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[1].Selector), exprDollar[4].PipelineExpr), exprDollar[2].duration, nil, exprDollar[3].OffsetExpr)
 		}
 	case 36:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:204
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[1].Selector), exprDollar[3].PipelineExpr), exprDollar[2].duration, exprDollar[4].UnwrapExpr, nil)
 		}
 	case 37:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
+//line pkg/logql/syntax/expr.y:205
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[1].Selector), exprDollar[4].PipelineExpr), exprDollar[2].duration, exprDollar[5].UnwrapExpr, exprDollar[3].OffsetExpr)
 		}
 	case 38:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:206
 		{
 			exprVAL.LogRangeExpr = exprDollar[2].LogRangeExpr
 		}
 	case 40:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:211
 		{
 			exprVAL.UnwrapExpr = newUnwrapExpr(exprDollar[3].str, "")
 		}
 	case 41:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
+//line pkg/logql/syntax/expr.y:212
 		{
 			exprVAL.UnwrapExpr = newUnwrapExpr(exprDollar[5].str, exprDollar[3].ConvOp)
 		}
 	case 42:
+		exprDollar = exprS[exprpt-8 : exprpt+1]
+//line pkg/logql/syntax/expr.y:213
+		{
+			exprVAL.UnwrapExpr = newUnwrapExprWithUnit(exprDollar[5].str, exprDollar[3].ConvOp, exprDollar[7].str)
+		}
+	case 43:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:214
 		{
 			exprVAL.UnwrapExpr = exprDollar[1].UnwrapExpr.addPostFilter(exprDollar[3].LabelFilter)
 		}
-	case 43:
+	case 44:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:218
 		{
 			exprVAL.ConvOp = OpConvBytes
 		}
-	case 44:
+	case 45:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:219
+		{
+			exprVAL.ConvOp = OpConvBytesInt
+		}
+	case 46:
+		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:220
 		{
 			exprVAL.ConvOp = OpConvDuration
 		}
-	case 45:
+	case 47:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:221
 		{
 			exprVAL.ConvOp = OpConvDurationSeconds
 		}
-	case 46:
+	case 48:
+		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:222
+		{
+			exprVAL.ConvOp = OpConvToFloat
+		}
+	case 49:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:226
 		{
 			exprVAL.RangeAggregationExpr = newRangeAggregationExpr(exprDollar[3].LogRangeExpr, exprDollar[1].RangeOp, nil, nil)
 		}
-	case 47:
+	case 50:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
+//line pkg/logql/syntax/expr.y:227
 		{
 			exprVAL.RangeAggregationExpr = newRangeAggregationExpr(exprDollar[5].LogRangeExpr, exprDollar[1].RangeOp, nil, &exprDollar[3].str)
 		}
-	case 48:
+	case 51:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
+//line pkg/logql/syntax/expr.y:228
 		{
 			exprVAL.RangeAggregationExpr = newRangeAggregationExpr(exprDollar[3].LogRangeExpr, exprDollar[1].RangeOp, exprDollar[5].Grouping, nil)
 		}
-	case 49:
+	case 52:
 		exprDollar = exprS[exprpt-7 : exprpt+1]
+//line pkg/logql/syntax/expr.y:229
 		{
 			exprVAL.RangeAggregationExpr = newRangeAggregationExpr(exprDollar[5].LogRangeExpr, exprDollar[1].RangeOp, exprDollar[7].Grouping, &exprDollar[3].str)
 		}
-	case 50:
+	case 53:
+		exprDollar = exprS[exprpt-5 : exprpt+1]
+//line pkg/logql/syntax/expr.y:230
+		{
+			exprVAL.RangeAggregationExpr = newSubqueryExpr(exprDollar[3].MetricExpr, exprDollar[1].RangeOp, exprDollar[4].subqueryRange, nil, nil)
+		}
+	case 54:
+		exprDollar = exprS[exprpt-6 : exprpt+1]
+//line pkg/logql/syntax/expr.y:231
+		{
+			exprVAL.RangeAggregationExpr = newSubqueryExpr(exprDollar[3].MetricExpr, exprDollar[1].RangeOp, exprDollar[4].subqueryRange, exprDollar[5].OffsetExpr, nil)
+		}
+	case 55:
+		exprDollar = exprS[exprpt-7 : exprpt+1]
+//line pkg/logql/syntax/expr.y:232
+		{
+			exprVAL.RangeAggregationExpr = newSubqueryExpr(exprDollar[5].MetricExpr, exprDollar[1].RangeOp, exprDollar[6].subqueryRange, nil, &exprDollar[3].str)
+		}
+	case 56:
+		exprDollar = exprS[exprpt-8 : exprpt+1]
+//line pkg/logql/syntax/expr.y:233
+		{
+			exprVAL.RangeAggregationExpr = newSubqueryExpr(exprDollar[5].MetricExpr, exprDollar[1].RangeOp, exprDollar[6].subqueryRange, exprDollar[7].OffsetExpr, &exprDollar[3].str)
+		}
+	case 57:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:238
 		{
 			exprVAL.VectorAggregationExpr = mustNewVectorAggregationExpr(exprDollar[3].MetricExpr, exprDollar[1].VectorOp, nil, nil)
 		}
-	case 51:
+	case 58:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
+//line pkg/logql/syntax/expr.y:239
 		{
 			exprVAL.VectorAggregationExpr = mustNewVectorAggregationExpr(exprDollar[4].MetricExpr, exprDollar[1].VectorOp, exprDollar[2].Grouping, nil)
 		}
-	case 52:
+	case 59:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
+//line pkg/logql/syntax/expr.y:240
 		{
 			exprVAL.VectorAggregationExpr = mustNewVectorAggregationExpr(exprDollar[3].MetricExpr, exprDollar[1].VectorOp, exprDollar[5].Grouping, nil)
 		}
-	case 53:
+	case 60:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
+//line pkg/logql/syntax/expr.y:242
 		{
 			exprVAL.VectorAggregationExpr = mustNewVectorAggregationExpr(exprDollar[5].MetricExpr, exprDollar[1].VectorOp, nil, &exprDollar[3].str)
 		}
-	case 54:
+	case 61:
 		exprDollar = exprS[exprpt-7 : exprpt+1]
+//line pkg/logql/syntax/expr.y:243
 		{
 			exprVAL.VectorAggregationExpr = mustNewVectorAggregationExpr(exprDollar[5].MetricExpr, exprDollar[1].VectorOp, exprDollar[7].Grouping, &exprDollar[3].str)
 		}
-	case 55:
+	case 62:
 		exprDollar = exprS[exprpt-7 : exprpt+1]
+//line pkg/logql/syntax/expr.y:244
 		{
 			exprVAL.VectorAggregationExpr = mustNewVectorAggregationExpr(exprDollar[6].MetricExpr, exprDollar[1].VectorOp, exprDollar[2].Grouping, &exprDollar[4].str)
 		}
-	case 56:
+	case 63:
 		exprDollar = exprS[exprpt-12 : exprpt+1]
+//line pkg/logql/syntax/expr.y:249
 		{
 			exprVAL.LabelReplaceExpr = mustNewLabelReplaceExpr(exprDollar[3].MetricExpr, exprDollar[5].str, exprDollar[7].str, exprDollar[9].str, exprDollar[11].str)
 		}
-	case 57:
+	case 64:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:253
 		{
 			exprVAL.Filter = labels.MatchRegexp
 		}
-	case 58:
+	case 65:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:254
 		{
 			exprVAL.Filter = labels.MatchEqual
 		}
-	case 59:
+	case 66:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:255
 		{
 			exprVAL.Filter = labels.MatchNotRegexp
 		}
-	case 60:
+	case 67:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:256
 		{
 			exprVAL.Filter = labels.MatchNotEqual
 		}
-	case 61:
+	case 68:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:260
 		{
 			exprVAL.Selector = exprDollar[2].Matchers
 		}
-	case 62:
+	case 69:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:261
 		{
 			exprVAL.Selector = exprDollar[2].Matchers
 		}
-	case 63:
+	case 70:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:262
 		{
 		}
-	case 64:
+	case 71:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:266
 		{
 			exprVAL.Matchers = []*labels.Matcher{exprDollar[1].Matcher}
 		}
-	case 65:
+	case 72:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:267
 		{
 			exprVAL.Matchers = append(exprDollar[1].Matchers, exprDollar[3].Matcher)
 		}
-	case 66:
+	case 73:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:271
 		{
 			exprVAL.Matcher = mustNewMatcher(labels.MatchEqual, exprDollar[1].str, exprDollar[3].str)
 		}
-	case 67:
+	case 74:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:272
 		{
 			exprVAL.Matcher = mustNewMatcher(labels.MatchNotEqual, exprDollar[1].str, exprDollar[3].str)
 		}
-	case 68:
+	case 75:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:273
 		{
 			exprVAL.Matcher = mustNewMatcher(labels.MatchRegexp, exprDollar[1].str, exprDollar[3].str)
 		}
-	case 69:
+	case 76:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:274
 		{
 			exprVAL.Matcher = mustNewMatcher(labels.MatchNotRegexp, exprDollar[1].str, exprDollar[3].str)
 		}
-	case 70:
+	case 77:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:278
 		{
 			exprVAL.PipelineExpr = MultiStageExpr{exprDollar[1].PipelineStage}
 		}
-	case 71:
+	case 78:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:279
 		{
 			exprVAL.PipelineExpr = append(exprDollar[1].PipelineExpr, exprDollar[2].PipelineStage)
 		}
-	case 72:
+	case 79:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:283
 		{
 			exprVAL.PipelineStage = exprDollar[1].LineFilters
 		}
-	case 73:
+	case 80:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:284
 		{
 			exprVAL.PipelineStage = exprDollar[2].LabelParser
 		}
-	case 74:
+	case 81:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:285
 		{
 			exprVAL.PipelineStage = exprDollar[2].JSONExpressionParser
 		}
-	case 75:
+	case 82:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:286
 		{
 			exprVAL.PipelineStage = exprDollar[2].LogfmtExpressionParser
 		}
-	case 76:
+	case 83:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:287
 		{
 			exprVAL.PipelineStage = &LabelFilterExpr{LabelFilterer: exprDollar[2].LabelFilter}
 		}
-	case 77:
+	case 84:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:288
 		{
 			exprVAL.PipelineStage = exprDollar[2].LineFormatExpr
 		}
-	case 78:
+	case 85:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:289
 		{
 			exprVAL.PipelineStage = exprDollar[2].DecolorizeExpr
 		}
-	case 79:
+	case 86:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:290
 		{
 			exprVAL.PipelineStage = exprDollar[2].LabelFormatExpr
 		}
-	case 80:
+	case 87:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:291
 		{
 			exprVAL.PipelineStage = exprDollar[2].DropLabelsExpr
 		}
-	case 81:
+	case 88:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:292
 		{
 			exprVAL.PipelineStage = exprDollar[2].KeepLabelsExpr
 		}
-	case 82:
+	case 89:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:293
 		{
 			exprVAL.PipelineStage = exprDollar[2].DistinctFilter
 		}
-	case 83:
+	case 90:
+		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:294
+		{
+			exprVAL.PipelineStage = exprDollar[2].SampleFilter
+		}
+	case 91:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:298
 		{
 			exprVAL.FilterOp = OpFilterIP
 		}
-	case 84:
+	case 92:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:302
 		{
 			exprVAL.LineFilter = newLineFilterExpr(exprDollar[1].Filter, "", exprDollar[2].str)
 		}
-	case 85:
+	case 93:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
+//line pkg/logql/syntax/expr.y:303
 		{
 			exprVAL.LineFilter = newLineFilterExpr(exprDollar[1].Filter, exprDollar[2].FilterOp, exprDollar[4].str)
 		}
-	case 86:
+	case 94:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:307
 		{
 			exprVAL.LineFilters = exprDollar[1].LineFilter
 		}
-	case 87:
+	case 95:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:308
 		{
 			exprVAL.LineFilters = newNestedLineFilterExpr(exprDollar[1].LineFilters, exprDollar[2].LineFilter)
 		}
-	case 88:
+	case 96:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:312
 		{
 			exprVAL.LabelParser = newLabelParserExpr(OpParserTypeJSON, "")
 		}
-	case 89:
+	case 97:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:313
 		{
 			exprVAL.LabelParser = newLabelParserExpr(OpParserTypeLogfmt, "")
 		}
-	case 90:
+	case 98:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:314
+		{
+			exprVAL.LabelParser = newLogfmtParserExpr(exprDollar[2].ParserFlags)
+		}
+	case 99:
+		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:315
 		{
 			exprVAL.LabelParser = newLabelParserExpr(OpParserTypeRegexp, exprDollar[2].str)
 		}
-	case 91:
+	case 100:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:316
 		{
 			exprVAL.LabelParser = newLabelParserExpr(OpParserTypeUnpack, "")
 		}
-	case 92:
+	case 101:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:317
 		{
 			exprVAL.LabelParser = newLabelParserExpr(OpParserTypePattern, exprDollar[2].str)
 		}
-	case 93:
+	case 102:
+		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:321
+		{
+			exprVAL.ParserFlags = []string{exprDollar[1].str}
+		}
+	case 103:
+		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:322
+		{
+			exprVAL.ParserFlags = append(exprDollar[1].ParserFlags, exprDollar[2].str)
+		}
+	case 104:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:326
 		{
 			exprVAL.JSONExpressionParser = newJSONExpressionParser(exprDollar[2].LabelExtractionExpressionList)
 		}
-	case 94:
+	case 105:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:329
 		{
 			exprVAL.LogfmtExpressionParser = newLogfmtExpressionParser(exprDollar[2].LabelExtractionExpressionList)
 		}
-	case 95:
+	case 106:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:331
 		{
 			exprVAL.LineFormatExpr = newLineFmtExpr(exprDollar[2].str)
 		}
-	case 96:
+	case 107:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:333
 		{
 			exprVAL.DecolorizeExpr = newDecolorizeExpr()
 		}
-	case 97:
+	case 108:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:336
 		{
 			exprVAL.LabelFormat = log.NewRenameLabelFmt(exprDollar[1].str, exprDollar[3].str)
 		}
-	case 98:
+	case 109:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:337
 		{
 			exprVAL.LabelFormat = log.NewTemplateLabelFmt(exprDollar[1].str, exprDollar[3].str)
 		}
-	case 99:
+	case 110:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:341
 		{
 			exprVAL.LabelsFormat = []log.LabelFmt{exprDollar[1].LabelFormat}
 		}
-	case 100:
+	case 111:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:342
 		{
 			exprVAL.LabelsFormat = append(exprDollar[1].LabelsFormat, exprDollar[3].LabelFormat)
 		}
-	case 102:
+	case 113:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:347
 		{
 			exprVAL.LabelFormatExpr = newLabelFmtExpr(exprDollar[2].LabelsFormat)
 		}
-	case 103:
+	case 114:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:350
 		{
 			exprVAL.DistinctLabel = []string{exprDollar[1].str}
 		}
-	case 104:
+	case 115:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:351
 		{
 			exprVAL.DistinctLabel = append(exprDollar[1].DistinctLabel, exprDollar[3].str)
 		}
-	case 105:
+	case 116:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:355
 		{
 			exprVAL.DistinctFilter = newDistinctFilterExpr(exprDollar[2].DistinctLabel)
 		}
-	case 106:
+	case 117:
+		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:358
+		{
+			exprVAL.SampleFilter = newSampleFilterExpr(exprDollar[2].str)
+		}
+	case 118:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:361
 		{
 			exprVAL.LabelFilter = log.NewStringLabelFilter(exprDollar[1].Matcher)
 		}
-	case 107:
+	case 119:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:362
 		{
 			exprVAL.LabelFilter = exprDollar[1].IPLabelFilter
 		}
-	case 108:
+	case 120:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:363
 		{
 			exprVAL.LabelFilter = exprDollar[1].UnitFilter
 		}
-	case 109:
+	case 121:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:364
 		{
 			exprVAL.LabelFilter = exprDollar[1].NumberFilter
 		}
-	case 110:
+	case 122:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:365
 		{
 			exprVAL.LabelFilter = exprDollar[2].LabelFilter
 		}
-	case 111:
+	case 123:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:366
 		{
 			exprVAL.LabelFilter = log.NewAndLabelFilter(exprDollar[1].LabelFilter, exprDollar[2].LabelFilter)
 		}
-	case 112:
+	case 124:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:367
 		{
 			exprVAL.LabelFilter = log.NewAndLabelFilter(exprDollar[1].LabelFilter, exprDollar[3].LabelFilter)
 		}
-	case 113:
+	case 125:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:368
 		{
 			exprVAL.LabelFilter = log.NewAndLabelFilter(exprDollar[1].LabelFilter, exprDollar[3].LabelFilter)
 		}
-	case 114:
+	case 126:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:369
 		{
 			exprVAL.LabelFilter = log.NewOrLabelFilter(exprDollar[1].LabelFilter, exprDollar[3].LabelFilter)
 		}
-	case 115:
+	case 127:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:373
 		{
 			exprVAL.LabelExtractionExpression = log.NewLabelExtractionExpr(exprDollar[1].str, exprDollar[3].str)
 		}
-	case 116:
+	case 128:
+		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:374
+		{
+			exprVAL.LabelExtractionExpression = log.NewLabelExtractionExpr(exprDollar[1].str, exprDollar[3].str)
+		}
+	case 129:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:375
 		{
 			exprVAL.LabelExtractionExpression = log.NewLabelExtractionExpr(exprDollar[1].str, exprDollar[1].str)
 		}
-	case 117:
+	case 130:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:378
 		{
 			exprVAL.LabelExtractionExpressionList = []log.LabelExtractionExpr{exprDollar[1].LabelExtractionExpression}
 		}
-	case 118:
+	case 131:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:379
 		{
 			exprVAL.LabelExtractionExpressionList = append(exprDollar[1].LabelExtractionExpressionList, exprDollar[3].LabelExtractionExpression)
 		}
-	case 119:
+	case 132:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
+//line pkg/logql/syntax/expr.y:383
 		{
 			exprVAL.IPLabelFilter = log.NewIPLabelFilter(exprDollar[5].str, exprDollar[1].str, log.LabelFilterEqual)
 		}
-	case 120:
+	case 133:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
+//line pkg/logql/syntax/expr.y:384
 		{
 			exprVAL.IPLabelFilter = log.NewIPLabelFilter(exprDollar[5].str, exprDollar[1].str, log.LabelFilterNotEqual)
 		}
-	case 121:
+	case 134:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:388
 		{
 			exprVAL.UnitFilter = exprDollar[1].DurationFilter
 		}
-	case 122:
+	case 135:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:389
 		{
 			exprVAL.UnitFilter = exprDollar[1].BytesFilter
 		}
-	case 123:
+	case 136:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:392
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterGreaterThan, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 124:
+	case 137:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:393
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterGreaterThanOrEqual, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 125:
+	case 138:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:394
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterLesserThan, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 126:
+	case 139:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:395
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterLesserThanOrEqual, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 127:
+	case 140:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:396
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterNotEqual, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 128:
+	case 141:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:397
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterEqual, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 129:
+	case 142:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:398
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterEqual, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 130:
+	case 143:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:402
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterGreaterThan, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 131:
+	case 144:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:403
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterGreaterThanOrEqual, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 132:
+	case 145:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:404
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterLesserThan, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 133:
+	case 146:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:405
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterLesserThanOrEqual, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 134:
+	case 147:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:406
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterNotEqual, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 135:
+	case 148:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:407
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterEqual, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 136:
+	case 149:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:408
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterEqual, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 137:
+	case 150:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:412
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterGreaterThan, exprDollar[1].str, mustNewFloat(exprDollar[3].str))
 		}
-	case 138:
+	case 151:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:413
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterGreaterThanOrEqual, exprDollar[1].str, mustNewFloat(exprDollar[3].str))
 		}
-	case 139:
+	case 152:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:414
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterLesserThan, exprDollar[1].str, mustNewFloat(exprDollar[3].str))
 		}
-	case 140:
+	case 153:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:415
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterLesserThanOrEqual, exprDollar[1].str, mustNewFloat(exprDollar[3].str))
 		}
-	case 141:
+	case 154:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:416
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterNotEqual, exprDollar[1].str, mustNewFloat(exprDollar[3].str))
 		}
-	case 142:
+	case 155:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:417
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterEqual, exprDollar[1].str, mustNewFloat(exprDollar[3].str))
 		}
-	case 143:
+	case 156:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:418
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterEqual, exprDollar[1].str, mustNewFloat(exprDollar[3].str))
 		}
-	case 144:
+	case 157:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:422
 		{
 			exprVAL.DropLabel = log.NewDropLabel(nil, exprDollar[1].str)
 		}
-	case 145:
+	case 158:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:423
 		{
 			exprVAL.DropLabel = log.NewDropLabel(exprDollar[1].Matcher, "")
 		}
-	case 146:
+	case 159:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:426
 		{
 			exprVAL.DropLabels = []log.DropLabel{exprDollar[1].DropLabel}
 		}
-	case 147:
+	case 160:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:427
 		{
 			exprVAL.DropLabels = append(exprDollar[1].DropLabels, exprDollar[3].DropLabel)
 		}
-	case 148:
+	case 161:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:430
 		{
 			exprVAL.DropLabelsExpr = newDropLabelsExpr(exprDollar[2].DropLabels)
 		}
-	case 149:
+	case 162:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:433
 		{
 			exprVAL.KeepLabel = log.NewKeepLabel(nil, exprDollar[1].str)
 		}
-	case 150:
+	case 163:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:434
 		{
 			exprVAL.KeepLabel = log.NewKeepLabel(exprDollar[1].Matcher, "")
 		}
-	case 151:
+	case 164:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:437
 		{
 			exprVAL.KeepLabels = []log.KeepLabel{exprDollar[1].KeepLabel}
 		}
-	case 152:
+	case 165:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:438
 		{
 			exprVAL.KeepLabels = append(exprDollar[1].KeepLabels, exprDollar[3].KeepLabel)
 		}
-	case 153:
+	case 166:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:441
 		{
 			exprVAL.KeepLabelsExpr = newKeepLabelsExpr(exprDollar[2].KeepLabels)
 		}
-	case 154:
+	case 167:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:445
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("or", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 155:
+	case 168:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:446
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("and", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 156:
+	case 169:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:447
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("unless", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 157:
+	case 170:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:448
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("+", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 158:
+	case 171:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:449
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("-", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 159:
+	case 172:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:450
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("*", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 160:
+	case 173:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:451
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("/", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 161:
+	case 174:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:452
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("%", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 162:
+	case 175:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:453
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("^", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 163:
+	case 176:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:454
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("==", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 164:
+	case 177:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:455
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("!=", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 165:
+	case 178:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:456
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr(">", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 166:
+	case 179:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:457
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr(">=", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 167:
+	case 180:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:458
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("<", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 168:
+	case 181:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:459
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("<=", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 169:
+	case 182:
 		exprDollar = exprS[exprpt-0 : exprpt+1]
+//line pkg/logql/syntax/expr.y:463
 		{
 			exprVAL.BoolModifier = &BinOpOptions{VectorMatching: &VectorMatching{Card: CardOneToOne}}
 		}
-	case 170:
+	case 183:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:467
 		{
 			exprVAL.BoolModifier = &BinOpOptions{VectorMatching: &VectorMatching{Card: CardOneToOne}, ReturnBool: true}
 		}
-	case 171:
+	case 184:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
+//line pkg/logql/syntax/expr.y:474
 		{
 			exprVAL.OnOrIgnoringModifier = exprDollar[1].BoolModifier
 			exprVAL.OnOrIgnoringModifier.VectorMatching.On = true
 			exprVAL.OnOrIgnoringModifier.VectorMatching.MatchingLabels = exprDollar[4].Labels
 		}
-	case 172:
+	case 185:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:480
 		{
 			exprVAL.OnOrIgnoringModifier = exprDollar[1].BoolModifier
 			exprVAL.OnOrIgnoringModifier.VectorMatching.On = true
 		}
-	case 173:
+	case 186:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
+//line pkg/logql/syntax/expr.y:485
 		{
 			exprVAL.OnOrIgnoringModifier = exprDollar[1].BoolModifier
 			exprVAL.OnOrIgnoringModifier.VectorMatching.MatchingLabels = exprDollar[4].Labels
 		}
-	case 174:
+	case 187:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:490
 		{
 			exprVAL.OnOrIgnoringModifier = exprDollar[1].BoolModifier
 		}
-	case 175:
+	case 188:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:496
 		{
 			exprVAL.BinOpModifier = exprDollar[1].BoolModifier
 		}
-	case 176:
+	case 189:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:497
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 		}
-	case 177:
+	case 190:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:499
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 			exprVAL.BinOpModifier.VectorMatching.Card = CardManyToOne
 		}
-	case 178:
+	case 191:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:504
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 			exprVAL.BinOpModifier.VectorMatching.Card = CardManyToOne
 		}
-	case 179:
+	case 192:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
+//line pkg/logql/syntax/expr.y:509
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 			exprVAL.BinOpModifier.VectorMatching.Card = CardManyToOne
 			exprVAL.BinOpModifier.VectorMatching.Include = exprDollar[4].Labels
 		}
-	case 180:
+	case 193:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:515
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 			exprVAL.BinOpModifier.VectorMatching.Card = CardOneToMany
 		}
-	case 181:
+	case 194:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:520
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 			exprVAL.BinOpModifier.VectorMatching.Card = CardOneToMany
 		}
-	case 182:
+	case 195:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
+//line pkg/logql/syntax/expr.y:525
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 			exprVAL.BinOpModifier.VectorMatching.Card = CardOneToMany
 			exprVAL.BinOpModifier.VectorMatching.Include = exprDollar[4].Labels
 		}
-	case 183:
+	case 196:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:533
 		{
 			exprVAL.LiteralExpr = mustNewLiteralExpr(exprDollar[1].str, false)
 		}
-	case 184:
+	case 197:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:534
 		{
 			exprVAL.LiteralExpr = mustNewLiteralExpr(exprDollar[2].str, false)
 		}
-	case 185:
+	case 198:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:535
 		{
 			exprVAL.LiteralExpr = mustNewLiteralExpr(exprDollar[2].str, true)
 		}
-	case 186:
+	case 199:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:539
 		{
 			exprVAL.VectorExpr = NewVectorExpr(exprDollar[3].str)
 		}
-	case 187:
+	case 200:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:542
 		{
 			exprVAL.Vector = OpTypeVector
 		}
-	case 188:
+	case 201:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:546
 		{
 			exprVAL.VectorOp = OpTypeSum
 		}
-	case 189:
+	case 202:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:547
 		{
 			exprVAL.VectorOp = OpTypeAvg
 		}
-	case 190:
+	case 203:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:548
 		{
 			exprVAL.VectorOp = OpTypeCount
 		}
-	case 191:
+	case 204:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:549
 		{
 			exprVAL.VectorOp = OpTypeMax
 		}
-	case 192:
+	case 205:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:550
 		{
 			exprVAL.VectorOp = OpTypeMin
 		}
-	case 193:
+	case 206:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:551
 		{
 			exprVAL.VectorOp = OpTypeStddev
 		}
-	case 194:
+	case 207:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:552
 		{
 			exprVAL.VectorOp = OpTypeStdvar
 		}
-	case 195:
+	case 208:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:553
 		{
 			exprVAL.VectorOp = OpTypeBottomK
 		}
-	case 196:
+	case 209:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:554
 		{
 			exprVAL.VectorOp = OpTypeTopK
 		}
-	case 197:
+	case 210:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:555
+		{
+			exprVAL.VectorOp = OpTypeApproxTopK
+		}
+	case 211:
+		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:556
 		{
 			exprVAL.VectorOp = OpTypeSort
 		}
-	case 198:
+	case 212:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:557
 		{
 			exprVAL.VectorOp = OpTypeSortDesc
 		}
-	case 199:
+	case 213:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:561
 		{
 			exprVAL.RangeOp = OpRangeTypeCount
 		}
-	case 200:
+	case 214:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:562
 		{
 			exprVAL.RangeOp = OpRangeTypeRate
 		}
-	case 201:
+	case 215:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:563
 		{
 			exprVAL.RangeOp = OpRangeTypeRateCounter
 		}
-	case 202:
+	case 216:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:564
 		{
 			exprVAL.RangeOp = OpRangeTypeBytes
 		}
-	case 203:
+	case 217:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:565
 		{
 			exprVAL.RangeOp = OpRangeTypeBytesRate
 		}
-	case 204:
+	case 218:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:566
 		{
 			exprVAL.RangeOp = OpRangeTypeAvg
 		}
-	case 205:
+	case 219:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:567
 		{
 			exprVAL.RangeOp = OpRangeTypeSum
 		}
-	case 206:
+	case 220:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:568
 		{
 			exprVAL.RangeOp = OpRangeTypeMin
 		}
-	case 207:
+	case 221:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:569
 		{
 			exprVAL.RangeOp = OpRangeTypeMax
 		}
-	case 208:
+	case 222:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:570
 		{
 			exprVAL.RangeOp = OpRangeTypeStdvar
 		}
-	case 209:
+	case 223:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:571
 		{
 			exprVAL.RangeOp = OpRangeTypeStddev
 		}
-	case 210:
+	case 224:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:572
 		{
 			exprVAL.RangeOp = OpRangeTypeQuantile
 		}
-	case 211:
+	case 225:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:573
 		{
 			exprVAL.RangeOp = OpRangeTypeFirst
 		}
-	case 212:
+	case 226:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:574
 		{
 			exprVAL.RangeOp = OpRangeTypeLast
 		}
-	case 213:
+	case 227:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:575
 		{
 			exprVAL.RangeOp = OpRangeTypeAbsent
 		}
-	case 214:
+	case 228:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:579
 		{
 			exprVAL.OffsetExpr = newOffsetExpr(exprDollar[2].duration)
 		}
-	case 215:
+	case 229:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:582
 		{
 			exprVAL.Labels = []string{exprDollar[1].str}
 		}
-	case 216:
+	case 230:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:583
 		{
 			exprVAL.Labels = append(exprDollar[1].Labels, exprDollar[3].str)
 		}
-	case 217:
+	case 231:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:587
 		{
 			exprVAL.Grouping = &Grouping{Without: false, Groups: exprDollar[3].Labels}
 		}
-	case 218:
+	case 232:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:588
 		{
 			exprVAL.Grouping = &Grouping{Without: true, Groups: exprDollar[3].Labels}
 		}
-	case 219:
+	case 233:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:589
 		{
 			exprVAL.Grouping = &Grouping{Without: false, Groups: nil}
 		}
-	case 220:
+	case 234:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:590
 		{
 			exprVAL.Grouping = &Grouping{Without: true, Groups: nil}
 		}
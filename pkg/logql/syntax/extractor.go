@@ -65,15 +65,19 @@ func (r RangeAggregationExpr) extractor(override *Grouping) (log.SampleExtractor
 		switch r.Left.Unwrap.Operation {
 		case OpConvBytes:
 			convOp = log.ConvertBytes
+		case OpConvBytesInt:
+			convOp = log.ConvertBytesInt
 		case OpConvDuration, OpConvDurationSeconds:
 			convOp = log.ConvertDuration
+		case OpConvToFloat:
+			convOp = log.ConvertToFloat
 		default:
 			convOp = log.ConvertFloat
 		}
 
-		return log.LabelExtractorWithStages(
+		return log.LabelExtractorWithUnit(
 			r.Left.Unwrap.Identifier,
-			convOp, groups, without, noLabels, stages,
+			convOp, r.Left.Unwrap.Unit, groups, without, noLabels, stages,
 			log.ReduceAndLabelFilter(r.Left.Unwrap.PostFilters),
 		)
 	}
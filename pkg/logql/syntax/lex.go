@@ -73,6 +73,7 @@ var tokens = map[string]int{
 	OpFilterIP:       IP,
 	OpDecolorize:     DECOLORIZE,
 	OpFilterDistinct: DISTINCT,
+	OpFilterSample:   SAMPLE,
 
 	// drop labels
 	OpDrop: DROP,
@@ -115,10 +116,15 @@ var functionTokens = map[string]int{
 	OpTypeSortDesc: SORT_DESC,
 	OpLabelReplace: LABEL_REPLACE,
 
+	// approximate vec ops
+	OpTypeApproxTopK: APPROX_TOPK,
+
 	// conversion Op
 	OpConvBytes:           BYTES_CONV,
+	OpConvBytesInt:        BYTES_INT_CONV,
 	OpConvDuration:        DURATION_CONV,
 	OpConvDurationSeconds: DURATION_SECONDS_CONV,
+	OpConvToFloat:         TO_FLOAT_CONV,
 
 	// filterOp
 	OpFilterIP: IP,
@@ -128,9 +134,34 @@ type lexer struct {
 	Scanner
 	errs    []logqlmodel.ParseError
 	builder strings.Builder
+
+	// prev is the previous token, and jsonExpr tells if the tokens are the expressions of a json parser,
+	// whose unquoted JSON paths are scanned as identifiers, e.g. | json server=servers[0].name.
+	prev     int
+	jsonExpr bool
+}
+
+// reset resets the state of the lexer, before parsing a new query.
+func (l *lexer) reset() {
+	l.errs = l.errs[:0]
+	l.prev = 0
+	l.jsonExpr = false
 }
 
 func (l *lexer) Lex(lval *exprSymType) int {
+	tok := l.lex(lval)
+	switch tok {
+	case JSON:
+		l.jsonExpr = true
+	case IDENTIFIER, EQ, STRING, COMMA:
+	default:
+		l.jsonExpr = false
+	}
+	l.prev = tok
+	return tok
+}
+
+func (l *lexer) lex(lval *exprSymType) int {
 	r := l.Scan()
 
 	switch r {
@@ -140,11 +171,17 @@ func (l *lexer) Lex(lval *exprSymType) int {
 		for next := l.Peek(); !(next == '\n' || next == scanner.EOF); next = l.Next() {
 		}
 
-		return l.Lex(lval)
+		return l.lex(lval)
 
 	case scanner.EOF:
 		return 0
 
+	case scanner.Ident:
+		if l.jsonExpr && l.prev == EQ {
+			lval.str = l.TokenText() + l.scanJSONPath()
+			return IDENTIFIER
+		}
+
 	case scanner.Int, scanner.Float:
 		numberText := l.TokenText()
 
@@ -168,6 +205,13 @@ func (l *lexer) Lex(lval *exprSymType) int {
 			lval.duration = duration
 			return DURATION
 		}
+		// handle the flags of the logfmt parser, e.g. --strict
+		if l.prev == LOGFMT || l.prev == PARSER_FLAG {
+			if flag, ok := tryScanParserFlag(&l.Scanner); ok {
+				lval.str = flag
+				return PARSER_FLAG
+			}
+		}
 
 	case scanner.String, scanner.RawString:
 		var err error
@@ -189,6 +233,9 @@ func (l *lexer) Lex(lval *exprSymType) int {
 		l.builder.Reset()
 		for r := l.Next(); r != scanner.EOF; r = l.Next() {
 			if r == ']' {
+				if rng, step, ok := strings.Cut(l.builder.String(), ":"); ok {
+					return l.subqueryRange(lval, rng, step)
+				}
 				i, err := model.ParseDuration(l.builder.String())
 				if err != nil {
 					l.Error(err.Error())
@@ -236,6 +283,89 @@ func (l *lexer) Lex(lval *exprSymType) int {
 	return IDENTIFIER
 }
 
+// scanJSONPath scans the rest of an unquoted JSON path following its first field,
+// e.g. [0].name of servers[0].name or ["response.ms"] of metrics["response.ms"].
+// Brackets holding anything else than an index or a quoted key, like a range, are not part of the path.
+func (l *lexer) scanJSONPath() string {
+	var sb strings.Builder
+	for {
+		switch l.Peek() {
+		case '.':
+			_, _ = sb.WriteRune(l.Next())
+			for r := l.Peek(); r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r); r = l.Peek() {
+				_, _ = sb.WriteRune(l.Next())
+			}
+		case '[':
+			access, ok := scanJSONPathAccess(l.Scanner)
+			if !ok {
+				return sb.String()
+			}
+			for range access {
+				_ = l.Next()
+			}
+			_, _ = sb.WriteString(access)
+		default:
+			return sb.String()
+		}
+	}
+}
+
+// scanJSONPathAccess returns the index or the key access starting at the bracket of the scanner, e.g. [0] or ["key"].
+// It scans a copy of the scanner, so the access can be consumed only if it is one.
+func scanJSONPathAccess(sc Scanner) (string, bool) {
+	var sb strings.Builder
+	_, _ = sb.WriteRune(sc.Next())
+	switch r := sc.Peek(); {
+	case unicode.IsDigit(r):
+		for ; unicode.IsDigit(r); r = sc.Peek() {
+			_, _ = sb.WriteRune(sc.Next())
+		}
+	case r == '"':
+		_, _ = sb.WriteRune(sc.Next())
+		for r = sc.Next(); r != '"'; r = sc.Next() {
+			if r == scanner.EOF || r == '\n' {
+				return "", false
+			}
+			_, _ = sb.WriteRune(r)
+			if r == '\\' {
+				_, _ = sb.WriteRune(sc.Next())
+			}
+		}
+		_, _ = sb.WriteRune(r)
+	default:
+		return "", false
+	}
+	if sc.Next() != ']' {
+		return "", false
+	}
+	_, _ = sb.WriteRune(']')
+	return sb.String(), true
+}
+
+// subqueryRange scans the range and the resolution of a subquery, e.g. [1h:1m].
+func (l *lexer) subqueryRange(lval *exprSymType, rng, step string) int {
+	r, err := model.ParseDuration(rng)
+	if err != nil {
+		l.Error(err.Error())
+		return 0
+	}
+	if step == "" {
+		l.Error("missing resolution in subquery range")
+		return 0
+	}
+	s, err := model.ParseDuration(step)
+	if err != nil {
+		l.Error(err.Error())
+		return 0
+	}
+	if s == 0 {
+		l.Error("zero resolution in subquery range")
+		return 0
+	}
+	lval.subqueryRange = subqueryRange{Range: time.Duration(r), Step: time.Duration(s)}
+	return SUBQUERY_RANGE
+}
+
 func (l *lexer) Error(msg string) {
 	l.errs = append(l.errs, logqlmodel.NewParseError(msg, l.Line, l.Column))
 }
@@ -273,6 +403,22 @@ func tryScanDuration(number string, l *Scanner) (time.Duration, bool) {
 	return duration, true
 }
 
+// tryScanParserFlag scans a parser flag following the first '-', like --strict.
+func tryScanParserFlag(l *Scanner) (string, bool) {
+	// copy the scanner to avoid advancing it in case it's not a flag.
+	s := *l
+	if s.Next() != '-' || !unicode.IsLetter(s.Peek()) {
+		return "", false
+	}
+	var sb strings.Builder
+	sb.WriteString("--")
+	for r := s.Peek(); unicode.IsLetter(r) || r == '-'; r = s.Peek() {
+		_, _ = sb.WriteRune(s.Next())
+	}
+	*l = s
+	return sb.String(), true
+}
+
 func parseDuration(d string) (time.Duration, error) {
 	var duration time.Duration
 	// Try to parse promql style durations first, to ensure that we support the same duration
@@ -51,7 +51,7 @@ type parser struct {
 }
 
 func (p *parser) Parse() (Expr, error) {
-	p.lexer.errs = p.lexer.errs[:0]
+	p.lexer.reset()
 	p.lexer.Scanner.Error = func(_ *Scanner, msg string) {
 		p.lexer.Error(msg)
 	}
@@ -181,6 +181,11 @@ func validateSampleExpr(expr SampleExpr) error {
 			}
 		}
 		return validateSampleExpr(e.Left)
+	case *SubqueryExpr:
+		if e.err != nil {
+			return e.err
+		}
+		return validateSampleExpr(e.Left)
 	default:
 		selector, err := e.Selector()
 		if err != nil {
@@ -52,6 +52,51 @@ func TestParse(t *testing.T) {
 				},
 			),
 		},
+		{
+			in: `{ foo = "bar" } | sample 0.01 | logfmt`,
+			exp: newPipelineExpr(
+				newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+				MultiStageExpr{
+					&SampleFilterExpr{Rate: 0.01},
+					newLabelParserExpr(OpParserTypeLogfmt, ""),
+				},
+			),
+		},
+		{
+			in: `{ foo = "bar" } | logfmt --strict --keep-empty | level="error"`,
+			exp: newPipelineExpr(
+				newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+				MultiStageExpr{
+					&LabelParserExpr{Op: OpParserTypeLogfmt, Strict: true, KeepEmpty: true},
+					newLabelFilterExpr(log.NewStringLabelFilter(mustNewMatcher(labels.MatchEqual, "level", "error"))),
+				},
+			),
+		},
+		{
+			in: `sum(count_over_time({ foo = "bar" } | logfmt --strict [5m]))`,
+			exp: mustNewVectorAggregationExpr(
+				newRangeAggregationExpr(
+					newLogRange(newPipelineExpr(
+						newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+						MultiStageExpr{&LabelParserExpr{Op: OpParserTypeLogfmt, Strict: true}},
+					), 5*time.Minute, nil, nil),
+					OpRangeTypeCount, nil, nil,
+				),
+				OpTypeSum, nil, nil,
+			),
+		},
+		{
+			in:  `{ foo = "bar" } | logfmt --lenient`,
+			err: logqlmodel.NewParseError("invalid logfmt parser flag --lenient", 0, 0),
+		},
+		{
+			in:  `{ foo = "bar" } | sample 0`,
+			err: logqlmodel.NewParseError("invalid sampling rate 0, it must be greater than 0 and at most 1", 0, 0),
+		},
+		{
+			in:  `{ foo = "bar" } | sample 1.5`,
+			err: logqlmodel.NewParseError("invalid sampling rate 1.5, it must be greater than 0 and at most 1", 0, 0),
+		},
 		{
 			// test [12h] before filter expr
 			in: `count_over_time({foo="bar"}[12h] |= "error")`,
@@ -309,6 +354,18 @@ func TestParse(t *testing.T) {
 				Groups:  []string{"bar"},
 			}, NewStringLabelFilter("10")),
 		},
+		{
+			in: `approx_topk(10,sum by (bar) (count_over_time({ foo = "bar" }[5h])))`,
+			exp: mustNewVectorAggregationExpr(mustNewVectorAggregationExpr(&RangeAggregationExpr{
+				Left: &LogRange{
+					Left:     &MatchersExpr{Mts: []*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}},
+					Interval: 5 * time.Hour,
+				},
+				Operation: "count_over_time",
+			}, "sum", &Grouping{
+				Groups: []string{"bar"},
+			}, nil), "approx_topk", nil, NewStringLabelFilter("10")),
+		},
 		{
 			in: `bottomk(30 ,sum(rate({ foo = "bar" }[5h])) by (foo))`,
 			exp: mustNewVectorAggregationExpr(mustNewVectorAggregationExpr(&RangeAggregationExpr{
@@ -359,6 +416,64 @@ func TestParse(t *testing.T) {
 			in:  `label_replace(rate({ foo = "bar" }[5m]),"foo","$1","bar","^^^^x43\\q")`,
 			err: logqlmodel.NewParseError("invalid regex in label_replace: error parsing regexp: invalid escape sequence: `\\q`", 0, 0),
 		},
+		{
+			in:  `approx_topk(2, sum by (foo) (rate({ foo = "bar" }[5m]))) by (foo)`,
+			err: logqlmodel.NewParseError("grouping not allowed for approx_topk aggregation, use it over a sum by aggregation instead", 0, 0),
+		},
+		{
+			in:  `label_replace(rate({ foo = "bar" }[5m]),"foo-bar","$1","bar","(.*)")`,
+			err: logqlmodel.NewParseError("invalid destination label name in label_replace: foo-bar", 0, 0),
+		},
+		{
+			in:  `rate({ foo = "bar" }[5m]) + bool rate({ foo = "bar" }[5m])`,
+			err: logqlmodel.NewParseError("bool modifier can only be used on comparison operators", 0, 0),
+		},
+		{
+			in:  `rate({ foo = "bar" }[5m]) * on (foo) 2`,
+			err: logqlmodel.NewParseError("vector matching only allowed between vectors", 0, 0),
+		},
+		{
+			in:  `rate({ foo = "bar" }[5m]) and on (foo) group_left rate({ foo = "bar" }[5m])`,
+			err: logqlmodel.NewParseError(`no grouping allowed for "and" operation`, 0, 0),
+		},
+		{
+			in:  `rate({ foo = "bar" }[5m]) / on (foo) group_left (foo) rate({ foo = "bar" }[5m])`,
+			err: logqlmodel.NewParseError(`label "foo" must not occur in ON and GROUP clause at once`, 0, 0),
+		},
+		{
+			in:  `bytes_over_time(rate({ foo = "bar" }[5m])[1h:1m])`,
+			err: logqlmodel.NewParseError("invalid aggregation bytes_over_time over a subquery", 0, 0),
+		},
+		{
+			in:  `max_over_time(rate({ foo = "bar" }[5m])[1h:])`,
+			err: logqlmodel.NewParseError("missing resolution in subquery range", 0, 40),
+		},
+		{
+			in: `max_over_time(rate({ foo = "bar" }[5m])[1h:1m] offset 5m)`,
+			exp: &SubqueryExpr{
+				Left: newRangeAggregationExpr(
+					newLogRange(newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}), 5*time.Minute, nil, nil),
+					OpRangeTypeRate, nil, nil,
+				),
+				Operation: OpRangeTypeMax,
+				Range:     time.Hour,
+				Step:      time.Minute,
+				Offset:    5 * time.Minute,
+			},
+		},
+		{
+			in: `quantile_over_time(0.99, sum(rate({ foo = "bar" }[5m]))[1h:5m])`,
+			exp: newSubqueryExpr(
+				mustNewVectorAggregationExpr(
+					newRangeAggregationExpr(
+						newLogRange(newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}), 5*time.Minute, nil, nil),
+						OpRangeTypeRate, nil, nil,
+					),
+					OpTypeSum, &Grouping{}, nil,
+				),
+				OpRangeTypeQuantile, subqueryRange{Range: time.Hour, Step: 5 * time.Minute}, nil, NewStringLabelFilter("0.99"),
+			),
+		},
 		{
 			in:  `rate({ foo = "bar" }[5)`,
 			err: logqlmodel.NewParseError("missing closing ']' in duration", 0, 21),
@@ -1718,6 +1833,51 @@ func TestParse(t *testing.T) {
 				OpRangeTypeSum, nil, nil,
 			),
 		},
+		{
+			in: `sum_over_time({namespace="tns"} | logfmt | unwrap to_float(foo, "ms") [5m])`,
+			exp: newRangeAggregationExpr(
+				newLogRange(&PipelineExpr{
+					Left: newMatcherExpr([]*labels.Matcher{{Type: labels.MatchEqual, Name: "namespace", Value: "tns"}}),
+					MultiStages: MultiStageExpr{
+						newLabelParserExpr(OpParserTypeLogfmt, ""),
+					},
+				},
+					5*time.Minute,
+					newUnwrapExprWithUnit("foo", OpConvToFloat, "ms"),
+					nil),
+				OpRangeTypeSum, nil, nil,
+			),
+		},
+		{
+			in: `sum_over_time({namespace="tns"} | logfmt | unwrap bytes_int(foo) [5m])`,
+			exp: newRangeAggregationExpr(
+				newLogRange(&PipelineExpr{
+					Left: newMatcherExpr([]*labels.Matcher{{Type: labels.MatchEqual, Name: "namespace", Value: "tns"}}),
+					MultiStages: MultiStageExpr{
+						newLabelParserExpr(OpParserTypeLogfmt, ""),
+					},
+				},
+					5*time.Minute,
+					newUnwrapExpr("foo", OpConvBytesInt),
+					nil),
+				OpRangeTypeSum, nil, nil,
+			),
+		},
+		{
+			in: `sum_over_time({namespace="tns"} | logfmt | unwrap to_float(foo) [5m])`,
+			exp: newRangeAggregationExpr(
+				newLogRange(&PipelineExpr{
+					Left: newMatcherExpr([]*labels.Matcher{{Type: labels.MatchEqual, Name: "namespace", Value: "tns"}}),
+					MultiStages: MultiStageExpr{
+						newLabelParserExpr(OpParserTypeLogfmt, ""),
+					},
+				},
+					5*time.Minute,
+					newUnwrapExpr("foo", OpConvToFloat),
+					nil),
+				OpRangeTypeSum, nil, nil,
+			),
+		},
 		{
 			in: `sum_over_time({namespace="tns"} |= "level=error" | json |foo>=5,bar<25ms| unwrap bytes(foo) [5m] offset 5m)`,
 			exp: newRangeAggregationExpr(
@@ -2872,7 +3032,7 @@ func TestParse(t *testing.T) {
 		},
 		{
 			in:  `quantile_over_time(foo,{namespace="tns"} |= "level=error" | json |foo>=5,bar<25ms| unwrap latency [5m])`,
-			err: logqlmodel.NewParseError("syntax error: unexpected IDENTIFIER, expecting NUMBER or { or (", 1, 20),
+			err: logqlmodel.NewParseError("syntax error: unexpected IDENTIFIER", 1, 20),
 		},
 		{
 			in:  `vector(abc)`,
@@ -3007,6 +3167,34 @@ func TestParse(t *testing.T) {
 				},
 			},
 		},
+		{
+			in: `{app="foo"} | json server=servers[0].name, latency=metrics["response.ms"], ua=request.headers["User-\"Agent\""], code=status`,
+			exp: &PipelineExpr{
+				Left: newMatcherExpr([]*labels.Matcher{{Type: labels.MatchEqual, Name: "app", Value: "foo"}}),
+				MultiStages: MultiStageExpr{
+					newJSONExpressionParser([]log.LabelExtractionExpr{
+						log.NewLabelExtractionExpr("server", `servers[0].name`),
+						log.NewLabelExtractionExpr("latency", `metrics["response.ms"]`),
+						log.NewLabelExtractionExpr("ua", `request.headers["User-\"Agent\""]`),
+						log.NewLabelExtractionExpr("code", `status`),
+					}),
+				},
+			},
+		},
+		{
+			in: `count_over_time({app="foo"} | json latency=metrics.latency[5m])`,
+			exp: newRangeAggregationExpr(
+				newLogRange(&PipelineExpr{
+					Left: newMatcherExpr([]*labels.Matcher{{Type: labels.MatchEqual, Name: "app", Value: "foo"}}),
+					MultiStages: MultiStageExpr{
+						newJSONExpressionParser([]log.LabelExtractionExpr{
+							log.NewLabelExtractionExpr("latency", `metrics.latency`),
+						}),
+					},
+				}, 5*time.Minute, nil, nil),
+				OpRangeTypeCount, nil, nil,
+			),
+		},
 		{
 			in: `count_over_time({ foo ="bar" } | json layer7_something_specific="layer7_something_specific" [12m])`,
 			exp: &RangeAggregationExpr{
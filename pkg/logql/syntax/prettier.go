@@ -335,6 +335,36 @@ func (e *LabelReplaceExpr) Pretty(level int) string {
 	return s
 }
 
+// e.g: max_over_time(rate({app="foo"}[1m])[1h:1m])
+func (e *SubqueryExpr) Pretty(level int) string {
+	s := indent(level)
+
+	if !needSplit(e) {
+		return s + e.String()
+	}
+
+	s += e.Operation
+
+	s += "(\n"
+
+	if e.Params != nil {
+		s = fmt.Sprintf("%s%s%s,", s, indent(level+1), fmt.Sprint(*e.Params))
+		s += "\n"
+	}
+
+	s += e.Left.Pretty(level + 1)
+
+	s = fmt.Sprintf("%s [%s:%s]", s, model.Duration(e.Range), model.Duration(e.Step))
+	if e.Offset != 0 {
+		oe := OffsetExpr{Offset: e.Offset}
+		s += oe.Pretty(level)
+	}
+
+	s += "\n" + indent(level) + ")"
+
+	return s
+}
+
 // e.g: vector(5)
 func (e *VectorExpr) Pretty(level int) string {
 	return commonPrefixIndent(level, e)
@@ -344,6 +374,10 @@ func (e *DistinctFilterExpr) Pretty(level int) string {
 	return commonPrefixIndent(level, e)
 }
 
+func (e *SampleFilterExpr) Pretty(level int) string {
+	return commonPrefixIndent(level, e)
+}
+
 // Grouping is technically not expression type. But used in both range and vector aggregations (`by` and `without` clause)
 // So by implenting `Pretty` for Grouping, we can re use it for both.
 // NOTE: indent is ignored for `Grouping`, because grouping always stays in the same line of it's parent expression.
@@ -200,6 +200,35 @@ func TestFormat_LabelReplace(t *testing.T) {
 	}
 }
 
+func TestFormat_Subquery(t *testing.T) {
+	maxCharsPerLine = 20
+
+	cases := []struct {
+		name string
+		in   string
+		exp  string
+	}{
+		{
+			name: "max_over_time",
+			in:   `max_over_time(rate({foo="bar",namespace="loki"}[5m])[1h:1m] offset 1h)`,
+			exp: `max_over_time(
+  rate(
+    {foo="bar", namespace="loki"} [5m]
+  ) [1h:1m] offset 1h
+)`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			expr, err := ParseExpr(c.in)
+			require.NoError(t, err)
+			got := Prettify(expr)
+			assert.Equal(t, c.exp, got)
+		})
+	}
+}
+
 func TestFormat_BinOp(t *testing.T) {
 	maxCharsPerLine = 20
 
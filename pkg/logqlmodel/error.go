@@ -33,6 +33,12 @@ func (p ParseError) Error() string {
 	return fmt.Sprintf("parse error at line %d, col %d: %s", p.line, p.col, p.msg)
 }
 
+// Line returns the line of the error in the query, starting at 1, or 0 when it's unknown.
+func (p ParseError) Line() int { return p.line }
+
+// Col returns the column of the error in the query, starting at 1, or 0 when it's unknown.
+func (p ParseError) Col() int { return p.col }
+
 // Is allows to use errors.Is(err,ErrParse) on this error.
 func (p ParseError) Is(target error) bool {
 	return target == ErrParse
@@ -48,6 +48,8 @@ type Context struct {
 	store Store
 	// result accumulates results for JoinResult.
 	result Result
+	// samplingRate is the fraction of the log lines kept by the sample stages of the query.
+	samplingRate float64
 
 	mtx sync.Mutex
 }
@@ -55,11 +57,13 @@ type Context struct {
 type CacheType string
 
 const (
-	ChunkCache       CacheType = "chunk" //nolint:staticcheck
-	IndexCache                 = "index"
-	ResultCache                = "result"
-	StatsResultCache           = "stats-result"
-	WriteDedupeCache           = "write-dedupe"
+	ChunkCache        CacheType = "chunk" //nolint:staticcheck
+	IndexCache                  = "index"
+	ResultCache                 = "result"
+	StatsResultCache            = "stats-result"
+	SeriesResultCache           = "series-result"
+	LabelResultCache            = "label-result"
+	WriteDedupeCache            = "write-dedupe"
 )
 
 // NewContext creates a new statistics context
@@ -92,9 +96,12 @@ func (c *Context) Ingester() Ingester {
 // Caches returns the cache statistics accumulated so far.
 func (c *Context) Caches() Caches {
 	return Caches{
-		Chunk:  c.caches.Chunk,
-		Index:  c.caches.Index,
-		Result: c.caches.Result,
+		Chunk:        c.caches.Chunk,
+		Index:        c.caches.Index,
+		Result:       c.caches.Result,
+		StatsResult:  c.caches.StatsResult,
+		SeriesResult: c.caches.SeriesResult,
+		LabelResult:  c.caches.LabelResult,
 	}
 }
 
@@ -108,6 +115,7 @@ func (c *Context) Reset() {
 	c.ingester.Reset()
 	c.result.Reset()
 	c.caches.Reset()
+	c.samplingRate = 0
 }
 
 // Result calculates the summary based on store and ingester data.
@@ -120,6 +128,9 @@ func (c *Context) Result(execTime time.Duration, queueTime time.Duration, totalE
 		},
 		Ingester: c.ingester,
 		Caches:   c.caches,
+		Summary: Summary{
+			SamplingRate: c.samplingRate,
+		},
 	})
 
 	r.ComputeSummary(execTime, queueTime, totalEntriesReturned)
@@ -180,6 +191,9 @@ func (s *Store) Merge(m Store) {
 func (s *Summary) Merge(m Summary) {
 	s.Splits += m.Splits
 	s.Shards += m.Shards
+	if m.SamplingRate > 0 && (s.SamplingRate == 0 || m.SamplingRate < s.SamplingRate) {
+		s.SamplingRate = m.SamplingRate
+	}
 }
 
 func (q *Querier) Merge(m Querier) {
@@ -198,6 +212,9 @@ func (c *Caches) Merge(m Caches) {
 	c.Chunk.Merge(m.Chunk)
 	c.Index.Merge(m.Index)
 	c.Result.Merge(m.Result)
+	c.StatsResult.Merge(m.StatsResult)
+	c.SeriesResult.Merge(m.SeriesResult)
+	c.LabelResult.Merge(m.LabelResult)
 }
 
 func (c *Cache) Merge(m Cache) {
@@ -259,6 +276,13 @@ func (r Result) TotalDecompressedLines() int64 {
 	return r.Querier.Store.Chunk.DecompressedLines + r.Ingester.Store.Chunk.DecompressedLines
 }
 
+// SetSamplingRate records the fraction of the log lines kept by the sample stages of the query.
+func (c *Context) SetSamplingRate(rate float64) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.samplingRate = rate
+}
+
 func (c *Context) AddIngesterBatch(size int64) {
 	atomic.AddInt64(&c.ingester.TotalBatches, 1)
 	atomic.AddInt64(&c.ingester.TotalLinesSent, size)
@@ -391,6 +415,12 @@ func (c *Context) getCacheStatsByType(t CacheType) *Cache {
 		stats = &c.caches.Index
 	case ResultCache:
 		stats = &c.caches.Result
+	case StatsResultCache:
+		stats = &c.caches.StatsResult
+	case SeriesResultCache:
+		stats = &c.caches.SeriesResult
+	case LabelResultCache:
+		stats = &c.caches.LabelResult
 	default:
 		return nil
 	}
@@ -436,6 +466,10 @@ func (s Summary) Log(log log.Logger) {
 		"Summary.TotalLinesProcessed", s.TotalLinesProcessed,
 		"Summary.ExecTime", ConvertSecondsToNanoseconds(s.ExecTime),
 		"Summary.QueueTime", ConvertSecondsToNanoseconds(s.QueueTime),
+		"Summary.Subqueries", s.Subqueries,
+		"Summary.Splits", s.Splits,
+		"Summary.Shards", s.Shards,
+		"Summary.SamplingRate", s.SamplingRate,
 	)
 }
 
@@ -462,5 +496,20 @@ func (c Caches) Log(log log.Logger) {
 		"Cache.Result.BytesSent", humanize.Bytes(uint64(c.Result.BytesSent)),
 		"Cache.Result.BytesReceived", humanize.Bytes(uint64(c.Result.BytesReceived)),
 		"Cache.Result.DownloadTime", c.Result.CacheDownloadTime(),
+		"Cache.StatsResult.Requests", c.StatsResult.Requests,
+		"Cache.StatsResult.EntriesRequested", c.StatsResult.EntriesRequested,
+		"Cache.StatsResult.EntriesFound", c.StatsResult.EntriesFound,
+		"Cache.StatsResult.EntriesStored", c.StatsResult.EntriesStored,
+		"Cache.StatsResult.DownloadTime", c.StatsResult.CacheDownloadTime(),
+		"Cache.SeriesResult.Requests", c.SeriesResult.Requests,
+		"Cache.SeriesResult.EntriesRequested", c.SeriesResult.EntriesRequested,
+		"Cache.SeriesResult.EntriesFound", c.SeriesResult.EntriesFound,
+		"Cache.SeriesResult.EntriesStored", c.SeriesResult.EntriesStored,
+		"Cache.SeriesResult.DownloadTime", c.SeriesResult.CacheDownloadTime(),
+		"Cache.LabelResult.Requests", c.LabelResult.Requests,
+		"Cache.LabelResult.EntriesRequested", c.LabelResult.EntriesRequested,
+		"Cache.LabelResult.EntriesFound", c.LabelResult.EntriesFound,
+		"Cache.LabelResult.EntriesStored", c.LabelResult.EntriesStored,
+		"Cache.LabelResult.DownloadTime", c.LabelResult.CacheDownloadTime(),
 	)
 }
@@ -291,6 +291,24 @@ func TestReset(t *testing.T) {
 	require.Empty(t, res)
 }
 
+func TestSamplingRate(t *testing.T) {
+	statsCtx, _ := NewContext(context.Background())
+	require.Equal(t, 0., statsCtx.Result(0, 0, 0).Summary.SamplingRate)
+
+	statsCtx.SetSamplingRate(0.5)
+	res := statsCtx.Result(0, 0, 0)
+	require.Equal(t, 0.5, res.Summary.SamplingRate)
+
+	// merging keeps the smallest rate of the sampled results.
+	res.Merge(Result{})
+	require.Equal(t, 0.5, res.Summary.SamplingRate)
+	res.Merge(Result{Summary: Summary{SamplingRate: 0.1}})
+	require.Equal(t, 0.1, res.Summary.SamplingRate)
+
+	statsCtx.Reset()
+	require.Equal(t, 0., statsCtx.Result(0, 0, 0).Summary.SamplingRate)
+}
+
 func TestIngester(t *testing.T) {
 	statsCtx, ctx := NewContext(context.Background())
 	fakeIngesterQuery(ctx)
@@ -321,6 +339,10 @@ func TestCaches(t *testing.T) {
 	statsCtx.AddCacheBytesRetrieved(ChunkCache, 1024)
 	statsCtx.AddCacheBytesSent(ChunkCache, 512)
 	statsCtx.AddCacheEntriesFound(IndexCache, 2)
+	statsCtx.AddCacheEntriesRequested(StatsResultCache, 4)
+	statsCtx.AddCacheEntriesFound(StatsResultCache, 3)
+	statsCtx.AddCacheEntriesStored(SeriesResultCache, 1)
+	statsCtx.AddCacheRequest(LabelResultCache, 2)
 
 	require.Equal(t, Caches{
 		Chunk: Cache{
@@ -335,5 +357,15 @@ func TestCaches(t *testing.T) {
 		Result: Cache{
 			EntriesStored: 3,
 		},
+		StatsResult: Cache{
+			EntriesRequested: 4,
+			EntriesFound:     3,
+		},
+		SeriesResult: Cache{
+			EntriesStored: 1,
+		},
+		LabelResult: Cache{
+			Requests: 2,
+		},
 	}, statsCtx.Caches())
 }
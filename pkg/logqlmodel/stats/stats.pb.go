@@ -95,9 +95,12 @@ func (m *Result) GetCaches() Caches {
 }
 
 type Caches struct {
-	Chunk  Cache `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk"`
-	Index  Cache `protobuf:"bytes,2,opt,name=index,proto3" json:"index"`
-	Result Cache `protobuf:"bytes,3,opt,name=result,proto3" json:"result"`
+	Chunk        Cache `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk"`
+	Index        Cache `protobuf:"bytes,2,opt,name=index,proto3" json:"index"`
+	Result       Cache `protobuf:"bytes,3,opt,name=result,proto3" json:"result"`
+	StatsResult  Cache `protobuf:"bytes,4,opt,name=statsResult,proto3" json:"statsResult"`
+	SeriesResult Cache `protobuf:"bytes,5,opt,name=seriesResult,proto3" json:"seriesResult"`
+	LabelResult  Cache `protobuf:"bytes,6,opt,name=labelResult,proto3" json:"labelResult"`
 }
 
 func (m *Caches) Reset()      { *m = Caches{} }
@@ -153,6 +156,27 @@ func (m *Caches) GetResult() Cache {
 	return Cache{}
 }
 
+func (m *Caches) GetStatsResult() Cache {
+	if m != nil {
+		return m.StatsResult
+	}
+	return Cache{}
+}
+
+func (m *Caches) GetSeriesResult() Cache {
+	if m != nil {
+		return m.SeriesResult
+	}
+	return Cache{}
+}
+
+func (m *Caches) GetLabelResult() Cache {
+	if m != nil {
+		return m.LabelResult
+	}
+	return Cache{}
+}
+
 // Summary is the summary of a query statistics.
 type Summary struct {
 	// Total bytes processed per second.
@@ -178,6 +202,8 @@ type Summary struct {
 	TotalEntriesReturned int64 `protobuf:"varint,8,opt,name=totalEntriesReturned,proto3" json:"totalEntriesReturned"`
 	Splits               int64 `protobuf:"varint,9,opt,name=splits,proto3" json:"splits"`
 	Shards               int64 `protobuf:"varint,10,opt,name=shards,proto3" json:"shards"`
+	// Fraction of the log lines kept by the sample stages of the query, if any.
+	SamplingRate float64 `protobuf:"fixed64,11,opt,name=samplingRate,proto3" json:"samplingRate,omitempty"`
 }
 
 func (m *Summary) Reset()      { *m = Summary{} }
@@ -282,6 +308,13 @@ func (m *Summary) GetShards() int64 {
 	return 0
 }
 
+func (m *Summary) GetSamplingRate() float64 {
+	if m != nil {
+		return m.SamplingRate
+	}
+	return 0
+}
+
 type Querier struct {
 	Store Store `protobuf:"bytes,1,opt,name=store,proto3" json:"store"`
 }
@@ -668,68 +701,73 @@ func init() {
 func init() { proto.RegisterFile("pkg/logqlmodel/stats/stats.proto", fileDescriptor_6cdfe5d2aea33ebb) }
 
 var fileDescriptor_6cdfe5d2aea33ebb = []byte{
-	// 967 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x56, 0xcf, 0x6f, 0xe3, 0x44,
-	0x14, 0x8e, 0x9b, 0x3a, 0x69, 0x87, 0xfe, 0xda, 0xd9, 0x2e, 0x6b, 0x40, 0xb2, 0xab, 0x9c, 0x2a,
-	0x81, 0x1a, 0xf1, 0x43, 0x42, 0x20, 0x56, 0x42, 0xee, 0xb2, 0x52, 0xa5, 0x45, 0x2c, 0xaf, 0x70,
-	0xe1, 0xe6, 0xd8, 0xb3, 0x89, 0x55, 0xc7, 0x4e, 0x3d, 0x36, 0xec, 0xde, 0xb8, 0x71, 0x84, 0x3f,
-	0x82, 0x03, 0x17, 0xfe, 0x8f, 0x3d, 0xf6, 0xb8, 0x27, 0x8b, 0xa6, 0x17, 0xe4, 0xd3, 0x4a, 0xdc,
-	0x11, 0x9a, 0x37, 0x13, 0xdb, 0xe3, 0x38, 0x12, 0x97, 0xcc, 0xbc, 0xef, 0x7b, 0xdf, 0xcc, 0xf3,
-	0xcc, 0x7b, 0x6f, 0x42, 0x4e, 0x16, 0x57, 0xd3, 0x71, 0x94, 0x4c, 0xaf, 0xa3, 0x79, 0x12, 0xb0,
-	0x68, 0xcc, 0x33, 0x2f, 0xe3, 0xf2, 0xf7, 0x6c, 0x91, 0x26, 0x59, 0x42, 0x4d, 0x34, 0xde, 0x3d,
-	0x9e, 0x26, 0xd3, 0x04, 0x91, 0xb1, 0x98, 0x49, 0x72, 0xf4, 0x8f, 0x41, 0x06, 0xc0, 0x78, 0x1e,
-	0x65, 0xf4, 0x33, 0x32, 0xe4, 0xf9, 0x7c, 0xee, 0xa5, 0x2f, 0x2d, 0xe3, 0xc4, 0x38, 0x7d, 0xeb,
-	0xa3, 0x83, 0x33, 0xb9, 0xcc, 0xa5, 0x44, 0xdd, 0xc3, 0x57, 0x85, 0xd3, 0x2b, 0x0b, 0x67, 0xe5,
-	0x06, 0xab, 0x89, 0x90, 0x5e, 0xe7, 0x2c, 0x0d, 0x59, 0x6a, 0x6d, 0x69, 0xd2, 0x6f, 0x25, 0x5a,
-	0x4b, 0x95, 0x1b, 0xac, 0x26, 0xf4, 0x11, 0xd9, 0x09, 0xe3, 0x29, 0xe3, 0x19, 0x4b, 0xad, 0x3e,
-	0x6a, 0x0f, 0x95, 0xf6, 0x42, 0xc1, 0xee, 0x91, 0x12, 0x57, 0x8e, 0x50, 0xcd, 0xe8, 0x27, 0x64,
-	0xe0, 0x7b, 0xfe, 0x8c, 0x71, 0x6b, 0x1b, 0xc5, 0xfb, 0x4a, 0x7c, 0x8e, 0xa0, 0xbb, 0xaf, 0xa4,
-	0x26, 0x3a, 0x81, 0xf2, 0x1d, 0xfd, 0x6e, 0x90, 0x81, 0xf4, 0xa0, 0x1f, 0x12, 0xd3, 0x9f, 0xe5,
-	0xf1, 0x95, 0xfa, 0xe6, 0xbd, 0xa6, 0xbe, 0x21, 0x17, 0x2e, 0x20, 0x07, 0x21, 0x09, 0xe3, 0x80,
-	0xbd, 0x50, 0xdf, 0xba, 0x41, 0x82, 0x2e, 0x20, 0x07, 0x11, 0x66, 0x8a, 0xa7, 0xac, 0xbe, 0x51,
-	0xd7, 0x1c, 0x28, 0x8d, 0xf2, 0x01, 0x35, 0x8e, 0xca, 0x6d, 0x32, 0x54, 0x87, 0x4f, 0xbf, 0x27,
-	0x0f, 0x27, 0x2f, 0x33, 0xc6, 0x9f, 0xa5, 0x89, 0xcf, 0x38, 0x67, 0xc1, 0x33, 0x96, 0x5e, 0x32,
-	0x3f, 0x89, 0x03, 0x8c, 0xbc, 0xef, 0xbe, 0x57, 0x16, 0xce, 0x26, 0x17, 0xd8, 0x44, 0x88, 0x65,
-	0xa3, 0x30, 0xee, 0x5c, 0x76, 0xab, 0x5e, 0x76, 0x83, 0x0b, 0x6c, 0x22, 0xe8, 0x05, 0xb9, 0x9f,
-	0x25, 0x99, 0x17, 0xb9, 0xda, 0xb6, 0xf8, 0xf1, 0x7d, 0xf7, 0x61, 0x59, 0x38, 0x5d, 0x34, 0x74,
-	0x81, 0xd5, 0x52, 0x4f, 0xb5, 0xad, 0xf0, 0xba, 0x9b, 0x4b, 0xe9, 0x34, 0x74, 0x81, 0xf4, 0x94,
-	0xec, 0xb0, 0x17, 0xcc, 0xff, 0x2e, 0x9c, 0x33, 0xcb, 0x3c, 0x31, 0x4e, 0x0d, 0x77, 0x4f, 0xa4,
-	0xd5, 0x0a, 0x83, 0x6a, 0x46, 0xdf, 0x27, 0xbb, 0xd7, 0x39, 0xcb, 0x19, 0xba, 0x0e, 0xd0, 0x75,
-	0xbf, 0x2c, 0x9c, 0x1a, 0x84, 0x7a, 0x4a, 0xcf, 0x08, 0xe1, 0xf9, 0x44, 0x26, 0x34, 0xb7, 0x86,
-	0x18, 0xd8, 0x41, 0x59, 0x38, 0x0d, 0x14, 0x1a, 0x73, 0xfa, 0x94, 0x1c, 0x63, 0x74, 0x5f, 0xc5,
-	0x19, 0x72, 0x2c, 0xcb, 0xd3, 0x98, 0x05, 0xd6, 0x0e, 0x2a, 0xad, 0xb2, 0x70, 0x3a, 0x79, 0xe8,
-	0x44, 0xe9, 0x88, 0x0c, 0xf8, 0x22, 0x0a, 0x33, 0x6e, 0xed, 0xa2, 0x9e, 0x88, 0x44, 0x92, 0x08,
-	0xa8, 0x11, 0x7d, 0x66, 0x5e, 0x1a, 0x70, 0x8b, 0x34, 0x7c, 0x10, 0x01, 0x35, 0x8e, 0xbe, 0x20,
-	0x43, 0x55, 0xad, 0x22, 0xc1, 0x79, 0x96, 0xa4, 0xac, 0x55, 0x13, 0x97, 0x02, 0xab, 0x13, 0x1c,
-	0x5d, 0x40, 0x0e, 0xa3, 0x3f, 0xb7, 0xc8, 0xce, 0x45, 0x5d, 0x94, 0x7b, 0x18, 0x2a, 0x30, 0x91,
-	0xde, 0x32, 0x41, 0x4d, 0xf7, 0xa8, 0x2c, 0x1c, 0x0d, 0x07, 0xcd, 0xa2, 0x4f, 0x08, 0x45, 0xfb,
-	0x5c, 0x14, 0x19, 0xff, 0xda, 0xcb, 0x50, 0x2b, 0xb3, 0xf0, 0xed, 0xb2, 0x70, 0x3a, 0x58, 0xe8,
-	0xc0, 0xaa, 0xdd, 0x5d, 0xb4, 0xb9, 0x4a, 0xba, 0x7a, 0x77, 0x85, 0x83, 0x66, 0xd1, 0xcf, 0xc9,
-	0x41, 0x9d, 0x32, 0x97, 0x2c, 0xce, 0x54, 0x86, 0xd1, 0xb2, 0x70, 0x5a, 0x0c, 0xb4, 0xec, 0xfa,
-	0xbc, 0xcc, 0xff, 0x7d, 0x5e, 0xbf, 0x6e, 0x11, 0x13, 0xf9, 0x6a, 0x63, 0xf9, 0x11, 0xc0, 0x9e,
-	0xab, 0x7a, 0xae, 0x37, 0xae, 0x18, 0x68, 0xd9, 0xf4, 0x1b, 0xf2, 0xa0, 0x81, 0x3c, 0x4e, 0x7e,
-	0x8a, 0xa3, 0xc4, 0x0b, 0xaa, 0x53, 0x7b, 0xa7, 0x2c, 0x9c, 0x6e, 0x07, 0xe8, 0x86, 0xc5, 0x1d,
-	0xf8, 0x1a, 0x86, 0x05, 0xd0, 0xaf, 0xef, 0x60, 0x9d, 0x85, 0x0e, 0xac, 0xee, 0xaa, 0xdb, 0x7a,
-	0xbb, 0x13, 0x58, 0x77, 0x57, 0x1d, 0xfd, 0xd2, 0x27, 0x26, 0xf2, 0xe2, 0x44, 0x66, 0xcc, 0x0b,
-	0xa4, 0xb3, 0x68, 0x06, 0xcd, 0xab, 0xd0, 0x19, 0x68, 0xd9, 0x9a, 0x16, 0x2f, 0x08, 0xef, 0xa4,
-	0xad, 0x45, 0x06, 0x5a, 0x36, 0x3d, 0x27, 0xf7, 0x02, 0xe6, 0x27, 0xf3, 0x45, 0x8a, 0xed, 0x42,
-	0x6e, 0x3d, 0x40, 0xf9, 0x83, 0xb2, 0x70, 0xd6, 0x49, 0x58, 0x87, 0xda, 0x8b, 0xc8, 0x18, 0x86,
-	0xdd, 0x8b, 0xc8, 0x30, 0xd6, 0x21, 0xfa, 0x88, 0x1c, 0xb6, 0xe3, 0x90, 0xcd, 0xe1, 0x7e, 0x59,
-	0x38, 0x6d, 0x0a, 0xda, 0x80, 0x90, 0xe3, 0xf5, 0x3e, 0xce, 0x17, 0x51, 0xe8, 0x7b, 0x42, 0xbe,
-	0x5b, 0xcb, 0x5b, 0x14, 0xb4, 0x81, 0xd1, 0xbf, 0x5b, 0xc4, 0xc4, 0x87, 0x49, 0x94, 0x12, 0x93,
-	0xed, 0xe6, 0x49, 0x92, 0xc7, 0x5a, 0x21, 0x37, 0x71, 0xd0, 0x2c, 0xfa, 0x25, 0x39, 0x62, 0xab,
-	0x26, 0x75, 0x9d, 0x8b, 0x96, 0x20, 0x13, 0xd2, 0x74, 0x8f, 0xcb, 0xc2, 0x59, 0xe3, 0x60, 0x0d,
-	0xa1, 0x9f, 0x92, 0x7d, 0x85, 0x61, 0x8d, 0xc8, 0x87, 0xc3, 0x74, 0xef, 0x95, 0x85, 0xa3, 0x13,
-	0xa0, 0x9b, 0x42, 0x88, 0x2f, 0x1d, 0x30, 0x9f, 0x85, 0x3f, 0x56, 0xcf, 0x04, 0x0a, 0x35, 0x02,
-	0x74, 0x53, 0x34, 0x7c, 0x04, 0xb0, 0xf2, 0x65, 0xca, 0x60, 0xc3, 0xaf, 0x40, 0xa8, 0xa7, 0xe2,
-	0x1d, 0x49, 0x65, 0xac, 0x32, 0x3f, 0x4c, 0xf9, 0x8e, 0xac, 0x30, 0xa8, 0x66, 0xe2, 0x00, 0x83,
-	0x66, 0x25, 0x0d, 0xeb, 0x5e, 0xd4, 0xc4, 0x41, 0xb3, 0xdc, 0xc9, 0xcd, 0xad, 0xdd, 0x7b, 0x7d,
-	0x6b, 0xf7, 0xde, 0xdc, 0xda, 0xc6, 0xcf, 0x4b, 0xdb, 0xf8, 0x63, 0x69, 0x1b, 0xaf, 0x96, 0xb6,
-	0x71, 0xb3, 0xb4, 0x8d, 0xbf, 0x96, 0xb6, 0xf1, 0xf7, 0xd2, 0xee, 0xbd, 0x59, 0xda, 0xc6, 0x6f,
-	0x77, 0x76, 0xef, 0xe6, 0xce, 0xee, 0xbd, 0xbe, 0xb3, 0x7b, 0x3f, 0x7c, 0x30, 0x0d, 0xb3, 0x59,
-	0x3e, 0x39, 0xf3, 0x93, 0xf9, 0x78, 0x9a, 0x7a, 0xcf, 0xbd, 0xd8, 0x1b, 0x47, 0xc9, 0x55, 0x38,
-	0xee, 0xfa, 0x87, 0x38, 0x19, 0xe0, 0xff, 0xbf, 0x8f, 0xff, 0x0b, 0x00, 0x00, 0xff, 0xff, 0x8a,
-	0x9c, 0x39, 0x00, 0x40, 0x0a, 0x00, 0x00,
+	// 1045 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x56, 0xcd, 0x8f, 0xdb, 0x44,
+	0x14, 0x8f, 0x93, 0x3a, 0xd9, 0xce, 0x7e, 0x76, 0x76, 0xdb, 0x9a, 0x22, 0xd9, 0x55, 0x4e, 0x95,
+	0xa8, 0x36, 0xe2, 0x43, 0x42, 0x20, 0x8a, 0x90, 0xb7, 0xac, 0xb4, 0x52, 0x11, 0xe5, 0x2d, 0x5c,
+	0xb8, 0x39, 0xce, 0x34, 0xb1, 0xd6, 0xb1, 0xb3, 0x9e, 0x31, 0x74, 0x6f, 0xdc, 0x38, 0xc2, 0x9f,
+	0x81, 0x90, 0xf8, 0x3f, 0x7a, 0xdc, 0x63, 0x4f, 0x16, 0x9b, 0xbd, 0x20, 0x9f, 0x2a, 0x21, 0x71,
+	0x44, 0x68, 0xde, 0x4c, 0xfc, 0x15, 0x6f, 0xc5, 0x25, 0x33, 0xef, 0xf7, 0x7e, 0xbf, 0x37, 0xe3,
+	0x99, 0x79, 0xef, 0x85, 0x3c, 0x5c, 0x9c, 0x4d, 0x47, 0x61, 0x3c, 0x3d, 0x0f, 0xe7, 0xf1, 0x84,
+	0x85, 0x23, 0x2e, 0x3c, 0xc1, 0xd5, 0xef, 0xe1, 0x22, 0x89, 0x45, 0x4c, 0x4d, 0x34, 0x1e, 0x1c,
+	0x4c, 0xe3, 0x69, 0x8c, 0xc8, 0x48, 0xce, 0x94, 0x73, 0xf8, 0xb7, 0x41, 0xfa, 0xc0, 0x78, 0x1a,
+	0x0a, 0xfa, 0x09, 0x19, 0xf0, 0x74, 0x3e, 0xf7, 0x92, 0x0b, 0xcb, 0x78, 0x68, 0x3c, 0xda, 0xfc,
+	0x60, 0xe7, 0x50, 0x85, 0x39, 0x55, 0xa8, 0xbb, 0xfb, 0x2a, 0x73, 0x3a, 0x79, 0xe6, 0xac, 0x68,
+	0xb0, 0x9a, 0x48, 0xe9, 0x79, 0xca, 0x92, 0x80, 0x25, 0x56, 0xb7, 0x26, 0xfd, 0x46, 0xa1, 0xa5,
+	0x54, 0xd3, 0x60, 0x35, 0xa1, 0x4f, 0xc8, 0x46, 0x10, 0x4d, 0x19, 0x17, 0x2c, 0xb1, 0x7a, 0xa8,
+	0xdd, 0xd5, 0xda, 0x13, 0x0d, 0xbb, 0x7b, 0x5a, 0x5c, 0x10, 0xa1, 0x98, 0xd1, 0x8f, 0x48, 0xdf,
+	0xf7, 0xfc, 0x19, 0xe3, 0xd6, 0x2d, 0x14, 0x6f, 0x6b, 0xf1, 0x11, 0x82, 0xee, 0xb6, 0x96, 0x9a,
+	0x48, 0x02, 0xcd, 0x1d, 0xfe, 0xd3, 0x25, 0x7d, 0xc5, 0xa0, 0xef, 0x13, 0xd3, 0x9f, 0xa5, 0xd1,
+	0x99, 0xfe, 0xe6, 0xad, 0xaa, 0xbe, 0x22, 0x97, 0x14, 0x50, 0x83, 0x94, 0x04, 0xd1, 0x84, 0xbd,
+	0xb4, 0xba, 0x6f, 0x93, 0x20, 0x05, 0xd4, 0x20, 0xb7, 0x99, 0xe0, 0x29, 0x5b, 0xbd, 0x16, 0xcd,
+	0x8e, 0xd6, 0x68, 0x0e, 0xe8, 0x91, 0x1e, 0x91, 0x4d, 0xa4, 0xa9, 0x0b, 0xb2, 0x6e, 0xb5, 0x48,
+	0xf7, 0xb5, 0xb4, 0x4a, 0x84, 0xaa, 0x41, 0x8f, 0xc9, 0x16, 0x97, 0x47, 0xbd, 0x8a, 0x62, 0xb6,
+	0x44, 0x39, 0xd0, 0x51, 0x6a, 0x4c, 0xa8, 0x59, 0x72, 0x33, 0xa1, 0x37, 0x66, 0xa1, 0x0e, 0xd3,
+	0x7f, 0xdb, 0x66, 0x2a, 0x44, 0xa8, 0x1a, 0xc3, 0xdf, 0x4d, 0x32, 0xd0, 0xcf, 0x89, 0x7e, 0x47,
+	0xee, 0x8f, 0x2f, 0x04, 0xe3, 0xcf, 0x93, 0xd8, 0x67, 0x9c, 0xb3, 0xc9, 0x73, 0x96, 0x9c, 0x32,
+	0x3f, 0x8e, 0x26, 0x78, 0x17, 0x3d, 0xf7, 0xdd, 0x3c, 0x73, 0x6e, 0xa2, 0xc0, 0x4d, 0x0e, 0x19,
+	0x36, 0x0c, 0xa2, 0xd6, 0xb0, 0xdd, 0x32, 0xec, 0x0d, 0x14, 0xb8, 0xc9, 0x41, 0x4f, 0xc8, 0xbe,
+	0x88, 0x85, 0x17, 0xba, 0xb5, 0x65, 0xf1, 0x3a, 0x7b, 0xee, 0xfd, 0x3c, 0x73, 0xda, 0xdc, 0xd0,
+	0x06, 0x16, 0xa1, 0x9e, 0xd5, 0x96, 0xb2, 0x6e, 0x35, 0x42, 0xd5, 0xdd, 0xd0, 0x06, 0xd2, 0x47,
+	0x64, 0x83, 0xbd, 0x64, 0xfe, 0xb7, 0xc1, 0x9c, 0xe1, 0xc5, 0x1a, 0xee, 0x96, 0x4c, 0x94, 0x15,
+	0x06, 0xc5, 0x8c, 0xbe, 0x47, 0x6e, 0x9f, 0xa7, 0x2c, 0x65, 0x48, 0xed, 0x23, 0x75, 0x3b, 0xcf,
+	0x9c, 0x12, 0x84, 0x72, 0x4a, 0x0f, 0x09, 0xe1, 0xe9, 0x58, 0xa5, 0x28, 0xb7, 0x06, 0xb8, 0xb1,
+	0x9d, 0x3c, 0x73, 0x2a, 0x28, 0x54, 0xe6, 0xf4, 0x19, 0x39, 0xc0, 0xdd, 0x7d, 0x19, 0x09, 0xf4,
+	0x31, 0x91, 0x26, 0x11, 0x9b, 0x58, 0x1b, 0xa8, 0xb4, 0xf2, 0xcc, 0x69, 0xf5, 0x43, 0x2b, 0x4a,
+	0x87, 0xa4, 0xcf, 0x17, 0x61, 0x20, 0xb8, 0x75, 0x1b, 0xf5, 0x44, 0xa6, 0x86, 0x42, 0x40, 0x8f,
+	0xc8, 0x99, 0x79, 0xc9, 0x84, 0x5b, 0xa4, 0xc2, 0x41, 0x04, 0xf4, 0x48, 0x3f, 0x27, 0x5b, 0xdc,
+	0x9b, 0x2f, 0xc2, 0x20, 0x9a, 0x82, 0x27, 0x98, 0xb5, 0x89, 0x5f, 0xfd, 0x20, 0xcf, 0x9c, 0x7b,
+	0x55, 0xfc, 0x71, 0x3c, 0x0f, 0x04, 0x9b, 0x2f, 0xc4, 0x05, 0xd4, 0xf8, 0xc3, 0xcf, 0xc8, 0x40,
+	0xd7, 0x2f, 0x99, 0xf2, 0x5c, 0xc4, 0x09, 0x6b, 0x54, 0x89, 0x53, 0x89, 0x95, 0x29, 0x8f, 0x14,
+	0x50, 0xc3, 0xf0, 0x8f, 0x2e, 0xd9, 0x38, 0x29, 0xcb, 0xd4, 0x16, 0x7e, 0x2a, 0x30, 0x99, 0x28,
+	0xea, 0x81, 0x9b, 0xee, 0x9e, 0x4c, 0xb9, 0x2a, 0x0e, 0x35, 0x8b, 0x1e, 0x13, 0x8a, 0xf6, 0x91,
+	0x2c, 0x3b, 0xfc, 0x2b, 0x4f, 0xa0, 0x56, 0xbd, 0xe2, 0x7b, 0x79, 0xe6, 0xb4, 0x78, 0xa1, 0x05,
+	0x2b, 0x56, 0x77, 0xd1, 0xe6, 0xfa, 0xd1, 0x96, 0xab, 0x6b, 0x1c, 0x6a, 0x16, 0xfd, 0x94, 0xec,
+	0x94, 0x4f, 0xee, 0x94, 0x45, 0x42, 0xbf, 0x50, 0x9a, 0x67, 0x4e, 0xc3, 0x03, 0x0d, 0xbb, 0x3c,
+	0x2f, 0xf3, 0x7f, 0x9f, 0xd7, 0x2f, 0x5d, 0x62, 0xa2, 0xbf, 0x58, 0x58, 0x7d, 0x04, 0xb0, 0x17,
+	0x96, 0xd1, 0x58, 0xb8, 0xf0, 0x40, 0xc3, 0xa6, 0x5f, 0x93, 0xbb, 0x15, 0xe4, 0x69, 0xfc, 0x63,
+	0x14, 0xc6, 0xde, 0xa4, 0x38, 0xb5, 0x77, 0xf2, 0xcc, 0x69, 0x27, 0x40, 0x3b, 0x2c, 0xef, 0xc0,
+	0xaf, 0x61, 0x98, 0x40, 0xbd, 0xf2, 0x0e, 0xd6, 0xbd, 0xd0, 0x82, 0x95, 0x7d, 0xa6, 0x51, 0xc5,
+	0x25, 0xd6, 0xde, 0x67, 0x86, 0x3f, 0xf7, 0x88, 0x89, 0x7e, 0x79, 0x22, 0x33, 0xe6, 0x4d, 0x14,
+	0x59, 0x16, 0x93, 0xea, 0x55, 0xd4, 0x3d, 0xd0, 0xb0, 0x6b, 0x5a, 0xbc, 0x20, 0xcb, 0x6c, 0xd1,
+	0xa2, 0x07, 0x1a, 0x36, 0x3d, 0x22, 0x77, 0x26, 0xcc, 0x8f, 0xe7, 0x8b, 0x04, 0xcb, 0x8d, 0x5a,
+	0xba, 0x8f, 0xf2, 0xbb, 0x79, 0xe6, 0xac, 0x3b, 0x61, 0x1d, 0x6a, 0x06, 0x51, 0x7b, 0x18, 0xb4,
+	0x07, 0x51, 0xdb, 0x58, 0x87, 0xe8, 0x13, 0xb2, 0xdb, 0xdc, 0x87, 0x2a, 0x2e, 0xfb, 0x79, 0xe6,
+	0x34, 0x5d, 0xd0, 0x04, 0xa4, 0x1c, 0xaf, 0xf7, 0x69, 0xba, 0x08, 0x03, 0xdf, 0x13, 0x6c, 0x55,
+	0x5b, 0x50, 0xde, 0x70, 0x41, 0x13, 0x18, 0xfe, 0xdb, 0x25, 0x26, 0xb6, 0x38, 0x99, 0x4a, 0x4c,
+	0x95, 0xab, 0xe3, 0x38, 0x8d, 0x6a, 0x89, 0x5c, 0xc5, 0xa1, 0x66, 0xd1, 0x2f, 0xc8, 0x1e, 0x5b,
+	0x15, 0xb9, 0xf3, 0x94, 0x71, 0xa1, 0x1f, 0xa4, 0xe9, 0x1e, 0xe4, 0x99, 0xb3, 0xe6, 0x83, 0x35,
+	0x84, 0x7e, 0x4c, 0xb6, 0x35, 0x86, 0x39, 0xa2, 0x1a, 0x8f, 0xe9, 0xde, 0xc9, 0x33, 0xa7, 0xee,
+	0x80, 0xba, 0x29, 0x85, 0xd8, 0x29, 0x81, 0xf9, 0x2c, 0xf8, 0xa1, 0x68, 0x33, 0x28, 0xac, 0x39,
+	0xa0, 0x6e, 0xca, 0x86, 0x81, 0x00, 0x66, 0xbe, 0x7a, 0x32, 0xd8, 0x30, 0x0a, 0x10, 0xca, 0xa9,
+	0xec, 0x43, 0x89, 0xda, 0xab, 0x7a, 0x1f, 0xa6, 0xea, 0x43, 0x2b, 0x0c, 0x8a, 0x99, 0x3c, 0xc0,
+	0x49, 0x35, 0x93, 0x06, 0x65, 0x2d, 0xaa, 0xe2, 0x50, 0xb3, 0xdc, 0xf1, 0xe5, 0x95, 0xdd, 0x79,
+	0x7d, 0x65, 0x77, 0xde, 0x5c, 0xd9, 0xc6, 0x4f, 0x4b, 0xdb, 0xf8, 0x6d, 0x69, 0x1b, 0xaf, 0x96,
+	0xb6, 0x71, 0xb9, 0xb4, 0x8d, 0x3f, 0x97, 0xb6, 0xf1, 0xd7, 0xd2, 0xee, 0xbc, 0x59, 0xda, 0xc6,
+	0xaf, 0xd7, 0x76, 0xe7, 0xf2, 0xda, 0xee, 0xbc, 0xbe, 0xb6, 0x3b, 0xdf, 0x3f, 0x9e, 0x06, 0x62,
+	0x96, 0x8e, 0x0f, 0xfd, 0x78, 0x3e, 0x9a, 0x26, 0xde, 0x0b, 0x2f, 0xf2, 0x46, 0x61, 0x7c, 0x16,
+	0x8c, 0xda, 0xfe, 0x33, 0x8f, 0xfb, 0xf8, 0x8f, 0xf8, 0xc3, 0xff, 0x06, 0x00, 0xe7, 0x89, 0x14,
+	0x81, 0x52, 0x0b, 0x00, 0x00,
 }
 
 func (this *Result) Equal(that interface{}) bool {
@@ -793,6 +831,15 @@ func (this *Caches) Equal(that interface{}) bool {
 	if !this.Result.Equal(&that1.Result) {
 		return false
 	}
+	if !this.StatsResult.Equal(&that1.StatsResult) {
+		return false
+	}
+	if !this.SeriesResult.Equal(&that1.SeriesResult) {
+		return false
+	}
+	if !this.LabelResult.Equal(&that1.LabelResult) {
+		return false
+	}
 	return true
 }
 func (this *Summary) Equal(that interface{}) bool {
@@ -844,6 +891,9 @@ func (this *Summary) Equal(that interface{}) bool {
 	if this.Shards != that1.Shards {
 		return false
 	}
+	if this.SamplingRate != that1.SamplingRate {
+		return false
+	}
 	return true
 }
 func (this *Querier) Equal(that interface{}) bool {
@@ -1037,11 +1087,14 @@ func (this *Caches) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 7)
+	s := make([]string, 0, 10)
 	s = append(s, "&stats.Caches{")
 	s = append(s, "Chunk: "+strings.Replace(this.Chunk.GoString(), `&`, ``, 1)+",\n")
 	s = append(s, "Index: "+strings.Replace(this.Index.GoString(), `&`, ``, 1)+",\n")
 	s = append(s, "Result: "+strings.Replace(this.Result.GoString(), `&`, ``, 1)+",\n")
+	s = append(s, "StatsResult: "+strings.Replace(this.StatsResult.GoString(), `&`, ``, 1)+",\n")
+	s = append(s, "SeriesResult: "+strings.Replace(this.SeriesResult.GoString(), `&`, ``, 1)+",\n")
+	s = append(s, "LabelResult: "+strings.Replace(this.LabelResult.GoString(), `&`, ``, 1)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -1049,7 +1102,7 @@ func (this *Summary) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 14)
+	s := make([]string, 0, 15)
 	s = append(s, "&stats.Summary{")
 	s = append(s, "BytesProcessedPerSecond: "+fmt.Sprintf("%#v", this.BytesProcessedPerSecond)+",\n")
 	s = append(s, "LinesProcessedPerSecond: "+fmt.Sprintf("%#v", this.LinesProcessedPerSecond)+",\n")
@@ -1061,6 +1114,7 @@ func (this *Summary) GoString() string {
 	s = append(s, "TotalEntriesReturned: "+fmt.Sprintf("%#v", this.TotalEntriesReturned)+",\n")
 	s = append(s, "Splits: "+fmt.Sprintf("%#v", this.Splits)+",\n")
 	s = append(s, "Shards: "+fmt.Sprintf("%#v", this.Shards)+",\n")
+	s = append(s, "SamplingRate: "+fmt.Sprintf("%#v", this.SamplingRate)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -1223,6 +1277,36 @@ func (m *Caches) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	{
+		size, err := m.LabelResult.MarshalToSizedBuffer(dAtA[:i])
+		if err != nil {
+			return 0, err
+		}
+		i -= size
+		i = encodeVarintStats(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0x32
+	{
+		size, err := m.SeriesResult.MarshalToSizedBuffer(dAtA[:i])
+		if err != nil {
+			return 0, err
+		}
+		i -= size
+		i = encodeVarintStats(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0x2a
+	{
+		size, err := m.StatsResult.MarshalToSizedBuffer(dAtA[:i])
+		if err != nil {
+			return 0, err
+		}
+		i -= size
+		i = encodeVarintStats(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0x22
 	{
 		size, err := m.Result.MarshalToSizedBuffer(dAtA[:i])
 		if err != nil {
@@ -1276,6 +1360,12 @@ func (m *Summary) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.SamplingRate != 0 {
+		i -= 8
+		encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.SamplingRate))))
+		i--
+		dAtA[i] = 0x59
+	}
 	if m.Shards != 0 {
 		i = encodeVarintStats(dAtA, i, uint64(m.Shards))
 		i--
@@ -1616,6 +1706,12 @@ func (m *Caches) Size() (n int) {
 	n += 1 + l + sovStats(uint64(l))
 	l = m.Result.Size()
 	n += 1 + l + sovStats(uint64(l))
+	l = m.StatsResult.Size()
+	n += 1 + l + sovStats(uint64(l))
+	l = m.SeriesResult.Size()
+	n += 1 + l + sovStats(uint64(l))
+	l = m.LabelResult.Size()
+	n += 1 + l + sovStats(uint64(l))
 	return n
 }
 
@@ -1655,6 +1751,9 @@ func (m *Summary) Size() (n int) {
 	if m.Shards != 0 {
 		n += 1 + sovStats(uint64(m.Shards))
 	}
+	if m.SamplingRate != 0 {
+		n += 9
+	}
 	return n
 }
 
@@ -1796,6 +1895,9 @@ func (this *Caches) String() string {
 		`Chunk:` + strings.Replace(strings.Replace(this.Chunk.String(), "Cache", "Cache", 1), `&`, ``, 1) + `,`,
 		`Index:` + strings.Replace(strings.Replace(this.Index.String(), "Cache", "Cache", 1), `&`, ``, 1) + `,`,
 		`Result:` + strings.Replace(strings.Replace(this.Result.String(), "Cache", "Cache", 1), `&`, ``, 1) + `,`,
+		`StatsResult:` + strings.Replace(strings.Replace(this.StatsResult.String(), "Cache", "Cache", 1), `&`, ``, 1) + `,`,
+		`SeriesResult:` + strings.Replace(strings.Replace(this.SeriesResult.String(), "Cache", "Cache", 1), `&`, ``, 1) + `,`,
+		`LabelResult:` + strings.Replace(strings.Replace(this.LabelResult.String(), "Cache", "Cache", 1), `&`, ``, 1) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -1815,6 +1917,7 @@ func (this *Summary) String() string {
 		`TotalEntriesReturned:` + fmt.Sprintf("%v", this.TotalEntriesReturned) + `,`,
 		`Splits:` + fmt.Sprintf("%v", this.Splits) + `,`,
 		`Shards:` + fmt.Sprintf("%v", this.Shards) + `,`,
+		`SamplingRate:` + fmt.Sprintf("%v", this.SamplingRate) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -2208,6 +2311,105 @@ func (m *Caches) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StatsResult", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStats
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthStats
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthStats
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.StatsResult.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SeriesResult", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStats
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthStats
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthStats
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.SeriesResult.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LabelResult", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStats
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthStats
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthStats
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.LabelResult.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipStats(dAtA[iNdEx:])
@@ -2435,6 +2637,17 @@ func (m *Summary) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 11:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SamplingRate", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.SamplingRate = float64(math.Float64frombits(v))
 		default:
 			iNdEx = preIndex
 			skippy, err := skipStats(dAtA[iNdEx:])
@@ -10,6 +10,7 @@ import (
 
 	"github.com/grafana/loki/pkg/ingester"
 	"github.com/grafana/loki/pkg/storage/config"
+	"github.com/grafana/loki/pkg/storage/stores/indexshipper/compactor/deletionmode"
 )
 
 func TestCrossComponentValidation(t *testing.T) {
@@ -75,3 +76,48 @@ func TestCrossComponentValidation(t *testing.T) {
 		}
 	}
 }
+
+func TestCompactorShardingValidation(t *testing.T) {
+	for _, tc := range []struct {
+		desc         string
+		deletionMode string
+		err          bool
+	}{
+		{
+			desc:         "deletion enabled",
+			deletionMode: deletionmode.FilterAndDelete.String(),
+			err:          true,
+		},
+		{
+			desc:         "deletion disabled",
+			deletionMode: deletionmode.Disabled.String(),
+			err:          false,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			cfg := &Config{}
+			cfg.RegisterFlags(flag.NewFlagSet(tc.desc, 0))
+			cfg.SchemaConfig = config.SchemaConfig{
+				Configs: []config.PeriodConfig{
+					{
+						RowShards: 16,
+						Schema:    "v11",
+						From: config.DayTime{
+							Time: model.Now(),
+						},
+					},
+				},
+			}
+			cfg.CompactorConfig.ShardingEnabled = true
+			cfg.CompactorConfig.RetentionEnabled = true
+			cfg.LimitsConfig.DeletionMode = tc.deletionMode
+
+			err := cfg.Validate()
+			if tc.err {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
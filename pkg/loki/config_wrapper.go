@@ -302,6 +302,18 @@ func applyConfigToRings(r, defaults *ConfigWrapper, rc util.RingConfig, mergeWit
 		r.IndexGateway.Ring.ZoneAwarenessEnabled = rc.ZoneAwarenessEnabled
 		r.IndexGateway.Ring.KVStore = rc.KVStore
 	}
+
+	// EmbeddedCachePeers, the instance port is not applied as the peers are
+	// reached on their peer listen port.
+	if mergeWithExisting || reflect.DeepEqual(r.EmbeddedCachePeers.Ring, defaults.EmbeddedCachePeers.Ring) {
+		r.EmbeddedCachePeers.Ring.HeartbeatTimeout = rc.HeartbeatTimeout
+		r.EmbeddedCachePeers.Ring.HeartbeatPeriod = rc.HeartbeatPeriod
+		r.EmbeddedCachePeers.Ring.InstanceAddr = rc.InstanceAddr
+		r.EmbeddedCachePeers.Ring.InstanceID = rc.InstanceID
+		r.EmbeddedCachePeers.Ring.InstanceInterfaceNames = rc.InstanceInterfaceNames
+		r.EmbeddedCachePeers.Ring.InstanceZone = rc.InstanceZone
+		r.EmbeddedCachePeers.Ring.KVStore = rc.KVStore
+	}
 }
 
 func applyTokensFilePath(cfg *ConfigWrapper) error {
@@ -422,12 +434,13 @@ func applyMemberlistConfig(r *ConfigWrapper) {
 	r.QueryScheduler.SchedulerRing.KVStore.Store = memberlistStr
 	r.CompactorConfig.CompactorRing.KVStore.Store = memberlistStr
 	r.IndexGateway.Ring.KVStore.Store = memberlistStr
+	r.EmbeddedCachePeers.Ring.KVStore.Store = memberlistStr
 }
 
 var ErrTooManyStorageConfigs = errors.New("too many storage configs provided in the common config, please only define one storage backend")
 
 // applyStorageConfig will attempt to apply a common storage config for either
-// s3, gcs, azure, or swift to all the places we create a storage client.
+// s3, gcs, azure, swift, bos or cos to all the places we create a storage client.
 // If any specific configs for an object storage client have been provided elsewhere in the
 // configuration file, applyStorageConfig will not override them.
 // If multiple storage configurations are provided, applyStorageConfig will return an error
@@ -504,6 +517,17 @@ func applyStorageConfig(cfg, defaults *ConfigWrapper) error {
 		}
 	}
 
+	if !reflect.DeepEqual(cfg.Common.Storage.COS, defaults.StorageConfig.COSConfig) {
+		configsFound++
+
+		applyConfig = func(r *ConfigWrapper) {
+			r.Ruler.StoreConfig.Type = "cos"
+			r.Ruler.StoreConfig.COS = r.Common.Storage.COS
+			r.StorageConfig.COSConfig = r.Common.Storage.COS
+			r.StorageConfig.Hedging = r.Common.Storage.Hedging
+		}
+	}
+
 	if configsFound > 1 {
 		return ErrTooManyStorageConfigs
 	}
@@ -552,9 +576,9 @@ func betterTSDBShipperDefaults(cfg, defaults *ConfigWrapper, period config.Perio
 }
 
 // applyFIFOCacheConfig turns on FIFO cache for the chunk store, for the query range results,
-// and for the index stats results, but only if no other cache storage is configured (redis or memcache).
+// and for the index stats, series and label results, but only if no other cache storage is configured (redis or memcache).
 // This behavior is only applied for the chunk store cache, for the query range results cache, and for
-// the index stats results (i.e: not applicable for the index queries cache or for the write dedupe cache).
+// the index stats, series and label results (i.e: not applicable for the index queries cache or for the write dedupe cache).
 func applyFIFOCacheConfig(r *ConfigWrapper) {
 	chunkCacheConfig := r.ChunkStoreConfig.ChunkCacheConfig
 	if !cache.IsCacheConfigured(chunkCacheConfig) {
@@ -575,6 +599,14 @@ func applyFIFOCacheConfig(r *ConfigWrapper) {
 		// We use the same config as the query range results cache.
 		r.QueryRange.StatsCacheConfig.CacheConfig = r.QueryRange.ResultsCacheConfig.CacheConfig
 	}
+
+	if !cache.IsCacheConfigured(r.QueryRange.SeriesCacheConfig.CacheConfig) {
+		r.QueryRange.SeriesCacheConfig.CacheConfig = r.QueryRange.ResultsCacheConfig.CacheConfig
+	}
+
+	if !cache.IsCacheConfigured(r.QueryRange.LabelsCacheConfig.CacheConfig) {
+		r.QueryRange.LabelsCacheConfig.CacheConfig = r.QueryRange.ResultsCacheConfig.CacheConfig
+	}
 }
 
 func applyIngesterFinalSleep(cfg *ConfigWrapper) {
@@ -2,9 +2,11 @@ package loki
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/grafana/loki/pkg/logql/syntax"
+	"github.com/grafana/loki/pkg/logqlmodel"
 	"github.com/grafana/loki/pkg/util/server"
 )
 
@@ -15,6 +17,7 @@ func formatQueryHandler() http.HandlerFunc {
 			status     = "success"
 			formatted  string
 			errStr     string
+			line, col  int
 		)
 
 		expr, err := syntax.ParseExpr(r.FormValue("query"))
@@ -22,6 +25,10 @@ func formatQueryHandler() http.HandlerFunc {
 			statusCode = http.StatusBadRequest
 			status = "invalid-query"
 			errStr = err.Error()
+			var parseErr logqlmodel.ParseError
+			if errors.As(err, &parseErr) {
+				line, col = parseErr.Line(), parseErr.Col()
+			}
 		}
 
 		if err == nil {
@@ -32,6 +39,8 @@ func formatQueryHandler() http.HandlerFunc {
 			Status: status,
 			Data:   formatted,
 			Err:    errStr,
+			Line:   line,
+			Column: col,
 		}
 
 		w.Header().Set("Content-Type", "application/json; charset=utf-8")
@@ -48,4 +57,7 @@ type FormatQueryResponse struct {
 	Status string `json:"status"`
 	Data   string `json:"data,omitempty"`
 	Err    string `json:"error,omitempty"`
+	// Line and Column are the position of the syntax error in the query, when it's known.
+	Line   int `json:"line,omitempty"`
+	Column int `json:"column,omitempty"`
 }
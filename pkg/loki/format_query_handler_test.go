@@ -31,6 +31,16 @@ func Test_formatQueryHandlerResponse(t *testing.T) {
 			expected: FormatQueryResponse{
 				Status: "invalid-query",
 				Err:    "parse error at line 1, col 6: literal not terminated",
+				Line:   1,
+				Column: 6,
+			},
+		},
+		{
+			name:  "invalid-query-without-position",
+			query: `{foo="bar"} | logfmt --lenient`,
+			expected: FormatQueryResponse{
+				Status: "invalid-query",
+				Err:    "parse error : invalid logfmt parser flag --lenient",
 			},
 		},
 	}
@@ -14,7 +14,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/NYTimes/gziphandler"
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/grafana/dskit/dns"
@@ -38,6 +37,7 @@ import (
 	"github.com/grafana/loki/pkg/ingester"
 	"github.com/grafana/loki/pkg/logproto"
 	"github.com/grafana/loki/pkg/logql"
+	"github.com/grafana/loki/pkg/lokifrontend"
 	"github.com/grafana/loki/pkg/lokifrontend/frontend"
 	"github.com/grafana/loki/pkg/lokifrontend/frontend/transport"
 	"github.com/grafana/loki/pkg/lokifrontend/frontend/v1/frontendv1pb"
@@ -66,6 +66,7 @@ import (
 	boltdb_shipper_compactor "github.com/grafana/loki/pkg/storage/stores/shipper/index/compactor"
 	"github.com/grafana/loki/pkg/storage/stores/shipper/indexgateway"
 	"github.com/grafana/loki/pkg/storage/stores/tsdb"
+	"github.com/grafana/loki/pkg/util/audit"
 	"github.com/grafana/loki/pkg/util/httpreq"
 	"github.com/grafana/loki/pkg/util/limiter"
 	util_log "github.com/grafana/loki/pkg/util/log"
@@ -111,6 +112,8 @@ const (
 	Write                    string = "write"
 	Backend                  string = "backend"
 	Analytics                string = "analytics"
+	EmbeddedCachePeers       string = "embedded-cache-peers"
+	QueryAuditLog            string = "query-audit-log"
 )
 
 func (t *Loki) initServer() (services.Service, error) {
@@ -314,6 +317,7 @@ func (t *Loki) initDistributor() (services.Service, error) {
 	).Wrap(http.HandlerFunc(t.distributor.PushHandler))
 
 	t.Server.HTTP.Path("/distributor/ring").Methods("GET", "POST").Handler(t.distributor)
+	t.Server.HTTP.Path("/distributor/discards").Methods("GET").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.distributor.DiscardsHandler)))
 
 	if t.Cfg.InternalServer.Enable {
 		t.InternalServer.HTTP.Path("/distributor/ring").Methods("GET").Handler(t.distributor)
@@ -321,6 +325,17 @@ func (t *Loki) initDistributor() (services.Service, error) {
 
 	t.Server.HTTP.Path("/api/prom/push").Methods("POST").Handler(pushHandler)
 	t.Server.HTTP.Path("/loki/api/v1/push").Methods("POST").Handler(pushHandler)
+
+	if t.Cfg.Distributor.ElasticsearchBulk.Enabled {
+		bulkHandler := middleware.Merge(
+			serverutil.RecoveryHTTPMiddleware,
+			t.HTTPAuthMiddleware,
+		).Wrap(http.HandlerFunc(t.distributor.ElasticsearchBulkHandler))
+		t.Server.HTTP.Path("/es/_bulk").Methods("POST", "PUT").Handler(bulkHandler)
+		t.Server.HTTP.Path("/es/{index}/_bulk").Methods("POST", "PUT").Handler(bulkHandler)
+		t.Server.HTTP.Path("/es").Methods("GET", "HEAD").Handler(http.HandlerFunc(t.distributor.ElasticsearchInfoHandler))
+		t.Server.HTTP.Path("/es/").Methods("GET", "HEAD").Handler(http.HandlerFunc(t.distributor.ElasticsearchInfoHandler))
+	}
 	return t.distributor, nil
 }
 
@@ -342,7 +357,7 @@ func (t *Loki) initQuerier() (services.Service, error) {
 	}
 
 	if t.Cfg.Querier.MultiTenantQueriesEnabled {
-		t.Querier = querier.NewMultiTenantQuerier(q, util_log.Logger)
+		t.Querier = querier.NewMultiTenantQuerier(q, t.Cfg.Querier.MultiTenantQueriesInjectTenantLabel, util_log.Logger)
 		tenant.WithDefaultResolver(tenant.NewMultiResolver())
 	} else {
 		t.Querier = q
@@ -365,11 +380,11 @@ func (t *Loki) initQuerier() (services.Service, error) {
 	}
 
 	logger := log.With(util_log.Logger, "component", "querier")
-	t.querierAPI = querier.NewQuerierAPI(t.Cfg.Querier, t.Querier, t.Overrides, logger)
+	t.querierAPI = querier.NewQuerierAPI(t.Cfg.Querier, t.Querier, t.Overrides, queryrange.WriteQueryResponse, logger)
 
 	indexStatsHTTPMiddleware := querier.WrapQuerySpanAndTimeout("query.IndexStats", t.querierAPI)
 
-	if t.supportIndexDeleteRequest() && t.Cfg.CompactorConfig.RetentionEnabled {
+	if t.supportIndexDeleteRequest() && t.Cfg.CompactorConfig.DeleteRequestsEnabled() {
 		toMerge = append(
 			toMerge,
 			queryrangebase.CacheGenNumberHeaderSetterMiddleware(t.cacheGenerationLoader),
@@ -486,7 +501,7 @@ func (t *Loki) initIngester() (_ services.Service, err error) {
 		serverutil.RecoveryHTTPMiddleware,
 	)
 	t.Server.HTTP.Methods("GET", "POST").Path("/flush").Handler(
-		httpMiddleware.Wrap(http.HandlerFunc(t.Ingester.FlushHandler)),
+		middleware.Merge(httpMiddleware, t.HTTPAuthMiddleware).Wrap(http.HandlerFunc(t.Ingester.FlushHandler)),
 	)
 	t.Server.HTTP.Methods("POST").Path("/ingester/flush_shutdown").Handler(
 		httpMiddleware.Wrap(http.HandlerFunc(t.Ingester.LegacyShutdownHandler)),
@@ -712,7 +727,7 @@ func (t *Loki) initQueryFrontendTripperware() (_ services.Service, err error) {
 		util_log.Logger,
 		t.Overrides,
 		t.Cfg.SchemaConfig,
-		t.cacheGenerationLoader, t.Cfg.CompactorConfig.RetentionEnabled,
+		t.cacheGenerationLoader, t.Cfg.CompactorConfig.DeleteRequestsEnabled(),
 		prometheus.DefaultRegisterer,
 	)
 	if err != nil {
@@ -813,15 +828,19 @@ func (t *Loki) initQueryFrontend() (_ services.Service, err error) {
 
 	frontendHandler := transport.NewHandler(t.Cfg.Frontend.Handler, roundTripper, util_log.Logger, prometheus.DefaultRegisterer)
 	if t.Cfg.Frontend.CompressResponses {
-		frontendHandler = gziphandler.GzipHandler(frontendHandler)
+		frontendHandler, err = lokifrontend.NewCompressionHandler(t.Cfg.Frontend.ResponseCompression, frontendHandler)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	toMerge := []middleware.Interface{
 		httpreq.ExtractQueryTagsMiddleware(),
 		httpreq.PropagateHeadersMiddleware(httpreq.LokiActorPathHeader),
+		httpreq.ExtractQueryPriorityMiddleware(),
 		serverutil.RecoveryHTTPMiddleware,
 		t.HTTPAuthMiddleware,
-		queryrange.StatsHTTPMiddleware,
+		queryrange.NewStatsHTTPMiddleware(t.queryAuditLog),
 		serverutil.NewPrepopulateMiddleware(),
 		serverutil.ResponseJSONMiddleware(),
 	}
@@ -839,7 +858,7 @@ func (t *Loki) initQueryFrontend() (_ services.Service, err error) {
 		httpMiddleware := middleware.Merge(
 			httpreq.ExtractQueryTagsMiddleware(),
 			t.HTTPAuthMiddleware,
-			queryrange.StatsHTTPMiddleware,
+			queryrange.NewStatsHTTPMiddleware(t.queryAuditLog),
 		)
 		tailURL, err := url.Parse(t.Cfg.Frontend.TailProxyURL)
 		if err != nil {
@@ -874,6 +893,7 @@ func (t *Loki) initQueryFrontend() (_ services.Service, err error) {
 	t.Server.HTTP.Path("/loki/api/v1/series").Methods("GET", "POST").Handler(frontendHandler)
 	t.Server.HTTP.Path("/loki/api/v1/index/stats").Methods("GET", "POST").Handler(frontendHandler)
 	t.Server.HTTP.Path("/loki/api/v1/index/series_volume").Methods("GET", "POST").Handler(frontendHandler)
+	t.Server.HTTP.Path("/loki/api/v1/query_explain").Methods("GET", "POST").Handler(frontendHandler)
 	t.Server.HTTP.Path("/api/prom/query").Methods("GET", "POST").Handler(frontendHandler)
 	t.Server.HTTP.Path("/api/prom/label").Methods("GET", "POST").Handler(frontendHandler)
 	t.Server.HTTP.Path("/api/prom/label/{name}/values").Methods("GET", "POST").Handler(frontendHandler)
@@ -975,6 +995,14 @@ func (t *Loki) initRuler() (_ services.Service, err error) {
 
 		base_ruler.RegisterRulerServer(t.Server.GRPC, t.ruler)
 
+		// Ruler ownership of the rule groups of the tenant
+		t.Server.HTTP.Path("/ruler/rule_groups/owners").Methods("GET").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.RuleGroupOwners)))
+		t.Server.HTTP.Path("/ruler/rule_groups/health").Methods("GET").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.RuleGroupsHealth)))
+		t.Server.HTTP.Path("/loki/api/v1/rule_groups/owners").Methods("GET").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.RuleGroupOwners)))
+		t.Server.HTTP.Path("/loki/api/v1/rule_groups/health").Methods("GET").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.RuleGroupsHealth)))
+		t.Server.HTTP.Path("/prometheus/api/v1/rule_groups/owners").Methods("GET").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.RuleGroupOwners)))
+		t.Server.HTTP.Path("/prometheus/api/v1/rule_groups/health").Methods("GET").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.RuleGroupsHealth)))
+
 		// Prometheus Rule API Routes
 		t.Server.HTTP.Path("/prometheus/api/v1/rules").Methods("GET").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.PrometheusRules)))
 		t.Server.HTTP.Path("/prometheus/api/v1/alerts").Methods("GET").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.PrometheusAlerts)))
@@ -982,6 +1010,7 @@ func (t *Loki) initRuler() (_ services.Service, err error) {
 		// Ruler Legacy API Routes
 		t.Server.HTTP.Path("/api/prom/rules").Methods("GET").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.ListRules)))
 		t.Server.HTTP.Path("/api/prom/rules/{namespace}").Methods("GET").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.ListRules)))
+		t.Server.HTTP.Path("/api/prom/rules/validate").Methods("POST").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.ValidateRuleGroup)))
 		t.Server.HTTP.Path("/api/prom/rules/{namespace}").Methods("POST").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.CreateRuleGroup)))
 		t.Server.HTTP.Path("/api/prom/rules/{namespace}").Methods("DELETE").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.DeleteNamespace)))
 		t.Server.HTTP.Path("/api/prom/rules/{namespace}/{groupName}").Methods("GET").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.GetRuleGroup)))
@@ -990,6 +1019,7 @@ func (t *Loki) initRuler() (_ services.Service, err error) {
 		// Ruler API Routes
 		t.Server.HTTP.Path("/loki/api/v1/rules").Methods("GET").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.ListRules)))
 		t.Server.HTTP.Path("/loki/api/v1/rules/{namespace}").Methods("GET").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.ListRules)))
+		t.Server.HTTP.Path("/loki/api/v1/rules/validate").Methods("POST").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.ValidateRuleGroup)))
 		t.Server.HTTP.Path("/loki/api/v1/rules/{namespace}").Methods("POST").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.CreateRuleGroup)))
 		t.Server.HTTP.Path("/loki/api/v1/rules/{namespace}").Methods("DELETE").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.DeleteNamespace)))
 		t.Server.HTTP.Path("/loki/api/v1/rules/{namespace}/{groupName}").Methods("GET").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.GetRuleGroup)))
@@ -1027,7 +1057,7 @@ func (t *Loki) initRuleEvaluator() (services.Service, error) {
 			break
 		}
 
-		evaluator, err = ruler.NewLocalEvaluator(engine, logger)
+		evaluator, err = ruler.NewLocalEvaluator(engine, t.queryAuditLog, logger)
 	case ruler.EvalModeRemote:
 		qfClient, e := ruler.DialQueryFrontend(&t.Cfg.Ruler.Evaluation.QueryFrontend)
 		if e != nil {
@@ -1043,6 +1073,8 @@ func (t *Loki) initRuleEvaluator() (services.Service, error) {
 		return nil, fmt.Errorf("failed to create %s rule evaluator: %w", mode, err)
 	}
 
+	evaluator = ruler.NewEvaluatorWithAlignment(evaluator, t.Cfg.Ruler.Evaluation.Alignment)
+	evaluator = ruler.NewEvaluatorWithConcurrencyLimit(evaluator, t.Overrides, logger, prometheus.DefaultRegisterer)
 	t.ruleEvaluator = ruler.NewEvaluatorWithJitter(evaluator, t.Cfg.Ruler.Evaluation.MaxJitter, fnv.New32a(), logger)
 
 	return nil, nil
@@ -1075,6 +1107,7 @@ func (t *Loki) initMemberlistKV() (services.Service, error) {
 	t.Cfg.Ingester.LifecyclerConfig.RingConfig.KVStore.MemberlistKV = t.MemberlistKV.GetMemberlistKV
 	t.Cfg.QueryScheduler.SchedulerRing.KVStore.MemberlistKV = t.MemberlistKV.GetMemberlistKV
 	t.Cfg.Ruler.Ring.KVStore.MemberlistKV = t.MemberlistKV.GetMemberlistKV
+	t.Cfg.EmbeddedCachePeers.Ring.KVStore.MemberlistKV = t.MemberlistKV.GetMemberlistKV
 
 	t.Server.HTTP.Handle("/memberlist", t.MemberlistKV)
 
@@ -1145,7 +1178,7 @@ func (t *Loki) initCompactor() (services.Service, error) {
 		t.InternalServer.HTTP.Path("/compactor/ring").Methods("GET").Handler(t.compactor)
 	}
 
-	if t.Cfg.CompactorConfig.RetentionEnabled {
+	if t.Cfg.CompactorConfig.DeleteRequestsEnabled() {
 		t.Server.HTTP.Path("/loki/api/v1/delete").Methods("PUT", "POST").Handler(t.addCompactorMiddleware(t.compactor.DeleteRequestsHandler.AddDeleteRequestHandler))
 		t.Server.HTTP.Path("/loki/api/v1/delete").Methods("GET").Handler(t.addCompactorMiddleware(t.compactor.DeleteRequestsHandler.GetAllDeleteRequestsHandler))
 		t.Server.HTTP.Path("/loki/api/v1/delete").Methods("DELETE").Handler(t.addCompactorMiddleware(t.compactor.DeleteRequestsHandler.CancelDeleteRequestHandler))
@@ -1276,6 +1309,54 @@ func (t *Loki) initQuerySchedulerRing() (_ services.Service, err error) {
 	return t.querySchedulerRingManager, nil
 }
 
+func (t *Loki) initQueryAuditLog() (services.Service, error) {
+	auditLog, err := audit.New(t.Cfg.QueryAuditLog, util_log.Logger)
+	if err != nil {
+		return nil, err
+	}
+	if auditLog == nil {
+		return nil, nil
+	}
+
+	t.queryAuditLog = auditLog
+	return services.NewIdleService(nil, func(_ error) error {
+		return t.queryAuditLog.Close()
+	}), nil
+}
+
+func (t *Loki) initEmbeddedCachePeers() (_ services.Service, err error) {
+	var distributed []*cache.EmbeddedCacheConfig
+	for _, cfg := range []*cache.Config{
+		&t.Cfg.QueryRange.ResultsCacheConfig.CacheConfig,
+		&t.Cfg.QueryRange.StatsCacheConfig.CacheConfig,
+		&t.Cfg.QueryRange.SeriesCacheConfig.CacheConfig,
+		&t.Cfg.QueryRange.LabelsCacheConfig.CacheConfig,
+		&t.Cfg.StorageConfig.IndexQueriesCacheConfig,
+		&t.Cfg.ChunkStoreConfig.ChunkCacheConfig,
+		&t.Cfg.ChunkStoreConfig.WriteDedupeCacheConfig,
+	} {
+		if cfg.EmbeddedCache.IsEnabled() && cfg.EmbeddedCache.Distributed {
+			distributed = append(distributed, &cfg.EmbeddedCache)
+		}
+	}
+	if len(distributed) == 0 {
+		return
+	}
+
+	t.embeddedCachePeers, err = cache.NewEmbeddedCachePeers(t.Cfg.EmbeddedCachePeers, util_log.Logger, prometheus.DefaultRegisterer)
+	if err != nil {
+		return nil, gerrors.Wrap(err, "new embedded cache peers")
+	}
+	for _, cfg := range distributed {
+		cfg.Peers = t.embeddedCachePeers
+	}
+
+	// The peers serve their entries on a dedicated listener, only the ring status page is exposed.
+	t.Server.HTTP.Path(cache.EmbeddedCachePeersPathPrefix + "ring").Handler(t.embeddedCachePeers)
+
+	return t.embeddedCachePeers, nil
+}
+
 func (t *Loki) initQueryLimiter() (services.Service, error) {
 	_ = level.Debug(util_log.Logger).Log("msg", "initializing query limiter")
 	logger := log.With(util_log.Logger, "component", "query-limiter")
@@ -1330,7 +1411,7 @@ func (t *Loki) initAnalytics() (services.Service, error) {
 }
 
 func (t *Loki) deleteRequestsClient(clientType string, limits limiter.CombinedLimits) (deletion.DeleteRequestsClient, error) {
-	if !t.supportIndexDeleteRequest() || !t.Cfg.CompactorConfig.RetentionEnabled {
+	if !t.supportIndexDeleteRequest() || !t.Cfg.CompactorConfig.DeleteRequestsEnabled() {
 		return deletion.NewNoOpDeleteRequestsStore(), nil
 	}
 
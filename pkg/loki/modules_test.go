@@ -403,6 +403,7 @@ func minimalWorkingConfig(t *testing.T, dir, target string, cfgTransformers ...f
 	cfg.CompactorConfig.CompactorRing.InstanceAddr = localhost
 	cfg.CompactorConfig.SharedStoreType = config.StorageTypeFileSystem
 	cfg.CompactorConfig.WorkingDirectory = path.Join(dir, "compactor")
+	cfg.Ingester.WAL.Dir = path.Join(dir, "wal")
 
 	cfg.Ruler.Config.Ring.InstanceAddr = localhost
 	cfg.Ruler.Config.StoreConfig.Type = config.StorageTypeLocal
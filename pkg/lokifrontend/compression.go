@@ -0,0 +1,272 @@
+package lokifrontend
+
+import (
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/NYTimes/gziphandler"
+	"github.com/klauspost/compress/zstd"
+)
+
+// CompressionConfig configures the compression of the HTTP responses of the query frontend,
+// if compress_responses is enabled.
+type CompressionConfig struct {
+	ZstdEnabled bool `yaml:"zstd_enabled"`
+	GzipLevel   int  `yaml:"gzip_level"`
+	ZstdLevel   int  `yaml:"zstd_level"`
+	MinSize     int  `yaml:"min_size"`
+
+	Endpoints []EndpointCompressionConfig `yaml:"endpoints,omitempty" doc:"description=Compression levels of the responses of specific endpoints, overriding gzip_level and zstd_level.\nExample:\n endpoints:\n - path: /loki/api/v1/query_range\n gzip_level: 9\n zstd_level: 19\nA level of 0 uses the level of all endpoints."`
+}
+
+// EndpointCompressionConfig configures the compression levels of the responses of an endpoint.
+type EndpointCompressionConfig struct {
+	Path      string `yaml:"path"`
+	GzipLevel int    `yaml:"gzip_level"`
+	ZstdLevel int    `yaml:"zstd_level"`
+}
+
+// RegisterFlagsWithPrefix registers flags with the given prefix.
+func (cfg *CompressionConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.BoolVar(&cfg.ZstdEnabled, prefix+".zstd-enabled", false, "Compress the responses with zstd instead of gzip for the clients accepting it, as stated by their Accept-Encoding header.")
+	f.IntVar(&cfg.GzipLevel, prefix+".gzip-level", gzip.DefaultCompression, "Compression level of the gzip responses, from 1 (best speed) to 9 (best compression). The value -1 uses the default level of gzip.")
+	f.IntVar(&cfg.ZstdLevel, prefix+".zstd-level", 3, "Compression level of the zstd responses, from 1 (best speed) to 22 (best compression).")
+	f.IntVar(&cfg.MinSize, prefix+".min-size", gziphandler.DefaultMinSize, "Minimum size in bytes of the responses to compress. Smaller responses are sent uncompressed.")
+}
+
+// Validate validates the config.
+func (cfg *CompressionConfig) Validate() error {
+	if err := validateCompressionLevels(cfg.GzipLevel, cfg.ZstdLevel); err != nil {
+		return err
+	}
+	if cfg.MinSize < 0 {
+		return fmt.Errorf("response compression: invalid min size %d", cfg.MinSize)
+	}
+	for _, e := range cfg.Endpoints {
+		if e.Path == "" {
+			return fmt.Errorf("response compression: endpoint path must not be empty")
+		}
+		if e.GzipLevel == 0 && e.ZstdLevel == 0 {
+			continue
+		}
+		if err := validateCompressionLevels(cfg.endpointLevels(e)); err != nil {
+			return fmt.Errorf("endpoint %s: %w", e.Path, err)
+		}
+	}
+	return nil
+}
+
+func validateCompressionLevels(gzipLevel, zstdLevel int) error {
+	if gzipLevel < gzip.DefaultCompression || gzipLevel > gzip.BestCompression || gzipLevel == gzip.NoCompression {
+		return fmt.Errorf("response compression: invalid gzip level %d", gzipLevel)
+	}
+	if zstdLevel < 1 || zstdLevel > 22 {
+		return fmt.Errorf("response compression: invalid zstd level %d", zstdLevel)
+	}
+	return nil
+}
+
+// endpointLevels returns the compression levels of the endpoint, using the levels
+// of all endpoints for those which are not set.
+func (cfg *CompressionConfig) endpointLevels(e EndpointCompressionConfig) (gzipLevel, zstdLevel int) {
+	gzipLevel, zstdLevel = cfg.GzipLevel, cfg.ZstdLevel
+	if e.GzipLevel != 0 {
+		gzipLevel = e.GzipLevel
+	}
+	if e.ZstdLevel != 0 {
+		zstdLevel = e.ZstdLevel
+	}
+	return gzipLevel, zstdLevel
+}
+
+// NewCompressionHandler returns a handler compressing the responses of next with
+// zstd for the clients accepting it if zstd is enabled, and with gzip otherwise.
+func NewCompressionHandler(cfg CompressionConfig, next http.Handler) (http.Handler, error) {
+	all, err := newCompressor(cfg.ZstdEnabled, cfg.GzipLevel, cfg.ZstdLevel, cfg.MinSize, next)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := make(map[string]*compressor, len(cfg.Endpoints))
+	for _, e := range cfg.Endpoints {
+		gzipLevel, zstdLevel := cfg.endpointLevels(e)
+		c, err := newCompressor(cfg.ZstdEnabled, gzipLevel, zstdLevel, cfg.MinSize, next)
+		if err != nil {
+			return nil, err
+		}
+		endpoints[e.Path] = c
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c, ok := endpoints[r.URL.Path]; ok {
+			c.ServeHTTP(w, r)
+			return
+		}
+		all.ServeHTTP(w, r)
+	}), nil
+}
+
+// compressor compresses the responses of a handler with given compression levels.
+type compressor struct {
+	next     http.Handler
+	gzip     http.Handler
+	zstdPool *sync.Pool
+	minSize  int
+}
+
+func newCompressor(zstdEnabled bool, gzipLevel, zstdLevel, minSize int, next http.Handler) (*compressor, error) {
+	gzipWrapper, err := gziphandler.NewGzipLevelAndMinSize(gzipLevel, minSize)
+	if err != nil {
+		return nil, err
+	}
+	c := &compressor{
+		next:    next,
+		gzip:    gzipWrapper(next),
+		minSize: minSize,
+	}
+	if zstdEnabled {
+		level := zstd.EncoderLevelFromZstd(zstdLevel)
+		c.zstdPool = &sync.Pool{
+			New: func() interface{} {
+				enc, _ := zstd.NewWriter(nil, zstd.WithEncoderLevel(level), zstd.WithEncoderConcurrency(1))
+				return enc
+			},
+		}
+	}
+	return c, nil
+}
+
+func (c *compressor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if c.zstdPool == nil || !acceptsEncoding(r, "zstd") {
+		c.gzip.ServeHTTP(w, r)
+		return
+	}
+
+	w.Header().Add("Vary", "Accept-Encoding")
+	zw := &zstdResponseWriter{ResponseWriter: w, pool: c.zstdPool, minSize: c.minSize}
+	defer zw.close()
+	c.next.ServeHTTP(zw, r)
+}
+
+// acceptsEncoding returns whether the Accept-Encoding header of the request accepts the encoding.
+func acceptsEncoding(r *http.Request, encoding string) bool {
+	for _, header := range r.Header.Values("Accept-Encoding") {
+		for _, value := range strings.Split(header, ",") {
+			name, params, _ := strings.Cut(value, ";")
+			if strings.TrimSpace(name) != encoding {
+				continue
+			}
+			params = strings.TrimSpace(params)
+			if !strings.HasPrefix(params, "q=") {
+				return true
+			}
+			q, err := strconv.ParseFloat(params[2:], 64)
+			return err == nil && q > 0
+		}
+	}
+	return false
+}
+
+// zstdResponseWriter compresses the response body with zstd once it reached the minimum size.
+// Smaller bodies, bodies which are already encoded and responses without a body are sent as they are.
+type zstdResponseWriter struct {
+	http.ResponseWriter
+	pool    *sync.Pool
+	minSize int
+
+	code        int
+	buf         []byte
+	enc         *zstd.Encoder
+	passThrough bool
+	wroteHeader bool
+}
+
+func (w *zstdResponseWriter) WriteHeader(code int) {
+	if w.code != 0 {
+		return
+	}
+	w.code = code
+	// Responses without a body, or which the handler encoded already, are not compressed.
+	if code < http.StatusOK || code == http.StatusNoContent || code == http.StatusNotModified || w.Header().Get("Content-Encoding") != "" {
+		w.passThrough = true
+		w.writeHeader()
+	}
+}
+
+func (w *zstdResponseWriter) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.passThrough {
+		return w.ResponseWriter.Write(b)
+	}
+	if w.enc != nil {
+		return w.enc.Write(b)
+	}
+
+	w.buf = append(w.buf, b...)
+	if len(w.buf) >= w.minSize {
+		if err := w.startZstd(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// startZstd writes the headers of the compressed response and compresses the buffered body.
+func (w *zstdResponseWriter) startZstd() error {
+	w.Header().Del("Content-Length")
+	w.Header().Set("Content-Encoding", "zstd")
+	w.writeHeader()
+
+	w.enc = w.pool.Get().(*zstd.Encoder)
+	w.enc.Reset(w.ResponseWriter)
+	buf := w.buf
+	w.buf = nil
+	_, err := w.enc.Write(buf)
+	return err
+}
+
+func (w *zstdResponseWriter) writeHeader() {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.ResponseWriter.WriteHeader(w.code)
+	}
+}
+
+// close ends the zstd frame of a compressed response, or sends the buffered body uncompressed
+// if it is smaller than the minimum size.
+func (w *zstdResponseWriter) close() {
+	if w.enc != nil {
+		_ = w.enc.Close()
+		w.pool.Put(w.enc)
+		w.enc = nil
+		return
+	}
+	if w.code == 0 {
+		return
+	}
+	w.writeHeader()
+	if len(w.buf) > 0 {
+		_, _ = w.ResponseWriter.Write(w.buf)
+		w.buf = nil
+	}
+}
+
+// Flush sends the data written so far to the client, compressed if the body is not sent as it is.
+func (w *zstdResponseWriter) Flush() {
+	if w.enc == nil && !w.passThrough && len(w.buf) > 0 {
+		_ = w.startZstd()
+	}
+	if w.enc != nil {
+		_ = w.enc.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok && w.wroteHeader {
+		f.Flush()
+	}
+}
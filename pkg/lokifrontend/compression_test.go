@@ -0,0 +1,201 @@
+package lokifrontend
+
+import (
+	"compress/gzip"
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressionHandler(t *testing.T) {
+	body := strings.Repeat(`{"status":"success","data":{"resultType":"matrix","result":[]}}`, 100)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, body)
+	})
+
+	var cfg CompressionConfig
+	cfg.RegisterFlagsWithPrefix("frontend.response-compression", flag.NewFlagSet("", flag.PanicOnError))
+	cfg.Endpoints = []EndpointCompressionConfig{{Path: "/loki/api/v1/query_range", ZstdLevel: 19}}
+	require.NoError(t, cfg.Validate())
+
+	for _, tc := range []struct {
+		name           string
+		zstdDisabled   bool
+		path           string
+		acceptEncoding string
+		expEncoding    string
+	}{
+		{
+			name: "identity",
+			path: "/loki/api/v1/query",
+		},
+		{
+			name:           "gzip",
+			path:           "/loki/api/v1/query",
+			acceptEncoding: "gzip",
+			expEncoding:    "gzip",
+		},
+		{
+			name:           "zstd",
+			path:           "/loki/api/v1/query",
+			acceptEncoding: "gzip, zstd",
+			expEncoding:    "zstd",
+		},
+		{
+			name:           "zstd of endpoint",
+			path:           "/loki/api/v1/query_range",
+			acceptEncoding: "zstd",
+			expEncoding:    "zstd",
+		},
+		{
+			name:           "zstd not accepted",
+			path:           "/loki/api/v1/query",
+			acceptEncoding: "gzip, zstd;q=0",
+			expEncoding:    "gzip",
+		},
+		{
+			name:           "zstd disabled",
+			zstdDisabled:   true,
+			path:           "/loki/api/v1/query",
+			acceptEncoding: "zstd, gzip",
+			expEncoding:    "gzip",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := cfg
+			cfg.ZstdEnabled = !tc.zstdDisabled
+			h, err := NewCompressionHandler(cfg, next)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tc.acceptEncoding)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			resp := rec.Result()
+			require.Equal(t, tc.expEncoding, resp.Header.Get("Content-Encoding"))
+
+			var r io.Reader = resp.Body
+			switch tc.expEncoding {
+			case "gzip":
+				r, err = gzip.NewReader(resp.Body)
+				require.NoError(t, err)
+			case "zstd":
+				dec, err := zstd.NewReader(resp.Body)
+				require.NoError(t, err)
+				defer dec.Close()
+				r = dec
+			}
+			got, err := io.ReadAll(r)
+			require.NoError(t, err)
+			require.Equal(t, body, string(got))
+		})
+	}
+}
+
+func TestCompressionHandler_Zstd(t *testing.T) {
+	var cfg CompressionConfig
+	cfg.RegisterFlagsWithPrefix("frontend.response-compression", flag.NewFlagSet("", flag.PanicOnError))
+	cfg.ZstdEnabled = true
+
+	for _, tc := range []struct {
+		name        string
+		handler     http.HandlerFunc
+		expCode     int
+		expEncoding string
+		expBody     string
+	}{
+		{
+			name: "body smaller than the min size",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = io.WriteString(w, "small")
+			},
+			expCode: http.StatusOK,
+			expBody: "small",
+		},
+		{
+			name: "body written in parts",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				for i := 0; i < 10; i++ {
+					_, _ = io.WriteString(w, strings.Repeat("a", cfg.MinSize/5))
+				}
+			},
+			expCode:     http.StatusOK,
+			expEncoding: "zstd",
+			expBody:     strings.Repeat("a", cfg.MinSize/5*10),
+		},
+		{
+			name: "no content",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			},
+			expCode: http.StatusNoContent,
+		},
+		{
+			name: "body already encoded",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Encoding", "snappy")
+				_, _ = io.WriteString(w, strings.Repeat("a", 2*cfg.MinSize))
+			},
+			expCode:     http.StatusOK,
+			expEncoding: "snappy",
+			expBody:     strings.Repeat("a", 2*cfg.MinSize),
+		},
+		{
+			name: "error status",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = io.WriteString(w, strings.Repeat("a", 2*cfg.MinSize))
+			},
+			expCode:     http.StatusBadRequest,
+			expEncoding: "zstd",
+			expBody:     strings.Repeat("a", 2*cfg.MinSize),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := NewCompressionHandler(cfg, tc.handler)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/loki/api/v1/query", nil)
+			req.Header.Set("Accept-Encoding", "zstd")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			resp := rec.Result()
+			require.Equal(t, tc.expCode, resp.StatusCode)
+			require.Equal(t, tc.expEncoding, resp.Header.Get("Content-Encoding"))
+
+			var r io.Reader = resp.Body
+			if tc.expEncoding == "zstd" {
+				dec, err := zstd.NewReader(resp.Body)
+				require.NoError(t, err)
+				defer dec.Close()
+				r = dec
+			}
+			got, err := io.ReadAll(r)
+			require.NoError(t, err)
+			require.Equal(t, tc.expBody, string(got))
+		})
+	}
+}
+
+func TestCompressionConfigValidate(t *testing.T) {
+	cfg := CompressionConfig{GzipLevel: gzip.DefaultCompression, ZstdLevel: 3}
+	require.NoError(t, cfg.Validate())
+
+	cfg.Endpoints = []EndpointCompressionConfig{{Path: "/loki/api/v1/series", ZstdLevel: 23}}
+	require.EqualError(t, cfg.Validate(), "endpoint /loki/api/v1/series: response compression: invalid zstd level 23")
+
+	cfg.Endpoints = nil
+	cfg.GzipLevel = 10
+	require.EqualError(t, cfg.Validate(), "response compression: invalid gzip level 10")
+}
@@ -18,6 +18,8 @@ type Config struct {
 	CompressResponses bool   `yaml:"compress_responses"`
 	DownstreamURL     string `yaml:"downstream_url"`
 
+	ResponseCompression CompressionConfig `yaml:"response_compression"`
+
 	TailProxyURL string           `yaml:"tail_proxy_url"`
 	TLS          tls.ClientConfig `yaml:"tail_tls_config"`
 }
@@ -28,8 +30,16 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.FrontendV1.RegisterFlags(f)
 	cfg.FrontendV2.RegisterFlags(f)
 	cfg.TLS.RegisterFlagsWithPrefix("frontend.tail-tls-config", f)
+	cfg.ResponseCompression.RegisterFlagsWithPrefix("frontend.response-compression", f)
 
-	f.BoolVar(&cfg.CompressResponses, "querier.compress-http-responses", false, "Compress HTTP responses.")
+	f.BoolVar(&cfg.CompressResponses, "querier.compress-http-responses", false, "Compress HTTP responses with gzip, or with zstd for the clients accepting it.")
 	f.StringVar(&cfg.DownstreamURL, "frontend.downstream-url", "", "URL of downstream Loki.")
 	f.StringVar(&cfg.TailProxyURL, "frontend.tail-proxy-url", "", "URL of querier for tail proxy.")
 }
+
+func (cfg *Config) Validate() error {
+	if err := cfg.ResponseCompression.Validate(); err != nil {
+		return err
+	}
+	return cfg.FrontendV2.Validate()
+}
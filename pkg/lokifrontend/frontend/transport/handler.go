@@ -21,6 +21,7 @@ import (
 
 	"github.com/grafana/dskit/tenant"
 
+	"github.com/grafana/loki/pkg/loghttp"
 	querier_stats "github.com/grafana/loki/pkg/querier/stats"
 	"github.com/grafana/loki/pkg/util"
 	util_log "github.com/grafana/loki/pkg/util/log"
@@ -143,8 +144,13 @@ func (f *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.WriteHeader(resp.StatusCode)
+	// The streaming responses are flushed to the client as they are written.
+	var dst io.Writer = w
+	if flusher, ok := w.(http.Flusher); ok && resp.Header.Get("Content-Type") == loghttp.StreamingContentType {
+		dst = flushWriter{w: w, flusher: flusher}
+	}
 	// we don't check for copy error as there is no much we can do at this point
-	_, _ = io.Copy(w, resp.Body)
+	_, _ = io.Copy(dst, resp.Body)
 
 	// Check whether we should parse the query string.
 	shouldReportSlowQuery := f.cfg.LogQueriesLongerThan > 0 && queryResponseTime > f.cfg.LogQueriesLongerThan
@@ -252,3 +258,15 @@ func statsValue(name string, d time.Duration) string {
 	durationInMs := strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', -1, 64)
 	return name + ";dur=" + durationInMs
 }
+
+// flushWriter flushes every write to the client.
+type flushWriter struct {
+	w       io.Writer
+	flusher http.Flusher
+}
+
+func (w flushWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.flusher.Flush()
+	return n, err
+}
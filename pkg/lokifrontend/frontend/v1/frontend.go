@@ -5,6 +5,7 @@ import (
 	"flag"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/go-kit/log"
@@ -23,6 +24,7 @@ import (
 	"github.com/grafana/loki/pkg/scheduler/queue"
 	"github.com/grafana/loki/pkg/util"
 	lokigrpc "github.com/grafana/loki/pkg/util/httpgrpc"
+	"github.com/grafana/loki/pkg/util/httpreq"
 	"github.com/grafana/loki/pkg/util/validation"
 )
 
@@ -32,12 +34,14 @@ var errTooManyRequest = httpgrpc.Errorf(http.StatusTooManyRequests, "too many ou
 type Config struct {
 	MaxOutstandingPerTenant int           `yaml:"max_outstanding_per_tenant"`
 	QuerierForgetDelay      time.Duration `yaml:"querier_forget_delay"`
+	MaxQueueHierarchyLevels int           `yaml:"max_queue_hierarchy_levels"`
 }
 
 // RegisterFlags adds the flags required to config this to the given FlagSet.
 func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&cfg.MaxOutstandingPerTenant, "querier.max-outstanding-requests-per-tenant", 2048, "Maximum number of outstanding requests per tenant per frontend; requests beyond this error with HTTP 429.")
 	f.DurationVar(&cfg.QuerierForgetDelay, "query-frontend.querier-forget-delay", 0, "In the event a tenant is repeatedly sending queries that lead the querier to crash or be killed due to an out-of-memory error, the crashed querier will be disconnected from the query frontend and a new querier will be immediately assigned to the tenant’s shard. This invalidates the assumption that shuffle sharding can be used to reduce the impact on tenants. This option mitigates the impact by configuring a delay between when a querier disconnects because of a crash and when the crashed querier is actually removed from the tenant's shard.")
+	f.IntVar(&cfg.MaxQueueHierarchyLevels, "query-frontend.max-queue-hierarchy-levels", 3, "Maximum number of levels of nesting of hierarchical queues, when the queries are enqueued in the query frontend instead of the query scheduler. 0 means that hierarchical queues are disabled.")
 }
 
 type Limits interface {
@@ -94,7 +98,7 @@ func New(cfg Config, limits Limits, log log.Logger, registerer prometheus.Regist
 		}),
 	}
 
-	f.requestQueue = queue.NewRequestQueue(cfg.MaxOutstandingPerTenant, cfg.QuerierForgetDelay, queueMetrics)
+	f.requestQueue = queue.NewRequestQueue(cfg.MaxOutstandingPerTenant, cfg.QuerierForgetDelay, nil, queueMetrics)
 	f.activeUsers = util.NewActiveUsersCleanupWithDefaultValues(f.cleanupInactiveUserMetrics)
 
 	var err error
@@ -313,10 +317,28 @@ func (f *Frontend) queueRequest(ctx context.Context, req *request) error {
 	// aggregate the max queriers limit in the case of a multi tenant query
 	maxQueriers := validation.SmallestPositiveNonZeroIntPerTenant(tenantIDs, f.limits.MaxQueriersPerUser)
 
+	// The actor path of the request selects the sub-queue of the tenant it is enqueued
+	// in, so that the actors of a tenant are dequeued fairly.
+	var queuePath []string
+	if f.cfg.MaxQueueHierarchyLevels > 0 {
+		queuePath = httpreq.ExtractActorPath(ctx)
+		if len(queuePath) > f.cfg.MaxQueueHierarchyLevels {
+			return httpgrpc.Errorf(http.StatusBadRequest,
+				"The header %s with value '%s' would result in a sub-queue which is "+
+					"nested %d levels deep, however only %d levels are allowed based on the "+
+					"configuration setting -query-frontend.max-queue-hierarchy-levels",
+				httpreq.LokiActorPathHeader,
+				strings.Join(queuePath, httpreq.LokiActorPathDelimiter),
+				len(queuePath),
+				f.cfg.MaxQueueHierarchyLevels,
+			)
+		}
+	}
+
 	joinedTenantID := tenant.JoinTenantIDs(tenantIDs)
 	f.activeUsers.UpdateUserTimestamp(joinedTenantID, now)
 
-	err = f.requestQueue.Enqueue(joinedTenantID, nil, req, maxQueriers, nil)
+	err = f.requestQueue.Enqueue(joinedTenantID, queuePath, req, maxQueriers, nil)
 	if err == queue.ErrTooManyRequests {
 		return errTooManyRequest
 	}
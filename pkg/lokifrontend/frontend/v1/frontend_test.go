@@ -6,6 +6,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -23,6 +24,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/uber/jaeger-client-go"
 	"github.com/uber/jaeger-client-go/config"
+	"github.com/weaveworks/common/httpgrpc"
 	httpgrpc_server "github.com/weaveworks/common/httpgrpc/server"
 	"github.com/weaveworks/common/middleware"
 	"github.com/weaveworks/common/user"
@@ -33,6 +35,7 @@ import (
 	"github.com/grafana/loki/pkg/lokifrontend/frontend/v1/frontendv1pb"
 	querier_worker "github.com/grafana/loki/pkg/querier/worker"
 	"github.com/grafana/loki/pkg/scheduler/queue"
+	"github.com/grafana/loki/pkg/util/httpreq"
 )
 
 const (
@@ -129,7 +132,7 @@ func TestFrontendCheckReady(t *testing.T) {
 			qm := queue.NewMetrics("query_frontend", nil)
 			f := &Frontend{
 				log:          log.NewNopLogger(),
-				requestQueue: queue.NewRequestQueue(5, 0, qm),
+				requestQueue: queue.NewRequestQueue(5, 0, nil, qm),
 			}
 			for i := 0; i < tt.connectedClients; i++ {
 				f.requestQueue.RegisterQuerierConnection("test")
@@ -222,6 +225,51 @@ func TestFrontendMetricsCleanup(t *testing.T) {
 	}
 }
 
+func TestFrontendActorPath(t *testing.T) {
+	cfg := defaultFrontendConfig()
+	cfg.MaxQueueHierarchyLevels = 1
+	f, err := New(cfg, limits{}, log.NewNopLogger(), nil)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), f))
+	defer func() {
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), f))
+	}()
+
+	enqueue := func(actor string) error {
+		var ctx context.Context
+		h := httpreq.PropagateHeadersMiddleware(httpreq.LokiActorPathHeader).Wrap(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			ctx = r.Context()
+		}))
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set(httpreq.LokiActorPathHeader, actor)
+		h.ServeHTTP(httptest.NewRecorder(), r)
+
+		return f.queueRequest(user.InjectOrgID(ctx, "1"), &request{
+			originalCtx: ctx,
+			request:     &httpgrpc.HTTPRequest{Url: actor},
+		})
+	}
+
+	err = enqueue("users|joe")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "only 1 levels are allowed")
+
+	// The requests of the actors are dequeued in turn.
+	for _, actor := range []string{"joe", "joe", "joe", "jane"} {
+		require.NoError(t, enqueue(actor))
+	}
+	f.requestQueue.RegisterQuerierConnection("querier")
+	last := queue.StartIndex
+	var dequeued []string
+	for i := 0; i < 4; i++ {
+		req, idx, err := f.requestQueue.Dequeue(context.Background(), last, "querier")
+		require.NoError(t, err)
+		last = idx
+		dequeued = append(dequeued, req.(*request).request.Url)
+	}
+	require.Equal(t, []string{"joe", "jane", "joe", "joe"}, dequeued)
+}
+
 func testFrontend(t *testing.T, config Config, handler http.Handler, test func(addr string, frontend *Frontend), matchMaxConcurrency bool, reg prometheus.Registerer) {
 	logger := log.NewNopLogger()
 
@@ -167,6 +167,9 @@ func (m *StreamAdapter) GetHash() uint64 {
 type EntryAdapter struct {
 	Timestamp time.Time `protobuf:"bytes,1,opt,name=timestamp,proto3,stdtime" json:"ts"`
 	Line      string    `protobuf:"bytes,2,opt,name=line,proto3" json:"line"`
+	// structuredMetadata contains key/value pairs attached to the entry which
+	// are stored alongside the line but are not indexed as stream labels.
+	StructuredMetadata []LabelPairAdapter `protobuf:"bytes,3,rep,name=structuredMetadata,proto3" json:"structuredMetadata,omitempty"`
 }
 
 func (m *EntryAdapter) Reset()      { *m = EntryAdapter{} }
@@ -215,44 +218,108 @@ func (m *EntryAdapter) GetLine() string {
 	return ""
 }
 
+func (m *EntryAdapter) GetStructuredMetadata() []LabelPairAdapter {
+	if m != nil {
+		return m.StructuredMetadata
+	}
+	return nil
+}
+
+type LabelPairAdapter struct {
+	Name  string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Value string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (m *LabelPairAdapter) Reset()      { *m = LabelPairAdapter{} }
+func (*LabelPairAdapter) ProtoMessage() {}
+func (*LabelPairAdapter) Descriptor() ([]byte, []int) {
+	return fileDescriptor_35ec442956852c9e, []int{4}
+}
+func (m *LabelPairAdapter) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *LabelPairAdapter) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_LabelPairAdapter.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *LabelPairAdapter) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LabelPairAdapter.Merge(m, src)
+}
+func (m *LabelPairAdapter) XXX_Size() int {
+	return m.Size()
+}
+func (m *LabelPairAdapter) XXX_DiscardUnknown() {
+	xxx_messageInfo_LabelPairAdapter.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LabelPairAdapter proto.InternalMessageInfo
+
+func (m *LabelPairAdapter) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *LabelPairAdapter) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*PushRequest)(nil), "logproto.PushRequest")
 	proto.RegisterType((*PushResponse)(nil), "logproto.PushResponse")
 	proto.RegisterType((*StreamAdapter)(nil), "logproto.StreamAdapter")
 	proto.RegisterType((*EntryAdapter)(nil), "logproto.EntryAdapter")
+	proto.RegisterType((*LabelPairAdapter)(nil), "logproto.LabelPairAdapter")
 }
 
 func init() { proto.RegisterFile("pkg/push/push.proto", fileDescriptor_35ec442956852c9e) }
 
 var fileDescriptor_35ec442956852c9e = []byte{
-	// 422 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x5c, 0x52, 0x41, 0x6f, 0xd3, 0x30,
-	0x18, 0xb5, 0xbb, 0xd2, 0x6d, 0xee, 0x18, 0x92, 0x61, 0xa3, 0x44, 0xc8, 0xae, 0x72, 0xea, 0x85,
-	0x44, 0x2a, 0x07, 0xce, 0x8d, 0x84, 0xb4, 0x23, 0x0a, 0x08, 0x24, 0x6e, 0x0e, 0x78, 0x4e, 0xb4,
-	0x24, 0x0e, 0xb1, 0x83, 0xc4, 0x8d, 0x9f, 0x30, 0xfe, 0x05, 0x3f, 0x65, 0xc7, 0x1e, 0x27, 0x0e,
-	0x81, 0xa6, 0x17, 0x94, 0xd3, 0x7e, 0x02, 0x8a, 0x13, 0xd3, 0xc2, 0xc5, 0x79, 0x7e, 0xfe, 0xfc,
-	0xbd, 0xf7, 0xbd, 0x18, 0x3d, 0x2c, 0xae, 0x84, 0x5f, 0x54, 0x2a, 0x36, 0x8b, 0x57, 0x94, 0x52,
-	0x4b, 0x7c, 0x94, 0x4a, 0x61, 0x90, 0xf3, 0x48, 0x48, 0x21, 0x0d, 0xf4, 0x3b, 0xd4, 0x9f, 0x3b,
-	0x54, 0x48, 0x29, 0x52, 0xee, 0x9b, 0x5d, 0x54, 0x5d, 0xfa, 0x3a, 0xc9, 0xb8, 0xd2, 0x2c, 0x2b,
-	0xfa, 0x02, 0xf7, 0x1d, 0x9a, 0xbe, 0xaa, 0x54, 0x1c, 0xf2, 0x4f, 0x15, 0x57, 0x1a, 0x5f, 0xa0,
-	0x43, 0xa5, 0x4b, 0xce, 0x32, 0x35, 0x83, 0xf3, 0x83, 0xc5, 0x74, 0xf9, 0xd8, 0xb3, 0x0a, 0xde,
-	0x6b, 0x73, 0xb0, 0xfa, 0xc8, 0x0a, 0xcd, 0xcb, 0xe0, 0xec, 0x47, 0x4d, 0x27, 0x3d, 0xd5, 0xd6,
-	0xd4, 0xde, 0x0a, 0x2d, 0x70, 0x4f, 0xd1, 0x49, 0xdf, 0x58, 0x15, 0x32, 0x57, 0xdc, 0xfd, 0x06,
-	0xd1, 0xfd, 0x7f, 0x3a, 0x60, 0x17, 0x4d, 0x52, 0x16, 0xf1, 0xb4, 0x93, 0x82, 0x8b, 0xe3, 0x00,
-	0xb5, 0x35, 0x1d, 0x98, 0x70, 0xf8, 0xe2, 0x15, 0x3a, 0xe4, 0xb9, 0x2e, 0x13, 0xae, 0x66, 0x23,
-	0xe3, 0xe7, 0x7c, 0xe7, 0xe7, 0x65, 0xae, 0xcb, 0x2f, 0xd6, 0xce, 0x83, 0x9b, 0x9a, 0x82, 0xce,
-	0xc8, 0x50, 0x1e, 0x5a, 0x80, 0x9f, 0xa0, 0x71, 0xcc, 0x54, 0x3c, 0x3b, 0x98, 0xc3, 0xc5, 0x38,
-	0xb8, 0xd7, 0xd6, 0x14, 0x3e, 0x0b, 0x0d, 0xe5, 0x7e, 0x46, 0x27, 0xfb, 0x4d, 0xf0, 0x05, 0x3a,
-	0xfe, 0x9b, 0x8f, 0x31, 0x35, 0x5d, 0x3a, 0x5e, 0x9f, 0xa0, 0x67, 0x13, 0xf4, 0xde, 0xd8, 0x8a,
-	0xe0, 0x74, 0xd0, 0x1c, 0x69, 0x75, 0xfd, 0x93, 0xc2, 0x70, 0x77, 0x19, 0x3f, 0x45, 0xe3, 0x34,
-	0xc9, 0xf9, 0x6c, 0x64, 0x26, 0x3b, 0x6a, 0x6b, 0x6a, 0xf6, 0xa1, 0x59, 0x97, 0x2b, 0x34, 0xe9,
-	0xb2, 0xe1, 0x25, 0x7e, 0x81, 0xc6, 0x1d, 0xc2, 0x67, 0xbb, 0xb1, 0xf6, 0x7e, 0x87, 0x73, 0xfe,
-	0x3f, 0x3d, 0x84, 0x09, 0x82, 0xb7, 0xeb, 0x0d, 0x01, 0xb7, 0x1b, 0x02, 0xee, 0x36, 0x04, 0x7e,
-	0x6d, 0x08, 0xfc, 0xde, 0x10, 0x78, 0xd3, 0x10, 0xb8, 0x6e, 0x08, 0xfc, 0xd5, 0x10, 0xf8, 0xbb,
-	0x21, 0xe0, 0xae, 0x21, 0xf0, 0x7a, 0x4b, 0xc0, 0x7a, 0x4b, 0xc0, 0xed, 0x96, 0x80, 0xf7, 0x73,
-	0x91, 0xe8, 0xb8, 0x8a, 0xbc, 0x0f, 0x32, 0xf3, 0x45, 0xc9, 0x2e, 0x59, 0xce, 0xfc, 0x54, 0x5e,
-	0x25, 0xbe, 0x7d, 0x5b, 0xd1, 0xc4, 0xa8, 0x3d, 0xff, 0x13, 0x00, 0x00, 0xff, 0xff, 0x5c, 0x30,
-	0xfc, 0xe9, 0x6e, 0x02, 0x00, 0x00,
+	// 500 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x53, 0x41, 0x6f, 0xd3, 0x30,
+	0x14, 0x8e, 0xdb, 0xae, 0xdb, 0xdc, 0x31, 0x90, 0xd9, 0x46, 0x88, 0x26, 0xa7, 0x8a, 0x38, 0xf4,
+	0x00, 0x89, 0x54, 0x0e, 0x5c, 0xb8, 0x34, 0x12, 0xd2, 0x0e, 0x20, 0x4d, 0x06, 0x81, 0xc4, 0xcd,
+	0x5d, 0xbd, 0x24, 0x5a, 0x12, 0x07, 0xdb, 0x41, 0xda, 0x8d, 0x9f, 0x30, 0xfe, 0x05, 0x3f, 0x65,
+	0xc7, 0x1e, 0x27, 0x0e, 0x81, 0xa6, 0x97, 0xa9, 0xa7, 0xfd, 0x04, 0x14, 0x27, 0xa1, 0x63, 0xec,
+	0xe2, 0x7e, 0xef, 0xf9, 0xbd, 0xf7, 0x7d, 0xef, 0x73, 0x03, 0x1f, 0x67, 0x67, 0x81, 0x97, 0xe5,
+	0x32, 0xd4, 0x87, 0x9b, 0x09, 0xae, 0x38, 0xda, 0x8a, 0x79, 0xa0, 0x91, 0xb5, 0x17, 0xf0, 0x80,
+	0x6b, 0xe8, 0x55, 0xa8, 0xbe, 0xb7, 0xec, 0x80, 0xf3, 0x20, 0x66, 0x9e, 0x8e, 0xa6, 0xf9, 0xa9,
+	0xa7, 0xa2, 0x84, 0x49, 0x45, 0x93, 0xac, 0x2e, 0x70, 0x3e, 0xc1, 0xc1, 0x71, 0x2e, 0x43, 0xc2,
+	0xbe, 0xe4, 0x4c, 0x2a, 0x74, 0x04, 0x37, 0xa5, 0x12, 0x8c, 0x26, 0xd2, 0x04, 0xc3, 0xee, 0x68,
+	0x30, 0x7e, 0xe2, 0xb6, 0x0c, 0xee, 0x7b, 0x7d, 0x31, 0x99, 0xd1, 0x4c, 0x31, 0xe1, 0xef, 0xff,
+	0x2c, 0xec, 0x7e, 0x9d, 0x5a, 0x15, 0x76, 0xdb, 0x45, 0x5a, 0xe0, 0xec, 0xc2, 0x9d, 0x7a, 0xb0,
+	0xcc, 0x78, 0x2a, 0x99, 0xf3, 0x1d, 0xc0, 0x07, 0xff, 0x4c, 0x40, 0x0e, 0xec, 0xc7, 0x74, 0xca,
+	0xe2, 0x8a, 0x0a, 0x8c, 0xb6, 0x7d, 0xb8, 0x2a, 0xec, 0x26, 0x43, 0x9a, 0x5f, 0x34, 0x81, 0x9b,
+	0x2c, 0x55, 0x22, 0x62, 0xd2, 0xec, 0x68, 0x3d, 0x07, 0x6b, 0x3d, 0x6f, 0x52, 0x25, 0xce, 0x5b,
+	0x39, 0x0f, 0x2f, 0x0b, 0xdb, 0xa8, 0x84, 0x34, 0xe5, 0xa4, 0x05, 0xe8, 0x29, 0xec, 0x85, 0x54,
+	0x86, 0x66, 0x77, 0x08, 0x46, 0x3d, 0x7f, 0x63, 0x55, 0xd8, 0xe0, 0x05, 0xd1, 0x29, 0xe7, 0x1a,
+	0xc0, 0x9d, 0xdb, 0x53, 0xd0, 0x11, 0xdc, 0xfe, 0x6b, 0x90, 0x56, 0x35, 0x18, 0x5b, 0x6e, 0x6d,
+	0xa1, 0xdb, 0x5a, 0xe8, 0x7e, 0x68, 0x2b, 0xfc, 0xdd, 0x86, 0xb4, 0xa3, 0xe4, 0xc5, 0x2f, 0x1b,
+	0x90, 0x75, 0x33, 0x3a, 0x84, 0xbd, 0x38, 0x4a, 0x99, 0xd9, 0xd1, 0xab, 0x6d, 0xad, 0x0a, 0x5b,
+	0xc7, 0x44, 0x9f, 0x28, 0x83, 0x48, 0x2a, 0x91, 0x9f, 0xa8, 0x5c, 0xb0, 0xd9, 0x3b, 0xa6, 0xe8,
+	0x8c, 0x2a, 0x6a, 0x76, 0xf5, 0x86, 0xd6, 0x7a, 0xc3, 0xb7, 0x95, 0x09, 0xc7, 0x34, 0x12, 0xed,
+	0x96, 0xcf, 0x1a, 0xc2, 0xc3, 0xff, 0xbb, 0x9f, 0xf3, 0x24, 0x52, 0x2c, 0xc9, 0xd4, 0x39, 0xb9,
+	0x67, 0xb6, 0xf3, 0x1a, 0x3e, 0xba, 0x3b, 0x0d, 0x21, 0xd8, 0x4b, 0x69, 0xc2, 0x6a, 0xfb, 0x89,
+	0xc6, 0x68, 0x0f, 0x6e, 0x7c, 0xa5, 0x71, 0xde, 0x08, 0x27, 0x75, 0x30, 0x9e, 0xc0, 0x7e, 0xf5,
+	0x98, 0x4c, 0xa0, 0x57, 0xb0, 0x57, 0x21, 0xb4, 0xbf, 0x56, 0x79, 0xeb, 0xff, 0x63, 0x1d, 0xdc,
+	0x4d, 0x37, 0xaf, 0x6f, 0xf8, 0x1f, 0xe7, 0x0b, 0x6c, 0x5c, 0x2d, 0xb0, 0x71, 0xb3, 0xc0, 0xe0,
+	0x5b, 0x89, 0xc1, 0x8f, 0x12, 0x83, 0xcb, 0x12, 0x83, 0x79, 0x89, 0xc1, 0xef, 0x12, 0x83, 0xeb,
+	0x12, 0x1b, 0x37, 0x25, 0x06, 0x17, 0x4b, 0x6c, 0xcc, 0x97, 0xd8, 0xb8, 0x5a, 0x62, 0xe3, 0xf3,
+	0x30, 0x88, 0x54, 0x98, 0x4f, 0xdd, 0x13, 0x9e, 0x78, 0x81, 0xa0, 0xa7, 0x34, 0xa5, 0x5e, 0xcc,
+	0xcf, 0x22, 0xaf, 0xfd, 0x18, 0xa6, 0x7d, 0xcd, 0xf6, 0xf2, 0xcf, 0x00, 0x0b, 0xd5, 0xe4, 0x65,
+	0x1f, 0x03, 0x00, 0x00,
 }
 
 func (this *PushRequest) Equal(that interface{}) bool {
@@ -365,6 +432,41 @@ func (this *EntryAdapter) Equal(that interface{}) bool {
 	if this.Line != that1.Line {
 		return false
 	}
+	if len(this.StructuredMetadata) != len(that1.StructuredMetadata) {
+		return false
+	}
+	for i := range this.StructuredMetadata {
+		if !this.StructuredMetadata[i].Equal(&that1.StructuredMetadata[i]) {
+			return false
+		}
+	}
+	return true
+}
+func (this *LabelPairAdapter) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*LabelPairAdapter)
+	if !ok {
+		that2, ok := that.(LabelPairAdapter)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Name != that1.Name {
+		return false
+	}
+	if this.Value != that1.Value {
+		return false
+	}
 	return true
 }
 func (this *PushRequest) GoString() string {
@@ -408,10 +510,28 @@ func (this *EntryAdapter) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 6)
+	s := make([]string, 0, 7)
 	s = append(s, "&push.EntryAdapter{")
 	s = append(s, "Timestamp: "+fmt.Sprintf("%#v", this.Timestamp)+",\n")
 	s = append(s, "Line: "+fmt.Sprintf("%#v", this.Line)+",\n")
+	if this.StructuredMetadata != nil {
+		vs := make([]LabelPairAdapter, len(this.StructuredMetadata))
+		for i := range vs {
+			vs[i] = this.StructuredMetadata[i]
+		}
+		s = append(s, "StructuredMetadata: "+fmt.Sprintf("%#v", vs)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *LabelPairAdapter) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 6)
+	s = append(s, "&push.LabelPairAdapter{")
+	s = append(s, "Name: "+fmt.Sprintf("%#v", this.Name)+",\n")
+	s = append(s, "Value: "+fmt.Sprintf("%#v", this.Value)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -633,6 +753,20 @@ func (m *EntryAdapter) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.StructuredMetadata) > 0 {
+		for iNdEx := len(m.StructuredMetadata) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.StructuredMetadata[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintPush(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
 	if len(m.Line) > 0 {
 		i -= len(m.Line)
 		copy(dAtA[i:], m.Line)
@@ -651,6 +785,43 @@ func (m *EntryAdapter) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *LabelPairAdapter) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *LabelPairAdapter) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *LabelPairAdapter) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Value) > 0 {
+		i -= len(m.Value)
+		copy(dAtA[i:], m.Value)
+		i = encodeVarintPush(dAtA, i, uint64(len(m.Value)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Name) > 0 {
+		i -= len(m.Name)
+		copy(dAtA[i:], m.Name)
+		i = encodeVarintPush(dAtA, i, uint64(len(m.Name)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintPush(dAtA []byte, offset int, v uint64) int {
 	offset -= sovPush(v)
 	base := offset
@@ -720,6 +891,29 @@ func (m *EntryAdapter) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovPush(uint64(l))
 	}
+	if len(m.StructuredMetadata) > 0 {
+		for _, e := range m.StructuredMetadata {
+			l = e.Size()
+			n += 1 + l + sovPush(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *LabelPairAdapter) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovPush(uint64(l))
+	}
+	l = len(m.Value)
+	if l > 0 {
+		n += 1 + l + sovPush(uint64(l))
+	}
 	return n
 }
 
@@ -769,9 +963,26 @@ func (this *EntryAdapter) String() string {
 	if this == nil {
 		return "nil"
 	}
+	repeatedStringForStructuredMetadata := "[]LabelPairAdapter{"
+	for _, f := range this.StructuredMetadata {
+		repeatedStringForStructuredMetadata += strings.Replace(strings.Replace(f.String(), "LabelPairAdapter", "LabelPairAdapter", 1), `&`, ``, 1) + ","
+	}
+	repeatedStringForStructuredMetadata += "}"
 	s := strings.Join([]string{`&EntryAdapter{`,
 		`Timestamp:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.Timestamp), "Timestamp", "types.Timestamp", 1), `&`, ``, 1) + `,`,
 		`Line:` + fmt.Sprintf("%v", this.Line) + `,`,
+		`StructuredMetadata:` + repeatedStringForStructuredMetadata + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *LabelPairAdapter) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&LabelPairAdapter{`,
+		`Name:` + fmt.Sprintf("%v", this.Name) + `,`,
+		`Value:` + fmt.Sprintf("%v", this.Value) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -1156,6 +1367,154 @@ func (m *EntryAdapter) Unmarshal(dAtA []byte) error {
 			}
 			m.Line = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StructuredMetadata", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPush
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthPush
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthPush
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.StructuredMetadata = append(m.StructuredMetadata, LabelPairAdapter{})
+			if err := m.StructuredMetadata[len(m.StructuredMetadata)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipPush(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthPush
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *LabelPairAdapter) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowPush
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: LabelPairAdapter: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: LabelPairAdapter: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPush
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthPush
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthPush
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPush
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthPush
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthPush
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Value = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipPush(dAtA[iNdEx:])
@@ -51,20 +51,32 @@ type QueryResponse struct {
 
 // nolint // QuerierAPI defines HTTP handler functions for the querier.
 type QuerierAPI struct {
-	querier Querier
-	cfg     Config
-	limits  Limits
-	engine  *logql.Engine
+	querier             Querier
+	cfg                 Config
+	limits              Limits
+	engine              *logql.Engine
+	queryResponseWriter QueryResponseWriter
 }
 
+// QueryResponseWriter writes the result of a range or instant query to the http.ResponseWriter,
+// in an encoding accepted by the sender of the request.
+type QueryResponseWriter func(r *http.Request, params logql.Params, result logqlmodel.Result, w http.ResponseWriter) error
+
 // NewQuerierAPI returns an instance of the QuerierAPI.
-func NewQuerierAPI(cfg Config, querier Querier, limits Limits, logger log.Logger) *QuerierAPI {
+// The results of range and instant queries are written as JSON if no QueryResponseWriter is given.
+func NewQuerierAPI(cfg Config, querier Querier, limits Limits, queryResponseWriter QueryResponseWriter, logger log.Logger) *QuerierAPI {
 	engine := logql.NewEngine(cfg.Engine, querier, limits, logger)
+	if queryResponseWriter == nil {
+		queryResponseWriter = func(_ *http.Request, _ logql.Params, result logqlmodel.Result, w http.ResponseWriter) error {
+			return marshal.WriteQueryResponseJSON(result, w)
+		}
+	}
 	return &QuerierAPI{
-		cfg:     cfg,
-		limits:  limits,
-		querier: querier,
-		engine:  engine,
+		cfg:                 cfg,
+		limits:              limits,
+		querier:             querier,
+		engine:              engine,
+		queryResponseWriter: queryResponseWriter,
 	}
 }
 
@@ -98,7 +110,7 @@ func (q *QuerierAPI) RangeQueryHandler(w http.ResponseWriter, r *http.Request) {
 		serverutil.WriteError(err, w)
 		return
 	}
-	if err := marshal.WriteQueryResponseJSON(result, w); err != nil {
+	if err := q.queryResponseWriter(r, params, result, w); err != nil {
 		serverutil.WriteError(err, w)
 		return
 	}
@@ -135,7 +147,7 @@ func (q *QuerierAPI) InstantQueryHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := marshal.WriteQueryResponseJSON(result, w); err != nil {
+	if err := q.queryResponseWriter(r, params, result, w); err != nil {
 		serverutil.WriteError(err, w)
 		return
 	}
@@ -30,7 +30,7 @@ func TestTailHandler(t *testing.T) {
 	limits, err := validation.NewOverrides(defaultLimits, nil)
 	require.NoError(t, err)
 
-	api := NewQuerierAPI(mockQuerierConfig(), nil, limits, log.NewNopLogger())
+	api := NewQuerierAPI(mockQuerierConfig(), nil, limits, nil, log.NewNopLogger())
 
 	req, err := http.NewRequest("GET", "/", nil)
 	ctx := user.InjectOrgID(req.Context(), "1|2")
@@ -79,7 +79,7 @@ func TestQueryWrapperMiddleware(t *testing.T) {
 		defaultLimits := defaultLimitsTestConfig()
 		limits, err := validation.NewOverrides(defaultLimits, nil)
 		require.NoError(t, err)
-		api := NewQuerierAPI(mockQuerierConfig(), nil, limits, log.NewNopLogger())
+		api := NewQuerierAPI(mockQuerierConfig(), nil, limits, nil, log.NewNopLogger())
 
 		// request timeout is 5ms but it sleeps for 100ms, so timeout injected in the request is expected.
 		connSimulator := &slowConnectionSimulator{
@@ -119,7 +119,7 @@ func TestQueryWrapperMiddleware(t *testing.T) {
 		defaultLimits.QueryTimeout = model.Duration(shortestTimeout)
 		limits, err := validation.NewOverrides(defaultLimits, nil)
 		require.NoError(t, err)
-		api := NewQuerierAPI(mockQuerierConfig(), nil, limits, log.NewNopLogger())
+		api := NewQuerierAPI(mockQuerierConfig(), nil, limits, nil, log.NewNopLogger())
 
 		// configure old querier:query_timeout parameter.
 		// although it is longer than the limits timeout, it should supersede it.
@@ -166,7 +166,7 @@ func TestQueryWrapperMiddleware(t *testing.T) {
 
 		limits, err := validation.NewOverrides(defaultLimits, nil)
 		require.NoError(t, err)
-		api := NewQuerierAPI(mockQuerierConfig(), nil, limits, log.NewNopLogger())
+		api := NewQuerierAPI(mockQuerierConfig(), nil, limits, nil, log.NewNopLogger())
 
 		connSimulator := &slowConnectionSimulator{
 			sleepFor: time.Millisecond * 100,
@@ -207,7 +207,7 @@ func TestSeriesVolumeHandler(t *testing.T) {
 		querier := newQuerierMock()
 		querier.On("SeriesVolume", mock.Anything, mock.Anything).Return(ret, nil)
 
-		api := NewQuerierAPI(Config{}, querier, nil, log.NewNopLogger())
+		api := NewQuerierAPI(Config{}, querier, nil, nil, log.NewNopLogger())
 
 		req := httptest.NewRequest(http.MethodGet, "/series_volume?start=0&end=1&query=%7Bfoo%3D%22bar%22%7D", nil)
 		err := req.ParseForm()
@@ -233,7 +233,7 @@ func TestSeriesVolumeHandler(t *testing.T) {
 		querier := newQuerierMock()
 		querier.On("SeriesVolume", mock.Anything, mock.Anything).Return(nil, nil)
 
-		api := NewQuerierAPI(Config{}, querier, nil, log.NewNopLogger())
+		api := NewQuerierAPI(Config{}, querier, nil, nil, log.NewNopLogger())
 
 		req := httptest.NewRequest(http.MethodGet, "/series_volume?start=0&end=1&query=%7Bfoo%3D%22bar%22%7D", nil)
 		err := req.ParseForm()
@@ -253,7 +253,7 @@ func TestSeriesVolumeHandler(t *testing.T) {
 		querier := newQuerierMock()
 		querier.On("SeriesVolume", mock.Anything, mock.Anything).Return(nil, errors.New("something bad"))
 
-		api := NewQuerierAPI(Config{}, querier, nil, log.NewNopLogger())
+		api := NewQuerierAPI(Config{}, querier, nil, nil, log.NewNopLogger())
 
 		req := httptest.NewRequest(http.MethodGet, "/series_volume?start=0&end=1&query=%7Bfoo%3D%22bar%22%7D", nil)
 		err := req.ParseForm()
@@ -27,12 +27,19 @@ const (
 // MultiTenantQuerier is able to query across different tenants.
 type MultiTenantQuerier struct {
 	Querier
+
+	// injectTenantLabel adds the tenant label to the results of queries
+	// across different tenants.
+	injectTenantLabel bool
 }
 
 // NewMultiTenantQuerier returns a new querier able to query across different tenants.
-func NewMultiTenantQuerier(querier Querier, _ log.Logger) *MultiTenantQuerier {
+// If injectTenantLabel is true, the results of queries across different tenants
+// are labeled with the tenant ID of each stream or series.
+func NewMultiTenantQuerier(querier Querier, injectTenantLabel bool, _ log.Logger) *MultiTenantQuerier {
 	return &MultiTenantQuerier{
-		Querier: querier,
+		Querier:           querier,
+		injectTenantLabel: injectTenantLabel,
 	}
 }
 
@@ -62,7 +69,10 @@ func (q *MultiTenantQuerier) SelectLogs(ctx context.Context, params logql.Select
 			return nil, err
 		}
 
-		iters[i] = NewTenantEntryIterator(iter, id)
+		if q.injectTenantLabel {
+			iter = NewTenantEntryIterator(iter, id)
+		}
+		iters[i] = iter
 		i++
 	}
 	return iter.NewSortEntryIterator(iters, params.Direction), nil
@@ -93,7 +103,10 @@ func (q *MultiTenantQuerier) SelectSamples(ctx context.Context, params logql.Sel
 			return nil, err
 		}
 
-		iters[i] = NewTenantSampleIterator(iter, id)
+		if q.injectTenantLabel {
+			iter = NewTenantSampleIterator(iter, id)
+		}
+		iters[i] = iter
 		i++
 	}
 	return iter.NewSortSampleIterator(iters), nil
@@ -125,7 +138,7 @@ func (q *MultiTenantQuerier) Label(ctx context.Context, req *logproto.LabelReque
 	}
 
 	// Append tenant ID label name if label names are requested.
-	if !req.Values {
+	if !req.Values && q.injectTenantLabel {
 		responses = append(responses, &logproto.LabelResponse{Values: []string{defaultTenantLabel}})
 	}
 
@@ -150,9 +163,11 @@ func (q *MultiTenantQuerier) Series(ctx context.Context, req *logproto.SeriesReq
 			return nil, err
 		}
 
-		for _, s := range resp.GetSeries() {
-			if _, ok := s.Labels[defaultTenantLabel]; !ok {
-				s.Labels[defaultTenantLabel] = id
+		if q.injectTenantLabel {
+			for _, s := range resp.GetSeries() {
+				if _, ok := s.Labels[defaultTenantLabel]; !ok {
+					s.Labels[defaultTenantLabel] = id
+				}
 			}
 		}
 
@@ -80,7 +80,7 @@ func TestMultiTenantQuerier_SelectLogs(t *testing.T) {
 			querier := newQuerierMock()
 			querier.On("SelectLogs", mock.Anything, mock.Anything).Return(func() iter.EntryIterator { return mockStreamIterator(1, 2) }, nil)
 
-			multiTenantQuerier := NewMultiTenantQuerier(querier, log.NewNopLogger())
+			multiTenantQuerier := NewMultiTenantQuerier(querier, true, log.NewNopLogger())
 
 			ctx := user.InjectOrgID(context.Background(), tc.orgID)
 			params := logql.SelectLogParams{QueryRequest: &logproto.QueryRequest{
@@ -156,7 +156,7 @@ func TestMultiTenantQuerier_SelectSamples(t *testing.T) {
 			querier := newQuerierMock()
 			querier.On("SelectSamples", mock.Anything, mock.Anything).Return(func() iter.SampleIterator { return newSampleIterator() }, nil)
 
-			multiTenantQuerier := NewMultiTenantQuerier(querier, log.NewNopLogger())
+			multiTenantQuerier := NewMultiTenantQuerier(querier, true, log.NewNopLogger())
 
 			ctx := user.InjectOrgID(context.Background(), tc.orgID)
 			params := logql.SelectSampleParams{SampleQueryRequest: &logproto.SampleQueryRequest{
@@ -174,6 +174,28 @@ func TestMultiTenantQuerier_SelectSamples(t *testing.T) {
 	}
 }
 
+func TestMultiTenantQuerier_SelectSamplesWithoutTenantLabel(t *testing.T) {
+	tenant.WithDefaultResolver(tenant.NewMultiResolver())
+
+	querier := newQuerierMock()
+	querier.On("SelectSamples", mock.Anything, mock.Anything).Return(func() iter.SampleIterator { return newSampleIterator() }, nil)
+
+	multiTenantQuerier := NewMultiTenantQuerier(querier, false, log.NewNopLogger())
+
+	ctx := user.InjectOrgID(context.Background(), "1|2")
+	params := logql.SelectSampleParams{SampleQueryRequest: &logproto.SampleQueryRequest{
+		Selector: `count_over_time({foo="bar", __tenant_id__="1"}[1m]) > 10`,
+	}}
+	iter, err := multiTenantQuerier.SelectSamples(ctx, params)
+	require.NoError(t, err)
+
+	received := []string{}
+	for iter.Next() {
+		received = append(received, iter.Labels())
+	}
+	require.ElementsMatch(t, []string{`{app="foo"}`, `{app="bar"}`, `{app="foo"}`, `{app="bar"}`}, received)
+}
+
 func TestMultiTenantQuerier_TenantFilter(t *testing.T) {
 	for _, tc := range []struct {
 		selector string
@@ -333,7 +355,7 @@ func TestMultiTenantQuerier_Label(t *testing.T) {
 		t.Run(tc.desc, func(t *testing.T) {
 			querier := newQuerierMock()
 			querier.On("Label", mock.Anything, mock.Anything).Return(mockLabelResponse([]string{"test"}), nil)
-			multiTenantQuerier := NewMultiTenantQuerier(querier, log.NewNopLogger())
+			multiTenantQuerier := NewMultiTenantQuerier(querier, true, log.NewNopLogger())
 			ctx := user.InjectOrgID(context.Background(), tc.orgID)
 
 			resp, err := multiTenantQuerier.Label(ctx, mockLabelRequest(tc.name))
@@ -397,7 +419,7 @@ func TestMultiTenantQuerierSeries(t *testing.T) {
 		t.Run(tc.desc, func(t *testing.T) {
 			querier := newQuerierMock()
 			querier.On("Series", mock.Anything, mock.Anything).Return(func() *logproto.SeriesResponse { return mockSeriesResponse() }, nil)
-			multiTenantQuerier := NewMultiTenantQuerier(querier, log.NewNopLogger())
+			multiTenantQuerier := NewMultiTenantQuerier(querier, true, log.NewNopLogger())
 			ctx := user.InjectOrgID(context.Background(), tc.orgID)
 
 			resp, err := multiTenantQuerier.Series(ctx, mockSeriesRequest())
@@ -434,7 +456,7 @@ func TestSeriesVolume(t *testing.T) {
 		t.Run(tc.desc, func(t *testing.T) {
 			querier := newQuerierMock()
 			querier.On("SeriesVolume", mock.Anything, mock.Anything).Return(mockLabelValueResponse(), nil)
-			multiTenantQuerier := NewMultiTenantQuerier(querier, log.NewNopLogger())
+			multiTenantQuerier := NewMultiTenantQuerier(querier, true, log.NewNopLogger())
 			ctx := user.InjectOrgID(context.Background(), tc.orgID)
 
 			resp, err := multiTenantQuerier.SeriesVolume(ctx, mockLabelValueRequest())
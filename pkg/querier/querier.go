@@ -60,6 +60,8 @@ type Config struct {
 	MultiTenantQueriesEnabled     bool             `yaml:"multi_tenant_queries_enabled"`
 	QueryTimeout                  time.Duration    `yaml:"query_timeout" doc:"hidden"`
 	PerRequestLimitsEnabled       bool             `yaml:"per_request_limits_enabled"`
+
+	MultiTenantQueriesInjectTenantLabel bool `yaml:"multi_tenant_queries_inject_tenant_label"`
 }
 
 // RegisterFlags register flags.
@@ -72,6 +74,7 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.BoolVar(&cfg.QueryStoreOnly, "querier.query-store-only", false, "Only query the store, and not attempt any ingesters. This is useful for running a standalone querier pool operating only against stored data.")
 	f.BoolVar(&cfg.QueryIngesterOnly, "querier.query-ingester-only", false, "When true, queriers only query the ingesters, and not stored data. This is useful when the object store is unavailable.")
 	f.BoolVar(&cfg.MultiTenantQueriesEnabled, "querier.multi-tenant-queries-enabled", false, "When true, allow queries to span multiple tenants.")
+	f.BoolVar(&cfg.MultiTenantQueriesInjectTenantLabel, "querier.multi-tenant-queries-inject-tenant-label", true, "When true, the results of queries spanning multiple tenants have the __tenant_id__ label of their tenant. When false, the streams and series of different tenants with the same labels are merged.")
 	f.BoolVar(&cfg.PerRequestLimitsEnabled, "querier.per-request-limits-enabled", false, "When true, querier limits sent via a header are enforced.")
 }
 
@@ -428,8 +431,15 @@ func (q *SingleTenantQuerier) Label(ctx context.Context, req *logproto.LabelRequ
 	}
 
 	results := append(ingesterValues, storeValues)
+	values := listutil.MergeStringLists(results...)
+	// the internal stream shard label isn't exposed.
+	if !req.Values {
+		values = listutil.WithoutShardLabelName(values)
+	} else if req.Name == listutil.ShardLbName {
+		values = nil
+	}
 	return &logproto.LabelResponse{
-		Values: listutil.MergeStringLists(results...),
+		Values: values,
 	}, nil
 }
 
@@ -590,6 +600,8 @@ func (q *SingleTenantQuerier) awaitSeries(ctx context.Context, req *logproto.Ser
 	deduped := make(map[string]logproto.SeriesIdentifier)
 	for _, set := range sets {
 		for _, s := range set {
+			// the shards of a stream are collapsed into a single series without the internal stream shard label.
+			delete(s.Labels, listutil.ShardLbName)
 			key := loghttp.LabelSet(s.Labels).String()
 			if _, exists := deduped[key]; !exists {
 				deduped[key] = s
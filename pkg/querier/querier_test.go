@@ -330,6 +330,27 @@ func TestQuerier_SeriesAPI(t *testing.T) {
 				}, resp.GetSeries())
 			},
 		},
+		{
+			"collapses stream shards",
+			mkReq([]string{`{a="1"}`}),
+			func(store *storeMock, querier *queryClientMock, ingester *querierClientMock, limits validation.Limits, req *logproto.SeriesRequest) {
+				ingester.On("Series", mock.Anything, req, mock.Anything).Return(mockSeriesResponse([]map[string]string{
+					{"a": "1", "__stream_shard__": "0"},
+				}), nil)
+
+				store.On("Series", mock.Anything, mock.Anything).Return([]logproto.SeriesIdentifier{
+					{Labels: map[string]string{"a": "1", "__stream_shard__": "1"}},
+				}, nil)
+			},
+			func(t *testing.T, q *SingleTenantQuerier, req *logproto.SeriesRequest) {
+				ctx := user.InjectOrgID(context.Background(), "test")
+				resp, err := q.Series(ctx, req)
+				require.Nil(t, err)
+				require.Equal(t, []logproto.SeriesIdentifier{
+					{Labels: map[string]string{"a": "1"}},
+				}, resp.GetSeries())
+			},
+		},
 	} {
 		t.Run(tc.desc, func(t *testing.T) {
 			store := newStoreMock()
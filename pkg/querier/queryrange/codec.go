@@ -300,6 +300,15 @@ func (Codec) EncodeRequest(ctx context.Context, r queryrangebase.Request) (*http
 		header.Set(httpreq.LokiActorPathHeader, actor)
 	}
 
+	priority := httpreq.ExtractHeader(ctx, httpreq.LokiQueryPriorityHeader)
+	if priority != "" {
+		header.Set(httpreq.LokiQueryPriorityHeader, priority)
+	}
+
+	if quantileSketches := httpreq.ExtractHeader(ctx, httpreq.LokiQuantileSketchesHeader); quantileSketches != "" {
+		header.Set(httpreq.LokiQuantileSketchesHeader, quantileSketches)
+	}
+
 	switch request := r.(type) {
 	case *LokiRequest:
 		params := url.Values{
@@ -323,6 +332,7 @@ func (Codec) EncodeRequest(ctx context.Context, r queryrangebase.Request) (*http
 			Path:     "/loki/api/v1/query_range",
 			RawQuery: params.Encode(),
 		}
+		header.Set("Accept", acceptQueryResponse)
 		req := &http.Request{
 			Method:     "GET",
 			RequestURI: u.String(), // This is what the httpgrpc code looks at.
@@ -387,6 +397,7 @@ func (Codec) EncodeRequest(ctx context.Context, r queryrangebase.Request) (*http
 			Path:     "/loki/api/v1/query",
 			RawQuery: params.Encode(),
 		}
+		header.Set("Accept", acceptQueryResponse)
 		req := &http.Request{
 			Method:     "GET",
 			RequestURI: u.String(), // This is what the httpgrpc code looks at.
@@ -510,6 +521,14 @@ func (Codec) DecodeResponse(_ context.Context, r *http.Response, req queryrangeb
 			Headers:  httpResponseHeadersToPromResponseHeaders(r.Header),
 		}, nil
 	default:
+		if strings.HasPrefix(r.Header.Get("Content-Type"), ProtobufType) {
+			resp, err := decodeQueryResponseProtobuf(buf, req, httpResponseHeadersToPromResponseHeaders(r.Header))
+			if err != nil {
+				return nil, httpgrpc.Errorf(http.StatusInternalServerError, "error decoding response: %v", err)
+			}
+			return resp, nil
+		}
+
 		var resp loghttp.QueryResponse
 		if err := resp.UnmarshalJSON(buf); err != nil {
 			return nil, httpgrpc.Errorf(http.StatusInternalServerError, "error decoding response: %v", err)
@@ -1060,6 +1079,8 @@ func httpResponseHeadersToPromResponseHeaders(httpHeaders http.Header) []queryra
 	for h, hv := range httpHeaders {
 		promHeaders = append(promHeaders, queryrangebase.PrometheusResponseHeader{Name: h, Values: hv})
 	}
+	// Sort the headers to decode the same response into the same headers.
+	sort.Slice(promHeaders, func(i, j int) bool { return promHeaders[i].Name < promHeaders[j].Name })
 
 	return promHeaders
 }
@@ -0,0 +1,463 @@
+package queryrange
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/tenant"
+	"github.com/prometheus/common/model"
+	"github.com/weaveworks/common/httpgrpc"
+
+	"github.com/grafana/loki/pkg/loghttp"
+	"github.com/grafana/loki/pkg/logql"
+	"github.com/grafana/loki/pkg/logql/syntax"
+	"github.com/grafana/loki/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/pkg/storage/config"
+	"github.com/grafana/loki/pkg/storage/stores/index/stats"
+	"github.com/grafana/loki/pkg/util"
+	logutil "github.com/grafana/loki/pkg/util/log"
+	"github.com/grafana/loki/pkg/util/validation"
+)
+
+// Types of the queries explained, as set by the type URL query parameter of the requests.
+const (
+	explainRange   = "range"
+	explainInstant = "instant"
+	explainSeries  = "series"
+	explainLabels  = "labels"
+)
+
+// QueryExplanation describes how the query frontend processes a query.
+type QueryExplanation struct {
+	Query string    `json:"query"`
+	Type  string    `json:"type"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Step  string    `json:"step,omitempty"`
+
+	// Matchers are the stream selectors the index is queried with.
+	Matchers []string `json:"matchers"`
+	// Middlewares are the middlewares of the query frontend the query goes through, in order.
+	Middlewares []string `json:"middlewares"`
+
+	SplitInterval string `json:"splitInterval"`
+	Splits        int    `json:"splits"`
+	SplitQuery    string `json:"splitQuery,omitempty"`
+	Shards        int    `json:"shards"`
+	ShardedQuery  string `json:"shardedQuery,omitempty"`
+
+	// IndexStats are the streams, chunks, bytes and entries the query is estimated to process.
+	// They are not requested for series and labels queries, which do not process the chunks.
+	IndexStats *stats.Stats `json:"indexStats,omitempty"`
+}
+
+type queryExplainer struct {
+	logger       log.Logger
+	cfg          Config
+	engineOpts   logql.EngineOpts
+	limits       Limits
+	schema       config.SchemaConfig
+	statsHandler queryrangebase.Handler
+	metrics      *logql.MapperMetrics
+	rangeMetrics *logql.MapperMetrics
+}
+
+// newQueryExplainer returns a round tripper explaining the queries without executing them.
+// Only the index stats of the queries are requested from the queriers.
+func newQueryExplainer(logger log.Logger, cfg Config, engineOpts logql.EngineOpts, limits Limits, schema config.SchemaConfig, statsRT http.RoundTripper) http.RoundTripper {
+	return queryExplainer{
+		logger:       logger,
+		cfg:          cfg,
+		engineOpts:   engineOpts,
+		limits:       limits,
+		schema:       schema,
+		statsHandler: queryrangebase.NewRoundTripperHandler(statsRT, LokiCodec),
+		metrics:      logql.NewShardMapperMetrics(nil),
+		rangeMetrics: logql.NewRangeMapperMetrics(nil),
+	}
+}
+
+func (e queryExplainer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := req.ParseForm(); err != nil {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+	}
+
+	tenantIDs, err := tenant.TenantIDs(req.Context())
+	if err != nil {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+	}
+
+	var explanation *QueryExplanation
+	switch typ := req.Form.Get("type"); typ {
+	case "", explainRange:
+		explanation, err = e.explainRange(req, tenantIDs)
+	case explainInstant:
+		explanation, err = e.explainInstant(req, tenantIDs)
+	case explainSeries:
+		explanation, err = e.explainSeries(req, tenantIDs)
+	case explainLabels:
+		explanation, err = e.explainLabels(req, tenantIDs)
+	default:
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, "unsupported query type %s", typ)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := jsonStd.Marshal(struct {
+		Status string            `json:"status"`
+		Data   *QueryExplanation `json:"data"`
+	}{
+		Status: loghttp.QueryStatusSuccess,
+		Data:   explanation,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(b)),
+	}, nil
+}
+
+// explainRange explains a range query, following NewMetricTripperware for metric queries,
+// NewLogFilterTripperware for log queries with a filter and NewLimitedTripperware for the other log queries.
+func (e queryExplainer) explainRange(r *http.Request, tenantIDs []string) (*QueryExplanation, error) {
+	ctx := r.Context()
+	rangeQuery, err := loghttp.ParseRangeQuery(r)
+	if err != nil {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+	}
+	expr, err := syntax.ParseExpr(rangeQuery.Query)
+	if err != nil {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+	}
+
+	req := &LokiRequest{
+		Query:     rangeQuery.Query,
+		Limit:     rangeQuery.Limit,
+		Step:      rangeQuery.Step.Milliseconds(),
+		Interval:  rangeQuery.Interval.Milliseconds(),
+		Direction: rangeQuery.Direction,
+		Path:      "/loki/api/v1/query_range",
+		StartTs:   rangeQuery.Start.UTC(),
+		EndTs:     rangeQuery.End.UTC(),
+	}
+
+	x := &QueryExplanation{
+		Query: rangeQuery.Query,
+		Start: req.StartTs,
+		End:   req.EndTs,
+		Step:  rangeQuery.Step.String(),
+	}
+
+	var (
+		splitter = splitByTime
+		limited  bool
+	)
+	switch concrete := expr.(type) {
+	case syntax.SampleExpr:
+		x.Type = "metric"
+		// Like the step align middleware, the queries with a step below a millisecond are not aligned.
+		if req.GetStep() > 0 {
+			splitter = splitMetricByTime
+		}
+	case syntax.LogSelectorExpr:
+		x.Type = "log"
+		limited = !concrete.HasFilter()
+	default:
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, "unsupported query type %T", expr)
+	}
+
+	x.Middlewares = []string{"limits"}
+	if x.Type == "metric" && req.GetStep() > 0 && e.alignsWithStep(ctx, tenantIDs) {
+		x.Middlewares = append(x.Middlewares, "step_align")
+		req = req.WithStartEnd((req.GetStart()/req.GetStep())*req.GetStep(), (req.GetEnd()/req.GetStep())*req.GetStep()).(*LokiRequest)
+		x.Start, x.End = req.StartTs, req.EndTs
+	}
+	x.Middlewares = append(x.Middlewares, "query_size_limiter")
+
+	if err := e.explainSplit(req, tenantIDs, e.limits, splitter, x); err != nil {
+		return nil, err
+	}
+
+	if err := e.explainIndexStats(ctx, tenantIDs, expr, req, x); err != nil {
+		return nil, err
+	}
+
+	// Limited queries are neither cached, sharded nor retried.
+	if limited {
+		x.Middlewares = append(x.Middlewares, "querier_size_limiter")
+		return x, nil
+	}
+
+	if e.cfg.CacheResults {
+		if x.Type == "metric" {
+			x.Middlewares = append(x.Middlewares, "results_cache")
+		} else {
+			x.Middlewares = append(x.Middlewares, "log_results_cache")
+		}
+	}
+
+	if e.cfg.ShardedQueries {
+		x.Middlewares = append(x.Middlewares, "query_sharding")
+		if err := e.explainSharding(ctx, tenantIDs, req, *x.IndexStats, x); err != nil {
+			return nil, err
+		}
+	} else {
+		x.Middlewares = append(x.Middlewares, "querier_size_limiter")
+	}
+
+	e.explainRetry(x)
+	return x, nil
+}
+
+// explainInstant explains an instant metric query, following NewInstantMetricTripperware.
+func (e queryExplainer) explainInstant(r *http.Request, tenantIDs []string) (*QueryExplanation, error) {
+	ctx := r.Context()
+	instantQuery, err := loghttp.ParseInstantQuery(r)
+	if err != nil {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+	}
+	expr, err := syntax.ParseExpr(instantQuery.Query)
+	if err != nil {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+	}
+	if _, ok := expr.(syntax.SampleExpr); !ok {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, "only metric instant queries are processed by the frontend, got %T", expr)
+	}
+
+	req := &LokiInstantRequest{
+		Query:     instantQuery.Query,
+		Limit:     instantQuery.Limit,
+		Direction: instantQuery.Direction,
+		Path:      "/loki/api/v1/query",
+		TimeTs:    instantQuery.Ts.UTC(),
+	}
+
+	x := &QueryExplanation{
+		Query:       instantQuery.Query,
+		Type:        explainInstant,
+		Start:       req.TimeTs,
+		End:         req.TimeTs,
+		Middlewares: []string{"limits", "query_size_limiter", "split_by_range"},
+		Splits:      1,
+	}
+
+	interval := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, instantMetricQuerySplitDuration(e.limits))
+	if interval > 0 {
+		mapper, err := logql.NewRangeMapper(interval, e.rangeMetrics, quantileSketchSharding(ctx, tenantIDs, e.limits))
+		if err != nil {
+			return nil, err
+		}
+		noop, parsed, err := mapper.Parse(req.GetQuery())
+		if err != nil {
+			return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+		}
+		if !noop {
+			maxRVDuration, _, err := maxRangeVectorAndOffsetDuration(req.GetQuery())
+			if err != nil {
+				return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+			}
+			x.SplitInterval = interval.String()
+			x.Splits = int((maxRVDuration + interval - 1) / interval)
+			x.SplitQuery = parsed.String()
+		}
+	}
+
+	if err := e.explainIndexStats(ctx, tenantIDs, expr, req, x); err != nil {
+		return nil, err
+	}
+
+	if e.cfg.ShardedQueries {
+		x.Middlewares = append(x.Middlewares, "query_sharding")
+		if err := e.explainSharding(ctx, tenantIDs, req, *x.IndexStats, x); err != nil {
+			return nil, err
+		}
+	}
+
+	e.explainRetry(x)
+	return x, nil
+}
+
+// explainSeries explains a series query, following NewSeriesTripperware.
+func (e queryExplainer) explainSeries(r *http.Request, tenantIDs []string) (*QueryExplanation, error) {
+	seriesQuery, err := loghttp.ParseAndValidateSeriesQuery(r)
+	if err != nil {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+	}
+
+	req := &LokiSeriesRequest{
+		Match:   seriesQuery.Groups,
+		Path:    "/loki/api/v1/series",
+		StartTs: seriesQuery.Start.UTC(),
+		EndTs:   seriesQuery.End.UTC(),
+	}
+
+	x := &QueryExplanation{
+		Query:       strings.Join(seriesQuery.Groups, ","),
+		Type:        explainSeries,
+		Start:       req.StartTs,
+		End:         req.EndTs,
+		Matchers:    seriesQuery.Groups,
+		Middlewares: []string{"limits"},
+	}
+
+	if err := e.explainSplit(req, tenantIDs, WithMetadataSplitLimits(e.limits), splitByTime, x); err != nil {
+		return nil, err
+	}
+	if e.cfg.CacheSeriesResults {
+		x.Middlewares = append(x.Middlewares, "series_results_cache")
+	}
+	e.explainRetry(x)
+	if e.cfg.ShardedQueries {
+		x.Middlewares = append(x.Middlewares, "series_query_sharding")
+	}
+	return x, nil
+}
+
+// explainLabels explains a label names query, or a label values query if the name URL query
+// parameter is set, following NewLabelsTripperware.
+func (e queryExplainer) explainLabels(r *http.Request, tenantIDs []string) (*QueryExplanation, error) {
+	labelQuery, err := loghttp.ParseLabelQuery(r)
+	if err != nil {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+	}
+
+	path := "/loki/api/v1/labels"
+	if name := r.Form.Get("name"); name != "" {
+		path = fmt.Sprintf("/loki/api/v1/label/%s/values", name)
+	}
+	req := &LokiLabelNamesRequest{
+		Path:    path,
+		StartTs: labelQuery.Start.UTC(),
+		EndTs:   labelQuery.End.UTC(),
+		Query:   labelQuery.Query,
+	}
+
+	x := &QueryExplanation{
+		Query:       labelQuery.Query,
+		Type:        explainLabels,
+		Start:       req.StartTs,
+		End:         req.EndTs,
+		Middlewares: []string{"limits"},
+	}
+	if labelQuery.Query != "" {
+		x.Matchers = []string{labelQuery.Query}
+	}
+
+	if err := e.explainSplit(req, tenantIDs, WithMetadataSplitLimits(e.limits), splitByTime, x); err != nil {
+		return nil, err
+	}
+	if e.cfg.CacheLabelResults {
+		x.Middlewares = append(x.Middlewares, "label_results_cache")
+	}
+	e.explainRetry(x)
+	return x, nil
+}
+
+// explainSplit sets the splits of the query by the split interval of the limits, like the split by interval middleware.
+func (e queryExplainer) explainSplit(req queryrangebase.Request, tenantIDs []string, limits Limits, splitter Splitter, x *QueryExplanation) error {
+	x.Splits = 1
+	interval := validation.MaxDurationOrZeroPerTenant(tenantIDs, limits.QuerySplitDuration)
+	if interval <= 0 {
+		return nil
+	}
+	splits, err := splitter(req, interval)
+	if err != nil {
+		return err
+	}
+	x.Middlewares = append(x.Middlewares, "split_by_interval")
+	x.SplitInterval = interval.String()
+	x.Splits = len(splits)
+	return nil
+}
+
+// explainIndexStats sets the matchers of the query and the index stats of the data it processes.
+func (e queryExplainer) explainIndexStats(ctx context.Context, tenantIDs []string, expr syntax.Expr, req queryrangebase.Request, x *QueryExplanation) error {
+	grps, err := syntax.MatcherGroups(expr)
+	if err != nil {
+		return httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+	}
+	if len(grps) == 0 {
+		grps = append(grps, syntax.MatcherRange{})
+	}
+	for _, g := range grps {
+		x.Matchers = append(x.Matchers, syntax.MatchersString(g.Matchers))
+	}
+
+	logger := logutil.WithContext(ctx, e.logger)
+	parallelism := MinWeightedParallelism(ctx, tenantIDs, e.schema.Configs, e.limits, model.Time(req.GetStart()), model.Time(req.GetEnd()))
+	results, err := getStatsForMatchers(ctx, logger, e.statsHandler, model.Time(req.GetStart()), model.Time(req.GetEnd()), grps, parallelism, e.engineOpts.MaxLookBackPeriod)
+	if err != nil {
+		return err
+	}
+	merged := stats.MergeStats(results...)
+	x.IndexStats = &merged
+	return nil
+}
+
+func (e queryExplainer) explainRetry(x *QueryExplanation) {
+	if e.cfg.MaxRetries > 0 {
+		x.Middlewares = append(x.Middlewares, "retry")
+	}
+}
+
+func (e queryExplainer) alignsWithStep(ctx context.Context, tenantIDs []string) bool {
+	if e.cfg.AlignQueriesWithStep {
+		return true
+	}
+	for _, id := range tenantIDs {
+		if !e.limits.AlignQueriesWithStep(ctx, id) {
+			return false
+		}
+	}
+	return true
+}
+
+// explainSharding sets the shards of the query, estimated from its index stats
+// like the sharding middleware does.
+func (e queryExplainer) explainSharding(ctx context.Context, tenantIDs []string, req queryrangebase.Request, indexStats stats.Stats, x *QueryExplanation) error {
+	minShardingLookback := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, e.limits.MinShardingLookback)
+	if minShardingLookback > 0 && !util.TimeFromMillis(req.GetEnd()).Before(time.Now().Add(-minShardingLookback)) {
+		return nil
+	}
+
+	maxRVDuration, maxOffset, err := maxRangeVectorAndOffsetDuration(req.GetQuery())
+	if err != nil {
+		return nil
+	}
+	conf, err := ShardingConfigs(e.schema.Configs).GetConf(int64(model.Time(req.GetStart()).Add(-maxRVDuration).Add(-maxOffset)), int64(model.Time(req.GetEnd()).Add(-maxOffset)))
+	if err != nil {
+		return nil
+	}
+
+	var shards int
+	switch {
+	case conf.IndexType == config.TSDBType:
+		shards = guessShardFactor(indexStats, 0)
+	case conf.RowShards >= 2:
+		shards = int(conf.RowShards)
+	}
+	if shards == 0 {
+		return nil
+	}
+
+	noop, _, parsed, err := logql.NewShardMapper(logql.ConstantShards(shards), e.metrics, quantileSketchSharding(ctx, tenantIDs, e.limits)).Parse(req.GetQuery())
+	if err != nil {
+		return httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+	}
+	if !noop {
+		x.Shards = shards
+		x.ShardedQuery = parsed.String()
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package queryrange
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/storage/config"
+	util_log "github.com/grafana/loki/pkg/util/log"
+)
+
+func TestQueryExplainTripperware(t *testing.T) {
+	cfg := testConfig
+	cfg.ShardedQueries = true
+	limits := fakeLimits{
+		maxQueryParallelism:     1,
+		tsdbMaxQueryParallelism: 1,
+		splits:                  map[string]time.Duration{"1": time.Hour},
+		instantMetricSplits:     map[string]time.Duration{"1": 5 * time.Minute},
+		metadataSplits:          map[string]time.Duration{"1": 24 * time.Hour},
+	}
+	indexStats := logproto.IndexStatsResponse{Streams: 10, Chunks: 20, Bytes: 1 << 30, Entries: 40}
+
+	for _, tc := range []struct {
+		name     string
+		params   string
+		expCalls int
+		expect   func(t *testing.T, x QueryExplanation)
+	}{
+		{
+			name:     "metric range query",
+			params:   "query=sum(count_over_time(%7Bapp%3D%22foo%22%7D%20%7C%3D%20%22bar%22%5B1m%5D))&start=1575281410&end=1575292210&step=60",
+			expCalls: 1,
+			expect: func(t *testing.T, x QueryExplanation) {
+				require.Equal(t, "metric", x.Type)
+				require.Equal(t, []string{`{app="foo"}`}, x.Matchers)
+				require.Equal(t, []string{"limits", "step_align", "query_size_limiter", "split_by_interval", "results_cache", "query_sharding", "retry"}, x.Middlewares)
+				require.Equal(t, "1h0m0s", x.SplitInterval)
+				require.Equal(t, 4, x.Splits)
+				require.Equal(t, 2, x.Shards)
+				require.NotEmpty(t, x.ShardedQuery)
+				require.Equal(t, &indexStats, x.IndexStats)
+			},
+		},
+		{
+			name:     "metric range query with a step below a millisecond",
+			params:   "query=sum(count_over_time(%7Bapp%3D%22foo%22%7D%5B1m%5D))&start=1575281410&end=1575281410.001&step=0.0005",
+			expCalls: 1,
+			expect: func(t *testing.T, x QueryExplanation) {
+				require.Equal(t, "metric", x.Type)
+				require.NotContains(t, x.Middlewares, "step_align")
+				require.Equal(t, 1, x.Splits)
+			},
+		},
+		{
+			name:     "log query without filter",
+			params:   "query=%7Bapp%3D%22foo%22%7D&start=1575281410&end=1575292210",
+			expCalls: 1,
+			expect: func(t *testing.T, x QueryExplanation) {
+				require.Equal(t, "log", x.Type)
+				require.Equal(t, []string{"limits", "query_size_limiter", "split_by_interval", "querier_size_limiter"}, x.Middlewares)
+				require.Equal(t, 0, x.Shards)
+			},
+		},
+		{
+			name:     "log query with filter",
+			params:   "query=%7Bapp%3D%22foo%22%7D%20%7C%3D%20%22bar%22&start=1575281410&end=1575292210",
+			expCalls: 1,
+			expect: func(t *testing.T, x QueryExplanation) {
+				require.Equal(t, "log", x.Type)
+				require.Equal(t, []string{"limits", "query_size_limiter", "split_by_interval", "log_results_cache", "query_sharding", "retry"}, x.Middlewares)
+			},
+		},
+		{
+			name:     "instant query",
+			params:   "type=instant&query=sum(count_over_time(%7Bapp%3D%22foo%22%7D%5B15m%5D))&time=1575292210",
+			expCalls: 1,
+			expect: func(t *testing.T, x QueryExplanation) {
+				require.Equal(t, "instant", x.Type)
+				require.Equal(t, []string{"limits", "query_size_limiter", "split_by_range", "query_sharding", "retry"}, x.Middlewares)
+				require.Equal(t, "5m0s", x.SplitInterval)
+				require.Equal(t, 3, x.Splits)
+				require.NotEmpty(t, x.SplitQuery)
+				require.Equal(t, &indexStats, x.IndexStats)
+			},
+		},
+		{
+			name:   "series query",
+			params: "type=series&match[]=%7Bapp%3D%22foo%22%7D&start=1575100000&end=1575292210",
+			expect: func(t *testing.T, x QueryExplanation) {
+				require.Equal(t, "series", x.Type)
+				require.Equal(t, []string{"limits", "split_by_interval", "retry", "series_query_sharding"}, x.Middlewares)
+				require.Equal(t, "24h0m0s", x.SplitInterval)
+				require.Equal(t, 3, x.Splits)
+				require.Nil(t, x.IndexStats)
+			},
+		},
+		{
+			name:   "label values query",
+			params: "type=labels&name=app&start=1575100000&end=1575292210",
+			expect: func(t *testing.T, x QueryExplanation) {
+				require.Equal(t, "labels", x.Type)
+				require.Equal(t, []string{"limits", "split_by_interval", "retry"}, x.Middlewares)
+				require.Equal(t, 3, x.Splits)
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tpw, stopper, err := NewTripperware(cfg, testEngineOpts, util_log.Logger, limits, config.SchemaConfig{Configs: testSchemasTSDB}, nil, false, nil)
+			if stopper != nil {
+				defer stopper.Stop()
+			}
+			require.NoError(t, err)
+			rt, err := newfakeRoundTripper()
+			require.NoError(t, err)
+			defer rt.Close()
+
+			// Only the index stats are requested from the queriers.
+			count, h := indexStatsResult(indexStats)
+			rt.setHandler(h)
+
+			ctx := user.InjectOrgID(context.Background(), "1")
+			req, err := http.NewRequest(http.MethodGet, "/loki/api/v1/query_explain?"+tc.params, nil)
+			require.NoError(t, err)
+			req = req.WithContext(ctx)
+			require.NoError(t, user.InjectOrgIDIntoHTTPRequest(ctx, req))
+
+			resp, err := tpw(rt).RoundTrip(req)
+			require.NoError(t, err)
+			require.Equal(t, tc.expCalls, *count)
+
+			b, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			var got struct {
+				Status string           `json:"status"`
+				Data   QueryExplanation `json:"data"`
+			}
+			require.NoError(t, jsonStd.Unmarshal(b, &got))
+			require.Equal(t, "success", got.Status)
+			tc.expect(t, got.Data)
+		})
+	}
+}
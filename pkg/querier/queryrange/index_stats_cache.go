@@ -121,6 +121,7 @@ func NewIndexStatsCacheMiddleware(
 		},
 		parallelismForReq,
 		retentionEnabled,
+		false,
 		metrics,
 	)
 }
@@ -0,0 +1,112 @@
+package queryrange
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/tenant"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/pkg/storage/chunk/cache"
+	"github.com/grafana/loki/pkg/util/validation"
+)
+
+// recentIndexStatsCache caches the index stats of the requests the results cache doesn't cache
+// because they end within max_stats_cache_freshness, for the recent_stats_cache_ttl of their tenants.
+// The requests are keyed by their matchers and their start and end truncated to the TTL, so the
+// frequent stats requests of refreshed dashboards and of the query sharding share their results.
+type recentIndexStatsCache struct {
+	next   queryrangebase.Handler
+	logger log.Logger
+	limits Limits
+	cache  cache.Cache
+}
+
+// NewRecentIndexStatsCacheMiddleware returns a middleware caching the recent index stats for a short TTL.
+func NewRecentIndexStatsCacheMiddleware(logger log.Logger, limits Limits, c cache.Cache) queryrangebase.Middleware {
+	return queryrangebase.MiddlewareFunc(func(next queryrangebase.Handler) queryrangebase.Handler {
+		return recentIndexStatsCache{
+			next:   next,
+			logger: logger,
+			limits: limits,
+			cache:  c,
+		}
+	})
+}
+
+func (c recentIndexStatsCache) Do(ctx context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+	tenantIDs, err := tenant.TenantIDs(ctx)
+	if err != nil {
+		return c.next.Do(ctx, r)
+	}
+
+	ttl := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, func(id string) time.Duration {
+		return c.limits.RecentStatsCacheTTL(ctx, id)
+	})
+	if ttl <= 0 || r.GetCachingOptions().Disabled {
+		return c.next.Do(ctx, r)
+	}
+	if cacheStats, err := shouldCacheStats(ctx, r, c.limits); err != nil || cacheStats {
+		return c.next.Do(ctx, r)
+	}
+
+	bucket := ttl.Milliseconds()
+	key := cache.HashKey(fmt.Sprintf("recentIndexStats:%s:%s:%d:%d", tenant.JoinTenantIDs(tenantIDs), r.GetQuery(), r.GetStart()/bucket, r.GetEnd()/bucket))
+	now := statsCacheMiddlewareNowTimeFunc()
+
+	if _, bufs, _, err := c.cache.Fetch(ctx, []string{key}); err == nil && len(bufs) == 1 {
+		if resp, ok := decodeRecentIndexStats(bufs[0], now); ok {
+			return resp, nil
+		}
+	}
+
+	resp, err := c.next.Do(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	statsResp, ok := resp.(*IndexStatsResponse)
+	if !ok || statsResp.Response == nil {
+		return resp, nil
+	}
+
+	buf, err := encodeRecentIndexStats(statsResp.Response, now.Add(ttl))
+	if err != nil {
+		level.Warn(c.logger).Log("msg", "failed to encode recent index stats", "err", err)
+		return resp, nil
+	}
+	if err := c.cache.Store(ctx, []string{key}, [][]byte{buf}); err != nil {
+		level.Warn(c.logger).Log("msg", "failed to cache recent index stats", "err", err)
+	}
+	return resp, nil
+}
+
+// encodeRecentIndexStats encodes the stats prefixed by their expiry, since the caches
+// don't expire the keys themselves.
+func encodeRecentIndexStats(stats *logproto.IndexStatsResponse, expiry model.Time) ([]byte, error) {
+	b, err := stats.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 8, 8+len(b))
+	binary.BigEndian.PutUint64(buf, uint64(expiry))
+	return append(buf, b...), nil
+}
+
+// decodeRecentIndexStats decodes the stats encoded by encodeRecentIndexStats,
+// and returns false if they are expired or invalid.
+func decodeRecentIndexStats(buf []byte, now model.Time) (*IndexStatsResponse, bool) {
+	if len(buf) < 8 || model.Time(binary.BigEndian.Uint64(buf)) <= now {
+		return nil, false
+	}
+	var stats logproto.IndexStatsResponse
+	if err := stats.Unmarshal(buf[8:]); err != nil {
+		return nil, false
+	}
+	return &IndexStatsResponse{Response: &stats}, true
+}
@@ -0,0 +1,70 @@
+package queryrange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/storage/chunk/cache"
+)
+
+func TestRecentIndexStatsCache(t *testing.T) {
+	now := model.Time(testTime.UnixMilli())
+	statsCacheMiddlewareNowTimeFunc = func() model.Time { return now }
+	defer func() { statsCacheMiddlewareNowTimeFunc = model.Now }()
+
+	statsResp := &IndexStatsResponse{
+		Response: &logproto.IndexStatsResponse{
+			Streams: 1,
+			Chunks:  2,
+			Bytes:   1 << 10,
+			Entries: 10,
+		},
+	}
+	lim := fakeLimits{maxStatsCacheFreshness: 10 * time.Minute, recentStatsCacheTTL: time.Minute}
+	calls, statsHandler := indexStatsResultHandler(statsResp)
+	rc := NewRecentIndexStatsCacheMiddleware(log.NewNopLogger(), lim, cache.NewMockCache()).Wrap(statsHandler)
+	ctx := user.InjectOrgID(context.Background(), "fake")
+
+	recentReq := &logproto.IndexStatsRequest{
+		From:     now.Add(-time.Hour),
+		Through:  now,
+		Matchers: `{foo="bar"}`,
+	}
+	resp, err := rc.Do(ctx, recentReq)
+	require.NoError(t, err)
+	require.Equal(t, 1, *calls)
+	require.Equal(t, statsResp, resp)
+
+	// The same request is served from the cache within the TTL.
+	*calls = 0
+	resp, err = rc.Do(ctx, recentReq)
+	require.NoError(t, err)
+	require.Equal(t, 0, *calls)
+	require.Equal(t, statsResp, resp)
+
+	// The cached stats expire after the TTL.
+	now = now.Add(time.Minute)
+	_, err = rc.Do(ctx, recentReq)
+	require.NoError(t, err)
+	require.Equal(t, 1, *calls)
+
+	// The requests the results cache caches are not cached.
+	*calls = 0
+	oldReq := &logproto.IndexStatsRequest{
+		From:     now.Add(-2 * time.Hour),
+		Through:  now.Add(-time.Hour),
+		Matchers: `{foo="bar"}`,
+	}
+	for i := 0; i < 2; i++ {
+		_, err = rc.Do(ctx, oldReq)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 2, *calls)
+}
@@ -15,6 +15,8 @@ import (
 	"github.com/grafana/dskit/tenant"
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/timestamp"
@@ -24,6 +26,7 @@ import (
 	"github.com/grafana/loki/pkg/logproto"
 	"github.com/grafana/loki/pkg/logql"
 	"github.com/grafana/loki/pkg/logql/syntax"
+	"github.com/grafana/loki/pkg/logqlmodel"
 	"github.com/grafana/loki/pkg/querier/queryrange/queryrangebase"
 	"github.com/grafana/loki/pkg/storage/config"
 	"github.com/grafana/loki/pkg/storage/stores/index/stats"
@@ -46,13 +49,22 @@ const (
 
 var (
 	ErrMaxQueryParalellism = fmt.Errorf("querying is disabled, please contact your Loki operator")
+
+	queriesRejectedBySize = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "loki",
+		Name:      "query_frontend_queries_rejected_by_size_total",
+		Help:      "Count of queries rejected before their execution, because the index stats estimate they would read more bytes than the limit",
+	}, []string{"user", "limit_name"})
 )
 
 // Limits extends the cortex limits interface with support for per tenant splitby parameters
 type Limits interface {
 	queryrangebase.Limits
+	queryrangebase.RetryLimits
 	logql.Limits
 	QuerySplitDuration(string) time.Duration
+	InstantMetricQuerySplitDuration(string) time.Duration
+	MetadataQuerySplitDuration(string) time.Duration
 	MaxQuerySeries(context.Context, string) int
 	MaxEntriesLimitPerQuery(context.Context, string) int
 	MinShardingLookback(string) time.Duration
@@ -64,6 +76,11 @@ type Limits interface {
 	MaxQueryBytesRead(context.Context, string) int
 	MaxQuerierBytesRead(context.Context, string) int
 	MaxStatsCacheFreshness(context.Context, string) time.Duration
+	RecentStatsCacheTTL(context.Context, string) time.Duration
+	MaxMetadataCacheFreshness(context.Context, string) time.Duration
+	MetadataCacheTTL(context.Context, string) time.Duration
+	AlignQueriesWithStep(context.Context, string) bool
+	QuantileSketchSharding(context.Context, string) bool
 }
 
 type limits struct {
@@ -103,6 +120,31 @@ func WithSplitByLimits(l Limits, splitBy time.Duration) Limits {
 	}
 }
 
+// metadataSplitLimits splits the queries by the split_metadata_queries_by_interval of the tenants.
+type metadataSplitLimits struct {
+	Limits
+}
+
+// WithMetadataSplitLimits returns limits splitting the series and label queries by their own interval.
+func WithMetadataSplitLimits(l Limits) Limits {
+	return metadataSplitLimits{l}
+}
+
+func (l metadataSplitLimits) QuerySplitDuration(user string) time.Duration {
+	return l.MetadataQuerySplitDuration(user)
+}
+
+// instantMetricQuerySplitDuration returns the interval the instant metric queries of a tenant are split by,
+// which falls back to the split_queries_by_interval of the tenant when split_instant_metric_queries_by_interval is unset.
+func instantMetricQuerySplitDuration(l Limits) func(string) time.Duration {
+	return func(user string) time.Duration {
+		if split := l.InstantMetricQuerySplitDuration(user); split > 0 {
+			return split
+		}
+		return l.QuerySplitDuration(user)
+	}
+}
+
 func WithMaxParallelism(l Limits, maxParallelism int) Limits {
 	return limits{
 		Limits:              l,
@@ -265,7 +307,7 @@ func NewQuerySizeLimiterMiddleware(
 	})
 }
 
-// getBytesReadForRequest returns the number of bytes that would be read for the query in r.
+// getStatsForRequest returns the index stats of the data that would be read for the query in r.
 // Since the query expression may contain multiple stream matchers, this function sums up the
 // bytes that will be read for each stream.
 // E.g. for the following query:
@@ -276,20 +318,20 @@ func NewQuerySizeLimiterMiddleware(
 // individual intervals and offsets
 //   - {job="foo"}
 //   - {job="bar"}
-func (q *querySizeLimiter) getBytesReadForRequest(ctx context.Context, r queryrangebase.Request) (uint64, error) {
-	sp, ctx := opentracing.StartSpanFromContext(ctx, "querySizeLimiter.getBytesReadForRequest")
+func (q *querySizeLimiter) getStatsForRequest(ctx context.Context, r queryrangebase.Request) (stats.Stats, error) {
+	sp, ctx := opentracing.StartSpanFromContext(ctx, "querySizeLimiter.getStatsForRequest")
 	defer sp.Finish()
 	log := spanlogger.FromContextWithFallback(ctx, q.logger)
 	defer log.Finish()
 
 	expr, err := syntax.ParseExpr(r.GetQuery())
 	if err != nil {
-		return 0, err
+		return stats.Stats{}, err
 	}
 
 	matcherGroups, err := syntax.MatcherGroups(expr)
 	if err != nil {
-		return 0, err
+		return stats.Stats{}, err
 	}
 
 	// TODO: Set concurrency dynamically as in shardResolverForConf?
@@ -297,7 +339,7 @@ func (q *querySizeLimiter) getBytesReadForRequest(ctx context.Context, r queryra
 	const maxConcurrentIndexReq = 10
 	matcherStats, err := getStatsForMatchers(ctx, q.logger, q.statsHandler, model.Time(r.GetStart()), model.Time(r.GetEnd()), matcherGroups, maxConcurrentIndexReq, q.maxLookBackPeriod)
 	if err != nil {
-		return 0, err
+		return stats.Stats{}, err
 	}
 
 	combinedStats := stats.MergeStats(matcherStats...)
@@ -314,7 +356,7 @@ func (q *querySizeLimiter) getBytesReadForRequest(ctx context.Context, r queryra
 		)...,
 	)
 
-	return combinedStats.Bytes, nil
+	return combinedStats, nil
 }
 
 func (q *querySizeLimiter) getSchemaCfg(r queryrangebase.Request) (config.PeriodConfig, error) {
@@ -364,16 +406,18 @@ func (q *querySizeLimiter) Do(ctx context.Context, r queryrangebase.Request) (qu
 
 	limitFuncCapture := func(id string) int { return q.limitFunc(ctx, id) }
 	if maxBytesRead := validation.SmallestPositiveNonZeroIntPerTenant(tenantIDs, limitFuncCapture); maxBytesRead > 0 {
-		bytesRead, err := q.getBytesReadForRequest(ctx, r)
+		queryStats, err := q.getStatsForRequest(ctx, r)
 		if err != nil {
 			return nil, httpgrpc.Errorf(http.StatusInternalServerError, "Failed to get bytes read stats for query: %s", err.Error())
 		}
 
+		bytesRead := queryStats.Bytes
 		statsBytesStr := humanize.IBytes(bytesRead)
 		maxBytesReadStr := humanize.IBytes(uint64(maxBytesRead))
 
 		if bytesRead > uint64(maxBytesRead) {
-			level.Warn(log).Log("msg", "Query exceeds limits", "status", "rejected", "limit_name", q.guessLimitName(), "limit_bytes", maxBytesReadStr, "resolved_bytes", statsBytesStr)
+			level.Warn(log).Log("msg", "Query exceeds limits", "status", "rejected", "limit_name", q.guessLimitName(), "limit_bytes", maxBytesReadStr, "resolved_bytes", statsBytesStr, "resolved_streams", queryStats.Streams, "resolved_chunks", queryStats.Chunks)
+			queriesRejectedBySize.WithLabelValues(tenant.JoinTenantIDs(tenantIDs), q.guessLimitName()).Inc()
 			return nil, httpgrpc.Errorf(http.StatusBadRequest, q.limitErrorTmpl, statsBytesStr, maxBytesReadStr)
 		}
 
@@ -732,6 +776,20 @@ func validateMaxEntriesLimits(req *http.Request, reqLimit uint32, limits Limits)
 	return nil
 }
 
+// validateBlockedQuery rejects the queries blocked by the blocked_queries of the tenants,
+// before they are split and sharded.
+func validateBlockedQuery(req *http.Request, limits Limits, logger log.Logger, query string) error {
+	tenantIDs, err := tenant.TenantIDs(req.Context())
+	if err != nil {
+		return httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+	}
+
+	if logql.IsQueryBlocked(req.Context(), query, tenantIDs, limits, logger) {
+		return httpgrpc.Errorf(http.StatusBadRequest, logqlmodel.ErrBlocked.Error())
+	}
+	return nil
+}
+
 func validateMatchers(req *http.Request, limits Limits, matchers []*labels.Matcher) error {
 	tenants, err := tenant.TenantIDs(req.Context())
 	if err != nil {
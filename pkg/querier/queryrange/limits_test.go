@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/promql"
@@ -48,6 +49,24 @@ func TestLimits(t *testing.T) {
 		fmt.Sprintf("%s:%s:%d:%d:%d", "a", r.GetQuery(), r.GetStep(), r.GetStart()/int64(time.Hour/time.Millisecond), int64(time.Hour)),
 		cacheKeyLimits{wrapped, nil}.GenerateCacheKey(context.Background(), "a", r),
 	)
+
+	// The metadata queries are split by their own per-tenant interval.
+	metadata := WithMetadataSplitLimits(fakeLimits{
+		splits:         map[string]time.Duration{"a": time.Minute},
+		metadataSplits: map[string]time.Duration{"a": 12 * time.Hour},
+	})
+	require.Equal(t, 12*time.Hour, metadata.QuerySplitDuration("a"))
+	require.Equal(t, time.Duration(0), metadata.QuerySplitDuration("b"))
+}
+
+func Test_InstantMetricQuerySplitDuration(t *testing.T) {
+	split := instantMetricQuerySplitDuration(fakeLimits{
+		splits:              map[string]time.Duration{"a": time.Hour, "b": time.Hour},
+		instantMetricSplits: map[string]time.Duration{"a": 5 * time.Minute},
+	})
+	require.Equal(t, 5*time.Minute, split("a"))
+	require.Equal(t, time.Hour, split("b"))
+	require.Equal(t, time.Duration(0), split("c"))
 }
 
 func Test_seriesLimiter(t *testing.T) {
@@ -602,12 +621,20 @@ func Test_MaxQuerySize(t *testing.T) {
 				NewQuerierSizeLimiterMiddleware(schemas, testEngineOpts, util_log.Logger, tc.limits, querierStatsHandler),
 			}
 
+			rejected := func() float64 {
+				return testutil.ToFloat64(queriesRejectedBySize.WithLabelValues("foo", "MaxQueryBytesRead")) +
+					testutil.ToFloat64(queriesRejectedBySize.WithLabelValues("foo", "MaxQuerierBytesRead"))
+			}
+			rejectedBefore := rejected()
+
 			_, err = queryrangebase.NewRoundTripper(fakeRT, LokiCodec, nil, middlewares...).RoundTrip(req)
 
 			if tc.shouldErr {
 				require.Error(t, err)
+				require.Equal(t, rejectedBefore+1, rejected())
 			} else {
 				require.NoError(t, err)
+				require.Equal(t, rejectedBefore, rejected())
 			}
 
 			require.Equal(t, tc.expectedQueryStatsHits, *queryStatsHits)
@@ -0,0 +1,139 @@
+package queryrange
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/grafana/loki/pkg/loghttp"
+	"github.com/grafana/loki/pkg/logql"
+	"github.com/grafana/loki/pkg/logqlmodel"
+	"github.com/grafana/loki/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/pkg/util/marshal"
+)
+
+// ProtobufType is the media type of the query responses encoded as protobuf.
+// The frontend accepts it for range and instant queries, so queriers supporting
+// it don't encode the results to JSON for the frontend to decode them again.
+// Queriers not supporting it keep responding with JSON, which is still decoded.
+const ProtobufType = "application/vnd.google.protobuf"
+
+// acceptQueryResponse is the Accept header of the range and instant queries sent to queriers.
+const acceptQueryResponse = ProtobufType + ", application/json"
+
+// AcceptsProtobuf returns true if the sender of the request accepts query responses encoded as protobuf.
+func AcceptsProtobuf(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), ProtobufType)
+}
+
+// ResultToResponse converts the result of a query to the response of the frontend.
+func ResultToResponse(result logqlmodel.Result, params logql.Params) (*QueryResponse, error) {
+	value, err := marshal.NewResultValue(result.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	prometheus := func(resultType string, samples []queryrangebase.SampleStream) *QueryResponse {
+		return &QueryResponse{
+			Response: &QueryResponse_Prometheus{
+				Prometheus: &LokiPromResponse{
+					Response: &queryrangebase.PrometheusResponse{
+						Status: loghttp.QueryStatusSuccess,
+						Data: queryrangebase.PrometheusData{
+							ResultType: resultType,
+							Result:     samples,
+						},
+					},
+					Statistics: result.Statistics,
+				},
+			},
+		}
+	}
+
+	switch result.Data.Type() {
+	case parser.ValueTypeMatrix:
+		return prometheus(loghttp.ResultTypeMatrix, toProtoMatrix(value.(loghttp.Matrix))), nil
+	case parser.ValueTypeVector:
+		return prometheus(loghttp.ResultTypeVector, toProtoVector(value.(loghttp.Vector))), nil
+	case parser.ValueTypeScalar:
+		return prometheus(loghttp.ResultTypeScalar, toProtoScalar(value.(loghttp.Scalar))), nil
+	case logqlmodel.ValueTypeStreams:
+		return &QueryResponse{
+			Response: &QueryResponse_Streams{
+				Streams: &LokiResponse{
+					Status:     loghttp.QueryStatusSuccess,
+					Direction:  params.Direction(),
+					Limit:      params.Limit(),
+					Version:    uint32(loghttp.VersionV1),
+					Statistics: result.Statistics,
+					Data: LokiData{
+						ResultType: loghttp.ResultTypeStream,
+						Result:     value.(loghttp.Streams).ToProto(),
+					},
+				},
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported result type (%s)", result.Data.Type())
+	}
+}
+
+// WriteQueryResponse writes the result of a query as protobuf if the frontend sending the request accepts it,
+// and as JSON otherwise.
+func WriteQueryResponse(r *http.Request, params logql.Params, result logqlmodel.Result, w http.ResponseWriter) error {
+	if AcceptsProtobuf(r) {
+		return WriteQueryResponseProtobuf(params, result, w)
+	}
+	return marshal.WriteQueryResponseJSON(result, w)
+}
+
+// WriteQueryResponseProtobuf marshals the result of a query to protobuf
+// and then writes it to the provided http.ResponseWriter.
+func WriteQueryResponseProtobuf(params logql.Params, result logqlmodel.Result, w http.ResponseWriter) error {
+	resp, err := ResultToResponse(result, params)
+	if err != nil {
+		return err
+	}
+	buf, err := resp.Marshal()
+	if err != nil {
+		return fmt.Errorf("could not write protobuf response: %w", err)
+	}
+	w.Header().Set("Content-Type", ProtobufType)
+	_, err = w.Write(buf)
+	return err
+}
+
+// decodeQueryResponseProtobuf unmarshals the protobuf response of a query, and adds it the headers of the http response.
+func decodeQueryResponseProtobuf(buf []byte, req queryrangebase.Request, headers []queryrangebase.PrometheusResponseHeader) (queryrangebase.Response, error) {
+	var resp QueryResponse
+	if err := resp.Unmarshal(buf); err != nil {
+		return nil, err
+	}
+
+	switch r := resp.Response.(type) {
+	case *QueryResponse_Streams:
+		// The version of the API is the one requested to the frontend, like for JSON responses.
+		var path string
+		switch req := req.(type) {
+		case *LokiRequest:
+			path = req.GetPath()
+		case *LokiInstantRequest:
+			path = req.GetPath()
+		default:
+			return nil, fmt.Errorf("expected *LokiRequest or *LokiInstantRequest, got (%T)", req)
+		}
+		r.Streams.Version = uint32(loghttp.GetVersion(path))
+		r.Streams.Headers = headers
+		return r.Streams, nil
+	case *QueryResponse_Prometheus:
+		if r.Prometheus.Response == nil {
+			r.Prometheus.Response = &queryrangebase.PrometheusResponse{}
+		}
+		r.Prometheus.Response.Headers = convertPrometheusResponseHeadersToPointers(headers)
+		return r.Prometheus, nil
+	default:
+		return nil, fmt.Errorf("unsupported response type, got (%T)", resp.Response)
+	}
+}
@@ -0,0 +1,126 @@
+package queryrange
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/loghttp"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/logqlmodel"
+	"github.com/grafana/loki/pkg/querier/queryrange/queryrangebase"
+)
+
+func TestQueryResponseProtobuf(t *testing.T) {
+	headers := []queryrangebase.PrometheusResponseHeader{{Name: "Content-Type", Values: []string{ProtobufType}}}
+	for _, tc := range []struct {
+		name   string
+		req    queryrangebase.Request
+		result logqlmodel.Result
+		want   queryrangebase.Response
+	}{
+		{
+			name:   "streams",
+			req:    &LokiRequest{Direction: logproto.FORWARD, Limit: 100, Path: "/loki/api/v1/query_range"},
+			result: logqlmodel.Result{Data: logqlmodel.Streams(logStreams), Statistics: statsResult},
+			want: &LokiResponse{
+				Status:    loghttp.QueryStatusSuccess,
+				Direction: logproto.FORWARD,
+				Limit:     100,
+				Version:   uint32(loghttp.VersionV1),
+				Data: LokiData{
+					ResultType: loghttp.ResultTypeStream,
+					Result:     logStreams,
+				},
+				Statistics: statsResult,
+				Headers:    headers,
+			},
+		},
+		{
+			name:   "streams legacy",
+			req:    &LokiRequest{Direction: logproto.FORWARD, Limit: 100, Path: "/api/prom/query"},
+			result: logqlmodel.Result{Data: logqlmodel.Streams(logStreams), Statistics: statsResult},
+			want: &LokiResponse{
+				Status:    loghttp.QueryStatusSuccess,
+				Direction: logproto.FORWARD,
+				Limit:     100,
+				Version:   uint32(loghttp.VersionLegacy),
+				Data: LokiData{
+					ResultType: loghttp.ResultTypeStream,
+					Result:     logStreams,
+				},
+				Statistics: statsResult,
+				Headers:    headers,
+			},
+		},
+		{
+			name: "matrix",
+			req:  &LokiRequest{Path: "/loki/api/v1/query_range"},
+			result: logqlmodel.Result{
+				Data: promql.Matrix{
+					{Metric: labels.FromStrings("filename", "/var/hostlog/apport.log"), Floats: []promql.FPoint{{T: 1000, F: 2}, {T: 2000, F: 3}}},
+				},
+				Statistics: statsResult,
+			},
+			want: &LokiPromResponse{
+				Response: &queryrangebase.PrometheusResponse{
+					Status: loghttp.QueryStatusSuccess,
+					Data: queryrangebase.PrometheusData{
+						ResultType: loghttp.ResultTypeMatrix,
+						Result: []queryrangebase.SampleStream{
+							{
+								Labels:  []logproto.LabelAdapter{{Name: "filename", Value: "/var/hostlog/apport.log"}},
+								Samples: []logproto.LegacySample{{TimestampMs: 1000, Value: 2}, {TimestampMs: 2000, Value: 3}},
+							},
+						},
+					},
+					Headers: []*queryrangebase.PrometheusResponseHeader{&headers[0]},
+				},
+				Statistics: statsResult,
+			},
+		},
+		{
+			name: "scalar",
+			req:  &LokiInstantRequest{Path: "/loki/api/v1/query"},
+			result: logqlmodel.Result{
+				Data:       promql.Scalar{T: 1000, V: 2},
+				Statistics: statsResult,
+			},
+			want: &LokiPromResponse{
+				Response: &queryrangebase.PrometheusResponse{
+					Status: loghttp.QueryStatusSuccess,
+					Data: queryrangebase.PrometheusData{
+						ResultType: loghttp.ResultTypeScalar,
+						Result: []queryrangebase.SampleStream{
+							{Samples: []logproto.LegacySample{{TimestampMs: 1000, Value: 2}}},
+						},
+					},
+					Headers: []*queryrangebase.PrometheusResponseHeader{&headers[0]},
+				},
+				Statistics: statsResult,
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// The frontend asks queriers for protobuf.
+			httpReq, err := LokiCodec.EncodeRequest(context.Background(), tc.req)
+			require.NoError(t, err)
+			require.True(t, AcceptsProtobuf(httpReq))
+
+			params, err := paramsFromRequest(tc.req)
+			require.NoError(t, err)
+			rec := httptest.NewRecorder()
+			require.NoError(t, WriteQueryResponseProtobuf(params, tc.result, rec))
+
+			resp := rec.Result()
+			require.Equal(t, ProtobufType, resp.Header.Get("Content-Type"))
+			got, err := LokiCodec.DecodeResponse(context.Background(), resp, tc.req)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package queryrange
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/tenant"
+
+	"github.com/grafana/loki/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/pkg/storage/chunk/cache"
+	"github.com/grafana/loki/pkg/util/validation"
+)
+
+type SeriesSplitter struct {
+	cacheKeyLimits
+}
+
+// GenerateCacheKey generates a cache key based on the userID, matchers and interval of the series request.
+func (s SeriesSplitter) GenerateCacheKey(ctx context.Context, userID string, r queryrangebase.Request) string {
+	matchers := append([]string(nil), r.(*LokiSeriesRequest).GetMatch()...)
+	sort.Strings(matchers)
+	cacheKey := s.cacheKeyLimits.GenerateCacheKey(ctx, userID, r)
+	return withMetadataCacheTTL(ctx, s.Limits, fmt.Sprintf("series:%s:%s", strings.Join(matchers, ","), cacheKey))
+}
+
+type LabelsSplitter struct {
+	cacheKeyLimits
+}
+
+// GenerateCacheKey generates a cache key based on the userID, path, query and interval of the labels request.
+// The path tells the label names requests from the label values ones, and the label name of the latter.
+func (s LabelsSplitter) GenerateCacheKey(ctx context.Context, userID string, r queryrangebase.Request) string {
+	cacheKey := s.cacheKeyLimits.GenerateCacheKey(ctx, userID, r)
+	return withMetadataCacheTTL(ctx, s.Limits, fmt.Sprintf("labels:%s:%s", r.(*LokiLabelNamesRequest).GetPath(), cacheKey))
+}
+
+// withMetadataCacheTTL appends the current interval of the smallest metadata cache TTL of the tenants to the cache key,
+// so the cached results are not used anymore once the interval elapsed.
+func withMetadataCacheTTL(ctx context.Context, lim Limits, cacheKey string) string {
+	tenantIDs, err := tenant.TenantIDs(ctx)
+	if err != nil {
+		return cacheKey
+	}
+	ttl := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, func(id string) time.Duration { return lim.MetadataCacheTTL(ctx, id) })
+	if ttl <= 0 {
+		return cacheKey
+	}
+	return fmt.Sprintf("%s:%d:%d", cacheKey, metadataCacheMiddlewareNowTimeFunc().UnixNano()/int64(ttl), ttl)
+}
+
+// MetadataExtractor returns cached series and labels responses as a whole, as
+// their series and labels cannot be attributed to a part of their time range.
+// The results cache is then configured to only use the cached extents within the time range of a request.
+type MetadataExtractor struct{}
+
+func (MetadataExtractor) Extract(_, _ int64, res queryrangebase.Response, _, _ int64) queryrangebase.Response {
+	return res
+}
+
+func (MetadataExtractor) ResponseWithoutHeaders(resp queryrangebase.Response) queryrangebase.Response {
+	switch r := resp.(type) {
+	case *LokiSeriesResponse:
+		return &LokiSeriesResponse{
+			Status:  r.Status,
+			Version: r.Version,
+			Data:    r.Data,
+		}
+	case *LokiLabelNamesResponse:
+		return &LokiLabelNamesResponse{
+			Status:  r.Status,
+			Version: r.Version,
+			Data:    r.Data,
+		}
+	}
+	return resp
+}
+
+type SeriesCacheConfig struct {
+	queryrangebase.ResultsCacheConfig `yaml:",inline"`
+}
+
+// RegisterFlags registers flags.
+func (cfg *SeriesCacheConfig) RegisterFlags(f *flag.FlagSet) {
+	cfg.ResultsCacheConfig.RegisterFlagsWithPrefix(f, "frontend.series-results-cache.")
+}
+
+func (cfg *SeriesCacheConfig) Validate() error {
+	return cfg.ResultsCacheConfig.Validate()
+}
+
+type LabelsCacheConfig struct {
+	queryrangebase.ResultsCacheConfig `yaml:",inline"`
+}
+
+// RegisterFlags registers flags.
+func (cfg *LabelsCacheConfig) RegisterFlags(f *flag.FlagSet) {
+	cfg.ResultsCacheConfig.RegisterFlagsWithPrefix(f, "frontend.label-results-cache.")
+}
+
+func (cfg *LabelsCacheConfig) Validate() error {
+	return cfg.ResultsCacheConfig.Validate()
+}
+
+// metadataCacheMiddlewareNowTimeFunc is a function that returns the current time.
+// It is used to allow tests to override the current time.
+var metadataCacheMiddlewareNowTimeFunc = time.Now
+
+// shouldCacheMetadata returns false if the request end time falls within the
+// max_metadata_cache_freshness duration, as the series and labels of recent data still change.
+func shouldCacheMetadata(ctx context.Context, req queryrangebase.Request, lim Limits) (bool, error) {
+	tenantIDs, err := tenant.TenantIDs(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	cacheFreshnessCapture := func(id string) time.Duration { return lim.MaxMetadataCacheFreshness(ctx, id) }
+	maxCacheFreshness := validation.MaxDurationPerTenant(tenantIDs, cacheFreshnessCapture)
+
+	now := metadataCacheMiddlewareNowTimeFunc()
+	return maxCacheFreshness == 0 || req.GetEnd() < now.Add(-maxCacheFreshness).UnixMilli(), nil
+}
+
+// NewSeriesCacheMiddleware creates a middleware caching the responses of series requests.
+func NewSeriesCacheMiddleware(
+	log log.Logger,
+	limits Limits,
+	merger queryrangebase.Merger,
+	c cache.Cache,
+	cacheGenNumberLoader queryrangebase.CacheGenNumberLoader,
+	shouldCache queryrangebase.ShouldCacheFn,
+	parallelismForReq func(ctx context.Context, tenantIDs []string, r queryrangebase.Request) int,
+	retentionEnabled bool,
+	transformer UserIDTransformer,
+	metrics *queryrangebase.ResultsCacheMetrics,
+) (queryrangebase.Middleware, error) {
+	return newMetadataCacheMiddleware(log, limits, SeriesSplitter{cacheKeyLimits{limits, transformer}}, merger, c,
+		cacheGenNumberLoader, shouldCache, parallelismForReq, retentionEnabled, metrics)
+}
+
+// NewLabelsCacheMiddleware creates a middleware caching the responses of label names and label values requests.
+func NewLabelsCacheMiddleware(
+	log log.Logger,
+	limits Limits,
+	merger queryrangebase.Merger,
+	c cache.Cache,
+	cacheGenNumberLoader queryrangebase.CacheGenNumberLoader,
+	shouldCache queryrangebase.ShouldCacheFn,
+	parallelismForReq func(ctx context.Context, tenantIDs []string, r queryrangebase.Request) int,
+	retentionEnabled bool,
+	transformer UserIDTransformer,
+	metrics *queryrangebase.ResultsCacheMetrics,
+) (queryrangebase.Middleware, error) {
+	return newMetadataCacheMiddleware(log, limits, LabelsSplitter{cacheKeyLimits{limits, transformer}}, merger, c,
+		cacheGenNumberLoader, shouldCache, parallelismForReq, retentionEnabled, metrics)
+}
+
+func newMetadataCacheMiddleware(
+	log log.Logger,
+	limits Limits,
+	splitter queryrangebase.CacheSplitter,
+	merger queryrangebase.Merger,
+	c cache.Cache,
+	cacheGenNumberLoader queryrangebase.CacheGenNumberLoader,
+	shouldCache queryrangebase.ShouldCacheFn,
+	parallelismForReq func(ctx context.Context, tenantIDs []string, r queryrangebase.Request) int,
+	retentionEnabled bool,
+	metrics *queryrangebase.ResultsCacheMetrics,
+) (queryrangebase.Middleware, error) {
+	return queryrangebase.NewResultsCacheMiddleware(
+		log,
+		c,
+		splitter,
+		limits,
+		merger,
+		MetadataExtractor{},
+		cacheGenNumberLoader,
+		func(ctx context.Context, r queryrangebase.Request) bool {
+			if shouldCache != nil && !shouldCache(ctx, r) {
+				return false
+			}
+
+			cacheMetadata, err := shouldCacheMetadata(ctx, r, limits)
+			if err != nil {
+				level.Error(log).Log("msg", "failed to determine if metadata should be cached. Won't cache", "err", err)
+				return false
+			}
+
+			return cacheMetadata
+		},
+		parallelismForReq,
+		retentionEnabled,
+		true,
+		metrics,
+	)
+}
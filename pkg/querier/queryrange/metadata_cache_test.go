@@ -0,0 +1,226 @@
+package queryrange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/loki/pkg/loghttp"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/pkg/storage/chunk/cache"
+)
+
+func metadataResultHandler(resp queryrangebase.Response) (*int, queryrangebase.Handler) {
+	calls := 0
+	return &calls, queryrangebase.HandlerFunc(func(_ context.Context, _ queryrangebase.Request) (queryrangebase.Response, error) {
+		calls++
+		return resp, nil
+	})
+}
+
+func newTestMetadataCacheMiddleware(t *testing.T, lim Limits, labels bool) queryrangebase.Middleware {
+	newMiddleware := NewSeriesCacheMiddleware
+	if labels {
+		newMiddleware = NewLabelsCacheMiddleware
+	}
+	cacheMiddleware, err := newMiddleware(
+		log.NewNopLogger(),
+		WithSplitByLimits(lim, 24*time.Hour),
+		LokiCodec,
+		cache.NewMockCache(),
+		nil,
+		nil,
+		func(_ context.Context, _ []string, _ queryrangebase.Request) int {
+			return 1
+		},
+		false,
+		nil,
+		nil,
+	)
+	require.NoError(t, err)
+	return cacheMiddleware
+}
+
+func TestSeriesCache(t *testing.T) {
+	seriesResp := &LokiSeriesResponse{
+		Status:  "success",
+		Version: uint32(loghttp.VersionV1),
+		Data: []logproto.SeriesIdentifier{
+			{Labels: map[string]string{"cluster": "eu-west", "namespace": "loki"}},
+		},
+	}
+	calls, handler := metadataResultHandler(seriesResp)
+	rc := newTestMetadataCacheMiddleware(t, fakeLimits{}, false).Wrap(handler)
+	ctx := user.InjectOrgID(context.Background(), "fake")
+
+	day := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	req := &LokiSeriesRequest{
+		Match:   []string{`{namespace="loki"}`, `{cluster="eu-west"}`},
+		Path:    "/loki/api/v1/series",
+		StartTs: day,
+		EndTs:   day.Add(24*time.Hour - time.Millisecond),
+	}
+
+	resp, err := rc.Do(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, 1, *calls)
+	require.Equal(t, seriesResp.Data, resp.(*LokiSeriesResponse).Data)
+
+	// Doing the same request again, with the matchers in another order, is served from the cache.
+	*calls = 0
+	sameReq := *req
+	sameReq.Match = []string{`{cluster="eu-west"}`, `{namespace="loki"}`}
+	resp, err = rc.Do(ctx, &sameReq)
+	require.NoError(t, err)
+	require.Equal(t, 0, *calls)
+	require.Equal(t, seriesResp.Data, resp.(*LokiSeriesResponse).Data)
+
+	// A shorter request of the same interval cannot use the cached response,
+	// as the series cannot be attributed to a part of its time range.
+	*calls = 0
+	resp, err = rc.Do(ctx, req.WithStartEnd(day.Add(time.Hour).UnixMilli(), req.GetEnd()))
+	require.NoError(t, err)
+	require.Equal(t, 1, *calls)
+	require.Equal(t, seriesResp.Data, resp.(*LokiSeriesResponse).Data)
+
+	// Other matchers do not hit the cached response.
+	*calls = 0
+	otherReq := *req
+	otherReq.Match = []string{`{namespace="mimir"}`}
+	_, err = rc.Do(ctx, &otherReq)
+	require.NoError(t, err)
+	require.Equal(t, 1, *calls)
+}
+
+func TestLabelsCache(t *testing.T) {
+	labelsResp := &LokiLabelNamesResponse{
+		Status:  "success",
+		Version: uint32(loghttp.VersionV1),
+		Data:    []string{"dev", "prod"},
+	}
+	calls, handler := metadataResultHandler(labelsResp)
+	rc := newTestMetadataCacheMiddleware(t, fakeLimits{}, true).Wrap(handler)
+	ctx := user.InjectOrgID(context.Background(), "fake")
+
+	day := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	req := &LokiLabelNamesRequest{
+		Path:    "/loki/api/v1/label/env/values",
+		StartTs: day,
+		EndTs:   day.Add(24*time.Hour - time.Millisecond),
+	}
+
+	resp, err := rc.Do(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, 1, *calls)
+	require.Equal(t, labelsResp.Data, resp.(*LokiLabelNamesResponse).Data)
+
+	*calls = 0
+	resp, err = rc.Do(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, 0, *calls)
+	require.Equal(t, labelsResp.Data, resp.(*LokiLabelNamesResponse).Data)
+
+	// The values of other labels and the values matching a query are cached separately.
+	for _, other := range []*LokiLabelNamesRequest{
+		{Path: "/loki/api/v1/label/cluster/values", StartTs: req.StartTs, EndTs: req.EndTs},
+		{Path: req.Path, StartTs: req.StartTs, EndTs: req.EndTs, Query: `{namespace="loki"}`},
+	} {
+		*calls = 0
+		_, err = rc.Do(ctx, other)
+		require.NoError(t, err)
+		require.Equal(t, 1, *calls)
+	}
+}
+
+func TestMetadataCache_RecentData(t *testing.T) {
+	now := time.Date(2023, 6, 2, 12, 0, 0, 0, time.UTC)
+	metadataCacheMiddlewareNowTimeFunc = func() time.Time { return now }
+	defer func() { metadataCacheMiddlewareNowTimeFunc = time.Now }()
+
+	labelsResp := &LokiLabelNamesResponse{Status: "success", Data: []string{"env"}}
+
+	for _, tc := range []struct {
+		name                      string
+		maxMetadataCacheFreshness time.Duration
+		end                       time.Time
+
+		expectedCallsAfterCache int
+	}{
+		{
+			name:                    "MaxMetadataCacheFreshness disabled",
+			end:                     now.Add(-5 * time.Minute), // So we don't hit the max_cache_freshness_per_query limit (1m)
+			expectedCallsAfterCache: 0,
+		},
+		{
+			name:                      "MaxMetadataCacheFreshness enabled",
+			maxMetadataCacheFreshness: time.Hour,
+			end:                       now.Add(-5 * time.Minute),
+			expectedCallsAfterCache:   1, // The whole request is done since it wasn't cached.
+		},
+		{
+			name:                      "MaxMetadataCacheFreshness enabled, but request before the max freshness",
+			maxMetadataCacheFreshness: time.Hour,
+			end:                       now.Add(-2 * time.Hour),
+			expectedCallsAfterCache:   0,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			calls, handler := metadataResultHandler(labelsResp)
+			rc := newTestMetadataCacheMiddleware(t, fakeLimits{maxMetadataCacheFreshness: tc.maxMetadataCacheFreshness}, true).Wrap(handler)
+			ctx := user.InjectOrgID(context.Background(), "fake")
+
+			req := &LokiLabelNamesRequest{
+				Path:    "/loki/api/v1/labels",
+				StartTs: tc.end.Add(-6 * time.Hour),
+				EndTs:   tc.end,
+			}
+			_, err := rc.Do(ctx, req)
+			require.NoError(t, err)
+			require.Equal(t, 1, *calls)
+
+			*calls = 0
+			_, err = rc.Do(ctx, req)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedCallsAfterCache, *calls)
+		})
+	}
+}
+
+func TestMetadataCache_TTL(t *testing.T) {
+	now := time.Date(2023, 6, 2, 12, 0, 0, 0, time.UTC)
+	metadataCacheMiddlewareNowTimeFunc = func() time.Time { return now }
+	defer func() { metadataCacheMiddlewareNowTimeFunc = time.Now }()
+
+	labelsResp := &LokiLabelNamesResponse{Status: "success", Data: []string{"env"}}
+	calls, handler := metadataResultHandler(labelsResp)
+	rc := newTestMetadataCacheMiddleware(t, fakeLimits{metadataCacheTTL: time.Hour}, true).Wrap(handler)
+	ctx := user.InjectOrgID(context.Background(), "fake")
+
+	day := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	req := &LokiLabelNamesRequest{
+		Path:    "/loki/api/v1/labels",
+		StartTs: day,
+		EndTs:   day.Add(24*time.Hour - time.Millisecond),
+	}
+	_, err := rc.Do(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, 1, *calls)
+
+	// The cached response is served within the TTL.
+	*calls = 0
+	now = now.Add(30 * time.Minute)
+	_, err = rc.Do(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, 0, *calls)
+
+	// Once the TTL elapsed, the labels are queried again.
+	now = now.Add(time.Hour)
+	_, err = rc.Do(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, 1, *calls)
+}
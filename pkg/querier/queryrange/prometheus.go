@@ -71,6 +71,11 @@ func (p *LokiPromResponse) encode(ctx context.Context) (*http.Response, error) {
 		Body:       io.NopCloser(bytes.NewBuffer(b)),
 		StatusCode: http.StatusOK,
 	}
+	for _, h := range p.Response.Headers {
+		if h.Name == StepAlignedHeader {
+			resp.Header[StepAlignedHeader] = h.Values
+		}
+	}
 	return &resp, nil
 }
 
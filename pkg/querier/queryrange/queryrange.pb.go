@@ -834,6 +834,93 @@ func (m *VolumeResponse) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_VolumeResponse proto.InternalMessageInfo
 
+// QueryResponse is the protobuf encoding of the response of a range or
+// instant query, sent by queriers to the frontend instead of JSON.
+type QueryResponse struct {
+	// Types that are valid to be assigned to Response:
+	//	*QueryResponse_Streams
+	//	*QueryResponse_Prometheus
+	Response isQueryResponse_Response `protobuf_oneof:"response"`
+}
+
+func (m *QueryResponse) Reset()      { *m = QueryResponse{} }
+func (*QueryResponse) ProtoMessage() {}
+func (*QueryResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_51b9d53b40d11902, []int{12}
+}
+func (m *QueryResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *QueryResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_QueryResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *QueryResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueryResponse.Merge(m, src)
+}
+func (m *QueryResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *QueryResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueryResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueryResponse proto.InternalMessageInfo
+
+type isQueryResponse_Response interface {
+	isQueryResponse_Response()
+	Equal(interface{}) bool
+	MarshalTo([]byte) (int, error)
+	Size() int
+}
+
+type QueryResponse_Streams struct {
+	Streams *LokiResponse `protobuf:"bytes,1,opt,name=streams,proto3,oneof" json:"streams,omitempty"`
+}
+type QueryResponse_Prometheus struct {
+	Prometheus *LokiPromResponse `protobuf:"bytes,2,opt,name=prometheus,proto3,oneof" json:"prometheus,omitempty"`
+}
+
+func (*QueryResponse_Streams) isQueryResponse_Response()    {}
+func (*QueryResponse_Prometheus) isQueryResponse_Response() {}
+
+func (m *QueryResponse) GetResponse() isQueryResponse_Response {
+	if m != nil {
+		return m.Response
+	}
+	return nil
+}
+
+func (m *QueryResponse) GetStreams() *LokiResponse {
+	if x, ok := m.GetResponse().(*QueryResponse_Streams); ok {
+		return x.Streams
+	}
+	return nil
+}
+
+func (m *QueryResponse) GetPrometheus() *LokiPromResponse {
+	if x, ok := m.GetResponse().(*QueryResponse_Prometheus); ok {
+		return x.Prometheus
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*QueryResponse) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*QueryResponse_Streams)(nil),
+		(*QueryResponse_Prometheus)(nil),
+	}
+}
+
 func init() {
 	proto.RegisterType((*LokiRequest)(nil), "queryrange.LokiRequest")
 	proto.RegisterType((*LokiInstantRequest)(nil), "queryrange.LokiInstantRequest")
@@ -847,6 +934,7 @@ func init() {
 	proto.RegisterType((*IndexStatsResponse)(nil), "queryrange.IndexStatsResponse")
 	proto.RegisterType((*VolumeRequest)(nil), "queryrange.VolumeRequest")
 	proto.RegisterType((*VolumeResponse)(nil), "queryrange.VolumeResponse")
+	proto.RegisterType((*QueryResponse)(nil), "queryrange.QueryResponse")
 }
 
 func init() {
@@ -854,73 +942,75 @@ func init() {
 }
 
 var fileDescriptor_51b9d53b40d11902 = []byte{
-	// 1042 bytes of a gzipped FileDescriptorProto
+	// 1087 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xec, 0x56, 0x4d, 0x6f, 0x23, 0x35,
-	0x18, 0x8e, 0xf3, 0xd5, 0xc6, 0x65, 0x0b, 0xb8, 0x65, 0x77, 0x54, 0xd0, 0x4c, 0x14, 0x09, 0x36,
-	0x48, 0x30, 0x11, 0x2d, 0xb0, 0xe2, 0x43, 0x88, 0x1d, 0x0a, 0xa2, 0xd2, 0x0a, 0xa1, 0xd9, 0x88,
-	0xbb, 0xd3, 0x71, 0x93, 0xa1, 0xf3, 0x55, 0xdb, 0xb3, 0xa2, 0x37, 0x7e, 0x00, 0x48, 0xfb, 0x17,
-	0xb8, 0x20, 0x24, 0x10, 0x3f, 0x00, 0x89, 0x7b, 0x8f, 0x3d, 0xae, 0x2a, 0x31, 0xd0, 0xf4, 0x02,
-	0x39, 0xf5, 0x27, 0x20, 0xdb, 0x33, 0x89, 0xd3, 0xaf, 0x4d, 0xba, 0x97, 0x22, 0x71, 0xc9, 0xd8,
-	0xaf, 0xdf, 0xc7, 0x7e, 0xdf, 0xe7, 0x7d, 0x5e, 0x3b, 0xf0, 0x6e, 0xb2, 0xdb, 0xef, 0xec, 0xa5,
-	0x84, 0xfa, 0x84, 0xca, 0xef, 0x3e, 0xc5, 0x51, 0x9f, 0x68, 0x43, 0x3b, 0xa1, 0x31, 0x8f, 0x11,
-	0x9c, 0x58, 0xd6, 0x56, 0xfb, 0x71, 0x3f, 0x96, 0xe6, 0x8e, 0x18, 0x29, 0x8f, 0x35, 0xab, 0x1f,
-	0xc7, 0xfd, 0x80, 0x74, 0xe4, 0xac, 0x97, 0xee, 0x74, 0xb8, 0x1f, 0x12, 0xc6, 0x71, 0x98, 0xe4,
-	0x0e, 0x2f, 0x8b, 0xb3, 0x82, 0xb8, 0xaf, 0x90, 0xc5, 0x20, 0x5f, 0x6c, 0xe6, 0x8b, 0x7b, 0x41,
-	0x18, 0x7b, 0x24, 0xe8, 0x30, 0x8e, 0x39, 0x53, 0xbf, 0xb9, 0xc7, 0x8a, 0xf0, 0x48, 0x52, 0x36,
-	0x90, 0x3f, 0xb9, 0xf1, 0x93, 0xa7, 0xc6, 0xdf, 0xc3, 0x8c, 0x74, 0x3c, 0xb2, 0xe3, 0x47, 0x3e,
-	0xf7, 0xe3, 0x88, 0xe9, 0xe3, 0x7c, 0x93, 0x77, 0x67, 0xdb, 0xe4, 0x2c, 0x27, 0xad, 0xc3, 0x32,
-	0x5c, 0x7a, 0x10, 0xef, 0xfa, 0x2e, 0xd9, 0x4b, 0x09, 0xe3, 0x68, 0x15, 0xd6, 0xa4, 0x8f, 0x01,
-	0x9a, 0xa0, 0xdd, 0x70, 0xd5, 0x44, 0x58, 0x03, 0x3f, 0xf4, 0xb9, 0x51, 0x6e, 0x82, 0xf6, 0x2d,
-	0x57, 0x4d, 0x10, 0x82, 0x55, 0xc6, 0x49, 0x62, 0x54, 0x9a, 0xa0, 0x5d, 0x71, 0xe5, 0x18, 0xad,
-	0xc1, 0x45, 0x3f, 0xe2, 0x84, 0x3e, 0xc2, 0x81, 0xd1, 0x90, 0xf6, 0xf1, 0x1c, 0x7d, 0x04, 0x17,
-	0x18, 0xc7, 0x94, 0x77, 0x99, 0x51, 0x6d, 0x82, 0xf6, 0xd2, 0xfa, 0x9a, 0xad, 0xf8, 0xb6, 0x0b,
-	0xbe, 0xed, 0x6e, 0xc1, 0xb7, 0xb3, 0x78, 0x90, 0x59, 0xa5, 0xc7, 0x7f, 0x5a, 0xc0, 0x2d, 0x40,
-	0xe8, 0x7d, 0x58, 0x23, 0x91, 0xd7, 0x65, 0x46, 0x6d, 0x0e, 0xb4, 0x82, 0xa0, 0xb7, 0x60, 0xc3,
-	0xf3, 0x29, 0xd9, 0x16, 0x9c, 0x19, 0xf5, 0x26, 0x68, 0x2f, 0xaf, 0xaf, 0xd8, 0xe3, 0xfa, 0x6d,
-	0x16, 0x4b, 0xee, 0xc4, 0x4b, 0xa4, 0x97, 0x60, 0x3e, 0x30, 0x16, 0x24, 0x13, 0x72, 0x8c, 0x5a,
-	0xb0, 0xce, 0x06, 0x98, 0x7a, 0xcc, 0x58, 0x6c, 0x56, 0xda, 0x0d, 0x07, 0x8e, 0x32, 0x2b, 0xb7,
-	0xb8, 0xf9, 0xb7, 0xf5, 0x0f, 0x80, 0x48, 0x50, 0xba, 0x15, 0x31, 0x8e, 0x23, 0x7e, 0x1d, 0x66,
-	0x3f, 0x84, 0x75, 0xa1, 0xbc, 0x2e, 0x93, 0xdc, 0xce, 0x9a, 0x6a, 0x8e, 0x99, 0xce, 0xb5, 0x3a,
-	0x57, 0xae, 0xb5, 0x0b, 0x73, 0xad, 0x5f, 0x9a, 0xeb, 0xcf, 0x55, 0xf8, 0x9c, 0x92, 0x0f, 0x4b,
-	0xe2, 0x88, 0x11, 0x01, 0x7a, 0xc8, 0x31, 0x4f, 0x99, 0x4a, 0x33, 0x07, 0x49, 0x8b, 0x9b, 0xaf,
-	0xa0, 0x8f, 0x61, 0x75, 0x13, 0x73, 0x2c, 0x53, 0x5e, 0x5a, 0x5f, 0xb5, 0x35, 0x51, 0x8a, 0xbd,
-	0xc4, 0x9a, 0x73, 0x5b, 0x64, 0x35, 0xca, 0xac, 0x65, 0x0f, 0x73, 0xfc, 0x46, 0x1c, 0xfa, 0x9c,
-	0x84, 0x09, 0xdf, 0x77, 0x25, 0x12, 0xbd, 0x03, 0x1b, 0x9f, 0x52, 0x1a, 0xd3, 0xee, 0x7e, 0x42,
-	0x24, 0x45, 0x0d, 0xe7, 0xce, 0x28, 0xb3, 0x56, 0x48, 0x61, 0xd4, 0x10, 0x13, 0x4f, 0xf4, 0x3a,
-	0xac, 0xc9, 0x89, 0x24, 0xa5, 0xe1, 0xac, 0x8c, 0x32, 0xeb, 0x79, 0x09, 0xd1, 0xdc, 0x95, 0xc7,
-	0x34, 0x87, 0xb5, 0x99, 0x38, 0x1c, 0x97, 0xb2, 0xae, 0x97, 0xd2, 0x80, 0x0b, 0x8f, 0x08, 0x65,
-	0x62, 0x9b, 0x05, 0x69, 0x2f, 0xa6, 0xe8, 0x3e, 0x84, 0x82, 0x18, 0x9f, 0x71, 0x7f, 0x5b, 0xe8,
-	0x49, 0x90, 0x71, 0xcb, 0x56, 0xd7, 0x85, 0x4b, 0x58, 0x1a, 0x70, 0x07, 0xe5, 0x2c, 0x68, 0x8e,
-	0xae, 0x36, 0x46, 0xbf, 0x00, 0xb8, 0xf0, 0x39, 0xc1, 0x1e, 0xa1, 0xcc, 0x68, 0x34, 0x2b, 0xed,
-	0xa5, 0xf5, 0x57, 0x6d, 0xfd, 0x6e, 0xf8, 0x92, 0xc6, 0x21, 0xe1, 0x03, 0x92, 0xb2, 0xa2, 0x40,
-	0xca, 0xdb, 0xd9, 0x3d, 0xca, 0xac, 0x5e, 0xdf, 0xe7, 0x83, 0xb4, 0x67, 0x6f, 0xc7, 0x61, 0xa7,
-	0x4f, 0xf1, 0x0e, 0x8e, 0x70, 0x27, 0x88, 0x77, 0xfd, 0xce, 0xdc, 0xf7, 0xd1, 0xa5, 0xe7, 0x8c,
-	0x32, 0x0b, 0xbc, 0xe9, 0x16, 0x21, 0xb6, 0xfe, 0x00, 0xf0, 0x45, 0x51, 0xe1, 0x87, 0x62, 0x6f,
-	0xa6, 0x35, 0x46, 0x88, 0xf9, 0xf6, 0xc0, 0x00, 0x42, 0x66, 0xae, 0x9a, 0xe8, 0x97, 0x45, 0xf9,
-	0x99, 0x2e, 0x8b, 0xca, 0xfc, 0x97, 0x45, 0xd1, 0x0d, 0xd5, 0x0b, 0xbb, 0xa1, 0x76, 0x69, 0x37,
-	0x7c, 0x57, 0x51, 0x9d, 0x5f, 0xe4, 0x37, 0x47, 0x4f, 0x7c, 0x36, 0xee, 0x89, 0x8a, 0x8c, 0x76,
-	0x2c, 0x35, 0xb5, 0xd7, 0x96, 0x47, 0x22, 0xee, 0xef, 0xf8, 0x84, 0x3e, 0xa5, 0x33, 0x34, 0xb9,
-	0x55, 0xa6, 0xe5, 0xa6, 0x6b, 0xa5, 0x7a, 0xe3, 0xb5, 0x72, 0xa6, 0x3b, 0x6a, 0xd7, 0xe8, 0x8e,
-	0xd6, 0xef, 0x00, 0xbe, 0x24, 0xca, 0xf1, 0x00, 0xf7, 0x48, 0xf0, 0x05, 0x0e, 0x27, 0x92, 0xd3,
-	0xc4, 0x05, 0x9e, 0x49, 0x5c, 0xe5, 0xeb, 0x8b, 0xab, 0xa2, 0x89, 0x6b, 0xfc, 0x36, 0x54, 0xb5,
-	0xb7, 0xa1, 0x75, 0x5a, 0x86, 0xb7, 0xcf, 0xc6, 0x3f, 0x87, 0xa4, 0x5e, 0xd3, 0x24, 0xd5, 0x70,
-	0xd0, 0xff, 0x92, 0x99, 0x41, 0x32, 0x3f, 0x02, 0xb8, 0x58, 0xbc, 0x41, 0xc8, 0x86, 0x50, 0xc1,
-	0xe4, 0x33, 0xa3, 0x88, 0x5e, 0x16, 0x60, 0x3a, 0xb6, 0xba, 0x9a, 0x07, 0xfa, 0x1a, 0xd6, 0xd5,
-	0x2c, 0xef, 0xe2, 0x3b, 0x5a, 0x17, 0x73, 0x4a, 0x70, 0x78, 0xdf, 0xc3, 0x09, 0x27, 0xd4, 0x79,
-	0x4f, 0x44, 0x71, 0x94, 0x59, 0x77, 0xaf, 0xa2, 0x48, 0xfe, 0x43, 0x54, 0x38, 0x51, 0x5c, 0x75,
-	0xa6, 0x9b, 0x9f, 0xd0, 0xfa, 0x1e, 0xc0, 0x17, 0x44, 0xa0, 0x82, 0x9a, 0xb1, 0x2a, 0x36, 0xe1,
-	0x22, 0xcd, 0xc7, 0xb9, 0xae, 0x5b, 0xf6, 0x34, 0xad, 0x17, 0x50, 0xe9, 0x54, 0x0f, 0x32, 0x0b,
-	0xb8, 0x63, 0x24, 0xda, 0x98, 0xa2, 0xb1, 0x7c, 0x11, 0x8d, 0x02, 0x52, 0x9a, 0x22, 0xee, 0xb7,
-	0x32, 0x44, 0x5b, 0x91, 0x47, 0xbe, 0x11, 0xe2, 0x9b, 0xe8, 0x34, 0x3d, 0x17, 0xd1, 0x2b, 0x13,
-	0x52, 0xce, 0xfb, 0x3b, 0x1f, 0x1c, 0x65, 0xd6, 0xbd, 0xab, 0x58, 0xb9, 0x02, 0xac, 0xa5, 0xa0,
-	0x0b, 0xb7, 0x7c, 0xf3, 0xdf, 0xc5, 0x1f, 0x00, 0xbc, 0xf5, 0x55, 0x1c, 0xa4, 0x21, 0xb9, 0xb1,
-	0x6f, 0x62, 0xeb, 0xd7, 0x32, 0x5c, 0x2e, 0x62, 0xcc, 0x59, 0x0e, 0xcf, 0x15, 0xd7, 0x98, 0x14,
-	0x77, 0xda, 0xd7, 0xb9, 0x77, 0x94, 0x59, 0x1b, 0x33, 0x15, 0x76, 0x1a, 0xf8, 0x9f, 0x2d, 0xaa,
-	0xf3, 0xf6, 0xe1, 0xb1, 0x59, 0x7a, 0x72, 0x6c, 0x96, 0x4e, 0x8f, 0x4d, 0xf0, 0xed, 0xd0, 0x04,
-	0x3f, 0x0d, 0x4d, 0x70, 0x30, 0x34, 0xc1, 0xe1, 0xd0, 0x04, 0x7f, 0x0d, 0x4d, 0xf0, 0xf7, 0xd0,
-	0x2c, 0x9d, 0x0e, 0x4d, 0xf0, 0xf8, 0xc4, 0x2c, 0x1d, 0x9e, 0x98, 0xa5, 0x27, 0x27, 0x66, 0xa9,
-	0x57, 0x97, 0x24, 0x6c, 0xfc, 0x1b, 0x00, 0x00, 0xff, 0xff, 0x0d, 0x3e, 0xc1, 0x91, 0xd5, 0x0e,
-	0x00, 0x00,
+	0x18, 0x8e, 0x33, 0x49, 0xda, 0xb8, 0xb4, 0x80, 0x5b, 0x76, 0x47, 0x65, 0x35, 0x13, 0x45, 0x82,
+	0x0d, 0x12, 0x4c, 0x44, 0xbb, 0xb0, 0xe2, 0x43, 0x2b, 0x76, 0x28, 0x68, 0x2b, 0xad, 0x10, 0xcc,
+	0x56, 0xdc, 0x9d, 0x8e, 0x9b, 0x0c, 0x9d, 0xaf, 0xda, 0x9e, 0x15, 0xbd, 0xf1, 0x03, 0x40, 0x5a,
+	0x7e, 0x02, 0x17, 0x84, 0x04, 0xe2, 0x07, 0x20, 0x71, 0xef, 0xb1, 0xc7, 0x55, 0x25, 0x06, 0x9a,
+	0x5e, 0xa0, 0xa7, 0xfe, 0x04, 0x64, 0xcf, 0x47, 0x9c, 0xf4, 0x63, 0x9b, 0xee, 0xa5, 0x48, 0x5c,
+	0x12, 0xfb, 0xf5, 0xfb, 0xd8, 0xef, 0xfb, 0xbc, 0xcf, 0x6b, 0x0f, 0xbc, 0x1d, 0x6f, 0xf7, 0xbb,
+	0x3b, 0x09, 0xa1, 0x1e, 0xa1, 0xf2, 0x7f, 0x97, 0xe2, 0xb0, 0x4f, 0x94, 0xa1, 0x15, 0xd3, 0x88,
+	0x47, 0x08, 0x8e, 0x2c, 0xcb, 0x4b, 0xfd, 0xa8, 0x1f, 0x49, 0x73, 0x57, 0x8c, 0x32, 0x8f, 0x65,
+	0xb3, 0x1f, 0x45, 0x7d, 0x9f, 0x74, 0xe5, 0xac, 0x97, 0x6c, 0x75, 0xb9, 0x17, 0x10, 0xc6, 0x71,
+	0x10, 0xe7, 0x0e, 0xaf, 0x8a, 0xb3, 0xfc, 0xa8, 0x9f, 0x21, 0x8b, 0x41, 0xbe, 0xd8, 0xca, 0x17,
+	0x77, 0xfc, 0x20, 0x72, 0x89, 0xdf, 0x65, 0x1c, 0x73, 0x96, 0xfd, 0xe6, 0x1e, 0x8b, 0xc2, 0x23,
+	0x4e, 0xd8, 0x40, 0xfe, 0xe4, 0xc6, 0x8f, 0x9f, 0x19, 0x7f, 0x0f, 0x33, 0xd2, 0x75, 0xc9, 0x96,
+	0x17, 0x7a, 0xdc, 0x8b, 0x42, 0xa6, 0x8e, 0xf3, 0x4d, 0xde, 0xbd, 0xdc, 0x26, 0x93, 0x9c, 0xb4,
+	0xf7, 0xab, 0x70, 0xee, 0x61, 0xb4, 0xed, 0x39, 0x64, 0x27, 0x21, 0x8c, 0xa3, 0x25, 0x58, 0x97,
+	0x3e, 0x3a, 0x68, 0x81, 0x4e, 0xd3, 0xc9, 0x26, 0xc2, 0xea, 0x7b, 0x81, 0xc7, 0xf5, 0x6a, 0x0b,
+	0x74, 0xe6, 0x9d, 0x6c, 0x82, 0x10, 0xac, 0x31, 0x4e, 0x62, 0x5d, 0x6b, 0x81, 0x8e, 0xe6, 0xc8,
+	0x31, 0x5a, 0x86, 0xb3, 0x5e, 0xc8, 0x09, 0x7d, 0x8c, 0x7d, 0xbd, 0x29, 0xed, 0xe5, 0x1c, 0xdd,
+	0x83, 0x33, 0x8c, 0x63, 0xca, 0x37, 0x98, 0x5e, 0x6b, 0x81, 0xce, 0xdc, 0xca, 0xb2, 0x95, 0xf1,
+	0x6d, 0x15, 0x7c, 0x5b, 0x1b, 0x05, 0xdf, 0xf6, 0xec, 0x5e, 0x6a, 0x56, 0x9e, 0xfc, 0x69, 0x02,
+	0xa7, 0x00, 0xa1, 0xf7, 0x61, 0x9d, 0x84, 0xee, 0x06, 0xd3, 0xeb, 0x53, 0xa0, 0x33, 0x08, 0x7a,
+	0x1b, 0x36, 0x5d, 0x8f, 0x92, 0x4d, 0xc1, 0x99, 0xde, 0x68, 0x81, 0xce, 0xc2, 0xca, 0xa2, 0x55,
+	0xd6, 0x6f, 0xad, 0x58, 0x72, 0x46, 0x5e, 0x22, 0xbd, 0x18, 0xf3, 0x81, 0x3e, 0x23, 0x99, 0x90,
+	0x63, 0xd4, 0x86, 0x0d, 0x36, 0xc0, 0xd4, 0x65, 0xfa, 0x6c, 0x4b, 0xeb, 0x34, 0x6d, 0x78, 0x9c,
+	0x9a, 0xb9, 0xc5, 0xc9, 0xff, 0xdb, 0xff, 0x00, 0x88, 0x04, 0xa5, 0xeb, 0x21, 0xe3, 0x38, 0xe4,
+	0x57, 0x61, 0xf6, 0x43, 0xd8, 0x10, 0xca, 0xdb, 0x60, 0xba, 0x36, 0x45, 0xaa, 0x39, 0x66, 0x3c,
+	0xd7, 0xda, 0x54, 0xb9, 0xd6, 0xcf, 0xcc, 0xb5, 0x71, 0x6e, 0xae, 0x3f, 0xd7, 0xe0, 0x0b, 0x99,
+	0x7c, 0x58, 0x1c, 0x85, 0x8c, 0x08, 0xd0, 0x23, 0x8e, 0x79, 0xc2, 0xb2, 0x34, 0x73, 0x90, 0xb4,
+	0x38, 0xf9, 0x0a, 0xfa, 0x08, 0xd6, 0xd6, 0x30, 0xc7, 0x32, 0xe5, 0xb9, 0x95, 0x25, 0x4b, 0x11,
+	0xa5, 0xd8, 0x4b, 0xac, 0xd9, 0x37, 0x44, 0x56, 0xc7, 0xa9, 0xb9, 0xe0, 0x62, 0x8e, 0xdf, 0x8c,
+	0x02, 0x8f, 0x93, 0x20, 0xe6, 0xbb, 0x8e, 0x44, 0xa2, 0x77, 0x60, 0xf3, 0x13, 0x4a, 0x23, 0xba,
+	0xb1, 0x1b, 0x13, 0x49, 0x51, 0xd3, 0xbe, 0x79, 0x9c, 0x9a, 0x8b, 0xa4, 0x30, 0x2a, 0x88, 0x91,
+	0x27, 0x7a, 0x03, 0xd6, 0xe5, 0x44, 0x92, 0xd2, 0xb4, 0x17, 0x8f, 0x53, 0xf3, 0x45, 0x09, 0x51,
+	0xdc, 0x33, 0x8f, 0x71, 0x0e, 0xeb, 0x97, 0xe2, 0xb0, 0x2c, 0x65, 0x43, 0x2d, 0xa5, 0x0e, 0x67,
+	0x1e, 0x13, 0xca, 0xc4, 0x36, 0x33, 0xd2, 0x5e, 0x4c, 0xd1, 0x7d, 0x08, 0x05, 0x31, 0x1e, 0xe3,
+	0xde, 0xa6, 0xd0, 0x93, 0x20, 0x63, 0xde, 0xca, 0xae, 0x0b, 0x87, 0xb0, 0xc4, 0xe7, 0x36, 0xca,
+	0x59, 0x50, 0x1c, 0x1d, 0x65, 0x8c, 0x7e, 0x01, 0x70, 0xe6, 0x01, 0xc1, 0x2e, 0xa1, 0x4c, 0x6f,
+	0xb6, 0xb4, 0xce, 0xdc, 0xca, 0x6b, 0x96, 0x7a, 0x37, 0x7c, 0x4e, 0xa3, 0x80, 0xf0, 0x01, 0x49,
+	0x58, 0x51, 0xa0, 0xcc, 0xdb, 0xde, 0x3e, 0x48, 0xcd, 0x5e, 0xdf, 0xe3, 0x83, 0xa4, 0x67, 0x6d,
+	0x46, 0x41, 0xb7, 0x4f, 0xf1, 0x16, 0x0e, 0x71, 0xd7, 0x8f, 0xb6, 0xbd, 0xee, 0xd4, 0xf7, 0xd1,
+	0xb9, 0xe7, 0x1c, 0xa7, 0x26, 0x78, 0xcb, 0x29, 0x42, 0x6c, 0xff, 0x01, 0xe0, 0xcb, 0xa2, 0xc2,
+	0x8f, 0xc4, 0xde, 0x4c, 0x69, 0x8c, 0x00, 0xf3, 0xcd, 0x81, 0x0e, 0x84, 0xcc, 0x9c, 0x6c, 0xa2,
+	0x5e, 0x16, 0xd5, 0xe7, 0xba, 0x2c, 0xb4, 0xe9, 0x2f, 0x8b, 0xa2, 0x1b, 0x6a, 0x67, 0x76, 0x43,
+	0xfd, 0xdc, 0x6e, 0xf8, 0x56, 0x83, 0x48, 0xcd, 0x6f, 0x8a, 0x9e, 0xf8, 0xb4, 0xec, 0x09, 0x4d,
+	0x46, 0x5b, 0x4a, 0x2d, 0xdb, 0x6b, 0xdd, 0x25, 0x21, 0xf7, 0xb6, 0x3c, 0x42, 0x9f, 0xd1, 0x19,
+	0x8a, 0xdc, 0xb4, 0x71, 0xb9, 0xa9, 0x5a, 0xa9, 0x5d, 0x7b, 0xad, 0x4c, 0x74, 0x47, 0xfd, 0x0a,
+	0xdd, 0xd1, 0xfe, 0x1d, 0xc0, 0x57, 0x44, 0x39, 0x1e, 0xe2, 0x1e, 0xf1, 0x3f, 0xc3, 0xc1, 0x48,
+	0x72, 0x8a, 0xb8, 0xc0, 0x73, 0x89, 0xab, 0x7a, 0x75, 0x71, 0x69, 0x8a, 0xb8, 0xca, 0xb7, 0xa1,
+	0xa6, 0xbc, 0x0d, 0xed, 0x93, 0x2a, 0xbc, 0x31, 0x19, 0xff, 0x14, 0x92, 0x7a, 0x5d, 0x91, 0x54,
+	0xd3, 0x46, 0xff, 0x4b, 0xe6, 0x12, 0x92, 0xf9, 0x11, 0xc0, 0xd9, 0xe2, 0x0d, 0x42, 0x16, 0x84,
+	0x19, 0x4c, 0x3e, 0x33, 0x19, 0xd1, 0x0b, 0x02, 0x4c, 0x4b, 0xab, 0xa3, 0x78, 0xa0, 0xaf, 0x60,
+	0x23, 0x9b, 0xe5, 0x5d, 0x7c, 0x53, 0xe9, 0x62, 0x4e, 0x09, 0x0e, 0xee, 0xbb, 0x38, 0xe6, 0x84,
+	0xda, 0xef, 0x89, 0x28, 0x0e, 0x52, 0xf3, 0xf6, 0x45, 0x14, 0xc9, 0x2f, 0xc4, 0x0c, 0x27, 0x8a,
+	0x9b, 0x9d, 0xe9, 0xe4, 0x27, 0xb4, 0xbf, 0x03, 0xf0, 0x25, 0x11, 0xa8, 0xa0, 0xa6, 0x54, 0xc5,
+	0x1a, 0x9c, 0xa5, 0xf9, 0x38, 0xd7, 0x75, 0xdb, 0x1a, 0xa7, 0xf5, 0x0c, 0x2a, 0xed, 0xda, 0x5e,
+	0x6a, 0x02, 0xa7, 0x44, 0xa2, 0xd5, 0x31, 0x1a, 0xab, 0x67, 0xd1, 0x28, 0x20, 0x95, 0x31, 0xe2,
+	0x7e, 0xab, 0x42, 0xb4, 0x1e, 0xba, 0xe4, 0x6b, 0x21, 0xbe, 0x91, 0x4e, 0x93, 0x53, 0x11, 0xdd,
+	0x1a, 0x91, 0x72, 0xda, 0xdf, 0xfe, 0xe0, 0x20, 0x35, 0xef, 0x5e, 0xc4, 0xca, 0x05, 0x60, 0x25,
+	0x05, 0x55, 0xb8, 0xd5, 0xeb, 0xff, 0x2e, 0xfe, 0x00, 0xe0, 0xfc, 0x97, 0x91, 0x9f, 0x04, 0xe4,
+	0xda, 0xbe, 0x89, 0xed, 0x5f, 0xab, 0x70, 0xa1, 0x88, 0x31, 0x67, 0x39, 0x38, 0x55, 0x5c, 0x7d,
+	0x54, 0xdc, 0x71, 0x5f, 0xfb, 0xee, 0x41, 0x6a, 0xae, 0x5e, 0xaa, 0xb0, 0xe3, 0xc0, 0xff, 0x6e,
+	0x51, 0xbf, 0x07, 0x70, 0xfe, 0x0b, 0xb1, 0x4b, 0xc9, 0xd7, 0x1d, 0x51, 0x3e, 0xd1, 0xcf, 0xac,
+	0xa4, 0x6b, 0xe2, 0xd3, 0xb7, 0xdc, 0xb0, 0xe2, 0x14, 0xae, 0xe8, 0x1e, 0x84, 0x71, 0x79, 0x62,
+	0x5e, 0xf7, 0x5b, 0x93, 0x40, 0xf5, 0x1a, 0x78, 0x50, 0x71, 0x14, 0x84, 0x0d, 0x47, 0x55, 0xb2,
+	0xef, 0xec, 0x1f, 0x1a, 0x95, 0xa7, 0x87, 0x46, 0xe5, 0xe4, 0xd0, 0x00, 0xdf, 0x0c, 0x0d, 0xf0,
+	0xd3, 0xd0, 0x00, 0x7b, 0x43, 0x03, 0xec, 0x0f, 0x0d, 0xf0, 0xd7, 0xd0, 0x00, 0x7f, 0x0f, 0x8d,
+	0xca, 0xc9, 0xd0, 0x00, 0x4f, 0x8e, 0x8c, 0xca, 0xfe, 0x91, 0x51, 0x79, 0x7a, 0x64, 0x54, 0x7a,
+	0x0d, 0x59, 0x98, 0xd5, 0x7f, 0x07, 0x00, 0x86, 0x06, 0x8c, 0xee, 0x69, 0x0f, 0x00, 0x00,
 }
 
 func (this *LokiRequest) Equal(that interface{}) bool {
@@ -1410,6 +1500,84 @@ func (this *VolumeResponse) Equal(that interface{}) bool {
 	}
 	return true
 }
+func (this *QueryResponse) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*QueryResponse)
+	if !ok {
+		that2, ok := that.(QueryResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if that1.Response == nil {
+		if this.Response != nil {
+			return false
+		}
+	} else if this.Response == nil {
+		return false
+	} else if !this.Response.Equal(that1.Response) {
+		return false
+	}
+	return true
+}
+func (this *QueryResponse_Streams) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*QueryResponse_Streams)
+	if !ok {
+		that2, ok := that.(QueryResponse_Streams)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if !this.Streams.Equal(that1.Streams) {
+		return false
+	}
+	return true
+}
+func (this *QueryResponse_Prometheus) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*QueryResponse_Prometheus)
+	if !ok {
+		that2, ok := that.(QueryResponse_Prometheus)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if !this.Prometheus.Equal(that1.Prometheus) {
+		return false
+	}
+	return true
+}
 func (this *LokiRequest) GoString() string {
 	if this == nil {
 		return "nil"
@@ -1580,6 +1748,34 @@ func (this *VolumeResponse) GoString() string {
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
+func (this *QueryResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 6)
+	s = append(s, "&queryrange.QueryResponse{")
+	if this.Response != nil {
+		s = append(s, "Response: "+fmt.Sprintf("%#v", this.Response)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *QueryResponse_Streams) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&queryrange.QueryResponse_Streams{` +
+		`Streams:` + fmt.Sprintf("%#v", this.Streams) + `}`}, ", ")
+	return s
+}
+func (this *QueryResponse_Prometheus) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&queryrange.QueryResponse_Prometheus{` +
+		`Prometheus:` + fmt.Sprintf("%#v", this.Prometheus) + `}`}, ", ")
+	return s
+}
 func valueToGoStringQueryrange(v interface{}, typ string) string {
 	rv := reflect.ValueOf(v)
 	if rv.IsNil() {
@@ -2320,6 +2516,80 @@ func (m *VolumeResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *QueryResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *QueryResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *QueryResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Response != nil {
+		{
+			size := m.Response.Size()
+			i -= size
+			if _, err := m.Response.MarshalTo(dAtA[i:]); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *QueryResponse_Streams) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *QueryResponse_Streams) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	if m.Streams != nil {
+		{
+			size, err := m.Streams.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintQueryrange(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+func (m *QueryResponse_Prometheus) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *QueryResponse_Prometheus) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	if m.Prometheus != nil {
+		{
+			size, err := m.Prometheus.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintQueryrange(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x12
+	}
+	return len(dAtA) - i, nil
+}
 func encodeVarintQueryrange(dAtA []byte, offset int, v uint64) int {
 	offset -= sovQueryrange(v)
 	base := offset
@@ -2642,6 +2912,43 @@ func (m *VolumeResponse) Size() (n int) {
 	return n
 }
 
+func (m *QueryResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Response != nil {
+		n += m.Response.Size()
+	}
+	return n
+}
+
+func (m *QueryResponse_Streams) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Streams != nil {
+		l = m.Streams.Size()
+		n += 1 + l + sovQueryrange(uint64(l))
+	}
+	return n
+}
+func (m *QueryResponse_Prometheus) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Prometheus != nil {
+		l = m.Prometheus.Size()
+		n += 1 + l + sovQueryrange(uint64(l))
+	}
+	return n
+}
+
 func sovQueryrange(x uint64) (n int) {
 	return (math_bits.Len64(x|1) + 6) / 7
 }
@@ -2815,6 +3122,36 @@ func (this *VolumeResponse) String() string {
 	}, "")
 	return s
 }
+func (this *QueryResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&QueryResponse{`,
+		`Response:` + fmt.Sprintf("%v", this.Response) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *QueryResponse_Streams) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&QueryResponse_Streams{`,
+		`Streams:` + strings.Replace(fmt.Sprintf("%v", this.Streams), "LokiResponse", "LokiResponse", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *QueryResponse_Prometheus) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&QueryResponse_Prometheus{`,
+		`Prometheus:` + strings.Replace(fmt.Sprintf("%v", this.Prometheus), "LokiPromResponse", "LokiPromResponse", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func valueToStringQueryrange(v interface{}) string {
 	rv := reflect.ValueOf(v)
 	if rv.IsNil() {
@@ -5084,6 +5421,126 @@ func (m *VolumeResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *QueryResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQueryrange
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: QueryResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: QueryResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Streams", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryrange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &LokiResponse{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Response = &QueryResponse_Streams{v}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Prometheus", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryrange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &LokiPromResponse{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Response = &QueryResponse_Prometheus{v}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQueryrange(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipQueryrange(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
@@ -172,6 +172,7 @@ type resultsCache struct {
 	shouldCache          ShouldCacheFn
 	parallelismForReq    func(ctx context.Context, tenantIDs []string, r Request) int
 	retentionEnabled     bool
+	onlyUseEntireExtent  bool
 	metrics              *ResultsCacheMetrics
 }
 
@@ -181,6 +182,8 @@ type resultsCache struct {
 // Each request starting from within the same interval will hit the same cache entry.
 // If the cache doesn't have the entire duration of the request cached, it will query the uncached parts and append them to the cache entries.
 // see `generateKey`.
+// With onlyUseEntireExtent, only the cached extents within the time range of a request are used, for
+// the responses which cannot be extracted to a subset of their time range.
 func NewResultsCacheMiddleware(
 	logger log.Logger,
 	c cache.Cache,
@@ -192,6 +195,7 @@ func NewResultsCacheMiddleware(
 	shouldCache ShouldCacheFn,
 	parallelismForReq func(ctx context.Context, tenantIDs []string, r Request) int,
 	retentionEnabled bool,
+	onlyUseEntireExtent bool,
 	metrics *ResultsCacheMetrics,
 ) (Middleware, error) {
 	if cacheGenNumberLoader != nil {
@@ -212,6 +216,7 @@ func NewResultsCacheMiddleware(
 			shouldCache:          shouldCache,
 			parallelismForReq:    parallelismForReq,
 			retentionEnabled:     retentionEnabled,
+			onlyUseEntireExtent:  onlyUseEntireExtent,
 			metrics:              metrics,
 		}
 	}), nil
@@ -568,6 +573,11 @@ func (s resultsCache) partition(req Request, extents []Extent) ([]Request, []Res
 			continue
 		}
 
+		// The extent is only used if the request covers it entirely when its response cannot be extracted.
+		if s.onlyUseEntireExtent && (start > extent.GetStart() || req.GetEnd() < extent.GetEnd()) {
+			continue
+		}
+
 		// If there is a bit missing at the front, make a request for that.
 		if start < extent.Start {
 			r := req.WithStartEnd(start, extent.Start)
@@ -770,6 +770,7 @@ func TestResultsCache(t *testing.T) {
 			return mockLimits{}.MaxQueryParallelism(context.Background(), "fake")
 		},
 		false,
+		false,
 		nil,
 	)
 	require.NoError(t, err)
@@ -816,6 +817,7 @@ func TestResultsCacheRecent(t *testing.T) {
 			return mockLimits{}.MaxQueryParallelism(context.Background(), "fake")
 		},
 		false,
+		false,
 		nil,
 	)
 	require.NoError(t, err)
@@ -884,6 +886,7 @@ func TestResultsCacheMaxFreshness(t *testing.T) {
 					return tc.fakeLimits.MaxQueryParallelism(context.Background(), "fake")
 				},
 				false,
+				false,
 				nil,
 			)
 			require.NoError(t, err)
@@ -927,6 +930,7 @@ func Test_resultsCache_MissingData(t *testing.T) {
 			return mockLimits{}.MaxQueryParallelism(context.Background(), "fake")
 		},
 		false,
+		false,
 		nil,
 	)
 	require.NoError(t, err)
@@ -1042,6 +1046,7 @@ func TestResultsCacheShouldCacheFunc(t *testing.T) {
 					return mockLimits{}.MaxQueryParallelism(context.Background(), "fake")
 				},
 				false,
+				false,
 				nil,
 			)
 			require.NoError(t, err)
@@ -2,18 +2,28 @@ package queryrangebase
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/backoff"
+	"github.com/grafana/dskit/tenant"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/weaveworks/common/httpgrpc"
 
 	util_log "github.com/grafana/loki/pkg/util/log"
+	"github.com/grafana/loki/pkg/util/validation"
+)
+
+const (
+	retryOutcomeSucceeded = "succeeded"
+	retryOutcomeExhausted = "exhausted"
 )
 
 type RetryMiddlewareMetrics struct {
-	retriesCount prometheus.Histogram
+	retriesCount    prometheus.Histogram
+	retriedRequests *prometheus.CounterVec
 }
 
 func NewRetryMiddlewareMetrics(registerer prometheus.Registerer) *RetryMiddlewareMetrics {
@@ -24,20 +34,46 @@ func NewRetryMiddlewareMetrics(registerer prometheus.Registerer) *RetryMiddlewar
 			Help:      "Number of times a request is retried.",
 			Buckets:   []float64{0, 1, 2, 3, 4, 5},
 		}),
+		retriedRequests: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki",
+			Name:      "query_frontend_retried_requests_total",
+			Help:      "Total number of requests that were retried, by whether a retry succeeded or the retries were exhausted.",
+		}, []string{"outcome"}),
 	}
 }
 
+// RetryLimits allows to override the retry policy of the requests per tenant.
+type RetryLimits interface {
+	// QueryMaxRetries returns the maximum number of times a request is tried,
+	// or 0 to use the default of the middleware.
+	QueryMaxRetries(context.Context, string) int
+
+	// QueryRetryMinBackoff returns the initial delay before retrying a request,
+	// or 0 to retry immediately.
+	QueryRetryMinBackoff(context.Context, string) time.Duration
+
+	// QueryRetryMaxBackoff returns the maximum delay before retrying a request.
+	QueryRetryMaxBackoff(context.Context, string) time.Duration
+
+	// QueryRetryStatusCodes returns the HTTP status codes of the failed requests
+	// which are retried, or none to retry the 5xx and non-HTTP errors.
+	QueryRetryStatusCodes(context.Context, string) []int
+}
+
 type retry struct {
 	log        log.Logger
 	next       Handler
 	maxRetries int
+	limits     RetryLimits
 
 	metrics *RetryMiddlewareMetrics
 }
 
 // NewRetryMiddleware returns a middleware that retries requests if they
-// fail with 500 or a non-HTTP error.
-func NewRetryMiddleware(log log.Logger, maxRetries int, metrics *RetryMiddlewareMetrics) Middleware {
+// fail with 500 or a non-HTTP error. The limits, if not nil, override the
+// number of retries, the backoff between them and the retried status codes
+// per tenant.
+func NewRetryMiddleware(log log.Logger, maxRetries int, limits RetryLimits, metrics *RetryMiddlewareMetrics) Middleware {
 	if metrics == nil {
 		metrics = NewRetryMiddlewareMetrics(nil)
 	}
@@ -47,28 +83,94 @@ func NewRetryMiddleware(log log.Logger, maxRetries int, metrics *RetryMiddleware
 			log:        log,
 			next:       next,
 			maxRetries: maxRetries,
+			limits:     limits,
 			metrics:    metrics,
 		}
 	})
 }
 
+// retryPolicy is the retry policy of a request.
+type retryPolicy struct {
+	maxRetries  int
+	backoff     backoff.Config
+	statusCodes map[int]struct{}
+}
+
+func (r retry) policy(ctx context.Context) retryPolicy {
+	p := retryPolicy{maxRetries: r.maxRetries}
+	if r.limits == nil {
+		return p
+	}
+	tenantIDs, err := tenant.TenantIDs(ctx)
+	if err != nil {
+		return p
+	}
+
+	if maxRetries := validation.SmallestPositiveNonZeroIntPerTenant(tenantIDs, func(id string) int {
+		return r.limits.QueryMaxRetries(ctx, id)
+	}); maxRetries > 0 {
+		p.maxRetries = maxRetries
+	}
+	p.backoff.MinBackoff = validation.MaxDurationPerTenant(tenantIDs, func(id string) time.Duration {
+		return r.limits.QueryRetryMinBackoff(ctx, id)
+	})
+	p.backoff.MaxBackoff = validation.MaxDurationPerTenant(tenantIDs, func(id string) time.Duration {
+		return r.limits.QueryRetryMaxBackoff(ctx, id)
+	})
+	for _, id := range tenantIDs {
+		for _, code := range r.limits.QueryRetryStatusCodes(ctx, id) {
+			if p.statusCodes == nil {
+				p.statusCodes = map[int]struct{}{}
+			}
+			p.statusCodes[code] = struct{}{}
+		}
+	}
+	return p
+}
+
+// retryable returns whether a request failed with the given error is retried.
+func (p retryPolicy) retryable(err error) bool {
+	httpResp, ok := httpgrpc.HTTPResponseFromError(err)
+	if !ok {
+		return true
+	}
+	if p.statusCodes == nil {
+		return httpResp.Code/100 == 5
+	}
+	_, ok = p.statusCodes[int(httpResp.Code)]
+	return ok
+}
+
 func (r retry) Do(ctx context.Context, req Request) (Response, error) {
+	p := r.policy(ctx)
+
 	tries := 0
 	defer func() { r.metrics.retriesCount.Observe(float64(tries)) }()
 
+	var bk *backoff.Backoff
+	if p.backoff.MinBackoff > 0 {
+		bk = backoff.New(ctx, p.backoff)
+	}
+
 	var lastErr error
-	for ; tries < r.maxRetries; tries++ {
+	for ; tries < p.maxRetries; tries++ {
+		if tries > 0 && bk != nil {
+			bk.Wait()
+		}
 		if ctx.Err() != nil {
 			return nil, ctx.Err()
 		}
 		resp, err := r.next.Do(ctx, req)
 		if err == nil {
+			if tries > 0 {
+				r.metrics.retriedRequests.WithLabelValues(retryOutcomeSucceeded).Inc()
+			}
 			return resp, nil
 		}
 
-		// Retry if we get a HTTP 500 or a non-HTTP error.
-		httpResp, ok := httpgrpc.HTTPResponseFromError(err)
-		if !ok || httpResp.Code/100 == 5 {
+		// Retry if we get a HTTP 500 or a non-HTTP error, or one of the
+		// status codes retried for the tenant.
+		if p.retryable(err) {
 			lastErr = err
 			level.Error(util_log.WithContext(ctx, r.log)).Log("msg", "error processing request", "try", tries, "query", req.GetQuery(), "err", err)
 			continue
@@ -76,5 +178,8 @@ func (r retry) Do(ctx context.Context, req Request) (Response, error) {
 
 		return nil, err
 	}
+	if lastErr != nil && tries > 1 {
+		r.metrics.retriedRequests.WithLabelValues(retryOutcomeExhausted).Inc()
+	}
 	return nil, lastErr
 }
@@ -6,10 +6,14 @@ import (
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 	"github.com/weaveworks/common/httpgrpc"
+	"github.com/weaveworks/common/user"
 	"go.uber.org/atomic"
 )
 
@@ -59,7 +63,7 @@ func TestRetry(t *testing.T) {
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			try.Store(0)
-			h := NewRetryMiddleware(log.NewNopLogger(), 5, nil).Wrap(tc.handler)
+			h := NewRetryMiddleware(log.NewNopLogger(), 5, nil, nil).Wrap(tc.handler)
 			req := &PrometheusRequest{
 				Query: `{env="test"} |= "error"`,
 			}
@@ -78,7 +82,7 @@ func Test_RetryMiddlewareCancel(t *testing.T) {
 	var try atomic.Int32
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
-	_, err := NewRetryMiddleware(log.NewNopLogger(), 5, nil).Wrap(
+	_, err := NewRetryMiddleware(log.NewNopLogger(), 5, nil, nil).Wrap(
 		HandlerFunc(func(c context.Context, r Request) (Response, error) {
 			try.Inc()
 			return nil, ctx.Err()
@@ -88,7 +92,7 @@ func Test_RetryMiddlewareCancel(t *testing.T) {
 	require.Equal(t, ctx.Err(), err)
 
 	ctx, cancel = context.WithCancel(context.Background())
-	_, err = NewRetryMiddleware(log.NewNopLogger(), 5, nil).Wrap(
+	_, err = NewRetryMiddleware(log.NewNopLogger(), 5, nil, nil).Wrap(
 		HandlerFunc(func(c context.Context, r Request) (Response, error) {
 			try.Inc()
 			cancel()
@@ -98,3 +102,83 @@ func Test_RetryMiddlewareCancel(t *testing.T) {
 	require.Equal(t, int32(1), try.Load())
 	require.Equal(t, ctx.Err(), err)
 }
+
+type fakeRetryLimits struct {
+	maxRetries  int
+	minBackoff  time.Duration
+	statusCodes []int
+}
+
+func (l fakeRetryLimits) QueryMaxRetries(context.Context, string) int { return l.maxRetries }
+func (l fakeRetryLimits) QueryRetryMinBackoff(context.Context, string) time.Duration {
+	return l.minBackoff
+}
+func (l fakeRetryLimits) QueryRetryMaxBackoff(context.Context, string) time.Duration {
+	return l.minBackoff
+}
+func (l fakeRetryLimits) QueryRetryStatusCodes(context.Context, string) []int { return l.statusCodes }
+
+func TestRetryPerTenant(t *testing.T) {
+	req := &PrometheusRequest{
+		Query: `{env="test"} |= "error"`,
+	}
+	ctx := user.InjectOrgID(context.Background(), "1")
+
+	for _, tc := range []struct {
+		name      string
+		limits    fakeRetryLimits
+		errs      []error
+		tries     int32
+		err       error
+		succeeded float64
+		exhausted float64
+	}{
+		{
+			name:      "max retries",
+			limits:    fakeRetryLimits{maxRetries: 2},
+			errs:      []error{errors.New("fail"), errors.New("fail"), errors.New("fail")},
+			tries:     2,
+			err:       errors.New("fail"),
+			exhausted: 1,
+		},
+		{
+			name:      "backoff",
+			limits:    fakeRetryLimits{minBackoff: time.Millisecond},
+			errs:      []error{errors.New("fail")},
+			tries:     2,
+			succeeded: 1,
+		},
+		{
+			name:      "retry status codes",
+			limits:    fakeRetryLimits{statusCodes: []int{http.StatusTooManyRequests}},
+			errs:      []error{httpgrpc.Errorf(http.StatusTooManyRequests, "Too Many Requests")},
+			tries:     2,
+			succeeded: 1,
+		},
+		{
+			name:   "don't retry other status codes",
+			limits: fakeRetryLimits{statusCodes: []int{http.StatusTooManyRequests}},
+			errs:   []error{httpgrpc.Errorf(http.StatusInternalServerError, "Internal Server Error")},
+			tries:  1,
+			err:    httpgrpc.Errorf(http.StatusInternalServerError, "Internal Server Error"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var try atomic.Int32
+			metrics := NewRetryMiddlewareMetrics(prometheus.NewRegistry())
+			_, err := NewRetryMiddleware(log.NewNopLogger(), 5, tc.limits, metrics).Wrap(
+				HandlerFunc(func(c context.Context, r Request) (Response, error) {
+					i := int(try.Inc()) - 1
+					if i < len(tc.errs) {
+						return nil, tc.errs[i]
+					}
+					return &PrometheusResponse{Status: "Hello World"}, nil
+				}),
+			).Do(ctx, req)
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.tries, try.Load())
+			require.Equal(t, tc.succeeded, testutil.ToFloat64(metrics.retriedRequests.WithLabelValues(retryOutcomeSucceeded)))
+			require.Equal(t, tc.exhausted, testutil.ToFloat64(metrics.retriedRequests.WithLabelValues(retryOutcomeExhausted)))
+		})
+	}
+}
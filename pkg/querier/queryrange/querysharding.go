@@ -22,6 +22,7 @@ import (
 	"github.com/grafana/loki/pkg/querier/queryrange/queryrangebase"
 	"github.com/grafana/loki/pkg/storage/config"
 	"github.com/grafana/loki/pkg/util"
+	"github.com/grafana/loki/pkg/util/httpreq"
 	util_log "github.com/grafana/loki/pkg/util/log"
 	"github.com/grafana/loki/pkg/util/marshal"
 	"github.com/grafana/loki/pkg/util/spanlogger"
@@ -109,6 +110,16 @@ type astMapperware struct {
 	maxShards    int
 }
 
+// quantileSketchSharding tells if quantile_over_time is sharded with quantile sketches, which all the tenants must enable.
+func quantileSketchSharding(ctx context.Context, tenantIDs []string, limits Limits) bool {
+	for _, id := range tenantIDs {
+		if !limits.QuantileSketchSharding(ctx, id) {
+			return false
+		}
+	}
+	return true
+}
+
 func (ast *astMapperware) checkQuerySizeLimit(ctx context.Context, bytesPerShard uint64, notShardable bool) error {
 	tenantIDs, err := tenant.TenantIDs(ctx)
 	if err != nil {
@@ -122,6 +133,7 @@ func (ast *astMapperware) checkQuerySizeLimit(ctx context.Context, bytesPerShard
 
 		if bytesPerShard > uint64(maxBytesRead) {
 			level.Warn(ast.logger).Log("msg", "Query exceeds limits", "status", "rejected", "limit_name", "MaxQuerierBytesRead", "limit_bytes", maxBytesReadStr, "resolved_bytes", statsBytesStr)
+			queriesRejectedBySize.WithLabelValues(tenant.JoinTenantIDs(tenantIDs), "MaxQuerierBytesRead").Inc()
 
 			errorTmpl := limErrQuerierTooManyBytesShardableTmpl
 			if notShardable {
@@ -143,6 +155,11 @@ func (ast *astMapperware) Do(ctx context.Context, r queryrangebase.Request) (que
 		util_log.WithContext(ctx, ast.logger),
 	)
 
+	// the quantile sketches of the splits of a quantile_over_time query are computed by a single querier.
+	if httpreq.ExtractHeader(ctx, httpreq.LokiQuantileSketchesHeader) != "" {
+		return ast.next.Do(ctx, r)
+	}
+
 	maxRVDuration, maxOffset, err := maxRangeVectorAndOffsetDuration(r.GetQuery())
 	if err != nil {
 		level.Warn(logger).Log("err", err.Error(), "msg", "failed to get range-vector and offset duration so skipped AST mapper for request")
@@ -176,7 +193,7 @@ func (ast *astMapperware) Do(ctx context.Context, r queryrangebase.Request) (que
 		return ast.next.Do(ctx, r)
 	}
 
-	mapper := logql.NewShardMapper(resolver, ast.metrics)
+	mapper := logql.NewShardMapper(resolver, ast.metrics, quantileSketchSharding(ctx, tenants, ast.limits))
 
 	noop, bytesPerShard, parsed, err := mapper.Parse(r.GetQuery())
 	if err != nil {
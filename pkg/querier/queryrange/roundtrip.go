@@ -31,6 +31,11 @@ type Config struct {
 	Transformer            UserIDTransformer     `yaml:"-"`
 	CacheIndexStatsResults bool                  `yaml:"cache_index_stats_results"`
 	StatsCacheConfig       IndexStatsCacheConfig `yaml:"index_stats_results_cache" doc:"description=If a cache config is not specified and cache_index_stats_results is true, the config for the results cache is used."`
+
+	CacheSeriesResults bool              `yaml:"cache_series_results"`
+	SeriesCacheConfig  SeriesCacheConfig `yaml:"series_results_cache" doc:"description=If a cache config is not specified and cache_series_results is true, the config for the results cache is used."`
+	CacheLabelResults  bool              `yaml:"cache_label_results"`
+	LabelsCacheConfig  LabelsCacheConfig `yaml:"label_results_cache" doc:"description=If a cache config is not specified and cache_label_results is true, the config for the results cache is used."`
 }
 
 // RegisterFlags adds the flags required to configure this flag set.
@@ -38,6 +43,10 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.Config.RegisterFlags(f)
 	f.BoolVar(&cfg.CacheIndexStatsResults, "querier.cache-index-stats-results", false, "Cache index stats query results.")
 	cfg.StatsCacheConfig.RegisterFlags(f)
+	f.BoolVar(&cfg.CacheSeriesResults, "querier.cache-series-results", false, "Cache series query results.")
+	cfg.SeriesCacheConfig.RegisterFlags(f)
+	f.BoolVar(&cfg.CacheLabelResults, "querier.cache-label-results", false, "Cache label names and label values query results.")
+	cfg.LabelsCacheConfig.RegisterFlags(f)
 }
 
 // Validate validates the config.
@@ -51,6 +60,18 @@ func (cfg *Config) Validate() error {
 			return errors.Wrap(err, "invalid index_stats_results_cache config")
 		}
 	}
+
+	if cfg.CacheSeriesResults {
+		if err := cfg.SeriesCacheConfig.Validate(); err != nil {
+			return errors.Wrap(err, "invalid series_results_cache config")
+		}
+	}
+
+	if cfg.CacheLabelResults {
+		if err := cfg.LabelsCacheConfig.Validate(); err != nil {
+			return errors.Wrap(err, "invalid label_results_cache config")
+		}
+	}
 	return nil
 }
 
@@ -103,6 +124,8 @@ func NewTripperware(
 	var (
 		resultsCache cache.Cache
 		statsCache   cache.Cache
+		seriesCache  cache.Cache
+		labelsCache  cache.Cache
 		err          error
 	)
 
@@ -127,6 +150,34 @@ func NewTripperware(
 		}
 	}
 
+	if cfg.CacheSeriesResults {
+		// If the series cache is not configured, use the results cache config.
+		cacheCfg := cfg.SeriesCacheConfig.ResultsCacheConfig
+		if !cache.IsCacheConfigured(cacheCfg.CacheConfig) {
+			level.Debug(log).Log("msg", "using results cache config for series cache")
+			cacheCfg = cfg.ResultsCacheConfig
+		}
+
+		seriesCache, err = newResultsCacheFromConfig(cacheCfg, registerer, log, stats.SeriesResultCache)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if cfg.CacheLabelResults {
+		// If the labels cache is not configured, use the results cache config.
+		cacheCfg := cfg.LabelsCacheConfig.ResultsCacheConfig
+		if !cache.IsCacheConfigured(cacheCfg.CacheConfig) {
+			level.Debug(log).Log("msg", "using results cache config for labels cache")
+			cacheCfg = cfg.ResultsCacheConfig
+		}
+
+		labelsCache, err = newResultsCacheFromConfig(cacheCfg, registerer, log, stats.LabelResultCache)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	indexStatsTripperware, err := NewIndexStatsTripperware(cfg, log, limits, schema, LokiCodec, statsCache,
 		cacheGenNumLoader, retentionEnabled, metrics)
 	if err != nil {
@@ -151,12 +202,12 @@ func NewTripperware(
 		return nil, nil, err
 	}
 
-	seriesTripperware, err := NewSeriesTripperware(cfg, log, limits, LokiCodec, metrics, schema)
+	seriesTripperware, err := NewSeriesTripperware(cfg, log, limits, LokiCodec, seriesCache, cacheGenNumLoader, retentionEnabled, metrics, schema)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	labelsTripperware, err := NewLabelsTripperware(cfg, log, limits, LokiCodec, metrics, schema)
+	labelsTripperware, err := NewLabelsTripperware(cfg, log, limits, LokiCodec, labelsCache, cacheGenNumLoader, retentionEnabled, metrics, schema)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -181,22 +232,29 @@ func NewTripperware(
 			instantRT     = instantMetricTripperware(next)
 			statsRT       = indexStatsTripperware(next)
 			labelVolumeRT = labelVolumeTripperware(next)
+			explainRT     = newQueryExplainer(log, cfg, engineOpts, limits, schema, statsRT)
+			// The streamed log queries are validated as a whole before being split.
+			streamLimits = queryrangebase.MergeMiddlewares(
+				NewLimitsMiddleware(limits),
+				NewQuerySizeLimiterMiddleware(schema.Configs, engineOpts, log, limits, queryrangebase.NewRoundTripperHandler(statsRT, LokiCodec)),
+			)
 		)
 
-		return newRoundTripper(log, next, limitedRT, logFilterRT, metricRT, seriesRT, labelsRT, instantRT, statsRT, labelVolumeRT, limits)
-	}, StopperWrapper{resultsCache, statsCache}, nil
+		return newRoundTripper(log, next, limitedRT, logFilterRT, metricRT, seriesRT, labelsRT, instantRT, statsRT, labelVolumeRT, explainRT, streamLimits, limits)
+	}, StopperWrapper{resultsCache, statsCache, seriesCache, labelsCache}, nil
 }
 
 type roundTripper struct {
 	logger log.Logger
 
-	next, limited, log, metric, series, labels, instantMetric, indexStats, labelVolume http.RoundTripper
+	next, limited, log, metric, series, labels, instantMetric, indexStats, labelVolume, explain http.RoundTripper
 
-	limits Limits
+	streamLimits queryrangebase.Middleware
+	limits       Limits
 }
 
 // newRoundTripper creates a new queryrange roundtripper
-func newRoundTripper(logger log.Logger, next, limited, log, metric, series, labels, instantMetric, indexStats, labelVolume http.RoundTripper, limits Limits) roundTripper {
+func newRoundTripper(logger log.Logger, next, limited, log, metric, series, labels, instantMetric, indexStats, labelVolume, explain http.RoundTripper, streamLimits queryrangebase.Middleware, limits Limits) roundTripper {
 	return roundTripper{
 		logger:        logger,
 		limited:       limited,
@@ -208,6 +266,8 @@ func newRoundTripper(logger log.Logger, next, limited, log, metric, series, labe
 		instantMetric: instantMetric,
 		indexStats:    indexStats,
 		labelVolume:   labelVolume,
+		explain:       explain,
+		streamLimits:  streamLimits,
 		next:          next,
 	}
 }
@@ -233,6 +293,10 @@ func (r roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 		queryHash := logql.HashedQuery(rangeQuery.Query)
 		level.Info(logger).Log("msg", "executing query", "type", "range", "query", rangeQuery.Query, "length", rangeQuery.End.Sub(rangeQuery.Start), "step", rangeQuery.Step, "query_hash", queryHash)
 
+		if err := validateBlockedQuery(req, r.limits, logger, rangeQuery.Query); err != nil {
+			return nil, err
+		}
+
 		switch e := expr.(type) {
 		case syntax.SampleExpr:
 			// The error will be handled later.
@@ -262,10 +326,14 @@ func (r roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 			}
 
 			// Only filter expressions are query sharded
+			next := r.log
 			if !expr.HasFilter() {
-				return r.limited.RoundTrip(req)
+				next = r.limited
+			}
+			if acceptsStreaming(req) {
+				return streamLogQuery(req, next, r.streamLimits, r.limits, logger)
 			}
-			return r.log.RoundTrip(req)
+			return next.RoundTrip(req)
 
 		default:
 			return r.next.RoundTrip(req)
@@ -301,6 +369,10 @@ func (r roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 		queryHash := logql.HashedQuery(instantQuery.Query)
 		level.Info(logger).Log("msg", "executing query", "type", "instant", "query", instantQuery.Query, "query_hash", queryHash)
 
+		if err := validateBlockedQuery(req, r.limits, logger, instantQuery.Query); err != nil {
+			return nil, err
+		}
+
 		switch expr.(type) {
 		case syntax.SampleExpr:
 			return r.instantMetric.RoundTrip(req)
@@ -323,6 +395,17 @@ func (r roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 		level.Info(logger).Log("msg", "executing query", "type", "series_volume", "query", volumeQuery.Query, "length", volumeQuery.End.Sub(volumeQuery.Start), "limit", volumeQuery.Limit)
 
 		return r.labelVolume.RoundTrip(req)
+	case QueryExplainOp:
+		query := req.Form.Get("query")
+		level.Info(logger).Log("msg", "explaining query", "type", req.Form.Get("type"), "query", query)
+
+		if query != "" {
+			if err := validateBlockedQuery(req, r.limits, logger, query); err != nil {
+				return nil, err
+			}
+		}
+
+		return r.explain.RoundTrip(req)
 	default:
 		return r.next.RoundTrip(req)
 	}
@@ -354,10 +437,13 @@ const (
 	LabelNamesOp   = "labels"
 	IndexStatsOp   = "index_stats"
 	SeriesVolumeOp = "series_volume"
+	QueryExplainOp = "query_explain"
 )
 
 func getOperation(path string) string {
 	switch {
+	case path == "/loki/api/v1/query_explain":
+		return QueryExplainOp
 	case strings.HasSuffix(path, "/query_range") || strings.HasSuffix(path, "/prom/query"):
 		return QueryRangeOp
 	case strings.HasSuffix(path, "/series"):
@@ -441,7 +527,7 @@ func NewLogFilterTripperware(
 		if cfg.MaxRetries > 0 {
 			queryRangeMiddleware = append(
 				queryRangeMiddleware, queryrangebase.InstrumentMiddleware("retry", metrics.InstrumentMiddlewareMetrics),
-				queryrangebase.NewRetryMiddleware(log, cfg.MaxRetries, metrics.RetryMiddlewareMetrics),
+				queryrangebase.NewRetryMiddleware(log, cfg.MaxRetries, limits, metrics.RetryMiddlewareMetrics),
 			)
 		}
 
@@ -493,23 +579,62 @@ func NewSeriesTripperware(
 	log log.Logger,
 	limits Limits,
 	codec queryrangebase.Codec,
+	c cache.Cache,
+	cacheGenNumLoader queryrangebase.CacheGenNumberLoader,
+	retentionEnabled bool,
 	metrics *Metrics,
 	schema config.SchemaConfig,
 ) (queryrangebase.Tripperware, error) {
+	// The Series API needs to pull one chunk per series to extract the label set, which is much cheaper than iterating through all matching chunks.
+	// Split by the split_metadata_queries_by_interval, its 24 hours default is more efficient with our static daily bucket storage.
+	// This would avoid queriers downloading chunks for same series over and over again for serving smaller queries.
+	limits = WithMetadataSplitLimits(limits)
+
 	queryRangeMiddleware := []queryrangebase.Middleware{
 		StatsCollectorMiddleware(),
 		NewLimitsMiddleware(limits),
 		queryrangebase.InstrumentMiddleware("split_by_interval", metrics.InstrumentMiddlewareMetrics),
-		// The Series API needs to pull one chunk per series to extract the label set, which is much cheaper than iterating through all matching chunks.
-		// Force a 24 hours split by for series API, this will be more efficient with our static daily bucket storage.
-		// This would avoid queriers downloading chunks for same series over and over again for serving smaller queries.
-		SplitByIntervalMiddleware(schema.Configs, WithSplitByLimits(limits, 24*time.Hour), codec, splitByTime, metrics.SplitByMetrics),
+		SplitByIntervalMiddleware(schema.Configs, limits, codec, splitByTime, metrics.SplitByMetrics),
+	}
+
+	if cfg.CacheSeriesResults {
+		cacheMiddleware, err := NewSeriesCacheMiddleware(
+			log,
+			limits,
+			codec,
+			c,
+			cacheGenNumLoader,
+			func(_ context.Context, r queryrangebase.Request) bool {
+				return !r.GetCachingOptions().Disabled
+			},
+			func(ctx context.Context, tenantIDs []string, r queryrangebase.Request) int {
+				return MinWeightedParallelism(
+					ctx,
+					tenantIDs,
+					schema.Configs,
+					limits,
+					model.Time(r.GetStart()),
+					model.Time(r.GetEnd()),
+				)
+			},
+			retentionEnabled,
+			cfg.Transformer,
+			metrics.ResultsCacheMetrics,
+		)
+		if err != nil {
+			return nil, err
+		}
+		queryRangeMiddleware = append(
+			queryRangeMiddleware,
+			queryrangebase.InstrumentMiddleware("series_results_cache", metrics.InstrumentMiddlewareMetrics),
+			cacheMiddleware,
+		)
 	}
 
 	if cfg.MaxRetries > 0 {
 		queryRangeMiddleware = append(queryRangeMiddleware,
 			queryrangebase.InstrumentMiddleware("retry", metrics.InstrumentMiddlewareMetrics),
-			queryrangebase.NewRetryMiddleware(log, cfg.MaxRetries, metrics.RetryMiddlewareMetrics),
+			queryrangebase.NewRetryMiddleware(log, cfg.MaxRetries, limits, metrics.RetryMiddlewareMetrics),
 		)
 	}
 
@@ -540,22 +665,61 @@ func NewLabelsTripperware(
 	log log.Logger,
 	limits Limits,
 	codec queryrangebase.Codec,
+	c cache.Cache,
+	cacheGenNumLoader queryrangebase.CacheGenNumberLoader,
+	retentionEnabled bool,
 	metrics *Metrics,
 	schema config.SchemaConfig,
 ) (queryrangebase.Tripperware, error) {
+	// Split by the split_metadata_queries_by_interval, its 24 hours default is more efficient with our static daily bucket storage.
+	// This is because the labels API is an index-only operation.
+	limits = WithMetadataSplitLimits(limits)
+
 	queryRangeMiddleware := []queryrangebase.Middleware{
 		StatsCollectorMiddleware(),
 		NewLimitsMiddleware(limits),
 		queryrangebase.InstrumentMiddleware("split_by_interval", metrics.InstrumentMiddlewareMetrics),
-		// Force a 24 hours split by for labels API, this will be more efficient with our static daily bucket storage.
-		// This is because the labels API is an index-only operation.
-		SplitByIntervalMiddleware(schema.Configs, WithSplitByLimits(limits, 24*time.Hour), codec, splitByTime, metrics.SplitByMetrics),
+		SplitByIntervalMiddleware(schema.Configs, limits, codec, splitByTime, metrics.SplitByMetrics),
+	}
+
+	if cfg.CacheLabelResults {
+		cacheMiddleware, err := NewLabelsCacheMiddleware(
+			log,
+			limits,
+			codec,
+			c,
+			cacheGenNumLoader,
+			func(_ context.Context, r queryrangebase.Request) bool {
+				return !r.GetCachingOptions().Disabled
+			},
+			func(ctx context.Context, tenantIDs []string, r queryrangebase.Request) int {
+				return MinWeightedParallelism(
+					ctx,
+					tenantIDs,
+					schema.Configs,
+					limits,
+					model.Time(r.GetStart()),
+					model.Time(r.GetEnd()),
+				)
+			},
+			retentionEnabled,
+			cfg.Transformer,
+			metrics.ResultsCacheMetrics,
+		)
+		if err != nil {
+			return nil, err
+		}
+		queryRangeMiddleware = append(
+			queryRangeMiddleware,
+			queryrangebase.InstrumentMiddleware("label_results_cache", metrics.InstrumentMiddlewareMetrics),
+			cacheMiddleware,
+		)
 	}
 
 	if cfg.MaxRetries > 0 {
 		queryRangeMiddleware = append(queryRangeMiddleware,
 			queryrangebase.InstrumentMiddleware("retry", metrics.InstrumentMiddlewareMetrics),
-			queryrangebase.NewRetryMiddleware(log, cfg.MaxRetries, metrics.RetryMiddlewareMetrics),
+			queryrangebase.NewRetryMiddleware(log, cfg.MaxRetries, limits, metrics.RetryMiddlewareMetrics),
 		)
 	}
 
@@ -609,6 +773,7 @@ func NewMetricTripperware(
 				)
 			},
 			retentionEnabled,
+			false,
 			metrics.ResultsCacheMetrics,
 		)
 		if err != nil {
@@ -624,16 +789,10 @@ func NewMetricTripperware(
 			NewLimitsMiddleware(limits),
 		}
 
-		if cfg.AlignQueriesWithStep {
-			queryRangeMiddleware = append(
-				queryRangeMiddleware,
-				queryrangebase.InstrumentMiddleware("step_align", metrics.InstrumentMiddlewareMetrics),
-				queryrangebase.StepAlignMiddleware,
-			)
-		}
-
 		queryRangeMiddleware = append(
 			queryRangeMiddleware,
+			queryrangebase.InstrumentMiddleware("step_align", metrics.InstrumentMiddlewareMetrics),
+			NewStepAlignMiddleware(limits, cfg.AlignQueriesWithStep),
 			NewQuerySizeLimiterMiddleware(schema.Configs, engineOpts, log, limits, statsHandler),
 			queryrangebase.InstrumentMiddleware("split_by_interval", metrics.InstrumentMiddlewareMetrics),
 			SplitByIntervalMiddleware(schema.Configs, limits, codec, splitMetricByTime, metrics.SplitByMetrics),
@@ -673,7 +832,7 @@ func NewMetricTripperware(
 			queryRangeMiddleware = append(
 				queryRangeMiddleware,
 				queryrangebase.InstrumentMiddleware("retry", metrics.InstrumentMiddlewareMetrics),
-				queryrangebase.NewRetryMiddleware(log, cfg.MaxRetries, metrics.RetryMiddlewareMetrics),
+				queryrangebase.NewRetryMiddleware(log, cfg.MaxRetries, limits, metrics.RetryMiddlewareMetrics),
 			)
 		}
 
@@ -709,11 +868,13 @@ func NewInstantMetricTripperware(
 			StatsCollectorMiddleware(),
 			NewLimitsMiddleware(limits),
 			NewQuerySizeLimiterMiddleware(schema.Configs, engineOpts, log, limits, statsHandler),
+			// Split the range selectors of the query by the split_instant_metric_queries_by_interval limit,
+			// the sub-range queries are executed in parallel and merged, like the split of range queries.
+			NewSplitByRangeMiddleware(log, engineOpts, limits, metrics.MiddlewareMapperMetrics.rangeMapper),
 		}
 
 		if cfg.ShardedQueries {
 			queryRangeMiddleware = append(queryRangeMiddleware,
-				NewSplitByRangeMiddleware(log, engineOpts, limits, metrics.MiddlewareMapperMetrics.rangeMapper),
 				NewQueryShardMiddleware(
 					log,
 					schema.Configs,
@@ -732,7 +893,7 @@ func NewInstantMetricTripperware(
 			queryRangeMiddleware = append(
 				queryRangeMiddleware,
 				queryrangebase.InstrumentMiddleware("retry", metrics.InstrumentMiddlewareMetrics),
-				queryrangebase.NewRetryMiddleware(log, cfg.MaxRetries, metrics.RetryMiddlewareMetrics),
+				queryrangebase.NewRetryMiddleware(log, cfg.MaxRetries, limits, metrics.RetryMiddlewareMetrics),
 			)
 		}
 
@@ -816,6 +977,8 @@ func NewIndexStatsTripperware(
 				middlewares,
 				queryrangebase.InstrumentMiddleware("log_results_cache", metrics.InstrumentMiddlewareMetrics),
 				cacheMiddleware,
+				queryrangebase.InstrumentMiddleware("recent_stats_cache", metrics.InstrumentMiddlewareMetrics),
+				NewRecentIndexStatsCacheMiddleware(log, limits, c),
 			)
 		}
 
@@ -823,7 +986,7 @@ func NewIndexStatsTripperware(
 			middlewares = append(
 				middlewares,
 				queryrangebase.InstrumentMiddleware("retry", metrics.InstrumentMiddlewareMetrics),
-				queryrangebase.NewRetryMiddleware(log, cfg.MaxRetries, metrics.RetryMiddlewareMetrics),
+				queryrangebase.NewRetryMiddleware(log, cfg.MaxRetries, limits, metrics.RetryMiddlewareMetrics),
 			)
 		}
 
@@ -403,8 +403,56 @@ func TestInstantQueryTripperware(t *testing.T) {
 	require.IsType(t, &LokiPromResponse{}, lokiResponse)
 }
 
+func TestInstantQueryTripperware_SplitByRange(t *testing.T) {
+	noCacheTestCfg := testConfig
+	noCacheTestCfg.CacheResults = false
+	noCacheTestCfg.CacheIndexStatsResults = false
+	var l Limits = fakeLimits{
+		maxQueryParallelism:     1,
+		tsdbMaxQueryParallelism: 1,
+		queryTimeout:            1 * time.Minute,
+		maxSeries:               10,
+		instantMetricSplits:     map[string]time.Duration{"1": 5 * time.Minute},
+	}
+	// The instant query is split even though sharding is disabled.
+	tpw, stopper, err := NewTripperware(noCacheTestCfg, testEngineOpts, util_log.Logger, l, config.SchemaConfig{Configs: testSchemasTSDB}, nil, false, nil)
+	if stopper != nil {
+		defer stopper.Stop()
+	}
+	require.NoError(t, err)
+	rt, err := newfakeRoundTripper()
+	require.NoError(t, err)
+	defer rt.Close()
+
+	lreq := &LokiInstantRequest{
+		Query:     `sum by (job) (bytes_over_time({cluster="dev-us-central-0"}[15m]))`,
+		Limit:     1000,
+		TimeTs:    testTime,
+		Direction: logproto.FORWARD,
+		Path:      "/loki/api/v1/query",
+	}
+
+	ctx := user.InjectOrgID(context.Background(), "1")
+	req, err := LokiCodec.EncodeRequest(ctx, lreq)
+	require.NoError(t, err)
+
+	req = req.WithContext(ctx)
+	err = user.InjectOrgIDIntoHTTPRequest(ctx, req)
+	require.NoError(t, err)
+
+	count, queryHandler := promqlResult(vector)
+	rt.setHandler(queryHandler)
+	resp, err := tpw(rt).RoundTrip(req)
+	require.NoError(t, err)
+	require.Equal(t, 3, *count)
+
+	lokiResponse, err := LokiCodec.DecodeResponse(ctx, resp, lreq)
+	require.NoError(t, err)
+	require.IsType(t, &LokiPromResponse{}, lokiResponse)
+}
+
 func TestSeriesTripperware(t *testing.T) {
-	tpw, stopper, err := NewTripperware(testConfig, testEngineOpts, util_log.Logger, fakeLimits{maxQueryLength: 48 * time.Hour, maxQueryParallelism: 1}, config.SchemaConfig{Configs: testSchemas}, nil, false, nil)
+	tpw, stopper, err := NewTripperware(testConfig, testEngineOpts, util_log.Logger, fakeLimits{maxQueryLength: 48 * time.Hour, maxQueryParallelism: 1, metadataSplits: map[string]time.Duration{"1": 24 * time.Hour}}, config.SchemaConfig{Configs: testSchemas}, nil, false, nil)
 	if stopper != nil {
 		defer stopper.Stop()
 	}
@@ -445,7 +493,7 @@ func TestSeriesTripperware(t *testing.T) {
 }
 
 func TestLabelsTripperware(t *testing.T) {
-	tpw, stopper, err := NewTripperware(testConfig, testEngineOpts, util_log.Logger, fakeLimits{maxQueryLength: 48 * time.Hour, maxQueryParallelism: 1}, config.SchemaConfig{Configs: testSchemas}, nil, false, nil)
+	tpw, stopper, err := NewTripperware(testConfig, testEngineOpts, util_log.Logger, fakeLimits{maxQueryLength: 48 * time.Hour, maxQueryParallelism: 1, metadataSplits: map[string]time.Duration{"1": 24 * time.Hour}}, config.SchemaConfig{Configs: testSchemas}, nil, false, nil)
 	if stopper != nil {
 		defer stopper.Stop()
 	}
@@ -681,6 +729,32 @@ func TestNewTripperware_Caches(t *testing.T) {
 			equalCaches: false,
 			err:         "",
 		},
+		{
+			name: "results cache enabled, series and label caches enabled",
+			config: Config{
+				Config: queryrangebase.Config{
+					CacheResults: true,
+					ResultsCacheConfig: queryrangebase.ResultsCacheConfig{
+						CacheConfig: cache.Config{
+							EmbeddedCache: cache.EmbeddedCacheConfig{
+								Enabled: true,
+							},
+						},
+					},
+				},
+				CacheSeriesResults: true,
+				CacheLabelResults:  true,
+			},
+			numCaches: 3,
+			err:       "",
+		},
+		{
+			name: "results cache disabled, series cache enabled (no config provided)",
+			config: Config{
+				CacheSeriesResults: true,
+			},
+			err: fmt.Sprintf("%s cache is not configured", stats.SeriesResultCache),
+		},
 		{
 			name: "results cache enabled (no config provided)",
 			config: Config{
@@ -864,6 +938,11 @@ func TestPostQueries(t *testing.T) {
 			t.Error("unexpected labelVolume roundtripper called")
 			return nil, nil
 		}),
+		queryrangebase.RoundTripFunc(func(*http.Request) (*http.Response, error) {
+			t.Error("unexpected explain roundtripper called")
+			return nil, nil
+		}),
+		queryrangebase.MergeMiddlewares(),
 		fakeLimits{},
 	).RoundTrip(req)
 	require.NoError(t, err)
@@ -956,6 +1035,55 @@ func TestTripperware_RequiredLabels(t *testing.T) {
 	}
 }
 
+func TestTripperware_BlockedQueries(t *testing.T) {
+	limits := fakeLimits{
+		maxEntriesLimitPerQuery: 5000,
+		maxQueryParallelism:     1,
+		blockedQueries: []*validation.BlockedQuery{
+			{Pattern: `.*buzz.*`, Regex: true, Types: []string{logql.QueryTypeMetric}},
+		},
+	}
+	tpw, stopper, err := NewTripperware(testConfig, testEngineOpts, util_log.Logger, limits, config.SchemaConfig{Configs: testSchemas}, nil, false, nil)
+	if stopper != nil {
+		defer stopper.Stop()
+	}
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		req      queryrangebase.Request
+		response parser.Value
+		blocked  bool
+	}{
+		{&LokiRequest{Query: `rate({app="buzz"}[1m])`, Limit: 1000, StartTs: testTime.Add(-6 * time.Hour), EndTs: testTime, Path: "/loki/api/v1/query_range"}, matrix, true},
+		{&LokiInstantRequest{Query: `rate({app="buzz"}[1m])`, Limit: 1000, TimeTs: testTime, Path: "/loki/api/v1/query"}, vector, true},
+		{&LokiRequest{Query: `{app="buzz"}`, Limit: 1000, StartTs: testTime.Add(-6 * time.Hour), EndTs: testTime, Path: "/loki/api/v1/query_range"}, streams, false},
+		{&LokiRequest{Query: `rate({app="foo"}[1m])`, Limit: 1000, StartTs: testTime.Add(-6 * time.Hour), EndTs: testTime, Path: "/loki/api/v1/query_range"}, matrix, false},
+	} {
+		t.Run(tc.req.GetQuery(), func(t *testing.T) {
+			rt, err := newfakeRoundTripper()
+			require.NoError(t, err)
+			defer rt.Close()
+			count, h := promqlResult(tc.response)
+			rt.setHandler(h)
+
+			ctx := user.InjectOrgID(context.Background(), "1")
+			req, err := LokiCodec.EncodeRequest(ctx, tc.req)
+			require.NoError(t, err)
+			req = req.WithContext(ctx)
+			require.NoError(t, user.InjectOrgIDIntoHTTPRequest(ctx, req))
+
+			_, err = tpw(rt).RoundTrip(req)
+			if !tc.blocked {
+				require.NoError(t, err)
+				return
+			}
+			// Blocked queries are rejected by the frontend, without being sent to queriers.
+			require.Equal(t, httpgrpc.Errorf(http.StatusBadRequest, logqlmodel.ErrBlocked.Error()), err)
+			require.Equal(t, 0, *count)
+		})
+	}
+}
+
 func TestTripperware_RequiredNumberLabels(t *testing.T) {
 
 	const noErr = ""
@@ -1154,6 +1282,18 @@ type fakeLimits struct {
 	maxQueryBytesRead       int
 	maxQuerierBytesRead     int
 	maxStatsCacheFreshness  time.Duration
+	recentStatsCacheTTL     time.Duration
+
+	maxMetadataCacheFreshness time.Duration
+	metadataCacheTTL          time.Duration
+
+	instantMetricSplits map[string]time.Duration
+	metadataSplits      map[string]time.Duration
+
+	blockedQueries []*validation.BlockedQuery
+
+	alignQueriesWithStep   bool
+	quantileSketchSharding bool
 }
 
 func (f fakeLimits) QuerySplitDuration(key string) time.Duration {
@@ -1163,6 +1303,20 @@ func (f fakeLimits) QuerySplitDuration(key string) time.Duration {
 	return f.splits[key]
 }
 
+func (f fakeLimits) InstantMetricQuerySplitDuration(key string) time.Duration {
+	if f.instantMetricSplits == nil {
+		return 0
+	}
+	return f.instantMetricSplits[key]
+}
+
+func (f fakeLimits) MetadataQuerySplitDuration(key string) time.Duration {
+	if f.metadataSplits == nil {
+		return 0
+	}
+	return f.metadataSplits[key]
+}
+
 func (f fakeLimits) MaxQueryLength(context.Context, string) time.Duration {
 	if f.maxQueryLength == 0 {
 		return time.Hour * 7
@@ -1215,7 +1369,7 @@ func (f fakeLimits) QueryTimeout(context.Context, string) time.Duration {
 }
 
 func (f fakeLimits) BlockedQueries(context.Context, string) []*validation.BlockedQuery {
-	return []*validation.BlockedQuery{}
+	return f.blockedQueries
 }
 
 func (f fakeLimits) RequiredLabels(context.Context, string) []string {
@@ -1230,6 +1384,42 @@ func (f fakeLimits) MaxStatsCacheFreshness(_ context.Context, _ string) time.Dur
 	return f.maxStatsCacheFreshness
 }
 
+func (f fakeLimits) RecentStatsCacheTTL(_ context.Context, _ string) time.Duration {
+	return f.recentStatsCacheTTL
+}
+
+func (f fakeLimits) AlignQueriesWithStep(context.Context, string) bool {
+	return f.alignQueriesWithStep
+}
+
+func (f fakeLimits) QuantileSketchSharding(context.Context, string) bool {
+	return f.quantileSketchSharding
+}
+
+func (f fakeLimits) QueryMaxRetries(context.Context, string) int {
+	return 0
+}
+
+func (f fakeLimits) QueryRetryMinBackoff(context.Context, string) time.Duration {
+	return 0
+}
+
+func (f fakeLimits) QueryRetryMaxBackoff(context.Context, string) time.Duration {
+	return 0
+}
+
+func (f fakeLimits) QueryRetryStatusCodes(context.Context, string) []int {
+	return nil
+}
+
+func (f fakeLimits) MaxMetadataCacheFreshness(_ context.Context, _ string) time.Duration {
+	return f.maxMetadataCacheFreshness
+}
+
+func (f fakeLimits) MetadataCacheTTL(_ context.Context, _ string) time.Duration {
+	return f.metadataCacheTTL
+}
+
 func counter() (*int, http.Handler) {
 	count := 0
 	var lock sync.Mutex
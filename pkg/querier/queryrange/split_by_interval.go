@@ -320,13 +320,28 @@ func maxRangeVectorAndOffsetDuration(q string) (time.Duration, time.Duration, er
 
 	var maxRVDuration, maxOffset time.Duration
 	expr.Walk(func(e interface{}) {
-		if r, ok := e.(*syntax.LogRange); ok {
+		switch r := e.(type) {
+		case *syntax.LogRange:
 			if r.Interval > maxRVDuration {
 				maxRVDuration = r.Interval
 			}
 			if r.Offset > maxOffset {
 				maxOffset = r.Offset
 			}
+		case *syntax.SubqueryExpr:
+			// the subqueries select the samples of their inner ranges within their own range.
+			groups, err := r.MatcherGroups()
+			if err != nil {
+				return
+			}
+			for _, g := range groups {
+				if g.Interval > maxRVDuration {
+					maxRVDuration = g.Interval
+				}
+				if g.Offset > maxOffset {
+					maxOffset = g.Offset
+				}
+			}
 		}
 	})
 	return maxRVDuration, maxOffset, nil
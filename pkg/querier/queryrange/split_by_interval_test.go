@@ -908,6 +908,26 @@ func Test_seriesvolume_splitByInterval_Do(t *testing.T) {
 	}
 }
 
+func Test_maxRangeVectorAndOffsetDuration(t *testing.T) {
+	for _, tc := range []struct {
+		query          string
+		expectedRange  time.Duration
+		expectedOffset time.Duration
+	}{
+		{`{app="foo"}`, 0, 0},
+		{`rate({app="foo"}[5m] offset 1h)`, 5 * time.Minute, time.Hour},
+		{`max_over_time(rate({app="foo"}[5m])[1h:1m])`, time.Hour + 5*time.Minute, 0},
+		{`max_over_time(rate({app="foo"}[5m] offset 1m)[1h:1m] offset 1h) / rate({app="foo"}[2h])`, 2 * time.Hour, time.Hour + time.Minute},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			maxRange, maxOffset, err := maxRangeVectorAndOffsetDuration(tc.query)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedRange, maxRange)
+			require.Equal(t, tc.expectedOffset, maxOffset)
+		})
+	}
+}
+
 func Test_ExitEarly(t *testing.T) {
 	ctx := user.InjectOrgID(context.Background(), "1")
 
@@ -51,13 +51,13 @@ func (s *splitByRange) Do(ctx context.Context, request queryrangebase.Request) (
 		return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
 	}
 
-	interval := validation.SmallestPositiveNonZeroDurationPerTenant(tenants, s.limits.QuerySplitDuration)
+	interval := validation.SmallestPositiveNonZeroDurationPerTenant(tenants, instantMetricQuerySplitDuration(s.limits))
 	// if no interval configured, continue to the next middleware
 	if interval == 0 {
 		return s.next.Do(ctx, request)
 	}
 
-	mapper, err := logql.NewRangeMapper(interval, s.metrics)
+	mapper, err := logql.NewRangeMapper(interval, s.metrics, quantileSketchSharding(ctx, tenants, s.limits))
 	if err != nil {
 		return nil, err
 	}
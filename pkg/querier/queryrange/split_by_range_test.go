@@ -20,7 +20,7 @@ func Test_RangeVectorSplit(t *testing.T) {
 	srm := NewSplitByRangeMiddleware(log.NewNopLogger(), testEngineOpts, fakeLimits{
 		maxSeries:    10000,
 		queryTimeout: time.Second,
-		splits: map[string]time.Duration{
+		instantMetricSplits: map[string]time.Duration{
 			"tenant": time.Minute,
 		},
 	}, nilShardingMetrics)
@@ -12,6 +12,7 @@ import (
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/tenant"
 	promql_parser "github.com/prometheus/prometheus/promql/parser"
 	"github.com/weaveworks/common/middleware"
 
@@ -19,6 +20,8 @@ import (
 	"github.com/grafana/loki/pkg/logqlmodel"
 	"github.com/grafana/loki/pkg/logqlmodel/stats"
 	"github.com/grafana/loki/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/pkg/util/audit"
+	"github.com/grafana/loki/pkg/util/httpreq"
 	util_log "github.com/grafana/loki/pkg/util/log"
 	"github.com/grafana/loki/pkg/util/spanlogger"
 )
@@ -42,6 +45,46 @@ var (
 	StatsHTTPMiddleware middleware.Interface = statsHTTPMiddleware(defaultMetricRecorder)
 )
 
+// NewStatsHTTPMiddleware returns the StatsHTTPMiddleware, also logging the audit entries of the queries
+// with the given audit logger.
+func NewStatsHTTPMiddleware(auditLogger *audit.Logger) middleware.Interface {
+	return statsHTTPMiddleware(metricRecorderFn(func(data *queryData) {
+		recordQueryMetrics(data)
+		recordQueryAudit(auditLogger, data)
+	}))
+}
+
+// recordQueryAudit logs the audit entry of a query executed by the query frontend.
+func recordQueryAudit(auditLogger *audit.Logger, data *queryData) {
+	if auditLogger == nil || data.params == nil {
+		return
+	}
+
+	tenantIDs, err := tenant.TenantIDs(data.ctx)
+	if err != nil {
+		level.Warn(util_log.Logger).Log("msg", "failed to log query audit entry", "err", err)
+		return
+	}
+
+	source := audit.SourceFrontend
+	if strings.HasPrefix(data.userAgent, httpreq.RulerUserAgentPrefix) {
+		source = audit.SourceRuler
+	}
+
+	auditLogger.Log(audit.Entry{
+		Tenant:   tenant.JoinTenantIDs(tenantIDs),
+		Query:    data.params.Query(),
+		Start:    data.params.Start(),
+		End:      data.params.End(),
+		Step:     data.params.Step(),
+		Source:   source,
+		Duration: stats.ConvertSecondsToNanoseconds(data.statistics.Summary.ExecTime),
+		Bytes:    data.statistics.Summary.TotalBytesProcessed,
+		Chunks:   data.statistics.TotalChunksDownloaded(),
+		Status:   data.status,
+	})
+}
+
 // recordQueryMetrics will be called from Query Frontend middleware chain for any type of query.
 func recordQueryMetrics(data *queryData) {
 	logger := log.With(util_log.Logger, "component", "frontend")
@@ -77,6 +120,7 @@ type queryData struct {
 	queryType  string
 	match      []string // used in `series` query.
 	label      string   // used in `labels` query
+	userAgent  string
 
 	recorded bool
 }
@@ -97,6 +141,7 @@ func statsHTTPMiddleware(recorder metricRecorder) middleware.Interface {
 				}
 				data.ctx = r.Context()
 				data.status = strconv.Itoa(interceptor.statusCode)
+				data.userAgent = r.UserAgent()
 				recorder.Record(data)
 			}
 		})
@@ -2,19 +2,25 @@ package queryrange
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	strings "strings"
 	"testing"
 	"time"
 
+	"github.com/go-kit/log"
 	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
 
 	"github.com/grafana/loki/pkg/logproto"
 	"github.com/grafana/loki/pkg/logqlmodel/stats"
 	"github.com/grafana/loki/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/pkg/util/audit"
 )
 
 func TestStatsCollectorMiddleware(t *testing.T) {
@@ -167,6 +173,45 @@ func Test_StatsHTTP(t *testing.T) {
 	}
 }
 
+func Test_StatsHTTP_QueryAudit(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "audit.log")
+	auditLogger, err := audit.New(audit.Config{Enabled: true, File: file}, log.NewNopLogger())
+	require.NoError(t, err)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data := r.Context().Value(ctxKey).(*queryData)
+		data.recorded = true
+		data.params, _ = paramsFromRequest(&LokiRequest{
+			Query:   `{app="foo"}`,
+			StartTs: start,
+			EndTs:   end,
+		})
+		data.statistics = &statsResult
+	})
+	for _, userAgent := range []string{"Grafana/10.0.0", "loki-ruler/2.8.0"} {
+		req := httptest.NewRequest("GET", "/loki/api/v1/query_range", strings.NewReader(""))
+		req.Header.Set("User-Agent", userAgent)
+		req = req.WithContext(user.InjectOrgID(req.Context(), "tenant-a"))
+		NewStatsHTTPMiddleware(auditLogger).Wrap(next).ServeHTTP(httptest.NewRecorder(), req)
+	}
+	require.NoError(t, auditLogger.Close())
+
+	buf, err := os.ReadFile(file)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(buf)), "\n")
+	require.Len(t, lines, 2)
+	for i, source := range []string{audit.SourceFrontend, audit.SourceRuler} {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(lines[i]), &entry))
+		require.Equal(t, "tenant-a", entry["tenant"])
+		require.Equal(t, `{app="foo"}`, entry["query"])
+		require.Equal(t, start.UTC().Format(time.RFC3339Nano), entry["start"])
+		require.Equal(t, source, entry["source"])
+		require.Equal(t, float64(statsResult.Summary.TotalBytesProcessed), entry["bytes"])
+		require.Equal(t, "200", entry["status"])
+	}
+}
+
 func Test_StatsUpdateResult(t *testing.T) {
 	resp, err := StatsCollectorMiddleware().Wrap(queryrangebase.HandlerFunc(func(c context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
 		time.Sleep(20 * time.Millisecond)
@@ -0,0 +1,72 @@
+package queryrange
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grafana/dskit/tenant"
+	"github.com/weaveworks/common/httpgrpc"
+
+	"github.com/grafana/loki/pkg/querier/queryrange/queryrangebase"
+)
+
+// StepAlignedHeader is the header of the responses to the queries whose start
+// and end were aligned with their step.
+const StepAlignedHeader = "X-Loki-Query-Step-Aligned"
+
+// NewStepAlignMiddleware returns a middleware aligning the start and end of the
+// queries with their step, to improve the cacheability of their results. The
+// queries of all tenants are aligned if alignAll is true, or only the queries
+// of the tenants which enabled it otherwise.
+func NewStepAlignMiddleware(limits Limits, alignAll bool) queryrangebase.Middleware {
+	return queryrangebase.MiddlewareFunc(func(next queryrangebase.Handler) queryrangebase.Handler {
+		return stepAlign{
+			next:     next,
+			limits:   limits,
+			alignAll: alignAll,
+		}
+	})
+}
+
+type stepAlign struct {
+	next     queryrangebase.Handler
+	limits   Limits
+	alignAll bool
+}
+
+func (s stepAlign) Do(ctx context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+	step := r.GetStep()
+	if step <= 0 {
+		return s.next.Do(ctx, r)
+	}
+
+	if !s.alignAll {
+		tenantIDs, err := tenant.TenantIDs(ctx)
+		if err != nil {
+			return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+		}
+		for _, id := range tenantIDs {
+			if !s.limits.AlignQueriesWithStep(ctx, id) {
+				return s.next.Do(ctx, r)
+			}
+		}
+	}
+
+	start := (r.GetStart() / step) * step
+	end := (r.GetEnd() / step) * step
+	if start == r.GetStart() && end == r.GetEnd() {
+		return s.next.Do(ctx, r)
+	}
+
+	resp, err := s.next.Do(ctx, r.WithStartEnd(start, end))
+	if err != nil {
+		return nil, err
+	}
+	if promResp, ok := resp.(*LokiPromResponse); ok && promResp.Response != nil {
+		promResp.Response.Headers = append(promResp.Response.Headers, &queryrangebase.PrometheusResponseHeader{
+			Name:   StepAlignedHeader,
+			Values: []string{"true"},
+		})
+	}
+	return resp, nil
+}
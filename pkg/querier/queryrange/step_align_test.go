@@ -0,0 +1,92 @@
+package queryrange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/loki/pkg/loghttp"
+	"github.com/grafana/loki/pkg/querier/queryrange/queryrangebase"
+)
+
+func TestStepAlignMiddleware(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "1")
+	start := time.Unix(0, 0).Add(90 * time.Second)
+	end := time.Unix(0, 0).Add(10*time.Minute + 30*time.Second)
+	step := time.Minute
+
+	for _, tc := range []struct {
+		name       string
+		alignAll   bool
+		limits     fakeLimits
+		start, end time.Time
+		expStart   time.Time
+		expEnd     time.Time
+		expHeader  []string
+	}{
+		{
+			name:     "disabled",
+			start:    start,
+			end:      end,
+			expStart: start,
+			expEnd:   end,
+		},
+		{
+			name:      "enabled for all tenants",
+			alignAll:  true,
+			start:     start,
+			end:       end,
+			expStart:  time.Unix(0, 0).Add(time.Minute),
+			expEnd:    time.Unix(0, 0).Add(10 * time.Minute),
+			expHeader: []string{"true"},
+		},
+		{
+			name:      "enabled for the tenant",
+			limits:    fakeLimits{alignQueriesWithStep: true},
+			start:     start,
+			end:       end,
+			expStart:  time.Unix(0, 0).Add(time.Minute),
+			expEnd:    time.Unix(0, 0).Add(10 * time.Minute),
+			expHeader: []string{"true"},
+		},
+		{
+			name:     "already aligned",
+			alignAll: true,
+			start:    time.Unix(0, 0).Add(time.Minute),
+			end:      time.Unix(0, 0).Add(10 * time.Minute),
+			expStart: time.Unix(0, 0).Add(time.Minute),
+			expEnd:   time.Unix(0, 0).Add(10 * time.Minute),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := &LokiRequest{
+				Query:   `rate({app="foo"}[1m])`,
+				StartTs: tc.start,
+				EndTs:   tc.end,
+				Step:    step.Milliseconds(),
+				Path:    "/loki/api/v1/query_range",
+			}
+
+			var got queryrangebase.Request
+			next := queryrangebase.HandlerFunc(func(_ context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+				got = r
+				return &LokiPromResponse{Response: &queryrangebase.PrometheusResponse{
+					Status: loghttp.QueryStatusSuccess,
+					Data:   queryrangebase.PrometheusData{ResultType: loghttp.ResultTypeMatrix},
+				}}, nil
+			})
+
+			resp, err := NewStepAlignMiddleware(tc.limits, tc.alignAll).Wrap(next).Do(ctx, req)
+			require.NoError(t, err)
+			require.Equal(t, tc.expStart.UnixMilli(), got.GetStart())
+			require.Equal(t, tc.expEnd.UnixMilli(), got.GetEnd())
+
+			httpResp, err := LokiCodec.EncodeResponse(ctx, resp)
+			require.NoError(t, err)
+			require.Equal(t, tc.expHeader, httpResp.Header.Values(StepAlignedHeader))
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package queryrange
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/tenant"
+	"github.com/weaveworks/common/httpgrpc"
+
+	"github.com/grafana/loki/pkg/loghttp"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/pkg/util/marshal"
+	"github.com/grafana/loki/pkg/util/validation"
+)
+
+// acceptsStreaming returns whether the client of the request accepts a streaming response.
+func acceptsStreaming(req *http.Request) bool {
+	for _, accept := range req.Header.Values("Accept") {
+		for _, value := range strings.Split(accept, ",") {
+			mediaType, _, _ := strings.Cut(value, ";")
+			if strings.TrimSpace(mediaType) == loghttp.StreamingContentType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// streamLogQuery executes the log range query by splits of the split interval of its tenants,
+// one after the other in the direction of the query, and streams the streams of each split to
+// the client as JSON lines as soon as next returns them. The response of the query is thus never
+// buffered in the frontend, and the splits are not executed once the limit of the query is reached.
+// The whole query is validated by validate before being split, so the limits on the length
+// and the size of the query apply to the query and not to its splits.
+// An error after the response has started is sent as a last line with the status "fail".
+func streamLogQuery(req *http.Request, next http.RoundTripper, validate queryrangebase.Middleware, limits Limits, logger log.Logger) (*http.Response, error) {
+	ctx := req.Context()
+	tenantIDs, err := tenant.TenantIDs(ctx)
+	if err != nil {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+	}
+
+	decoded, err := LokiCodec.DecodeRequest(ctx, req, nil)
+	if err != nil {
+		return nil, err
+	}
+	lokiReq := decoded.(*LokiRequest)
+
+	// The validation can change the time range of the query, or skip a query outside the lookback of its tenants.
+	var validated queryrangebase.Request
+	_, err = validate.Wrap(queryrangebase.HandlerFunc(func(_ context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+		validated = r
+		return NewEmptyResponse(r)
+	})).Do(ctx, lokiReq)
+	if err != nil {
+		return nil, err
+	}
+
+	var splits []queryrangebase.Request
+	if validated != nil {
+		lokiReq = validated.(*LokiRequest)
+		splits = []queryrangebase.Request{lokiReq}
+		if interval := validation.MaxDurationOrZeroPerTenant(tenantIDs, limits.QuerySplitDuration); interval > 0 {
+			if splits, err = splitByTime(lokiReq, interval); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if lokiReq.Direction == logproto.BACKWARD {
+		for i, j := 0, len(splits)-1; i < j; i, j = i+1, j-1 {
+			splits[i], splits[j] = splits[j], splits[i]
+		}
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		// The request context is canceled when the client goes away, which
+		// unblocks the writes the client doesn't read anymore.
+		<-ctx.Done()
+		pr.CloseWithError(ctx.Err())
+	}()
+	go func() {
+		err := writeStreamedSplits(ctx, pw, queryrangebase.NewRoundTripperHandler(next, LokiCodec), splits, lokiReq.Limit)
+		if err != nil {
+			level.Warn(logger).Log("msg", "failed to stream log query", "err", err)
+			_ = writeStreamedLine(pw, struct {
+				Status string `json:"status"`
+				Error  string `json:"error"`
+			}{
+				Status: loghttp.QueryStatusFail,
+				Error:  err.Error(),
+			})
+		}
+		_ = pw.Close()
+	}()
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{loghttp.StreamingContentType}},
+		Body:       pr,
+	}, nil
+}
+
+func writeStreamedSplits(ctx context.Context, w io.Writer, handler queryrangebase.Handler, splits []queryrangebase.Request, limit uint32) error {
+	remaining := limit
+	for _, split := range splits {
+		if remaining == 0 {
+			return nil
+		}
+		splitReq := *split.(*LokiRequest)
+		splitReq.Limit = remaining
+		resp, err := handler.Do(ctx, &splitReq)
+		if err != nil {
+			return err
+		}
+		lokiResp, ok := resp.(*LokiResponse)
+		if !ok {
+			return httpgrpc.Errorf(http.StatusInternalServerError, "unexpected response type %T", resp)
+		}
+
+		for _, s := range lokiResp.Data.Result {
+			if remaining == 0 {
+				return nil
+			}
+			if uint32(len(s.Entries)) > remaining {
+				s.Entries = s.Entries[:remaining]
+			}
+			remaining -= uint32(len(s.Entries))
+
+			stream, err := marshal.NewStream(s)
+			if err != nil {
+				return err
+			}
+			if err := writeStreamedLine(w, stream); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func writeStreamedLine(w io.Writer, v interface{}) error {
+	b, err := jsonStd.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
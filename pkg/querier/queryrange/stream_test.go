@@ -0,0 +1,159 @@
+package queryrange
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/httpgrpc"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/loki/pkg/loghttp"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/pkg/storage/config"
+	util_log "github.com/grafana/loki/pkg/util/log"
+)
+
+func TestStreamLogQuery(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		direction logproto.Direction
+		limit     int
+		expLines  []string
+	}{
+		{
+			direction: logproto.FORWARD,
+			limit:     100,
+			expLines:  []string{"00:00", "01:00", "02:00"},
+		},
+		{
+			direction: logproto.BACKWARD,
+			limit:     100,
+			expLines:  []string{"02:00", "01:00", "00:00"},
+		},
+		{
+			direction: logproto.BACKWARD,
+			limit:     2,
+			expLines:  []string{"02:00", "01:00"},
+		},
+	} {
+		t.Run(fmt.Sprintf("%s limit %d", tc.direction, tc.limit), func(t *testing.T) {
+			var calls int
+			next := queryrangebase.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+				calls++
+				decoded, err := LokiCodec.DecodeRequest(req.Context(), req, nil)
+				require.NoError(t, err)
+				lokiReq := decoded.(*LokiRequest)
+
+				// Each split returns one entry at its start.
+				return LokiCodec.EncodeResponse(req.Context(), &LokiResponse{
+					Status:    loghttp.QueryStatusSuccess,
+					Direction: lokiReq.Direction,
+					Limit:     lokiReq.Limit,
+					Version:   uint32(loghttp.VersionV1),
+					Data: LokiData{
+						ResultType: loghttp.ResultTypeStream,
+						Result: []logproto.Stream{
+							{
+								Labels:  `{app="foo"}`,
+								Entries: []logproto.Entry{{Timestamp: lokiReq.StartTs, Line: lokiReq.StartTs.Format("15:04")}},
+							},
+						},
+					},
+				})
+			})
+
+			params := url.Values{
+				"query":     []string{`{app="foo"}`},
+				"start":     []string{fmt.Sprint(start.UnixNano())},
+				"end":       []string{fmt.Sprint(start.Add(3 * time.Hour).UnixNano())},
+				"limit":     []string{fmt.Sprint(tc.limit)},
+				"direction": []string{tc.direction.String()},
+			}
+			req := httptest.NewRequest(http.MethodGet, "/loki/api/v1/query_range?"+params.Encode(), nil)
+			req.Header.Set("Accept", loghttp.StreamingContentType)
+			require.True(t, acceptsStreaming(req))
+			ctx, cancel := context.WithCancel(user.InjectOrgID(context.Background(), "1"))
+			defer cancel()
+			req = req.WithContext(ctx)
+
+			limits := fakeLimits{splits: map[string]time.Duration{"1": time.Hour}}
+			resp, err := streamLogQuery(req, next, NewLimitsMiddleware(limits), limits, log.NewNopLogger())
+			require.NoError(t, err)
+			require.Equal(t, loghttp.StreamingContentType, resp.Header.Get("Content-Type"))
+
+			var lines []string
+			scanner := bufio.NewScanner(resp.Body)
+			for scanner.Scan() {
+				var stream loghttp.Stream
+				require.NoError(t, stream.UnmarshalJSON(scanner.Bytes()))
+				require.Equal(t, loghttp.LabelSet{"app": "foo"}, stream.Labels)
+				for _, e := range stream.Entries {
+					lines = append(lines, e.Line)
+				}
+			}
+			require.NoError(t, scanner.Err())
+			require.Equal(t, tc.expLines, lines)
+			require.Equal(t, len(tc.expLines), calls)
+		})
+	}
+}
+
+func TestStreamLogQueryError(t *testing.T) {
+	next := queryrangebase.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, fmt.Errorf("querier unavailable")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, `/loki/api/v1/query_range?query={app="foo"}&start=0&end=3600000000000`, nil)
+	ctx, cancel := context.WithCancel(user.InjectOrgID(context.Background(), "1"))
+	defer cancel()
+	req = req.WithContext(ctx)
+
+	resp, err := streamLogQuery(req, next, NewLimitsMiddleware(fakeLimits{}), fakeLimits{}, log.NewNopLogger())
+	require.NoError(t, err)
+
+	scanner := bufio.NewScanner(resp.Body)
+	require.True(t, scanner.Scan())
+	require.JSONEq(t, `{"status":"fail","error":"querier unavailable"}`, scanner.Text())
+	require.False(t, scanner.Scan())
+}
+
+func TestStreamLogQueryTooLong(t *testing.T) {
+	tpw, stopper, err := NewTripperware(testConfig, testEngineOpts, util_log.Logger, fakeLimits{
+		maxQueryLength:      time.Hour,
+		maxQueryParallelism: 1,
+		splits:              map[string]time.Duration{"1": time.Hour},
+	}, config.SchemaConfig{Configs: testSchemas}, nil, false, nil)
+	if stopper != nil {
+		defer stopper.Stop()
+	}
+	require.NoError(t, err)
+
+	next := queryrangebase.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		t.Error("unexpected query of a split")
+		return nil, nil
+	})
+
+	// Each split of the query is shorter than the max query length, but the query is not.
+	req := httptest.NewRequest(http.MethodGet, `/loki/api/v1/query_range?query={app="foo"}&start=0&end=10800000000000`, nil)
+	req.Header.Set("Accept", loghttp.StreamingContentType)
+	ctx := user.InjectOrgID(context.Background(), "1")
+	req = req.WithContext(ctx)
+	require.NoError(t, user.InjectOrgIDIntoHTTPRequest(ctx, req))
+
+	_, err = tpw(next).RoundTrip(req)
+	require.Error(t, err)
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusBadRequest), resp.Code)
+	require.Contains(t, string(resp.Body), "the query time range exceeds the limit")
+}
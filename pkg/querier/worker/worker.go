@@ -52,6 +52,9 @@ func (cfg *Config) Validate(log log.Logger) error {
 	if cfg.FrontendAddress != "" && cfg.SchedulerAddress != "" {
 		return errors.New("frontend address and scheduler address are mutually exclusive, please use only one")
 	}
+	if err := util.ValidateGRPCClientTLS("querier.frontend-client", cfg.GRPCClientConfig); err != nil {
+		return err
+	}
 	return cfg.GRPCClientConfig.Validate(log)
 }
 
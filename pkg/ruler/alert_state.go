@@ -0,0 +1,86 @@
+package ruler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/rules"
+
+	"github.com/grafana/loki/pkg/util"
+)
+
+// alertState is the state of the active alerts of a tenant, persisted when the rules manager of the tenant stops,
+// so the "for" state of the alerts is restored when the rules of the tenant are loaded again, e.g. after a restart.
+type alertState struct {
+	// Timestamp is the time the state was persisted at, in milliseconds.
+	Timestamp int64            `json:"timestamp"`
+	Alerts    []persistedAlert `json:"alerts"`
+}
+
+// persistedAlert is the ALERTS_FOR_STATE sample of an active alert.
+type persistedAlert struct {
+	Labels labels.Labels `json:"labels"`
+	// ActiveAt is the time the alert became active at, in seconds.
+	ActiveAt int64 `json:"active_at"`
+}
+
+func alertStatePath(dir, userID string) string {
+	return filepath.Join(dir, userID+".json")
+}
+
+// saveAlertState persists the state of the active alerts of the rule groups of the tenant.
+func saveAlertState(dir, userID string, groups []*rules.Group, now time.Time) error {
+	state := alertState{Timestamp: util.TimeToMillis(now)}
+	for _, g := range groups {
+		for _, r := range g.Rules() {
+			rule, ok := r.(*rules.AlertingRule)
+			if !ok {
+				continue
+			}
+			for _, a := range rule.ActiveAlerts() {
+				state.Alerts = append(state.Alerts, persistedAlert{
+					Labels:   ForStateMetric(a.Labels, rule.Name()),
+					ActiveAt: a.ActiveAt.Unix(),
+				})
+			}
+		}
+	}
+
+	path := alertStatePath(dir, userID)
+	if len(state.Alerts) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+	// the state is renamed once written, so a partial state is never loaded.
+	if err := os.WriteFile(path+".tmp", b, 0o640); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// loadAlertState loads the persisted state of the active alerts of the tenant, if any.
+func loadAlertState(dir, userID string) (alertState, error) {
+	var state alertState
+	b, err := os.ReadFile(alertStatePath(dir, userID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return state, err
+	}
+	err = json.Unmarshal(b, &state)
+	return state, err
+}
@@ -2,6 +2,7 @@ package base
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
@@ -17,6 +18,7 @@ import (
 	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/rulefmt"
+	promRules "github.com/prometheus/prometheus/rules"
 	"github.com/weaveworks/common/user"
 	"gopkg.in/yaml.v3"
 
@@ -69,6 +71,22 @@ type RuleGroup struct {
 	Interval       float64   `json:"interval"`
 	LastEvaluation time.Time `json:"lastEvaluation"`
 	EvaluationTime float64   `json:"evaluationTime"`
+	Health         string    `json:"health"`
+	LastError      string    `json:"lastError"`
+}
+
+// RuleGroupHealth has the health of the last evaluation of a rule group
+type RuleGroupHealth struct {
+	Name           string    `json:"name"`
+	File           string    `json:"file"`
+	Health         string    `json:"health"`
+	LastError      string    `json:"lastError"`
+	LastEvaluation time.Time `json:"lastEvaluation"`
+	EvaluationTime float64   `json:"evaluationTime"`
+	Rules          int       `json:"rules"`
+	FailingRules   int       `json:"failingRules"`
+	ActiveAlerts   int       `json:"activeAlerts"`
+	Series         int64     `json:"series"`
 }
 
 type rule interface{}
@@ -165,6 +183,7 @@ func (a *API) PrometheusRules(w http.ResponseWriter, req *http.Request) {
 			LastEvaluation: g.GetEvaluationTimestamp(),
 			EvaluationTime: g.GetEvaluationDuration().Seconds(),
 		}
+		grp.Health, grp.LastError = groupHealth(g)
 
 		for i, rl := range g.ActiveRules {
 			if g.ActiveRules[i].Rule.Alert != "" {
@@ -229,6 +248,85 @@ func (a *API) PrometheusRules(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// groupHealth returns the health of the last evaluation of a rule group: "err" when the evaluation of one of its rules
+// failed, "unknown" when one of its rules wasn't evaluated yet and "ok" otherwise. The last error of the group is the
+// last error of its first failing rule.
+func groupHealth(g *GroupStateDesc) (string, string) {
+	health := string(promRules.HealthGood)
+	for _, rl := range g.ActiveRules {
+		switch rl.GetHealth() {
+		case string(promRules.HealthBad):
+			name := rl.Rule.GetAlert()
+			if name == "" {
+				name = rl.Rule.GetRecord()
+			}
+			return rl.GetHealth(), fmt.Sprintf("%s: %s", name, rl.GetLastError())
+		case string(promRules.HealthUnknown):
+			health = rl.GetHealth()
+		}
+	}
+	return health, ""
+}
+
+// RuleGroupsHealth returns the health of the last evaluation of the rule groups of the tenant, so the rule groups
+// failing persistently can be told apart from the rule groups without results.
+func (a *API) RuleGroupsHealth(w http.ResponseWriter, req *http.Request) {
+	logger := util_log.WithContext(req.Context(), a.logger)
+	userID, err := tenant.TenantID(req.Context())
+	if err != nil || userID == "" {
+		level.Error(logger).Log("msg", "error extracting org id from context", "err", err)
+		respondError(logger, w, "no valid org id found")
+		return
+	}
+
+	rgs, err := a.ruler.GetRules(req.Context())
+	if err != nil {
+		respondError(logger, w, err.Error())
+		return
+	}
+
+	groups := make([]*RuleGroupHealth, 0, len(rgs))
+	for _, g := range rgs {
+		grp := &RuleGroupHealth{
+			Name:           g.Group.Name,
+			File:           g.Group.Namespace,
+			LastEvaluation: g.GetEvaluationTimestamp(),
+			EvaluationTime: g.GetEvaluationDuration().Seconds(),
+			Rules:          len(g.ActiveRules),
+			Series:         g.GetSeries(),
+		}
+		grp.Health, grp.LastError = groupHealth(g)
+		for _, rl := range g.ActiveRules {
+			if rl.GetHealth() == string(promRules.HealthBad) {
+				grp.FailingRules++
+			}
+			grp.ActiveAlerts += len(rl.Alerts)
+		}
+		groups = append(groups, grp)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].File != groups[j].File {
+			return groups[i].File < groups[j].File
+		}
+		return groups[i].Name < groups[j].Name
+	})
+
+	b, err := json.Marshal(&response{
+		Status: "success",
+		Data:   groups,
+	})
+	if err != nil {
+		level.Error(logger).Log("msg", "error marshaling json response", "err", err)
+		respondError(logger, w, "unable to marshal the requested data")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if n, err := w.Write(b); err != nil {
+		level.Error(logger).Log("msg", "error writing response", "bytesWritten", n, "err", err)
+	}
+}
+
 func (a *API) PrometheusAlerts(w http.ResponseWriter, req *http.Request) {
 	logger := util_log.WithContext(req.Context(), a.logger)
 	userID, err := tenant.TenantID(req.Context())
@@ -418,6 +516,54 @@ func (a *API) ListRules(w http.ResponseWriter, req *http.Request) {
 	marshalAndSend(formatted, w, logger)
 }
 
+// RuleGroupOwners returns the ruler instances evaluating the rule groups of the tenant.
+func (a *API) RuleGroupOwners(w http.ResponseWriter, req *http.Request) {
+	logger := util_log.WithContext(req.Context(), a.logger)
+	userID, err := tenant.TenantID(req.Context())
+	if err != nil || userID == "" {
+		level.Error(logger).Log("msg", "error extracting org id from context", "err", err)
+		respondError(logger, w, "no valid org id found")
+		return
+	}
+
+	rgs, err := a.store.ListRuleGroupsForUserAndNamespace(req.Context(), userID, "")
+	if err != nil {
+		respondError(logger, w, err.Error())
+		return
+	}
+	if err := a.store.LoadRuleGroups(req.Context(), map[string]rulespb.RuleGroupList{userID: rgs}); err != nil {
+		respondError(logger, w, err.Error())
+		return
+	}
+
+	owners, err := a.ruler.RuleGroupOwners(userID, rgs)
+	if err != nil {
+		respondError(logger, w, err.Error())
+		return
+	}
+	sort.Slice(owners, func(i, j int) bool {
+		if owners[i].Namespace != owners[j].Namespace {
+			return owners[i].Namespace < owners[j].Namespace
+		}
+		return owners[i].Name < owners[j].Name
+	})
+
+	b, err := json.Marshal(&response{
+		Status: "success",
+		Data:   owners,
+	})
+	if err != nil {
+		level.Error(logger).Log("msg", "error marshaling json response", "err", err)
+		respondError(logger, w, "unable to marshal the requested data")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if n, err := w.Write(b); err != nil {
+		level.Error(logger).Log("msg", "error writing response", "bytesWritten", n, "err", err)
+	}
+}
+
 func (a *API) GetRuleGroup(w http.ResponseWriter, req *http.Request) {
 	logger := util_log.WithContext(req.Context(), a.logger)
 	userID, namespace, groupName, err := parseRequest(req, true, true)
@@ -485,6 +631,12 @@ func (a *API) CreateRuleGroup(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if err := a.ruler.AssertMinEvaluationInterval(userID, time.Duration(rg.Interval)); err != nil {
+		level.Error(logger).Log("msg", "limit validation failure", "err", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	rgs, err := a.store.ListRuleGroupsForUserAndNamespace(req.Context(), userID, "")
 	if err != nil {
 		level.Error(logger).Log("msg", "unable to fetch current rule groups for validation", "err", err.Error(), "user", userID)
@@ -492,7 +644,7 @@ func (a *API) CreateRuleGroup(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	if err := a.ruler.AssertMaxRuleGroups(userID, len(rgs)+1); err != nil {
+	if err := a.ruler.AssertMaxRuleGroups(userID, ruleGroupsCount(rgs, namespace, rg.Name)); err != nil {
 		level.Error(logger).Log("msg", "limit validation failure", "err", err.Error())
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -513,6 +665,103 @@ func (a *API) CreateRuleGroup(w http.ResponseWriter, req *http.Request) {
 	respondAccepted(w, logger)
 }
 
+// ruleGroupsCount returns the number of rule groups of the tenant once the rule group is set, which replaces
+// the rule group of the same name in the namespace, or in any namespace if the namespace is empty.
+func ruleGroupsCount(rgs rulespb.RuleGroupList, namespace, name string) int {
+	for _, g := range rgs {
+		if g.Name == name && (namespace == "" || g.Namespace == namespace) {
+			return len(rgs)
+		}
+	}
+	return len(rgs) + 1
+}
+
+// ruleGroupValidation is the result of the validation of a rule group by the ruler.
+type ruleGroupValidation struct {
+	Errors []string `json:"errors"`
+}
+
+// ValidateRuleGroup validates a rule group like CreateRuleGroup does, including the LogQL expressions
+// of its rules and the limits of the tenant, but without storing it. All the validation errors are returned.
+func (a *API) ValidateRuleGroup(w http.ResponseWriter, req *http.Request) {
+	logger := util_log.WithContext(req.Context(), a.logger)
+	userID, err := tenant.TenantID(req.Context())
+	if err != nil || userID == "" {
+		level.Error(logger).Log("msg", "error extracting org id from context", "err", err)
+		respondError(logger, w, "no valid org id found")
+		return
+	}
+
+	logger = log.With(logger, "userID", userID)
+
+	payload, err := io.ReadAll(req.Body)
+	if err != nil {
+		level.Error(logger).Log("msg", "unable to read rule group payload", "err", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rg := rulefmt.RuleGroup{}
+	if err := yaml.Unmarshal(payload, &rg); err != nil {
+		respondValidation(w, logger, []error{err})
+		return
+	}
+
+	errs := a.ruler.manager.ValidateRuleGroup(rg)
+	if err := a.ruler.AssertMaxRulesPerRuleGroup(userID, len(rg.Rules)); err != nil {
+		errs = append(errs, err)
+	}
+	if err := a.ruler.AssertMinEvaluationInterval(userID, time.Duration(rg.Interval)); err != nil {
+		errs = append(errs, err)
+	}
+
+	rgs, err := a.store.ListRuleGroupsForUserAndNamespace(req.Context(), userID, "")
+	if err != nil {
+		level.Error(logger).Log("msg", "unable to fetch current rule groups for validation", "err", err.Error(), "user", userID)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := a.ruler.AssertMaxRuleGroups(userID, ruleGroupsCount(rgs, "", rg.Name)); err != nil {
+		errs = append(errs, err)
+	}
+
+	respondValidation(w, logger, errs)
+}
+
+// respondValidation writes the validation errors of a rule group, with a bad request status when there are any.
+func respondValidation(w http.ResponseWriter, logger log.Logger, errs []error) {
+	resp := &response{
+		Status: "success",
+		Data:   ruleGroupValidation{Errors: []string{}},
+	}
+	status := http.StatusOK
+	if len(errs) > 0 {
+		msgs := make([]string, 0, len(errs))
+		for _, err := range errs {
+			msgs = append(msgs, err.Error())
+		}
+		resp = &response{
+			Status:    "error",
+			Data:      ruleGroupValidation{Errors: msgs},
+			ErrorType: v1.ErrBadData,
+			Error:     strings.Join(msgs, ", "),
+		}
+		status = http.StatusBadRequest
+	}
+
+	b, err := json.Marshal(resp)
+	if err != nil {
+		level.Error(logger).Log("msg", "error marshaling json response", "err", err)
+		respondError(logger, w, "unable to marshal the requested data")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if n, err := w.Write(b); err != nil {
+		level.Error(logger).Log("msg", "error writing response", "bytesWritten", n, "err", err)
+	}
+}
+
 func (a *API) DeleteNamespace(w http.ResponseWriter, req *http.Request) {
 	logger := util_log.WithContext(req.Context(), a.logger)
 
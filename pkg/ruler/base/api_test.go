@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/gorilla/mux"
@@ -66,6 +67,7 @@ func TestRuler_rules(t *testing.T) {
 						},
 					},
 					Interval: 60,
+					Health:   "unknown",
 				},
 			},
 		},
@@ -121,6 +123,7 @@ func TestRuler_rules_special_characters(t *testing.T) {
 						},
 					},
 					Interval: 60,
+					Health:   "unknown",
 				},
 			},
 		},
@@ -129,6 +132,78 @@ func TestRuler_rules_special_characters(t *testing.T) {
 	require.Equal(t, string(expectedResponse), string(body))
 }
 
+func TestRuler_RuleGroupsHealth(t *testing.T) {
+	cfg := defaultRulerConfig(t, newMockRuleStore(mockRules))
+
+	r := newTestRuler(t, cfg)
+	defer services.StopAndAwaitTerminated(context.Background(), r) //nolint:errcheck
+
+	a := NewAPI(r, r.store, log.NewNopLogger())
+
+	req := requestFor(t, http.MethodGet, "https://localhost:8080/ruler/rule_groups/health", nil, "user1")
+	w := httptest.NewRecorder()
+	a.RuleGroupsHealth(w, req)
+
+	resp := w.Result()
+	body, _ := io.ReadAll(resp.Body)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// The rules of the group weren't evaluated yet.
+	expectedResponse, _ := json.Marshal(response{
+		Status: "success",
+		Data: []*RuleGroupHealth{
+			{
+				Name:   "group1",
+				File:   "namespace1",
+				Health: "unknown",
+				Rules:  2,
+			},
+		},
+	})
+	require.Equal(t, string(expectedResponse), string(body))
+}
+
+func TestGroupHealth(t *testing.T) {
+	for _, tc := range []struct {
+		name              string
+		rules             []*RuleStateDesc
+		health, lastError string
+	}{
+		{
+			name: "healthy",
+			rules: []*RuleStateDesc{
+				{Rule: &rulespb.RuleDesc{Record: "UP_RULE"}, Health: "ok"},
+				{Rule: &rulespb.RuleDesc{Alert: "UP_ALERT"}, Health: "ok"},
+			},
+			health: "ok",
+		},
+		{
+			name: "not evaluated",
+			rules: []*RuleStateDesc{
+				{Rule: &rulespb.RuleDesc{Record: "UP_RULE"}, Health: "ok"},
+				{Rule: &rulespb.RuleDesc{Alert: "UP_ALERT"}, Health: "unknown"},
+			},
+			health: "unknown",
+		},
+		{
+			name: "failing",
+			rules: []*RuleStateDesc{
+				{Rule: &rulespb.RuleDesc{Record: "UP_RULE"}, Health: "unknown"},
+				{Rule: &rulespb.RuleDesc{Alert: "UP_ALERT"}, Health: "err", LastError: "query timed out"},
+				{Rule: &rulespb.RuleDesc{Alert: "DOWN_ALERT"}, Health: "err", LastError: "too many outstanding requests"},
+			},
+			health:    "err",
+			lastError: "UP_ALERT: query timed out",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			health, lastError := groupHealth(&GroupStateDesc{ActiveRules: tc.rules})
+			require.Equal(t, tc.health, health)
+			require.Equal(t, tc.lastError, lastError)
+		})
+	}
+}
+
 func TestRuler_alerts(t *testing.T) {
 	cfg := defaultRulerConfig(t, newMockRuleStore(mockRules))
 
@@ -295,7 +370,7 @@ func TestRuler_LimitsPerGroup(t *testing.T) {
 	r := newTestRuler(t, cfg)
 	defer services.StopAndAwaitTerminated(context.Background(), r) //nolint:errcheck
 
-	r.limits = ruleLimits{maxRuleGroups: 1, maxRulesPerRuleGroup: 1}
+	r.limits = ruleLimits{maxRuleGroups: 1, maxRulesPerRuleGroup: 1, minEvalInterval: time.Minute}
 
 	a := NewAPI(r, r.store, log.NewNopLogger())
 
@@ -325,6 +400,18 @@ rules:
 `,
 			output: "per-user rules per rule group limit (limit: 1 actual: 2) exceeded\n",
 		},
+		{
+			name:   "when not reaching the minimum evaluation interval",
+			status: 400,
+			input: `
+name: test
+interval: 15s
+rules:
+- record: up_rule
+  expr: up{}
+`,
+			output: "per-user minimum evaluation interval (limit: 1m0s actual: 15s) not reached\n",
+		},
 	}
 
 	for _, tt := range tc {
@@ -383,6 +470,18 @@ rules:
 `,
 			output: "per-user rule groups limit (limit: 1 actual: 2) exceeded\n",
 		},
+		{
+			name:   "when updating the first group at the rule group limit",
+			status: 202,
+			input: `
+name: test_first_group_will_succeed
+interval: 30s
+rules:
+- record: up_rule
+  expr: up{}
+`,
+			output: "{\"status\":\"success\",\"data\":null,\"errorType\":\"\",\"error\":\"\"}",
+		},
 	}
 
 	// define once so the requests build on each other so the number of rules can be tested
@@ -402,6 +501,75 @@ rules:
 	}
 }
 
+func TestRuler_ValidateRuleGroup(t *testing.T) {
+	cfg := defaultRulerConfig(t, newMockRuleStore(make(map[string]rulespb.RuleGroupList)))
+
+	r := newTestRuler(t, cfg)
+	defer services.StopAndAwaitTerminated(context.Background(), r) //nolint:errcheck
+
+	r.limits = ruleLimits{maxRuleGroups: 1, maxRulesPerRuleGroup: 1}
+
+	a := NewAPI(r, r.store, log.NewNopLogger())
+
+	tc := []struct {
+		name   string
+		input  string
+		output string
+		status int
+	}{
+		{
+			name:   "with a valid rule group",
+			status: 200,
+			input: `
+name: test
+interval: 15s
+rules:
+- record: up_rule
+  expr: up{}
+`,
+			output: `{"status":"success","data":{"errors":[]},"errorType":"","error":""}`,
+		},
+		{
+			name:   "with an invalid payload",
+			status: 400,
+			input:  "name: [test]",
+			output: `{"status":"error","data":{"errors":["yaml: unmarshal errors:\n  line 1: cannot unmarshal !!seq into string"]},"errorType":"bad_data","error":"yaml: unmarshal errors:\n  line 1: cannot unmarshal !!seq into string"}`,
+		},
+		{
+			name:   "with an invalid rule group exceeding the limits",
+			status: 400,
+			input: `
+name: test
+interval: 15s
+rules:
+- record: up_rule
+- alert: up_alert
+  expr: sum(up{}) > 1
+`,
+			output: `{"status":"error","data":{"errors":["7:9: group \"test\", rule 0, \"up_rule\": field 'expr' must be set in rule","per-user rules per rule group limit (limit: 1 actual: 2) exceeded"]},"errorType":"bad_data","error":"7:9: group \"test\", rule 0, \"up_rule\": field 'expr' must be set in rule, per-user rules per rule group limit (limit: 1 actual: 2) exceeded"}`,
+		},
+	}
+
+	router := mux.NewRouter()
+	router.Path("/api/v1/rules/validate").Methods("POST").HandlerFunc(a.ValidateRuleGroup)
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			req := requestFor(t, http.MethodPost, "https://localhost:8080/api/v1/rules/validate", strings.NewReader(tt.input), "user1")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+			require.Equal(t, tt.status, w.Code)
+			require.Equal(t, tt.output, w.Body.String())
+		})
+	}
+
+	// The validated rule groups are not stored.
+	rgs, err := r.store.ListRuleGroupsForUserAndNamespace(context.Background(), "user1", "")
+	require.NoError(t, err)
+	require.Empty(t, rgs)
+}
+
 func requestFor(t *testing.T, method string, url string, body io.Reader, userID string) *http.Request {
 	t.Helper()
 
@@ -23,6 +23,7 @@ import (
 
 	"github.com/grafana/loki/pkg/logproto"
 	"github.com/grafana/loki/pkg/ruler/config"
+	"github.com/grafana/loki/pkg/ruler/util"
 	util_log "github.com/grafana/loki/pkg/util/log"
 )
 
@@ -142,7 +143,11 @@ type RulesLimits interface {
 	RulerTenantShardSize(userID string) int
 	RulerMaxRuleGroupsPerTenant(userID string) int
 	RulerMaxRulesPerRuleGroup(userID string) int
+	RulerMinEvaluationInterval(userID string) time.Duration
 	RulerAlertManagerConfig(userID string) *config.AlertManagerConfig
+	RulerAlertRelabelConfigs(userID string) []*util.RelabelConfig
+	RulerExternalLabels(userID string) labels.Labels
+	RulerExternalURL(userID string) string
 }
 
 // EngineQueryFunc returns a new query function using the rules.EngineQueryFunc function
@@ -271,13 +276,14 @@ func DefaultTenantManagerFactory(cfg Config, p Pusher, q storage.Queryable, engi
 			queryTime = rulerQuerySeconds.WithLabelValues(userID)
 		}
 
+		externalURL := TenantExternalURL(cfg, overrides, userID)
 		return rules.NewManager(&rules.ManagerOptions{
 			Appendable:      NewPusherAppendable(p, userID, overrides, totalWrites, failedWrites),
 			Queryable:       q,
 			QueryFunc:       RecordAndReportRuleQueryMetrics(MetricsQueryFunc(EngineQueryFunc(engine, q, overrides, userID), totalQueries, failedQueries), queryTime, logger),
 			Context:         user.InjectOrgID(ctx, userID),
-			ExternalURL:     cfg.ExternalURL.URL,
-			NotifyFunc:      SendAlerts(notifier, cfg.ExternalURL.URL.String(), cfg.DatasourceUID),
+			ExternalURL:     externalURL.URL,
+			NotifyFunc:      SendAlerts(notifier, externalURL.URL.String(), cfg.DatasourceUID),
 			Logger:          log.With(logger, "user", userID),
 			Registerer:      reg,
 			OutageTolerance: cfg.OutageTolerance,
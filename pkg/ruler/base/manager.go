@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"reflect"
 	"sync"
 
 	"github.com/go-kit/log"
@@ -13,13 +14,16 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/prometheus/config"
+	"github.com/prometheus/prometheus/model/relabel"
 	"github.com/prometheus/prometheus/model/rulefmt"
 	"github.com/prometheus/prometheus/notifier"
 	promRules "github.com/prometheus/prometheus/rules"
 	"github.com/weaveworks/common/user"
 	"golang.org/x/net/context/ctxhttp"
 
+	ruler_config "github.com/grafana/loki/pkg/ruler/config"
 	"github.com/grafana/loki/pkg/ruler/rulespb"
+	"github.com/grafana/loki/pkg/ruler/util"
 )
 
 type DefaultMultiTenantManager struct {
@@ -139,6 +143,11 @@ func (r *DefaultMultiTenantManager) syncRulesToManager(ctx context.Context, user
 	}
 
 	manager, exists := r.userManagers[user]
+	if exists {
+		if err := r.updateNotifier(user); err != nil {
+			level.Error(r.logger).Log("msg", "unable to update notifier", "user", user, "err", err)
+		}
+	}
 	if !exists || update {
 		level.Debug(r.logger).Log("msg", "updating rules", "user", user)
 		r.configUpdatesTotal.WithLabelValues(user).Inc()
@@ -155,7 +164,7 @@ func (r *DefaultMultiTenantManager) syncRulesToManager(ctx context.Context, user
 			go manager.Run()
 			r.userManagers[user] = manager
 		}
-		err = manager.Update(r.cfg.EvaluationInterval, files, r.cfg.ExternalLabels, r.cfg.ExternalURL.String(), nil)
+		err = manager.Update(r.cfg.EvaluationInterval, files, TenantExternalLabels(r.cfg, r.limits, user), TenantExternalURL(r.cfg, r.limits, user).String(), nil)
 		if err != nil {
 			r.lastReloadSuccessful.WithLabelValues(user).Set(0)
 			level.Error(r.logger).Log("msg", "unable to update rule manager", "user", user, "err", err)
@@ -192,30 +201,20 @@ func (r *DefaultMultiTenantManager) getOrCreateNotifier(userID string) (*notifie
 		return n.notifier, nil
 	}
 
-	nCfg, ok := r.notifiersCfg[userID]
-	if !ok {
-		amCfg := r.cfg.AlertManagerConfig
-
-		// Apply the tenant specific alertmanager config when defined
-		if amOverrides := r.limits.RulerAlertManagerConfig(userID); amOverrides != nil {
-			amCfg = applyAlertmanagerDefaults(*amOverrides)
-		}
-
-		var err error
-		nCfg, err = buildNotifierConfig(&amCfg, r.cfg.ExternalLabels)
-		if err != nil {
-			return nil, fmt.Errorf("failed to build notifier config for tenant %s: %w", userID, err)
-		}
-
-		if nCfg != nil {
-			r.notifiersCfg[userID] = nCfg
-		}
+	amCfg, err := r.alertManagerConfig(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build notifier config for tenant %s: %w", userID, err)
+	}
+	nCfg, err := buildNotifierConfig(&amCfg, TenantExternalLabels(r.cfg, r.limits, userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build notifier config for tenant %s: %w", userID, err)
 	}
+	r.notifiersCfg[userID] = nCfg
 
 	reg := prometheus.WrapRegistererWith(prometheus.Labels{"user": userID}, r.registry)
 	reg = prometheus.WrapRegistererWithPrefix("cortex_", reg)
 	n = newRulerNotifier(&notifier.Options{
-		QueueCapacity: r.cfg.NotificationQueueCapacity,
+		QueueCapacity: amCfg.NotificationQueueCapacity,
 		Registerer:    reg,
 		Do: func(ctx context.Context, client *http.Client, req *http.Request) (*http.Response, error) {
 			// Note: The passed-in context comes from the Prometheus notifier
@@ -245,6 +244,56 @@ func (r *DefaultMultiTenantManager) getOrCreateNotifier(userID string) (*notifie
 	return n.notifier, nil
 }
 
+// alertManagerConfig returns the Alertmanager configuration of the tenant, which is the tenant specific
+// configuration when defined, and the configuration of the ruler otherwise.
+func (r *DefaultMultiTenantManager) alertManagerConfig(userID string) (ruler_config.AlertManagerConfig, error) {
+	amCfg := r.cfg.AlertManagerConfig
+	if amOverrides := r.limits.RulerAlertManagerConfig(userID); amOverrides != nil {
+		amCfg = applyAlertmanagerDefaults(*amOverrides)
+	}
+
+	// the alert relabel configs of the tenant are applied after the ones of the Alertmanager configuration.
+	relabelConfigs, err := util.ParseRelabelConfigs(r.limits.RulerAlertRelabelConfigs(userID))
+	if err != nil {
+		return amCfg, fmt.Errorf("failed to parse alert relabel configs: %w", err)
+	}
+	if len(relabelConfigs) > 0 {
+		amCfg.AlertRelabelConfigs = append(append(make([]*relabel.Config, 0, len(amCfg.AlertRelabelConfigs)+len(relabelConfigs)), amCfg.AlertRelabelConfigs...), relabelConfigs...)
+	}
+	return amCfg, nil
+}
+
+// updateNotifier applies the Alertmanager configuration of the tenant to its notifier when it changed,
+// e.g. when the tenant specific configuration is updated in the runtime config. The capacity of the
+// notification queue is only applied when the notifier is created.
+func (r *DefaultMultiTenantManager) updateNotifier(userID string) error {
+	r.notifiersMtx.Lock()
+	defer r.notifiersMtx.Unlock()
+
+	n, ok := r.notifiers[userID]
+	if !ok {
+		return nil
+	}
+
+	amCfg, err := r.alertManagerConfig(userID)
+	if err != nil {
+		return fmt.Errorf("failed to build notifier config for tenant %s: %w", userID, err)
+	}
+	nCfg, err := buildNotifierConfig(&amCfg, TenantExternalLabels(r.cfg, r.limits, userID))
+	if err != nil {
+		return fmt.Errorf("failed to build notifier config for tenant %s: %w", userID, err)
+	}
+	if reflect.DeepEqual(nCfg, r.notifiersCfg[userID]) {
+		return nil
+	}
+
+	if err := n.applyConfig(nCfg); err != nil {
+		return err
+	}
+	r.notifiersCfg[userID] = nCfg
+	return nil
+}
+
 func (r *DefaultMultiTenantManager) GetRules(userID string) []*promRules.Group {
 	var groups []*promRules.Group
 	r.userManagerMtx.Lock()
@@ -255,6 +304,10 @@ func (r *DefaultMultiTenantManager) GetRules(userID string) []*promRules.Group {
 	return groups
 }
 
+func (r *DefaultMultiTenantManager) GetRuleGroupSamples(userID string) map[string]float64 {
+	return r.userManagerMetrics.LastEvaluationSamples(userID)
+}
+
 func (r *DefaultMultiTenantManager) Stop() {
 	r.notifiersMtx.Lock()
 	for _, n := range r.notifiers {
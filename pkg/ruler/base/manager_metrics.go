@@ -119,6 +119,30 @@ func (m *ManagerMetrics) RemoveUserRegistry(user string) {
 	m.regs.RemoveUserRegistry(user, true)
 }
 
+// LastEvaluationSamples returns the number of samples returned during the last
+// evaluation of each rule group of the user, keyed by the rule group key.
+func (m *ManagerMetrics) LastEvaluationSamples(user string) map[string]float64 {
+	mfm, err := m.regs.GatherUser(user)
+	if err != nil || mfm == nil {
+		return nil
+	}
+	mf, ok := mfm["prometheus_rule_group_last_evaluation_samples"]
+	if !ok {
+		return nil
+	}
+
+	samples := make(map[string]float64, len(mf.GetMetric()))
+	for _, metric := range mf.GetMetric() {
+		for _, lp := range metric.GetLabel() {
+			if lp.GetName() == "rule_group" {
+				samples[lp.GetValue()] = metric.GetGauge().GetValue()
+				break
+			}
+		}
+	}
+	return samples
+}
+
 // Describe implements the Collector interface
 func (m *ManagerMetrics) Describe(out chan<- *prometheus.Desc) {
 	out <- m.EvalDuration
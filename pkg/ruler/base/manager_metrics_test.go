@@ -408,6 +408,19 @@ func TestMetricsArePerUser(t *testing.T) {
 	}
 }
 
+func TestManagerMetrics_LastEvaluationSamples(t *testing.T) {
+	managerMetrics := NewManagerMetrics(false, nil)
+	managerMetrics.AddUserRegistry("user1", populateManager(1))
+	managerMetrics.AddUserRegistry("user2", populateManager(10))
+
+	require.Equal(t, map[string]float64{"group_one": 1000, "group_two": 1000}, managerMetrics.LastEvaluationSamples("user1"))
+	require.Equal(t, map[string]float64{"group_one": 10000, "group_two": 10000}, managerMetrics.LastEvaluationSamples("user2"))
+	require.Nil(t, managerMetrics.LastEvaluationSamples("user3"))
+
+	managerMetrics.RemoveUserRegistry("user2")
+	require.Nil(t, managerMetrics.LastEvaluationSamples("user2"))
+}
+
 func TestMetricLabelTransformer(t *testing.T) {
 	mainReg := prometheus.NewPedanticRegistry()
 
@@ -7,13 +7,18 @@ import (
 
 	"github.com/go-kit/log"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	promConfig "github.com/prometheus/prometheus/config"
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
 	"github.com/prometheus/prometheus/notifier"
 	promRules "github.com/prometheus/prometheus/rules"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/atomic"
 
+	"github.com/grafana/loki/pkg/ruler/config"
 	"github.com/grafana/loki/pkg/ruler/rulespb"
+	ruler_util "github.com/grafana/loki/pkg/ruler/util"
 	"github.com/grafana/loki/pkg/util/test"
 )
 
@@ -92,6 +97,122 @@ func TestSyncRuleGroups(t *testing.T) {
 	})
 }
 
+func TestSyncRuleGroupsUpdatesNotifierConfig(t *testing.T) {
+	const user = "testUser"
+	limits := ruleLimits{alertManagerConfig: map[string]*config.AlertManagerConfig{
+		user: {AlertmanagerURL: "http://alertmanager-1:9093"},
+	}}
+
+	m, err := NewDefaultMultiTenantManager(Config{RulePath: t.TempDir()}, factory, nil, log.NewNopLogger(), limits)
+	require.NoError(t, err)
+	defer m.Stop()
+
+	userRules := map[string]rulespb.RuleGroupList{
+		user: {
+			&rulespb.RuleGroupDesc{
+				Name:      "group1",
+				Namespace: "ns",
+				Interval:  1 * time.Minute,
+				User:      user,
+			},
+		},
+	}
+	m.SyncRuleGroups(context.Background(), userRules)
+	require.Len(t, getNotifierConfig(m, user).AlertingConfig.AlertmanagerConfigs, 1)
+
+	// The tenant specific configuration is updated, e.g. by the runtime config.
+	limits.alertManagerConfig[user] = &config.AlertManagerConfig{
+		AlertmanagerURL:          "http://alertmanager-1:9093,http://alertmanager-2:9093",
+		AlertmanangerEnableV2API: true,
+	}
+	m.SyncRuleGroups(context.Background(), userRules)
+
+	amConfigs := getNotifierConfig(m, user).AlertingConfig.AlertmanagerConfigs
+	require.Len(t, amConfigs, 2)
+	for _, amConfig := range amConfigs {
+		require.Equal(t, promConfig.AlertmanagerAPIVersionV2, amConfig.APIVersion)
+	}
+}
+
+func TestSyncRuleGroupsAppliesAlertRelabelConfigs(t *testing.T) {
+	const user = "testUser"
+	limits := ruleLimits{alertRelabelConfigs: map[string][]*ruler_util.RelabelConfig{
+		user: {{TargetLabel: "cluster", Replacement: "dev", Action: "replace"}},
+	}}
+
+	cfg := Config{RulePath: t.TempDir()}
+	cfg.AlertManagerConfig = config.AlertManagerConfig{
+		AlertmanagerURL:     "http://alertmanager:9093",
+		AlertRelabelConfigs: []*relabel.Config{{SourceLabels: model.LabelNames{"severity"}, Regex: relabel.MustNewRegexp("debug"), Action: relabel.Drop}},
+	}
+	m, err := NewDefaultMultiTenantManager(cfg, factory, nil, log.NewNopLogger(), limits)
+	require.NoError(t, err)
+	defer m.Stop()
+
+	m.SyncRuleGroups(context.Background(), map[string]rulespb.RuleGroupList{
+		user: {
+			&rulespb.RuleGroupDesc{
+				Name:      "group1",
+				Namespace: "ns",
+				Interval:  1 * time.Minute,
+				User:      user,
+			},
+		},
+	})
+
+	// The alert relabel configs of the tenant are applied after the ones of the ruler.
+	relabelConfigs := getNotifierConfig(m, user).AlertingConfig.AlertRelabelConfigs
+	require.Len(t, relabelConfigs, 2)
+	require.Equal(t, relabel.Drop, relabelConfigs[0].Action)
+	require.Equal(t, relabel.Replace, relabelConfigs[1].Action)
+	require.Equal(t, "cluster", relabelConfigs[1].TargetLabel)
+	require.Equal(t, "dev", relabelConfigs[1].Replacement)
+
+	// The alert relabel configs of the ruler are left unchanged.
+	require.Len(t, m.cfg.AlertManagerConfig.AlertRelabelConfigs, 1)
+}
+
+func TestSyncRuleGroupsAppliesTenantExternalLabels(t *testing.T) {
+	const user = "testUser"
+	limits := ruleLimits{
+		externalLabels: map[string]labels.Labels{user: labels.FromStrings("cluster", "tenant-cluster")},
+		externalURL:    map[string]string{user: "https://grafana.tenant.example.com"},
+	}
+
+	cfg := Config{RulePath: t.TempDir(), ExternalLabels: labels.FromStrings("cluster", "ruler-cluster", "region", "eu")}
+	cfg.AlertManagerConfig = config.AlertManagerConfig{AlertmanagerURL: "http://alertmanager:9093"}
+	require.NoError(t, cfg.ExternalURL.Set("https://grafana.example.com"))
+	m, err := NewDefaultMultiTenantManager(cfg, factory, nil, log.NewNopLogger(), limits)
+	require.NoError(t, err)
+	defer m.Stop()
+
+	m.SyncRuleGroups(context.Background(), map[string]rulespb.RuleGroupList{
+		user: {
+			&rulespb.RuleGroupDesc{
+				Name:      "group1",
+				Namespace: "ns",
+				Interval:  1 * time.Minute,
+				User:      user,
+			},
+		},
+	})
+
+	// The external labels of the tenant are added to the ones of the ruler.
+	require.Equal(t, labels.FromStrings("cluster", "tenant-cluster", "region", "eu"), getNotifierConfig(m, user).GlobalConfig.ExternalLabels)
+	require.Equal(t, "https://grafana.tenant.example.com", TenantExternalURL(cfg, limits, user).String())
+
+	// The tenants without external labels and URL get the ones of the ruler.
+	require.Equal(t, cfg.ExternalLabels, TenantExternalLabels(cfg, limits, "other"))
+	require.Equal(t, "https://grafana.example.com", TenantExternalURL(cfg, limits, "other").String())
+}
+
+func getNotifierConfig(m *DefaultMultiTenantManager, user string) *promConfig.Config {
+	m.notifiersMtx.Lock()
+	defer m.notifiersMtx.Unlock()
+
+	return m.notifiersCfg[user]
+}
+
 func getManager(m *DefaultMultiTenantManager, user string) RulesManager {
 	m.userManagerMtx.Lock()
 	defer m.userManagerMtx.Unlock()
@@ -62,6 +62,7 @@ const (
 	// Limit errors
 	errMaxRuleGroupsPerUserLimitExceeded        = "per-user rule groups limit (limit: %d actual: %d) exceeded"
 	errMaxRulesPerRuleGroupPerUserLimitExceeded = "per-user rules per rule group limit (limit: %d actual: %d) exceeded"
+	errMinEvaluationIntervalNotReached          = "per-user minimum evaluation interval (limit: %s actual: %s) not reached"
 
 	// errors
 	errListAllUser = "unable to list the ruler users"
@@ -192,6 +193,33 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.RingCheckPeriod = 5 * time.Second
 }
 
+// TenantExternalLabels returns the external labels of the tenant, which are the external labels of the ruler
+// with the external labels of the tenant added to them.
+func TenantExternalLabels(cfg Config, limits RulesLimits, userID string) labels.Labels {
+	tenantLabels := limits.RulerExternalLabels(userID)
+	if len(tenantLabels) == 0 {
+		return cfg.ExternalLabels
+	}
+
+	b := labels.NewBuilder(cfg.ExternalLabels)
+	for _, l := range tenantLabels {
+		b.Set(l.Name, l.Value)
+	}
+	return b.Labels()
+}
+
+// TenantExternalURL returns the external URL of the tenant, which is the external URL of the ruler unless
+// the tenant has its own.
+func TenantExternalURL(cfg Config, limits RulesLimits, userID string) flagext.URLValue {
+	if v := limits.RulerExternalURL(userID); v != "" {
+		// the external URL of the tenant is validated with its limits.
+		if u, err := url.Parse(v); err == nil {
+			return flagext.URLValue{URL: u}
+		}
+	}
+	return cfg.ExternalURL
+}
+
 // MultiTenantManager is the interface of interaction with a Manager that is tenant aware.
 type MultiTenantManager interface {
 	// SyncRuleGroups is used to sync the Manager with rules from the RuleStore.
@@ -199,6 +227,9 @@ type MultiTenantManager interface {
 	SyncRuleGroups(ctx context.Context, ruleGroups map[string]rulespb.RuleGroupList)
 	// GetRules fetches rules for a particular tenant (userID).
 	GetRules(userID string) []*promRules.Group
+	// GetRuleGroupSamples returns the number of samples of the last evaluation of
+	// each rule group of a particular tenant (userID), keyed by the rule group key.
+	GetRuleGroupSamples(userID string) map[string]float64
 	// Stop stops all Manager components.
 	Stop()
 	// ValidateRuleGroup validates a rulegroup
@@ -483,25 +514,88 @@ func getRuleIdentifier(r *rulespb.RuleDesc) string {
 }
 
 func instanceOwnsRuleGroup(r ring.ReadRing, g *rulespb.RuleGroupDesc, instanceAddr string) (bool, error) {
-	hash := tokenForGroup(g)
-
-	rlrs, err := r.Get(hash, RingOp, nil, nil, nil)
+	owner, err := ruleGroupOwner(r, g)
 	if err != nil {
-		return false, errors.Wrap(err, "error reading ring to verify rule group ownership")
+		return false, err
 	}
 
-	return rlrs.Instances[0].Addr == instanceAddr, nil
+	return owner == instanceAddr, nil
 }
 
 func instanceOwnsRule(r ring.ReadRing, rg *rulespb.RuleGroupDesc, rd *rulespb.RuleDesc, instanceAddr string) (bool, error) {
-	hash := tokenForRule(rg, rd)
+	owner, err := ruleOwner(r, rg, rd)
+	if err != nil {
+		return false, err
+	}
 
-	rlrs, err := r.Get(hash, RingOp, nil, nil, nil)
+	return owner == instanceAddr, nil
+}
+
+// ruleGroupOwner returns the address of the ruler instance owning the rule group in the ring.
+func ruleGroupOwner(r ring.ReadRing, g *rulespb.RuleGroupDesc) (string, error) {
+	rlrs, err := r.Get(tokenForGroup(g), RingOp, nil, nil, nil)
 	if err != nil {
-		return false, errors.Wrap(err, "error reading ring to verify rule group ownership")
+		return "", errors.Wrap(err, "error reading ring to verify rule group ownership")
 	}
 
-	return rlrs.Instances[0].Addr == instanceAddr, nil
+	return rlrs.Instances[0].Addr, nil
+}
+
+// ruleOwner returns the address of the ruler instance owning the rule of the rule group in the ring.
+func ruleOwner(r ring.ReadRing, rg *rulespb.RuleGroupDesc, rd *rulespb.RuleDesc) (string, error) {
+	rlrs, err := r.Get(tokenForRule(rg, rd), RingOp, nil, nil, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "error reading ring to verify rule group ownership")
+	}
+
+	return rlrs.Instances[0].Addr, nil
+}
+
+// RuleGroupOwner is the ruler instance evaluating a rule group of a tenant, or the instances evaluating
+// each of its rules when the rules are sharded by rule.
+type RuleGroupOwner struct {
+	Namespace string      `json:"namespace"`
+	Name      string      `json:"name"`
+	Owner     string      `json:"owner,omitempty"`
+	Rules     []RuleOwner `json:"rules,omitempty"`
+}
+
+// RuleOwner is the ruler instance evaluating a rule.
+type RuleOwner struct {
+	Name  string `json:"name"`
+	Owner string `json:"owner"`
+}
+
+// RuleGroupOwners returns the addresses of the ruler instances evaluating the rule groups of a tenant,
+// according to the ring and the sharding strategy and algorithm of the ruler. The owner of a rule group,
+// or of a rule, is empty when no healthy ruler instance owns it.
+func (r *Ruler) RuleGroupOwners(userID string, groups []*rulespb.RuleGroupDesc) ([]RuleGroupOwner, error) {
+	if !r.cfg.EnableSharding {
+		return nil, errors.New("the rule groups are not sharded: every ruler evaluates all of them")
+	}
+
+	userRing := ring.ReadRing(r.ring)
+	if shardSize := r.limits.RulerTenantShardSize(userID); shardSize > 0 && r.cfg.ShardingStrategy == util.ShardingStrategyShuffle {
+		userRing = r.ring.ShuffleShard(userID, shardSize)
+	}
+
+	owners := make([]RuleGroupOwner, 0, len(groups))
+	for _, g := range groups {
+		owner := RuleGroupOwner{Namespace: g.Namespace, Name: g.Name}
+		switch r.cfg.ShardingAlgo {
+		case util.ShardingAlgoByRule:
+			for _, rd := range g.Rules {
+				// the owner is left empty when no healthy ruler owns the rule.
+				addr, _ := ruleOwner(userRing, g, rd)
+				owner.Rules = append(owner.Rules, RuleOwner{Name: getRuleIdentifier(rd), Owner: addr})
+			}
+		default:
+			addr, _ := ruleGroupOwner(userRing, g)
+			owner.Owner = addr
+		}
+		owners = append(owners, owner)
+	}
+	return owners, nil
 }
 
 func (r *Ruler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
@@ -809,6 +903,7 @@ func (r *Ruler) GetRules(ctx context.Context) ([]*GroupStateDesc, error) {
 
 func (r *Ruler) getLocalRules(userID string) ([]*GroupStateDesc, error) {
 	groups := r.manager.GetRules(userID)
+	samples := r.manager.GetRuleGroupSamples(userID)
 
 	groupDescs := make([]*GroupStateDesc, 0, len(groups))
 	prefix := filepath.Join(r.cfg.RulePath, userID) + "/"
@@ -832,6 +927,7 @@ func (r *Ruler) getLocalRules(userID string) ([]*GroupStateDesc, error) {
 
 			EvaluationTimestamp: group.GetLastEvaluation(),
 			EvaluationDuration:  group.GetEvaluationTime(),
+			Series:              int64(samples[promRules.GroupKey(group.File(), group.Name())]),
 		}
 		for _, r := range group.Rules() {
 			lastError := ""
@@ -1011,6 +1107,25 @@ func (r *Ruler) AssertMaxRulesPerRuleGroup(userID string, rules int) error {
 	return fmt.Errorf(errMaxRulesPerRuleGroupPerUserLimitExceeded, limit, rules)
 }
 
+// AssertMinEvaluationInterval limit is reached by the evaluation interval of a rule group in input,
+// which defaults to the evaluation interval of the ruler, and returns an error if not.
+func (r *Ruler) AssertMinEvaluationInterval(userID string, interval time.Duration) error {
+	limit := r.limits.RulerMinEvaluationInterval(userID)
+
+	if limit <= 0 {
+		return nil
+	}
+
+	if interval == 0 {
+		interval = r.cfg.EvaluationInterval
+	}
+
+	if interval >= limit {
+		return nil
+	}
+	return fmt.Errorf(errMinEvaluationIntervalNotReached, limit, interval)
+}
+
 func (r *Ruler) DeleteTenantConfiguration(w http.ResponseWriter, req *http.Request) {
 	logger := util_log.WithContext(req.Context(), r.logger)
 
@@ -122,6 +122,8 @@ type GroupStateDesc struct {
 	ActiveRules         []*RuleStateDesc       `protobuf:"bytes,2,rep,name=active_rules,json=activeRules,proto3" json:"active_rules,omitempty"`
 	EvaluationTimestamp time.Time              `protobuf:"bytes,3,opt,name=evaluationTimestamp,proto3,stdtime" json:"evaluationTimestamp"`
 	EvaluationDuration  time.Duration          `protobuf:"bytes,4,opt,name=evaluationDuration,proto3,stdduration" json:"evaluationDuration"`
+	// series is the number of series returned by the last evaluation.
+	Series int64 `protobuf:"varint,5,opt,name=series,proto3" json:"series,omitempty"`
 }
 
 func (m *GroupStateDesc) Reset()      { *m = GroupStateDesc{} }
@@ -184,6 +186,13 @@ func (m *GroupStateDesc) GetEvaluationDuration() time.Duration {
 	return 0
 }
 
+func (m *GroupStateDesc) GetSeries() int64 {
+	if m != nil {
+		return m.Series
+	}
+	return 0
+}
+
 // RuleStateDesc is a proto representation of a Prometheus Rule
 type RuleStateDesc struct {
 	Rule                *rulespb.RuleDesc `protobuf:"bytes,1,opt,name=rule,proto3" json:"rule,omitempty"`
@@ -380,50 +389,51 @@ func init() {
 func init() { proto.RegisterFile("pkg/ruler/base/ruler.proto", fileDescriptor_ca810a0fd7057a73) }
 
 var fileDescriptor_ca810a0fd7057a73 = []byte{
-	// 682 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x54, 0x41, 0x6f, 0xd3, 0x30,
-	0x14, 0x8e, 0xbb, 0xb6, 0x6b, 0xdd, 0x31, 0x24, 0x6f, 0x42, 0x59, 0x01, 0x77, 0x2a, 0x97, 0x09,
-	0xa1, 0x44, 0x0c, 0x84, 0x84, 0x10, 0x42, 0x9d, 0x36, 0xb8, 0xec, 0x80, 0x32, 0xe0, 0x3a, 0xb9,
-	0xad, 0x97, 0x45, 0xf3, 0xe2, 0x60, 0x3b, 0x95, 0xb8, 0xf1, 0x13, 0x76, 0xe4, 0xca, 0x8d, 0x9f,
-	0xb2, 0xe3, 0x8e, 0x13, 0x48, 0x83, 0x65, 0x17, 0x8e, 0xfb, 0x01, 0x1c, 0x90, 0xed, 0x64, 0xc9,
-	0x60, 0x1c, 0x2a, 0xb4, 0x4b, 0x6b, 0xbf, 0xf7, 0xbe, 0xef, 0x7b, 0xef, 0xb3, 0x1d, 0xd8, 0x4d,
-	0xf6, 0x42, 0x5f, 0xa4, 0x8c, 0x0a, 0x7f, 0x48, 0x24, 0xb5, 0x4b, 0x2f, 0x11, 0x5c, 0x71, 0x54,
-	0xd7, 0x91, 0xee, 0x62, 0xc8, 0x43, 0x6e, 0x02, 0xbe, 0x5e, 0xd9, 0x5c, 0x17, 0x87, 0x9c, 0x87,
-	0x8c, 0xfa, 0x66, 0x37, 0x4c, 0x77, 0xfc, 0x71, 0x2a, 0x88, 0x8a, 0x78, 0x9c, 0xe7, 0x7b, 0x7f,
-	0xe6, 0x55, 0xb4, 0x4f, 0xa5, 0x22, 0xfb, 0x49, 0x5e, 0x70, 0x5b, 0x0b, 0x33, 0x1e, 0x5a, 0xe6,
-	0x62, 0x91, 0x27, 0xef, 0x96, 0x5d, 0xe9, 0x5f, 0x99, 0x0c, 0xed, 0xbf, 0x4d, 0xf7, 0xe7, 0xe1,
-	0x5c, 0xa0, 0xb7, 0x01, 0x7d, 0x9f, 0x52, 0xa9, 0xfa, 0xcf, 0xe1, 0x8d, 0x7c, 0x2f, 0x13, 0x1e,
-	0x4b, 0x8a, 0x1e, 0xc0, 0x66, 0x28, 0x78, 0x9a, 0x48, 0x17, 0x2c, 0xcf, 0xac, 0x74, 0x56, 0x17,
-	0x3d, 0x3d, 0x8a, 0xf7, 0x4a, 0xc7, 0xb6, 0x14, 0x51, 0x74, 0x9d, 0xca, 0x51, 0x90, 0xd7, 0xf4,
-	0x3f, 0xd7, 0xe0, 0xfc, 0xe5, 0x14, 0xba, 0x0f, 0x1b, 0x26, 0xe9, 0x82, 0x65, 0x60, 0xf0, 0x56,
-	0x5e, 0xab, 0x98, 0x4a, 0x83, 0xb7, 0x25, 0xe8, 0x09, 0x9c, 0x23, 0x23, 0x15, 0x4d, 0xe8, 0xb6,
-	0x29, 0x72, 0x6b, 0x46, 0x72, 0xc1, 0x4a, 0x6a, 0x44, 0xa9, 0xd8, 0xb1, 0x85, 0xa6, 0x59, 0xf4,
-	0x0e, 0x2e, 0xd0, 0x09, 0x61, 0xa9, 0xb1, 0xed, 0x4d, 0x61, 0x8f, 0x3b, 0x63, 0x14, 0xbb, 0x9e,
-	0x35, 0xd0, 0x2b, 0x0c, 0xf4, 0x2e, 0x2a, 0xd6, 0x5a, 0x87, 0x27, 0x3d, 0xe7, 0xe0, 0x7b, 0x0f,
-	0x04, 0x57, 0x11, 0xa0, 0x2d, 0x88, 0xca, 0xf0, 0x7a, 0x7e, 0x2c, 0x6e, 0xdd, 0xd0, 0x2e, 0xfd,
-	0x45, 0x5b, 0x14, 0x58, 0xd6, 0x4f, 0x9a, 0xf5, 0x0a, 0x78, 0xff, 0x5b, 0xcd, 0x7a, 0x5c, 0x5a,
-	0x74, 0x0f, 0xd6, 0xf5, 0xbc, 0xb9, 0x43, 0x37, 0x2b, 0x0e, 0x99, 0x51, 0x4d, 0x12, 0x2d, 0xc2,
-	0x86, 0xd4, 0x08, 0xb7, 0xb6, 0x0c, 0x56, 0xda, 0x81, 0xdd, 0xa0, 0x5b, 0xb0, 0xb9, 0x4b, 0x09,
-	0x53, 0xbb, 0x66, 0xd8, 0x76, 0x90, 0xef, 0xd0, 0x1d, 0xd8, 0x66, 0x44, 0xaa, 0x0d, 0x21, 0xb8,
-	0x30, 0x0d, 0xb7, 0x83, 0x32, 0xa0, 0x0f, 0x95, 0x30, 0x2a, 0x94, 0x74, 0x1b, 0xd5, 0x43, 0x1d,
-	0xe8, 0x58, 0xe5, 0x50, 0x6d, 0xcd, 0xbf, 0xdc, 0x6d, 0x5e, 0x8f, 0xbb, 0xb3, 0xff, 0xe7, 0xee,
-	0xaf, 0x3a, 0x9c, 0xbf, 0x3c, 0x47, 0xe9, 0x1c, 0xa8, 0x3a, 0xc7, 0x60, 0x93, 0x91, 0x21, 0x65,
-	0xc5, 0x2d, 0x5b, 0xf2, 0x2e, 0x5e, 0xce, 0x26, 0x0d, 0xc9, 0xe8, 0xc3, 0xa6, 0xce, 0xbe, 0x26,
-	0x91, 0x58, 0x7b, 0xaa, 0x15, 0xbf, 0x9e, 0xf4, 0x1e, 0x86, 0x91, 0xda, 0x4d, 0x87, 0xde, 0x88,
-	0xef, 0xfb, 0xa1, 0x20, 0x3b, 0x24, 0x26, 0x3e, 0xe3, 0x7b, 0x91, 0x5f, 0x7d, 0x80, 0x9e, 0xc1,
-	0x0d, 0xc6, 0x24, 0x51, 0x54, 0x04, 0xb9, 0x06, 0x9a, 0xc0, 0x0e, 0x89, 0x63, 0xae, 0x4c, 0x93,
-	0xd2, 0x9d, 0xb9, 0x46, 0xc9, 0xaa, 0x90, 0x9e, 0x5d, 0x7b, 0x44, 0xcd, 0x1d, 0x00, 0x81, 0xdd,
-	0xa0, 0x01, 0x6c, 0xe7, 0xef, 0x8c, 0x28, 0xb7, 0x31, 0xc5, 0x39, 0xb6, 0x2c, 0x6c, 0xa0, 0xd0,
-	0x0b, 0xd8, 0xda, 0x89, 0x04, 0x1d, 0x6b, 0x86, 0x69, 0x6e, 0xc2, 0xac, 0x41, 0x0d, 0x14, 0xda,
-	0x80, 0x1d, 0x41, 0x25, 0x67, 0x13, 0xcb, 0x31, 0x3b, 0x05, 0x07, 0x2c, 0x80, 0x03, 0x85, 0x5e,
-	0xc2, 0x39, 0x7d, 0xaf, 0xb7, 0x25, 0x8d, 0x95, 0xe6, 0x69, 0x4d, 0xc3, 0xa3, 0x91, 0x5b, 0x34,
-	0x56, 0xb6, 0x9d, 0x09, 0x61, 0xd1, 0x78, 0x3b, 0x8d, 0x55, 0xc4, 0xdc, 0xf6, 0x34, 0x34, 0x06,
-	0xf8, 0x56, 0xe3, 0x56, 0x9f, 0xc1, 0x86, 0x7e, 0xb7, 0x02, 0xad, 0xda, 0x85, 0x44, 0xa8, 0xfc,
-	0x7a, 0x15, 0x5f, 0xd9, 0xee, 0xc2, 0xa5, 0x98, 0xfd, 0xd2, 0xf6, 0x9d, 0xb5, 0xc7, 0x47, 0xa7,
-	0xd8, 0x39, 0x3e, 0xc5, 0xce, 0xf9, 0x29, 0x06, 0x1f, 0x33, 0x0c, 0xbe, 0x64, 0x18, 0x1c, 0x66,
-	0x18, 0x1c, 0x65, 0x18, 0xfc, 0xc8, 0x30, 0xf8, 0x99, 0x61, 0xe7, 0x3c, 0xc3, 0xe0, 0xe0, 0x0c,
-	0x3b, 0x47, 0x67, 0xd8, 0x39, 0x3e, 0xc3, 0xce, 0xb0, 0x69, 0x9a, 0x7b, 0xf4, 0x3b, 0x00, 0x00,
-	0xff, 0xff, 0x35, 0xb6, 0x62, 0xce, 0x80, 0x06, 0x00, 0x00,
+	// 695 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x54, 0xcb, 0x6e, 0x13, 0x3d,
+	0x18, 0x1d, 0xe7, 0xd6, 0xc4, 0x69, 0xfb, 0x4b, 0x6e, 0xf5, 0x6b, 0x1a, 0xc0, 0x89, 0xc2, 0xa6,
+	0x42, 0x68, 0x46, 0x14, 0x84, 0x84, 0x10, 0x42, 0xa9, 0x5a, 0xd8, 0x74, 0x81, 0xa6, 0xc0, 0xb6,
+	0x72, 0x12, 0x77, 0x3a, 0xaa, 0x3b, 0x1e, 0x6c, 0x4f, 0x24, 0x76, 0x3c, 0x42, 0x97, 0x3c, 0x02,
+	0xaf, 0xc0, 0x1b, 0x74, 0xd9, 0x65, 0x05, 0x52, 0xa1, 0x93, 0x0d, 0xcb, 0x3e, 0x00, 0x0b, 0x64,
+	0x7b, 0xa6, 0x49, 0xa0, 0x2c, 0x22, 0xd4, 0x4d, 0xe2, 0xef, 0x72, 0xce, 0xf9, 0x7c, 0x6c, 0x0f,
+	0x6c, 0x25, 0x87, 0xa1, 0x2f, 0x52, 0x46, 0x85, 0xdf, 0x27, 0x92, 0xda, 0xa5, 0x97, 0x08, 0xae,
+	0x38, 0xaa, 0xe8, 0x4c, 0x6b, 0x35, 0xe4, 0x21, 0x37, 0x09, 0x5f, 0xaf, 0x6c, 0xad, 0x85, 0x43,
+	0xce, 0x43, 0x46, 0x7d, 0x13, 0xf5, 0xd3, 0x7d, 0x7f, 0x98, 0x0a, 0xa2, 0x22, 0x1e, 0xe7, 0xf5,
+	0xf6, 0xef, 0x75, 0x15, 0x1d, 0x51, 0xa9, 0xc8, 0x51, 0x92, 0x37, 0xdc, 0xd2, 0xc2, 0x8c, 0x87,
+	0x96, 0xb9, 0x58, 0xe4, 0xc5, 0x3b, 0x93, 0xa9, 0xf4, 0xaf, 0x4c, 0xfa, 0xf6, 0xdf, 0x96, 0xbb,
+	0xcb, 0x70, 0x31, 0xd0, 0x61, 0x40, 0xdf, 0xa5, 0x54, 0xaa, 0xee, 0x33, 0xb8, 0x94, 0xc7, 0x32,
+	0xe1, 0xb1, 0xa4, 0xe8, 0x3e, 0xac, 0x85, 0x82, 0xa7, 0x89, 0x74, 0x41, 0xa7, 0xbc, 0xde, 0xdc,
+	0x58, 0xf5, 0xf4, 0x56, 0xbc, 0x97, 0x3a, 0xb7, 0xab, 0x88, 0xa2, 0x5b, 0x54, 0x0e, 0x82, 0xbc,
+	0xa7, 0xfb, 0xb9, 0x04, 0x97, 0x67, 0x4b, 0xe8, 0x1e, 0xac, 0x9a, 0xa2, 0x0b, 0x3a, 0xc0, 0xe0,
+	0xad, 0xbc, 0x56, 0x31, 0x9d, 0x06, 0x6f, 0x5b, 0xd0, 0x63, 0xb8, 0x48, 0x06, 0x2a, 0x1a, 0xd1,
+	0x3d, 0xd3, 0xe4, 0x96, 0x8c, 0xe4, 0x8a, 0x95, 0xd4, 0x88, 0x89, 0x62, 0xd3, 0x36, 0x9a, 0x61,
+	0xd1, 0x5b, 0xb8, 0x42, 0x47, 0x84, 0xa5, 0xc6, 0xb6, 0xd7, 0x85, 0x3d, 0x6e, 0xd9, 0x28, 0xb6,
+	0x3c, 0x6b, 0xa0, 0x57, 0x18, 0xe8, 0x5d, 0x75, 0x6c, 0xd6, 0x4f, 0xce, 0xdb, 0xce, 0xf1, 0xb7,
+	0x36, 0x08, 0xae, 0x23, 0x40, 0xbb, 0x10, 0x4d, 0xd2, 0x5b, 0xf9, 0xb1, 0xb8, 0x15, 0x43, 0xbb,
+	0xf6, 0x07, 0x6d, 0xd1, 0x60, 0x59, 0x3f, 0x6a, 0xd6, 0x6b, 0xe0, 0xe8, 0x7f, 0x58, 0x93, 0x54,
+	0x44, 0x54, 0xba, 0xd5, 0x0e, 0x58, 0x2f, 0x07, 0x79, 0xd4, 0xfd, 0x5a, 0x82, 0x4b, 0x33, 0x7b,
+	0x44, 0x77, 0x61, 0x45, 0xfb, 0x90, 0x3b, 0xf7, 0xdf, 0x94, 0x73, 0xc6, 0x02, 0x53, 0x44, 0xab,
+	0xb0, 0x2a, 0x35, 0xc2, 0x2d, 0x75, 0xc0, 0x7a, 0x23, 0xb0, 0x81, 0x16, 0x39, 0xa0, 0x84, 0xa9,
+	0x03, 0x63, 0x42, 0x23, 0xc8, 0x23, 0x74, 0x1b, 0x36, 0x18, 0x91, 0x6a, 0x5b, 0x08, 0x2e, 0xcc,
+	0x46, 0x1a, 0xc1, 0x24, 0xa1, 0x0f, 0x9b, 0x30, 0x2a, 0x94, 0x1e, 0x6d, 0xea, 0xb0, 0x7b, 0x3a,
+	0x37, 0x75, 0xd8, 0xb6, 0xe7, 0x6f, 0xae, 0xd7, 0x6e, 0xc6, 0xf5, 0x85, 0x7f, 0x72, 0xbd, 0xfb,
+	0xb3, 0x02, 0x97, 0x67, 0xf7, 0x31, 0x71, 0x0e, 0x4c, 0x3b, 0xc7, 0x60, 0x8d, 0x91, 0x3e, 0x65,
+	0xc5, 0xed, 0x5b, 0xf3, 0xae, 0x5e, 0xd4, 0x0e, 0x0d, 0xc9, 0xe0, 0xfd, 0x8e, 0xae, 0xbe, 0x22,
+	0x91, 0xd8, 0x7c, 0xa2, 0x15, 0xbf, 0x9c, 0xb7, 0x1f, 0x84, 0x91, 0x3a, 0x48, 0xfb, 0xde, 0x80,
+	0x1f, 0xf9, 0xa1, 0x20, 0xfb, 0x24, 0x26, 0x3e, 0xe3, 0x87, 0x91, 0x3f, 0xfd, 0x30, 0x3d, 0x83,
+	0xeb, 0x0d, 0x49, 0xa2, 0xa8, 0x08, 0x72, 0x0d, 0x34, 0x82, 0x4d, 0x12, 0xc7, 0x5c, 0x99, 0x21,
+	0xa5, 0x5b, 0xbe, 0x41, 0xc9, 0x69, 0x21, 0xbd, 0x77, 0xed, 0x11, 0x35, 0x77, 0x00, 0x04, 0x36,
+	0x40, 0x3d, 0xd8, 0xc8, 0xdf, 0x1f, 0x51, 0x6e, 0x75, 0x8e, 0x73, 0xac, 0x5b, 0x58, 0x4f, 0xa1,
+	0xe7, 0xb0, 0xbe, 0x1f, 0x09, 0x3a, 0xd4, 0x0c, 0xf3, 0xdc, 0x84, 0x05, 0x83, 0xea, 0x29, 0xb4,
+	0x0d, 0x9b, 0x82, 0x4a, 0xce, 0x46, 0x96, 0x63, 0x61, 0x0e, 0x0e, 0x58, 0x00, 0x7b, 0x0a, 0xbd,
+	0x80, 0x8b, 0xfa, 0x5e, 0xef, 0x49, 0x1a, 0x2b, 0xcd, 0x53, 0x9f, 0x87, 0x47, 0x23, 0x77, 0x69,
+	0xac, 0xec, 0x38, 0x23, 0xc2, 0xa2, 0xe1, 0x5e, 0x1a, 0xab, 0x88, 0xb9, 0x8d, 0x79, 0x68, 0x0c,
+	0xf0, 0x8d, 0xc6, 0x6d, 0x3c, 0x85, 0x55, 0xfd, 0x6e, 0x05, 0xda, 0xb0, 0x0b, 0x89, 0xd0, 0xe4,
+	0xab, 0x56, 0x7c, 0x7d, 0x5b, 0x2b, 0x33, 0x39, 0xfb, 0x05, 0xee, 0x3a, 0x9b, 0x8f, 0x4e, 0x2f,
+	0xb0, 0x73, 0x76, 0x81, 0x9d, 0xcb, 0x0b, 0x0c, 0x3e, 0x64, 0x18, 0x7c, 0xca, 0x30, 0x38, 0xc9,
+	0x30, 0x38, 0xcd, 0x30, 0xf8, 0x9e, 0x61, 0xf0, 0x23, 0xc3, 0xce, 0x65, 0x86, 0xc1, 0xf1, 0x18,
+	0x3b, 0xa7, 0x63, 0xec, 0x9c, 0x8d, 0xb1, 0xd3, 0xaf, 0x99, 0xe1, 0x1e, 0xfe, 0x1a, 0x00, 0x0f,
+	0x77, 0xb0, 0x91, 0x98, 0x06, 0x00, 0x00,
 }
 
 func (this *RulesRequest) Equal(that interface{}) bool {
@@ -512,6 +522,9 @@ func (this *GroupStateDesc) Equal(that interface{}) bool {
 	if this.EvaluationDuration != that1.EvaluationDuration {
 		return false
 	}
+	if this.Series != that1.Series {
+		return false
+	}
 	return true
 }
 func (this *RuleStateDesc) Equal(that interface{}) bool {
@@ -644,7 +657,7 @@ func (this *GroupStateDesc) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 8)
+	s := make([]string, 0, 9)
 	s = append(s, "&base.GroupStateDesc{")
 	if this.Group != nil {
 		s = append(s, "Group: "+fmt.Sprintf("%#v", this.Group)+",\n")
@@ -654,6 +667,7 @@ func (this *GroupStateDesc) GoString() string {
 	}
 	s = append(s, "EvaluationTimestamp: "+fmt.Sprintf("%#v", this.EvaluationTimestamp)+",\n")
 	s = append(s, "EvaluationDuration: "+fmt.Sprintf("%#v", this.EvaluationDuration)+",\n")
+	s = append(s, "Series: "+fmt.Sprintf("%#v", this.Series)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -864,6 +878,11 @@ func (m *GroupStateDesc) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.Series != 0 {
+		i = encodeVarintRuler(dAtA, i, uint64(m.Series))
+		i--
+		dAtA[i] = 0x28
+	}
 	n1, err1 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.EvaluationDuration, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.EvaluationDuration):])
 	if err1 != nil {
 		return 0, err1
@@ -1154,6 +1173,9 @@ func (m *GroupStateDesc) Size() (n int) {
 	n += 1 + l + sovRuler(uint64(l))
 	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.EvaluationDuration)
 	n += 1 + l + sovRuler(uint64(l))
+	if m.Series != 0 {
+		n += 1 + sovRuler(uint64(m.Series))
+	}
 	return n
 }
 
@@ -1274,6 +1296,7 @@ func (this *GroupStateDesc) String() string {
 		`ActiveRules:` + repeatedStringForActiveRules + `,`,
 		`EvaluationTimestamp:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.EvaluationTimestamp), "Timestamp", "types.Timestamp", 1), `&`, ``, 1) + `,`,
 		`EvaluationDuration:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.EvaluationDuration), "Duration", "duration.Duration", 1), `&`, ``, 1) + `,`,
+		`Series:` + fmt.Sprintf("%v", this.Series) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -1630,6 +1653,25 @@ func (m *GroupStateDesc) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Series", wireType)
+			}
+			m.Series = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRuler
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Series |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRuler(dAtA[iNdEx:])
@@ -49,6 +49,7 @@ import (
 	"github.com/grafana/loki/pkg/ruler/rulespb"
 	"github.com/grafana/loki/pkg/ruler/rulestore"
 	"github.com/grafana/loki/pkg/ruler/rulestore/objectclient"
+	ruler_util "github.com/grafana/loki/pkg/ruler/util"
 	loki_storage "github.com/grafana/loki/pkg/storage"
 	"github.com/grafana/loki/pkg/storage/chunk/client/hedging"
 	"github.com/grafana/loki/pkg/storage/chunk/client/testutils"
@@ -85,7 +86,11 @@ type ruleLimits struct {
 	tenantShard          int
 	maxRulesPerRuleGroup int
 	maxRuleGroups        int
+	minEvalInterval      time.Duration
 	alertManagerConfig   map[string]*config.AlertManagerConfig
+	alertRelabelConfigs  map[string][]*ruler_util.RelabelConfig
+	externalLabels       map[string]labels.Labels
+	externalURL          map[string]string
 }
 
 func (r ruleLimits) EvaluationDelay(_ string) time.Duration {
@@ -104,10 +109,26 @@ func (r ruleLimits) RulerMaxRulesPerRuleGroup(_ string) int {
 	return r.maxRulesPerRuleGroup
 }
 
+func (r ruleLimits) RulerMinEvaluationInterval(_ string) time.Duration {
+	return r.minEvalInterval
+}
+
 func (r ruleLimits) RulerAlertManagerConfig(tenantID string) *config.AlertManagerConfig {
 	return r.alertManagerConfig[tenantID]
 }
 
+func (r ruleLimits) RulerAlertRelabelConfigs(tenantID string) []*ruler_util.RelabelConfig {
+	return r.alertRelabelConfigs[tenantID]
+}
+
+func (r ruleLimits) RulerExternalLabels(tenantID string) labels.Labels {
+	return r.externalLabels[tenantID]
+}
+
+func (r ruleLimits) RulerExternalURL(tenantID string) string {
+	return r.externalURL[tenantID]
+}
+
 func testQueryableFunc(q storage.Querier) storage.QueryableFunc {
 	if q != nil {
 		return func(ctx context.Context, mint, maxt int64) (storage.Querier, error) {
@@ -1482,6 +1503,37 @@ func TestSharding(t *testing.T) {
 			addToActual(ruler3, r3)
 
 			require.Equal(t, tc.expectedRules, actual)
+
+			if !tc.sharding {
+				_, err := r1.RuleGroupOwners(user1, allRules[user1])
+				require.Error(t, err)
+				return
+			}
+
+			// The owners of the rule groups are the rulers loading them.
+			addrs := map[string]string{ruler1: ruler1Addr, ruler2: ruler2Addr, ruler3: ruler3Addr}
+			for id, users := range tc.expectedRules {
+				for user, groups := range users {
+					owners, err := r1.RuleGroupOwners(user, allRules[user])
+					require.NoError(t, err)
+
+					for _, g := range groups {
+						var owner string
+						for _, o := range owners {
+							if o.Namespace != g.Namespace || o.Name != RemoveRuleTokenFromGroupName(g.Name) {
+								continue
+							}
+							owner = o.Owner
+							for _, ro := range o.Rules {
+								if ro.Name == getRuleIdentifier(g.Rules[0]) {
+									owner = ro.Owner
+								}
+							}
+						}
+						require.Equal(t, addrs[id], owner, "owner of the rule group %s/%s of %s", g.Namespace, g.Name, user)
+					}
+				}
+			}
 		})
 	}
 }
@@ -110,7 +110,7 @@ func NewLegacyRuleStore(cfg RuleStoreConfig, hedgeCfg hedging.Config, clientMetr
 	case "local":
 		return local.NewLocalRulesClient(cfg.Local, loader)
 	default:
-		return nil, fmt.Errorf("unrecognized rule storage mode %v, choose one of: configdb, gcs, s3, swift, azure, local", cfg.Type)
+		return nil, fmt.Errorf("unrecognized rule storage mode %v, choose one of: configdb, gcs, s3, swift, azure, bos, cos, local", cfg.Type)
 	}
 
 	if err != nil {
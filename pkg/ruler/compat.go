@@ -35,12 +35,14 @@ import (
 type RulesLimits interface {
 	ruler.RulesLimits
 
+	RulerMaxConcurrentEvaluations(userID string) int
 	RulerRemoteWriteDisabled(userID string) bool
 	RulerRemoteWriteURL(userID string) string
 	RulerRemoteWriteTimeout(userID string) time.Duration
 	RulerRemoteWriteHeaders(userID string) map[string]string
 	RulerRemoteWriteRelabelConfigs(userID string) []*util.RelabelConfig
 	RulerRemoteWriteConfig(userID string, id string) *config.RemoteWriteConfig
+	RulerRemoteWriteClientIDs(userID string) []string
 	RulerRemoteWriteQueueCapacity(userID string) int
 	RulerRemoteWriteQueueMinShards(userID string) int
 	RulerRemoteWriteQueueMaxShards(userID string) int
@@ -111,6 +113,10 @@ func (m *MultiTenantManager) GetRules(userID string) []*rules.Group {
 	return m.inner.GetRules(userID)
 }
 
+func (m *MultiTenantManager) GetRuleGroupSamples(userID string) map[string]float64 {
+	return m.inner.GetRuleGroupSamples(userID)
+}
+
 func (m *MultiTenantManager) Stop() {
 	if registry != nil {
 		registry.stop()
@@ -146,18 +152,26 @@ func MultiTenantRuleManager(cfg Config, evaluator Evaluator, overrides RulesLimi
 		logger = log.With(logger, "user", userID)
 		queryFn := queryFunc(evaluator, overrides, registry, userID, logger)
 		memStore := NewMemStore(userID, queryFn, newMemstoreMetrics(reg), 5*time.Minute, log.With(logger, "subcomponent", "MemStore"))
+		if cfg.AlertStateDirectory != "" {
+			state, err := loadAlertState(cfg.AlertStateDirectory, userID)
+			if err != nil {
+				level.Warn(logger).Log("msg", "unable to load the persisted alert state", "err", err)
+			}
+			memStore.restoreAlertState(state)
+		}
 
 		// GroupLoader builds a cache of the rules as they're loaded by the
 		// manager.This is used to back the memstore
 		groupLoader := NewCachingGroupLoader(GroupLoader{})
 
+		externalURL := ruler.TenantExternalURL(cfg.Config, overrides, userID)
 		mgr := rules.NewManager(&rules.ManagerOptions{
 			Appendable:      registry,
 			Queryable:       memStore,
 			QueryFunc:       queryFn,
 			Context:         user.InjectOrgID(ctx, userID),
-			ExternalURL:     cfg.ExternalURL.URL,
-			NotifyFunc:      ruler.SendAlerts(notifier, cfg.ExternalURL.URL.String(), cfg.DatasourceUID),
+			ExternalURL:     externalURL.URL,
+			NotifyFunc:      ruler.SendAlerts(notifier, externalURL.URL.String(), cfg.DatasourceUID),
 			Logger:          logger,
 			Registerer:      reg,
 			OutageTolerance: cfg.OutageTolerance,
@@ -169,6 +183,10 @@ func MultiTenantRuleManager(cfg Config, evaluator Evaluator, overrides RulesLimi
 		cachingManager := &CachingRulesManager{
 			manager:     mgr,
 			groupLoader: groupLoader,
+			memStore:    memStore,
+			userID:      userID,
+			stateDir:    cfg.AlertStateDirectory,
+			logger:      logger,
 		}
 
 		memStore.Start(groupLoader)
@@ -183,13 +201,20 @@ func MultiTenantRuleManager(cfg Config, evaluator Evaluator, overrides RulesLimi
 type CachingRulesManager struct {
 	manager     ruler.RulesManager
 	groupLoader *CachingGroupLoader
+	// the persisted alert state of the memStore is dropped once restored.
+	memStore *MemStore
+
+	// the alert state of the tenant is persisted in stateDir when the manager stops, if set.
+	userID   string
+	stateDir string
+	logger   log.Logger
 }
 
 // Update reconciles the state of the CachingGroupLoader after a manager.Update.
 // The GroupLoader is mutated as part of a call to Update but it might still
 // contain removed files. Update tells the loader which files to keep
 func (m *CachingRulesManager) Update(interval time.Duration, files []string, externalLabels labels.Labels, externalURL string, ruleGroupPostProcessFunc rules.GroupEvalIterationFunc) error {
-	err := m.manager.Update(interval, files, externalLabels, externalURL, ruleGroupPostProcessFunc)
+	err := m.manager.Update(interval, files, externalLabels, externalURL, m.memStore.dropRestoredAlertState(ruleGroupPostProcessFunc))
 	if err != nil {
 		return err
 	}
@@ -203,6 +228,11 @@ func (m *CachingRulesManager) Run() {
 }
 
 func (m *CachingRulesManager) Stop() {
+	if m.stateDir != "" {
+		if err := saveAlertState(m.stateDir, m.userID, m.manager.RuleGroups(), time.Now()); err != nil {
+			level.Warn(m.logger).Log("msg", "unable to persist the alert state", "err", err)
+		}
+	}
 	m.manager.Stop()
 }
 
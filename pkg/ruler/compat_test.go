@@ -47,7 +47,7 @@ func TestNonMetricQuery(t *testing.T) {
 
 	log := log.Logger
 	engine := logql.NewEngine(logql.EngineOpts{}, &FakeQuerier{}, overrides, log)
-	eval, err := NewLocalEvaluator(engine, log)
+	eval, err := NewLocalEvaluator(engine, nil, log)
 	require.NoError(t, err)
 
 	queryFunc := queryFunc(eval, overrides, fakeChecker{}, "fake", log)
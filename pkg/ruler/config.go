@@ -24,6 +24,8 @@ type Config struct {
 	RemoteWrite RemoteWriteConfig `yaml:"remote_write,omitempty" doc:"description=Remote-write configuration to send rule samples to a Prometheus remote-write endpoint."`
 
 	Evaluation EvaluationConfig `yaml:"evaluation,omitempty" doc:"description=Configuration for rule evaluation."`
+
+	AlertStateDirectory string `yaml:"alert_state_directory"`
 }
 
 func (c *Config) RegisterFlags(f *flag.FlagSet) {
@@ -32,6 +34,7 @@ func (c *Config) RegisterFlags(f *flag.FlagSet) {
 	c.WAL.RegisterFlags(f)
 	c.WALCleaner.RegisterFlags(f)
 	c.Evaluation.RegisterFlags(f)
+	f.StringVar(&c.AlertStateDirectory, "ruler.alert-state-directory", "", "Directory to persist the state of the active alerts of the tenants in when the ruler stops, to restore the \"for\" state of the alerts on startup within the \"for\" outage tolerance. When empty, the \"for\" state is restored by evaluating the alerting rules.")
 
 	// TODO(owen-d, 3.0.0): remove deprecated experimental prefix in Cortex if they'll accept it.
 	f.BoolVar(&c.Config.EnableAPI, "ruler.enable-api", true, "Enable the ruler API.")
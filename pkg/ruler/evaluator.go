@@ -19,6 +19,7 @@ type Evaluator interface {
 type EvaluationConfig struct {
 	Mode      string        `yaml:"mode,omitempty"`
 	MaxJitter time.Duration `yaml:"max_jitter"`
+	Alignment time.Duration `yaml:"alignment"`
 
 	QueryFrontend QueryFrontendConfig `yaml:"query_frontend,omitempty"`
 }
@@ -26,6 +27,7 @@ type EvaluationConfig struct {
 func (c *EvaluationConfig) RegisterFlags(f *flag.FlagSet) {
 	f.StringVar(&c.Mode, "ruler.evaluation.mode", EvalModeLocal, "The evaluation mode for the ruler. Can be either 'local' or 'remote'. If set to 'local', the ruler will evaluate rules locally. If set to 'remote', the ruler will evaluate rules remotely. If unset, the ruler will evaluate rules locally.")
 	f.DurationVar(&c.MaxJitter, "ruler.evaluation.max-jitter", 0, "Upper bound of random duration to wait before rule evaluation to avoid contention during concurrent execution of rules. Jitter is calculated consistently for a given rule. Set 0 to disable (default).")
+	f.DurationVar(&c.Alignment, "ruler.evaluation.alignment", 0, "Align the evaluation timestamps of the rules to a multiple of this duration, so the series recorded by rule groups of the same interval have the same timestamps across rule groups and tenants. Must not be greater than the shortest evaluation interval of the rule groups. Set 0 to disable (default).")
 	c.QueryFrontend.RegisterFlags(f)
 }
 
@@ -33,6 +35,9 @@ func (c *EvaluationConfig) Validate() error {
 	if c.Mode != EvalModeLocal && c.Mode != EvalModeRemote {
 		return fmt.Errorf("invalid evaluation mode: %s. Acceptable modes are: %s", c.Mode, strings.Join([]string{EvalModeLocal, EvalModeRemote}, ", "))
 	}
+	if c.Alignment < 0 {
+		return fmt.Errorf("invalid evaluation alignment: %s. The alignment must not be negative", c.Alignment)
+	}
 
 	return nil
 }
@@ -0,0 +1,34 @@
+package ruler
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/loki/pkg/logqlmodel"
+)
+
+// EvaluatorWithAlignment wraps a given Evaluator. It evaluates the rules at the evaluation timestamp truncated to a
+// multiple of the alignment, so the series recorded by rule groups of the same interval have the same timestamps,
+// whatever the offset of the groups, e.g. to compare or aggregate the series recorded for different tenants.
+// The alignment must not be greater than the interval of the rule groups, or consecutive evaluations would have the
+// same timestamp.
+type EvaluatorWithAlignment struct {
+	inner     Evaluator
+	alignment time.Duration
+}
+
+func NewEvaluatorWithAlignment(inner Evaluator, alignment time.Duration) Evaluator {
+	if alignment <= 0 {
+		// alignment is disabled or invalid
+		return inner
+	}
+
+	return &EvaluatorWithAlignment{
+		inner:     inner,
+		alignment: alignment,
+	}
+}
+
+func (e *EvaluatorWithAlignment) Eval(ctx context.Context, qs string, now time.Time) (*logqlmodel.Result, error) {
+	return e.inner.Eval(ctx, qs, now.Truncate(e.alignment))
+}
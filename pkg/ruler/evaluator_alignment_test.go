@@ -0,0 +1,31 @@
+package ruler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/logqlmodel"
+)
+
+type timestampEval struct {
+	ts time.Time
+}
+
+func (e *timestampEval) Eval(_ context.Context, _ string, now time.Time) (*logqlmodel.Result, error) {
+	e.ts = now
+	return nil, nil
+}
+
+func TestEvaluationWithAlignment(t *testing.T) {
+	inner := &timestampEval{}
+	require.Same(t, inner, NewEvaluatorWithAlignment(inner, 0))
+
+	eval := NewEvaluatorWithAlignment(inner, time.Minute)
+	now := time.Date(2023, 5, 1, 10, 30, 42, 500, time.UTC)
+	_, err := eval.Eval(context.Background(), "some logql query...", now)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2023, 5, 1, 10, 30, 0, 0, time.UTC), inner.ts)
+}
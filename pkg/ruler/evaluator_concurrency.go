@@ -0,0 +1,87 @@
+package ruler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/tenant"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/loki/pkg/logqlmodel"
+)
+
+// EvaluatorWithConcurrencyLimit wraps a given Evaluator. It limits the number of rules of a tenant evaluated concurrently
+// to the ruler_max_concurrent_evaluations limit of the tenant. The evaluations exceeding the limit are throttled: they wait
+// for a running evaluation of the tenant to complete, so a tenant with many rules can't use all the resources of the ruler.
+type EvaluatorWithConcurrencyLimit struct {
+	mu sync.Mutex
+	// the semaphores of the tenants, sized by their limit.
+	semaphores map[string]chan struct{}
+
+	inner     Evaluator
+	limits    RulesLimits
+	throttled *prometheus.CounterVec
+	logger    log.Logger
+}
+
+func NewEvaluatorWithConcurrencyLimit(inner Evaluator, limits RulesLimits, logger log.Logger, registerer prometheus.Registerer) Evaluator {
+	return &EvaluatorWithConcurrencyLimit{
+		semaphores: map[string]chan struct{}{},
+		inner:      inner,
+		limits:     limits,
+		throttled: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki",
+			Subsystem: "ruler",
+			Name:      "evaluations_throttled_total",
+			Help:      "Total number of rule evaluations throttled by the concurrent evaluations limit of the tenant.",
+		}, []string{"user"}),
+		logger: logger,
+	}
+}
+
+func (e *EvaluatorWithConcurrencyLimit) Eval(ctx context.Context, qs string, now time.Time) (*logqlmodel.Result, error) {
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := e.limits.RulerMaxConcurrentEvaluations(userID)
+	if limit <= 0 {
+		return e.inner.Eval(ctx, qs, now)
+	}
+
+	sem := e.semaphore(userID, limit)
+	select {
+	case sem <- struct{}{}:
+	default:
+		e.throttled.WithLabelValues(userID).Inc()
+		level.Debug(e.logger).Log("msg", "throttling rule evaluation", "user", userID, "limit", limit)
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	defer func() { <-sem }()
+
+	return e.inner.Eval(ctx, qs, now)
+}
+
+// semaphore returns the semaphore of the tenant, replacing it when the limit of the tenant changed.
+// The running evaluations release the semaphore they acquired.
+func (e *EvaluatorWithConcurrencyLimit) semaphore(userID string, limit int) chan struct{} {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	sem, ok := e.semaphores[userID]
+	if !ok || cap(sem) != limit {
+		sem = make(chan struct{}, limit)
+		e.semaphores[userID] = sem
+	}
+	return sem
+}
@@ -0,0 +1,99 @@
+package ruler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+	"go.uber.org/atomic"
+
+	"github.com/grafana/loki/pkg/logqlmodel"
+	"github.com/grafana/loki/pkg/util/test"
+	"github.com/grafana/loki/pkg/validation"
+)
+
+type blockingEval struct {
+	running, maxRunning atomic.Int32
+	release             chan struct{}
+}
+
+func (b *blockingEval) Eval(context.Context, string, time.Time) (*logqlmodel.Result, error) {
+	running := b.running.Inc()
+	defer b.running.Dec()
+	for {
+		max := b.maxRunning.Load()
+		if running <= max || b.maxRunning.CAS(max, running) {
+			break
+		}
+	}
+
+	<-b.release
+	return nil, nil
+}
+
+func TestEvaluationWithConcurrencyLimit(t *testing.T) {
+	defaultLimits := defaultLimitsTestConfig()
+	defaultLimits.RulerMaxConcurrentEvaluations = 2
+	limits, err := validation.NewOverrides(defaultLimits, nil)
+	require.NoError(t, err)
+
+	inner := &blockingEval{release: make(chan struct{})}
+	eval := NewEvaluatorWithConcurrencyLimit(inner, limits, log.NewNopLogger(), prometheus.NewRegistry())
+	ctx := user.InjectOrgID(context.Background(), "test")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := eval.Eval(ctx, "sum(rate({foo=\"bar\"}[5m]))", time.Now())
+			require.NoError(t, err)
+		}()
+	}
+
+	// Two evaluations are running and the two others are throttled.
+	throttled := eval.(*EvaluatorWithConcurrencyLimit).throttled.WithLabelValues("test")
+	test.Poll(t, time.Second, float64(2), func() interface{} {
+		return testutil.ToFloat64(throttled)
+	})
+	require.Equal(t, int32(2), inner.running.Load())
+
+	close(inner.release)
+	wg.Wait()
+	require.Equal(t, int32(2), inner.maxRunning.Load())
+}
+
+func TestEvaluationWithConcurrencyLimitCanceled(t *testing.T) {
+	defaultLimits := defaultLimitsTestConfig()
+	defaultLimits.RulerMaxConcurrentEvaluations = 1
+	limits, err := validation.NewOverrides(defaultLimits, nil)
+	require.NoError(t, err)
+
+	inner := &blockingEval{release: make(chan struct{})}
+	defer close(inner.release)
+	eval := NewEvaluatorWithConcurrencyLimit(inner, limits, log.NewNopLogger(), prometheus.NewRegistry())
+	ctx := user.InjectOrgID(context.Background(), "test")
+
+	go func() {
+		_, _ = eval.Eval(ctx, "sum(rate({foo=\"bar\"}[5m]))", time.Now())
+	}()
+	test.Poll(t, time.Second, int32(1), func() interface{} {
+		return inner.running.Load()
+	})
+
+	// The throttled evaluation returns when its context is canceled.
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = eval.Eval(ctx, "sum(rate({foo=\"bar\"}[5m]))", time.Now())
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The evaluations without tenant are rejected.
+	_, err = eval.Eval(context.Background(), "sum(rate({foo=\"bar\"}[5m]))", time.Now())
+	require.Error(t, err)
+}
@@ -3,28 +3,34 @@ package ruler
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-kit/log"
+	"github.com/grafana/dskit/tenant"
 
 	"github.com/grafana/loki/pkg/logproto"
 	"github.com/grafana/loki/pkg/logql"
 	"github.com/grafana/loki/pkg/logqlmodel"
+	"github.com/grafana/loki/pkg/util/audit"
+	"github.com/grafana/loki/pkg/util/server"
 )
 
 const EvalModeLocal = "local"
 
 type LocalEvaluator struct {
-	engine *logql.Engine
-	logger log.Logger
+	engine      *logql.Engine
+	auditLogger *audit.Logger
+	logger      log.Logger
 }
 
-func NewLocalEvaluator(engine *logql.Engine, logger log.Logger) (*LocalEvaluator, error) {
+func NewLocalEvaluator(engine *logql.Engine, auditLogger *audit.Logger, logger log.Logger) (*LocalEvaluator, error) {
 	if engine == nil {
 		return nil, fmt.Errorf("given engine is nil")
 	}
 
-	return &LocalEvaluator{engine: engine, logger: logger}, nil
+	return &LocalEvaluator{engine: engine, auditLogger: auditLogger, logger: logger}, nil
 }
 
 func (l *LocalEvaluator) Eval(ctx context.Context, qs string, now time.Time) (*logqlmodel.Result, error) {
@@ -39,11 +45,39 @@ func (l *LocalEvaluator) Eval(ctx context.Context, qs string, now time.Time) (*l
 		nil,
 	)
 
+	start := time.Now()
 	q := l.engine.Query(params)
 	res, err := q.Exec(ctx)
+	l.audit(ctx, params, res, err, time.Since(start))
 	if err != nil {
 		return nil, err
 	}
 
 	return &res, nil
 }
+
+// audit logs the audit entry of an evaluated rule query.
+func (l *LocalEvaluator) audit(ctx context.Context, params logql.Params, res logqlmodel.Result, err error, duration time.Duration) {
+	if l.auditLogger == nil {
+		return
+	}
+
+	orgID, _ := tenant.TenantID(ctx)
+	status := http.StatusOK
+	if err != nil {
+		status, _ = server.ClientHTTPStatusAndError(err)
+	}
+
+	l.auditLogger.Log(audit.Entry{
+		Tenant:   orgID,
+		Query:    params.Query(),
+		Start:    params.Start(),
+		End:      params.End(),
+		Step:     params.Step(),
+		Source:   audit.SourceRuler,
+		Duration: duration,
+		Bytes:    res.Statistics.Summary.TotalBytesProcessed,
+		Chunks:   res.Statistics.TotalChunksDownloaded(),
+		Status:   strconv.Itoa(status),
+	})
+}
@@ -16,6 +16,7 @@ import (
 	"net/textproto"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-kit/log"
@@ -23,6 +24,7 @@ import (
 	"github.com/grafana/dskit/crypto/tls"
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	otgrpc "github.com/opentracing-contrib/go-grpc"
+	"github.com/opentracing-contrib/go-stdlib/nethttp"
 	"github.com/opentracing/opentracing-go"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
@@ -55,7 +57,7 @@ const (
 )
 
 var (
-	userAgent = fmt.Sprintf("loki-ruler/%s", build.Version)
+	userAgent = httpreq.RulerUserAgentPrefix + build.Version
 )
 
 type metrics struct {
@@ -167,7 +169,12 @@ func (r *RemoteEvaluator) Eval(ctx context.Context, qs string, now time.Time) (*
 }
 
 // DialQueryFrontend creates and initializes a new httpgrpc.HTTPClient taking a QueryFrontendConfig configuration.
+// The queries are sent over HTTP when the address is an HTTP URL, or over gRPC otherwise.
 func DialQueryFrontend(cfg *QueryFrontendConfig) (httpgrpc.HTTPClient, error) {
+	if strings.HasPrefix(cfg.Address, "http://") || strings.HasPrefix(cfg.Address, "https://") {
+		return newHTTPQueryFrontendClient(cfg)
+	}
+
 	tlsDialOptions, err := cfg.TLS.GetGRPCDialOptions(cfg.TLSEnabled)
 	if err != nil {
 		return nil, err
@@ -199,6 +206,62 @@ func DialQueryFrontend(cfg *QueryFrontendConfig) (httpgrpc.HTTPClient, error) {
 	return httpgrpc.NewHTTPClient(conn), nil
 }
 
+// httpQueryFrontendClient sends the httpgrpc requests to the query-frontend over HTTP.
+type httpQueryFrontendClient struct {
+	address string
+	client  *http.Client
+}
+
+func newHTTPQueryFrontendClient(cfg *QueryFrontendConfig) (*httpQueryFrontendClient, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.TLSEnabled {
+		tlsConfig, err := cfg.TLS.GetTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	return &httpQueryFrontendClient{
+		address: strings.TrimSuffix(cfg.Address, "/"),
+		client:  &http.Client{Transport: &nethttp.Transport{RoundTripper: transport}},
+	}, nil
+}
+
+func (c *httpQueryFrontendClient) Handle(ctx context.Context, in *httpgrpc.HTTPRequest, _ ...grpc.CallOption) (*httpgrpc.HTTPResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, in.Method, c.address+in.Url, bytes.NewReader(in.Body))
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range in.Headers {
+		for _, v := range h.Values {
+			req.Header.Add(h.Key, v)
+		}
+	}
+
+	req, ht := nethttp.TraceRequest(opentracing.GlobalTracer(), req)
+	defer ht.Finish()
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	headers := make([]*httpgrpc.Header, 0, len(resp.Header))
+	for k, v := range resp.Header {
+		headers = append(headers, &httpgrpc.Header{Key: k, Values: v})
+	}
+	return &httpgrpc.HTTPResponse{
+		Code:    int32(resp.StatusCode),
+		Headers: headers,
+		Body:    body,
+	}, nil
+}
+
 // Middleware provides a mechanism to inspect outgoing remote querier requests.
 type Middleware func(ctx context.Context, req *httpgrpc.HTTPRequest) error
 
@@ -353,7 +416,7 @@ type QueryFrontendConfig struct {
 }
 
 func (c *QueryFrontendConfig) RegisterFlags(f *flag.FlagSet) {
-	f.StringVar(&c.Address, "ruler.evaluation.query-frontend.address", "", "GRPC listen address of the query-frontend(s). Must be a DNS address (prefixed with dns:///) to enable client side load balancing.")
+	f.StringVar(&c.Address, "ruler.evaluation.query-frontend.address", "", "GRPC listen address of the query-frontend(s). Must be a DNS address (prefixed with dns:///) to enable client side load balancing. The queries are sent over HTTP instead when the address is an HTTP URL, e.g. http://query-frontend:3100.")
 	f.BoolVar(&c.TLSEnabled, "ruler.evaluation.query-frontend.tls-enabled", false, "Set to true if query-frontend connection requires TLS.")
 
 	c.TLS.RegisterFlagsWithPrefix("ruler.evaluation.query-frontend", f)
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -155,6 +156,46 @@ func TestRemoteEvalScalar(t *testing.T) {
 	require.Equal(t, now.Unix(), res.Data.(promql.Scalar).T)
 }
 
+func TestRemoteEvalHTTPQueryFrontend(t *testing.T) {
+	defaultLimits := defaultLimitsTestConfig()
+	limits, err := validation.NewOverrides(defaultLimits, nil)
+	require.NoError(t, err)
+
+	now := time.Now()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, queryEndpointPath, r.URL.Path)
+		require.Equal(t, "test", r.Header.Get(user.OrgIDHeaderName))
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, "19", r.PostForm.Get("query"))
+
+		resp := loghttp.QueryResponse{
+			Status: loghttp.QueryStatusSuccess,
+			Data: loghttp.QueryResponseData{
+				ResultType: loghttp.ResultTypeScalar,
+				Result: loghttp.Scalar{
+					Value:     model.SampleValue(19),
+					Timestamp: model.TimeFromUnixNano(now.UnixNano()),
+				},
+			},
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(resp))
+	}))
+	defer srv.Close()
+
+	cli, err := DialQueryFrontend(&QueryFrontendConfig{Address: srv.URL})
+	require.NoError(t, err)
+	require.IsType(t, &httpQueryFrontendClient{}, cli)
+
+	ev, err := NewRemoteEvaluator(cli, limits, log.Logger, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	res, err := ev.Eval(ctx, "19", now)
+	require.NoError(t, err)
+	require.IsType(t, promql.Scalar{}, res.Data)
+	require.EqualValues(t, 19, res.Data.(promql.Scalar).V)
+}
+
 // TestRemoteEvalEmptyScalarResponse validates that an empty scalar response is valid and does not cause an error
 func TestRemoteEvalEmptyScalarResponse(t *testing.T) {
 	defaultLimits := defaultLimitsTestConfig()
@@ -71,6 +71,10 @@ type MemStore struct {
 	logger    log.Logger
 	rules     map[string]*RuleCache
 
+	// the persisted state of the active alerts by alert name, restoring their "for" state instead of evaluating their rules.
+	persisted   map[string]map[string]persistedAlert
+	persistedAt int64
+
 	initiated       chan struct{}
 	done            chan struct{}
 	cleanupInterval time.Duration
@@ -92,6 +96,39 @@ func NewMemStore(userID string, queryFunc rules.QueryFunc, metrics *memstoreMetr
 
 }
 
+// restoreAlertState sets the persisted state of the active alerts of the tenant, restoring their "for" state.
+// It must be called before Start.
+func (m *MemStore) restoreAlertState(state alertState) {
+	m.persisted = make(map[string]map[string]persistedAlert)
+	for _, a := range state.Alerts {
+		name := a.Labels.Get(labels.AlertName)
+		if m.persisted[name] == nil {
+			m.persisted[name] = make(map[string]persistedAlert)
+		}
+		m.persisted[name][a.Labels.String()] = a
+	}
+	m.persistedAt = state.Timestamp
+}
+
+// dropRestoredAlertState wraps the evaluation of the rule groups, dropping the persisted state of the alerts
+// of a rule group once the rule group has evaluated after restoring their "for" state.
+func (m *MemStore) dropRestoredAlertState(next rules.GroupEvalIterationFunc) rules.GroupEvalIterationFunc {
+	if next == nil {
+		next = rules.DefaultEvalIterationFunc
+	}
+	return func(ctx context.Context, g *rules.Group, evalTimestamp time.Time) {
+		next(ctx, g, evalTimestamp)
+
+		m.mtx.Lock()
+		defer m.mtx.Unlock()
+		for _, rule := range g.AlertingRules() {
+			if rule.Restored() {
+				delete(m.persisted, rule.Name())
+			}
+		}
+	}
+}
+
 // Calling Start will set the RuleIter, unblock the MemStore, and start the run() function in a separate goroutine.
 func (m *MemStore) Start(iter RuleIter) {
 	m.mgr = iter
@@ -158,17 +195,25 @@ func (m *MemStore) run() {
 
 			}
 
+			// the persisted state of the alerts of the rules no longer being tracked is never restored.
+			for name := range m.persisted {
+				if _, ok := holdDurs[name]; !ok {
+					delete(m.persisted, name)
+				}
+			}
+
 			m.mtx.Unlock()
 		}
 	}
 }
 
 // implement storage.Queryable. It is only called with the desired ts as maxtime. Mint is
-// parameterized via the outage tolerance, but since we're synthetically generating these,
-// we only care about the desired time.
-func (m *MemStore) Querier(ctx context.Context, _, maxt int64) (storage.Querier, error) {
+// parameterized via the outage tolerance: since we're synthetically generating these,
+// we only care about the desired time, but the persisted alert state must be newer than mint.
+func (m *MemStore) Querier(ctx context.Context, mint, maxt int64) (storage.Querier, error) {
 	<-m.initiated
 	return &memStoreQuerier{
+		mint:     mint,
 		ts:       util.TimeFromMillis(maxt),
 		MemStore: m,
 		ctx:      ctx,
@@ -177,8 +222,9 @@ func (m *MemStore) Querier(ctx context.Context, _, maxt int64) (storage.Querier,
 }
 
 type memStoreQuerier struct {
-	ts  time.Time
-	ctx context.Context
+	mint int64
+	ts   time.Time
+	ctx  context.Context
 	*MemStore
 }
 
@@ -202,6 +248,21 @@ func (m *memStoreQuerier) Select(_ bool, _ *storage.SelectHints, matchers ...*la
 		return storage.NoopSeriesSet()
 	}
 
+	// the alert was active when its state was persisted, within the outage tolerance.
+	m.mtx.Lock()
+	a, ok := m.persisted[ruleKey][ls.String()]
+	m.mtx.Unlock()
+	if ok && m.persistedAt >= m.mint {
+		level.Debug(m.logger).Log("msg", "restoring for state from the persisted alert state", "rule", ruleKey)
+		return series.NewConcreteSeriesSet(
+			[]storage.Series{
+				series.NewConcreteSeries(a.Labels, []model.SamplePair{
+					{Timestamp: model.Time(m.persistedAt), Value: model.SampleValue(a.ActiveAt)},
+				}),
+			},
+		)
+	}
+
 	rule, ok := m.findRule(ruleKey)
 	if !ok {
 		level.Error(m.logger).Log("msg", "failure trying to restore for state for untracked alerting rule", "name", ruleKey)
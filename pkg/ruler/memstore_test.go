@@ -11,6 +11,7 @@ import (
 	"github.com/go-kit/log"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/promql/parser"
 	"github.com/prometheus/prometheus/rules"
 	"github.com/prometheus/prometheus/tsdb/chunkenc"
 	"github.com/stretchr/testify/require"
@@ -196,3 +197,107 @@ func TestMemstoreBlocks(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestSelectRestoresPersistedAlertState(t *testing.T) {
+	dir := t.TempDir()
+	activeAt := time.Now().Add(-30 * time.Minute).Truncate(time.Second)
+	tNow := time.Now()
+
+	expr, err := parser.ParseExpr("up")
+	require.NoError(t, err)
+	rule := rules.NewAlertingRule(ruleName, expr, time.Hour, 0, labels.FromStrings("foo", "bar"), labels.EmptyLabels(), labels.EmptyLabels(), "", false, log.NewNopLogger())
+	_, err = rule.Eval(context.Background(), activeAt, func(_ context.Context, _ string, t time.Time) (promql.Vector, error) {
+		return promql.Vector{{Metric: labels.FromStrings(labels.MetricName, "up", "bazz", "buzz"), T: util.TimeToMillis(t), F: 1}}, nil
+	}, nil, 0)
+	require.NoError(t, err)
+	group := rules.NewGroup(rules.GroupOptions{
+		Name:  "group",
+		Rules: []rules.Rule{rule},
+		Opts:  &rules.ManagerOptions{Logger: log.NewNopLogger()},
+	})
+
+	require.NoError(t, saveAlertState(dir, "test", []*rules.Group{group}, tNow))
+	state, err := loadAlertState(dir, "test")
+	require.NoError(t, err)
+
+	callCount := 0
+	store := testStore(func(ctx context.Context, qs string, t time.Time) (promql.Vector, error) {
+		callCount++
+		return nil, nil
+	})
+	store.restoreAlertState(state)
+	store.Start(MockRuleIter([]rulefmt.Rule{{Alert: ruleName, Expr: "unused", For: model.Duration(time.Hour)}}))
+
+	ls := ForStateMetric(labels.FromStrings("foo", "bar", "bazz", "buzz"), ruleName)
+
+	// The sample of the persisted state is at the time the state was persisted, with the active time of the alert.
+	q, err := store.Querier(context.Background(), util.TimeToMillis(tNow.Add(-time.Hour)), util.TimeToMillis(time.Now()))
+	require.NoError(t, err)
+	sset := q.Select(false, nil, labelsToMatchers(ls)...)
+	require.True(t, sset.Next())
+	require.Equal(t, ls, sset.At().Labels())
+	iter := sset.At().Iterator(nil)
+	require.Equal(t, chunkenc.ValFloat, iter.Next())
+	ts, v := iter.At()
+	require.Equal(t, util.TimeToMillis(tNow), ts)
+	require.Equal(t, float64(activeAt.Unix()), v)
+	require.False(t, sset.Next())
+	require.Equal(t, 0, callCount)
+
+	// The persisted state older than the outage tolerance is not restored.
+	q, err = store.Querier(context.Background(), util.TimeToMillis(tNow.Add(time.Second)), util.TimeToMillis(time.Now()))
+	require.NoError(t, err)
+	sset = q.Select(false, nil, labelsToMatchers(ls)...)
+	require.False(t, sset.Next())
+	require.Equal(t, 1, callCount)
+
+	// The state without active alerts removes the persisted state.
+	require.NoError(t, saveAlertState(dir, "test", nil, tNow))
+	state, err = loadAlertState(dir, "test")
+	require.NoError(t, err)
+	require.Empty(t, state.Alerts)
+}
+
+func TestDropRestoredAlertState(t *testing.T) {
+	tNow := time.Now()
+	ls := ForStateMetric(labels.FromStrings("foo", "bar"), ruleName)
+
+	expr, err := parser.ParseExpr("up")
+	require.NoError(t, err)
+	rule := rules.NewAlertingRule(ruleName, expr, time.Hour, 0, labels.EmptyLabels(), labels.EmptyLabels(), labels.EmptyLabels(), "", false, log.NewNopLogger())
+	group := rules.NewGroup(rules.GroupOptions{
+		Name:  "group",
+		Rules: []rules.Rule{rule},
+		Opts:  &rules.ManagerOptions{Logger: log.NewNopLogger()},
+	})
+
+	callCount := 0
+	store := testStore(func(ctx context.Context, qs string, t time.Time) (promql.Vector, error) {
+		callCount++
+		return nil, nil
+	})
+	store.restoreAlertState(alertState{
+		Timestamp: util.TimeToMillis(tNow),
+		Alerts:    []persistedAlert{{Labels: ls, ActiveAt: tNow.Add(-30 * time.Minute).Unix()}},
+	})
+	store.Start(MockRuleIter([]rulefmt.Rule{{Alert: ruleName, Expr: "unused", For: model.Duration(time.Hour)}}))
+
+	evals := 0
+	eval := store.dropRestoredAlertState(func(_ context.Context, _ *rules.Group, _ time.Time) { evals++ })
+
+	// The persisted state is kept until the for state of the alerts of the group is restored.
+	eval(context.Background(), group, tNow)
+	require.Len(t, store.persisted, 1)
+
+	// The group evaluating after the restore drops the persisted state, the for state is then restored by evaluation.
+	rule.SetRestored(true)
+	eval(context.Background(), group, tNow)
+	require.Equal(t, 2, evals)
+	require.Empty(t, store.persisted)
+
+	q, err := store.Querier(context.Background(), util.TimeToMillis(tNow.Add(-time.Hour)), util.TimeToMillis(time.Now()))
+	require.NoError(t, err)
+	sset := q.Select(false, nil, labelsToMatchers(ls)...)
+	require.False(t, sset.Next())
+	require.Equal(t, 1, callCount)
+}
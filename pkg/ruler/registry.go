@@ -22,11 +22,12 @@ import (
 	"github.com/prometheus/prometheus/model/relabel"
 	"github.com/prometheus/prometheus/storage"
 	"github.com/weaveworks/common/user"
-	"gopkg.in/yaml.v2"
 
+	ruler "github.com/grafana/loki/pkg/ruler/base"
 	"github.com/grafana/loki/pkg/ruler/storage/cleaner"
 	"github.com/grafana/loki/pkg/ruler/storage/instance"
 	"github.com/grafana/loki/pkg/ruler/storage/wal"
+	"github.com/grafana/loki/pkg/ruler/util"
 )
 
 type walRegistry struct {
@@ -184,6 +185,7 @@ func (r *walRegistry) getTenantConfig(tenant string) (instance.Config, error) {
 
 	conf.Name = tenant
 	conf.Tenant = tenant
+	conf.ExternalLabels = ruler.TenantExternalLabels(r.config.Config, r.overrides, tenant)
 
 	// retrieve remote-write config for this tenant, using the global remote-write for defaults
 	rwCfg, err := r.getTenantRemoteWriteConfig(tenant, r.config.RemoteWrite)
@@ -194,7 +196,7 @@ func (r *walRegistry) getTenantConfig(tenant string) (instance.Config, error) {
 	// TODO(dannyk): implement multiple RW configs
 	conf.RemoteWrite = []*config.RemoteWriteConfig{}
 	if rwCfg.Enabled {
-		for id := range r.config.RemoteWrite.Clients {
+		for id := range rwCfg.Clients {
 			clt := rwCfg.Clients[id]
 			if rwCfg.Clients[id].Headers == nil {
 				clt.Headers = make(map[string]string)
@@ -232,10 +234,21 @@ func (r *walRegistry) getTenantRemoteWriteConfig(tenant string, base RemoteWrite
 		overrides.Enabled = false
 	}
 
+	// add the remote clients configured only for this tenant, which get all their settings from the overrides.
+	if overrides.Enabled {
+		for _, id := range r.overrides.RulerRemoteWriteClientIDs(tenant) {
+			if overrides.Clients == nil {
+				overrides.Clients = map[string]config.RemoteWriteConfig{}
+			}
+			if _, ok := overrides.Clients[id]; !ok {
+				overrides.Clients[id] = config.DefaultRemoteWriteConfig
+			}
+		}
+	}
+
 	for id, clt := range overrides.Clients {
 		clt.Name = fmt.Sprintf("%s-rw-%s", tenant, id)
 		clt.SendExemplars = false
-		// TODO(dannyk): configure HTTP client overrides
 		// metadata is only used by prometheus scrape configs
 		clt.MetadataConfig = config.MetadataConfig{Send: false}
 
@@ -323,6 +336,10 @@ func (r *walRegistry) getTenantRemoteWriteConfig(tenant string, base RemoteWrite
 			}
 		}
 
+		if overrides.Enabled && clt.URL == nil {
+			return nil, fmt.Errorf("remote-write client '%s' URL for tenant %s is not configured", id, tenant)
+		}
+
 		overrides.Clients[id] = clt
 	}
 
@@ -340,22 +357,7 @@ func (r *walRegistry) createRelabelConfigs(tenant string) ([]*relabel.Config, er
 	}
 
 	// we want to treat an empty slice as "no relabel configs"
-	relabelConfigs := make([]*relabel.Config, len(configs))
-	for i, config := range configs {
-		out, err := yaml.Marshal(config)
-		if err != nil {
-			return nil, err
-		}
-
-		var rc relabel.Config
-		if err = yaml.Unmarshal(out, &rc); err != nil {
-			return nil, err
-		}
-
-		relabelConfigs[i] = &rc
-	}
-
-	return relabelConfigs, nil
+	return util.ParseRelabelConfigs(configs)
 }
 
 var errNotReady = errors.New("appender not ready")
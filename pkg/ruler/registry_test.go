@@ -13,6 +13,7 @@ import (
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/common/sigv4"
 	"github.com/prometheus/prometheus/config"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/relabel"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -34,6 +35,9 @@ const nilRelabelsTenant = "nil-relabels"
 const emptySliceRelabelsTenant = "empty-slice-relabels"
 const sigV4ConfigTenant = "sigv4"
 const multiRemoteWriteTenant = "multi-remote-write-tenant"
+const tenantRemoteWriteTenant = "tenant-remote-write"
+const missingURLRemoteWriteTenant = "missing-url-remote-write"
+const externalLabelsTenant = "external-labels"
 const sigV4GlobalRegion = "us-east-1"
 const sigV4TenantRegion = "us-east-2"
 
@@ -42,6 +46,7 @@ const capacity = 1500
 
 const remote1 = "remote-1"
 const remote2 = "remote-2"
+const tenantRemote = "tenant-remote"
 
 var remoteURL, _ = url.Parse("http://remote-write")
 var backCompatCfg = Config{
@@ -301,6 +306,26 @@ func newFakeLimits() fakeLimits {
 					},
 				},
 			},
+			tenantRemoteWriteTenant: {
+				RulerRemoteWriteConfig: map[string]config.RemoteWriteConfig{
+					tenantRemote: {
+						URL: &promConfig.URL{URL: newRemoteURL2},
+						HTTPClientConfig: promConfig.HTTPClientConfig{
+							BearerToken: "tenant-token",
+						},
+					},
+				},
+			},
+			missingURLRemoteWriteTenant: {
+				RulerRemoteWriteConfig: map[string]config.RemoteWriteConfig{
+					tenantRemote: {
+						QueueConfig: config.QueueConfig{Capacity: 800},
+					},
+				},
+			},
+			externalLabelsTenant: {
+				RulerExternalLabels: labels.FromStrings("cluster", "tenant-cluster", "team", "a"),
+			},
 		},
 	}
 }
@@ -474,6 +499,38 @@ func TestTenantMultiRemoteWriteConfigWithoutOverride(t *testing.T) {
 	assert.ElementsMatch(t, actualURLs, expectedURLs, "URLs do not match")
 }
 
+func TestTenantOnlyRemoteWriteConfig(t *testing.T) {
+	reg := setupRegistry(t, cfg, newFakeLimits())
+
+	tenantCfg, err := reg.getTenantConfig(tenantRemoteWriteTenant)
+	require.NoError(t, err)
+
+	// the tenant remote client is added to the global ones
+	require.Len(t, tenantCfg.RemoteWrite, 3)
+
+	var tenantClient *config.RemoteWriteConfig
+	for _, rw := range tenantCfg.RemoteWrite {
+		if rw.Name == fmt.Sprintf("%s-rw-%s", tenantRemoteWriteTenant, tenantRemote) {
+			tenantClient = rw
+		}
+	}
+	require.NotNil(t, tenantClient)
+	assert.Equal(t, newRemoteURL2, tenantClient.URL.URL)
+	assert.Equal(t, promConfig.Secret("tenant-token"), tenantClient.HTTPClientConfig.BearerToken)
+	assert.Equal(t, config.DefaultQueueConfig.Capacity, tenantClient.QueueConfig.Capacity)
+	assert.Equal(t, tenantRemoteWriteTenant, tenantClient.Headers[user.OrgIDHeaderName])
+
+	// the tenant remote client must have a URL
+	_, err = reg.getTenantConfig(missingURLRemoteWriteTenant)
+	require.Error(t, err)
+
+	// the tenant remote clients are not added when remote-write is disabled
+	reg.config.RemoteWrite.Enabled = false
+	tenantCfg, err = reg.getTenantConfig(missingURLRemoteWriteTenant)
+	require.NoError(t, err)
+	assert.Len(t, tenantCfg.RemoteWrite, 0)
+}
+
 func TestRulerRemoteWriteSigV4ConfigWithOverrides(t *testing.T) {
 	reg := setupSigV4Registry(t, backCompatCfg, newFakeLimitsBackwardCompat())
 
@@ -724,6 +781,21 @@ func TestTenantRemoteWriteHeadersNoOverride(t *testing.T) {
 	assert.ElementsMatch(t, actual, expected, "Headers do not match")
 }
 
+func TestTenantExternalLabels(t *testing.T) {
+	c := cfg
+	c.ExternalLabels = labels.FromStrings("cluster", "ruler-cluster", "region", "eu")
+	reg := setupRegistry(t, c, newFakeLimits())
+
+	// the external labels of the tenant are added to the ones of the ruler
+	tenantCfg, err := reg.getTenantConfig(externalLabelsTenant)
+	require.NoError(t, err)
+	assert.Equal(t, labels.FromStrings("cluster", "tenant-cluster", "region", "eu", "team", "a"), tenantCfg.ExternalLabels)
+
+	tenantCfg, err = reg.getTenantConfig(enabledRWTenant)
+	require.NoError(t, err)
+	assert.Equal(t, c.ExternalLabels, tenantCfg.ExternalLabels)
+}
+
 func TestRelabelConfigOverrides(t *testing.T) {
 	reg := setupRegistry(t, backCompatCfg, newFakeLimitsBackwardCompat())
 
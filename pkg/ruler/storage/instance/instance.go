@@ -9,6 +9,7 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io/fs"
 	"math"
 	"path/filepath"
 	"strings"
@@ -20,6 +21,7 @@ import (
 	"github.com/oklog/run"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/prometheus/config"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/timestamp"
 	"github.com/prometheus/prometheus/scrape"
 	"github.com/prometheus/prometheus/storage"
@@ -29,6 +31,7 @@ import (
 	"github.com/grafana/loki/pkg/ruler/storage/util"
 	"github.com/grafana/loki/pkg/ruler/storage/wal"
 	"github.com/grafana/loki/pkg/util/build"
+	"github.com/grafana/loki/pkg/util/flagext"
 )
 
 func init() {
@@ -56,6 +59,8 @@ type Config struct {
 	Tenant      string                      `doc:"hidden"`
 	Name        string                      `doc:"hidden"`
 	RemoteWrite []*config.RemoteWriteConfig `doc:"hidden"`
+	// Labels to add to the remote-written samples.
+	ExternalLabels labels.Labels `doc:"hidden"`
 
 	Dir string `yaml:"dir"`
 
@@ -66,6 +71,9 @@ type Config struct {
 	MinAge time.Duration `yaml:"min_age,omitempty"`
 	MaxAge time.Duration `yaml:"max_age,omitempty"`
 
+	// Maximum size of the WAL of a tenant before the samples not yet remote-written are truncated.
+	MaxSize flagext.ByteSize `yaml:"max_size,omitempty"`
+
 	RemoteFlushDeadline time.Duration `yaml:"remote_flush_deadline,omitempty" doc:"hidden"`
 }
 
@@ -147,6 +155,7 @@ func (c *Config) RegisterFlags(f *flag.FlagSet) {
 	f.DurationVar(&c.TruncateFrequency, "ruler.wal.truncate-frequency", DefaultConfig.TruncateFrequency, "Frequency with which to run the WAL truncation process.")
 	f.DurationVar(&c.MinAge, "ruler.wal.min-age", DefaultConfig.MinAge, "Minimum age that samples must exist in the WAL before being truncated.")
 	f.DurationVar(&c.MaxAge, "ruler.wal.max-age", DefaultConfig.MaxAge, "Maximum age that samples must exist in the WAL before being truncated.")
+	f.Var(&c.MaxSize, "ruler.wal.max-size", "Maximum size of the WAL of each tenant. When the WAL is larger at a truncation, the samples older than the minimum age are truncated, even if they haven't been remote-written yet. 0 to disable.")
 }
 
 type walStorageFactory func(reg prometheus.Registerer) (walStorage, error)
@@ -304,6 +313,7 @@ func (i *Instance) initialize(_ context.Context, reg prometheus.Registerer, cfg
 	remoteLogger := log.With(i.logger, "component", "remote")
 	i.remoteStore = remote.NewStorage(remoteLogger, reg, i.wal.StartTime, i.wal.Directory(), cfg.RemoteFlushDeadline, noopScrapeManager{})
 	err = i.remoteStore.ApplyConfig(&config.Config{
+		GlobalConfig:       config.GlobalConfig{ExternalLabels: cfg.ExternalLabels},
 		RemoteWriteConfigs: cfg.RemoteWrite,
 	})
 	if err != nil {
@@ -365,6 +375,7 @@ func (i *Instance) Update(c Config) (err error) {
 	i.cfg = c
 
 	err = i.remoteStore.ApplyConfig(&config.Config{
+		GlobalConfig:       config.GlobalConfig{ExternalLabels: c.ExternalLabels},
 		RemoteWriteConfigs: c.RemoteWrite,
 	})
 	if err != nil {
@@ -426,24 +437,16 @@ func (i *Instance) truncateLoop(ctx context.Context, wal walStorage, cfg *Config
 		case <-ctx.Done():
 			return
 		case <-time.After(cfg.TruncateFrequency):
-			// The timestamp ts is used to determine which series are not receiving
-			// samples and may be deleted from the WAL. Their most recent append
-			// timestamp is compared to ts, and if that timestamp is older then ts,
-			// they are considered inactive and may be deleted.
-			//
-			// Subtracting a duration from ts will delay when it will be considered
-			// inactive and scheduled for deletion.
-			ts := i.getRemoteWriteTimestamp() - i.cfg.MinAge.Milliseconds()
-			if ts < 0 {
-				ts = 0
+			var size int64
+			if cfg.MaxSize > 0 {
+				var err error
+				if size, err = dirSize(wal.Directory()); err != nil {
+					level.Warn(i.logger).Log("msg", "could not compute the size of the WAL", "err", err)
+				}
 			}
-
-			// Network issues can prevent the result of getRemoteWriteTimestamp from
-			// changing. We don't want data in the WAL to grow forever, so we set a cap
-			// on the maximum age data can be. If our ts is older than this cutoff point,
-			// we'll shift it forward to start deleting very stale data.
-			if maxTS := timestamp.FromTime(time.Now().Add(-i.cfg.MaxAge)); ts < maxTS {
-				ts = maxTS
+			ts := truncateTimestamp(cfg, i.getRemoteWriteTimestamp(), time.Now(), size)
+			if cfg.MaxSize > 0 && size > int64(cfg.MaxSize) {
+				level.Warn(i.logger).Log("msg", "the WAL is larger than its maximum size, truncating the samples not yet remote-written", "size", size, "max_size", cfg.MaxSize)
 			}
 
 			if ts == lastTs {
@@ -463,6 +466,58 @@ func (i *Instance) truncateLoop(ctx context.Context, wal walStorage, cfg *Config
 	}
 }
 
+// truncateTimestamp returns the timestamp the WAL is truncated to, from the last remote write timestamp and the size of the WAL.
+func truncateTimestamp(cfg *Config, remoteTs int64, now time.Time, walSize int64) int64 {
+	// The timestamp ts is used to determine which series are not receiving
+	// samples and may be deleted from the WAL. Their most recent append
+	// timestamp is compared to ts, and if that timestamp is older then ts,
+	// they are considered inactive and may be deleted.
+	//
+	// Subtracting a duration from ts will delay when it will be considered
+	// inactive and scheduled for deletion.
+	ts := remoteTs - cfg.MinAge.Milliseconds()
+	if ts < 0 {
+		ts = 0
+	}
+
+	// Network issues can prevent the result of getRemoteWriteTimestamp from
+	// changing. We don't want data in the WAL to grow forever, so we set a cap
+	// on the maximum age data can be. If our ts is older than this cutoff point,
+	// we'll shift it forward to start deleting very stale data.
+	if maxTS := timestamp.FromTime(now.Add(-cfg.MaxAge)); ts < maxTS {
+		ts = maxTS
+	}
+
+	// The same goes for the size of the WAL: when it's too large, the samples older than
+	// the minimum age are truncated, even if they haven't been sent yet.
+	if cfg.MaxSize > 0 && walSize > int64(cfg.MaxSize) {
+		if sizeTS := timestamp.FromTime(now.Add(-cfg.MinAge)); ts < sizeTS {
+			ts = sizeTS
+		}
+	}
+	return ts
+}
+
+// dirSize returns the size of the files of a directory and its sub-directories.
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
+
 // getRemoteWriteTimestamp looks up the last successful remote write timestamp.
 // This is passed to wal.Storage for its truncation. If no remote write sections
 // are configured, getRemoteWriteTimestamp returns the current time.
@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
@@ -24,6 +25,7 @@ import (
 	"github.com/prometheus/prometheus/model/histogram"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/metadata"
+	"github.com/prometheus/prometheus/model/timestamp"
 	"github.com/prometheus/prometheus/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -108,6 +110,33 @@ func TestConfig_ApplyDefaults_Validations(t *testing.T) {
 	}
 }
 
+func TestTruncateTimestamp(t *testing.T) {
+	now := time.Unix(100000, 0)
+	cfg := DefaultConfig
+	cfg.MaxSize = 1024
+
+	remoteTs := timestamp.FromTime(now.Add(-time.Hour))
+	// the samples not sent yet are kept, within the maximum age.
+	require.Equal(t, remoteTs-cfg.MinAge.Milliseconds(), truncateTimestamp(&cfg, remoteTs, now, 512))
+	require.Equal(t, timestamp.FromTime(now.Add(-cfg.MaxAge)), truncateTimestamp(&cfg, 0, now, 512))
+	// the samples not sent yet are truncated when the WAL is too large.
+	require.Equal(t, timestamp.FromTime(now.Add(-cfg.MinAge)), truncateTimestamp(&cfg, remoteTs, now, 2048))
+
+	cfg.MaxSize = 0
+	require.Equal(t, remoteTs-cfg.MinAge.Milliseconds(), truncateTimestamp(&cfg, remoteTs, now, 2048))
+}
+
+func TestDirSize(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "wal"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a"), make([]byte, 10), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "wal", "b"), make([]byte, 20), 0o600))
+
+	size, err := dirSize(dir)
+	require.NoError(t, err)
+	require.Equal(t, int64(30), size)
+}
+
 func TestMetricValueCollector(t *testing.T) {
 	r := prometheus.NewRegistry()
 	vc := NewMetricValueCollector(r, "this_should_be_tracked")
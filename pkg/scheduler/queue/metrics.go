@@ -9,6 +9,7 @@ type Metrics struct {
 	queueLength       *prometheus.GaugeVec   // Per tenant
 	discardedRequests *prometheus.CounterVec // Per tenant
 	enqueueCount      *prometheus.CounterVec // Per tenant and level
+	tenantQueriers    *prometheus.GaugeVec   // Per tenant
 }
 
 func NewMetrics(subsystem string, registerer prometheus.Registerer) *Metrics {
@@ -31,6 +32,12 @@ func NewMetrics(subsystem string, registerer prometheus.Registerer) *Metrics {
 			Name:      "enqueue_count",
 			Help:      "Total number of enqueued (sub-)queries.",
 		}, []string{"user", "level"}),
+		tenantQueriers: promauto.With(registerer).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "loki",
+			Subsystem: subsystem,
+			Name:      "tenant_queriers",
+			Help:      "Number of queriers which can handle the requests of the tenant, limited by its max_queriers_per_tenant, as of its last enqueued request.",
+		}, []string{"user"}),
 	}
 }
 
@@ -38,4 +45,5 @@ func (m *Metrics) Cleanup(user string) {
 	m.queueLength.DeleteLabelValues(user)
 	m.discardedRequests.DeleteLabelValues(user)
 	m.enqueueCount.DeletePartialMatch(prometheus.Labels{"user": user})
+	m.tenantQueriers.DeleteLabelValues(user)
 }
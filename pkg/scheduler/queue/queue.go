@@ -61,9 +61,12 @@ type RequestQueue struct {
 	metrics *Metrics
 }
 
-func NewRequestQueue(maxOutstandingPerTenant int, forgetDelay time.Duration, metrics *Metrics) *RequestQueue {
+// NewRequestQueue creates a new RequestQueue. The priorityWeights are the weights of the first level
+// sub-queues of each tenant, which are dequeued as many consecutive times as their weight. Sub-queues
+// without a weight have a weight of 1.
+func NewRequestQueue(maxOutstandingPerTenant int, forgetDelay time.Duration, priorityWeights map[string]int, metrics *Metrics) *RequestQueue {
 	q := &RequestQueue{
-		queues:                  newTenantQueues(maxOutstandingPerTenant, forgetDelay),
+		queues:                  newTenantQueues(maxOutstandingPerTenant, forgetDelay, priorityWeights),
 		connectedQuerierWorkers: atomic.NewInt32(0),
 		metrics:                 metrics,
 	}
@@ -111,6 +114,7 @@ func (q *RequestQueue) Enqueue(tenant string, path []string, req Request, maxQue
 	case queue.Chan() <- req:
 		q.metrics.queueLength.WithLabelValues(tenant).Inc()
 		q.metrics.enqueueCount.WithLabelValues(tenant, fmt.Sprint(len(path))).Inc()
+		q.metrics.tenantQueriers.WithLabelValues(tenant).Set(float64(q.queues.queriersForTenant(tenant)))
 		q.cond.Broadcast()
 		// Call this function while holding a lock. This guarantees that no querier can fetch the request before function returns.
 		if successFn != nil {
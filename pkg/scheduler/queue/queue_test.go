@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/grafana/dskit/services"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -45,7 +47,7 @@ func BenchmarkGetNextRequest(b *testing.B) {
 
 			queues := make([]*RequestQueue, 0, b.N)
 			for n := 0; n < b.N; n++ {
-				queue := NewRequestQueue(maxOutstandingPerTenant, 0, NewMetrics("query_scheduler", nil))
+				queue := NewRequestQueue(maxOutstandingPerTenant, 0, nil, NewMetrics("query_scheduler", nil))
 				queues = append(queues, queue)
 
 				for ix := 0; ix < queriers; ix++ {
@@ -103,7 +105,7 @@ func BenchmarkQueueRequest(b *testing.B) {
 	requests := make([]string, 0, numTenants)
 
 	for n := 0; n < b.N; n++ {
-		q := NewRequestQueue(maxOutstandingPerTenant, 0, NewMetrics("query_scheduler", nil))
+		q := NewRequestQueue(maxOutstandingPerTenant, 0, nil, NewMetrics("query_scheduler", nil))
 
 		for ix := 0; ix < queriers; ix++ {
 			q.RegisterQuerierConnection(fmt.Sprintf("querier-%d", ix))
@@ -133,7 +135,7 @@ func BenchmarkQueueRequest(b *testing.B) {
 func TestRequestQueue_GetNextRequestForQuerier_ShouldGetRequestAfterReshardingBecauseQuerierHasBeenForgotten(t *testing.T) {
 	const forgetDelay = 3 * time.Second
 
-	queue := NewRequestQueue(1, forgetDelay, NewMetrics("query_scheduler", nil))
+	queue := NewRequestQueue(1, forgetDelay, nil, NewMetrics("query_scheduler", nil))
 
 	// Start the queue service.
 	ctx := context.Background()
@@ -304,7 +306,7 @@ func TestContextCond(t *testing.T) {
 func TestMaxQueueSize(t *testing.T) {
 	t.Run("queue size is tracked per tenant", func(t *testing.T) {
 		maxSize := 3
-		queue := NewRequestQueue(maxSize, 0, NewMetrics("query_scheduler", nil))
+		queue := NewRequestQueue(maxSize, 0, nil, NewMetrics("query_scheduler", nil))
 		queue.RegisterQuerierConnection("querier")
 
 		// enqueue maxSize items with different actors
@@ -331,6 +333,22 @@ func TestMaxQueueSize(t *testing.T) {
 	})
 }
 
+func TestTenantQueriersMetric(t *testing.T) {
+	metrics := NewMetrics("query_scheduler", prometheus.NewRegistry())
+	queue := NewRequestQueue(10, 0, nil, metrics)
+	for i := 0; i < 5; i++ {
+		queue.RegisterQuerierConnection(fmt.Sprintf("querier-%d", i))
+	}
+
+	require.NoError(t, queue.Enqueue("shuffle-sharded", nil, 1, 2, nil))
+	require.NoError(t, queue.Enqueue("all-queriers", nil, 2, 0, nil))
+	require.Equal(t, float64(2), testutil.ToFloat64(metrics.tenantQueriers.WithLabelValues("shuffle-sharded")))
+	require.Equal(t, float64(5), testutil.ToFloat64(metrics.tenantQueriers.WithLabelValues("all-queriers")))
+
+	metrics.Cleanup("shuffle-sharded")
+	require.Equal(t, 1, testutil.CollectAndCount(metrics.tenantQueriers))
+}
+
 func assertChanReceived(t *testing.T, c chan struct{}, timeout time.Duration, msg string) {
 	t.Helper()
 
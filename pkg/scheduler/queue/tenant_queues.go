@@ -67,6 +67,9 @@ type tenantQueues struct {
 
 	// Sorted list of querier names, used when creating per-user shard.
 	sortedQueriers []string
+
+	// Weights of the first level sub-queues of the tenants.
+	weights map[string]int
 }
 
 type Queue interface {
@@ -96,7 +99,7 @@ type tenantQueue struct {
 	seed int64
 }
 
-func newTenantQueues(maxUserQueueSize int, forgetDelay time.Duration) *tenantQueues {
+func newTenantQueues(maxUserQueueSize int, forgetDelay time.Duration, weights map[string]int) *tenantQueues {
 	mm := &Mapping[*tenantQueue]{}
 	mm.Init(64)
 	return &tenantQueues{
@@ -106,6 +109,7 @@ func newTenantQueues(maxUserQueueSize int, forgetDelay time.Duration) *tenantQue
 		forgetDelay:      forgetDelay,
 		queriers:         map[string]*querier{},
 		sortedQueriers:   nil,
+		weights:          weights,
 	}
 }
 
@@ -137,6 +141,7 @@ func (q *tenantQueues) getOrAddQueue(tenant string, path []string, maxQueriers i
 			seed: util.ShuffleShardSeed(tenant, ""),
 		}
 		uq.TreeQueue = newTreeQueue(q.maxUserQueueSize, tenant)
+		uq.TreeQueue.weights = q.weights
 		q.mapping.Put(tenant, uq)
 	}
 
@@ -151,6 +156,15 @@ func (q *tenantQueues) getOrAddQueue(tenant string, path []string, maxQueriers i
 	return uq.add(path)
 }
 
+// queriersForTenant returns the number of queriers which can handle the requests of the tenant.
+func (q *tenantQueues) queriersForTenant(tenant string) int {
+	uq := q.mapping.GetByKey(tenant)
+	if uq == nil || uq.queriers == nil {
+		return len(q.sortedQueriers)
+	}
+	return len(uq.queriers)
+}
+
 // Finds next queue for the querier. To support fair scheduling between users, client is expected
 // to pass last user index returned by this function as argument. Is there was no previous
 // last user index, use -1.
@@ -18,7 +18,7 @@ import (
 )
 
 func TestQueues(t *testing.T) {
-	uq := newTenantQueues(0, 0)
+	uq := newTenantQueues(0, 0, nil)
 	assert.NotNil(t, uq)
 	assert.NoError(t, isConsistent(uq))
 
@@ -76,7 +76,7 @@ func TestQueues(t *testing.T) {
 }
 
 func TestQueuesOnTerminatingQuerier(t *testing.T) {
-	uq := newTenantQueues(0, 0)
+	uq := newTenantQueues(0, 0, nil)
 	assert.NotNil(t, uq)
 	assert.NoError(t, isConsistent(uq))
 
@@ -106,7 +106,7 @@ func TestQueuesOnTerminatingQuerier(t *testing.T) {
 }
 
 func TestQueuesWithQueriers(t *testing.T) {
-	uq := newTenantQueues(0, 0)
+	uq := newTenantQueues(0, 0, nil)
 	assert.NotNil(t, uq)
 	assert.NoError(t, isConsistent(uq))
 
@@ -183,7 +183,7 @@ func TestQueuesConsistency(t *testing.T) {
 
 	for testName, testData := range tests {
 		t.Run(testName, func(t *testing.T) {
-			uq := newTenantQueues(0, testData.forgetDelay)
+			uq := newTenantQueues(0, testData.forgetDelay, nil)
 			assert.NotNil(t, uq)
 			assert.NoError(t, isConsistent(uq))
 
@@ -232,7 +232,7 @@ func TestQueues_ForgetDelay(t *testing.T) {
 	)
 
 	now := time.Now()
-	uq := newTenantQueues(0, forgetDelay)
+	uq := newTenantQueues(0, forgetDelay, nil)
 	assert.NotNil(t, uq)
 	assert.NoError(t, isConsistent(uq))
 
@@ -324,7 +324,7 @@ func TestQueues_ForgetDelay_ShouldCorrectlyHandleQuerierReconnectingBeforeForget
 	)
 
 	now := time.Now()
-	uq := newTenantQueues(0, forgetDelay)
+	uq := newTenantQueues(0, forgetDelay, nil)
 	assert.NotNil(t, uq)
 	assert.NoError(t, isConsistent(uq))
 
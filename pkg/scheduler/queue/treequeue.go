@@ -10,6 +10,7 @@ type QueuePath []string //nolint:revive
 // TreeQueue is an hierarchical queue implementation where each sub-queue
 // has the same guarantees to be chosen from.
 // Each queue has also a local queue, which gets chosen with equal preference as the sub-queues.
+// If the queue has weights, each sub-queue is chosen as many consecutive times as its weight.
 type TreeQueue struct {
 	// local queue
 	ch RequestChannel
@@ -23,6 +24,11 @@ type TreeQueue struct {
 	name string
 	// maximum queue size of the local queue
 	size int
+	// weights of the sub-queues by name, 1 for the sub-queues without a weight
+	weights map[string]int
+	// name of the last chosen sub-queue and number of consecutive times it was chosen
+	last   string
+	served int
 }
 
 // newTreeQueue creates a new TreeQueue instance
@@ -79,6 +85,20 @@ func (q *TreeQueue) Dequeue() Request {
 		return nil
 	}
 
+	// keep choosing the last sub-queue until it was chosen as many times as its weight
+	if q.served > 0 {
+		if subq := q.mapping.GetByKey(q.last); subq != nil && q.served < q.weight(q.last) {
+			if item := subq.Dequeue(); item != nil {
+				q.served++
+				if subq.Len() == 0 {
+					q.mapping.Remove(subq.name)
+				}
+				return item
+			}
+		}
+		q.served = 0
+	}
+
 	maxIter := len(q.mapping.keys) + 1
 	for iters := 0; iters < maxIter; iters++ {
 		if q.current == StartIndexWithLocalQueue {
@@ -100,6 +120,7 @@ func (q *TreeQueue) Dequeue() Request {
 			q.current = subq.pos
 			item := subq.Dequeue()
 			if item != nil {
+				q.last, q.served = subq.name, 1
 				if subq.Len() == 0 {
 					q.mapping.Remove(subq.name)
 				}
@@ -110,6 +131,13 @@ func (q *TreeQueue) Dequeue() Request {
 	return nil
 }
 
+func (q *TreeQueue) weight(name string) int {
+	if w, ok := q.weights[name]; ok {
+		return w
+	}
+	return 1
+}
+
 // Name implements Queue
 func (q *TreeQueue) Name() string {
 	return q.name
@@ -149,6 +149,40 @@ func TestTreeQueue(t *testing.T) {
 		require.Equal(t, []int{100, 200, 300, 101, 301, 102}, items)
 	})
 
+	t.Run("dequeue weighted sub-queues", func(t *testing.T) {
+		/**
+		root:
+		  a: [100, 101, 102, 103, 104] (weight 3)
+			b: [200, 201, 202] (weight 1)
+			c: [300, 301, 302] (no weight)
+		**/
+		q := newTreeQueue(10, "root")
+		q.weights = map[string]int{"a": 3, "b": 1}
+		for _, p := range []QueuePath{{"a"}, {"b"}, {"c"}} {
+			q.add(p)
+		}
+
+		for _, id := range []int{100, 101, 102, 103, 104} {
+			q.mapping.GetByKey("a").Chan() <- r(id)
+		}
+		for _, id := range []int{200, 201, 202} {
+			q.mapping.GetByKey("b").Chan() <- r(id)
+		}
+		for _, id := range []int{300, 301, 302} {
+			q.mapping.GetByKey("c").Chan() <- r(id)
+		}
+
+		items := make([]int, 0, q.Len())
+		for q.Len() > 0 {
+			r := q.Dequeue()
+			if r == nil {
+				continue
+			}
+			items = append(items, r.(*dummyRequest).id)
+		}
+		require.Equal(t, []int{100, 101, 102, 200, 300, 103, 104, 201, 301, 202, 302}, items)
+	})
+
 	t.Run("empty sub-queues are removed", func(t *testing.T) {
 		q := newTreeQueue(10, "root")
 		q.add(QueuePath{"a"})
@@ -71,6 +71,7 @@ type Scheduler struct {
 	queueDuration            prometheus.Histogram
 	schedulerRunning         prometheus.Gauge
 	inflightRequests         prometheus.Summary
+	requeuedRequests         *prometheus.CounterVec
 
 	// Ring used for finding schedulers
 	ringManager *RingManager
@@ -102,6 +103,46 @@ type Config struct {
 	// Schedulers ring
 	UseSchedulerRing bool            `yaml:"use_scheduler_ring"`
 	SchedulerRing    util.RingConfig `yaml:"scheduler_ring,omitempty" doc:"description=The hash ring configuration. This option is required only if use_scheduler_ring is true."`
+
+	QueryPriorities PriorityConfig `yaml:"query_priorities" doc:"description=Experimental: Configures the priority classes of the queries, which are dequeued from per-tenant sub-queues weighted by their class."`
+
+	MaxRequeuesOnQuerierFailure int `yaml:"max_requeues_on_querier_failure"`
+}
+
+// PriorityConfig configures the priority classes of the queries.
+// The class of a query is selected by the X-Loki-Query-Priority header, and queries
+// without it are interactive, unless they're evaluated remotely by the ruler.
+type PriorityConfig struct {
+	Enabled           bool `yaml:"enabled"`
+	InteractiveWeight int  `yaml:"interactive_weight"`
+	RulerWeight       int  `yaml:"ruler_weight"`
+	BatchWeight       int  `yaml:"batch_weight"`
+}
+
+// RegisterFlags registers flags.
+func (cfg *PriorityConfig) RegisterFlags(f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, "query-scheduler.query-priorities.enabled", false, "Enqueue the queries of each tenant in sub-queues of their priority class: interactive, ruler or batch. The sub-queues are dequeued in turn, each one as many consecutive times as its weight, so that no class starves the others.")
+	f.IntVar(&cfg.InteractiveWeight, "query-scheduler.query-priorities.interactive-weight", 4, "Weight of the interactive queries, which are the queries without a priority class.")
+	f.IntVar(&cfg.RulerWeight, "query-scheduler.query-priorities.ruler-weight", 2, "Weight of the queries evaluated remotely by the ruler.")
+	f.IntVar(&cfg.BatchWeight, "query-scheduler.query-priorities.batch-weight", 1, "Weight of the batch queries.")
+}
+
+// weights returns the weights of the priority classes, or nil if the priorities are disabled.
+func (cfg *PriorityConfig) weights() (map[string]int, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	weights := map[string]int{
+		lokihttpreq.PriorityInteractive: cfg.InteractiveWeight,
+		lokihttpreq.PriorityRuler:       cfg.RulerWeight,
+		lokihttpreq.PriorityBatch:       cfg.BatchWeight,
+	}
+	for priority, weight := range weights {
+		if weight < 1 {
+			return nil, fmt.Errorf("the weight of the %s queries must be at least 1, got %d", priority, weight)
+		}
+	}
+	return weights, nil
 }
 
 func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
@@ -111,6 +152,12 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.GRPCClientConfig.RegisterFlagsWithPrefix("query-scheduler.grpc-client-config", f)
 	f.BoolVar(&cfg.UseSchedulerRing, "query-scheduler.use-scheduler-ring", false, "Set to true to have the query schedulers create and place themselves in a ring. If no frontend_address or scheduler_address are present anywhere else in the configuration, Loki will toggle this value to true.")
 	cfg.SchedulerRing.RegisterFlagsWithPrefix("query-scheduler.", "collectors/", f)
+	cfg.QueryPriorities.RegisterFlags(f)
+	f.IntVar(&cfg.MaxRequeuesOnQuerierFailure, "query-scheduler.max-requeues-on-querier-failure", 0, "Maximum number of times a request is enqueued again when the querier executing it disconnects, for example because it is restarted, instead of failing the request. A request which makes the queriers crash is requeued as many times too. 0 to disable.")
+}
+
+func (cfg *Config) Validate() error {
+	return util.ValidateGRPCClientTLS("query-scheduler.grpc-client-config", cfg.GRPCClientConfig)
 }
 
 // NewScheduler creates a new Scheduler.
@@ -123,6 +170,11 @@ func NewScheduler(cfg Config, limits Limits, log log.Logger, ringManager *RingMa
 		}
 	}
 
+	priorityWeights, err := cfg.QueryPriorities.weights()
+	if err != nil {
+		return nil, err
+	}
+
 	queueMetrics := queue.NewMetrics("query_scheduler", registerer)
 	s := &Scheduler{
 		cfg:    cfg,
@@ -133,7 +185,7 @@ func NewScheduler(cfg Config, limits Limits, log log.Logger, ringManager *RingMa
 		connectedFrontends: map[string]*connectedFrontend{},
 		queueMetrics:       queueMetrics,
 		ringManager:        ringManager,
-		requestQueue:       queue.NewRequestQueue(cfg.MaxOutstandingPerTenant, cfg.QuerierForgetDelay, queueMetrics),
+		requestQueue:       queue.NewRequestQueue(cfg.MaxOutstandingPerTenant, cfg.QuerierForgetDelay, priorityWeights, queueMetrics),
 	}
 
 	s.queueDuration = promauto.With(registerer).NewHistogram(prometheus.HistogramOpts{
@@ -160,6 +212,11 @@ func NewScheduler(cfg Config, limits Limits, log log.Logger, ringManager *RingMa
 		MaxAge:     time.Minute,
 		AgeBuckets: 6,
 	})
+	s.requeuedRequests = promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+		Namespace: "loki",
+		Name:      "query_scheduler_requeued_requests_total",
+		Help:      "Total number of requests enqueued again after the querier executing them disconnected.",
+	}, []string{"user"})
 
 	s.activeUsers = util.NewActiveUsersCleanupWithDefaultValues(s.cleanupMetricsForInactiveUser)
 
@@ -172,7 +229,6 @@ func NewScheduler(cfg Config, limits Limits, log log.Logger, ringManager *RingMa
 		s.shouldRun.Store(true)
 	}
 
-	var err error
 	s.subservices, err = services.NewManager(svcs...)
 	if err != nil {
 		return nil, err
@@ -197,6 +253,12 @@ type schedulerRequest struct {
 	request         *httpgrpc.HTTPRequest
 	statsEnabled    bool
 
+	// The queue path and max queriers the request is enqueued with,
+	// and how many times it was enqueued again after a querier failure.
+	queuePath   []string
+	maxQueriers int
+	requeues    int
+
 	queueTime time.Time
 
 	ctx       context.Context
@@ -216,15 +278,15 @@ func (s *Scheduler) FrontendLoop(frontend schedulerpb.SchedulerForFrontend_Front
 	defer s.frontendDisconnected(frontendAddress)
 
 	// Response to INIT. If scheduler is not running, we skip for-loop, send SHUTTING_DOWN and exit this method.
-	if s.State() == services.Running && s.shouldRun.Load() {
+	if s.acceptsFrontends() {
 		if err := frontend.Send(&schedulerpb.SchedulerToFrontend{Status: schedulerpb.OK}); err != nil {
 			return err
 		}
 	}
 
-	// We stop accepting new queries in Stopping state. By returning quickly, we disconnect frontends, which in turns
-	// cancels all their queries.
-	for s.State() == services.Running {
+	// We stop accepting new queries in Stopping state, or once this scheduler left the ReplicationSet of the ring.
+	// By returning quickly, we disconnect frontends, which in turns cancels all their queries.
+	for s.acceptsFrontends() {
 		msg, err := frontend.Recv()
 		if err != nil {
 			// No need to report this as error, it is expected when query-frontend performs SendClose() (as frontendSchedulerWorker does).
@@ -234,7 +296,7 @@ func (s *Scheduler) FrontendLoop(frontend schedulerpb.SchedulerForFrontend_Front
 			return err
 		}
 
-		if s.State() != services.Running {
+		if !s.acceptsFrontends() {
 			break // break out of the loop, and send SHUTTING_DOWN message.
 		}
 
@@ -272,6 +334,14 @@ func (s *Scheduler) FrontendLoop(frontend schedulerpb.SchedulerForFrontend_Front
 	return frontend.Send(&schedulerpb.SchedulerToFrontend{Status: schedulerpb.SHUTTING_DOWN})
 }
 
+// acceptsFrontends returns whether the scheduler is running and, when the
+// schedulers are discovered with the ring, whether it is in the ReplicationSet.
+// The frontends which connect to schedulers outside of the ReplicationSet while
+// scaling up or down are told it is shutting down, so they use another one.
+func (s *Scheduler) acceptsFrontends() bool {
+	return s.State() == services.Running && s.shouldRun.Load()
+}
+
 func (s *Scheduler) frontendConnected(frontend schedulerpb.SchedulerForFrontend_FrontendLoopServer) (string, context.Context, error) {
 	msg, err := frontend.Recv()
 	if err != nil {
@@ -371,6 +441,15 @@ func (s *Scheduler) enqueueRequest(frontendContext context.Context, frontendAddr
 		}
 	}
 
+	// The priority class is the first level of the queue hierarchy, so the
+	// requests of a tenant are dequeued from its classes according to their weights.
+	if s.cfg.QueryPriorities.Enabled {
+		queuePath = append([]string{requestPriority(msg.HttpRequest)}, queuePath...)
+	}
+
+	req.queuePath = queuePath
+	req.maxQueriers = maxQueriers
+
 	s.activeUsers.UpdateUserTimestamp(req.tenantID, now)
 	return s.requestQueue.Enqueue(req.tenantID, queuePath, req, maxQueriers, func() {
 		shouldCancel = false
@@ -381,6 +460,16 @@ func (s *Scheduler) enqueueRequest(frontendContext context.Context, frontendAddr
 	})
 }
 
+// requestPriority returns the priority class of the request sent by the frontend.
+func requestPriority(req *httpgrpc.HTTPRequest) string {
+	for _, h := range req.GetHeaders() {
+		if textproto.CanonicalMIMEHeaderKey(h.Key) == lokihttpreq.LokiQueryPriorityHeader && len(h.Values) > 0 {
+			return lokihttpreq.QueryPriority(h.Values[0])
+		}
+	}
+	return lokihttpreq.PriorityInteractive
+}
+
 // This method doesn't do removal from the queue.
 func (s *Scheduler) cancelRequestAndRemoveFromPending(frontendAddr string, queryID uint64) {
 	s.pendingRequestsMu.Lock()
@@ -469,8 +558,13 @@ func (s *Scheduler) NotifyQuerierShutdown(_ context.Context, req *schedulerpb.No
 }
 
 func (s *Scheduler) forwardRequestToQuerier(querier schedulerpb.SchedulerForQuerier_QuerierLoopServer, req *schedulerRequest) error {
-	// Make sure to cancel request at the end to cleanup resources.
-	defer s.cancelRequestAndRemoveFromPending(req.frontendAddress, req.queryID)
+	// Make sure to cancel request at the end to cleanup resources, unless it is requeued.
+	requeued := false
+	defer func() {
+		if !requeued {
+			s.cancelRequestAndRemoveFromPending(req.frontendAddress, req.queryID)
+		}
+	}()
 
 	// Handle the stream sending & receiving on a goroutine so we can
 	// monitoring the contexts in a select and cancel things appropriately.
@@ -504,12 +598,47 @@ func (s *Scheduler) forwardRequestToQuerier(querier schedulerpb.SchedulerForQuer
 		// then error out this upstream request _and_ stream.
 
 		if err != nil {
-			s.forwardErrorToFrontend(req.ctx, req, err)
+			requeued = s.requeueRequest(req, err)
+			if !requeued {
+				s.forwardErrorToFrontend(req.ctx, req, err)
+			}
 		}
 		return err
 	}
 }
 
+// requeueRequest enqueues the request again after the querier it was forwarded to failed,
+// so that another querier executes it, unless it was already requeued as many times as allowed.
+// It returns whether the request was requeued.
+func (s *Scheduler) requeueRequest(req *schedulerRequest, querierErr error) bool {
+	if req.requeues >= s.cfg.MaxRequeuesOnQuerierFailure || req.ctx.Err() != nil || !s.isRunningOrStopping() {
+		return false
+	}
+
+	// The queue time header is added again when the request is dequeued.
+	queueTimeHeader := textproto.CanonicalMIMEHeaderKey(string(lokihttpreq.QueryQueueTimeHTTPHeader))
+	headers := req.request.Headers[:0]
+	for _, h := range req.request.Headers {
+		if h.Key != queueTimeHeader {
+			headers = append(headers, h)
+		}
+	}
+	req.request.Headers = headers
+
+	req.requeues++
+	req.queueTime = time.Now()
+	req.queueSpan, _ = opentracing.StartSpanFromContextWithTracer(req.ctx, opentracing.GlobalTracer(), "requeued", opentracing.ChildOf(req.parentSpanContext))
+	if err := s.requestQueue.Enqueue(req.tenantID, req.queuePath, req, req.maxQueriers, nil); err != nil {
+		req.queueSpan.Finish()
+		level.Warn(s.log).Log("msg", "failed to requeue request after querier failure", "frontend", req.frontendAddress, "query_id", req.queryID, "err", err, "querier_err", querierErr)
+		return false
+	}
+
+	s.requeuedRequests.WithLabelValues(req.tenantID).Inc()
+	level.Warn(s.log).Log("msg", "requeued request after querier failure", "frontend", req.frontendAddress, "query_id", req.queryID, "requeues", req.requeues, "querier_err", querierErr)
+	return true
+}
+
 func (s *Scheduler) forwardErrorToFrontend(ctx context.Context, req *schedulerRequest, requestErr error) {
 	opts, err := s.cfg.GRPCClientConfig.DialOption([]grpc.UnaryClientInterceptor{
 		otgrpc.OpenTracingClientInterceptor(opentracing.GlobalTracer()),
@@ -645,6 +774,7 @@ func (s *Scheduler) stopping(_ error) error {
 
 func (s *Scheduler) cleanupMetricsForInactiveUser(user string) {
 	s.queueMetrics.Cleanup(user)
+	s.requeuedRequests.DeleteLabelValues(user)
 }
 
 func (s *Scheduler) getConnectedFrontendClientsMetric() float64 {
@@ -2,14 +2,21 @@ package scheduler
 
 import (
 	"context"
+	"io"
 	"testing"
 
+	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/dskit/services"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/weaveworks/common/httpgrpc"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/metadata"
 
+	"github.com/grafana/loki/pkg/scheduler/queue"
 	"github.com/grafana/loki/pkg/scheduler/schedulerpb"
 	util_log "github.com/grafana/loki/pkg/util/log"
 )
@@ -99,3 +106,98 @@ func (m mockSchedulerForFrontendFrontendLoopServer) SendMsg(_ interface{}) error
 func (m mockSchedulerForFrontendFrontendLoopServer) RecvMsg(_ interface{}) error {
 	panic("implement me")
 }
+
+type mockFrontendLoopServer struct {
+	mockSchedulerForFrontendFrontendLoopServer
+	recv []*schedulerpb.FrontendToScheduler
+}
+
+func (m *mockFrontendLoopServer) Recv() (*schedulerpb.FrontendToScheduler, error) {
+	if len(m.recv) == 0 {
+		return nil, io.EOF
+	}
+	msg := m.recv[0]
+	m.recv = m.recv[1:]
+	return msg, nil
+}
+
+func TestScheduler_FrontendLoopOutsideOfReplicationSet(t *testing.T) {
+	s := Scheduler{
+		log:                util_log.Logger,
+		connectedFrontends: map[string]*connectedFrontend{},
+	}
+	s.Service = services.NewIdleService(nil, nil)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), s.Service))
+	t.Cleanup(func() { _ = services.StopAndAwaitTerminated(context.Background(), s.Service) })
+
+	// A scheduler which is not in the ReplicationSet tells the frontends it is shutting down,
+	// rather than leaving them waiting for the response to INIT.
+	frontend := &mockFrontendLoopServer{recv: []*schedulerpb.FrontendToScheduler{
+		{Type: schedulerpb.INIT, FrontendAddress: "127.0.0.1:9095"},
+	}}
+	require.NoError(t, s.FrontendLoop(frontend))
+	assert.Equal(t, schedulerpb.SHUTTING_DOWN, frontend.msg.Status)
+	assert.Empty(t, s.connectedFrontends)
+}
+
+type mockQuerierLoopServer struct {
+	schedulerpb.SchedulerForQuerier_QuerierLoopServer
+	sent    []*schedulerpb.SchedulerToQuerier
+	recvErr error
+}
+
+func (m *mockQuerierLoopServer) Send(msg *schedulerpb.SchedulerToQuerier) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func (m *mockQuerierLoopServer) Recv() (*schedulerpb.QuerierToScheduler, error) {
+	return nil, m.recvErr
+}
+
+func TestScheduler_RequeueOnQuerierFailure(t *testing.T) {
+	var cfg Config
+	flagext.DefaultValues(&cfg)
+	cfg.MaxRequeuesOnQuerierFailure = 1
+	s, err := NewScheduler(cfg, limits{}, util_log.Logger, nil, prometheus.NewRegistry())
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), s))
+	t.Cleanup(func() { _ = services.StopAndAwaitTerminated(context.Background(), s) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, s.enqueueRequest(ctx, "127.0.0.1:9095", &schedulerpb.FrontendToScheduler{
+		Type:        schedulerpb.ENQUEUE,
+		QueryID:     1,
+		UserID:      "fake",
+		HttpRequest: &httpgrpc.HTTPRequest{Url: "/loki/api/v1/query_range"},
+	}))
+
+	s.requestQueue.RegisterQuerierConnection("querier")
+	dequeue := func() *schedulerRequest {
+		req, _, err := s.requestQueue.Dequeue(ctx, queue.StartIndex, "querier")
+		require.NoError(t, err)
+		return req.(*schedulerRequest)
+	}
+
+	// The request is requeued after the querier disconnects mid-execution.
+	req := dequeue()
+	querier := &mockQuerierLoopServer{recvErr: io.EOF}
+	require.ErrorIs(t, s.forwardRequestToQuerier(querier, req), io.EOF)
+	require.Len(t, querier.sent, 1)
+	require.Equal(t, 1, req.requeues)
+	require.NoError(t, req.ctx.Err())
+	require.Equal(t, float64(1), testutil.ToFloat64(s.requeuedRequests.WithLabelValues("fake")))
+
+	s.pendingRequestsMu.Lock()
+	require.Contains(t, s.pendingRequests, requestKey{frontendAddr: "127.0.0.1:9095", queryID: 1})
+	s.pendingRequestsMu.Unlock()
+
+	// The request is not requeued more than allowed.
+	require.Same(t, req, dequeue())
+	require.False(t, s.requeueRequest(req, io.EOF))
+}
+
+type limits struct{}
+
+func (limits) MaxQueriersPerUser(_ string) int { return 0 }
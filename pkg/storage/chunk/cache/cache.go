@@ -117,10 +117,27 @@ func New(cfg Config, reg prometheus.Registerer, logger log.Logger, cacheType sta
 
 	var caches []Cache
 
+	if cfg.EmbeddedCache.IsEnabled() && cfg.EmbeddedCache.usesTenantLRU() {
+		if cfg.EmbeddedCache.Distributed && cfg.EmbeddedCache.Peers == nil {
+			return nil, errors.New("distributed embedded cache requires the embedded cache peers")
+		}
+		embeddedCfg := cfg.EmbeddedCache
+		if embeddedCfg.TTL == 0 && cfg.DefaultValidity != 0 {
+			embeddedCfg.TTL = cfg.DefaultValidity
+		}
+		var peers *EmbeddedCachePeers
+		if embeddedCfg.Distributed {
+			peers = embeddedCfg.Peers
+		}
+		cacheName := cfg.Prefix + "embedded-cache"
+		cache := NewEmbeddedCache(cacheName, embeddedCfg, peers, reg, logger, cacheType)
+		caches = append(caches, CollectStats(Instrument(cacheName, cache, reg)))
+	}
+
 	// Currently fifocache can be enabled in two ways.
 	// 1. cfg.EnableFifocache (old deprecated way)
 	// 2. cfg.EmbeddedCache.Enabled=true and cfg.EmbeddedCache.Distributed=false (new way)
-	if cfg.EnableFifoCache || cfg.EmbeddedCache.IsEnabled() {
+	if cfg.EnableFifoCache || (cfg.EmbeddedCache.IsEnabled() && !cfg.EmbeddedCache.usesTenantLRU()) {
 		var fifocfg FifoCacheConfig
 
 		if cfg.EnableFifoCache {
@@ -128,7 +145,7 @@ func New(cfg Config, reg prometheus.Registerer, logger log.Logger, cacheType sta
 			fifocfg = cfg.Fifocache
 		}
 
-		if cfg.EmbeddedCache.IsEnabled() {
+		if cfg.EmbeddedCache.IsEnabled() && !cfg.EmbeddedCache.usesTenantLRU() {
 			fifocfg = FifoCacheConfig{
 				MaxSizeBytes:  fmt.Sprint(cfg.EmbeddedCache.MaxSizeMB * 1e6),
 				TTL:           cfg.EmbeddedCache.TTL,
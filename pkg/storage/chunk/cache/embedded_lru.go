@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	evictionReasonSize        = "size"
+	evictionReasonTenantQuota = "tenant_quota"
+	evictionReasonExpired     = "expired"
+)
+
+// lruKey namespaces the keys by tenant.
+type lruKey struct {
+	tenant, key string
+}
+
+type lruEntry struct {
+	key, tenant string
+	value       []byte
+	expiresAt   time.Time
+
+	// elements of the entry in the list of all entries and in the list of the entries of its tenant.
+	all, own *list.Element
+}
+
+func (e *lruEntry) size() int64 {
+	return int64(len(e.key) + len(e.value))
+}
+
+type tenantEntries struct {
+	entries *list.List
+	bytes   int64
+}
+
+// embeddedLRU is a least recently used cache bounded in bytes, which
+// additionally bounds the bytes of the entries of every tenant.
+// The keys are namespaced by tenant, so the entries of a tenant are only
+// returned to that tenant. The entries stored without tenant are only bounded
+// by the total size.
+type embeddedLRU struct {
+	mtx sync.Mutex
+
+	maxBytes, maxTenantBytes int64
+	ttl                      time.Duration
+
+	entries map[lruKey]*lruEntry
+	all     *list.List
+	tenants map[string]*tenantEntries
+	bytes   int64
+
+	evicted *prometheus.CounterVec
+}
+
+func newEmbeddedLRU(maxBytes, maxTenantBytes int64, ttl time.Duration, evicted *prometheus.CounterVec) *embeddedLRU {
+	return &embeddedLRU{
+		maxBytes:       maxBytes,
+		maxTenantBytes: maxTenantBytes,
+		ttl:            ttl,
+		entries:        map[lruKey]*lruEntry{},
+		all:            list.New(),
+		tenants:        map[string]*tenantEntries{},
+		evicted:        evicted,
+	}
+}
+
+// put stores the value of the key for the tenant, evicting the least recently
+// used entries of the tenant and then of all tenants once over the limits.
+func (c *embeddedLRU) put(tenant, key string, value []byte, now time.Time) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if e, ok := c.entries[lruKey{tenant, key}]; ok {
+		c.remove(e)
+	}
+	e := &lruEntry{key: key, tenant: tenant, value: value}
+	if c.ttl > 0 {
+		e.expiresAt = now.Add(c.ttl)
+	}
+	if e.size() > c.maxBytes || (tenant != "" && c.maxTenantBytes > 0 && e.size() > c.maxTenantBytes) {
+		return
+	}
+
+	t, ok := c.tenants[tenant]
+	if !ok {
+		t = &tenantEntries{entries: list.New()}
+		c.tenants[tenant] = t
+	}
+	e.all = c.all.PushFront(e)
+	e.own = t.entries.PushFront(e)
+	c.entries[lruKey{tenant, key}] = e
+	c.bytes += e.size()
+	t.bytes += e.size()
+
+	for tenant != "" && c.maxTenantBytes > 0 && t.bytes > c.maxTenantBytes {
+		c.evict(t.entries.Back().Value.(*lruEntry), evictionReasonTenantQuota)
+	}
+	for c.bytes > c.maxBytes {
+		c.evict(c.all.Back().Value.(*lruEntry), evictionReasonSize)
+	}
+}
+
+// get returns the value of the key of the tenant if it is present and did not expire.
+func (c *embeddedLRU) get(tenant, key string, now time.Time) ([]byte, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	e, ok := c.entries[lruKey{tenant, key}]
+	if !ok {
+		return nil, false
+	}
+	if !e.expiresAt.IsZero() && now.After(e.expiresAt) {
+		c.evict(e, evictionReasonExpired)
+		return nil, false
+	}
+	c.all.MoveToFront(e.all)
+	c.tenants[e.tenant].entries.MoveToFront(e.own)
+	return e.value, true
+}
+
+func (c *embeddedLRU) evict(e *lruEntry, reason string) {
+	c.remove(e)
+	c.evicted.WithLabelValues(reason).Inc()
+}
+
+func (c *embeddedLRU) remove(e *lruEntry) {
+	t := c.tenants[e.tenant]
+	c.all.Remove(e.all)
+	t.entries.Remove(e.own)
+	delete(c.entries, lruKey{e.tenant, e.key})
+	c.bytes -= e.size()
+	t.bytes -= e.size()
+	if t.entries.Len() == 0 {
+		delete(c.tenants, e.tenant)
+	}
+}
@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEmbeddedLRU(maxBytes, maxTenantBytes int64, ttl time.Duration) *embeddedLRU {
+	return newEmbeddedLRU(maxBytes, maxTenantBytes, ttl, newEmbeddedCacheEvictions("test", prometheus.NewRegistry()))
+}
+
+func TestEmbeddedLRU_Eviction(t *testing.T) {
+	// Every entry takes 4 bytes.
+	c := newTestEmbeddedLRU(12, 8, 0)
+	now := time.Now()
+
+	c.put("a", "a1", []byte("aa"), now)
+	c.put("a", "a2", []byte("aa"), now)
+	c.put("b", "b1", []byte("bb"), now)
+
+	// a1 is the least recently used entry of tenant a once read.
+	_, ok := c.get("a", "a2", now)
+	require.True(t, ok)
+	_, ok = c.get("a", "a1", now)
+	require.True(t, ok)
+
+	// The quota of tenant a evicts its least recently used entry.
+	c.put("a", "a3", []byte("aa"), now)
+	_, ok = c.get("a", "a2", now)
+	require.False(t, ok)
+	require.Equal(t, float64(1), testutil.ToFloat64(c.evicted.WithLabelValues(evictionReasonTenantQuota)))
+
+	// The total size evicts the least recently used entry of all tenants.
+	c.put("", "c1", []byte("cc"), now)
+	_, ok = c.get("b", "b1", now)
+	require.False(t, ok)
+	require.Equal(t, float64(1), testutil.ToFloat64(c.evicted.WithLabelValues(evictionReasonSize)))
+	for _, key := range []lruKey{{"a", "a1"}, {"a", "a3"}, {"", "c1"}} {
+		_, ok = c.get(key.tenant, key.key, now)
+		require.True(t, ok, key)
+	}
+	require.Equal(t, int64(12), c.bytes)
+
+	// Entries larger than the quota are not stored.
+	c.put("a", "a4", []byte("aaaaaaaa"), now)
+	_, ok = c.get("a", "a4", now)
+	require.False(t, ok)
+}
+
+func TestEmbeddedLRU_Overwrite(t *testing.T) {
+	c := newTestEmbeddedLRU(100, 0, 0)
+	now := time.Now()
+
+	c.put("a", "k", []byte("v1"), now)
+	c.put("a", "k", []byte("v22"), now)
+	v, ok := c.get("a", "k", now)
+	require.True(t, ok)
+	require.Equal(t, []byte("v22"), v)
+	require.Equal(t, int64(4), c.bytes)
+}
+
+func TestEmbeddedLRU_TenantNamespaces(t *testing.T) {
+	c := newTestEmbeddedLRU(100, 0, 0)
+	now := time.Now()
+
+	c.put("a", "k", []byte("v1"), now)
+	c.put("b", "k", []byte("v22"), now)
+	v, ok := c.get("a", "k", now)
+	require.True(t, ok)
+	require.Equal(t, []byte("v1"), v)
+	v, ok = c.get("b", "k", now)
+	require.True(t, ok)
+	require.Equal(t, []byte("v22"), v)
+	_, ok = c.get("c", "k", now)
+	require.False(t, ok)
+}
+
+func TestEmbeddedLRU_TTL(t *testing.T) {
+	c := newTestEmbeddedLRU(100, 0, time.Minute)
+	now := time.Now()
+
+	c.put("a", "k", []byte("v"), now)
+	_, ok := c.get("a", "k", now.Add(30*time.Second))
+	require.True(t, ok)
+	_, ok = c.get("a", "k", now.Add(2*time.Minute))
+	require.False(t, ok)
+	require.Equal(t, float64(1), testutil.ToFloat64(c.evicted.WithLabelValues(evictionReasonExpired)))
+	require.Empty(t, c.entries)
+}
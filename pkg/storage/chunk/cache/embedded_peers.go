@@ -0,0 +1,486 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cespare/xxhash"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/kv"
+	"github.com/grafana/dskit/ring"
+	"github.com/grafana/dskit/services"
+	"github.com/grafana/dskit/tenant"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/loki/pkg/logqlmodel/stats"
+	"github.com/grafana/loki/pkg/util"
+)
+
+const (
+	// embeddedCacheRingKey is the key under which the peers are stored in the KVStore.
+	embeddedCacheRingKey = "embedded-cache"
+
+	// embeddedCacheRingNumTokens is the number of tokens of every peer, which
+	// spreads the keys evenly across the peers.
+	embeddedCacheRingNumTokens = 128
+
+	// embeddedCacheRingAutoForgetUnhealthyPeriods is how many consecutive timeout periods an unhealthy
+	// peer in the ring will be automatically removed.
+	embeddedCacheRingAutoForgetUnhealthyPeriods = 10
+
+	// EmbeddedCachePeersPathPrefix is the HTTP path prefix of the routes served by the peers.
+	// The ring status page is served on the HTTP server, the entries on the peer listener.
+	EmbeddedCachePeersPathPrefix = "/embedded-cache/"
+)
+
+var embeddedCacheRingOp = ring.NewOp([]ring.InstanceState{ring.ACTIVE}, nil)
+
+// EmbeddedCachePeersConfig configures the ring of the processes sharing their distributed embedded caches.
+type EmbeddedCachePeersConfig struct {
+	Ring          util.RingConfig `yaml:"ring" doc:"description=The hash ring configuration used to partition the keys of the distributed embedded caches across the peers. The peers are reached on the peer listen port unless the instance port is set."`
+	ListenAddress string          `yaml:"listen_address"`
+	ListenPort    int             `yaml:"listen_port"`
+	Timeout       time.Duration   `yaml:"timeout"`
+}
+
+// RegisterFlags registers the flags of the peers of the distributed embedded caches.
+func (cfg *EmbeddedCachePeersConfig) RegisterFlags(f *flag.FlagSet) {
+	cfg.Ring.RegisterFlagsWithPrefix("embedded-cache.", "collectors/", f)
+	f.StringVar(&cfg.ListenAddress, "embedded-cache.peer-listen-address", "", "Address the entries of the distributed embedded caches are served to the other peers on. The entries are served on a dedicated listener rather than the HTTP server, so they are not exposed with the API and the listener can be restricted to the peers.")
+	f.IntVar(&cfg.ListenPort, "embedded-cache.peer-listen-port", 9097, "Port the entries of the distributed embedded caches are served to the other peers on.")
+	f.DurationVar(&cfg.Timeout, "embedded-cache.peer-timeout", 500*time.Millisecond, "Maximum time to wait for a peer to fetch or store the entries of a distributed embedded cache. The keys of peers failing in time are reported as missing.")
+}
+
+// EmbeddedCachePeers is the ring of the processes sharing their distributed
+// embedded caches. Every peer holds the entries of the keys it owns in the
+// ring, and serves them to the other peers over HTTP on the peer listener.
+type EmbeddedCachePeers struct {
+	services.Service
+
+	cfg    EmbeddedCachePeersConfig
+	logger log.Logger
+	reg    prometheus.Registerer
+
+	ring       *ring.Ring
+	lifecycler *ring.BasicLifecycler
+
+	subservices        *services.Manager
+	subservicesWatcher *services.FailureWatcher
+
+	client   *http.Client
+	listener net.Listener
+	server   *http.Server
+	serveErr chan error
+
+	mtx    sync.RWMutex
+	caches map[string]*embeddedLRU
+
+	peerRequests *prometheus.CounterVec
+}
+
+// NewEmbeddedCachePeers creates the peers of the distributed embedded caches
+// and listens on the peer listen address. The process registers itself in the
+// ring and serves its entries once started.
+func NewEmbeddedCachePeers(cfg EmbeddedCachePeersConfig, logger log.Logger, reg prometheus.Registerer) (_ *EmbeddedCachePeers, err error) {
+	listener, err := net.Listen("tcp", net.JoinHostPort(cfg.ListenAddress, strconv.Itoa(cfg.ListenPort)))
+	if err != nil {
+		return nil, errors.Wrap(err, "embedded cache peers listen")
+	}
+	defer func() {
+		if err != nil {
+			listener.Close()
+		}
+	}()
+	// The port of the listener is advertised in the ring, unless an instance port is set.
+	cfg.Ring.ListenPort = listener.Addr().(*net.TCPAddr).Port
+
+	p := &EmbeddedCachePeers{
+		cfg:      cfg,
+		logger:   log.With(logger, "component", "embedded-cache-peers"),
+		reg:      reg,
+		client:   &http.Client{Timeout: cfg.Timeout},
+		listener: listener,
+		serveErr: make(chan error, 1),
+		caches:   map[string]*embeddedLRU{},
+		peerRequests: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki",
+			Subsystem: "embedded_cache",
+			Name:      "peer_requests_total",
+			Help:      "The total number of requests to the peers of the distributed embedded caches.",
+		}, []string{"operation", "status"}),
+	}
+
+	ringStore, err := kv.NewClient(
+		cfg.Ring.KVStore,
+		ring.GetCodec(),
+		kv.RegistererWithKVName(prometheus.WrapRegistererWithPrefix("loki_", reg), "embedded-cache"),
+		p.logger,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "embedded cache peers create KV store client")
+	}
+
+	p.ring, err = ring.NewWithStoreClientAndStrategy(
+		cfg.Ring.ToRingConfig(1),
+		embeddedCacheRingKey,
+		embeddedCacheRingKey,
+		ringStore,
+		ring.NewIgnoreUnhealthyInstancesReplicationStrategy(),
+		prometheus.WrapRegistererWithPrefix("cortex_", reg),
+		p.logger,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create ring client for embedded cache peers")
+	}
+
+	lifecyclerCfg, err := cfg.Ring.ToLifecyclerConfig(embeddedCacheRingNumTokens, p.logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid ring lifecycler config")
+	}
+	delegate := ring.BasicLifecyclerDelegate(p)
+	delegate = ring.NewLeaveOnStoppingDelegate(delegate, p.logger)
+	delegate = ring.NewTokensPersistencyDelegate(cfg.Ring.TokensFilePath, ring.ACTIVE, delegate, p.logger)
+	delegate = ring.NewAutoForgetDelegate(embeddedCacheRingAutoForgetUnhealthyPeriods*cfg.Ring.HeartbeatTimeout, delegate, p.logger)
+
+	p.lifecycler, err = ring.NewBasicLifecycler(lifecyclerCfg, embeddedCacheRingKey, embeddedCacheRingKey, ringStore, delegate, p.logger, reg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create ring lifecycler for embedded cache peers")
+	}
+
+	p.subservices, err = services.NewManager(p.lifecycler, p.ring)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create services manager for embedded cache peers")
+	}
+	p.subservicesWatcher = services.NewFailureWatcher()
+	p.subservicesWatcher.WatchManager(p.subservices)
+	mux := http.NewServeMux()
+	mux.HandleFunc(EmbeddedCachePeersPathPrefix+"fetch", p.serveEntries)
+	mux.HandleFunc(EmbeddedCachePeersPathPrefix+"store", p.serveEntries)
+	p.server = &http.Server{Handler: mux, ReadHeaderTimeout: cfg.Timeout}
+
+	p.Service = services.NewBasicService(p.starting, p.running, p.stopping)
+	return p, nil
+}
+
+func (p *EmbeddedCachePeers) starting(ctx context.Context) error {
+	go func() {
+		if err := p.server.Serve(p.listener); err != http.ErrServerClosed {
+			p.serveErr <- err
+		}
+	}()
+	if err := services.StartManagerAndAwaitHealthy(ctx, p.subservices); err != nil {
+		return errors.Wrap(err, "unable to start embedded cache peers subservices")
+	}
+	// The peers own keys as soon as they are in the ring, as the entries of a
+	// cache can be missing anyway.
+	return ring.WaitInstanceState(ctx, p.ring, p.lifecycler.GetInstanceID(), ring.ACTIVE)
+}
+
+func (p *EmbeddedCachePeers) running(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-p.subservicesWatcher.Chan():
+		return errors.Wrap(err, "running embedded cache peers subservice failed")
+	case err := <-p.serveErr:
+		return errors.Wrap(err, "serving the embedded cache entries failed")
+	}
+}
+
+func (p *EmbeddedCachePeers) stopping(_ error) error {
+	err := services.StopManagerAndAwaitStopped(context.Background(), p.subservices)
+	// The entries are served until this process left the ring.
+	if closeErr := p.server.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// OnRingInstanceRegister implements ring.BasicLifecyclerDelegate.
+func (p *EmbeddedCachePeers) OnRingInstanceRegister(_ *ring.BasicLifecycler, ringDesc ring.Desc, instanceExists bool, _ string, instanceDesc ring.InstanceDesc) (ring.InstanceState, ring.Tokens) {
+	var tokens []uint32
+	if instanceExists {
+		tokens = instanceDesc.GetTokens()
+	}
+	tokens = append(tokens, ring.GenerateTokens(embeddedCacheRingNumTokens-len(tokens), ringDesc.GetTokens())...)
+	return ring.ACTIVE, tokens
+}
+
+func (p *EmbeddedCachePeers) OnRingInstanceTokens(_ *ring.BasicLifecycler, _ ring.Tokens) {}
+func (p *EmbeddedCachePeers) OnRingInstanceStopping(_ *ring.BasicLifecycler)              {}
+func (p *EmbeddedCachePeers) OnRingInstanceHeartbeat(_ *ring.BasicLifecycler, _ *ring.Desc, _ *ring.InstanceDesc) {
+}
+
+// register returns the local entries of the cache, created on first use.
+// The caches of the same name share their entries, as they are the same
+// cache in the different processes.
+func (p *EmbeddedCachePeers) register(name string, cfg EmbeddedCacheConfig) *embeddedLRU {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if c, ok := p.caches[name]; ok {
+		return c
+	}
+	c := newEmbeddedLRU(cfg.MaxSizeMB*1e6, cfg.MaxSizeMBPerTenant*1e6, cfg.TTL, newEmbeddedCacheEvictions(name, p.reg))
+	p.caches[name] = c
+	return c
+}
+
+func (p *EmbeddedCachePeers) local(name string) (*embeddedLRU, bool) {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+	c, ok := p.caches[name]
+	return c, ok
+}
+
+// owner returns the address of the peer owning the key, or an empty string if
+// the key is owned by this process.
+func (p *EmbeddedCachePeers) owner(key string) (string, error) {
+	set, err := p.ring.Get(uint32(xxhash.Sum64String(key)), embeddedCacheRingOp, nil, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	if addr := set.Instances[0].Addr; addr != p.lifecycler.GetInstanceAddr() {
+		return addr, nil
+	}
+	return "", nil
+}
+
+type peerRequest struct {
+	Keys []string `json:"keys"`
+	Bufs [][]byte `json:"bufs,omitempty"`
+}
+
+func (p *EmbeddedCachePeers) call(ctx context.Context, addr, operation, name string, req peerRequest) (resp peerRequest, err error) {
+	defer func() {
+		status := "success"
+		if err != nil {
+			status = "failure"
+		}
+		p.peerRequests.WithLabelValues(operation, status).Inc()
+	}()
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return resp, err
+	}
+	u := fmt.Sprintf("http://%s%s%s?cache=%s", addr, EmbeddedCachePeersPathPrefix, operation, url.QueryEscape(name))
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return resp, err
+	}
+	if tenantID, err := tenant.TenantID(ctx); err == nil {
+		r.Header.Set(user.OrgIDHeaderName, tenantID)
+	}
+	res, err := p.client.Do(r)
+	if err != nil {
+		return resp, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return resp, fmt.Errorf("unexpected status code %d from peer %s", res.StatusCode, addr)
+	}
+	if operation == "fetch" {
+		err = json.NewDecoder(res.Body).Decode(&resp)
+	}
+	return resp, err
+}
+
+// ServeHTTP serves the ring status page.
+func (p *EmbeddedCachePeers) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.ring.ServeHTTP(w, r)
+}
+
+// serveEntries serves the entries of this process to the other peers. The
+// entries are scoped to the tenant of the request.
+func (p *EmbeddedCachePeers) serveEntries(w http.ResponseWriter, r *http.Request) {
+	operation := r.URL.Path[len(EmbeddedCachePeersPathPrefix):]
+	if r.Method != http.MethodPost {
+		http.NotFound(w, r)
+		return
+	}
+	c, ok := p.local(r.URL.Query().Get("cache"))
+	if !ok {
+		http.Error(w, "unknown cache", http.StatusNotFound)
+		return
+	}
+	var req peerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	tenantID := r.Header.Get(user.OrgIDHeaderName)
+	if operation == "store" {
+		if len(req.Keys) != len(req.Bufs) {
+			http.Error(w, "mismatching number of keys and values", http.StatusBadRequest)
+			return
+		}
+		for i, key := range req.Keys {
+			c.put(tenantID, key, req.Bufs[i], now)
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var resp peerRequest
+	for _, key := range req.Keys {
+		if buf, ok := c.get(tenantID, key, now); ok {
+			resp.Keys = append(resp.Keys, key)
+			resp.Bufs = append(resp.Bufs, buf)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		level.Error(p.logger).Log("msg", "failed to write embedded cache response", "err", err)
+	}
+}
+
+func newEmbeddedCacheEvictions(name string, reg prometheus.Registerer) *prometheus.CounterVec {
+	return promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+		Namespace:   "loki",
+		Subsystem:   "embedded_cache",
+		Name:        "evicted_total",
+		Help:        "The total number of entries evicted from the embedded cache.",
+		ConstLabels: prometheus.Labels{"cache": name},
+	}, []string{"reason"})
+}
+
+// embeddedCache is an embedded cache bounding the size of the entries of
+// every tenant. If distributed, the keys are partitioned across the peers.
+type embeddedCache struct {
+	name      string
+	cacheType stats.CacheType
+	local     *embeddedLRU
+	peers     *EmbeddedCachePeers
+	logger    log.Logger
+}
+
+// NewEmbeddedCache creates an embedded cache. The cache is distributed across
+// the peers, if any.
+func NewEmbeddedCache(name string, cfg EmbeddedCacheConfig, peers *EmbeddedCachePeers, reg prometheus.Registerer, logger log.Logger, cacheType stats.CacheType) Cache {
+	c := &embeddedCache{name: name, cacheType: cacheType, peers: peers, logger: logger}
+	if peers != nil {
+		c.local = peers.register(name, cfg)
+	} else {
+		c.local = newEmbeddedLRU(cfg.MaxSizeMB*1e6, cfg.MaxSizeMBPerTenant*1e6, cfg.TTL, newEmbeddedCacheEvictions(name, reg))
+	}
+	return c
+}
+
+// partition groups the keys by the address of their owner. The keys owned by
+// this process are grouped under the empty address.
+func (c *embeddedCache) partition(keys []string) map[string][]int {
+	byOwner := map[string][]int{}
+	for i, key := range keys {
+		addr := ""
+		if c.peers != nil {
+			var err error
+			if addr, err = c.peers.owner(key); err != nil {
+				level.Warn(c.logger).Log("msg", "failed to find the owner of the key, using the local cache", "cache", c.name, "err", err)
+				addr = ""
+			}
+		}
+		byOwner[addr] = append(byOwner[addr], i)
+	}
+	return byOwner
+}
+
+func (c *embeddedCache) Store(ctx context.Context, keys []string, bufs [][]byte) error {
+	tenantID, _ := tenant.TenantID(ctx)
+	now := time.Now()
+	var wg sync.WaitGroup
+	for addr, idxs := range c.partition(keys) {
+		if addr == "" {
+			for _, i := range idxs {
+				c.local.put(tenantID, keys[i], bufs[i], now)
+			}
+			continue
+		}
+		req := peerRequest{}
+		for _, i := range idxs {
+			req.Keys = append(req.Keys, keys[i])
+			req.Bufs = append(req.Bufs, bufs[i])
+		}
+		wg.Add(1)
+		go func(addr string) {
+			defer wg.Done()
+			if _, err := c.peers.call(ctx, addr, "store", c.name, req); err != nil {
+				level.Warn(c.logger).Log("msg", "failed to store entries on peer", "cache", c.name, "peer", addr, "err", err)
+			}
+		}(addr)
+	}
+	wg.Wait()
+	return nil
+}
+
+func (c *embeddedCache) Fetch(ctx context.Context, keys []string) (found []string, bufs [][]byte, missing []string, err error) {
+	tenantID, _ := tenant.TenantID(ctx)
+	now := time.Now()
+	results := make([][]byte, len(keys))
+	hits := make([]bool, len(keys))
+
+	var wg sync.WaitGroup
+	for addr, idxs := range c.partition(keys) {
+		if addr == "" {
+			for _, i := range idxs {
+				results[i], hits[i] = c.local.get(tenantID, keys[i], now)
+			}
+			continue
+		}
+		wg.Add(1)
+		go func(addr string, idxs []int) {
+			defer wg.Done()
+			req := peerRequest{}
+			for _, i := range idxs {
+				req.Keys = append(req.Keys, keys[i])
+			}
+			resp, err := c.peers.call(ctx, addr, "fetch", c.name, req)
+			if err != nil {
+				level.Warn(c.logger).Log("msg", "failed to fetch entries from peer", "cache", c.name, "peer", addr, "err", err)
+				return
+			}
+			if len(resp.Keys) != len(resp.Bufs) {
+				level.Warn(c.logger).Log("msg", "mismatching number of keys and values fetched from peer", "cache", c.name, "peer", addr)
+				return
+			}
+			values := make(map[string][]byte, len(resp.Keys))
+			for j, key := range resp.Keys {
+				values[key] = resp.Bufs[j]
+			}
+			for _, i := range idxs {
+				results[i], hits[i] = values[keys[i]]
+			}
+		}(addr, idxs)
+	}
+	wg.Wait()
+
+	for i, key := range keys {
+		if hits[i] {
+			found = append(found, key)
+			bufs = append(bufs, results[i])
+		} else {
+			missing = append(missing, key)
+		}
+	}
+	return found, bufs, missing, nil
+}
+
+func (c *embeddedCache) Stop() {}
+
+func (c *embeddedCache) GetCacheType() stats.CacheType {
+	return c.cacheType
+}
@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/kv/consul"
+	"github.com/grafana/dskit/ring"
+	"github.com/grafana/dskit/services"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/loki/pkg/logqlmodel/stats"
+)
+
+func TestEmbeddedCachePeers(t *testing.T) {
+	store, closer := consul.NewInMemoryClient(ring.GetCodec(), log.NewNopLogger(), nil)
+	t.Cleanup(func() { closer.Close() })
+
+	cfg := EmbeddedCacheConfig{Enabled: true, Distributed: true, MaxSizeMB: 1, TTL: time.Hour}
+	var caches []Cache
+	for i := 0; i < 2; i++ {
+		var peersCfg EmbeddedCachePeersConfig
+		peersCfg.Timeout = time.Second
+		peersCfg.ListenAddress = "127.0.0.1"
+		peersCfg.Ring.KVStore.Mock = store
+		peersCfg.Ring.HeartbeatPeriod = time.Second
+		peersCfg.Ring.HeartbeatTimeout = time.Minute
+		peersCfg.Ring.InstanceID = fmt.Sprintf("peer-%d", i)
+		peersCfg.Ring.InstanceAddr = "127.0.0.1"
+
+		peers, err := NewEmbeddedCachePeers(peersCfg, log.NewNopLogger(), prometheus.NewRegistry())
+		require.NoError(t, err)
+		require.NoError(t, services.StartAndAwaitRunning(context.Background(), peers))
+		t.Cleanup(func() {
+			require.NoError(t, services.StopAndAwaitTerminated(context.Background(), peers))
+		})
+
+		caches = append(caches, NewEmbeddedCache("results", cfg, peers, nil, log.NewNopLogger(), stats.ResultCache))
+	}
+
+	// Wait until both peers see each other in the ring.
+	for _, c := range caches {
+		p := c.(*embeddedCache).peers
+		require.Eventually(t, func() bool { return p.ring.InstancesCount() == 2 }, 5*time.Second, 10*time.Millisecond)
+	}
+
+	ctx := user.InjectOrgID(context.Background(), "fake")
+	keys := make([]string, 0, 20)
+	bufs := make([][]byte, 0, 20)
+	for i := 0; i < 20; i++ {
+		keys = append(keys, fmt.Sprintf("key-%d", i))
+		bufs = append(bufs, []byte(fmt.Sprintf("value-%d", i)))
+	}
+	require.NoError(t, caches[0].Store(ctx, keys, bufs))
+
+	// The keys are partitioned across both peers.
+	for _, c := range caches {
+		entries := c.(*embeddedCache).local.entries
+		require.NotEmpty(t, entries)
+		require.Less(t, len(entries), len(keys))
+	}
+
+	// Every peer finds all keys, in order.
+	for _, c := range caches {
+		found, foundBufs, missing, err := c.Fetch(ctx, append(keys, "unknown"))
+		require.NoError(t, err)
+		require.Equal(t, keys, found)
+		require.Equal(t, bufs, foundBufs)
+		require.Equal(t, []string{"unknown"}, missing)
+	}
+
+	// The entries are scoped to their tenant.
+	found, _, missing, err := caches[1].Fetch(user.InjectOrgID(context.Background(), "other"), keys)
+	require.NoError(t, err)
+	require.Empty(t, found)
+	require.Equal(t, keys, missing)
+
+	// The ring status page doesn't serve the entries.
+	peers := caches[0].(*embeddedCache).peers
+	w := httptest.NewRecorder()
+	peers.ServeHTTP(w, httptest.NewRequest(http.MethodPost, EmbeddedCachePeersPathPrefix+"fetch?cache=results", strings.NewReader(`{"keys":["key-0"]}`)))
+	require.NotContains(t, w.Body.String(), "value-0")
+}
+
+func TestEmbeddedCache_Local(t *testing.T) {
+	cfg := EmbeddedCacheConfig{Enabled: true, MaxSizeMB: 1, MaxSizeMBPerTenant: 1, TTL: time.Hour}
+	c := NewEmbeddedCache("local", cfg, nil, prometheus.NewRegistry(), log.NewNopLogger(), stats.ResultCache)
+
+	ctx := user.InjectOrgID(context.Background(), "fake")
+	require.NoError(t, c.Store(ctx, []string{"a", "b"}, [][]byte{[]byte("1"), []byte("2")}))
+	found, bufs, missing, err := c.Fetch(ctx, []string{"a", "c", "b"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, found)
+	require.Equal(t, [][]byte{[]byte("1"), []byte("2")}, bufs)
+	require.Equal(t, []string{"c"}, missing)
+	require.Contains(t, c.(*embeddedCache).local.tenants, "fake")
+}
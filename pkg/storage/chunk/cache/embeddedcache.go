@@ -15,6 +15,12 @@ type EmbeddedCacheConfig struct {
 	MaxSizeMB int64         `yaml:"max_size_mb"`
 	TTL       time.Duration `yaml:"ttl"`
 
+	Distributed        bool  `yaml:"distributed" category:"experimental"`
+	MaxSizeMBPerTenant int64 `yaml:"max_size_mb_per_tenant" category:"experimental"`
+
+	// Peers is injected internally to share the distributed embedded caches.
+	Peers *EmbeddedCachePeers `yaml:"-"`
+
 	// PurgeInterval tell how often should we remove keys that are expired.
 	// by default it takes `DefaultPurgeInterval`
 	PurgeInterval time.Duration `yaml:"-"`
@@ -24,9 +30,16 @@ func (cfg *EmbeddedCacheConfig) RegisterFlagsWithPrefix(prefix, description stri
 	f.BoolVar(&cfg.Enabled, prefix+"embedded-cache.enabled", false, description+"Whether embedded cache is enabled.")
 	f.Int64Var(&cfg.MaxSizeMB, prefix+"embedded-cache.max-size-mb", 100, description+"Maximum memory size of the cache in MB.")
 	f.DurationVar(&cfg.TTL, prefix+"embedded-cache.ttl", time.Hour, description+"The time to live for items in the cache before they get purged.")
-
+	f.BoolVar(&cfg.Distributed, prefix+"embedded-cache.distributed", false, description+"Experimental. Partition the keys of the cache across the peers of the embedded cache ring, so the processes share their cached items.")
+	f.Int64Var(&cfg.MaxSizeMBPerTenant, prefix+"embedded-cache.max-size-mb-per-tenant", 0, description+"Experimental. Maximum memory size of the items of a tenant in the cache in MB, per process. 0 to disable.")
 }
 
 func (cfg *EmbeddedCacheConfig) IsEnabled() bool {
 	return cfg.Enabled
 }
+
+// usesTenantLRU tells if the cache needs the features of the embedded LRU
+// rather than the FIFO cache.
+func (cfg *EmbeddedCacheConfig) usesTenantLRU() bool {
+	return cfg.Distributed || cfg.MaxSizeMBPerTenant > 0
+}
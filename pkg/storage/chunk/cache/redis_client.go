@@ -2,7 +2,6 @@ package cache
 
 import (
 	"context"
-	"crypto/tls"
 	"flag"
 	"fmt"
 	"net"
@@ -10,6 +9,7 @@ import (
 	"time"
 	"unsafe"
 
+	dstls "github.com/grafana/dskit/crypto/tls"
 	"github.com/grafana/dskit/flagext"
 
 	"github.com/go-redis/redis/v8"
@@ -17,19 +17,20 @@ import (
 
 // RedisConfig defines how a RedisCache should be constructed.
 type RedisConfig struct {
-	Endpoint           string         `yaml:"endpoint"`
-	MasterName         string         `yaml:"master_name"`
-	Timeout            time.Duration  `yaml:"timeout"`
-	Expiration         time.Duration  `yaml:"expiration"`
-	DB                 int            `yaml:"db"`
-	PoolSize           int            `yaml:"pool_size"`
-	Username           string         `yaml:"username"`
-	Password           flagext.Secret `yaml:"password"`
-	EnableTLS          bool           `yaml:"tls_enabled"`
-	InsecureSkipVerify bool           `yaml:"tls_insecure_skip_verify"`
-	IdleTimeout        time.Duration  `yaml:"idle_timeout"`
-	MaxConnAge         time.Duration  `yaml:"max_connection_age"`
-	RouteRandomly      bool           `yaml:"route_randomly"`
+	Endpoint      string         `yaml:"endpoint"`
+	MasterName    string         `yaml:"master_name"`
+	Timeout       time.Duration  `yaml:"timeout"`
+	Expiration    time.Duration  `yaml:"expiration"`
+	DB            int            `yaml:"db"`
+	PoolSize      int            `yaml:"pool_size"`
+	Username      string         `yaml:"username"`
+	Password      flagext.Secret `yaml:"password"`
+	EnableTLS     bool           `yaml:"tls_enabled"`
+	IdleTimeout   time.Duration  `yaml:"idle_timeout"`
+	MaxConnAge    time.Duration  `yaml:"max_connection_age"`
+	RouteRandomly bool           `yaml:"route_randomly"`
+
+	TLS dstls.ClientConfig `yaml:",inline"`
 }
 
 // RegisterFlagsWithPrefix adds the flags required to config this to the given FlagSet
@@ -43,10 +44,10 @@ func (cfg *RedisConfig) RegisterFlagsWithPrefix(prefix, description string, f *f
 	f.StringVar(&cfg.Username, prefix+"redis.username", "", description+"Username to use when connecting to redis.")
 	f.Var(&cfg.Password, prefix+"redis.password", description+"Password to use when connecting to redis.")
 	f.BoolVar(&cfg.EnableTLS, prefix+"redis.tls-enabled", false, description+"Enable connecting to redis with TLS.")
-	f.BoolVar(&cfg.InsecureSkipVerify, prefix+"redis.tls-insecure-skip-verify", false, description+"Skip validating server certificate.")
 	f.DurationVar(&cfg.IdleTimeout, prefix+"redis.idle-timeout", 0, description+"Close connections after remaining idle for this duration. If the value is zero, then idle connections are not closed.")
 	f.DurationVar(&cfg.MaxConnAge, prefix+"redis.max-connection-age", 0, description+"Close connections older than this duration. If the value is zero, then the pool does not close connections based on age.")
 	f.BoolVar(&cfg.RouteRandomly, prefix+"redis.route-randomly", false, description+"By default, the Redis client only reads from the master node. Enabling this option can lower pressure on the master node by randomly routing read-only commands to the master and any available replicas.")
+	cfg.TLS.RegisterFlagsWithPrefix(prefix+"redis", f)
 }
 
 type RedisClient struct {
@@ -74,7 +75,9 @@ func NewRedisClient(cfg *RedisConfig) (*RedisClient, error) {
 		RouteRandomly: cfg.RouteRandomly,
 	}
 	if cfg.EnableTLS {
-		opt.TLSConfig = &tls.Config{InsecureSkipVerify: cfg.InsecureSkipVerify}
+		if opt.TLSConfig, err = cfg.TLS.GetTLSConfig(); err != nil {
+			return nil, fmt.Errorf("invalid redis TLS config: %w", err)
+		}
 	}
 	return &RedisClient{
 		expiration: cfg.Expiration,
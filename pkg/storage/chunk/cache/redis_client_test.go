@@ -101,6 +101,20 @@ func mockRedisClientCluster() (*RedisClient, error) {
 	}, nil
 }
 
+func TestRedisClientTLS(t *testing.T) {
+	cfg := &RedisConfig{Endpoint: "localhost:6379", EnableTLS: true}
+	cfg.TLS.InsecureSkipVerify = true
+	cfg.TLS.ServerName = "redis"
+	c, err := NewRedisClient(cfg)
+	require.NoError(t, err)
+	defer c.Close()
+
+	// A client certificate requires its key.
+	cfg.TLS.CertPath = "cert.pem"
+	_, err = NewRedisClient(cfg)
+	require.Error(t, err)
+}
+
 func Test_deriveEndpoints(t *testing.T) {
 	const (
 		upstream   = "upstream"
@@ -0,0 +1,153 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// decreaseFactor is the factor the parallelism is multiplied by when the store is overloaded.
+const decreaseFactor = 0.9
+
+// AdaptiveParallelismConfig configures the adaptive parallelism of the chunk downloads from a store.
+type AdaptiveParallelismConfig struct {
+	Enabled        bool          `yaml:"enabled"`
+	MinParallelism int           `yaml:"min_parallelism"`
+	MaxParallelism int           `yaml:"max_parallelism"`
+	TargetLatency  time.Duration `yaml:"target_latency"`
+}
+
+// RegisterFlagsWithPrefix registers flags with the given prefix.
+func (cfg *AdaptiveParallelismConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, prefix+"enabled", false, "Adapt the number of parallel chunk downloads from each object store to its latency and errors, instead of downloading up to -store.max-parallel-get-chunk chunks in parallel per query. The parallelism is increased by about one after as many downloads as the current parallelism within the target latency, and decreased by 10% when the downloads fail or exceed the target latency.")
+	f.IntVar(&cfg.MinParallelism, prefix+"min-parallelism", 10, "Minimum number of parallel chunk downloads from each object store, across all queries.")
+	f.IntVar(&cfg.MaxParallelism, prefix+"max-parallelism", 500, "Maximum number of parallel chunk downloads from each object store, across all queries.")
+	f.DurationVar(&cfg.TargetLatency, prefix+"target-latency", time.Second, "Latency of the chunk downloads above which the object store is considered overloaded and the parallelism is decreased.")
+}
+
+// Validate validates the config.
+func (cfg *AdaptiveParallelismConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.MinParallelism < 1 {
+		return fmt.Errorf("the minimum parallelism must be at least 1, got %d", cfg.MinParallelism)
+	}
+	if cfg.MaxParallelism < cfg.MinParallelism {
+		return fmt.Errorf("the maximum parallelism %d must not be lower than the minimum parallelism %d", cfg.MaxParallelism, cfg.MinParallelism)
+	}
+	if cfg.TargetLatency <= 0 {
+		return errors.New("the target latency must be positive")
+	}
+	return nil
+}
+
+// ParallelismLimits are the per-tenant limits of the chunk downloads.
+type ParallelismLimits interface {
+	MaxParallelGetChunk(userID string) int
+}
+
+// AdaptiveParallelism limits the chunk downloads from a store in flight, with a limit
+// increased additively while the downloads succeed within the target latency and decreased
+// multiplicatively otherwise. The downloads of each tenant are also limited by its limits.
+type AdaptiveParallelism struct {
+	cfg    AdaptiveParallelismConfig
+	limits ParallelismLimits
+
+	mtx          sync.Mutex
+	limit        float64
+	inflight     int
+	perTenant    map[string]int
+	lastDecrease time.Time
+	// released is closed and replaced when a download is released, to wake up the waiting downloads.
+	released chan struct{}
+
+	limitGauge prometheus.Gauge
+	now        func() time.Time
+}
+
+// NewAdaptiveParallelism returns an adaptive parallelism starting at the minimum parallelism.
+func NewAdaptiveParallelism(cfg AdaptiveParallelismConfig, limits ParallelismLimits, registerer prometheus.Registerer) *AdaptiveParallelism {
+	p := &AdaptiveParallelism{
+		cfg:       cfg,
+		limits:    limits,
+		limit:     float64(cfg.MinParallelism),
+		perTenant: map[string]int{},
+		released:  make(chan struct{}),
+		limitGauge: promauto.With(registerer).NewGauge(prometheus.GaugeOpts{
+			Namespace: "loki",
+			Name:      "chunk_store_adaptive_parallelism",
+			Help:      "Current limit of the parallel chunk downloads from the object store.",
+		}),
+		now: time.Now,
+	}
+	p.limitGauge.Set(p.limit)
+	return p
+}
+
+// Limit returns the current limit of the parallel downloads.
+func (p *AdaptiveParallelism) Limit() int {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return int(p.limit)
+}
+
+// Acquire waits until a download of the tenant is allowed, or the context is done.
+func (p *AdaptiveParallelism) Acquire(ctx context.Context, userID string) error {
+	for {
+		p.mtx.Lock()
+		tenantLimit := p.limits.MaxParallelGetChunk(userID)
+		if p.inflight < int(p.limit) && (tenantLimit <= 0 || p.perTenant[userID] < tenantLimit) {
+			p.inflight++
+			p.perTenant[userID]++
+			p.mtx.Unlock()
+			return nil
+		}
+		released := p.released
+		p.mtx.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-released:
+		}
+	}
+}
+
+// Release releases a download of the tenant acquired with Acquire, and adapts the limit
+// to its latency and whether the store failed. Errors which are not caused by the store,
+// like not found objects or canceled requests, must be reported as nil.
+func (p *AdaptiveParallelism) Release(userID string, latency time.Duration, storeErr error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.inflight--
+	if p.perTenant[userID]--; p.perTenant[userID] <= 0 {
+		delete(p.perTenant, userID)
+	}
+
+	if storeErr == nil && latency <= p.cfg.TargetLatency {
+		p.limit += 1 / p.limit
+		if max := float64(p.cfg.MaxParallelism); p.limit > max {
+			p.limit = max
+		}
+	} else if now := p.now(); now.Sub(p.lastDecrease) >= p.cfg.TargetLatency {
+		// The downloads in flight fail together when the store is overloaded,
+		// so the limit is decreased at most once per target latency.
+		p.lastDecrease = now
+		p.limit *= decreaseFactor
+		if min := float64(p.cfg.MinParallelism); p.limit < min {
+			p.limit = min
+		}
+	}
+	p.limitGauge.Set(float64(int(p.limit)))
+
+	close(p.released)
+	p.released = make(chan struct{})
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type parallelismLimits map[string]int
+
+func (l parallelismLimits) MaxParallelGetChunk(userID string) int {
+	return l[userID]
+}
+
+func TestAdaptiveParallelism(t *testing.T) {
+	cfg := AdaptiveParallelismConfig{Enabled: true, MinParallelism: 2, MaxParallelism: 4, TargetLatency: time.Second}
+	require.NoError(t, cfg.Validate())
+	p := NewAdaptiveParallelism(cfg, parallelismLimits{}, nil)
+	now := time.Unix(0, 0)
+	p.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	require.Equal(t, 2, p.Limit())
+
+	// The limit is increased by about one after as many successful downloads as the limit.
+	for i := 0; i < 3; i++ {
+		require.NoError(t, p.Acquire(ctx, "fake"))
+		p.Release("fake", 100*time.Millisecond, nil)
+	}
+	require.Equal(t, 3, p.Limit())
+	for i := 0; i < 10; i++ {
+		require.NoError(t, p.Acquire(ctx, "fake"))
+		p.Release("fake", 100*time.Millisecond, nil)
+	}
+	require.Equal(t, 4, p.Limit())
+
+	// The limit is decreased by a slow or a failed download, at most once per target latency.
+	now = now.Add(time.Minute)
+	require.NoError(t, p.Acquire(ctx, "fake"))
+	p.Release("fake", 2*time.Second, nil)
+	require.Equal(t, 3, p.Limit())
+	require.NoError(t, p.Acquire(ctx, "fake"))
+	p.Release("fake", 100*time.Millisecond, errors.New("slow down"))
+	require.Equal(t, 3, p.Limit())
+
+	for i := 0; i < 10; i++ {
+		now = now.Add(time.Second)
+		require.NoError(t, p.Acquire(ctx, "fake"))
+		p.Release("fake", 100*time.Millisecond, errors.New("slow down"))
+	}
+	require.Equal(t, 2, p.Limit())
+}
+
+func TestAdaptiveParallelismAcquire(t *testing.T) {
+	cfg := AdaptiveParallelismConfig{Enabled: true, MinParallelism: 2, MaxParallelism: 2, TargetLatency: time.Second}
+	p := NewAdaptiveParallelism(cfg, parallelismLimits{"limited": 1}, nil)
+
+	// The downloads of a tenant are limited by its limit.
+	require.NoError(t, p.Acquire(context.Background(), "limited"))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, p.Acquire(ctx, "limited"), context.DeadlineExceeded)
+
+	// The downloads of all tenants are limited by the limit of the store.
+	require.NoError(t, p.Acquire(context.Background(), "fake"))
+	acquired := make(chan error)
+	go func() {
+		acquired <- p.Acquire(context.Background(), "fake")
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("acquired a download beyond the limit")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	p.Release("limited", 100*time.Millisecond, nil)
+	require.NoError(t, <-acquired)
+}
@@ -81,6 +81,9 @@ type client struct {
 	keyEncoder          KeyEncoder
 	getChunkMaxParallel int
 	schema              config.SchemaConfig
+
+	// parallelism limits the chunk downloads across all queries, if not nil.
+	parallelism *AdaptiveParallelism
 }
 
 // NewClient wraps the provided ObjectClient with a chunk.Client implementation
@@ -97,6 +100,18 @@ func NewClientWithMaxParallel(store ObjectClient, encoder KeyEncoder, maxParalle
 	}
 }
 
+// NewClientWithAdaptiveParallelism wraps the provided ObjectClient with a chunk.Client implementation
+// downloading the chunks with the adaptive parallelism.
+func NewClientWithAdaptiveParallelism(store ObjectClient, encoder KeyEncoder, parallelism *AdaptiveParallelism, schema config.SchemaConfig) Client {
+	return &client{
+		store:               store,
+		keyEncoder:          encoder,
+		getChunkMaxParallel: parallelism.cfg.MaxParallelism,
+		schema:              schema,
+		parallelism:         parallelism,
+	}
+}
+
 // Stop shuts down the object store and any underlying clients
 func (o *client) Stop() {
 	o.store.Stop()
@@ -163,13 +178,38 @@ func (o *client) getChunk(ctx context.Context, decodeContext *chunk.DecodeContex
 		key = o.keyEncoder(o.schema, c)
 	}
 
+	if o.parallelism != nil {
+		if err := o.parallelism.Acquire(ctx, c.UserID); err != nil {
+			return chunk.Chunk{}, err
+		}
+	}
+	start := time.Now()
+	buf, err := o.getObject(ctx, key)
+	if o.parallelism != nil {
+		storeErr := err
+		if ctx.Err() != nil || o.store.IsObjectNotFoundErr(errors.Cause(err)) {
+			storeErr = nil
+		}
+		o.parallelism.Release(c.UserID, time.Since(start), storeErr)
+	}
+	if err != nil {
+		return chunk.Chunk{}, err
+	}
+
+	if err := c.Decode(decodeContext, buf); err != nil {
+		return chunk.Chunk{}, errors.WithStack(err)
+	}
+	return c, nil
+}
+
+func (o *client) getObject(ctx context.Context, key string) ([]byte, error) {
 	readCloser, size, err := o.store.GetObject(ctx, key)
 	if err != nil {
-		return chunk.Chunk{}, errors.WithStack(errors.Wrapf(err, "failed to load chunk '%s'", key))
+		return nil, errors.WithStack(errors.Wrapf(err, "failed to load chunk '%s'", key))
 	}
 
 	if readCloser == nil {
-		return chunk.Chunk{}, errors.New("object client getChunk fail because object is nil")
+		return nil, errors.New("object client getChunk fail because object is nil")
 	}
 	defer readCloser.Close()
 
@@ -178,13 +218,9 @@ func (o *client) getChunk(ctx context.Context, decodeContext *chunk.DecodeContex
 	buf := bytes.NewBuffer(make([]byte, 0, size+bytes.MinRead))
 	_, err = buf.ReadFrom(readCloser)
 	if err != nil {
-		return chunk.Chunk{}, errors.WithStack(err)
-	}
-
-	if err := c.Decode(decodeContext, buf.Bytes()); err != nil {
-		return chunk.Chunk{}, errors.WithStack(err)
+		return nil, errors.WithStack(err)
 	}
-	return c, nil
+	return buf.Bytes(), nil
 }
 
 // GetChunks retrieves the specified chunks from the configured backend
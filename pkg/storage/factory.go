@@ -63,6 +63,7 @@ func ResetBoltDBIndexClientsWithShipper() {
 type StoreLimits interface {
 	downloads.Limits
 	stores.StoreLimits
+	client.ParallelismLimits
 	CardinalityLimit(string) int
 }
 
@@ -281,6 +282,8 @@ type Config struct {
 	DisableBroadIndexQueries bool         `yaml:"disable_broad_index_queries"`
 	MaxParallelGetChunk      int          `yaml:"max_parallel_get_chunk"`
 
+	AdaptiveParallelGetChunk client.AdaptiveParallelismConfig `yaml:"adaptive_parallel_get_chunk" doc:"description=Configures the adaptive parallelism of the chunk downloads from the object stores."`
+
 	MaxChunkBatchSize   int                 `yaml:"max_chunk_batch_size"`
 	BoltDBShipperConfig shipper.Config      `yaml:"boltdb_shipper" doc:"description=Configures storing index in an Object Store (GCS/S3/Azure/Swift/COS/Filesystem) in the form of boltdb files. Required fields only required when boltdb-shipper is defined in config."`
 	TSDBShipperConfig   indexshipper.Config `yaml:"tsdb_shipper"`
@@ -310,6 +313,7 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.DurationVar(&cfg.IndexCacheValidity, "store.index-cache-validity", 5*time.Minute, "Cache validity for active index entries. Should be no higher than -ingester.max-chunk-idle.")
 	f.BoolVar(&cfg.DisableBroadIndexQueries, "store.disable-broad-index-queries", false, "Disable broad index queries which results in reduced cache usage and faster query performance at the expense of somewhat higher QPS on the index store.")
 	f.IntVar(&cfg.MaxParallelGetChunk, "store.max-parallel-get-chunk", 150, "Maximum number of parallel chunk reads.")
+	cfg.AdaptiveParallelGetChunk.RegisterFlagsWithPrefix("store.adaptive-parallel-get-chunk.", f)
 	cfg.BoltDBShipperConfig.RegisterFlags(f)
 	f.IntVar(&cfg.MaxChunkBatchSize, "store.max-chunk-batch-size", 50, "The maximum number of chunks to fetch per batch.")
 	cfg.TSDBShipperConfig.RegisterFlagsWithPrefix("tsdb.", f)
@@ -341,6 +345,9 @@ func (cfg *Config) Validate() error {
 	if err := cfg.TSDBShipperConfig.Validate(); err != nil {
 		return errors.Wrap(err, "invalid tsdb config")
 	}
+	if err := cfg.AdaptiveParallelGetChunk.Validate(); err != nil {
+		return errors.Wrap(err, "invalid adaptive parallel get chunk config")
+	}
 
 	return cfg.NamedStores.validate()
 }
@@ -416,7 +423,7 @@ func NewIndexClient(periodCfg config.PeriodConfig, tableRange config.TableRange,
 }
 
 // NewChunkClient makes a new chunk.Client of the desired types.
-func NewChunkClient(name string, cfg Config, schemaCfg config.SchemaConfig, clientMetrics ClientMetrics, registerer prometheus.Registerer) (client.Client, error) {
+func NewChunkClient(name string, cfg Config, schemaCfg config.SchemaConfig, limits StoreLimits, clientMetrics ClientMetrics, registerer prometheus.Registerer) (client.Client, error) {
 	var (
 		storeType = name
 	)
@@ -426,6 +433,14 @@ func NewChunkClient(name string, cfg Config, schemaCfg config.SchemaConfig, clie
 		storeType = nsType
 	}
 
+	// The chunks are downloaded from the object stores with the adaptive parallelism if it is enabled.
+	newChunkClient := func(c client.ObjectClient, encoder client.KeyEncoder, maxParallel int) client.Client {
+		if cfg.AdaptiveParallelGetChunk.Enabled {
+			return client.NewClientWithAdaptiveParallelism(c, encoder, client.NewAdaptiveParallelism(cfg.AdaptiveParallelGetChunk, limits, registerer), schemaCfg)
+		}
+		return client.NewClientWithMaxParallel(c, encoder, maxParallel, schemaCfg)
+	}
+
 	switch storeType {
 	case config.StorageTypeInMemory:
 		return testutils.NewMockStorage(), nil
@@ -434,7 +449,7 @@ func NewChunkClient(name string, cfg Config, schemaCfg config.SchemaConfig, clie
 		if err != nil {
 			return nil, err
 		}
-		return client.NewClientWithMaxParallel(c, nil, cfg.MaxParallelGetChunk, schemaCfg), nil
+		return newChunkClient(c, nil, cfg.MaxParallelGetChunk), nil
 	case config.StorageTypeAWSDynamo:
 		if cfg.AWSStorageConfig.DynamoDB.URL == nil {
 			return nil, fmt.Errorf("Must set -dynamodb.url in aws mode")
@@ -449,19 +464,19 @@ func NewChunkClient(name string, cfg Config, schemaCfg config.SchemaConfig, clie
 		if err != nil {
 			return nil, err
 		}
-		return client.NewClientWithMaxParallel(c, nil, cfg.MaxParallelGetChunk, schemaCfg), nil
+		return newChunkClient(c, nil, cfg.MaxParallelGetChunk), nil
 	case config.StorageTypeAlibabaCloud:
 		c, err := alibaba.NewOssObjectClient(context.Background(), cfg.AlibabaStorageConfig)
 		if err != nil {
 			return nil, err
 		}
-		return client.NewClientWithMaxParallel(c, nil, cfg.MaxParallelGetChunk, schemaCfg), nil
+		return newChunkClient(c, nil, cfg.MaxParallelGetChunk), nil
 	case config.StorageTypeBOS:
 		c, err := NewObjectClient(name, cfg, clientMetrics)
 		if err != nil {
 			return nil, err
 		}
-		return client.NewClientWithMaxParallel(c, nil, cfg.MaxChunkBatchSize, schemaCfg), nil
+		return newChunkClient(c, nil, cfg.MaxChunkBatchSize), nil
 	case config.StorageTypeGCP:
 		return gcp.NewBigtableObjectClient(context.Background(), cfg.GCPStorageConfig, schemaCfg)
 	case config.StorageTypeGCPColumnKey, config.StorageTypeBigTable, config.StorageTypeBigTableHashed:
@@ -471,13 +486,13 @@ func NewChunkClient(name string, cfg Config, schemaCfg config.SchemaConfig, clie
 		if err != nil {
 			return nil, err
 		}
-		return client.NewClientWithMaxParallel(c, nil, cfg.MaxParallelGetChunk, schemaCfg), nil
+		return newChunkClient(c, nil, cfg.MaxParallelGetChunk), nil
 	case config.StorageTypeSwift:
 		c, err := NewObjectClient(name, cfg, clientMetrics)
 		if err != nil {
 			return nil, err
 		}
-		return client.NewClientWithMaxParallel(c, nil, cfg.MaxParallelGetChunk, schemaCfg), nil
+		return newChunkClient(c, nil, cfg.MaxParallelGetChunk), nil
 	case config.StorageTypeCassandra:
 		return cassandra.NewObjectClient(cfg.CassandraStorageConfig, schemaCfg, registerer, cfg.MaxParallelGetChunk)
 	case config.StorageTypeFileSystem:
@@ -485,7 +500,7 @@ func NewChunkClient(name string, cfg Config, schemaCfg config.SchemaConfig, clie
 		if err != nil {
 			return nil, err
 		}
-		return client.NewClientWithMaxParallel(c, client.FSEncoder, cfg.MaxParallelGetChunk, schemaCfg), nil
+		return newChunkClient(c, client.FSEncoder, cfg.MaxParallelGetChunk), nil
 	case config.StorageTypeGrpc:
 		return grpc.NewStorageClient(cfg.GrpcConfig, schemaCfg)
 	case config.StorageTypeCOS:
@@ -493,7 +508,7 @@ func NewChunkClient(name string, cfg Config, schemaCfg config.SchemaConfig, clie
 		if err != nil {
 			return nil, err
 		}
-		return client.NewClientWithMaxParallel(c, nil, cfg.MaxParallelGetChunk, schemaCfg), nil
+		return newChunkClient(c, nil, cfg.MaxParallelGetChunk), nil
 	default:
 		return nil, fmt.Errorf("Unrecognized storage client %v, choose one of: %v, %v, %v, %v, %v, %v, %v, %v, %v", name, config.StorageTypeAWS, config.StorageTypeAzure, config.StorageTypeCassandra, config.StorageTypeInMemory, config.StorageTypeGCP, config.StorageTypeBigTable, config.StorageTypeBigTableHashed, config.StorageTypeGrpc, config.StorageTypeCOS)
 	}
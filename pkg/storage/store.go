@@ -185,7 +185,7 @@ func (s *store) chunkClientForPeriod(p config.PeriodConfig) (client.Client, erro
 	chunkClientReg := prometheus.WrapRegistererWith(
 		prometheus.Labels{"component": "chunk-store-" + p.From.String()}, s.registerer)
 
-	chunks, err := NewChunkClient(objectStoreType, s.cfg, s.schemaCfg, s.clientMetrics, chunkClientReg)
+	chunks, err := NewChunkClient(objectStoreType, s.cfg, s.schemaCfg, s.limits, s.clientMetrics, chunkClientReg)
 	if err != nil {
 		return nil, errors.Wrap(err, "error creating object client")
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"math"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -11,6 +12,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/cespare/xxhash/v2"
 	"github.com/go-kit/log/level"
 	"github.com/grafana/dskit/flagext"
 	"github.com/grafana/dskit/kv"
@@ -30,6 +32,7 @@ import (
 	shipper_storage "github.com/grafana/loki/pkg/storage/stores/indexshipper/storage"
 	"github.com/grafana/loki/pkg/util"
 	"github.com/grafana/loki/pkg/util/filter"
+	loki_flagext "github.com/grafana/loki/pkg/util/flagext"
 	util_log "github.com/grafana/loki/pkg/util/log"
 	"github.com/grafana/loki/pkg/validation"
 )
@@ -63,6 +66,10 @@ const (
 	// ringNumTokens sets our single token in the ring,
 	// we only need to insert 1 token to be used for leader election purposes.
 	ringNumTokens = 1
+
+	// ringNumTokensWithSharding sets the tokens of the compactors in the ring when sharding is enabled,
+	// so the tables are evenly divided between the compactors.
+	ringNumTokensWithSharding = 128
 )
 
 var (
@@ -71,26 +78,32 @@ var (
 )
 
 type Config struct {
-	WorkingDirectory          string          `yaml:"working_directory"`
-	SharedStoreType           string          `yaml:"shared_store"`
-	SharedStoreKeyPrefix      string          `yaml:"shared_store_key_prefix"`
-	CompactionInterval        time.Duration   `yaml:"compaction_interval"`
-	ApplyRetentionInterval    time.Duration   `yaml:"apply_retention_interval"`
-	RetentionEnabled          bool            `yaml:"retention_enabled"`
-	RetentionDeleteDelay      time.Duration   `yaml:"retention_delete_delay"`
-	RetentionDeleteWorkCount  int             `yaml:"retention_delete_worker_count"`
-	RetentionTableTimeout     time.Duration   `yaml:"retention_table_timeout"`
-	DeleteRequestStore        string          `yaml:"delete_request_store"`
-	DefaultDeleteRequestStore string          `yaml:"-" doc:"hidden"`
-	DeleteBatchSize           int             `yaml:"delete_batch_size"`
-	DeleteRequestCancelPeriod time.Duration   `yaml:"delete_request_cancel_period"`
-	DeleteMaxInterval         time.Duration   `yaml:"delete_max_interval"`
-	MaxCompactionParallelism  int             `yaml:"max_compaction_parallelism"`
-	UploadParallelism         int             `yaml:"upload_parallelism"`
-	CompactorRing             util.RingConfig `yaml:"compactor_ring,omitempty" doc:"description=The hash ring configuration used by compactors to elect a single instance for running compactions. The CLI flags prefix for this block config is: boltdb.shipper.compactor.ring"`
-	RunOnce                   bool            `yaml:"_" doc:"hidden"`
-	TablesToCompact           int             `yaml:"tables_to_compact"`
-	SkipLatestNTables         int             `yaml:"skip_latest_n_tables"`
+	WorkingDirectory          string                `yaml:"working_directory"`
+	SharedStoreType           string                `yaml:"shared_store"`
+	SharedStoreKeyPrefix      string                `yaml:"shared_store_key_prefix"`
+	CompactionInterval        time.Duration         `yaml:"compaction_interval"`
+	ApplyRetentionInterval    time.Duration         `yaml:"apply_retention_interval"`
+	RetentionWindowStart      string                `yaml:"retention_window_start"`
+	RetentionWindowEnd        string                `yaml:"retention_window_end"`
+	RetentionEnabled          bool                  `yaml:"retention_enabled"`
+	RetentionDryRun           bool                  `yaml:"retention_dry_run"`
+	RetentionDeleteDelay      time.Duration         `yaml:"retention_delete_delay"`
+	RetentionDeleteWorkCount  int                   `yaml:"retention_delete_worker_count"`
+	RetentionDeleteRateLimit  float64               `yaml:"retention_delete_rate_limit"`
+	RetentionTableTimeout     time.Duration         `yaml:"retention_table_timeout"`
+	DeleteRequestStore        string                `yaml:"delete_request_store"`
+	DefaultDeleteRequestStore string                `yaml:"-" doc:"hidden"`
+	DeleteBatchSize           int                   `yaml:"delete_batch_size"`
+	DeleteRequestCancelPeriod time.Duration         `yaml:"delete_request_cancel_period"`
+	DeleteMaxInterval         time.Duration         `yaml:"delete_max_interval"`
+	MaxCompactionParallelism  int                   `yaml:"max_compaction_parallelism"`
+	UploadParallelism         int                   `yaml:"upload_parallelism"`
+	UploadRateLimit           loki_flagext.ByteSize `yaml:"upload_rate_limit"`
+	CompactorRing             util.RingConfig       `yaml:"compactor_ring,omitempty" doc:"description=The hash ring configuration used by compactors to elect a single instance for running compactions. The CLI flags prefix for this block config is: boltdb.shipper.compactor.ring"`
+	RunOnce                   bool                  `yaml:"_" doc:"hidden"`
+	TablesToCompact           int                   `yaml:"tables_to_compact"`
+	SkipLatestNTables         int                   `yaml:"skip_latest_n_tables"`
+	ShardingEnabled           bool                  `yaml:"sharding_enabled"`
 
 	// Deprecated
 	DeletionMode string `yaml:"deletion_mode" doc:"deprecated|description=Use deletion_mode per tenant configuration instead."`
@@ -103,9 +116,13 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.StringVar(&cfg.SharedStoreKeyPrefix, "boltdb.shipper.compactor.shared-store.key-prefix", "index/", "Prefix to add to object keys in shared store. Path separator(if any) should always be a '/'. Prefix should never start with a separator but should always end with it.")
 	f.DurationVar(&cfg.CompactionInterval, "boltdb.shipper.compactor.compaction-interval", 10*time.Minute, "Interval at which to re-run the compaction operation.")
 	f.DurationVar(&cfg.ApplyRetentionInterval, "boltdb.shipper.compactor.apply-retention-interval", 0, "Interval at which to apply/enforce retention. 0 means run at same interval as compaction. If non-zero, it should always be a multiple of compaction interval.")
+	f.StringVar(&cfg.RetentionWindowStart, "boltdb.shipper.compactor.retention-window-start", "", "Time of the day, in UTC and in the HH:MM format, from which retention is applied, e.g. 22:00 to apply retention off-peak. Both the marking of the expired chunks and the deletion of the marked chunks only happen within the window. Retention is applied at any time when empty. Requires -boltdb.shipper.compactor.retention-window-end.")
+	f.StringVar(&cfg.RetentionWindowEnd, "boltdb.shipper.compactor.retention-window-end", "", "Time of the day, in UTC and in the HH:MM format, until which retention is applied. The window spans midnight when it ends before it starts, e.g. 22:00 to 06:00.")
 	f.DurationVar(&cfg.RetentionDeleteDelay, "boltdb.shipper.compactor.retention-delete-delay", 2*time.Hour, "Delay after which chunks will be fully deleted during retention.")
 	f.BoolVar(&cfg.RetentionEnabled, "boltdb.shipper.compactor.retention-enabled", false, "(Experimental) Activate custom (per-stream,per-tenant) retention.")
+	f.BoolVar(&cfg.RetentionDryRun, "boltdb.shipper.compactor.retention-dry-run", false, "Evaluate the retention without deleting any chunk, to preview the chunks a retention configuration would delete. The chunks the retention would delete and their size are reported by the loki_boltdb_shipper_retention_dry_run_expired_chunks and loki_boltdb_shipper_retention_dry_run_expired_bytes metrics and logged, per table and tenant. The delete requests are not processed during a dry run.")
 	f.IntVar(&cfg.RetentionDeleteWorkCount, "boltdb.shipper.compactor.retention-delete-worker-count", 150, "The total amount of worker to use to delete chunks.")
+	f.Float64Var(&cfg.RetentionDeleteRateLimit, "boltdb.shipper.compactor.retention-delete-rate-limit", 0, "The maximum number of chunks deleted per second by all the workers, per object store. 0 means no limit.")
 	f.StringVar(&cfg.DeleteRequestStore, "boltdb.shipper.compactor.delete-request-store", "", "Store used for managing delete requests. Defaults to -boltdb.shipper.compactor.shared-store.")
 	f.IntVar(&cfg.DeleteBatchSize, "boltdb.shipper.compactor.delete-batch-size", 70, "The max number of delete requests to run per compaction cycle.")
 	f.DurationVar(&cfg.DeleteRequestCancelPeriod, "boltdb.shipper.compactor.delete-request-cancel-period", 24*time.Hour, "Allow cancellation of delete request until duration after they are created. Data would be deleted only after delete requests have been older than this duration. Ideally this should be set to at least 24h.")
@@ -113,6 +130,7 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.DurationVar(&cfg.RetentionTableTimeout, "boltdb.shipper.compactor.retention-table-timeout", 0, "The maximum amount of time to spend running retention and deletion on any given table in the index.")
 	f.IntVar(&cfg.MaxCompactionParallelism, "boltdb.shipper.compactor.max-compaction-parallelism", 1, "Maximum number of tables to compact in parallel. While increasing this value, please make sure compactor has enough disk space allocated to be able to store and compact as many tables.")
 	f.IntVar(&cfg.UploadParallelism, "boltdb.shipper.compactor.upload-parallelism", 10, "Number of upload/remove operations to execute in parallel when finalizing a compaction. NOTE: This setting is per compaction operation, which can be executed in parallel. The upper bound on the number of concurrent uploads is upload_parallelism * max_compaction_parallelism.")
+	f.Var(&cfg.UploadRateLimit, "boltdb.shipper.compactor.upload-rate-limit", "The maximum number of bytes per second of the index files uploaded by all the compactions, per object store. The chunks rewritten by the delete requests aren't limited. 0 means no limit. A unit suffix (KB, MB, GB) may be applied.")
 	f.BoolVar(&cfg.RunOnce, "boltdb.shipper.compactor.run-once", false, "Run the compactor one time to cleanup and compact index files only (no retention applied)")
 
 	// Deprecated
@@ -121,9 +139,39 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.CompactorRing.RegisterFlagsWithPrefix("boltdb.shipper.compactor.", "collectors/", f)
 	f.IntVar(&cfg.TablesToCompact, "boltdb.shipper.compactor.tables-to-compact", 0, "Number of tables that compactor will try to compact. Newer tables are chosen when this is less than the number of tables available.")
 	f.IntVar(&cfg.SkipLatestNTables, "boltdb.shipper.compactor.skip-latest-n-tables", 0, "Do not compact N latest tables. Together with -boltdb.shipper.compactor.run-once and -boltdb.shipper.compactor.tables-to-compact, this is useful when clearing compactor backlogs.")
+	f.BoolVar(&cfg.ShardingEnabled, "boltdb.shipper.compactor.sharding-enabled", false, "(Experimental) Divide the tables between all the compactors of the ring, instead of electing a single compactor running the compactions. Each compactor compacts and applies retention to the tables it owns. The deletion must be disabled when sharding is enabled, since each compactor would write its own delete requests store.")
 
 }
 
+// retentionWindowLayout is the layout of the times of the day of the retention window.
+const retentionWindowLayout = "15:04"
+
+// inRetentionWindow returns whether the retention can be applied at the time, which is always the case without window.
+func (cfg *Config) inRetentionWindow(now time.Time) bool {
+	if cfg.RetentionWindowStart == "" {
+		return true
+	}
+
+	// the window has been validated.
+	start, _ := time.Parse(retentionWindowLayout, cfg.RetentionWindowStart)
+	end, _ := time.Parse(retentionWindowLayout, cfg.RetentionWindowEnd)
+	now = now.UTC()
+	minute := now.Hour()*60 + now.Minute()
+	startMinute, endMinute := start.Hour()*60+start.Minute(), end.Hour()*60+end.Minute()
+	if startMinute < endMinute {
+		return minute >= startMinute && minute < endMinute
+	}
+	// the window spans midnight.
+	return minute >= startMinute || minute < endMinute
+}
+
+func (cfg *Config) numRingTokens() int {
+	if cfg.ShardingEnabled {
+		return ringNumTokensWithSharding
+	}
+	return ringNumTokens
+}
+
 // Validate verifies the config does not contain inappropriate values
 func (cfg *Config) Validate() error {
 	if cfg.MaxCompactionParallelism < 1 {
@@ -132,6 +180,34 @@ func (cfg *Config) Validate() error {
 	if cfg.RetentionEnabled && cfg.ApplyRetentionInterval != 0 && cfg.ApplyRetentionInterval%cfg.CompactionInterval != 0 {
 		return errors.New("interval for applying retention should either be set to a 0 or a multiple of compaction interval")
 	}
+	if (cfg.RetentionWindowStart == "") != (cfg.RetentionWindowEnd == "") {
+		return errors.New("both the start and the end of the retention window should be set")
+	}
+	if cfg.RetentionWindowStart != "" {
+		start, err := time.Parse(retentionWindowLayout, cfg.RetentionWindowStart)
+		if err != nil {
+			return fmt.Errorf("invalid start of the retention window: %w", err)
+		}
+		end, err := time.Parse(retentionWindowLayout, cfg.RetentionWindowEnd)
+		if err != nil {
+			return fmt.Errorf("invalid end of the retention window: %w", err)
+		}
+		if start.Equal(end) {
+			return errors.New("the start and the end of the retention window should be different")
+		}
+	}
+	if cfg.RetentionDeleteRateLimit < 0 {
+		return errors.New("retention delete rate limit must be >= 0")
+	}
+	if cfg.UploadRateLimit > math.MaxInt32 {
+		return errors.New("upload rate limit must be < 2GiB")
+	}
+	if cfg.RetentionDryRun && !cfg.RetentionEnabled {
+		return errors.New("retention dry run requires the retention to be enabled")
+	}
+	if cfg.ShardingEnabled && cfg.RunOnce {
+		return errors.New("compactor sharding can't be enabled when running the compactor once")
+	}
 
 	if err := shipper_storage.ValidateSharedStoreKeyPrefix(cfg.SharedStoreKeyPrefix); err != nil {
 		return err
@@ -144,6 +220,13 @@ func (cfg *Config) Validate() error {
 	return nil
 }
 
+// DeleteRequestsEnabled returns whether the compactor stores and processes the delete requests.
+// The delete requests store is a single file uploaded by the compactor writing it, so it can't be
+// written by every compactor when the compactor sharding is enabled.
+func (cfg *Config) DeleteRequestsEnabled() bool {
+	return cfg.RetentionEnabled && !cfg.ShardingEnabled
+}
+
 type Compactor struct {
 	services.Service
 
@@ -212,7 +295,7 @@ func NewCompactor(cfg Config, objectStoreClients map[string]client.ObjectClient,
 	if err != nil {
 		return nil, errors.Wrap(err, "create KV store client")
 	}
-	lifecyclerCfg, err := cfg.CompactorRing.ToLifecyclerConfig(ringNumTokens, util_log.Logger)
+	lifecyclerCfg, err := cfg.CompactorRing.ToLifecyclerConfig(cfg.numRingTokens(), util_log.Logger)
 	if err != nil {
 		return nil, errors.Wrap(err, "invalid ring lifecycler config")
 	}
@@ -256,7 +339,7 @@ func (c *Compactor) init(objectStoreClients map[string]client.ObjectClient, sche
 		return err
 	}
 
-	if c.cfg.RetentionEnabled {
+	if c.cfg.DeleteRequestsEnabled() {
 		deleteRequestsStore := func() string {
 			switch {
 			case c.cfg.DeleteRequestStore != "":
@@ -278,12 +361,18 @@ func (c *Compactor) init(objectStoreClients map[string]client.ObjectClient, sche
 		if err := c.initDeletes(objectClient, r, limits); err != nil {
 			return fmt.Errorf("failed to init delete store: %w", err)
 		}
+	} else if c.cfg.RetentionEnabled {
+		// the sharded compactors don't process the delete requests, see DeleteRequestsEnabled.
+		c.expirationChecker = newExpirationChecker(retention.NewExpirationChecker(limits, r), retention.NeverExpiringExpirationChecker(limits))
 	}
 
 	c.storeContainers = make(map[string]storeContainer, len(objectStoreClients))
 	for objectStoreType, objectClient := range objectStoreClients {
 		var sc storeContainer
 		sc.indexStorageClient = shipper_storage.NewIndexStorageClient(objectClient, c.cfg.SharedStoreKeyPrefix)
+		if c.cfg.UploadRateLimit > 0 {
+			sc.indexStorageClient = newRateLimitedUploadClient(sc.indexStorageClient, int(c.cfg.UploadRateLimit))
+		}
 
 		if c.cfg.RetentionEnabled {
 			// given that compaction can now run on multiple object stores, marker files are stored under /retention/{objectStoreType}/markers/
@@ -303,14 +392,18 @@ func (c *Compactor) init(objectStoreClients map[string]client.ObjectClient, sche
 			}
 			chunkClient := client.NewClient(objectClient, encoder, schemaConfig)
 
-			sc.sweeper, err = retention.NewSweeper(retentionWorkDir, chunkClient, c.cfg.RetentionDeleteWorkCount, c.cfg.RetentionDeleteDelay, r)
+			sc.sweeper, err = retention.NewSweeper(retentionWorkDir, chunkClient, c.cfg.RetentionDeleteWorkCount, c.cfg.RetentionDeleteDelay, c.cfg.RetentionDeleteRateLimit, c.cfg.inRetentionWindow, r)
 			if err != nil {
 				return fmt.Errorf("failed to init sweeper: %w", err)
 			}
 
-			sc.tableMarker, err = retention.NewMarker(retentionWorkDir, c.expirationChecker, c.cfg.RetentionTableTimeout, chunkClient, r)
-			if err != nil {
-				return fmt.Errorf("failed to init table marker: %w", err)
+			if c.cfg.RetentionDryRun {
+				sc.tableMarker = retention.NewDryRunMarker(limits, c.cfg.RetentionTableTimeout, r)
+			} else {
+				sc.tableMarker, err = retention.NewMarker(retentionWorkDir, c.expirationChecker, c.cfg.RetentionTableTimeout, chunkClient, r)
+				if err != nil {
+					return fmt.Errorf("failed to init table marker: %w", err)
+				}
 			}
 		}
 
@@ -352,7 +445,12 @@ func (c *Compactor) initDeletes(objectClient client.ObjectClient, r prometheus.R
 		r,
 	)
 
-	c.expirationChecker = newExpirationChecker(retention.NewExpirationChecker(limits), c.deleteRequestsManager)
+	deletionExpiryChecker := retention.ExpirationChecker(c.deleteRequestsManager)
+	if c.cfg.RetentionDryRun {
+		// the delete requests would be marked as processed without being applied.
+		deletionExpiryChecker = retention.NeverExpiringExpirationChecker(limits)
+	}
+	c.expirationChecker = newExpirationChecker(retention.NewExpirationChecker(limits, r), deletionExpiryChecker)
 	return nil
 }
 
@@ -435,6 +533,21 @@ func (c *Compactor) loop(ctx context.Context) error {
 	var runningCtx context.Context
 	var runningCancel context.CancelFunc
 
+	if c.cfg.ShardingEnabled {
+		// every compactor of the ring runs the compactions of the tables it owns.
+		level.Info(util_log.Logger).Log("msg", "compactor sharding is enabled, starting compactor")
+		runningCtx, runningCancel = context.WithCancel(ctx)
+		defer runningCancel()
+		c.runCompactions(runningCtx)
+		c.running = true
+		c.metrics.compactorRunning.Set(1)
+
+		<-ctx.Done()
+		c.wg.Wait()
+		level.Info(util_log.Logger).Log("msg", "compactor exiting")
+		return nil
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -486,7 +599,13 @@ func (c *Compactor) runCompactions(ctx context.Context) {
 	// this allows the ring to settle if there are a lot of ring changes and gives
 	// time for existing compactors to shutdown before this starts to avoid
 	// multiple compactors running at the same time.
+	// When no other compactor is healthy in the ring, e.g. the previous compactor died, the compactor starts right away.
 	func() {
+		if !c.otherCompactorsHealthy() {
+			level.Info(util_log.Logger).Log("msg", "no other compactor is healthy in the ring, skipping compactor startup delay")
+			return
+		}
+
 		t := time.NewTimer(c.cfg.CompactionInterval)
 		defer t.Stop()
 		level.Info(util_log.Logger).Log("msg", fmt.Sprintf("waiting %v for ring to stay stable and previous compactions to finish before starting compactor", c.cfg.CompactionInterval))
@@ -502,7 +621,7 @@ func (c *Compactor) runCompactions(ctx context.Context) {
 	lastRetentionRunAt := time.Unix(0, 0)
 	runCompaction := func() {
 		applyRetention := false
-		if c.cfg.RetentionEnabled && time.Since(lastRetentionRunAt) >= c.cfg.ApplyRetentionInterval {
+		if c.cfg.RetentionEnabled && time.Since(lastRetentionRunAt) >= c.cfg.ApplyRetentionInterval && c.cfg.inRetentionWindow(time.Now()) {
 			level.Info(util_log.Logger).Log("msg", "applying retention with compaction")
 			applyRetention = true
 		}
@@ -578,6 +697,12 @@ func (c *Compactor) CompactTable(ctx context.Context, tableName string, applyRet
 		level.Error(util_log.Logger).Log("msg", "failed to initialize table for compaction", "table", tableName, "err", err)
 		return err
 	}
+	if c.cfg.ShardingEnabled {
+		// the ownership of the tables was computed when the compaction started, and can change while the table is compacted.
+		table.stillOwned = func() (bool, error) {
+			return c.ownsTable(tableName, nil, nil, nil)
+		}
+	}
 
 	interval := retention.ExtractIntervalFromTableName(tableName)
 	intervalMayHaveExpiredChunks := false
@@ -586,6 +711,9 @@ func (c *Compactor) CompactTable(ctx context.Context, tableName string, applyRet
 	}
 
 	err = table.compact(intervalMayHaveExpiredChunks)
+	if errors.Is(err, errTableNotOwned) {
+		return err
+	}
 	if err != nil {
 		level.Error(util_log.Logger).Log("msg", "failed to compact files", "table", tableName, "err", err)
 		return err
@@ -641,6 +769,15 @@ func (c *Compactor) RunCompaction(ctx context.Context, applyRetention bool) erro
 		tables = append(tables, tbls...)
 	}
 
+	if c.cfg.ShardingEnabled {
+		var err error
+		tables, err = c.ownedTables(tables)
+		if err != nil {
+			status = statusFailure
+			return err
+		}
+	}
+
 	// process most recent tables first
 	sortTablesByRange(tables)
 
@@ -671,6 +808,12 @@ func (c *Compactor) RunCompaction(ctx context.Context, applyRetention bool) erro
 
 					level.Info(util_log.Logger).Log("msg", "compacting table", "table-name", tableName)
 					err = c.CompactTable(ctx, tableName, applyRetention)
+					if errors.Is(err, errTableNotOwned) {
+						// the table is compacted by its new owner.
+						level.Info(util_log.Logger).Log("msg", "skipping table since its ownership changed", "table-name", tableName)
+						err = nil
+						continue
+					}
 					if err != nil {
 						return
 					}
@@ -712,6 +855,49 @@ func (c *Compactor) RunCompaction(ctx context.Context, applyRetention bool) erro
 	return firstErr
 }
 
+// otherCompactorsHealthy returns whether any other compactor is healthy in the ring, and so could still be running compactions.
+func (c *Compactor) otherCompactorsHealthy() bool {
+	rs, err := c.ring.GetAllHealthy(ring.Reporting)
+	if err != nil {
+		level.Error(util_log.Logger).Log("msg", "error asking ring for the healthy compactors", "err", err)
+		return true
+	}
+
+	for _, addr := range rs.GetAddresses() {
+		if addr != c.ringLifecycler.GetInstanceAddr() {
+			return true
+		}
+	}
+	return false
+}
+
+// ownedTables returns the tables owned by this compactor, by hashing their name over the ring.
+func (c *Compactor) ownedTables(tables []string) ([]string, error) {
+	bufDescs, bufHosts, bufZones := ring.MakeBuffersForGet()
+	owned := make([]string, 0, len(tables))
+	for _, tableName := range tables {
+		ok, err := c.ownsTable(tableName, bufDescs, bufHosts, bufZones)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			owned = append(owned, tableName)
+		}
+	}
+	return owned, nil
+}
+
+func (c *Compactor) ownsTable(tableName string, bufDescs []ring.InstanceDesc, bufHosts, bufZones []string) (bool, error) {
+	// the names of the tables only differ by their period number, xxhash spreads them over the ring.
+	rs, err := c.ring.Get(uint32(xxhash.Sum64String(tableName)), ring.Write, bufDescs, bufHosts, bufZones)
+	if err != nil {
+		return false, fmt.Errorf("failed to find the compactor owning table %s: %w", tableName, err)
+	}
+
+	addrs := rs.GetAddresses()
+	return len(addrs) == 1 && addrs[0] == c.ringLifecycler.GetInstanceAddr(), nil
+}
+
 type expirationChecker struct {
 	retentionExpiryChecker retention.ExpirationChecker
 	deletionExpiryChecker  retention.ExpirationChecker
@@ -767,7 +953,7 @@ func (c *Compactor) OnRingInstanceRegister(_ *ring.BasicLifecycler, ringDesc rin
 	}
 
 	takenTokens := ringDesc.GetTokens()
-	newTokens := ring.GenerateTokens(ringNumTokens-len(tokens), takenTokens)
+	newTokens := ring.GenerateTokens(c.cfg.numRingTokens()-len(tokens), takenTokens)
 
 	// Tokens sorting will be enforced by the parent caller.
 	tokens = append(tokens, newTokens...)
@@ -1,6 +1,7 @@
 package compactor
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
@@ -9,14 +10,22 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-kit/log"
 	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/dskit/kv"
+	"github.com/grafana/dskit/kv/consul"
+	"github.com/grafana/dskit/ring"
+	"github.com/grafana/dskit/services"
 	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/loki/pkg/storage/chunk/client"
 	"github.com/grafana/loki/pkg/storage/chunk/client/local"
 	"github.com/grafana/loki/pkg/storage/config"
+	"github.com/grafana/loki/pkg/storage/stores/indexshipper/storage"
 	loki_net "github.com/grafana/loki/pkg/util/net"
+	"github.com/grafana/loki/pkg/util/test"
 )
 
 const indexTablePrefix = "table_"
@@ -189,6 +198,238 @@ func TestCompactor_RunCompactionMultipleStores(t *testing.T) {
 	}
 }
 
+// setupTestRingCompactor returns the i-th compactor of a ring using the ring store.
+func setupTestRingCompactor(t *testing.T, objectClients map[string]client.ObjectClient, periodConfigs []config.PeriodConfig, tempDir string, ringStore kv.Client, i int, shardingEnabled bool) *Compactor {
+	cfg := Config{}
+	flagext.DefaultValues(&cfg)
+	cfg.WorkingDirectory = filepath.Join(tempDir, fmt.Sprintf("%s-%d", workingDirName, i))
+	cfg.ShardingEnabled = shardingEnabled
+	cfg.CompactorRing.KVStore.Mock = ringStore
+	cfg.CompactorRing.InstanceID = fmt.Sprintf("compactor-%d", i)
+	cfg.CompactorRing.InstanceAddr = "127.0.0.1"
+	cfg.CompactorRing.InstancePort = 9095 + i
+	require.NoError(t, cfg.Validate())
+
+	c, err := NewCompactor(cfg, objectClients, config.SchemaConfig{
+		Configs: periodConfigs,
+	}, nil, nil)
+	require.NoError(t, err)
+	c.RegisterIndexCompactor("dummy", testIndexCompactor{})
+
+	return c
+}
+
+func TestCompactor_OtherCompactorsHealthy(t *testing.T) {
+	tempDir := t.TempDir()
+
+	periodConfigs := []config.PeriodConfig{
+		{
+			From:       config.DayTime{Time: model.Time(0)},
+			IndexType:  "dummy",
+			ObjectType: "fs_01",
+			IndexTables: config.PeriodicTableConfig{
+				Prefix: indexTablePrefix,
+				Period: config.ObjectStorageIndexRequiredPeriod,
+			},
+		},
+	}
+
+	var (
+		objectClients = map[string]client.ObjectClient{}
+		err           error
+	)
+	objectClients["fs_01"], err = local.NewFSObjectClient(local.FSConfig{Directory: tempDir})
+	require.NoError(t, err)
+
+	ringStore, closer := consul.NewInMemoryClient(ring.GetCodec(), log.NewNopLogger(), nil)
+	t.Cleanup(func() { assert.NoError(t, closer.Close()) })
+
+	leader := setupTestRingCompactor(t, objectClients, periodConfigs, tempDir, ringStore, 0, false)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), leader))
+	defer func() {
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), leader))
+	}()
+	require.False(t, leader.otherCompactorsHealthy())
+
+	// the compactor waits for the other healthy compactors to stop their compactions before starting its compactions.
+	standby := setupTestRingCompactor(t, objectClients, periodConfigs, tempDir, ringStore, 1, false)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), standby))
+	test.Poll(t, time.Second, true, func() interface{} {
+		return leader.otherCompactorsHealthy()
+	})
+
+	// the compactors leave the ring when they stop.
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), standby))
+	test.Poll(t, time.Second, false, func() interface{} {
+		return leader.otherCompactorsHealthy()
+	})
+}
+
+func TestCompactor_RunCompactionWithSharding(t *testing.T) {
+	tempDir := t.TempDir()
+
+	tablesPath := filepath.Join(tempDir, "index")
+	commonDBsConfig := IndexesConfig{NumUnCompactedFiles: 5}
+	perUserDBsConfig := PerUserIndexesConfig{}
+
+	daySeconds := int64(24 * time.Hour / time.Second)
+	tableNumEnd := time.Now().Unix() / daySeconds
+	tableNumStart := tableNumEnd - 5
+
+	periodConfigs := []config.PeriodConfig{
+		{
+			From:       config.DayTime{Time: model.Time(0)},
+			IndexType:  "dummy",
+			ObjectType: "fs_01",
+			IndexTables: config.PeriodicTableConfig{
+				Prefix: indexTablePrefix,
+				Period: config.ObjectStorageIndexRequiredPeriod,
+			},
+		},
+	}
+
+	for i := tableNumStart; i <= tableNumEnd; i++ {
+		SetupTable(t, filepath.Join(tablesPath, fmt.Sprintf("%s%d", indexTablePrefix, i)), IndexesConfig{NumUnCompactedFiles: 5}, PerUserIndexesConfig{})
+	}
+
+	var (
+		objectClients = map[string]client.ObjectClient{}
+		err           error
+	)
+	objectClients["fs_01"], err = local.NewFSObjectClient(local.FSConfig{Directory: tempDir})
+	require.NoError(t, err)
+
+	ringStore, closer := consul.NewInMemoryClient(ring.GetCodec(), log.NewNopLogger(), nil)
+	t.Cleanup(func() { assert.NoError(t, closer.Close()) })
+	compactors := make([]*Compactor, 0, 2)
+	for i := 0; i < 2; i++ {
+		c := setupTestRingCompactor(t, objectClients, periodConfigs, tempDir, ringStore, i, true)
+		require.NoError(t, services.StartAndAwaitRunning(context.Background(), c))
+		defer func() {
+			require.NoError(t, services.StopAndAwaitTerminated(context.Background(), c))
+		}()
+		compactors = append(compactors, c)
+	}
+	for _, c := range compactors {
+		c := c
+		test.Poll(t, time.Second, 2, func() interface{} {
+			return c.ring.InstancesCount()
+		})
+	}
+
+	// every table is owned by exactly one of the compactors.
+	tables := make([]string, 0, 100)
+	for i := 0; i < 100; i++ {
+		tables = append(tables, fmt.Sprintf("%s%d", indexTablePrefix, i))
+	}
+	owners := map[string]int{}
+	for _, c := range compactors {
+		owned, err := c.ownedTables(tables)
+		require.NoError(t, err)
+		require.NotEmpty(t, owned)
+		for _, tableName := range owned {
+			owners[tableName]++
+		}
+	}
+	require.Len(t, owners, len(tables))
+	for tableName, count := range owners {
+		require.Equal(t, 1, count, tableName)
+	}
+
+	// together, the compactors compact all the tables.
+	for _, c := range compactors {
+		require.NoError(t, c.RunCompaction(context.Background(), false))
+	}
+	for i := tableNumStart; i <= tableNumEnd; i++ {
+		name := fmt.Sprintf("%s%d", indexTablePrefix, i)
+		// verify that we have only 1 file left in storage after compaction.
+		files, err := os.ReadDir(filepath.Join(tablesPath, name))
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+		require.True(t, strings.HasSuffix(files[0].Name(), ".gz"))
+
+		verifyCompactedIndexTable(t, commonDBsConfig, perUserDBsConfig, filepath.Join(tablesPath, name))
+	}
+}
+
+func TestConfig_RetentionWindow(t *testing.T) {
+	at := func(hhmm string) time.Time {
+		tm, err := time.Parse(retentionWindowLayout, hhmm)
+		require.NoError(t, err)
+		return tm
+	}
+
+	for _, tc := range []struct {
+		name       string
+		start, end string
+		in, out    []string
+	}{
+		{
+			name: "no window",
+			in:   []string{"00:00", "12:00", "23:59"},
+		},
+		{
+			name:  "window within the day",
+			start: "01:00",
+			end:   "05:30",
+			in:    []string{"01:00", "03:00", "05:29"},
+			out:   []string{"00:59", "05:30", "12:00"},
+		},
+		{
+			name:  "window spanning midnight",
+			start: "22:00",
+			end:   "06:00",
+			in:    []string{"22:00", "23:59", "00:00", "05:59"},
+			out:   []string{"06:00", "12:00", "21:59"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{}
+			flagext.DefaultValues(&cfg)
+			cfg.RetentionWindowStart = tc.start
+			cfg.RetentionWindowEnd = tc.end
+			require.NoError(t, cfg.Validate())
+
+			for _, hhmm := range tc.in {
+				require.True(t, cfg.inRetentionWindow(at(hhmm)), hhmm)
+			}
+			for _, hhmm := range tc.out {
+				require.False(t, cfg.inRetentionWindow(at(hhmm)), hhmm)
+			}
+		})
+	}
+
+	for _, window := range [][2]string{{"22:00", ""}, {"", "06:00"}, {"22h", "06:00"}, {"22:00", "6"}, {"06:00", "06:00"}} {
+		cfg := Config{}
+		flagext.DefaultValues(&cfg)
+		cfg.RetentionWindowStart, cfg.RetentionWindowEnd = window[0], window[1]
+		require.Error(t, cfg.Validate(), window)
+	}
+}
+
+func TestRateLimitedUploadClient(t *testing.T) {
+	tempDir := t.TempDir()
+	objectClient, err := local.NewFSObjectClient(local.FSConfig{Directory: tempDir})
+	require.NoError(t, err)
+
+	// the burst of the limiter is a second of uploads, the second half of the file waits for a second.
+	indexStorageClient := newRateLimitedUploadClient(storage.NewIndexStorageClient(objectClient, ""), 10<<10)
+	content := bytes.Repeat([]byte("a"), 20<<10)
+
+	start := time.Now()
+	require.NoError(t, indexStorageClient.PutFile(context.Background(), "table", "file", bytes.NewReader(content)))
+	require.GreaterOrEqual(t, time.Since(start), 900*time.Millisecond)
+
+	uploaded, err := os.ReadFile(filepath.Join(tempDir, "table", "file"))
+	require.NoError(t, err)
+	require.Equal(t, content, uploaded)
+
+	// the uploads are cancelled with their context.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.Error(t, indexStorageClient.PutUserFile(ctx, "table", "user", "file", bytes.NewReader(content)))
+}
+
 func Test_schemaPeriodForTable(t *testing.T) {
 	indexFromTime := func(t time.Time) string {
 		return fmt.Sprintf("%d", t.Unix()/int64(24*time.Hour/time.Second))
@@ -168,6 +168,12 @@ func (dm *DeleteRequestHandler) GetAllDeleteRequestsHandler(w http.ResponseWrite
 		return
 	}
 
+	status := r.URL.Query().Get("status")
+	if status != "" && status != string(StatusReceived) && status != statusProcessing && status != string(StatusProcessed) {
+		http.Error(w, fmt.Sprintf("invalid status %q, it should be one of %s, %s or %s", status, StatusReceived, statusProcessing, StatusProcessed), http.StatusBadRequest)
+		return
+	}
+
 	deleteGroups, err := dm.deleteRequestsStore.GetAllDeleteRequestsForUser(ctx, userID)
 	if err != nil {
 		level.Error(util_log.Logger).Log("msg", "error getting delete requests from the store", "err", err)
@@ -177,6 +183,9 @@ func (dm *DeleteRequestHandler) GetAllDeleteRequestsHandler(w http.ResponseWrite
 
 	deletesPerRequest := partitionByRequestID(deleteGroups)
 	deleteRequests := mergeDeletes(deletesPerRequest)
+	if status != "" {
+		deleteRequests = filterByStatus(deleteRequests, status)
+	}
 
 	sort.Slice(deleteRequests, func(i, j int) bool {
 		return deleteRequests[i].CreatedAt < deleteRequests[j].CreatedAt
@@ -226,6 +235,10 @@ func mergeData(deletes []DeleteRequest) (model.Time, model.Time, DeleteRequestSt
 	return startTime, endTime, deleteRequestStatus(numProcessed, len(deletes))
 }
 
+// statusProcessing filters the delete requests having only some of their subqueries processed,
+// reported with their percentage of completion.
+const statusProcessing = "processing"
+
 func deleteRequestStatus(processed, total int) DeleteRequestStatus {
 	if processed == 0 {
 		return StatusReceived
@@ -239,6 +252,22 @@ func deleteRequestStatus(processed, total int) DeleteRequestStatus {
 	return DeleteRequestStatus(fmt.Sprintf("%d%% Complete", int(percentCompleted*100)))
 }
 
+// filterByStatus returns the merged delete requests having the status, where the partially
+// processed requests have the processing status.
+func filterByStatus(reqs []DeleteRequest, status string) []DeleteRequest {
+	filtered := []DeleteRequest{}
+	for _, r := range reqs {
+		reqStatus := string(r.Status)
+		if r.Status != StatusReceived && r.Status != StatusProcessed {
+			reqStatus = statusProcessing
+		}
+		if reqStatus == status {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
 // CancelDeleteRequestHandler handles delete request cancellation
 func (dm *DeleteRequestHandler) CancelDeleteRequestHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -160,7 +160,7 @@ func TestAddDeleteRequestHandler(t *testing.T) {
 		}{
 			{"", `{foo="bar"}`, "0000000000", "0000000001", "", "no org id\n"},
 			{"org-id", "", "0000000000", "0000000001", "", "query not set\n"},
-			{"org-id", `not a query`, "0000000000", "0000000001", "", "invalid query expression\n"},
+			{"org-id", `not a query`, "0000000000", "0000000001", "", "invalid query expression: parse error at line 1, col 1: syntax error: unexpected IDENTIFIER\n"},
 			{"org-id", `{foo="bar"}`, "", "0000000001", "", "start time not set\n"},
 			{"org-id", `{foo="bar"}`, "0000000000000", "0000000001", "", "invalid start time: require unix seconds or RFC3339 format\n"},
 			{"org-id", `{foo="bar"}`, "0000000000", "0000000000001", "", "invalid end time: require unix seconds or RFC3339 format\n"},
@@ -406,6 +406,37 @@ func TestGetAllDeleteRequestsHandler(t *testing.T) {
 		}, result)
 	})
 
+	t.Run("it filters the requests by status", func(t *testing.T) {
+		store := &mockDeleteRequestsStore{}
+		store.getAllResult = []DeleteRequest{
+			{RequestID: "test-request-1", CreatedAt: now, Status: StatusProcessed},
+			{RequestID: "test-request-1", CreatedAt: now, Status: StatusReceived},
+			{RequestID: "test-request-2", CreatedAt: now.Add(time.Minute), Status: StatusProcessed},
+			{RequestID: "test-request-3", CreatedAt: now.Add(2 * time.Minute), Status: StatusReceived},
+		}
+		h := NewDeleteRequestHandler(store, 0, nil)
+
+		for status, expected := range map[string][]DeleteRequest{
+			"received":   {{RequestID: "test-request-3", CreatedAt: now.Add(2 * time.Minute), Status: StatusReceived}},
+			"processing": {{RequestID: "test-request-1", CreatedAt: now, Status: "50% Complete"}},
+			"processed":  {{RequestID: "test-request-2", CreatedAt: now.Add(time.Minute), Status: StatusProcessed}},
+		} {
+			req := buildRequest("org-id", ``, "", "")
+			params := req.URL.Query()
+			params.Set("status", status)
+			req.URL.RawQuery = params.Encode()
+
+			w := httptest.NewRecorder()
+			h.GetAllDeleteRequestsHandler(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var result []DeleteRequest
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+			require.Equal(t, expected, result, status)
+		}
+	})
+
 	t.Run("error getting from store", func(t *testing.T) {
 		store := &mockDeleteRequestsStore{}
 		store.getAllErr = errors.New("something bad")
@@ -435,6 +466,21 @@ func TestGetAllDeleteRequestsHandler(t *testing.T) {
 			require.Equal(t, w.Code, http.StatusBadRequest)
 			require.Equal(t, "no org id\n", w.Body.String())
 		})
+
+		t.Run("invalid status", func(t *testing.T) {
+			h := NewDeleteRequestHandler(&mockDeleteRequestsStore{}, 0, nil)
+
+			req := buildRequest("org-id", ``, "", "")
+			params := req.URL.Query()
+			params.Set("status", "canceled")
+			req.URL.RawQuery = params.Encode()
+
+			w := httptest.NewRecorder()
+			h.GetAllDeleteRequestsHandler(w, req)
+
+			require.Equal(t, w.Code, http.StatusBadRequest)
+			require.Equal(t, "invalid status \"canceled\", it should be one of received, processing or processed\n", w.Body.String())
+		})
 	})
 }
 
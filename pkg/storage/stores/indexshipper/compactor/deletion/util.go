@@ -2,6 +2,7 @@ package deletion
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/grafana/loki/pkg/storage/stores/indexshipper/compactor/deletionmode"
 
@@ -12,11 +13,12 @@ var (
 	errInvalidQuery = errors.New("invalid query expression")
 )
 
-// parseDeletionQuery checks if the given logQL is valid for deletions
+// parseDeletionQuery checks if the given logQL is valid for deletions.
+// The error tells why the query is invalid, e.g. the position of a syntax error.
 func parseDeletionQuery(query string) (syntax.LogSelectorExpr, error) {
 	logSelectorExpr, err := syntax.ParseLogSelector(query, false)
 	if err != nil {
-		return nil, errInvalidQuery
+		return nil, fmt.Errorf("%w: %s", errInvalidQuery, err)
 	}
 
 	return logSelectorExpr, nil
@@ -35,5 +35,6 @@ func TestParseLogQLExpressionForDeletion(t *testing.T) {
 		logSelectorExpr, err := parseDeletionQuery(`{env="dev", secret="true"} |= social sec number`)
 		require.Nil(t, logSelectorExpr)
 		require.ErrorIs(t, err, errInvalidQuery)
+		require.Contains(t, err.Error(), "parse error at line 1, col 31")
 	})
 }
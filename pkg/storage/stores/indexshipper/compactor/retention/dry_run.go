@@ -0,0 +1,72 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+)
+
+// DryRunMarker is a TableMarker evaluating the retention of the tables without modifying them,
+// nor marking any chunk for deletion. It reports the chunks the retention would delete per tenant.
+// The delete requests aren't evaluated, and the chunks aren't counted as expired by the retention.
+type DryRunMarker struct {
+	tenantsRetention *TenantsRetention
+	markTimeout      time.Duration
+	metrics          *dryRunMetrics
+}
+
+func NewDryRunMarker(limits Limits, markTimeout time.Duration, r prometheus.Registerer) *DryRunMarker {
+	return &DryRunMarker{
+		tenantsRetention: NewTenantsRetention(limits),
+		markTimeout:      markTimeout,
+		metrics:          newDryRunMetrics(r),
+	}
+}
+
+type dryRunExpired struct {
+	chunks int
+	bytes  uint64
+}
+
+// MarkForDelete reports the chunks of the table the retention would delete. The table is never empty nor modified.
+func (t *DryRunMarker) MarkForDelete(_ context.Context, tableName, _ string, indexProcessor IndexProcessor, logger log.Logger) (bool, bool, error) {
+	expiredChunks := map[string]*dryRunExpired{}
+	now := model.Now()
+
+	iterCtx, cancel := ctxForTimeout(t.markTimeout)
+	defer cancel()
+
+	err := indexProcessor.ForEachChunk(iterCtx, func(c ChunkEntry) (bool, error) {
+		expired, ok := expiredChunks[string(c.UserID)]
+		if !ok {
+			expired = &dryRunExpired{}
+			expiredChunks[string(c.UserID)] = expired
+		}
+
+		if ok, _ := t.tenantsRetention.expired(c, now); ok {
+			expired.chunks++
+			expired.bytes += uint64(c.KB) * 1024
+		}
+		return false, nil
+	})
+	if err != nil {
+		if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(iterCtx.Err(), context.DeadlineExceeded) {
+			return false, false, err
+		}
+		level.Warn(logger).Log("msg", "timed out while running retention dry run")
+	}
+
+	for userID, expired := range expiredChunks {
+		t.metrics.expiredChunks.WithLabelValues(tableName, userID).Set(float64(expired.chunks))
+		t.metrics.expiredBytes.WithLabelValues(tableName, userID).Set(float64(expired.bytes))
+		if expired.chunks > 0 {
+			level.Info(logger).Log("msg", "retention dry run found expired chunks", "user", userID, "expired_chunks", expired.chunks, "expired_bytes", expired.bytes)
+		}
+	}
+	return false, false, nil
+}
@@ -0,0 +1,80 @@
+package retention
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/storage/chunk"
+	util_log "github.com/grafana/loki/pkg/util/log"
+)
+
+func TestDryRunMarker(t *testing.T) {
+	schema := allSchemas[0]
+	store := newTestStore(t)
+	c1 := createChunk(t, "1", labels.Labels{labels.Label{Name: "foo", Value: "bar"}}, schema.from, schema.from.Add(1*time.Hour))
+	c2 := createChunk(t, "1", labels.Labels{labels.Label{Name: "foo", Value: "buzz"}}, schema.from, schema.from.Add(1*time.Hour))
+	c3 := createChunk(t, "2", labels.Labels{labels.Label{Name: "foo", Value: "buzz"}}, schema.from, schema.from.Add(1*time.Hour))
+
+	require.NoError(t, store.Put(context.TODO(), []chunk.Chunk{
+		c1, c2, c3,
+	}))
+
+	store.Stop()
+
+	tables := store.indexTables()
+	require.Len(t, tables, 1)
+
+	// Only the chunks of the tenant 1 are out of retention.
+	marker := NewDryRunMarker(&fakeLimits{perTenant: map[string]retentionLimit{"1": {retentionPeriod: time.Second}, "2": {retentionPeriod: 0}}}, 0, prometheus.NewRegistry())
+	empty, modified, err := marker.MarkForDelete(context.Background(), tables[0].name, "", tables[0], util_log.Logger)
+	require.NoError(t, err)
+	require.False(t, empty)
+	require.False(t, modified)
+
+	require.Equal(t, float64(2), testutil.ToFloat64(marker.metrics.expiredChunks.WithLabelValues(tables[0].name, "1")))
+	require.Equal(t, float64(0), testutil.ToFloat64(marker.metrics.expiredChunks.WithLabelValues(tables[0].name, "2")))
+
+	// No chunk is deleted.
+	require.True(t, store.HasChunk(c1))
+	require.True(t, store.HasChunk(c2))
+	require.True(t, store.HasChunk(c3))
+}
+
+type fakeIndexProcessor struct {
+	IndexProcessor
+	chunks []ChunkEntry
+}
+
+func (f fakeIndexProcessor) ForEachChunk(_ context.Context, callback ChunkEntryCallback) error {
+	for _, c := range f.chunks {
+		if _, err := callback(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestDryRunMarker_ExpiredBytes(t *testing.T) {
+	now := model.Now()
+	c1 := newChunkEntry("1", `{foo="bar"}`, now.Add(-3*time.Hour), now.Add(-2*time.Hour))
+	c1.KB = 100
+	c2 := newChunkEntry("1", `{foo="buzz"}`, now.Add(-3*time.Hour), now.Add(-2*time.Hour))
+	c2.KB = 20
+	c3 := newChunkEntry("1", `{foo="bar"}`, now.Add(-time.Minute), now)
+	c3.KB = 50
+
+	limits := &fakeLimits{perTenant: map[string]retentionLimit{"1": {retentionPeriod: time.Hour}}}
+	marker := NewDryRunMarker(limits, 0, prometheus.NewRegistry())
+	_, _, err := marker.MarkForDelete(context.Background(), "table", "", fakeIndexProcessor{chunks: []ChunkEntry{c1, c2, c3}}, util_log.Logger)
+	require.NoError(t, err)
+
+	require.Equal(t, float64(2), testutil.ToFloat64(marker.metrics.expiredChunks.WithLabelValues("table", "1")))
+	require.Equal(t, float64(120*1024), testutil.ToFloat64(marker.metrics.expiredBytes.WithLabelValues("table", "1")))
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
 
@@ -34,30 +35,36 @@ type ExpirationChecker interface {
 type expirationChecker struct {
 	tenantsRetention         *TenantsRetention
 	latestRetentionStartTime latestRetentionStartTime
+	expiredChunksTotal       *prometheus.CounterVec
 }
 
-type Limits interface {
+// StreamRetentionLimits are the limits defining the retention period of the streams of a tenant.
+type StreamRetentionLimits interface {
 	RetentionPeriod(userID string) time.Duration
 	StreamRetention(userID string) []validation.StreamRetention
+}
+
+type Limits interface {
+	StreamRetentionLimits
 	AllByUserID() map[string]*validation.Limits
 	DefaultLimits() *validation.Limits
 }
 
-func NewExpirationChecker(limits Limits) ExpirationChecker {
+func NewExpirationChecker(limits Limits, r prometheus.Registerer) ExpirationChecker {
 	return &expirationChecker{
-		tenantsRetention: NewTenantsRetention(limits),
+		tenantsRetention:   NewTenantsRetention(limits),
+		expiredChunksTotal: newExpirationMetrics(r),
 	}
 }
 
 // Expired tells if a ref chunk is expired based on retention rules.
 func (e *expirationChecker) Expired(ref ChunkEntry, now model.Time) (bool, filter.Func) {
-	userID := unsafeGetString(ref.UserID)
-	period := e.tenantsRetention.RetentionPeriodFor(userID, ref.Labels)
-	// The 0 value should disable retention
-	if period <= 0 {
+	expired, selector := e.tenantsRetention.expired(ref, now)
+	if !expired {
 		return false, nil
 	}
-	return now.Sub(ref.Through) > period, nil
+	e.expiredChunksTotal.WithLabelValues(unsafeGetString(ref.UserID), selector).Inc()
+	return true, nil
 }
 
 // DropFromIndex tells if it is okay to drop the chunk entry from index table.
@@ -125,6 +132,16 @@ type TenantsRetention struct {
 	limits Limits
 }
 
+// expired tells if a ref chunk is expired based on retention rules, with the selector of the matching stream retention.
+func (tr *TenantsRetention) expired(ref ChunkEntry, now model.Time) (bool, string) {
+	period, selector := retentionFor(tr.limits, unsafeGetString(ref.UserID), ref.Labels)
+	// The 0 value should disable retention
+	if period <= 0 {
+		return false, ""
+	}
+	return now.Sub(ref.Through) > period, selector
+}
+
 func NewTenantsRetention(l Limits) *TenantsRetention {
 	return &TenantsRetention{
 		limits: l,
@@ -132,8 +149,21 @@ func NewTenantsRetention(l Limits) *TenantsRetention {
 }
 
 func (tr *TenantsRetention) RetentionPeriodFor(userID string, lbs labels.Labels) time.Duration {
-	streamRetentions := tr.limits.StreamRetention(userID)
-	globalRetention := tr.limits.RetentionPeriod(userID)
+	return RetentionPeriodFor(tr.limits, userID, lbs)
+}
+
+// RetentionPeriodFor returns the retention period of the stream of the tenant, from the
+// highest priority per-stream retention matching its labels or the retention of the tenant.
+func RetentionPeriodFor(limits StreamRetentionLimits, userID string, lbs labels.Labels) time.Duration {
+	period, _ := retentionFor(limits, userID, lbs)
+	return period
+}
+
+// retentionFor returns the retention period of the stream of the tenant, with the selector of the per-stream
+// retention it is from, which is empty when it is the retention of the tenant.
+func retentionFor(limits StreamRetentionLimits, userID string, lbs labels.Labels) (time.Duration, string) {
+	streamRetentions := limits.StreamRetention(userID)
+	globalRetention := limits.RetentionPeriod(userID)
 	var (
 		matchedRule validation.StreamRetention
 		found       bool
@@ -160,9 +190,9 @@ Outer:
 		matchedRule = streamRetention
 	}
 	if found {
-		return time.Duration(matchedRule.Period)
+		return time.Duration(matchedRule.Period), matchedRule.Selector
 	}
-	return globalRetention
+	return globalRetention, ""
 }
 
 type latestRetentionStartTime struct {
@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/grafana/dskit/flagext"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/require"
@@ -88,8 +90,8 @@ func Test_expirationChecker_Expired(t *testing.T) {
 	t2 := defaultLimitsTestConfig()
 	t2.RetentionPeriod = model.Duration(24 * time.Hour)
 	t2.StreamRetention = []validation.StreamRetention{
-		{Period: model.Duration(1 * time.Hour), Matchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "foo", "bar")}},
-		{Period: model.Duration(2 * time.Hour), Matchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchRegexp, "foo", "ba.")}},
+		{Period: model.Duration(1 * time.Hour), Selector: `{foo="bar"}`, Matchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "foo", "bar")}},
+		{Period: model.Duration(2 * time.Hour), Selector: `{foo=~"ba."}`, Matchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchRegexp, "foo", "ba.")}},
 	}
 
 	f := fakeOverrides{
@@ -101,7 +103,7 @@ func Test_expirationChecker_Expired(t *testing.T) {
 	o, err := overridesTestConfig(d, f)
 	require.NoError(t, err)
 
-	e := NewExpirationChecker(o)
+	e := NewExpirationChecker(o, prometheus.NewRegistry())
 	tests := []struct {
 		name string
 		ref  ChunkEntry
@@ -121,6 +123,12 @@ func Test_expirationChecker_Expired(t *testing.T) {
 			require.Nil(t, nonDeletedIntervalFilters)
 		})
 	}
+
+	// the expired chunks are counted by the selector of the stream retention they expired from.
+	expiredChunksTotal := e.(*expirationChecker).expiredChunksTotal
+	require.Equal(t, float64(1), testutil.ToFloat64(expiredChunksTotal.WithLabelValues("1", "")))
+	require.Equal(t, float64(1), testutil.ToFloat64(expiredChunksTotal.WithLabelValues("2", `{foo="bar"}`)))
+	require.Equal(t, float64(0), testutil.ToFloat64(expiredChunksTotal.WithLabelValues("2", `{foo=~"ba."}`)))
 }
 
 func Test_expirationChecker_Expired_zeroValue(t *testing.T) {
@@ -139,7 +147,7 @@ func Test_expirationChecker_Expired_zeroValue(t *testing.T) {
 	}
 	o, err := overridesTestConfig(d, f)
 	require.NoError(t, err)
-	e := NewExpirationChecker(o)
+	e := NewExpirationChecker(o, nil)
 	tests := []struct {
 		name string
 		ref  ChunkEntry
@@ -185,7 +193,7 @@ func Test_expirationChecker_Expired_zeroValueOverride(t *testing.T) {
 	o, err := overridesTestConfig(d, f)
 	require.NoError(t, err)
 
-	e := NewExpirationChecker(o)
+	e := NewExpirationChecker(o, nil)
 	tests := []struct {
 		name string
 		ref  ChunkEntry
@@ -219,7 +227,7 @@ func Test_expirationChecker_DropFromIndex_zeroValue(t *testing.T) {
 	}
 	o, err := overridesTestConfig(d, f)
 	require.NoError(t, err)
-	e := NewExpirationChecker(o)
+	e := NewExpirationChecker(o, nil)
 
 	chunkFrom := model.Now().Add(-3 * time.Hour)
 	chunkThrough := model.Now().Add(-2 * time.Hour)
@@ -148,6 +148,8 @@ type markerProcessor struct {
 	folder         string // folder where to find markers file.
 	maxParallelism int
 	minAgeFile     time.Duration
+	// inDeleteWindow returns whether the marked chunks can be deleted at the time, they can always be deleted when it is nil.
+	inDeleteWindow func(time.Time) bool
 
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -156,7 +158,7 @@ type markerProcessor struct {
 	sweeperMetrics *sweeperMetrics
 }
 
-func newMarkerStorageReader(workingDir string, maxParallelism int, minAgeFile time.Duration, sweeperMetrics *sweeperMetrics) (*markerProcessor, error) {
+func newMarkerStorageReader(workingDir string, maxParallelism int, minAgeFile time.Duration, inDeleteWindow func(time.Time) bool, sweeperMetrics *sweeperMetrics) (*markerProcessor, error) {
 	folder := filepath.Join(workingDir, MarkersFolder)
 	err := chunk_util.EnsureDirectory(folder)
 	if err != nil {
@@ -169,6 +171,7 @@ func newMarkerStorageReader(workingDir string, maxParallelism int, minAgeFile ti
 		cancel:         cancel,
 		maxParallelism: maxParallelism,
 		minAgeFile:     minAgeFile,
+		inDeleteWindow: inDeleteWindow,
 		sweeperMetrics: sweeperMetrics,
 	}, nil
 }
@@ -193,6 +196,10 @@ func (r *markerProcessor) Start(deleteFunc func(ctx context.Context, chunkId []b
 				// cancelled
 				return
 			}
+			if !r.canDelete() {
+				level.Debug(util_log.Logger).Log("msg", "not processing marks outside of the delete window")
+				continue
+			}
 			paths, times, err := r.availablePath()
 			if err != nil {
 				level.Error(util_log.Logger).Log("msg", "failed to list marks path", "path", r.folder, "err", err)
@@ -208,6 +215,9 @@ func (r *markerProcessor) Start(deleteFunc func(ctx context.Context, chunkId []b
 				if r.ctx.Err() != nil {
 					return
 				}
+				if !r.canDelete() {
+					break
+				}
 				r.sweeperMetrics.markerFileCurrentTime.Set(float64(times[i].UnixNano()) / 1e9)
 				if err := r.processPath(path, deleteFunc); err != nil {
 					level.Warn(util_log.Logger).Log("msg", "failed to process marks", "path", path, "err", err)
@@ -310,6 +320,10 @@ func (r *markerProcessor) processPath(path string, deleteFunc func(ctx context.C
 
 		c := b.Cursor()
 		for k, v := c.First(); k != nil; k, v = c.Next() {
+			if !r.canDelete() {
+				// the remaining marks are processed in the next delete window.
+				return nil
+			}
 			key, err := getKeyPairBuffer(k, v)
 			if err != nil {
 				return err
@@ -325,6 +339,10 @@ func (r *markerProcessor) processPath(path string, deleteFunc func(ctx context.C
 	})
 }
 
+func (r *markerProcessor) canDelete() bool {
+	return r.inDeleteWindow == nil || r.inDeleteWindow(time.Now())
+}
+
 func processKey(ctx context.Context, key *keyPair, db *bbolt.DB, deleteFunc func(ctx context.Context, chunkId []byte) error) error {
 	chunkID := key.value.Bytes()
 	if err := deleteFunc(ctx, chunkID); err != nil {
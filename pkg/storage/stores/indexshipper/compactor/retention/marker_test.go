@@ -19,7 +19,7 @@ func initAndFeedMarkerProcessor(t *testing.T, deleteWorkerCount int) *markerProc
 	t.Helper()
 	minListMarkDelay = time.Second
 	dir := t.TempDir()
-	p, err := newMarkerStorageReader(dir, deleteWorkerCount, time.Second, sweepMetrics)
+	p, err := newMarkerStorageReader(dir, deleteWorkerCount, time.Second, nil, sweepMetrics)
 	require.NoError(t, err)
 	go func() {
 		w, err := NewMarkerStorageWriter(dir)
@@ -39,7 +39,7 @@ func initAndFeedMarkerProcessor(t *testing.T, deleteWorkerCount int) *markerProc
 
 func Test_marlkerProcessor_Deadlock(t *testing.T) {
 	dir := t.TempDir()
-	p, err := newMarkerStorageReader(dir, 150, 0, sweepMetrics)
+	p, err := newMarkerStorageReader(dir, 150, 0, nil, sweepMetrics)
 	require.NoError(t, err)
 	w, err := NewMarkerStorageWriter(dir)
 	require.NoError(t, err)
@@ -58,6 +58,37 @@ func Test_marlkerProcessor_Deadlock(t *testing.T) {
 	require.Len(t, paths, 0)
 }
 
+func Test_markerProcessor_OutsideDeleteWindow(t *testing.T) {
+	dir := t.TempDir()
+	inDeleteWindow := false
+	p, err := newMarkerStorageReader(dir, 5, 0, func(time.Time) bool { return inDeleteWindow }, sweepMetrics)
+	require.NoError(t, err)
+	w, err := NewMarkerStorageWriter(dir)
+	require.NoError(t, err)
+	require.NoError(t, w.Put([]byte("1")))
+	require.NoError(t, w.Close())
+
+	var deleted []string
+	deleteFunc := func(ctx context.Context, chunkId []byte) error {
+		deleted = append(deleted, string(chunkId))
+		return nil
+	}
+	paths, _, err := p.availablePath()
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+	require.NoError(t, p.processPath(paths[0], deleteFunc))
+	require.NoError(t, p.deleteEmptyMarks(paths[0]))
+	require.Empty(t, deleted)
+
+	inDeleteWindow = true
+	require.NoError(t, p.processPath(paths[0], deleteFunc))
+	require.NoError(t, p.deleteEmptyMarks(paths[0]))
+	require.Equal(t, []string{"1"}, deleted)
+	paths, _, err = p.availablePath()
+	require.NoError(t, err)
+	require.Len(t, paths, 0)
+}
+
 func Test_markerProcessor_StartRetryKey(t *testing.T) {
 	p := initAndFeedMarkerProcessor(t, 5)
 	defer p.Stop()
@@ -148,7 +179,7 @@ func Test_markerProcessor_availablePath(t *testing.T) {
 	} {
 		t.Run("", func(t *testing.T) {
 			dir := t.TempDir()
-			p, err := newMarkerStorageReader(dir, 5, 2*time.Hour, sweepMetrics)
+			p, err := newMarkerStorageReader(dir, 5, 2*time.Hour, nil, sweepMetrics)
 
 			expectedPath, expectedTimes := tt.expected(p.folder)
 
@@ -163,7 +194,7 @@ func Test_markerProcessor_availablePath(t *testing.T) {
 
 func Test_MarkFileRotation(t *testing.T) {
 	dir := t.TempDir()
-	p, err := newMarkerStorageReader(dir, 150, 0, sweepMetrics)
+	p, err := newMarkerStorageReader(dir, 150, 0, nil, sweepMetrics)
 	require.NoError(t, err)
 	w, err := NewMarkerStorageWriter(dir)
 	require.NoError(t, err)
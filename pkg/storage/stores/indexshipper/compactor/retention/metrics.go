@@ -74,3 +74,31 @@ func newMarkerMetrics(r prometheus.Registerer) *markerMetrics {
 		}, []string{"table", "status"}),
 	}
 }
+
+type dryRunMetrics struct {
+	expiredChunks *prometheus.GaugeVec
+	expiredBytes  *prometheus.GaugeVec
+}
+
+func newDryRunMetrics(r prometheus.Registerer) *dryRunMetrics {
+	return &dryRunMetrics{
+		expiredChunks: promauto.With(r).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "loki_boltdb_shipper",
+			Name:      "retention_dry_run_expired_chunks",
+			Help:      "Count of chunks the retention would delete per table for each user, found by the last retention dry run of the table.",
+		}, []string{"table", "user_id"}),
+		expiredBytes: promauto.With(r).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "loki_boltdb_shipper",
+			Name:      "retention_dry_run_expired_bytes",
+			Help:      "Size of the chunks the retention would delete per table for each user, found by the last retention dry run of the table. Only the TSDB index records the size of the chunks.",
+		}, []string{"table", "user_id"}),
+	}
+}
+
+func newExpirationMetrics(r prometheus.Registerer) *prometheus.CounterVec {
+	return promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+		Namespace: "loki_boltdb_shipper",
+		Name:      "retention_expired_chunks_total",
+		Help:      "Total count of chunks expired by the retention for each user per stream retention selector. Empty string for selector is for the retention period of the user",
+	}, []string{"user_id", "selector"})
+}
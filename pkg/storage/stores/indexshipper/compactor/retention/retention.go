@@ -14,6 +14,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
+	"golang.org/x/time/rate"
 
 	"github.com/grafana/loki/pkg/chunkenc"
 	"github.com/grafana/loki/pkg/storage/chunk"
@@ -36,6 +37,8 @@ type ChunkRef struct {
 	ChunkID  []byte
 	From     model.Time
 	Through  model.Time
+	// KB is the size of the chunk in KB, when the index records it.
+	KB uint32
 }
 
 func (c ChunkRef) String() string {
@@ -272,24 +275,37 @@ type Sweeper struct {
 	markerProcessor MarkerProcessor
 	chunkClient     ChunkClient
 	sweeperMetrics  *sweeperMetrics
+	// deleteLimiter limits the rate of the chunk deletes of all the workers.
+	deleteLimiter *rate.Limiter
 }
 
-func NewSweeper(workingDir string, deleteClient ChunkClient, deleteWorkerCount int, minAgeDelete time.Duration, r prometheus.Registerer) (*Sweeper, error) {
+// NewSweeper returns a Sweeper deleting the marked chunks at most deleteRateLimit times per second, or without limit when it is 0.
+// The chunks are only deleted at the times inDeleteWindow returns true for, or at any time when it is nil.
+func NewSweeper(workingDir string, deleteClient ChunkClient, deleteWorkerCount int, minAgeDelete time.Duration, deleteRateLimit float64, inDeleteWindow func(time.Time) bool, r prometheus.Registerer) (*Sweeper, error) {
 	m := newSweeperMetrics(r)
 
-	p, err := newMarkerStorageReader(workingDir, deleteWorkerCount, minAgeDelete, m)
+	p, err := newMarkerStorageReader(workingDir, deleteWorkerCount, minAgeDelete, inDeleteWindow, m)
 	if err != nil {
 		return nil, err
 	}
+	deleteLimiter := rate.NewLimiter(rate.Inf, 0)
+	if deleteRateLimit > 0 {
+		deleteLimiter = rate.NewLimiter(rate.Limit(deleteRateLimit), 1)
+	}
 	return &Sweeper{
 		markerProcessor: p,
 		chunkClient:     deleteClient,
 		sweeperMetrics:  m,
+		deleteLimiter:   deleteLimiter,
 	}, nil
 }
 
 func (s *Sweeper) Start() {
 	s.markerProcessor.Start(func(ctx context.Context, chunkId []byte) error {
+		if err := s.deleteLimiter.Wait(ctx); err != nil {
+			return err
+		}
+
 		status := statusSuccess
 		start := time.Now()
 		defer func() {
@@ -23,7 +23,10 @@ const (
 	gzipExtension = ".gz"
 )
 
-var errRetentionFileCountNotOne = fmt.Errorf("can't apply retention when index file count is not one")
+var (
+	errRetentionFileCountNotOne = fmt.Errorf("can't apply retention when index file count is not one")
+	errTableNotOwned            = fmt.Errorf("the table is owned by another compactor")
+)
 
 type tableExpirationChecker interface {
 	IntervalMayHaveExpiredChunks(interval model.Interval, userID string) bool
@@ -83,6 +86,10 @@ type table struct {
 	usersWithPerUserIndex []string
 	logger                log.Logger
 
+	// stillOwned is set when the tables are sharded between the compactors,
+	// so the table isn't modified once another compactor owns it.
+	stillOwned func() (bool, error)
+
 	ctx context.Context
 }
 
@@ -173,6 +180,10 @@ func (t *table) compact(applyRetention bool) error {
 		return err
 	}
 
+	if err := t.checkOwnership(); err != nil {
+		return err
+	}
+
 	if applyRetention {
 		err := t.applyRetention()
 		if err != nil {
@@ -180,9 +191,29 @@ func (t *table) compact(applyRetention bool) error {
 		}
 	}
 
+	if err := t.checkOwnership(); err != nil {
+		return err
+	}
+
 	return t.done()
 }
 
+// checkOwnership returns errTableNotOwned when another compactor owns the table now.
+func (t *table) checkOwnership() error {
+	if t.stillOwned == nil {
+		return nil
+	}
+
+	owned, err := t.stillOwned()
+	if err != nil {
+		return err
+	}
+	if !owned {
+		return errTableNotOwned
+	}
+	return nil
+}
+
 func (t *table) done() error {
 	userIDs := make([]string, 0, len(t.indexSets))
 	for userID := range t.indexSets {
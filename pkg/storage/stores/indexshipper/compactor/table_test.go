@@ -477,3 +477,34 @@ func TestTable_CompactionFailure(t *testing.T) {
 	// ensure that we have cleanup the local working directory after successful compaction.
 	require.NoFileExists(t, tableWorkingDirectory)
 }
+
+func TestTable_CompactionOwnershipChanged(t *testing.T) {
+	tempDir := t.TempDir()
+
+	tableName := "test"
+	objectStoragePath := filepath.Join(tempDir, objectsStorageDirName)
+	tablePathInStorage := filepath.Join(objectStoragePath, tableName)
+	tableWorkingDirectory := filepath.Join(tempDir, workingDirName, tableName)
+
+	numDBs := 10
+	SetupTable(t, tablePathInStorage, IndexesConfig{NumUnCompactedFiles: numDBs}, PerUserIndexesConfig{})
+
+	objectClient, err := local.NewFSObjectClient(local.FSConfig{Directory: objectStoragePath})
+	require.NoError(t, err)
+
+	table, err := newTable(context.Background(), tableWorkingDirectory, storage.NewIndexStorageClient(objectClient, ""),
+		newTestIndexCompactor(), config.PeriodConfig{}, nil, nil, 10)
+	require.NoError(t, err)
+	// another compactor owns the table once it is compacted.
+	table.stillOwned = func() (bool, error) {
+		return false, nil
+	}
+
+	require.ErrorIs(t, table.compact(false), errTableNotOwned)
+
+	// ensure that the files in storage weren't touched.
+	files, err := os.ReadDir(tablePathInStorage)
+	require.NoError(t, err)
+	require.Len(t, files, numDBs)
+	require.NoFileExists(t, tableWorkingDirectory)
+}
@@ -0,0 +1,52 @@
+package compactor
+
+import (
+	"context"
+	"io"
+
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/loki/pkg/storage/stores/indexshipper/storage"
+)
+
+// rateLimitedUploadClient limits the rate of the bytes uploaded by the compactions, the other operations aren't limited.
+type rateLimitedUploadClient struct {
+	storage.Client
+	limiter *rate.Limiter
+}
+
+func newRateLimitedUploadClient(client storage.Client, bytesPerSecond int) storage.Client {
+	return &rateLimitedUploadClient{
+		Client:  client,
+		limiter: rate.NewLimiter(rate.Limit(bytesPerSecond), bytesPerSecond),
+	}
+}
+
+func (c *rateLimitedUploadClient) PutFile(ctx context.Context, tableName, fileName string, file io.ReadSeeker) error {
+	return c.Client.PutFile(ctx, tableName, fileName, &rateLimitedReader{ctx: ctx, ReadSeeker: file, limiter: c.limiter})
+}
+
+func (c *rateLimitedUploadClient) PutUserFile(ctx context.Context, tableName, userID, fileName string, file io.ReadSeeker) error {
+	return c.Client.PutUserFile(ctx, tableName, userID, fileName, &rateLimitedReader{ctx: ctx, ReadSeeker: file, limiter: c.limiter})
+}
+
+type rateLimitedReader struct {
+	io.ReadSeeker
+	ctx     context.Context
+	limiter *rate.Limiter
+}
+
+func (r *rateLimitedReader) Read(p []byte) (int, error) {
+	// the reads can't be larger than the burst of the limiter.
+	if len(p) > r.limiter.Burst() {
+		p = p[:r.limiter.Burst()]
+	}
+
+	n, err := r.ReadSeeker.Read(p)
+	if n > 0 {
+		if err := r.limiter.WaitN(r.ctx, n); err != nil {
+			return n, err
+		}
+	}
+	return n, err
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"sync"
 	"time"
 	"unsafe"
 
@@ -23,6 +24,7 @@ import (
 	"github.com/grafana/loki/pkg/storage/stores/tsdb/index"
 )
 
+// readDBsConcurrency is the number of index files read, and of user indexes built, concurrently.
 const readDBsConcurrency = 50
 
 type indexProcessor struct{}
@@ -139,7 +141,9 @@ func (t *tableCompactor) CompactTable() error {
 	// go through all the users having index in the multi-tenant indexes and setup builder for each user
 	// builder would combine users index from multi-tenant indexes and the existing compacted index(es)
 	t.compactedIndexes = make(map[string]compactor.CompactedIndex, len(userIDs))
-	for _, userID := range userIDs {
+	var compactedIndexesMtx sync.Mutex
+	err = concurrency.ForEachJob(t.ctx, len(userIDs), readDBsConcurrency, func(ctx context.Context, job int) error {
+		userID := userIDs[job]
 		existingUserIndexSet, ok := t.existingUserIndexSet[userID]
 		if !ok {
 			var err error
@@ -149,36 +153,49 @@ func (t *tableCompactor) CompactTable() error {
 			}
 		}
 
-		builder, err := setupBuilder(t.ctx, userID, existingUserIndexSet, multiTenantIndices)
+		builder, err := setupBuilder(ctx, userID, existingUserIndexSet, multiTenantIndices)
 		if err != nil {
 			return err
 		}
 
 		compactedIndex := newCompactedIndex(t.ctx, existingUserIndexSet.GetTableName(), userID, existingUserIndexSet.GetWorkingDir(), t.periodConfig, builder)
+		compactedIndexesMtx.Lock()
 		t.compactedIndexes[userID] = compactedIndex
+		compactedIndexesMtx.Unlock()
 
-		if err := existingUserIndexSet.SetCompactedIndex(compactedIndex, true); err != nil {
-			return err
-		}
+		return existingUserIndexSet.SetCompactedIndex(compactedIndex, true)
+	})
+	if err != nil {
+		return err
 	}
 
 	// go through existingUserIndexSet and find the ones that were not initialized now due to no updates and
 	// have multiple index files in the storage to merge them into a single index file.
+	var userIndexSetsToMerge []string
 	for userID, srcIdxSet := range t.existingUserIndexSet {
 		if _, ok := t.compactedIndexes[userID]; ok || len(srcIdxSet.ListSourceFiles()) <= 1 {
 			continue
 		}
+		userIndexSetsToMerge = append(userIndexSetsToMerge, userID)
+	}
 
-		builder, err := setupBuilder(t.ctx, userID, srcIdxSet, []Index{})
+	err = concurrency.ForEachJob(t.ctx, len(userIndexSetsToMerge), readDBsConcurrency, func(ctx context.Context, job int) error {
+		userID := userIndexSetsToMerge[job]
+		srcIdxSet := t.existingUserIndexSet[userID]
+		builder, err := setupBuilder(ctx, userID, srcIdxSet, []Index{})
 		if err != nil {
 			return err
 		}
 
 		compactedIndex := newCompactedIndex(t.ctx, srcIdxSet.GetTableName(), userID, srcIdxSet.GetWorkingDir(), t.periodConfig, builder)
+		compactedIndexesMtx.Lock()
 		t.compactedIndexes[userID] = compactedIndex
-		if err := srcIdxSet.SetCompactedIndex(compactedIndex, true); err != nil {
-			return err
-		}
+		compactedIndexesMtx.Unlock()
+
+		return srcIdxSet.SetCompactedIndex(compactedIndex, true)
+	})
+	if err != nil {
+		return err
 	}
 
 	if len(multiTenantIndices) > 0 {
@@ -297,6 +314,7 @@ func (c *compactedIndex) ForEachChunk(ctx context.Context, callback retention.Ch
 			chunkEntry.ChunkID = getUnsafeBytes(schemaCfg.ExternalKey(logprotoChunkRef))
 			chunkEntry.From = logprotoChunkRef.From
 			chunkEntry.Through = logprotoChunkRef.Through
+			chunkEntry.KB = chk.KB
 
 			deleteChunk, err := callback(chunkEntry)
 			if err != nil {
@@ -630,6 +630,7 @@ func chunkMetasToChunkEntry(schemaCfg config.SchemaConfig, userID string, lbls l
 				ChunkID:  []byte(schemaCfg.ExternalKey(chunkMetaToChunkRef(userID, chunkMeta, lbls))),
 				From:     chunkMeta.From(),
 				Through:  chunkMeta.Through(),
+				KB:       chunkMeta.KB,
 			},
 			Labels: lbls,
 		})
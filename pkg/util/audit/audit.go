@@ -0,0 +1,101 @@
+package audit
+
+import (
+	"flag"
+	"os"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+)
+
+const (
+	// SourceFrontend is the source of the queries executed by the query frontend.
+	SourceFrontend = "frontend"
+	// SourceRuler is the source of the queries evaluated by the ruler,
+	// locally or remotely by the query frontend.
+	SourceRuler = "ruler"
+)
+
+// Config configures the audit log of the executed queries.
+type Config struct {
+	Enabled bool   `yaml:"enabled"`
+	File    string `yaml:"file"`
+}
+
+// RegisterFlags registers flags.
+func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, "query-audit-log.enabled", false, "Log an audit entry for every query executed by the query frontend or evaluated by the ruler, with its tenant, query, time range, source, duration, processed bytes and chunks, and status.")
+	f.StringVar(&cfg.File, "query-audit-log.file", "", "File the audit entries are appended to, as JSON lines. By default, they are written to the log of Loki.")
+}
+
+// Entry is the audit entry of an executed query.
+type Entry struct {
+	Tenant   string
+	Query    string
+	Start    time.Time
+	End      time.Time
+	Step     time.Duration
+	Source   string
+	Duration time.Duration
+	Bytes    int64
+	Chunks   int64
+	Status   string
+}
+
+// Logger logs the audit entries of the executed queries.
+// A nil Logger doesn't log anything, so it can be used when the audit log is disabled.
+type Logger struct {
+	logger log.Logger
+	file   *os.File
+}
+
+// New returns the Logger of the audit entries, or nil if the audit log is disabled.
+func New(cfg Config, logger log.Logger) (*Logger, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	if cfg.File == "" {
+		return &Logger{logger: log.With(logger, "component", "query-audit")}, nil
+	}
+
+	f, err := os.OpenFile(cfg.File, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o640)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening query audit log file")
+	}
+	return &Logger{
+		logger: log.With(log.NewJSONLogger(log.NewSyncWriter(f)), "ts", log.DefaultTimestampUTC),
+		file:   f,
+	}, nil
+}
+
+// Log logs the audit entry of an executed query.
+func (l *Logger) Log(e Entry) {
+	if l == nil {
+		return
+	}
+
+	level.Info(l.logger).Log(
+		"msg", "query audit",
+		"tenant", e.Tenant,
+		"query", e.Query,
+		"start", e.Start.UTC().Format(time.RFC3339Nano),
+		"end", e.End.UTC().Format(time.RFC3339Nano),
+		"step", e.Step.String(),
+		"source", e.Source,
+		"duration", e.Duration.String(),
+		"bytes", e.Bytes,
+		"chunks", e.Chunks,
+		"status", e.Status,
+	)
+}
+
+// Close closes the file of the audit entries, if any.
+func (l *Logger) Close() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	return l.file.Close()
+}
@@ -0,0 +1,37 @@
+package audit
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger(t *testing.T) {
+	// A disabled audit log doesn't log anything.
+	l, err := New(Config{}, log.NewNopLogger())
+	require.NoError(t, err)
+	require.Nil(t, l)
+	l.Log(Entry{Tenant: "fake"})
+	require.NoError(t, l.Close())
+
+	// Without file, the entries are logged by the process logger.
+	var buf bytes.Buffer
+	l, err = New(Config{Enabled: true}, log.NewLogfmtLogger(&buf))
+	require.NoError(t, err)
+	l.Log(Entry{
+		Tenant:   "fake",
+		Query:    `{app="foo"}`,
+		Start:    time.Unix(0, 0),
+		End:      time.Unix(3600, 0),
+		Source:   SourceRuler,
+		Duration: time.Second,
+		Bytes:    1024,
+		Chunks:   2,
+		Status:   "200",
+	})
+	require.NoError(t, l.Close())
+	require.Equal(t, `level=info component=query-audit msg="query audit" tenant=fake query="{app=\"foo\"}" start=1970-01-01T00:00:00Z end=1970-01-01T01:00:00Z step=0s source=ruler duration=1s bytes=1024 chunks=2 status=200`+"\n", buf.String())
+}
@@ -0,0 +1,27 @@
+package util
+
+import (
+	"fmt"
+
+	"github.com/grafana/dskit/grpcclient"
+)
+
+// ValidateGRPCClientTLS returns an error if the TLS options of the gRPC client
+// configured by the flags with the given prefix are set while TLS is disabled,
+// as the client then connects in cleartext, or if only one of the certificate
+// and key of the client is set, as mutual TLS requires both.
+func ValidateGRPCClientTLS(prefix string, cfg grpcclient.Config) error {
+	tlsCfg := cfg.TLS
+	if !cfg.TLSEnabled {
+		if tlsCfg.CertPath != "" || tlsCfg.KeyPath != "" || tlsCfg.CAPath != "" || tlsCfg.ServerName != "" ||
+			tlsCfg.InsecureSkipVerify || tlsCfg.CipherSuites != "" || tlsCfg.MinVersion != "" {
+			return fmt.Errorf("TLS options of the gRPC client are set, but TLS is disabled: set -%s.tls-enabled to true", prefix)
+		}
+		return nil
+	}
+
+	if (tlsCfg.CertPath == "") != (tlsCfg.KeyPath == "") {
+		return fmt.Errorf("both -%[1]s.tls-cert-path and -%[1]s.tls-key-path must be set for the gRPC client to authenticate with a certificate", prefix)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/grafana/dskit/crypto/tls"
+	"github.com/grafana/dskit/grpcclient"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateGRPCClientTLS(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		cfg  grpcclient.Config
+		err  string
+	}{
+		{
+			name: "cleartext",
+		},
+		{
+			name: "tls options without tls",
+			cfg:  grpcclient.Config{TLS: tls.ClientConfig{CAPath: "ca.crt"}},
+			err:  "TLS options of the gRPC client are set, but TLS is disabled: set -frontend.grpc-client-config.tls-enabled to true",
+		},
+		{
+			name: "tls",
+			cfg:  grpcclient.Config{TLSEnabled: true, TLS: tls.ClientConfig{CAPath: "ca.crt", MinVersion: "VersionTLS13"}},
+		},
+		{
+			name: "mutual tls",
+			cfg:  grpcclient.Config{TLSEnabled: true, TLS: tls.ClientConfig{CertPath: "client.crt", KeyPath: "client.key", CAPath: "ca.crt"}},
+		},
+		{
+			name: "certificate without key",
+			cfg:  grpcclient.Config{TLSEnabled: true, TLS: tls.ClientConfig{CertPath: "client.crt"}},
+			err:  "both -frontend.grpc-client-config.tls-cert-path and -frontend.grpc-client-config.tls-key-path must be set for the gRPC client to authenticate with a certificate",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateGRPCClientTLS("frontend.grpc-client-config", tc.cfg)
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
@@ -16,6 +16,24 @@ var (
 
 	// LokiActorPathDelimiter is the delimiter used to serialise the hierarchy of the actor.
 	LokiActorPathDelimiter = "|"
+
+	// LokiQueryPriorityHeader is the name of the header used to select the priority class
+	// of a query, which the query-scheduler enqueues it with.
+	LokiQueryPriorityHeader = "X-Loki-Query-Priority"
+
+	// LokiQuantileSketchesHeader is the name of the header the frontend sets on the quantile_over_time
+	// queries it shards and splits, for the queriers to return the quantile sketches instead of the quantiles.
+	LokiQuantileSketchesHeader = "X-Loki-Quantile-Sketches"
+
+	// RulerUserAgentPrefix is the prefix of the user agent of the rulers evaluating their rules remotely.
+	RulerUserAgentPrefix = "loki-ruler/"
+)
+
+// Priority classes of the queries.
+const (
+	PriorityInteractive = "interactive"
+	PriorityRuler       = "ruler"
+	PriorityBatch       = "batch"
 )
 
 func PropagateHeadersMiddleware(headers ...string) middleware.Interface {
@@ -34,6 +52,11 @@ func PropagateHeadersMiddleware(headers ...string) middleware.Interface {
 	})
 }
 
+// InjectHeader puts the value of the header into the context, as PropagateHeadersMiddleware does.
+func InjectHeader(ctx context.Context, name, value string) context.Context {
+	return context.WithValue(ctx, headerContextKey(name), value)
+}
+
 func ExtractHeader(ctx context.Context, name string) string {
 	s, _ := ctx.Value(headerContextKey(name)).(string)
 	return s
@@ -46,3 +69,31 @@ func ExtractActorPath(ctx context.Context) []string {
 	}
 	return strings.Split(value, LokiActorPathDelimiter)
 }
+
+// ExtractQueryPriorityMiddleware puts the priority class of the query into the context,
+// from the LokiQueryPriorityHeader, or from the user agent of the rulers if the header is not set.
+func ExtractQueryPriorityMiddleware() middleware.Interface {
+	return middleware.Func(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			priority := req.Header.Get(LokiQueryPriorityHeader)
+			if priority == "" && strings.HasPrefix(req.UserAgent(), RulerUserAgentPrefix) {
+				priority = PriorityRuler
+			}
+			if priority != "" {
+				req = req.WithContext(context.WithValue(req.Context(), headerContextKey(LokiQueryPriorityHeader), priority))
+			}
+			next.ServeHTTP(w, req)
+		})
+	})
+}
+
+// QueryPriority returns the priority class of the query, PriorityInteractive
+// if the value of the LokiQueryPriorityHeader is empty or unknown.
+func QueryPriority(value string) string {
+	switch value {
+	case PriorityRuler, PriorityBatch:
+		return value
+	default:
+		return PriorityInteractive
+	}
+}
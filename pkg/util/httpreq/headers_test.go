@@ -0,0 +1,63 @@
+package httpreq
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryPriority(t *testing.T) {
+	for _, tc := range []struct {
+		desc      string
+		header    string
+		userAgent string
+		exp       string
+	}{
+		{
+			desc: "no-priority",
+			exp:  PriorityInteractive,
+		},
+		{
+			desc:   "header",
+			header: PriorityBatch,
+			exp:    PriorityBatch,
+		},
+		{
+			desc:   "unknown-priority",
+			header: "urgent",
+			exp:    PriorityInteractive,
+		},
+		{
+			desc:      "ruler",
+			userAgent: RulerUserAgentPrefix + "2.8.0",
+			exp:       PriorityRuler,
+		},
+		{
+			desc:      "header-overrides-ruler",
+			header:    PriorityBatch,
+			userAgent: RulerUserAgentPrefix + "2.8.0",
+			exp:       PriorityBatch,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "http://testing.com", nil)
+			if tc.header != "" {
+				req.Header.Set(LokiQueryPriorityHeader, tc.header)
+			}
+			if tc.userAgent != "" {
+				req.Header.Set("User-Agent", tc.userAgent)
+			}
+
+			checked := false
+			mware := ExtractQueryPriorityMiddleware().Wrap(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				require.Equal(t, tc.exp, QueryPriority(ExtractHeader(req.Context(), LokiQueryPriorityHeader)))
+				checked = true
+			}))
+
+			mware.ServeHTTP(httptest.NewRecorder(), req)
+			require.True(t, checked)
+		})
+	}
+}
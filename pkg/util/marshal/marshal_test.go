@@ -19,6 +19,7 @@ import (
 	legacy "github.com/grafana/loki/pkg/loghttp/legacy"
 	"github.com/grafana/loki/pkg/logproto"
 	"github.com/grafana/loki/pkg/logqlmodel"
+	"github.com/grafana/loki/pkg/push"
 )
 
 // covers responses from /loki/api/v1/query_range and /loki/api/v1/query
@@ -114,6 +115,169 @@ var queryTests = []struct {
 							"bytesSent": 0,
 							"requests": 0,
 							"downloadTime": 0
+						},
+						"statsResult": {
+							"entriesFound": 0,
+							"entriesRequested": 0,
+							"entriesStored": 0,
+							"bytesReceived": 0,
+							"bytesSent": 0,
+							"requests": 0,
+							"downloadTime": 0
+						},
+						"seriesResult": {
+							"entriesFound": 0,
+							"entriesRequested": 0,
+							"entriesStored": 0,
+							"bytesReceived": 0,
+							"bytesSent": 0,
+							"requests": 0,
+							"downloadTime": 0
+						},
+						"labelResult": {
+							"entriesFound": 0,
+							"entriesRequested": 0,
+							"entriesStored": 0,
+							"bytesReceived": 0,
+							"bytesSent": 0,
+							"requests": 0,
+							"downloadTime": 0
+						}
+					},
+					"summary": {
+						"bytesProcessedPerSecond": 0,
+						"execTime": 0,
+						"linesProcessedPerSecond": 0,
+						"queueTime": 0,
+                        "shards": 0,
+                        "splits": 0,
+						"subqueries": 0,
+						"totalBytesProcessed":0,
+                                                "totalEntriesReturned":0,
+						"totalLinesProcessed":0
+					}
+				}
+			}
+		}`,
+	},
+	{
+		logqlmodel.Streams{
+			logproto.Stream{
+				Entries: []logproto.Entry{
+					{
+						Timestamp: time.Unix(0, 123456789012345),
+						Line:      "super line",
+						StructuredMetadata: push.LabelsAdapter{
+							{Name: "traceID", Value: "123"},
+						},
+					},
+				},
+				Labels: `{test="test"}`,
+			},
+		},
+		`{
+			"status": "success",
+			"data": {
+				"resultType": "streams",
+				"result": [
+					{
+						"stream": {
+							"test": "test"
+						},
+						"values":[
+							[ "123456789012345", "super line", { "traceID": "123" } ]
+						]
+					}
+				],
+				"stats" : {
+					"ingester" : {
+						"store": {
+							"chunksDownloadTime": 0,
+							"totalChunksRef": 0,
+							"totalChunksDownloaded": 0,
+							"chunk" :{
+								"compressedBytes": 0,
+								"decompressedBytes": 0,
+								"decompressedLines": 0,
+								"headChunkBytes": 0,
+								"headChunkLines": 0,
+								"totalDuplicates": 0
+							}
+						},
+						"totalBatches": 0,
+						"totalChunksMatched": 0,
+						"totalLinesSent": 0,
+						"totalReached": 0
+					},
+					"querier": {
+						"store": {
+							"chunksDownloadTime": 0,
+							"totalChunksRef": 0,
+							"totalChunksDownloaded": 0,
+							"chunk" :{
+								"compressedBytes": 0,
+								"decompressedBytes": 0,
+								"decompressedLines": 0,
+								"headChunkBytes": 0,
+								"headChunkLines": 0,
+								"totalDuplicates": 0
+							}
+						}
+					},
+					"cache": {
+						"chunk": {
+							"entriesFound": 0,
+							"entriesRequested": 0,
+							"entriesStored": 0,
+							"bytesReceived": 0,
+							"bytesSent": 0,
+							"requests": 0,
+							"downloadTime": 0
+						},
+						"index": {
+							"entriesFound": 0,
+							"entriesRequested": 0,
+							"entriesStored": 0,
+							"bytesReceived": 0,
+							"bytesSent": 0,
+							"requests": 0,
+							"downloadTime": 0
+						},
+						"result": {
+							"entriesFound": 0,
+							"entriesRequested": 0,
+							"entriesStored": 0,
+							"bytesReceived": 0,
+							"bytesSent": 0,
+							"requests": 0,
+							"downloadTime": 0
+						},
+						"statsResult": {
+							"entriesFound": 0,
+							"entriesRequested": 0,
+							"entriesStored": 0,
+							"bytesReceived": 0,
+							"bytesSent": 0,
+							"requests": 0,
+							"downloadTime": 0
+						},
+						"seriesResult": {
+							"entriesFound": 0,
+							"entriesRequested": 0,
+							"entriesStored": 0,
+							"bytesReceived": 0,
+							"bytesSent": 0,
+							"requests": 0,
+							"downloadTime": 0
+						},
+						"labelResult": {
+							"entriesFound": 0,
+							"entriesRequested": 0,
+							"entriesStored": 0,
+							"bytesReceived": 0,
+							"bytesSent": 0,
+							"requests": 0,
+							"downloadTime": 0
 						}
 					},
 					"summary": {
@@ -251,6 +415,33 @@ var queryTests = []struct {
 						"bytesSent": 0,
 						"requests": 0,
 						"downloadTime": 0
+					},
+					"statsResult": {
+						"entriesFound": 0,
+						"entriesRequested": 0,
+						"entriesStored": 0,
+						"bytesReceived": 0,
+						"bytesSent": 0,
+						"requests": 0,
+						"downloadTime": 0
+					},
+					"seriesResult": {
+						"entriesFound": 0,
+						"entriesRequested": 0,
+						"entriesStored": 0,
+						"bytesReceived": 0,
+						"bytesSent": 0,
+						"requests": 0,
+						"downloadTime": 0
+					},
+					"labelResult": {
+						"entriesFound": 0,
+						"entriesRequested": 0,
+						"entriesStored": 0,
+						"bytesReceived": 0,
+						"bytesSent": 0,
+						"requests": 0,
+						"downloadTime": 0
 					}
 				},
 				"summary": {
@@ -409,6 +600,33 @@ var queryTests = []struct {
 						"bytesSent": 0,
 						"requests": 0,
 						"downloadTime": 0
+					},
+					"statsResult": {
+						"entriesFound": 0,
+						"entriesRequested": 0,
+						"entriesStored": 0,
+						"bytesReceived": 0,
+						"bytesSent": 0,
+						"requests": 0,
+						"downloadTime": 0
+					},
+					"seriesResult": {
+						"entriesFound": 0,
+						"entriesRequested": 0,
+						"entriesStored": 0,
+						"bytesReceived": 0,
+						"bytesSent": 0,
+						"requests": 0,
+						"downloadTime": 0
+					},
+					"labelResult": {
+						"entriesFound": 0,
+						"entriesRequested": 0,
+						"entriesStored": 0,
+						"bytesReceived": 0,
+						"bytesSent": 0,
+						"requests": 0,
+						"downloadTime": 0
 					}
 				},
 				"summary": {
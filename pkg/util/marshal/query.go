@@ -105,8 +105,9 @@ func NewStream(s logproto.Stream) (loghttp.Stream, error) {
 // NewEntry constructs an Entry from a logproto.Entry
 func NewEntry(e logproto.Entry) loghttp.Entry {
 	return loghttp.Entry{
-		Timestamp: e.Timestamp,
-		Line:      e.Line,
+		Timestamp:          e.Timestamp,
+		Line:               e.Line,
+		StructuredMetadata: logproto.FromStructuredMetadataToLabels(e.StructuredMetadata),
 	}
 }
 
@@ -315,6 +316,18 @@ func encodeStream(stream logproto.Stream, s *jsoniter.Stream) error {
 		s.WriteRaw(`"`)
 		s.WriteMore()
 		s.WriteStringWithHTMLEscaped(e.Line)
+		if len(e.StructuredMetadata) > 0 {
+			s.WriteMore()
+			s.WriteObjectStart()
+			for i, l := range e.StructuredMetadata {
+				if i > 0 {
+					s.WriteMore()
+				}
+				s.WriteObjectField(l.Name)
+				s.WriteStringWithHTMLEscaped(l.Value)
+			}
+			s.WriteObjectEnd()
+		}
 		s.WriteArrayEnd()
 
 		s.Flush()
@@ -667,6 +667,30 @@ func (r *UserRegistries) softRemoveUserRegistry(ur *UserRegistry) bool {
 	return true
 }
 
+// GatherUser gathers the metrics of the registry of the given user. It returns
+// nil if the user has no registry.
+func (r *UserRegistries) GatherUser(user string) (MetricFamilyMap, error) {
+	var reg *prometheus.Registry
+	r.regsMu.Lock()
+	for _, entry := range r.regs {
+		if entry.user == user && entry.reg != nil {
+			reg = entry.reg
+			break
+		}
+	}
+	r.regsMu.Unlock()
+
+	if reg == nil {
+		return nil, nil
+	}
+
+	m, err := reg.Gather()
+	if err != nil {
+		return nil, err
+	}
+	return NewMetricFamilyMap(m)
+}
+
 // Registries returns a copy of the user registries list.
 func (r *UserRegistries) Registries() []UserRegistry {
 	r.regsMu.Lock()
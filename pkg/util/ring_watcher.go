@@ -31,7 +31,14 @@ func NewRingWatcher(log log.Logger, ring ring.ReadRing, lookupPeriod time.Durati
 		notifications: notifications,
 		lookupPeriod:  lookupPeriod,
 	}
-	return services.NewBasicService(nil, w.watchLoop, nil), nil
+	return services.NewBasicService(w.starting, w.watchLoop, nil), nil
+}
+
+// starting looks up the addresses once, so the instances of the ring are
+// used right away rather than after the first lookup period.
+func (w *ringWatcher) starting(_ context.Context) error {
+	w.lookupAddresses()
+	return nil
 }
 
 // watchLoop watches for changes in DNS and sends notifications.
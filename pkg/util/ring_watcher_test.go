@@ -0,0 +1,45 @@
+package util
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/ring"
+	"github.com/grafana/dskit/services"
+	"github.com/stretchr/testify/require"
+)
+
+type addressNotifications struct {
+	added, removed []string
+}
+
+func (n *addressNotifications) AddressAdded(address string)   { n.added = append(n.added, address) }
+func (n *addressNotifications) AddressRemoved(address string) { n.removed = append(n.removed, address) }
+
+func TestRingWatcher(t *testing.T) {
+	r := newReadRingMock([]ring.InstanceDesc{
+		{Addr: "scheduler-0", State: ring.ACTIVE},
+		{Addr: "scheduler-1", State: ring.ACTIVE},
+	})
+	n := &addressNotifications{}
+
+	// The addresses are looked up on start, rather than after the first lookup period.
+	w, err := NewRingWatcher(log.NewNopLogger(), r, time.Hour, n)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), w))
+	t.Cleanup(func() { _ = services.StopAndAwaitTerminated(context.Background(), w) })
+	require.Equal(t, []string{"scheduler-0", "scheduler-1"}, n.added)
+
+	// Scaling the ring replaces the instances which left it.
+	r.replicationSet.Instances = []ring.InstanceDesc{
+		{Addr: "scheduler-1", State: ring.ACTIVE},
+		{Addr: "scheduler-2", State: ring.ACTIVE},
+	}
+	n.added = nil
+	rw := &ringWatcher{log: log.NewNopLogger(), ring: r, notifications: n, addresses: []string{"scheduler-0", "scheduler-1"}}
+	rw.lookupAddresses()
+	require.Equal(t, []string{"scheduler-2"}, n.added)
+	require.Equal(t, []string{"scheduler-0"}, n.removed)
+}
@@ -4,8 +4,14 @@ import (
 	"crypto/md5"
 	"encoding/binary"
 	"math"
+
+	"github.com/prometheus/prometheus/model/labels"
 )
 
+// ShardLbName is the internal label used by Loki when dividing a stream into smaller pieces.
+// Possible values are only increasing integers starting from 0.
+const ShardLbName = "__stream_shard__"
+
 // Sharding strategies & algorithms.
 const (
 	// ShardingStrategyDefault shards rule groups across available rulers in the ring.
@@ -53,3 +59,25 @@ func ShuffleShardExpectedInstancesPerZone(shardSize, numZones int) int {
 func ShuffleShardExpectedInstances(shardSize, numZones int) int {
 	return ShuffleShardExpectedInstancesPerZone(shardSize, numZones) * numZones
 }
+
+// WithoutShardLabel returns the labels without the internal stream shard label, so that
+// the shards of a stream are collapsed back into a single stream when they are read.
+// The labels are returned as is if they do not contain the shard label.
+func WithoutShardLabel(lbs labels.Labels) labels.Labels {
+	if !lbs.Has(ShardLbName) {
+		return lbs
+	}
+	return labels.NewBuilder(lbs).Del(ShardLbName).Labels()
+}
+
+// WithoutShardLabelName returns the label names without the internal stream shard label name.
+// The names are returned as is if they do not contain it.
+func WithoutShardLabelName(names []string) []string {
+	for i, name := range names {
+		if name == ShardLbName {
+			res := make([]string, 0, len(names)-1)
+			return append(append(res, names[:i]...), names[i+1:]...)
+		}
+	}
+	return names
+}
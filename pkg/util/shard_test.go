@@ -3,6 +3,7 @@ package util
 import (
 	"testing"
 
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -81,3 +82,20 @@ func TestShuffleShardExpectedInstances(t *testing.T) {
 		assert.Equal(t, test.expected, ShuffleShardExpectedInstances(test.shardSize, test.numZones))
 	}
 }
+
+func TestWithoutShardLabel(t *testing.T) {
+	lbs := labels.FromStrings("app", "foo", ShardLbName, "1")
+	assert.Equal(t, labels.FromStrings("app", "foo"), WithoutShardLabel(lbs))
+
+	lbs = labels.FromStrings("app", "foo")
+	assert.Equal(t, lbs, WithoutShardLabel(lbs))
+}
+
+func TestWithoutShardLabelName(t *testing.T) {
+	names := []string{"__stream_shard__", "app", "env"}
+	assert.Equal(t, []string{"app", "env"}, WithoutShardLabelName(names))
+	assert.Equal(t, []string{"__stream_shard__", "app", "env"}, names)
+
+	names = []string{"app", "env"}
+	assert.Equal(t, names, WithoutShardLabelName(names))
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/grafana/loki/pkg/loghttp"
 	legacy_loghttp "github.com/grafana/loki/pkg/loghttp/legacy"
 	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/push"
 	"github.com/grafana/loki/pkg/util/marshal"
 )
 
@@ -45,6 +46,35 @@ var pushTests = []struct {
 			]
 		}`,
 	},
+	{
+		[]logproto.Stream{
+			{
+				Entries: []logproto.Entry{
+					{
+						Timestamp: time.Unix(0, 123456789012345),
+						Line:      "super line",
+						StructuredMetadata: push.LabelsAdapter{
+							{Name: "a", Value: "1"},
+							{Name: "b", Value: "2"},
+						},
+					},
+				},
+				Labels: `{test="test"}`,
+			},
+		},
+		`{
+			"streams": [
+				{
+					"stream": {
+						"test": "test"
+					},
+					"values":[
+						[ "123456789012345", "super line", { "a": "1", "b": "2" } ]
+					]
+				}
+			]
+		}`,
+	},
 }
 
 func Test_DecodePushRequest(t *testing.T) {
@@ -5,7 +5,11 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/go-kit/log/level"
@@ -16,10 +20,12 @@ import (
 	"github.com/prometheus/common/sigv4"
 	"github.com/prometheus/prometheus/config"
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
 	"golang.org/x/time/rate"
 	"gopkg.in/yaml.v2"
 
 	"github.com/grafana/loki/pkg/distributor/shardstreams"
+	"github.com/grafana/loki/pkg/logql/log"
 	"github.com/grafana/loki/pkg/logql/syntax"
 	ruler_config "github.com/grafana/loki/pkg/ruler/config"
 	"github.com/grafana/loki/pkg/ruler/util"
@@ -50,6 +56,9 @@ const (
 	defaultPerStreamRateLimit  = 3 << 20 // 3MB
 	defaultPerStreamBurstLimit = 5 * defaultPerStreamRateLimit
 
+	defaultMaxStructuredMetadataSize  = "64kb"
+	defaultMaxStructuredMetadataCount = 128
+
 	DefaultPerTenantQueryTimeout = "1m"
 )
 
@@ -71,14 +80,42 @@ type Limits struct {
 	EnforceMetricName           bool             `yaml:"enforce_metric_name" json:"enforce_metric_name"`
 	MaxLineSize                 flagext.ByteSize `yaml:"max_line_size" json:"max_line_size"`
 	MaxLineSizeTruncate         bool             `yaml:"max_line_size_truncate" json:"max_line_size_truncate"`
+	MaxLineSizeTruncateMarker   string           `yaml:"max_line_size_truncate_marker" json:"max_line_size_truncate_marker"`
 	IncrementDuplicateTimestamp bool             `yaml:"increment_duplicate_timestamp" json:"increment_duplicate_timestamp"`
 
+	DistributorPerStreamRateLimit      flagext.ByteSize `yaml:"distributor_per_stream_rate_limit" json:"distributor_per_stream_rate_limit"`
+	DistributorPerStreamRateLimitBurst flagext.ByteSize `yaml:"distributor_per_stream_rate_limit_burst" json:"distributor_per_stream_rate_limit_burst"`
+
+	AllowStructuredMetadata           bool             `yaml:"allow_structured_metadata" json:"allow_structured_metadata"`
+	MaxStructuredMetadataSize         flagext.ByteSize `yaml:"max_structured_metadata_size" json:"max_structured_metadata_size"`
+	MaxStructuredMetadataEntriesCount int              `yaml:"max_structured_metadata_entries_count" json:"max_structured_metadata_entries_count"`
+
+	IngestionRelabelConfigs       []*util.RelabelConfig `yaml:"ingestion_relabel_configs,omitempty" json:"ingestion_relabel_configs,omitempty" doc:"description=List of relabel configurations applied to the labels of incoming streams before they are validated and sharded. Streams dropped by a relabel rule are discarded."`
+	ParsedIngestionRelabelConfigs []*relabel.Config     `yaml:"-" json:"-"` // populated during validation.
+
+	DropRules []DropRule `yaml:"drop_rules,omitempty" json:"drop_rules,omitempty" doc:"description=Rules to discard log entries in the distributor before they reach the ingesters.\nExample:\n drop_rules:\n - name: debug-logs\n selector: '{namespace=\"dev\"}'\n line_regex: 'level=debug'\nAn entry is discarded if the labels of its stream match the selector and, if set, its line matches the regular expression. Discarded entries are counted per rule."`
+
+	SamplingRules []SamplingRule `yaml:"sampling_rules,omitempty" json:"sampling_rules,omitempty" doc:"description=Rules to keep only a fraction of the entries of matching streams in the distributor.\nExample:\n sampling_rules:\n - selector: '{app=\"debug-logger\"}'\n rate: 0.1\nThe first rule whose selector matches the labels of a stream applies. Whether an entry is kept is derived from a hash of its line, so all distributors and retries agree."`
+
+	IngestionPipelines []IngestionPipeline `yaml:"ingestion_pipelines,omitempty" json:"ingestion_pipelines,omitempty" doc:"description=Pipelines processing the entries of matching streams in the distributor, for agents that can't process their logs themselves.\nExample:\n ingestion_pipelines:\n - selector: '{job=\"syslog\"}'\n stages: '| json | line_format \"{{.msg}}\"'\n labels: [level]\n timestamp_label: ts\n timestamp_format: RFC3339\nThe first pipeline whose selector matches the labels of a stream applies. Only the json, logfmt, line_format and label_format stages are supported. The extracted labels listed in labels are added to the stream labels. If timestamp_label is set, the timestamp of the entries is parsed from the extracted label with timestamp_format, which is either RFC3339, RFC3339Nano, Unix, UnixMs, UnixNs or a Go time layout."`
+
+	AcceptHASamples bool   `yaml:"accept_ha_samples" json:"accept_ha_samples"`
+	HAClusterLabel  string `yaml:"ha_cluster_label" json:"ha_cluster_label"`
+	HAReplicaLabel  string `yaml:"ha_replica_label" json:"ha_replica_label"`
+
 	// Ingester enforced limits.
 	MaxLocalStreamsPerUser  int              `yaml:"max_streams_per_user" json:"max_streams_per_user"`
 	MaxGlobalStreamsPerUser int              `yaml:"max_global_streams_per_user" json:"max_global_streams_per_user"`
+	EvictStreamsOnLimit     bool             `yaml:"evict_streams_on_limit" json:"evict_streams_on_limit"`
+	MaxTailersPerUser       int              `yaml:"max_tailers_per_user" json:"max_tailers_per_user"`
 	UnorderedWrites         bool             `yaml:"unordered_writes" json:"unordered_writes"`
+	OutOfOrderTimeWindow    model.Duration   `yaml:"out_of_order_time_window" json:"out_of_order_time_window"`
+	DuplicateEntriesWindow  model.Duration   `yaml:"duplicate_entries_window" json:"duplicate_entries_window"`
 	PerStreamRateLimit      flagext.ByteSize `yaml:"per_stream_rate_limit" json:"per_stream_rate_limit"`
 	PerStreamRateLimitBurst flagext.ByteSize `yaml:"per_stream_rate_limit_burst" json:"per_stream_rate_limit_burst"`
+	ChunkTargetSize         flagext.ByteSize `yaml:"chunk_target_size" json:"chunk_target_size"`
+	ChunkIdlePeriod         model.Duration   `yaml:"chunk_idle_period" json:"chunk_idle_period"`
+	MaxChunkAge             model.Duration   `yaml:"max_chunk_age" json:"max_chunk_age"`
 
 	// Querier enforced limits.
 	MaxChunksPerQuery          int            `yaml:"max_chunks_per_query" json:"max_chunks_per_query"`
@@ -89,11 +126,15 @@ type Limits struct {
 	MaxQueryParallelism        int            `yaml:"max_query_parallelism" json:"max_query_parallelism"`
 	TSDBMaxQueryParallelism    int            `yaml:"tsdb_max_query_parallelism" json:"tsdb_max_query_parallelism"`
 	CardinalityLimit           int            `yaml:"cardinality_limit" json:"cardinality_limit"`
+	MaxParallelGetChunk        int            `yaml:"max_parallel_get_chunk" json:"max_parallel_get_chunk"`
 	MaxStreamsMatchersPerQuery int            `yaml:"max_streams_matchers_per_query" json:"max_streams_matchers_per_query"`
 	MaxConcurrentTailRequests  int            `yaml:"max_concurrent_tail_requests" json:"max_concurrent_tail_requests"`
 	MaxEntriesLimitPerQuery    int            `yaml:"max_entries_limit_per_query" json:"max_entries_limit_per_query"`
 	MaxCacheFreshness          model.Duration `yaml:"max_cache_freshness_per_query" json:"max_cache_freshness_per_query"`
 	MaxStatsCacheFreshness     model.Duration `yaml:"max_stats_cache_freshness" json:"max_stats_cache_freshness"`
+	RecentStatsCacheTTL        model.Duration `yaml:"recent_stats_cache_ttl" json:"recent_stats_cache_ttl"`
+	MaxMetadataCacheFreshness  model.Duration `yaml:"max_metadata_cache_freshness" json:"max_metadata_cache_freshness"`
+	MetadataCacheTTL           model.Duration `yaml:"metadata_cache_ttl" json:"metadata_cache_ttl"`
 	MaxQueriersPerTenant       int            `yaml:"max_queriers_per_tenant" json:"max_queriers_per_tenant"`
 	QueryReadyIndexNumDays     int            `yaml:"query_ready_index_num_days" json:"query_ready_index_num_days"`
 	QueryTimeout               model.Duration `yaml:"query_timeout" json:"query_timeout"`
@@ -104,6 +145,19 @@ type Limits struct {
 	MaxQueryBytesRead   flagext.ByteSize `yaml:"max_query_bytes_read" json:"max_query_bytes_read"`
 	MaxQuerierBytesRead flagext.ByteSize `yaml:"max_querier_bytes_read" json:"max_querier_bytes_read"`
 
+	// The instant metric queries are split by the QuerySplitDuration when the InstantMetricQuerySplitDuration is unset.
+	InstantMetricQuerySplitDuration model.Duration `yaml:"split_instant_metric_queries_by_interval" json:"split_instant_metric_queries_by_interval"`
+	MetadataQuerySplitDuration      model.Duration `yaml:"split_metadata_queries_by_interval" json:"split_metadata_queries_by_interval"`
+
+	AlignQueriesWithStep bool `yaml:"align_queries_with_step" json:"align_queries_with_step"`
+
+	QuantileSketchSharding bool `yaml:"quantile_sketch_sharding" json:"quantile_sketch_sharding"`
+
+	QueryMaxRetries       int            `yaml:"query_max_retries" json:"query_max_retries"`
+	QueryRetryMinBackoff  model.Duration `yaml:"query_retry_min_backoff" json:"query_retry_min_backoff"`
+	QueryRetryMaxBackoff  model.Duration `yaml:"query_retry_max_backoff" json:"query_retry_max_backoff"`
+	QueryRetryStatusCodes []int          `yaml:"query_retry_status_codes,omitempty" json:"query_retry_status_codes,omitempty" doc:"description=HTTP status codes of the failed requests the query frontend retries for the tenant, for example [500, 502, 503, 504, 429]. Requests failed with a non-HTTP error are always retried. By default, all 5xx status codes are retried."`
+
 	// Ruler defaults and limits.
 
 	// TODO(dannyk): this setting is misnamed and probably deprecatable.
@@ -113,6 +167,13 @@ type Limits struct {
 	RulerAlertManagerConfig     *ruler_config.AlertManagerConfig `yaml:"ruler_alertmanager_config" json:"ruler_alertmanager_config" doc:"hidden"`
 	RulerTenantShardSize        int                              `yaml:"ruler_tenant_shard_size" json:"ruler_tenant_shard_size"`
 
+	RulerMinEvaluationInterval    model.Duration `yaml:"ruler_min_evaluation_interval" json:"ruler_min_evaluation_interval"`
+	RulerMaxConcurrentEvaluations int            `yaml:"ruler_max_concurrent_evaluations" json:"ruler_max_concurrent_evaluations"`
+
+	RulerExternalLabels      labels.Labels         `yaml:"ruler_external_labels,omitempty" json:"ruler_external_labels,omitempty" doc:"description=Labels to add to the alerts and to the samples remote-written by the recording rules of the tenant, in addition to the external labels of the ruler."`
+	RulerExternalURL         string                `yaml:"ruler_external_url" json:"ruler_external_url" doc:"description=Base URL of the Grafana instance of the tenant, used in the links of its alerts instead of the external URL of the ruler."`
+	RulerAlertRelabelConfigs []*util.RelabelConfig `yaml:"ruler_alert_relabel_configs,omitempty" json:"ruler_alert_relabel_configs,omitempty" doc:"description=List of alert relabel configurations of the tenant, applied to the alerts after the alert relabel configurations of the ruler, before they are sent to the Alertmanager."`
+
 	// TODO(dannyk): add HTTP client overrides (basic auth / tls config, etc)
 	// Ruler remote-write limits.
 
@@ -147,7 +208,7 @@ type Limits struct {
 	// deprecated use RulerRemoteWriteConfig instead
 	RulerRemoteWriteSigV4Config *sigv4.SigV4Config `yaml:"ruler_remote_write_sigv4_config" json:"ruler_remote_write_sigv4_config" doc:"deprecated|description=Use 'ruler_remote_write_config' instead. Configures AWS's Signature Verification 4 signing process to sign every remote write request."`
 
-	RulerRemoteWriteConfig map[string]config.RemoteWriteConfig `yaml:"ruler_remote_write_config,omitempty" json:"ruler_remote_write_config,omitempty" doc:"description=Configures global and per-tenant limits for remote write clients. A map with remote client id as key."`
+	RulerRemoteWriteConfig map[string]config.RemoteWriteConfig `yaml:"ruler_remote_write_config,omitempty" json:"ruler_remote_write_config,omitempty" doc:"description=Configures global and per-tenant limits for remote write clients. A map with remote client id as key. The clients not configured globally are added for the tenant."`
 
 	// TODO(dannyk): possible enhancement is to align this with rule group interval
 	RulerRemoteEvaluationTimeout         time.Duration `yaml:"ruler_remote_evaluation_timeout" json:"ruler_remote_evaluation_timeout" doc:"description=Timeout for a remote rule evaluation. Defaults to the value of 'querier.query-timeout'."`
@@ -160,6 +221,9 @@ type Limits struct {
 	RetentionPeriod model.Duration    `yaml:"retention_period" json:"retention_period"`
 	StreamRetention []StreamRetention `yaml:"retention_stream,omitempty" json:"retention_stream,omitempty" doc:"description=Per-stream retention to apply, if the retention is enable on the compactor side.\nExample:\n retention_stream:\n - selector: '{namespace=\"dev\"}'\n priority: 1\n period: 24h\n- selector: '{container=\"nginx\"}'\n priority: 1\n period: 744h\nSelector is a Prometheus labels matchers that will apply the 'period' retention only if the stream is matching. In case multiple stream are matching, the highest priority will be picked. If no rule is matched the 'retention_period' is used."`
 
+	// Enforce the retention before the compactor applies it.
+	EnforceRetention bool `yaml:"enforce_retention" json:"enforce_retention"`
+
 	// Config for overrides, convenient if it goes here.
 	PerTenantOverrideConfig string         `yaml:"per_tenant_override_config" json:"per_tenant_override_config"`
 	PerTenantOverridePeriod model.Duration `yaml:"per_tenant_override_period" json:"per_tenant_override_period"`
@@ -182,6 +246,131 @@ type StreamRetention struct {
 	Matchers []*labels.Matcher `yaml:"-" json:"-"` // populated during validation.
 }
 
+// DropRule discards the entries of matching streams in the distributor.
+type DropRule struct {
+	Name      string            `yaml:"name" json:"name"`
+	Selector  string            `yaml:"selector" json:"selector"`
+	LineRegex string            `yaml:"line_regex,omitempty" json:"line_regex,omitempty"`
+	Matchers  []*labels.Matcher `yaml:"-" json:"-"` // populated during validation.
+	Regex     *regexp.Regexp    `yaml:"-" json:"-"` // populated during validation, nil if LineRegex is empty.
+}
+
+// Matches returns whether the entry of a stream with the given labels must be dropped.
+func (r *DropRule) Matches(lbs labels.Labels, line string) bool {
+	for _, m := range r.Matchers {
+		if !m.Matches(lbs.Get(m.Name)) {
+			return false
+		}
+	}
+	return r.Regex == nil || r.Regex.MatchString(line)
+}
+
+// SamplingRule keeps only a fraction of the entries of matching streams in the distributor.
+type SamplingRule struct {
+	Selector string            `yaml:"selector" json:"selector"`
+	Rate     float64           `yaml:"rate" json:"rate"`
+	Matchers []*labels.Matcher `yaml:"-" json:"-"` // populated during validation.
+}
+
+// Matches returns whether the rule applies to a stream with the given labels.
+func (r *SamplingRule) Matches(lbs labels.Labels) bool {
+	for _, m := range r.Matchers {
+		if !m.Matches(lbs.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
+// IngestionPipeline processes the entries of matching streams in the distributor.
+type IngestionPipeline struct {
+	Selector        string   `yaml:"selector" json:"selector"`
+	Stages          string   `yaml:"stages" json:"stages"`
+	Labels          []string `yaml:"labels,omitempty" json:"labels,omitempty"`
+	TimestampLabel  string   `yaml:"timestamp_label,omitempty" json:"timestamp_label,omitempty"`
+	TimestampFormat string   `yaml:"timestamp_format,omitempty" json:"timestamp_format,omitempty"`
+
+	Matchers     []*labels.Matcher     `yaml:"-" json:"-"` // populated during validation.
+	PipelineExpr syntax.MultiStageExpr `yaml:"-" json:"-"` // populated during validation.
+
+	// pipelines are the pipelines built from PipelineExpr, populated during validation.
+	// The pipelines are reused across pushes, but they can't be used concurrently,
+	// so every push takes its own pipeline out of the pool. The pipelines of the streams
+	// are reset when a pipeline is put back, so they don't pile up with the streams of the tenant.
+	pipelines *sync.Pool
+}
+
+// ForStream returns the pipeline of a stream with the given labels, and the function releasing it
+// once the entries of the stream are processed.
+func (p *IngestionPipeline) ForStream(lbs labels.Labels) (log.StreamPipeline, func(), error) {
+	if p.pipelines == nil {
+		return nil, nil, errors.New("ingestion pipeline is not validated")
+	}
+	switch pipeline := p.pipelines.Get().(type) {
+	case log.Pipeline:
+		return pipeline.ForStream(lbs), func() {
+			pipeline.Reset()
+			p.pipelines.Put(pipeline)
+		}, nil
+	case error:
+		return nil, nil, pipeline
+	default:
+		return nil, nil, fmt.Errorf("unexpected ingestion pipeline %T", pipeline)
+	}
+}
+
+// Matches returns whether the pipeline applies to a stream with the given labels.
+func (p *IngestionPipeline) Matches(lbs labels.Labels) bool {
+	for _, m := range p.Matchers {
+		if !m.Matches(lbs.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *IngestionPipeline) validate() error {
+	expr, err := syntax.ParseLogSelector(p.Selector+" "+p.Stages, true)
+	if err != nil {
+		return err
+	}
+	pipeline, ok := expr.(*syntax.PipelineExpr)
+	if !ok {
+		return errors.New("no stages")
+	}
+	for _, stage := range pipeline.MultiStages {
+		switch s := stage.(type) {
+		case *syntax.LabelParserExpr:
+			if s.Op != syntax.OpParserTypeJSON && s.Op != syntax.OpParserTypeLogfmt {
+				return fmt.Errorf("unsupported stage %q", stage.String())
+			}
+		case *syntax.JSONExpressionParser, *syntax.LogfmtExpressionParser, *syntax.LineFmtExpr, *syntax.LabelFmtExpr:
+		default:
+			return fmt.Errorf("unsupported stage %q", stage.String())
+		}
+	}
+	if p.TimestampLabel == "" && p.TimestampFormat != "" {
+		return errors.New("timestamp_format requires timestamp_label")
+	}
+	built, err := pipeline.MultiStages.Pipeline()
+	if err != nil {
+		return err
+	}
+	p.Matchers = pipeline.Matchers()
+	p.PipelineExpr = pipeline.MultiStages
+	p.pipelines = &sync.Pool{
+		New: func() interface{} {
+			built, err := p.PipelineExpr.Pipeline()
+			if err != nil {
+				return err
+			}
+			return built
+		},
+	}
+	p.pipelines.Put(built)
+	return nil
+}
+
 // LimitError are errors that do not comply with the limits specified.
 type LimitError string
 
@@ -196,12 +385,23 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.Float64Var(&l.IngestionBurstSizeMB, "distributor.ingestion-burst-size-mb", 6, "Per-user allowed ingestion burst size (in sample size). Units in MB. The burst size refers to the per-distributor local rate limiter even in the case of the 'global' strategy, and should be set at least to the maximum logs size expected in a single push request.")
 	f.Var(&l.MaxLineSize, "distributor.max-line-size", "Maximum line size on ingestion path. Example: 256kb. Any log line exceeding this limit will be discarded unless `distributor.max-line-size-truncate` is set which in case it is truncated instead of discarding it completely. There is no limit when unset or set to 0.")
 	f.BoolVar(&l.MaxLineSizeTruncate, "distributor.max-line-size-truncate", false, "Whether to truncate lines that exceed max_line_size.")
+	f.StringVar(&l.MaxLineSizeTruncateMarker, "distributor.max-line-size-truncate-marker", "...", "Marker appended to lines truncated because they exceed max_line_size. The truncated line including the marker does not exceed max_line_size.")
+	f.Var(&l.DistributorPerStreamRateLimit, "distributor.per-stream-rate-limit", "Maximum byte rate per second per stream enforced by the distributors, also expressible in human readable forms (1MB, 256KB, etc). With the global ingestion rate strategy the limit is shared across all distributors. The limit applies to each shard of the streams sharded by the distributors. Pushes of a stream exceeding the limit are discarded with the reason per_stream_rate_limit. 0 to disable.")
+	f.Var(&l.DistributorPerStreamRateLimitBurst, "distributor.per-stream-rate-limit-burst", "Maximum burst bytes per stream enforced by the distributors, also expressible in human readable forms (1MB, 256KB, etc). Defaults to the per-stream rate limit if 0. Pushes of a stream larger than the burst can never be accepted and are rejected as invalid.")
 	f.IntVar(&l.MaxLabelNameLength, "validation.max-length-label-name", 1024, "Maximum length accepted for label names.")
 	f.IntVar(&l.MaxLabelValueLength, "validation.max-length-label-value", 2048, "Maximum length accepted for label value. This setting also applies to the metric name.")
 	f.IntVar(&l.MaxLabelNamesPerSeries, "validation.max-label-names-per-series", 30, "Maximum number of label names per series.")
 	f.BoolVar(&l.RejectOldSamples, "validation.reject-old-samples", true, "Whether or not old samples will be rejected.")
 	f.BoolVar(&l.IncrementDuplicateTimestamp, "validation.increment-duplicate-timestamps", false, "Alter the log line timestamp during ingestion when the timestamp is the same as the previous entry for the same stream. When enabled, if a log line in a push request has the same timestamp as the previous line for the same stream, one nanosecond is added to the log line. This will preserve the received order of log lines with the exact same timestamp when they are queried, by slightly altering their stored timestamp. NOTE: This is imperfect, because Loki accepts out of order writes, and another push request for the same stream could contain duplicate timestamps to existing entries and they will not be incremented.")
 
+	f.BoolVar(&l.AcceptHASamples, "distributor.ha-tracker.enable-for-all-users", false, "Deduplicate pushes of redundant pairs of agents with the HA tracker. Requires the HA tracker to be enabled.")
+	f.StringVar(&l.HAClusterLabel, "distributor.ha-tracker.cluster", "cluster", "Label identifying the cluster of a pair of redundant agents.")
+	f.StringVar(&l.HAReplicaLabel, "distributor.ha-tracker.replica", "__replica__", "Label identifying the replica within a pair of redundant agents. The label is removed from the streams of the elected replica.")
+	f.BoolVar(&l.AllowStructuredMetadata, "validation.allow-structured-metadata", false, "Allow user to send structured metadata in push payload.")
+	_ = l.MaxStructuredMetadataSize.Set(defaultMaxStructuredMetadataSize)
+	f.Var(&l.MaxStructuredMetadataSize, "limits.max-structured-metadata-size", "Maximum size accepted for structured metadata per log line. When set to 0, the structured metadata is limited to the max line size instead.")
+	f.IntVar(&l.MaxStructuredMetadataEntriesCount, "limits.max-structured-metadata-entries-count", defaultMaxStructuredMetadataCount, "Maximum number of structured metadata entries per log line.")
+
 	_ = l.RejectOldSamplesMaxAge.Set("7d")
 	f.Var(&l.RejectOldSamplesMaxAge, "validation.reject-old-samples.max-age", "Maximum accepted sample age before rejecting.")
 	_ = l.CreationGracePeriod.Set("10m")
@@ -211,7 +411,14 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 
 	f.IntVar(&l.MaxLocalStreamsPerUser, "ingester.max-streams-per-user", 0, "Maximum number of active streams per user, per ingester. 0 to disable.")
 	f.IntVar(&l.MaxGlobalStreamsPerUser, "ingester.max-global-streams-per-user", 5000, "Maximum number of active streams per user, across the cluster. 0 to disable. When the global limit is enabled, each ingester is configured with a dynamic local limit based on the replication factor and the current number of healthy ingesters, and is kept updated whenever the number of ingesters change.")
+	f.BoolVar(&l.EvictStreamsOnLimit, "ingester.evict-streams-on-limit", false, "Evict the least recently written streams of the tenant to make room for new streams when the streams limit is reached, instead of rejecting the new streams. The chunks of evicted streams are flushed, and the streams are closed once they are flushed, unless they are written to again in the meantime.")
+	f.IntVar(&l.MaxTailersPerUser, "ingester.max-tailers-per-user", 0, "Maximum number of concurrent tail requests per user, per ingester. 0 to disable.")
 	f.BoolVar(&l.UnorderedWrites, "ingester.unordered-writes", true, "When true, out-of-order writes are accepted.")
+	f.Var(&l.OutOfOrderTimeWindow, "ingester.out-of-order-time-window", "Maximum age of out-of-order entries relative to the newest entry of their stream, when unordered writes are enabled. Older entries are rejected as too far behind. Windows larger than half of the ingester max_chunk_age cause chunks to be flushed before they are full. 0 to use half of max_chunk_age.")
+	f.Var(&l.DuplicateEntriesWindow, "ingester.duplicate-entries-window", "Window during which entries with the same timestamp, line and structured metadata as an entry previously pushed to their stream, or earlier in the same push, are suppressed, e.g. when clients retry pushes. The ingesters remember the entries of the window, so their memory usage grows with the window. 0 to only suppress duplicates of the last entry of a stream.")
+	f.Var(&l.ChunkTargetSize, "ingester.tenant-chunk-target-size", "Target _compressed_ size of the chunks of the tenant, overriding the ingester chunk_target_size. 0 to use the ingester chunk_target_size.")
+	f.Var(&l.ChunkIdlePeriod, "ingester.tenant-chunk-idle-period", "How long the chunks of the tenant sit in-memory with no updates before being flushed, overriding the ingester chunk_idle_period. 0 to use the ingester chunk_idle_period.")
+	f.Var(&l.MaxChunkAge, "ingester.tenant-max-chunk-age", "Maximum duration of the chunks of the tenant in memory, overriding the ingester max_chunk_age. 0 to use the ingester max_chunk_age.")
 
 	_ = l.PerStreamRateLimit.Set(strconv.Itoa(defaultPerStreamRateLimit))
 	f.Var(&l.PerStreamRateLimit, "ingester.per-stream-rate-limit", "Maximum byte rate per second per stream, also expressible in human readable forms (1MB, 256KB, etc).")
@@ -233,6 +440,7 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&l.MaxQueryParallelism, "querier.max-query-parallelism", 32, "Maximum number of queries that will be scheduled in parallel by the frontend.")
 	f.IntVar(&l.TSDBMaxQueryParallelism, "querier.tsdb-max-query-parallelism", 512, "Maximum number of queries will be scheduled in parallel by the frontend for TSDB schemas.")
 	f.IntVar(&l.CardinalityLimit, "store.cardinality-limit", 1e5, "Cardinality limit for index queries.")
+	f.IntVar(&l.MaxParallelGetChunk, "store.max-parallel-get-chunk-per-tenant", 0, "Maximum number of parallel chunk downloads of the tenant from each object store, across all its queries. Only enforced if -store.adaptive-parallel-get-chunk.enabled is true. 0 to disable.")
 	f.IntVar(&l.MaxStreamsMatchersPerQuery, "querier.max-streams-matcher-per-query", 1000, "Maximum number of stream matchers per query.")
 	f.IntVar(&l.MaxConcurrentTailRequests, "querier.max-concurrent-tail-requests", 10, "Maximum number of concurrent tail requests.")
 
@@ -247,6 +455,13 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 
 	f.Var(&l.MaxStatsCacheFreshness, "frontend.max-stats-cache-freshness", "Do not cache requests with an end time that falls within Now minus this duration. 0 disables this feature (default).")
 
+	_ = l.RecentStatsCacheTTL.Set("1m")
+	f.Var(&l.RecentStatsCacheTTL, "frontend.recent-stats-cache-ttl", "Cache the index stats of the requests with an end time that falls within -frontend.max-stats-cache-freshness for this duration, when the index stats results are cached. The requests are keyed by their matchers and their start and end truncated to this duration. 0 disables this feature.")
+
+	_ = l.MaxMetadataCacheFreshness.Set("24h")
+	f.Var(&l.MaxMetadataCacheFreshness, "frontend.max-metadata-cache-freshness", "Do not cache series and label requests with an end time that falls within Now minus this duration, as the series and labels of recent data still change. 0 disables this feature.")
+	f.Var(&l.MetadataCacheTTL, "frontend.metadata-cache-ttl", "Time to live of the cached series and label results. The results are cached under the current interval of this duration, so they are queried again once it elapsed. 0 to keep the results until the cache evicts them.")
+
 	f.IntVar(&l.MaxQueriersPerTenant, "frontend.max-queriers-per-tenant", 0, "Maximum number of queriers that can handle requests for a single tenant. If set to 0 or value higher than number of available queriers, *all* queriers will handle requests for the tenant. Each frontend (or query-scheduler, if used) will select the same set of queriers for the same tenant (given that all queriers are connected to all frontends / query-schedulers). This option only works with queriers connecting to the query-frontend / query-scheduler, not when using downstream URL.")
 	f.IntVar(&l.QueryReadyIndexNumDays, "store.query-ready-index-num-days", 0, "Number of days of index to be kept always downloaded for queries. Applies only to per user index in boltdb-shipper index store. 0 to disable.")
 
@@ -256,10 +471,14 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&l.RulerMaxRulesPerRuleGroup, "ruler.max-rules-per-rule-group", 0, "Maximum number of rules per rule group per-tenant. 0 to disable.")
 	f.IntVar(&l.RulerMaxRuleGroupsPerTenant, "ruler.max-rule-groups-per-tenant", 0, "Maximum number of rule groups per-tenant. 0 to disable.")
 	f.IntVar(&l.RulerTenantShardSize, "ruler.tenant-shard-size", 0, "The default tenant's shard size when shuffle-sharding is enabled in the ruler. When this setting is specified in the per-tenant overrides, a value of 0 disables shuffle sharding for the tenant.")
+	_ = l.RulerMinEvaluationInterval.Set("0s")
+	f.Var(&l.RulerMinEvaluationInterval, "ruler.min-evaluation-interval", "Minimum evaluation interval of the rule groups per-tenant. The rules API rejects the rule groups evaluated more frequently. 0 to disable.")
+	f.IntVar(&l.RulerMaxConcurrentEvaluations, "ruler.max-concurrent-evaluations", 0, "Maximum number of rules evaluated concurrently per-tenant, per ruler. The evaluations exceeding the limit wait for a running evaluation to complete. 0 to disable.")
 
 	f.StringVar(&l.PerTenantOverrideConfig, "limits.per-user-override-config", "", "Feature renamed to 'runtime configuration', flag deprecated in favor of -runtime-config.file (runtime_config.file in YAML).")
 	_ = l.RetentionPeriod.Set("0s")
 	f.Var(&l.RetentionPeriod, "store.retention", "Retention period to apply to stored data, only applies if retention_enabled is true in the compactor config. As of version 2.8.0, a zero value of 0 or 0s disables retention. In previous releases, Loki did not properly honor a zero value to disable retention and a really large value should be used instead.")
+	f.BoolVar(&l.EnforceRetention, "store.enforce-retention", false, "Enforce the retention of the tenant before the compactor applies it. The ingesters discard the chunks beyond the retention of their stream instead of flushing them, and queries are limited to the longest of the retention_period and the retention_stream periods, like with max_query_lookback.")
 
 	_ = l.PerTenantOverridePeriod.Set("10s")
 	f.Var(&l.PerTenantOverridePeriod, "limits.per-user-override-period", "Feature renamed to 'runtime configuration'; flag deprecated in favor of -runtime-config.reload-period (runtime_config.period in YAML).")
@@ -267,6 +486,18 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	_ = l.QuerySplitDuration.Set("30m")
 	f.Var(&l.QuerySplitDuration, "querier.split-queries-by-interval", "Split queries by a time interval and execute in parallel. The value 0 disables splitting by time. This also determines how cache keys are chosen when result caching is enabled.")
 
+	f.Var(&l.InstantMetricQuerySplitDuration, "querier.split-instant-metric-queries-by-interval", "Split instant metric queries with a range selector longer than this interval into sub-range queries executed in parallel and merged, for the range and vector aggregations which can be merged. When set to 0, the instant metric queries are split by the split_queries_by_interval instead.")
+
+	_ = l.MetadataQuerySplitDuration.Set("24h")
+	f.Var(&l.MetadataQuerySplitDuration, "querier.split-metadata-queries-by-interval", "Split series and label queries by a time interval and execute in parallel. The default of 24h matches the daily index tables. The value 0 disables splitting series and label queries by time. This also determines how cache keys are chosen when series and label results caching is enabled.")
+
+	f.BoolVar(&l.AlignQueriesWithStep, "frontend.align-queries-with-step", false, "Align the start and end of the metric range queries of the tenant with their step, so the repeated queries of dashboards hit the results cache. The responses to the aligned queries have the X-Loki-Query-Step-Aligned header. -querier.align-querier-with-step aligns the queries of all tenants.")
+	f.BoolVar(&l.QuantileSketchSharding, "frontend.quantile-sketch-sharding", false, "Shard and split the quantile_over_time queries of the tenant by merging the quantile sketches of the unwrapped values computed by the shards and the splits. The merged quantiles are within 1% of the exact quantiles.")
+	f.IntVar(&l.QueryMaxRetries, "frontend.query-max-retries", 0, "Maximum number of times the query frontend tries a request of the tenant. The value 0 uses -querier.max-retries-per-request. Only applies when -querier.max-retries-per-request is greater than 0.")
+	f.Var(&l.QueryRetryMinBackoff, "frontend.query-retry-min-backoff", "Delay before the first retry of a failed request of the tenant, doubled on each further retry up to -frontend.query-retry-max-backoff. The value 0 retries immediately.")
+	_ = l.QueryRetryMaxBackoff.Set("5s")
+	f.Var(&l.QueryRetryMaxBackoff, "frontend.query-retry-max-backoff", "Maximum delay before retrying a failed request of the tenant.")
+
 	f.StringVar(&l.DeletionMode, "compactor.deletion-mode", "filter-and-delete", "Deletion mode. Can be one of 'disabled', 'filter-only', or 'filter-and-delete'. When set to 'filter-only' or 'filter-and-delete', and if retention_enabled is true, then the log entry deletion API endpoints are available.")
 
 	// Deprecated
@@ -312,6 +543,71 @@ func (l *Limits) Validate() error {
 		}
 	}
 
+	if l.IngestionRelabelConfigs != nil {
+		configs, err := util.ParseRelabelConfigs(l.IngestionRelabelConfigs)
+		if err != nil {
+			return fmt.Errorf("invalid ingestion relabel configs: %w", err)
+		}
+		l.ParsedIngestionRelabelConfigs = configs
+	}
+
+	if l.QueryMaxRetries < 0 {
+		return fmt.Errorf("query_max_retries must be >= 0, was %d", l.QueryMaxRetries)
+	}
+	for _, code := range l.QueryRetryStatusCodes {
+		if code < 100 || code > 599 {
+			return fmt.Errorf("query_retry_status_codes: invalid HTTP status code %d", code)
+		}
+	}
+
+	names := make(map[string]struct{}, len(l.DropRules))
+	for i, rule := range l.DropRules {
+		if rule.Name == "" {
+			return fmt.Errorf("drop rule %d: name must not be empty", i)
+		}
+		if _, ok := names[rule.Name]; ok {
+			return fmt.Errorf("drop rule %q: name must be unique", rule.Name)
+		}
+		names[rule.Name] = struct{}{}
+
+		matchers, err := syntax.ParseMatchers(rule.Selector)
+		if err != nil {
+			return fmt.Errorf("drop rule %q: invalid labels matchers: %w", rule.Name, err)
+		}
+		l.DropRules[i].Matchers = matchers
+
+		if rule.LineRegex != "" {
+			re, err := regexp.Compile(rule.LineRegex)
+			if err != nil {
+				return fmt.Errorf("drop rule %q: invalid line regex: %w", rule.Name, err)
+			}
+			l.DropRules[i].Regex = re
+		}
+	}
+
+	for i, rule := range l.SamplingRules {
+		matchers, err := syntax.ParseMatchers(rule.Selector)
+		if err != nil {
+			return fmt.Errorf("sampling rule %d: invalid labels matchers: %w", i, err)
+		}
+		if rule.Rate < 0 || rule.Rate > 1 {
+			return fmt.Errorf("sampling rule %d: rate must be between 0 and 1, was %v", i, rule.Rate)
+		}
+		l.SamplingRules[i].Matchers = matchers
+	}
+
+	for i := range l.IngestionPipelines {
+		if err := l.IngestionPipelines[i].validate(); err != nil {
+			return fmt.Errorf("ingestion pipeline %d: %w", i, err)
+		}
+	}
+
+	if l.RulerExternalURL != "" {
+		if _, err := url.Parse(l.RulerExternalURL); err != nil {
+			return fmt.Errorf("invalid ruler_external_url: %w", err)
+		}
+	}
+
 	if _, err := deletionmode.ParseMode(l.DeletionMode); err != nil {
 		return err
 	}
@@ -429,6 +725,12 @@ func (o *Overrides) MaxGlobalStreamsPerUser(userID string) int {
 	return o.getOverridesForUser(userID).MaxGlobalStreamsPerUser
 }
 
+// EvictStreamsOnLimit returns whether the least recently written streams of a user are evicted
+// when the streams limit is reached.
+func (o *Overrides) EvictStreamsOnLimit(userID string) bool {
+	return o.getOverridesForUser(userID).EvictStreamsOnLimit
+}
+
 // MaxChunksPerQuery returns the maximum number of chunks allowed per query.
 func (o *Overrides) MaxChunksPerQuery(userID string) int {
 	return o.getOverridesForUser(userID).MaxChunksPerQuery
@@ -485,6 +787,11 @@ func (o *Overrides) CardinalityLimit(userID string) int {
 	return o.getOverridesForUser(userID).CardinalityLimit
 }
 
+// MaxParallelGetChunk returns the limit to the number of parallel chunk downloads of the tenant from each object store.
+func (o *Overrides) MaxParallelGetChunk(userID string) int {
+	return o.getOverridesForUser(userID).MaxParallelGetChunk
+}
+
 // MaxStreamsMatchersPerQuery returns the limit to number of streams matchers per query.
 func (o *Overrides) MaxStreamsMatchersPerQuery(_ context.Context, userID string) int {
 	return o.getOverridesForUser(userID).MaxStreamsMatchersPerQuery
@@ -500,6 +807,16 @@ func (o *Overrides) QuerySplitDuration(userID string) time.Duration {
 	return time.Duration(o.getOverridesForUser(userID).QuerySplitDuration)
 }
 
+// InstantMetricQuerySplitDuration returns the tenant specific interval instant metric queries are split by in the query frontend.
+func (o *Overrides) InstantMetricQuerySplitDuration(userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).InstantMetricQuerySplitDuration)
+}
+
+// MetadataQuerySplitDuration returns the tenant specific interval series and label queries are split by in the query frontend.
+func (o *Overrides) MetadataQuerySplitDuration(userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).MetadataQuerySplitDuration)
+}
+
 // MaxQueryBytesRead returns the maximum bytes a query can read.
 func (o *Overrides) MaxQueryBytesRead(_ context.Context, userID string) int {
 	return o.getOverridesForUser(userID).MaxQueryBytesRead.Val()
@@ -525,6 +842,61 @@ func (o *Overrides) MaxLineSizeTruncate(userID string) bool {
 	return o.getOverridesForUser(userID).MaxLineSizeTruncate
 }
 
+// MaxLineSizeTruncateMarker returns the marker appended to truncated lines.
+func (o *Overrides) MaxLineSizeTruncateMarker(userID string) string {
+	return o.getOverridesForUser(userID).MaxLineSizeTruncateMarker
+}
+
+// AllowStructuredMetadata returns whether structured metadata is accepted for the given tenant.
+func (o *Overrides) AllowStructuredMetadata(userID string) bool {
+	return o.getOverridesForUser(userID).AllowStructuredMetadata
+}
+
+// MaxStructuredMetadataSize returns the maximum size in bytes of the structured metadata of a log line.
+func (o *Overrides) MaxStructuredMetadataSize(userID string) int {
+	return o.getOverridesForUser(userID).MaxStructuredMetadataSize.Val()
+}
+
+// MaxStructuredMetadataCount returns the maximum number of structured metadata entries of a log line.
+func (o *Overrides) MaxStructuredMetadataCount(userID string) int {
+	return o.getOverridesForUser(userID).MaxStructuredMetadataEntriesCount
+}
+
+// AcceptHASamples returns whether the pushes of redundant pairs of agents are deduplicated for the given tenant.
+func (o *Overrides) AcceptHASamples(userID string) bool {
+	return o.getOverridesForUser(userID).AcceptHASamples
+}
+
+// HAClusterLabel returns the label identifying the cluster of a pair of redundant agents for the given tenant.
+func (o *Overrides) HAClusterLabel(userID string) string {
+	return o.getOverridesForUser(userID).HAClusterLabel
+}
+
+// HAReplicaLabel returns the label identifying the replica within a pair of redundant agents for the given tenant.
+func (o *Overrides) HAReplicaLabel(userID string) string {
+	return o.getOverridesForUser(userID).HAReplicaLabel
+}
+
+// IngestionRelabelConfigs returns the relabel configs applied to the labels of incoming streams for the given tenant.
+func (o *Overrides) IngestionRelabelConfigs(userID string) []*relabel.Config {
+	return o.getOverridesForUser(userID).ParsedIngestionRelabelConfigs
+}
+
+// DropRules returns the rules to discard log entries in the distributor for the given tenant.
+func (o *Overrides) DropRules(userID string) []DropRule {
+	return o.getOverridesForUser(userID).DropRules
+}
+
+// SamplingRules returns the rules to sample log entries in the distributor for the given tenant.
+func (o *Overrides) SamplingRules(userID string) []SamplingRule {
+	return o.getOverridesForUser(userID).SamplingRules
+}
+
+// IngestionPipelines returns the pipelines processing log entries in the distributor for the given tenant.
+func (o *Overrides) IngestionPipelines(userID string) []IngestionPipeline {
+	return o.getOverridesForUser(userID).IngestionPipelines
+}
+
 // MaxEntriesLimitPerQuery returns the limit to number of entries the querier should return per query.
 func (o *Overrides) MaxEntriesLimitPerQuery(_ context.Context, userID string) int {
 	return o.getOverridesForUser(userID).MaxEntriesLimitPerQuery
@@ -542,9 +914,72 @@ func (o *Overrides) MaxStatsCacheFreshness(_ context.Context, userID string) tim
 	return time.Duration(o.getOverridesForUser(userID).MaxStatsCacheFreshness)
 }
 
+func (o *Overrides) RecentStatsCacheTTL(_ context.Context, userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).RecentStatsCacheTTL)
+}
+
+func (o *Overrides) MaxMetadataCacheFreshness(_ context.Context, userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).MaxMetadataCacheFreshness)
+}
+
+// MetadataCacheTTL returns the time to live of the cached series and label results of a given user.
+func (o *Overrides) MetadataCacheTTL(_ context.Context, userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).MetadataCacheTTL)
+}
+
+// AlignQueriesWithStep returns whether the start and end of the metric queries of the tenant are aligned with their step.
+func (o *Overrides) AlignQueriesWithStep(_ context.Context, userID string) bool {
+	return o.getOverridesForUser(userID).AlignQueriesWithStep
+}
+
+// QuantileSketchSharding returns whether the quantile_over_time queries of the tenant are sharded with quantile sketches.
+func (o *Overrides) QuantileSketchSharding(_ context.Context, userID string) bool {
+	return o.getOverridesForUser(userID).QuantileSketchSharding
+}
+
+// QueryMaxRetries returns the maximum number of times the query frontend tries a request of the tenant.
+func (o *Overrides) QueryMaxRetries(_ context.Context, userID string) int {
+	return o.getOverridesForUser(userID).QueryMaxRetries
+}
+
+// QueryRetryMinBackoff returns the delay before the first retry of a failed request of the tenant.
+func (o *Overrides) QueryRetryMinBackoff(_ context.Context, userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).QueryRetryMinBackoff)
+}
+
+// QueryRetryMaxBackoff returns the maximum delay before retrying a failed request of the tenant.
+func (o *Overrides) QueryRetryMaxBackoff(_ context.Context, userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).QueryRetryMaxBackoff)
+}
+
+// QueryRetryStatusCodes returns the HTTP status codes of the failed requests of the tenant which are retried.
+func (o *Overrides) QueryRetryStatusCodes(_ context.Context, userID string) []int {
+	return o.getOverridesForUser(userID).QueryRetryStatusCodes
+}
+
 // MaxQueryLookback returns the max lookback period of queries.
+// It is capped by the longest retention period of the tenant and its streams when the retention is enforced.
 func (o *Overrides) MaxQueryLookback(_ context.Context, userID string) time.Duration {
-	return time.Duration(o.getOverridesForUser(userID).MaxQueryLookback)
+	limits := o.getOverridesForUser(userID)
+	lookback := time.Duration(limits.MaxQueryLookback)
+	if !limits.EnforceRetention {
+		return lookback
+	}
+
+	retention := time.Duration(limits.RetentionPeriod)
+	if retention <= 0 {
+		// the streams not matching a stream retention are retained forever.
+		return lookback
+	}
+	for _, s := range limits.StreamRetention {
+		if period := time.Duration(s.Period); period > retention {
+			retention = period
+		}
+	}
+	if lookback == 0 || retention < lookback {
+		return retention
+	}
+	return lookback
 }
 
 // EvaluationDelay returns the rules evaluation delay for a given user.
@@ -567,11 +1002,36 @@ func (o *Overrides) RulerMaxRuleGroupsPerTenant(userID string) int {
 	return o.getOverridesForUser(userID).RulerMaxRuleGroupsPerTenant
 }
 
+// RulerMinEvaluationInterval returns the minimum evaluation interval of the rule groups for a given user.
+func (o *Overrides) RulerMinEvaluationInterval(userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).RulerMinEvaluationInterval)
+}
+
+// RulerMaxConcurrentEvaluations returns the maximum number of rules evaluated concurrently for a given user.
+func (o *Overrides) RulerMaxConcurrentEvaluations(userID string) int {
+	return o.getOverridesForUser(userID).RulerMaxConcurrentEvaluations
+}
+
 // RulerAlertManagerConfig returns the alertmanager configurations to use for a given user.
 func (o *Overrides) RulerAlertManagerConfig(userID string) *ruler_config.AlertManagerConfig {
 	return o.getOverridesForUser(userID).RulerAlertManagerConfig
 }
 
+// RulerExternalLabels returns the external labels to add to the alerts and the recorded samples of a given user.
+func (o *Overrides) RulerExternalLabels(userID string) labels.Labels {
+	return o.getOverridesForUser(userID).RulerExternalLabels
+}
+
+// RulerExternalURL returns the external URL to use in the alerts of a given user.
+func (o *Overrides) RulerExternalURL(userID string) string {
+	return o.getOverridesForUser(userID).RulerExternalURL
+}
+
+// RulerAlertRelabelConfigs returns the alert relabel configs to apply to the alerts of a given user.
+func (o *Overrides) RulerAlertRelabelConfigs(userID string) []*util.RelabelConfig {
+	return o.getOverridesForUser(userID).RulerAlertRelabelConfigs
+}
+
 // RulerRemoteWriteDisabled returns whether remote-write is disabled for a given user or not.
 func (o *Overrides) RulerRemoteWriteDisabled(userID string) bool {
 	return o.getOverridesForUser(userID).RulerRemoteWriteDisabled
@@ -663,6 +1123,17 @@ func (o *Overrides) RulerRemoteWriteConfig(userID string, id string) *config.Rem
 	return nil
 }
 
+// RulerRemoteWriteClientIDs returns the sorted ids of the remote clients configured for a given user.
+func (o *Overrides) RulerRemoteWriteClientIDs(userID string) []string {
+	configs := o.getOverridesForUser(userID).RulerRemoteWriteConfig
+	ids := make([]string, 0, len(configs))
+	for id := range configs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
 // RulerRemoteEvaluationTimeout returns the duration after which to timeout a remote rule evaluation request for a given user.
 func (o *Overrides) RulerRemoteEvaluationTimeout(userID string) time.Duration {
 	// if not defined, use the base query timeout
@@ -689,10 +1160,41 @@ func (o *Overrides) StreamRetention(userID string) []StreamRetention {
 	return o.getOverridesForUser(userID).StreamRetention
 }
 
+// EnforceRetention returns whether the retention of a given user is enforced before the compactor applies it.
+func (o *Overrides) EnforceRetention(userID string) bool {
+	return o.getOverridesForUser(userID).EnforceRetention
+}
+
 func (o *Overrides) UnorderedWrites(userID string) bool {
 	return o.getOverridesForUser(userID).UnorderedWrites
 }
 
+func (o *Overrides) OutOfOrderTimeWindow(userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).OutOfOrderTimeWindow)
+}
+
+// DuplicateEntriesWindow returns the window during which the duplicate entries of a given user are suppressed.
+func (o *Overrides) DuplicateEntriesWindow(userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).DuplicateEntriesWindow)
+}
+
+// MaxTailersPerUser returns the maximum number of concurrent tail requests per user, per ingester.
+func (o *Overrides) MaxTailersPerUser(userID string) int {
+	return o.getOverridesForUser(userID).MaxTailersPerUser
+}
+
+func (o *Overrides) ChunkTargetSize(userID string) int {
+	return o.getOverridesForUser(userID).ChunkTargetSize.Val()
+}
+
+func (o *Overrides) ChunkIdlePeriod(userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).ChunkIdlePeriod)
+}
+
+func (o *Overrides) MaxChunkAge(userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).MaxChunkAge)
+}
+
 func (o *Overrides) DeletionMode(userID string) string {
 	return o.getOverridesForUser(userID).DeletionMode
 }
@@ -726,6 +1228,16 @@ func (o *Overrides) PerStreamRateLimit(userID string) RateLimit {
 	}
 }
 
+// DistributorPerStreamRateLimit returns the per-stream rate limit enforced by the distributors.
+func (o *Overrides) DistributorPerStreamRateLimit(userID string) RateLimit {
+	user := o.getOverridesForUser(userID)
+
+	return RateLimit{
+		Limit: rate.Limit(float64(user.DistributorPerStreamRateLimit.Val())),
+		Burst: user.DistributorPerStreamRateLimitBurst.Val(),
+	}
+}
+
 func (o *Overrides) IncrementDuplicateTimestamps(userID string) bool {
 	return o.getOverridesForUser(userID).IncrementDuplicateTimestamp
 }
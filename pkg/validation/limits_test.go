@@ -1,6 +1,7 @@
 package validation
 
 import (
+	"context"
 	"encoding/json"
 	"reflect"
 	"testing"
@@ -11,6 +12,7 @@ import (
 	"github.com/grafana/loki/pkg/storage/stores/indexshipper/compactor/deletionmode"
 
 	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v2"
@@ -301,3 +303,91 @@ func TestLimitsValidation(t *testing.T) {
 		require.True(t, errors.Is(limits.Validate(), tc.expected))
 	}
 }
+
+func TestMaxQueryLookbackEnforcedRetention(t *testing.T) {
+	for _, tc := range []struct {
+		lookback, retention time.Duration
+		streamRetention     []time.Duration
+		enforce             bool
+		expected            time.Duration
+	}{
+		{lookback: time.Hour, retention: 30 * time.Minute, expected: time.Hour},
+		{lookback: time.Hour, retention: 30 * time.Minute, enforce: true, expected: 30 * time.Minute},
+		{lookback: time.Hour, retention: 2 * time.Hour, enforce: true, expected: time.Hour},
+		{retention: 2 * time.Hour, enforce: true, expected: 2 * time.Hour},
+		{lookback: time.Hour, enforce: true, expected: time.Hour},
+		// the longest stream retention applies when it's longer than the tenant retention.
+		{retention: time.Hour, streamRetention: []time.Duration{30 * time.Minute, 3 * time.Hour}, enforce: true, expected: 3 * time.Hour},
+		{lookback: 2 * time.Hour, retention: time.Hour, streamRetention: []time.Duration{3 * time.Hour}, enforce: true, expected: 2 * time.Hour},
+		{retention: 2 * time.Hour, streamRetention: []time.Duration{time.Hour}, enforce: true, expected: 2 * time.Hour},
+		{streamRetention: []time.Duration{time.Hour}, enforce: true, expected: 0},
+	} {
+		limits := Limits{
+			MaxQueryLookback: model.Duration(tc.lookback),
+			RetentionPeriod:  model.Duration(tc.retention),
+			EnforceRetention: tc.enforce,
+		}
+		for _, period := range tc.streamRetention {
+			limits.StreamRetention = append(limits.StreamRetention, StreamRetention{Period: model.Duration(period)})
+		}
+		overrides, err := NewOverrides(limits, nil)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, overrides.MaxQueryLookback(context.Background(), "fake"))
+	}
+}
+
+func TestDropRulesValidation(t *testing.T) {
+	for _, tc := range []struct {
+		desc  string
+		rules []DropRule
+		err   bool
+	}{
+		{desc: "valid", rules: []DropRule{{Name: "a", Selector: `{app="foo"}`, LineRegex: "debug"}, {Name: "b", Selector: `{app="bar"}`}}},
+		{desc: "missing name", rules: []DropRule{{Selector: `{app="foo"}`}}, err: true},
+		{desc: "duplicate name", rules: []DropRule{{Name: "a", Selector: `{app="foo"}`}, {Name: "a", Selector: `{app="bar"}`}}, err: true},
+		{desc: "invalid selector", rules: []DropRule{{Name: "a", Selector: `{app=}`}}, err: true},
+		{desc: "invalid regex", rules: []DropRule{{Name: "a", Selector: `{app="foo"}`, LineRegex: "("}}, err: true},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			limits := Limits{DeletionMode: "disabled", DropRules: tc.rules}
+			err := limits.Validate()
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			for _, rule := range limits.DropRules {
+				require.NotEmpty(t, rule.Matchers)
+			}
+			require.True(t, limits.DropRules[0].Matches(labels.FromStrings("app", "foo"), "level=debug"))
+			require.False(t, limits.DropRules[0].Matches(labels.FromStrings("app", "foo"), "level=info"))
+			require.True(t, limits.DropRules[1].Matches(labels.FromStrings("app", "bar"), "level=info"))
+		})
+	}
+}
+
+func TestIngestionPipelinesValidation(t *testing.T) {
+	for _, tc := range []struct {
+		pipeline IngestionPipeline
+		err      bool
+	}{
+		{pipeline: IngestionPipeline{Selector: `{app="foo"}`, Stages: `| json | line_format "{{.msg}}"`}},
+		{pipeline: IngestionPipeline{Selector: `{app="foo"}`, Stages: `| logfmt | label_format level=lvl`}},
+		{pipeline: IngestionPipeline{Selector: `{app="foo"}`, Stages: `| json msg="message"`}},
+		{pipeline: IngestionPipeline{Selector: `{app="foo"}`}, err: true},
+		{pipeline: IngestionPipeline{Selector: `{app="foo"}`, Stages: `|= "foo"`}, err: true},
+		{pipeline: IngestionPipeline{Selector: `{app="foo"}`, Stages: `| regexp "(?P<msg>.*)"`}, err: true},
+		{pipeline: IngestionPipeline{Selector: `{app="foo"}`, Stages: `| json`, TimestampFormat: "Unix"}, err: true},
+	} {
+		t.Run(tc.pipeline.Stages, func(t *testing.T) {
+			limits := Limits{DeletionMode: "disabled", IngestionPipelines: []IngestionPipeline{tc.pipeline}}
+			err := limits.Validate()
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NotEmpty(t, limits.IngestionPipelines[0].PipelineExpr)
+		})
+	}
+}
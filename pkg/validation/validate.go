@@ -17,6 +17,10 @@ const (
 
 	MissingLabelsErrorMsg = "error at least one label pair is required per stream"
 	InvalidLabelsErrorMsg = "Error parsing labels '%s' with error: %s"
+	// RelabelDropped is a reason for discarding log lines of the streams dropped by the relabel configs of the tenant.
+	RelabelDropped = "relabel_dropped"
+	// Sampled is the reason for entries discarded by a sampling rule.
+	Sampled = "sampled"
 	// RateLimited is one of the values for the reason to discard samples.
 	// Declared here to avoid duplication in ingester and distributor.
 	RateLimited         = "rate_limited"
@@ -31,6 +35,8 @@ const (
 	// StreamRateLimit is a reason for discarding lines when the streams own rate limit is hit
 	// rather than the overall ingestion rate limit.
 	StreamRateLimit = "per_stream_rate_limit"
+	// StreamRateLimitBurstErrorMsg is the error of the pushes of a stream larger than the burst of its rate limit.
+	StreamRateLimitBurstErrorMsg = "Push of stream '%s' totaling %s exceeds the per stream rate limit burst of %s and can never be accepted, send smaller pushes or contact your Loki administrator to see if the burst can be increased"
 	// OutOfOrder is a reason for discarding lines when Loki doesn't accept out
 	// of order log lines (parameter `-ingester.unordered-writes` is set to
 	// `false`) and the lines in question are older than the newest line in the
@@ -60,6 +66,16 @@ const (
 	// DuplicateLabelNames is a reason for discarding a log line which has duplicate label names
 	DuplicateLabelNames         = "duplicate_label_names"
 	DuplicateLabelNamesErrorMsg = "stream '%s' has duplicate label name: '%s'"
+	// DisallowedStructuredMetadata is a reason for discarding a log line which has structured metadata
+	// while structured metadata is not allowed for the tenant.
+	DisallowedStructuredMetadata         = "disallowed_structured_metadata"
+	DisallowedStructuredMetadataErrorMsg = "stream '%s' includes structured metadata, but this feature is disallowed. Please see `limits_config.allow_structured_metadata` or contact your Loki administrator to enable it."
+	// StructuredMetadataTooLarge is a reason for discarding a log line which has structured metadata too large
+	StructuredMetadataTooLarge         = "structured_metadata_too_large"
+	StructuredMetadataTooLargeErrorMsg = "stream '%s' has structured metadata too large: '%d' bytes, limit: '%d' bytes. Please see `limits_config.max_structured_metadata_size` or contact your Loki administrator to increase it."
+	// StructuredMetadataTooMany is a reason for discarding a log line which has too many structured metadata entries
+	StructuredMetadataTooMany         = "structured_metadata_too_many"
+	StructuredMetadataTooManyErrorMsg = "stream '%s' has too many structured metadata labels: '%d', limit: '%d'. Please see `limits_config.max_structured_metadata_entries_count` or contact your Loki administrator to increase it."
 )
 
 type ErrStreamRateLimit struct {
@@ -0,0 +1,577 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"go.etcd.io/bbolt"
+
+	"github.com/grafana/loki/pkg/chunkenc"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/loki"
+	"github.com/grafana/loki/pkg/storage"
+	"github.com/grafana/loki/pkg/storage/chunk"
+	"github.com/grafana/loki/pkg/storage/chunk/client"
+	"github.com/grafana/loki/pkg/storage/chunk/client/local"
+	"github.com/grafana/loki/pkg/storage/chunk/client/util"
+	"github.com/grafana/loki/pkg/storage/config"
+	"github.com/grafana/loki/pkg/storage/stores/indexshipper/compactor/retention"
+	shipper_storage "github.com/grafana/loki/pkg/storage/stores/indexshipper/storage"
+	"github.com/grafana/loki/pkg/storage/stores/shipper/index/compactor"
+	shipper_util "github.com/grafana/loki/pkg/storage/stores/shipper/util"
+	"github.com/grafana/loki/pkg/storage/stores/tsdb"
+	tsdb_index "github.com/grafana/loki/pkg/storage/stores/tsdb/index"
+	"github.com/grafana/loki/pkg/util/cfg"
+	util_log "github.com/grafana/loki/pkg/util/log"
+)
+
+const (
+	gzipExtension  = ".gz"
+	tempFileSuffix = ".temp"
+
+	// fetchChunksBatchSize is the number of chunks fetched at a time for reading their size.
+	fetchChunksBatchSize = 100
+
+	// the size of the chunks guessed with GUESS_CHUNK_SIZES=true, like the tsdb-map tool does.
+	guessedChunkKB      = ((3 << 20) / 4) / 1024 // 0.75mb, 1/2 of the max size, rounded to KB
+	guessedChunkEntries = 10000
+)
+
+var (
+	tableNumMin, tableNumMax int64
+	newTablePrefix           string
+	// tenants are the tenants to migrate, all the tenants are migrated when empty.
+	tenants map[string]struct{}
+	// guessChunkSizes makes the chunks indexed with a guessed size instead of the size read from the chunks.
+	guessChunkSizes bool
+)
+
+func exit(code int) {
+	util_log.Flush()
+	os.Exit(code)
+}
+
+// Usage: NEW_TABLE_PREFIX=tsdb_index_ TABLE_NUM_MIN=19464 TABLE_NUM_MAX=19465 TENANTS=tenant1,tenant2 GUESS_CHUNK_SIZES=false go run tools/tsdb/migrate-index/main.go --config.file /tmp/loki-config.yaml
+//
+// It rewrites the boltdb-shipper index of the tables into per-tenant TSDB index files of the tables with the same number
+// and the NEW_TABLE_PREFIX prefix. The tenants already having a TSDB index file in the new table are skipped, so an
+// interrupted migration is resumed by running it again.
+//
+// The boltdb-shipper index does not record the size of the chunks, so every chunk is fetched from the object store for
+// reading its size and number of entries. With GUESS_CHUNK_SIZES=true the chunks are not fetched and every chunk is indexed
+// with a size of 0.75MB and 10000 entries instead. It makes the migration much faster, but TSDB uses the size of the chunks
+// for sharding the queries, so the queries of the migrated tables are sharded by a wrong estimation of their size.
+func main() {
+	lokiCfg := setup()
+	clientMetrics := storage.NewClientMetrics()
+
+	if got := os.Getenv("TABLE_NUM_MIN"); got != "" {
+		n, err := strconv.Atoi(got)
+		if err != nil {
+			log.Fatalf("invalid TABLE_NUM_MIN: %v", err)
+		}
+		tableNumMin = int64(n)
+	}
+
+	if got := os.Getenv("TABLE_NUM_MAX"); got != "" {
+		n, err := strconv.Atoi(got)
+		if err != nil {
+			log.Fatalf("invalid TABLE_NUM_MAX: %v", err)
+		}
+		tableNumMax = int64(n)
+	}
+
+	newTablePrefix = os.Getenv("NEW_TABLE_PREFIX")
+	if newTablePrefix == "" {
+		log.Fatalf("NEW_TABLE_PREFIX is required")
+	}
+
+	if got := os.Getenv("TENANTS"); got != "" {
+		tenants = map[string]struct{}{}
+		for _, tenant := range strings.Split(got, ",") {
+			tenants[strings.TrimSpace(tenant)] = struct{}{}
+		}
+	}
+
+	if got := os.Getenv("GUESS_CHUNK_SIZES"); got != "" {
+		var err error
+		guessChunkSizes, err = strconv.ParseBool(got)
+		if err != nil {
+			log.Fatalf("invalid GUESS_CHUNK_SIZES: %v", err)
+		}
+	}
+
+	var failed int
+	for i, cfg := range lokiCfg.SchemaConfig.Configs {
+		if cfg.IndexType != config.BoltDBShipperType {
+			continue
+		}
+
+		periodEndTime := config.DayTime{Time: math.MaxInt64}
+		if i < len(lokiCfg.SchemaConfig.Configs)-1 {
+			periodEndTime = config.DayTime{Time: lokiCfg.SchemaConfig.Configs[i+1].From.Time.Add(-time.Millisecond)}
+		}
+
+		tableRange := cfg.GetIndexTableNumberRange(periodEndTime)
+		if err := migrateTables(cfg, lokiCfg.StorageConfig, clientMetrics, tableRange); err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to migrate boltdb-shipper index to tsdb", "schema_start", cfg.From, "err", err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		// the other schema periods are still migrated, but the migration has to be retried.
+		log.Fatalf("failed to migrate the index of %d schema periods", failed)
+	}
+}
+
+func migrateTables(pCfg config.PeriodConfig, storageCfg storage.Config, clientMetrics storage.ClientMetrics, tableRange config.TableRange) error {
+	objClient, err := storage.NewObjectClient(pCfg.ObjectType, storageCfg, clientMetrics)
+	if err != nil {
+		return err
+	}
+
+	sourceClient := shipper_storage.NewIndexStorageClient(objClient, storageCfg.BoltDBShipperConfig.SharedStoreKeyPrefix)
+	destClient := shipper_storage.NewIndexStorageClient(objClient, storageCfg.TSDBShipperConfig.SharedStoreKeyPrefix)
+
+	var encoder client.KeyEncoder
+	if _, ok := objClient.(*local.FSObjectClient); ok {
+		encoder = client.FSEncoder
+	}
+	chunkClient := client.NewClient(objClient, encoder, config.SchemaConfig{Configs: []config.PeriodConfig{pCfg}})
+
+	tableNames, err := sourceClient.ListTables(context.Background())
+	if err != nil {
+		return err
+	}
+
+	for _, tableName := range tableNames {
+		if !strings.HasPrefix(tableName, pCfg.IndexTables.Prefix) {
+			continue
+		}
+		tableNum, err := config.ExtractTableNumberFromName(tableName)
+		if err != nil {
+			return err
+		}
+		if tableNumMin != 0 && tableNum < tableNumMin {
+			continue
+		}
+
+		if tableNumMax != 0 && tableNum > tableNumMax {
+			continue
+		}
+
+		tableInRange, err := tableRange.TableInRange(tableName)
+		if err != nil {
+			return err
+		}
+		if !tableInRange {
+			continue
+		}
+
+		if err := migrateTable(tableName, fmt.Sprintf("%s%d", newTablePrefix, tableNum), pCfg, sourceClient, destClient, chunkClient); err != nil {
+			return errors.Wrapf(err, "failed to migrate table %s", tableName)
+		}
+		level.Info(util_log.Logger).Log("msg", "successfully migrated", "table_name", tableName)
+	}
+
+	return nil
+}
+
+// indexFile is a boltdb-shipper index file of a table downloaded to the local disk.
+type indexFile struct {
+	path string
+	// user is the tenant of a per-tenant index file, empty for the index files common to all the tenants.
+	user string
+}
+
+func migrateTable(tableName, newTableName string, pCfg config.PeriodConfig, sourceClient, destClient shipper_storage.Client, chunkClient client.Client) error {
+	tempDir, err := os.MkdirTemp("", "migrate-index")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+
+	files, err := downloadTable(tableName, tempDir, sourceClient)
+	if err != nil {
+		return err
+	}
+
+	tenantIDs, err := listTenants(files, pCfg)
+	if err != nil {
+		return err
+	}
+
+	for _, tenant := range tenantIDs {
+		existingFiles, err := destClient.ListUserFiles(context.Background(), newTableName, tenant, true)
+		if err != nil {
+			return err
+		}
+		if len(existingFiles) != 0 {
+			level.Info(util_log.Logger).Log("msg", "skipping migration of tenant index which is already migrated", "table_name", tableName, "tenant", tenant)
+			continue
+		}
+
+		numChunks, err := migrateTenant(tenant, filepath.Join(tempDir, "tsdb", tenant), newTableName, files, pCfg, destClient, chunkClient)
+		if err != nil {
+			return err
+		}
+		level.Info(util_log.Logger).Log("msg", "migrated tenant index", "table_name", tableName, "new_table_name", newTableName, "tenant", tenant, "chunks", numChunks)
+	}
+
+	return nil
+}
+
+// migrateTenant builds the TSDB index of a tenant from the downloaded index files of the table and uploads it.
+// The index of every tenant is built separately, so only the chunks of a single tenant are held in memory at a time.
+func migrateTenant(tenant, tenantDir, newTableName string, files []indexFile, pCfg config.PeriodConfig, destClient shipper_storage.Client, chunkClient client.Client) (int, error) {
+	if err := util.EnsureDirectory(tenantDir); err != nil {
+		return 0, err
+	}
+	defer os.RemoveAll(tenantDir)
+
+	chunks, err := readTenantChunks(tenant, files, pCfg)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to read chunks of tenant %s", tenant)
+	}
+
+	builder := tsdb.NewBuilder()
+	if err := addChunks(builder, chunks, chunkClient); err != nil {
+		return 0, errors.Wrapf(err, "failed to read sizes of chunks of tenant %s", tenant)
+	}
+	builder.FinalizeChunks()
+
+	id, err := builder.Build(context.Background(), tenantDir, func(from, through model.Time, checksum uint32) tsdb.Identifier {
+		id := tsdb.SingleTenantTSDBIdentifier{
+			TS:       time.Now(),
+			From:     from,
+			Through:  through,
+			Checksum: checksum,
+		}
+		return tsdb.NewPrefixedIdentifier(id, tenantDir, "")
+	})
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to build tsdb index for tenant %s", tenant)
+	}
+
+	tsdbFile, err := tsdb.NewShippableTSDBFile(id)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err := tsdbFile.Close(); err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to close index file", "err", err)
+		}
+	}()
+
+	if err := verifyIndex(tsdbFile, len(chunks)); err != nil {
+		return 0, errors.Wrapf(err, "failed to verify tsdb index for tenant %s", tenant)
+	}
+
+	if err := uploadFile(tsdbFile, destClient, newTableName, tenant); err != nil {
+		return 0, errors.Wrapf(err, "failed to upload index file for tenant %s", tenant)
+	}
+
+	return len(chunks), nil
+}
+
+// downloadTable downloads the boltdb-shipper index files of the table common to all the tenants and the ones of the selected tenants.
+func downloadTable(tableName, tempDir string, sourceClient shipper_storage.Client) ([]indexFile, error) {
+	commonFiles, users, err := sourceClient.ListFiles(context.Background(), tableName, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []indexFile
+	for _, file := range commonFiles {
+		path, err := downloadFile(filepath.Join(tempDir, "boltdb"), file.Name, func() (io.ReadCloser, error) {
+			return sourceClient.GetFile(context.Background(), tableName, file.Name)
+		})
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, indexFile{path: path})
+	}
+
+	for _, user := range users {
+		if !tenantSelected(user) {
+			continue
+		}
+
+		userFiles, err := sourceClient.ListUserFiles(context.Background(), tableName, user, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range userFiles {
+			path, err := downloadFile(filepath.Join(tempDir, "boltdb", user), file.Name, func() (io.ReadCloser, error) {
+				return sourceClient.GetUserFile(context.Background(), tableName, user, file.Name)
+			})
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, indexFile{path: path, user: user})
+		}
+	}
+
+	return files, nil
+}
+
+func downloadFile(dir, fileName string, getFileFunc shipper_storage.GetFileFunc) (string, error) {
+	if err := util.EnsureDirectory(dir); err != nil {
+		return "", err
+	}
+
+	dst := filepath.Join(dir, fileName)
+	decompress := shipper_storage.IsCompressedFile(fileName)
+	if decompress {
+		dst = strings.TrimSuffix(dst, gzipExtension)
+	}
+	if err := shipper_storage.DownloadFileFromStorage(dst, decompress, true, shipper_storage.LoggerWithFilename(util_log.Logger, fileName), getFileFunc); err != nil {
+		return "", err
+	}
+	return dst, nil
+}
+
+// listTenants returns the sorted IDs of the selected tenants having chunks indexed in the index files.
+func listTenants(files []indexFile, pCfg config.PeriodConfig) ([]string, error) {
+	tenantIDs := map[string]struct{}{}
+	for _, file := range files {
+		if file.user != "" {
+			tenantIDs[file.user] = struct{}{}
+			continue
+		}
+
+		if err := forEachChunk(file, pCfg, func(entry retention.ChunkEntry) (bool, error) {
+			if tenantSelected(string(entry.UserID)) {
+				tenantIDs[string(entry.UserID)] = struct{}{}
+			}
+			return false, nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]string, 0, len(tenantIDs))
+	for tenant := range tenantIDs {
+		out = append(out, tenant)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// readTenantChunks reads the chunks of the tenant from the index files, the labels of the returned chunks are the TSDB labels of their series.
+func readTenantChunks(tenant string, files []indexFile, pCfg config.PeriodConfig) ([]chunk.Chunk, error) {
+	var (
+		chunks []chunk.Chunk
+		seen   = map[string]struct{}{}
+	)
+	for _, file := range files {
+		if file.user != "" && file.user != tenant {
+			continue
+		}
+
+		if err := forEachChunk(file, pCfg, func(entry retention.ChunkEntry) (bool, error) {
+			if string(entry.UserID) != tenant {
+				return false, nil
+			}
+			if _, ok := seen[string(entry.ChunkID)]; ok {
+				return false, nil
+			}
+			seen[string(entry.ChunkID)] = struct{}{}
+
+			chk, err := chunk.ParseExternalKey(tenant, string(entry.ChunkID))
+			if err != nil {
+				return false, err
+			}
+
+			// TSDB doesnt need the __name__="log" convention the old chunk store index used.
+			lb := labels.NewBuilder(entry.Labels)
+			lb.Del(labels.MetricName)
+			chk.Metric = lb.Labels()
+			chunks = append(chunks, chk)
+			return false, nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return chunks, nil
+}
+
+func forEachChunk(file indexFile, pCfg config.PeriodConfig, callback retention.ChunkEntryCallback) error {
+	db, err := shipper_util.SafeOpenBoltdbFile(file.path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to close index file", "path", file.path, "err", err)
+		}
+	}()
+
+	return db.View(func(tx *bbolt.Tx) error {
+		return tx.ForEach(func(_ []byte, b *bbolt.Bucket) error {
+			return compactor.ForEachChunk(context.Background(), b, pCfg, callback)
+		})
+	})
+}
+
+// addChunks adds the chunks to the TSDB index with their size read from the chunks, or guessed with GUESS_CHUNK_SIZES=true.
+func addChunks(builder *tsdb.Builder, chunks []chunk.Chunk, chunkClient client.Client) error {
+	for len(chunks) > 0 {
+		batch := chunks
+		if len(batch) > fetchChunksBatchSize {
+			batch = batch[:fetchChunksBatchSize]
+		}
+		chunks = chunks[len(batch):]
+
+		metas := make([]tsdb_index.ChunkMeta, len(batch))
+		for i, chk := range batch {
+			metas[i] = tsdb_index.ChunkMeta{
+				Checksum: chk.Checksum,
+				MinTime:  int64(chk.From),
+				MaxTime:  int64(chk.Through),
+				KB:       guessedChunkKB,
+				Entries:  guessedChunkEntries,
+			}
+		}
+
+		if !guessChunkSizes {
+			fetched, err := chunkClient.GetChunks(context.Background(), batch)
+			if err != nil {
+				return err
+			}
+
+			data := make(map[logproto.ChunkRef]chunk.Data, len(fetched))
+			for _, chk := range fetched {
+				data[chk.ChunkRef] = chk.Data
+			}
+			for i, chk := range batch {
+				d, ok := data[chk.ChunkRef]
+				if !ok {
+					return fmt.Errorf("chunk %s not fetched", chk.ChunkRef.String())
+				}
+				metas[i].KB = uint32(math.Round(float64(d.UncompressedSize()) / float64(1<<10)))
+				metas[i].Entries = uint32(d.Entries())
+			}
+		}
+
+		for i, chk := range batch {
+			builder.AddSeries(chk.Metric, model.Fingerprint(chk.Fingerprint), []tsdb_index.ChunkMeta{metas[i]})
+		}
+	}
+
+	return nil
+}
+
+func tenantSelected(tenant string) bool {
+	if len(tenants) == 0 {
+		return true
+	}
+	_, ok := tenants[tenant]
+	return ok
+}
+
+// verifyIndex verifies the TSDB index has all the chunks of the tenant read from the boltdb-shipper index.
+func verifyIndex(tsdbFile *tsdb.TSDBFile, expectedChunks int) error {
+	var chunks int
+	err := tsdbFile.Index.(*tsdb.TSDBIndex).ForSeries(context.Background(), nil, 0, math.MaxInt64, func(_ labels.Labels, _ model.Fingerprint, chks []tsdb_index.ChunkMeta) {
+		chunks += len(chks)
+	}, labels.MustNewMatcher(labels.MatchEqual, "", ""))
+	if err != nil {
+		return err
+	}
+
+	if chunks != expectedChunks {
+		return fmt.Errorf("tsdb index has %d chunks, expected %d", chunks, expectedChunks)
+	}
+	return nil
+}
+
+func uploadFile(idx *tsdb.TSDBFile, indexStorageClient shipper_storage.Client, tableName, tenant string) error {
+	fileName := idx.Name()
+	level.Debug(util_log.Logger).Log("msg", fmt.Sprintf("uploading index %s", fileName))
+
+	idxPath := idx.Path()
+
+	filePath := fmt.Sprintf("%s%s", idxPath, tempFileSuffix)
+	f, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := f.Close(); err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to close temp file", "path", filePath, "err", err)
+		}
+
+		if err := os.Remove(filePath); err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to remove temp file", "path", filePath, "err", err)
+		}
+	}()
+
+	compressedWriter := chunkenc.Gzip.GetWriter(f)
+	defer chunkenc.Gzip.PutWriter(compressedWriter)
+
+	idxReader, err := idx.Reader()
+	if err != nil {
+		return err
+	}
+
+	_, err = idxReader.Seek(0, 0)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(compressedWriter, idxReader)
+	if err != nil {
+		return err
+	}
+
+	err = compressedWriter.Close()
+	if err != nil {
+		return err
+	}
+
+	// flush the file to disk and seek the file to the beginning.
+	if err := f.Sync(); err != nil {
+		return err
+	}
+
+	if _, err := f.Seek(0, 0); err != nil {
+		return err
+	}
+
+	return indexStorageClient.PutUserFile(context.Background(), tableName, tenant, fmt.Sprintf("%s%s", idx.Name(), gzipExtension), f)
+}
+
+func setup() loki.Config {
+	var c loki.ConfigWrapper
+	if err := cfg.DynamicUnmarshal(&c, os.Args[1:], flag.CommandLine); err != nil {
+		fmt.Fprintf(os.Stderr, "failed parsing config: %v\n", err)
+		os.Exit(1)
+	}
+
+	util_log.InitLogger(&c.Server, prometheus.DefaultRegisterer, c.UseBufferedLogger, c.UseSyncLogger)
+
+	if err := c.Validate(); err != nil {
+		level.Error(util_log.Logger).Log("msg", "validating config", "err", err.Error())
+		exit(1)
+	}
+
+	return c.Config
+}
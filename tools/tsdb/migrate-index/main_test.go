@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/chunkenc"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/storage"
+	"github.com/grafana/loki/pkg/storage/chunk"
+	"github.com/grafana/loki/pkg/storage/chunk/client"
+	"github.com/grafana/loki/pkg/storage/chunk/client/local"
+	"github.com/grafana/loki/pkg/storage/config"
+	shipper_storage "github.com/grafana/loki/pkg/storage/stores/indexshipper/storage"
+	series_index "github.com/grafana/loki/pkg/storage/stores/series/index"
+	shipper_util "github.com/grafana/loki/pkg/storage/stores/shipper/util"
+	"github.com/grafana/loki/pkg/storage/stores/tsdb"
+	tsdb_index "github.com/grafana/loki/pkg/storage/stores/tsdb/index"
+	util_log "github.com/grafana/loki/pkg/util/log"
+)
+
+const (
+	indexPrefix = "index_"
+	tsdbPrefix  = "tsdb_index_"
+)
+
+func TestMigrateTables(t *testing.T) {
+	tempDir := t.TempDir()
+
+	now := model.Now()
+	pcfg := config.PeriodConfig{
+		From:       config.DayTime{Time: now.Add(-10 * 24 * time.Hour)},
+		IndexType:  config.BoltDBShipperType,
+		ObjectType: "filesystem",
+		Schema:     "v12",
+		RowShards:  16,
+		IndexTables: config.PeriodicTableConfig{
+			Prefix: indexPrefix,
+			Period: 24 * time.Hour,
+		},
+	}
+	schemaCfg := config.SchemaConfig{Configs: []config.PeriodConfig{pcfg}}
+
+	storageCfg := storage.Config{
+		FSConfig: local.FSConfig{
+			Directory: tempDir,
+		},
+	}
+	storageCfg.BoltDBShipperConfig.SharedStoreKeyPrefix = "index/"
+	storageCfg.TSDBShipperConfig.SharedStoreKeyPrefix = "tsdb-index/"
+	clientMetrics := storage.NewClientMetrics()
+
+	objClient, err := storage.NewObjectClient(pcfg.ObjectType, storageCfg, clientMetrics)
+	require.NoError(t, err)
+	chunkClient := client.NewClient(objClient, client.FSEncoder, schemaCfg)
+	sourceClient := shipper_storage.NewIndexStorageClient(objClient, storageCfg.BoltDBShipperConfig.SharedStoreKeyPrefix)
+	destClient := shipper_storage.NewIndexStorageClient(objClient, storageCfg.TSDBShipperConfig.SharedStoreKeyPrefix)
+
+	schema, err := series_index.CreateSchema(pcfg)
+	require.NoError(t, err)
+
+	tableName := pcfg.IndexTables.TableFor(now)
+	tableNum, err := config.ExtractTableNumberFromName(tableName)
+	require.NoError(t, err)
+
+	// setup a boltdb-shipper index file with the chunks of two tenants
+	batch := local.NewWriteBatch()
+	expectedChunks := map[string]int{"user1": 3, "user2": 2}
+	// the expected chunk metas of the tenants by checksum
+	expectedMetas := map[string]map[uint32]tsdb_index.ChunkMeta{}
+	for tenant, numChunks := range expectedChunks {
+		lbls := labels.Labels{{Name: labels.MetricName, Value: "logs"}, {Name: "tenant", Value: tenant}}
+		expectedMetas[tenant] = map[uint32]tsdb_index.ChunkMeta{}
+		for i := 0; i < numChunks; i++ {
+			from, through := now.Add(-time.Hour+time.Duration(i)*time.Minute), now.Add(time.Duration(i)*time.Minute)
+			chk := createChunk(t, tenant, lbls, from, through, 100*(i+1))
+			require.NoError(t, chunkClient.PutChunks(context.Background(), []chunk.Chunk{chk}))
+			expectedMetas[tenant][chk.Checksum] = tsdb_index.ChunkMeta{
+				Checksum: chk.Checksum,
+				MinTime:  int64(from),
+				MaxTime:  int64(through),
+				KB:       uint32(math.Round(float64(chk.Data.UncompressedSize()) / float64(1<<10))),
+				Entries:  uint32(100 * (i + 1)),
+			}
+
+			chunkID := schemaCfg.ExternalKey(chk.ChunkRef)
+			_, labelEntries, err := schema.GetCacheKeysAndLabelWriteEntries(from, through, tenant, "logs", lbls, chunkID)
+			require.NoError(t, err)
+			entries, err := schema.GetChunkWriteEntries(from, through, tenant, "logs", lbls, chunkID)
+			require.NoError(t, err)
+			for _, bucketEntries := range labelEntries {
+				entries = append(entries, bucketEntries...)
+			}
+			for _, e := range entries {
+				batch.Add(e.TableName, e.HashValue, e.RangeValue, e.Value)
+			}
+		}
+	}
+
+	dbPath := filepath.Join(t.TempDir(), "db")
+	db, err := shipper_util.SafeOpenBoltdbFile(dbPath)
+	require.NoError(t, err)
+	require.NoError(t, local.WriteToDB(context.Background(), db, []byte("index"), batch.(*local.BoltWriteBatch).Writes[tableName]))
+	require.NoError(t, db.Close())
+
+	f, err := os.Open(dbPath)
+	require.NoError(t, err)
+	require.NoError(t, sourceClient.PutFile(context.Background(), tableName, "db", f))
+	require.NoError(t, f.Close())
+
+	newTablePrefix = tsdbPrefix
+	tableRange := config.TableRange{
+		Start:        0,
+		End:          tableNum,
+		PeriodConfig: &pcfg,
+	}
+	require.NoError(t, migrateTables(pcfg, storageCfg, clientMetrics, tableRange))
+
+	newTableName := fmt.Sprintf("%s%d", tsdbPrefix, tableNum)
+	uploadedFiles := map[string]string{}
+	for tenant, numChunks := range expectedChunks {
+		indexFiles, err := destClient.ListUserFiles(context.Background(), newTableName, tenant, true)
+		require.NoError(t, err)
+		require.Len(t, indexFiles, 1)
+		uploadedFiles[tenant] = indexFiles[0].Name
+
+		dst := filepath.Join(t.TempDir(), strings.TrimSuffix(indexFiles[0].Name, gzipExtension))
+		err = shipper_storage.DownloadFileFromStorage(
+			dst,
+			true,
+			true,
+			shipper_storage.LoggerWithFilename(util_log.Logger, indexFiles[0].Name),
+			func() (io.ReadCloser, error) {
+				return destClient.GetUserFile(context.Background(), newTableName, tenant, indexFiles[0].Name)
+			},
+		)
+		require.NoError(t, err)
+
+		idx, _, err := tsdb.NewTSDBIndexFromFile(dst)
+		require.NoError(t, err)
+
+		var chunks int
+		require.NoError(t, idx.ForSeries(context.Background(), nil, 0, math.MaxInt64, func(lbls labels.Labels, _ model.Fingerprint, chks []tsdb_index.ChunkMeta) {
+			// the metric name isn't migrated to the tsdb index
+			require.Equal(t, labels.Labels{{Name: "tenant", Value: tenant}}, lbls)
+			for _, chk := range chks {
+				// the size of the chunks is read from the chunks
+				require.Equal(t, expectedMetas[tenant][chk.Checksum], chk)
+			}
+			chunks += len(chks)
+		}, labels.MustNewMatcher(labels.MatchEqual, "", "")))
+		require.Equal(t, numChunks, chunks)
+		require.NoError(t, idx.Close())
+	}
+
+	// running the migration again skips the tenants already migrated
+	require.NoError(t, migrateTables(pcfg, storageCfg, clientMetrics, tableRange))
+	for tenant, name := range uploadedFiles {
+		indexFiles, err := destClient.ListUserFiles(context.Background(), newTableName, tenant, true)
+		require.NoError(t, err)
+		require.Len(t, indexFiles, 1)
+		require.Equal(t, name, indexFiles[0].Name)
+	}
+
+	// only the selected tenants are migrated, with the guessed size of the chunks when enabled
+	tenants = map[string]struct{}{"user1": {}}
+	guessChunkSizes = true
+	defer func() {
+		tenants = nil
+		guessChunkSizes = false
+	}()
+	newTablePrefix = "tsdb_selected_"
+	require.NoError(t, migrateTables(pcfg, storageCfg, clientMetrics, tableRange))
+	selectedTableName := fmt.Sprintf("tsdb_selected_%d", tableNum)
+	_, users, err := destClient.ListFiles(context.Background(), selectedTableName, true)
+	require.NoError(t, err)
+	require.Equal(t, []string{"user1"}, users)
+
+	indexFiles, err := destClient.ListUserFiles(context.Background(), selectedTableName, "user1", true)
+	require.NoError(t, err)
+	require.Len(t, indexFiles, 1)
+	dst := filepath.Join(t.TempDir(), strings.TrimSuffix(indexFiles[0].Name, gzipExtension))
+	require.NoError(t, shipper_storage.DownloadFileFromStorage(
+		dst,
+		true,
+		true,
+		shipper_storage.LoggerWithFilename(util_log.Logger, indexFiles[0].Name),
+		func() (io.ReadCloser, error) {
+			return destClient.GetUserFile(context.Background(), selectedTableName, "user1", indexFiles[0].Name)
+		},
+	))
+	idx, _, err := tsdb.NewTSDBIndexFromFile(dst)
+	require.NoError(t, err)
+	defer idx.Close()
+	require.NoError(t, idx.ForSeries(context.Background(), nil, 0, math.MaxInt64, func(_ labels.Labels, _ model.Fingerprint, chks []tsdb_index.ChunkMeta) {
+		for _, chk := range chks {
+			require.Equal(t, uint32(guessedChunkKB), chk.KB)
+			require.Equal(t, uint32(guessedChunkEntries), chk.Entries)
+		}
+	}, labels.MustNewMatcher(labels.MatchEqual, "", "")))
+}
+
+func createChunk(t testing.TB, userID string, lbs labels.Labels, from, through model.Time, numEntries int) chunk.Chunk {
+	t.Helper()
+	const (
+		targetSize = 1500 * 1024
+		blockSize  = 256 * 1024
+	)
+	chunkEnc := chunkenc.NewMemChunk(chunkenc.EncSnappy, chunkenc.UnorderedHeadBlockFmt, blockSize, targetSize)
+
+	step := through.Sub(from) / time.Duration(numEntries)
+	for i := 0; i < numEntries; i++ {
+		ts := from.Add(time.Duration(i) * step)
+		require.NoError(t, chunkEnc.Append(&logproto.Entry{
+			Timestamp: ts.Time(),
+			Line:      ts.String(),
+		}))
+	}
+
+	require.NoError(t, chunkEnc.Close())
+	c := chunk.NewChunk(userID, model.Fingerprint(lbs.Hash()), lbs, chunkenc.NewFacade(chunkEnc, blockSize, targetSize), from, through)
+	require.NoError(t, c.Encode())
+	return c
+}